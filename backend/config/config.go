@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"strings"
 	"time"
 
@@ -15,6 +16,7 @@ type Config struct {
 	Redis    RedisConfig    `mapstructure:"redis"`
 	Auth     AuthConfig     `mapstructure:"auth"`
 	Mail     MailConfig     `mapstructure:"mail"`
+	Webhook  WebhookConfig  `mapstructure:"webhook"`
 	Log      LogConfig      `mapstructure:"log"`
 	Feature  FeatureConfig  `mapstructure:"feature"`
 }
@@ -24,6 +26,8 @@ type ServerConfig struct {
 	Port    int        `mapstructure:"port"`
 	BaseURL string     `mapstructure:"base_url"`
 	CORS    CORSConfig `mapstructure:"cors"`
+	// TrustedProxies 可信反向代理的 IP 或网段；仅来自这些地址的 X-Forwarded-For 会被采信，为空则直接使用连接地址
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
 }
 
 // CORSConfig 跨域配置
@@ -86,6 +90,13 @@ type MailConfig struct {
 	From     string `mapstructure:"from"`
 }
 
+// WebhookConfig 外部 Webhook 配置（URL 为空时不推送）
+type WebhookConfig struct {
+	URL     string        `mapstructure:"url"`
+	Secret  string        `mapstructure:"secret"` // 非空时请求头 X-Echo-Signature 为请求体的 HMAC-SHA256
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
 // LogConfig 日志配置
 type LogConfig struct {
 	Level  string `mapstructure:"level"`
@@ -129,6 +140,10 @@ func Load(path string) (*Config, error) {
 	v.SetDefault("auth.cookie.secure", false)
 	v.SetDefault("auth.cookie.same_site", "Lax")
 
+	v.SetDefault("mail.smtp_port", 587)
+
+	v.SetDefault("webhook.timeout", "5s")
+
 	v.SetDefault("log.level", "info")
 	v.SetDefault("log.format", "json")
 
@@ -180,6 +195,11 @@ func (c *Config) Validate() error {
 	if c.Server.Port <= 0 || c.Server.Port > 65535 {
 		return fmt.Errorf("配置校验失败: server.port 必须在 1-65535 之间")
 	}
+	for _, p := range c.Server.TrustedProxies {
+		if _, _, err := net.ParseCIDR(p); err != nil && net.ParseIP(p) == nil {
+			return fmt.Errorf("配置校验失败: server.trusted_proxies 中的 %q 不是合法的 IP 或网段", p)
+		}
+	}
 	return nil
 }
 
@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/service"
+	"echo-union/backend/pkg/response"
+)
+
+// CalendarHandler 学期校历 HTTP 处理器
+type CalendarHandler struct {
+	svc service.CalendarService
+}
+
+// NewCalendarHandler 创建 CalendarHandler
+func NewCalendarHandler(svc service.CalendarService) *CalendarHandler {
+	return &CalendarHandler{svc: svc}
+}
+
+// ListDays 获取学期校历特殊日期
+// GET /api/v1/semesters/:id/calendar
+func (h *CalendarHandler) ListDays(c *gin.Context) {
+	days, err := h.svc.ListDays(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleCalendarError(c, err)
+		return
+	}
+	response.OK(c, gin.H{"list": days})
+}
+
+// ResolveDays 按校历换算日期区间（是否值班、教学周、调休执行星期）
+// GET /api/v1/semesters/:id/calendar/resolve?from=&to=
+func (h *CalendarHandler) ResolveDays(c *gin.Context) {
+	var req dto.CalendarResolveRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, 10001, "参数校验失败")
+		return
+	}
+
+	days, err := h.svc.Resolve(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		h.handleCalendarError(c, err)
+		return
+	}
+	response.OK(c, gin.H{"list": days})
+}
+
+// UpsertDay 新增或覆盖校历特殊日期（放假 / 调休上班）
+// POST /api/v1/semesters/:id/calendar
+func (h *CalendarHandler) UpsertDay(c *gin.Context) {
+	var req dto.UpsertCalendarDayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, 10001, "参数校验失败")
+		return
+	}
+
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	day, err := h.svc.UpsertDay(c.Request.Context(), c.Param("id"), &req, callerID)
+	if err != nil {
+		h.handleCalendarError(c, err)
+		return
+	}
+	response.OK(c, day)
+}
+
+// DeleteDay 删除校历特殊日期
+// DELETE /api/v1/semesters/:id/calendar/:day_id
+func (h *CalendarHandler) DeleteDay(c *gin.Context) {
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.DeleteDay(c.Request.Context(), c.Param("id"), c.Param("day_id"), callerID); err != nil {
+		h.handleCalendarError(c, err)
+		return
+	}
+	response.OK(c, nil)
+}
+
+// ImportICS 导入节假日 ICS
+// POST /api/v1/semesters/:id/calendar/import
+//
+// 支持两种方式：
+//   - 文件上传: multipart/form-data, field="file"
+//   - URL 导入: application/json, body={"url": "..."}
+func (h *CalendarHandler) ImportICS(c *gin.Context) {
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	const maxUploadSize = 5 << 20 // 5MB
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxUploadSize)
+
+	file, _, err := c.Request.FormFile("file")
+	if err == nil {
+		defer file.Close()
+		resp, err := h.svc.ImportICS(c.Request.Context(), c.Param("id"), file, callerID)
+		if err != nil {
+			h.handleCalendarError(c, err)
+			return
+		}
+		response.OK(c, resp)
+		return
+	}
+
+	var req dto.ImportICSRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		req.URL = c.PostForm("url")
+	}
+	if req.URL == "" {
+		response.BadRequest(c, 14100, "请上传 ICS 文件或提供 ICS URL")
+		return
+	}
+
+	body, err := service.FetchICSContent(req.URL)
+	if err != nil {
+		response.ErrorWithDetails(c, http.StatusBadRequest, 14104, "ICS URL 获取失败", err.Error())
+		return
+	}
+	defer body.Close()
+
+	resp, err := h.svc.ImportICS(c.Request.Context(), c.Param("id"), body, callerID)
+	if err != nil {
+		h.handleCalendarError(c, err)
+		return
+	}
+	response.OK(c, resp)
+}
+
+// handleCalendarError 将校历 Service 层错误映射为 HTTP 响应
+func (h *CalendarHandler) handleCalendarError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrSemesterNotFound):
+		response.NotFound(c, 14001, "学期不存在")
+	case errors.Is(err, service.ErrCalendarDayNotFound):
+		response.NotFound(c, 14101, "校历日期不存在")
+	case errors.Is(err, service.ErrCalendarDateOutOfRange):
+		response.BadRequest(c, 14102, "日期不在学期范围内")
+	case errors.Is(err, service.ErrCalendarDayInvalid):
+		response.BadRequest(c, 14103, "校历日期参数无效")
+	case errors.Is(err, service.ErrCalendarICSParseFailed):
+		response.BadRequest(c, 14105, "节假日 ICS 解析失败")
+	case errors.Is(err, service.ErrCalendarICSEmpty):
+		response.BadRequest(c, 14106, "节假日 ICS 中无学期范围内的日期")
+	default:
+		response.InternalError(c)
+	}
+}
@@ -0,0 +1,448 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/skip2/go-qrcode"
+
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/model"
+	"echo-union/backend/internal/service"
+	"echo-union/backend/pkg/response"
+)
+
+// DutyHandler 值班 HTTP 处理器
+type DutyHandler struct {
+	dutySvc service.DutyService
+}
+
+// NewDutyHandler 创建 DutyHandler
+func NewDutyHandler(dutySvc service.DutyService) *DutyHandler {
+	return &DutyHandler{dutySvc: dutySvc}
+}
+
+// SignIn 签到（地点开启二维码签到时须提交展示屏上的二维码内容）
+// POST /api/v1/duties/records/:id/sign-in
+func (h *DutyHandler) SignIn(c *gin.Context) {
+	var req dto.SignInRequest
+	// 请求体可选
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, 10001, "参数校验失败")
+			return
+		}
+	}
+
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	record, err := h.dutySvc.SignIn(c.Request.Context(), c.Param("id"), &req, callerID, c.ClientIP())
+	if err != nil {
+		h.handleDutyError(c, err)
+		return
+	}
+
+	response.OK(c, record)
+}
+
+// SignOut 签退
+// POST /api/v1/duties/records/:id/sign-out
+func (h *DutyHandler) SignOut(c *gin.Context) {
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	record, err := h.dutySvc.SignOut(c.Request.Context(), c.Param("id"), callerID, c.ClientIP())
+	if err != nil {
+		h.handleDutyError(c, err)
+		return
+	}
+
+	response.OK(c, record)
+}
+
+// SetNetworkOverride 为单条值班记录豁免 / 恢复签到网段限制
+// PUT /api/v1/duties/records/:id/network-override
+func (h *DutyHandler) SetNetworkOverride(c *gin.Context) {
+	var req dto.NetworkOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, 10001, "参数校验失败")
+		return
+	}
+
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	record, err := h.dutySvc.SetNetworkOverride(c.Request.Context(), c.Param("id"), &req, callerID)
+	if err != nil {
+		h.handleDutyError(c, err)
+		return
+	}
+
+	response.OK(c, record)
+}
+
+// ListSignInRejections 被拒绝的签到 / 签退审计（leader 仅本部门）
+// GET /api/v1/duties/sign-in-rejections
+func (h *DutyHandler) ListSignInRejections(c *gin.Context) {
+	var req dto.SignInRejectionListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, 10001, "参数校验失败")
+		return
+	}
+
+	callerRole, ok := MustGetRole(c)
+	if !ok {
+		return
+	}
+	callerDeptID, ok := MustGetDepartmentID(c)
+	if !ok {
+		return
+	}
+
+	rejections, total, err := h.dutySvc.ListSignInRejections(c.Request.Context(), &req, callerRole, callerDeptID)
+	if err != nil {
+		h.handleDutyError(c, err)
+		return
+	}
+
+	response.OKPage(c, rejections, total, req.GetPage(), req.GetPageSize())
+}
+
+// SaveReport 填写 / 修改本人值班的值班日志
+// PUT /api/v1/duties/records/:id/report
+func (h *DutyHandler) SaveReport(c *gin.Context) {
+	var req dto.SaveDutyReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, 10001, "参数校验失败")
+		return
+	}
+
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.dutySvc.SaveReport(c.Request.Context(), c.Param("id"), &req, callerID)
+	if err != nil {
+		h.handleDutyError(c, err)
+		return
+	}
+
+	response.OK(c, report)
+}
+
+// GetReport 值班日志详情（填写人、管理员与本部门负责人）
+// GET /api/v1/duties/records/:id/report
+func (h *DutyHandler) GetReport(c *gin.Context) {
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+	callerRole, ok := MustGetRole(c)
+	if !ok {
+		return
+	}
+	callerDeptID, ok := MustGetDepartmentID(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.dutySvc.GetReport(c.Request.Context(), c.Param("id"), callerID, callerRole, callerDeptID)
+	if err != nil {
+		h.handleDutyError(c, err)
+		return
+	}
+
+	response.OK(c, report)
+}
+
+// GetHandover 本人值班的交接内容（同一地点上一班次的值班日志）
+// GET /api/v1/duties/records/:id/handover
+func (h *DutyHandler) GetHandover(c *gin.Context) {
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	handover, err := h.dutySvc.Handover(c.Request.Context(), c.Param("id"), callerID)
+	if err != nil {
+		h.handleDutyError(c, err)
+		return
+	}
+
+	response.OK(c, handover)
+}
+
+// ListReports 检索值班日志（leader 仅本部门）
+// GET /api/v1/duties/reports
+func (h *DutyHandler) ListReports(c *gin.Context) {
+	var req dto.DutyReportListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, 10001, "参数校验失败")
+		return
+	}
+
+	callerRole, ok := MustGetRole(c)
+	if !ok {
+		return
+	}
+	callerDeptID, ok := MustGetDepartmentID(c)
+	if !ok {
+		return
+	}
+
+	reports, total, err := h.dutySvc.ListReports(c.Request.Context(), &req, callerRole, callerDeptID)
+	if err != nil {
+		h.handleDutyError(c, err)
+		return
+	}
+
+	response.OKPage(c, reports, total, req.GetPage(), req.GetPageSize())
+}
+
+// GetLocationSignInQR 地点展示屏的当前签到二维码；format=png 时直接返回二维码图片
+// GET /api/v1/locations/:id/sign-in-qr?format=png
+func (h *DutyHandler) GetLocationSignInQR(c *gin.Context) {
+	qr, err := h.dutySvc.LocationSignInQR(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleDutyError(c, err)
+		return
+	}
+
+	if c.Query("format") != "png" {
+		response.OK(c, qr)
+		return
+	}
+	png, err := qrcode.Encode(qr.Token, qrcode.Medium, 512)
+	if err != nil {
+		response.InternalError(c)
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Header("Refresh", strconv.Itoa(qr.RefreshIn))
+	c.Data(http.StatusOK, "image/png", png)
+}
+
+// MarkAbsent 标记缺勤，可选广播紧急替班
+// POST /api/v1/duties/records/:id/absent
+func (h *DutyHandler) MarkAbsent(c *gin.Context) {
+	var req dto.MarkAbsentRequest
+	// 请求体可选
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, 10001, "参数校验失败")
+			return
+		}
+	}
+
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+	callerRole, ok := MustGetRole(c)
+	if !ok {
+		return
+	}
+	callerDeptID, ok := MustGetDepartmentID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.dutySvc.MarkAbsent(c.Request.Context(), c.Param("id"), &req, callerID, callerRole, callerDeptID)
+	if err != nil {
+		h.handleDutyError(c, err)
+		return
+	}
+
+	response.OK(c, result)
+}
+
+// GetEmergency 紧急替班详情
+// GET /api/v1/duties/emergencies/:id
+func (h *DutyHandler) GetEmergency(c *gin.Context) {
+	emergency, err := h.dutySvc.GetEmergency(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleDutyError(c, err)
+		return
+	}
+
+	response.OK(c, emergency)
+}
+
+// CoverEmergency 响应紧急替班（先到先得）
+// POST /api/v1/duties/emergencies/:id/cover
+func (h *DutyHandler) CoverEmergency(c *gin.Context) {
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	emergency, err := h.dutySvc.CoverEmergency(c.Request.Context(), c.Param("id"), callerID)
+	if err != nil {
+		h.handleDutyError(c, err)
+		return
+	}
+
+	response.OK(c, emergency)
+}
+
+// PreviewEmergencyByLink 一键接班链接详情（无需登录，凭签名校验；仅查看，不接班）
+// GET /api/v1/duties/emergencies/:id/cover-link?member=&token=
+// format=html 时返回带确认按钮的页面（邮件中的链接），否则返回 JSON
+func (h *DutyHandler) PreviewEmergencyByLink(c *gin.Context) {
+	page := c.Query("format") == "html"
+	var req dto.CoverEmergencyByTokenRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		if page {
+			renderCoverPage(c, http.StatusBadRequest, coverPage{Message: "接班链接无效"})
+			return
+		}
+		response.BadRequest(c, 10001, "参数校验失败")
+		return
+	}
+
+	emergency, err := h.dutySvc.PreviewEmergencyByToken(c.Request.Context(), c.Param("id"), &req)
+	if !page {
+		if err != nil {
+			h.handleDutyError(c, err)
+			return
+		}
+		response.OK(c, emergency)
+		return
+	}
+	if err != nil {
+		status, msg := coverPageError(err)
+		renderCoverPage(c, status, coverPage{Message: msg})
+		return
+	}
+	if emergency.Status != model.EmergencyStatusOpen {
+		renderCoverPage(c, http.StatusOK, coverPage{Emergency: emergency, Message: "紧急替班已有人接班或已取消"})
+		return
+	}
+	renderCoverPage(c, http.StatusOK, coverPage{Emergency: emergency, MemberID: req.MemberID, Token: req.Token})
+}
+
+// CoverEmergencyByLink 一键接班确认（无需登录，凭签名校验）
+// POST /api/v1/duties/emergencies/:id/cover-link，请求体（表单或 JSON）携带 member、token
+// 由确认页面提交（format=html）时返回结果页面
+func (h *DutyHandler) CoverEmergencyByLink(c *gin.Context) {
+	page := c.Query("format") == "html"
+	var req dto.CoverEmergencyByTokenRequest
+	if err := c.ShouldBind(&req); err != nil {
+		if page {
+			renderCoverPage(c, http.StatusBadRequest, coverPage{Message: "接班链接无效"})
+			return
+		}
+		response.BadRequest(c, 10001, "参数校验失败")
+		return
+	}
+
+	emergency, err := h.dutySvc.CoverEmergencyByToken(c.Request.Context(), c.Param("id"), &req)
+	if !page {
+		if err != nil {
+			h.handleDutyError(c, err)
+			return
+		}
+		response.OK(c, emergency)
+		return
+	}
+	if err != nil {
+		status, msg := coverPageError(err)
+		renderCoverPage(c, status, coverPage{Message: msg})
+		return
+	}
+	renderCoverPage(c, http.StatusOK, coverPage{Emergency: emergency, Message: "接班成功，请按时到岗签到"})
+}
+
+// CancelEmergency 取消紧急替班广播
+// POST /api/v1/duties/emergencies/:id/cancel
+func (h *DutyHandler) CancelEmergency(c *gin.Context) {
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+	callerRole, ok := MustGetRole(c)
+	if !ok {
+		return
+	}
+	callerDeptID, ok := MustGetDepartmentID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.dutySvc.CancelEmergency(c.Request.Context(), c.Param("id"), callerID, callerRole, callerDeptID); err != nil {
+		h.handleDutyError(c, err)
+		return
+	}
+
+	response.OK(c, nil)
+}
+
+// handleDutyError 统一处理值班模块业务错误
+func (h *DutyHandler) handleDutyError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrDutyRecordNotFound):
+		response.NotFound(c, 23001, "值班记录不存在")
+	case errors.Is(err, service.ErrDutyForbidden):
+		response.Forbidden(c, 23002, "无权操作该值班记录")
+	case errors.Is(err, service.ErrDutyNotStarted):
+		response.BadRequest(c, 23003, "值班尚未开始，不能标记缺勤")
+	case errors.Is(err, service.ErrDutyRecordNotPending):
+		response.Error(c, http.StatusConflict, 23004, "值班记录已签到或已处理")
+	case errors.Is(err, service.ErrEmergencyNotFound):
+		response.NotFound(c, 23005, "紧急替班不存在")
+	case errors.Is(err, service.ErrEmergencyNotEligible):
+		response.BadRequest(c, 23006, "该次值班不支持紧急替班")
+	case errors.Is(err, service.ErrEmergencyClosed):
+		response.Error(c, http.StatusConflict, 23007, "紧急替班已有人接班或已取消")
+	case errors.Is(err, service.ErrEmergencyExpired):
+		response.Error(c, http.StatusGone, 23008, "该次值班已结束")
+	case errors.Is(err, service.ErrEmergencyInvalidToken):
+		response.Forbidden(c, 23009, "接班链接无效")
+	case errors.Is(err, service.ErrEmergencyMemberUnavailable):
+		response.ErrorWithDetails(c, http.StatusBadRequest, 23010, "你在该次值班时段不可用", err.Error())
+	case errors.Is(err, service.ErrEmergencyAbsentMemberCannot):
+		response.BadRequest(c, 23011, "缺勤成员不能为自己替班")
+	case errors.Is(err, service.ErrDutyNotOwner):
+		response.Forbidden(c, 23012, "只能为本人的值班签到签退")
+	case errors.Is(err, service.ErrSignInWindow):
+		response.BadRequest(c, 23013, "当前不在签到时间窗口")
+	case errors.Is(err, service.ErrSignOutWindow):
+		response.BadRequest(c, 23014, "当前不在签退时间窗口")
+	case errors.Is(err, service.ErrDutyAlreadySignedIn):
+		response.Error(c, http.StatusConflict, 23015, "已签到，请勿重复签到")
+	case errors.Is(err, service.ErrDutyNotSignedIn):
+		response.BadRequest(c, 23016, "未签到或已签退，无法签退")
+	case errors.Is(err, service.ErrSignInQRRequired):
+		response.BadRequest(c, 23017, "该地点须扫描二维码签到")
+	case errors.Is(err, service.ErrSignInQRInvalid):
+		response.BadRequest(c, 23018, "二维码无效或已过期")
+	case errors.Is(err, service.ErrSignInQRWrongLocation):
+		response.BadRequest(c, 23019, "二维码不属于本次值班的地点")
+	case errors.Is(err, service.ErrSignInQRUsed):
+		response.Error(c, http.StatusConflict, 23020, "二维码已被使用，请等待刷新后重新扫描")
+	case errors.Is(err, service.ErrLocationNotFound):
+		response.NotFound(c, 23021, "地点不存在")
+	case errors.Is(err, service.ErrSignInNetwork):
+		response.Forbidden(c, 23022, "当前网络不在该地点允许的签到范围内")
+	case errors.Is(err, service.ErrDutyReportNotFound):
+		response.NotFound(c, 23023, "该次值班暂无值班日志")
+	case errors.Is(err, service.ErrDutyReportNotSignedIn):
+		response.BadRequest(c, 23024, "签到后才能填写值班日志")
+	case errors.Is(err, service.ErrDutyReportClosed):
+		response.BadRequest(c, 23025, "值班结束已超过 24 小时，不能再填写或修改值班日志")
+	case errors.Is(err, service.ErrSemesterNotFound):
+		response.NotFound(c, 23026, "学期不存在")
+	default:
+		response.InternalError(c)
+	}
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/service"
+	"echo-union/backend/pkg/response"
+)
+
+// coverPage 一键接班确认 / 结果页面数据；MemberID、Token 非空时显示确认按钮
+type coverPage struct {
+	Emergency *dto.EmergencySubstitutionResponse
+	Message   string
+	MemberID  string
+	Token     string
+}
+
+// coverPageTmpl 邮件中的一键接班链接打开的页面：GET 只展示详情，点击确认后以 POST 提交接班
+var coverPageTmpl = template.Must(template.New("cover").Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>紧急替班</title>
+</head>
+<body style="font-family: sans-serif; max-width: 480px; margin: 40px auto; padding: 0 16px;">
+<h2>紧急替班</h2>
+{{with .Emergency}}{{with .DutyRecord}}
+<p>日期：{{.DutyDate}}</p>
+{{with .TimeSlot}}<p>时段：{{.Name}}（{{.StartTime}} - {{.EndTime}}）</p>{{end}}
+{{with .Location}}<p>地点：{{.Name}}</p>{{end}}
+{{with .Member}}<p>缺勤成员：{{.Name}}</p>{{end}}
+{{end}}{{end}}
+{{with .Message}}<p><strong>{{.}}</strong></p>{{end}}
+{{if .Token}}
+<form method="post" action="?format=html">
+<input type="hidden" name="member" value="{{.MemberID}}">
+<input type="hidden" name="token" value="{{.Token}}">
+<p>确认接班后将为你生成一条值班记录，先到先得。</p>
+<button type="submit">确认接班</button>
+</form>
+{{end}}
+</body>
+</html>
+`))
+
+// renderCoverPage 渲染一键接班页面
+func renderCoverPage(c *gin.Context, status int, page coverPage) {
+	var buf bytes.Buffer
+	if err := coverPageTmpl.Execute(&buf, page); err != nil {
+		response.InternalError(c)
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Data(status, "text/html; charset=utf-8", buf.Bytes())
+}
+
+// coverPageError 一键接班页面的错误状态码与提示，与 handleDutyError 保持一致
+func coverPageError(err error) (int, string) {
+	switch {
+	case errors.Is(err, service.ErrEmergencyNotFound):
+		return http.StatusNotFound, "紧急替班不存在"
+	case errors.Is(err, service.ErrEmergencyNotEligible):
+		return http.StatusBadRequest, "该次值班不支持紧急替班"
+	case errors.Is(err, service.ErrEmergencyClosed):
+		return http.StatusConflict, "紧急替班已有人接班或已取消"
+	case errors.Is(err, service.ErrEmergencyExpired):
+		return http.StatusGone, "该次值班已结束"
+	case errors.Is(err, service.ErrEmergencyInvalidToken):
+		return http.StatusForbidden, "接班链接无效"
+	case errors.Is(err, service.ErrEmergencyMemberUnavailable):
+		return http.StatusBadRequest, err.Error()
+	case errors.Is(err, service.ErrEmergencyAbsentMemberCannot):
+		return http.StatusBadRequest, "缺勤成员不能为自己替班"
+	default:
+		return http.StatusInternalServerError, "服务器内部错误"
+	}
+}
@@ -0,0 +1,373 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/model"
+	"echo-union/backend/internal/service"
+	"echo-union/backend/pkg/response"
+)
+
+// EventHandler 活动值班 HTTP 处理器
+type EventHandler struct {
+	eventSvc service.EventService
+}
+
+// NewEventHandler 创建 EventHandler
+func NewEventHandler(eventSvc service.EventService) *EventHandler {
+	return &EventHandler{eventSvc: eventSvc}
+}
+
+// ListEvents 获取活动列表（非管理员不含草稿）
+// GET /api/v1/events?semester_id=xxx&status=published
+func (h *EventHandler) ListEvents(c *gin.Context) {
+	var req dto.EventListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, 10001, "参数校验失败")
+		return
+	}
+
+	role, ok := MustGetRole(c)
+	if !ok {
+		return
+	}
+
+	events, err := h.eventSvc.ListEvents(c.Request.Context(), &req, role == model.RoleAdmin)
+	if err != nil {
+		response.InternalError(c)
+		return
+	}
+
+	response.OK(c, gin.H{"list": events})
+}
+
+// GetEvent 获取活动详情（含班次与人员）
+// GET /api/v1/events/:id
+func (h *EventHandler) GetEvent(c *gin.Context) {
+	role, ok := MustGetRole(c)
+	if !ok {
+		return
+	}
+
+	event, err := h.eventSvc.GetEvent(c.Request.Context(), c.Param("id"), role == model.RoleAdmin)
+	if err != nil {
+		h.handleEventError(c, err)
+		return
+	}
+
+	response.OK(c, event)
+}
+
+// CreateEvent 创建活动（草稿）
+// POST /api/v1/events
+func (h *EventHandler) CreateEvent(c *gin.Context) {
+	var req dto.CreateEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, 10001, "参数校验失败")
+		return
+	}
+
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	event, err := h.eventSvc.CreateEvent(c.Request.Context(), &req, callerID)
+	if err != nil {
+		h.handleEventError(c, err)
+		return
+	}
+
+	response.Created(c, event)
+}
+
+// UpdateEvent 更新活动
+// PUT /api/v1/events/:id
+func (h *EventHandler) UpdateEvent(c *gin.Context) {
+	var req dto.UpdateEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, 10001, "参数校验失败")
+		return
+	}
+
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	event, err := h.eventSvc.UpdateEvent(c.Request.Context(), c.Param("id"), &req, callerID)
+	if err != nil {
+		h.handleEventError(c, err)
+		return
+	}
+
+	response.OK(c, event)
+}
+
+// DeleteEvent 删除草稿活动
+// DELETE /api/v1/events/:id
+func (h *EventHandler) DeleteEvent(c *gin.Context) {
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.eventSvc.DeleteEvent(c.Request.Context(), c.Param("id"), callerID); err != nil {
+		h.handleEventError(c, err)
+		return
+	}
+
+	response.OK(c, nil)
+}
+
+// PublishEvent 发布活动（生成值班记录）
+// POST /api/v1/events/:id/publish
+func (h *EventHandler) PublishEvent(c *gin.Context) {
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	event, err := h.eventSvc.PublishEvent(c.Request.Context(), c.Param("id"), callerID)
+	if err != nil {
+		h.handleEventError(c, err)
+		return
+	}
+
+	response.OK(c, event)
+}
+
+// CancelEvent 取消活动
+// POST /api/v1/events/:id/cancel
+func (h *EventHandler) CancelEvent(c *gin.Context) {
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	event, err := h.eventSvc.CancelEvent(c.Request.Context(), c.Param("id"), callerID)
+	if err != nil {
+		h.handleEventError(c, err)
+		return
+	}
+
+	response.OK(c, event)
+}
+
+// AutoAssign 按可用性自动补足各班次人手
+// POST /api/v1/events/:id/auto-assign
+func (h *EventHandler) AutoAssign(c *gin.Context) {
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.eventSvc.AutoAssign(c.Request.Context(), c.Param("id"), callerID)
+	if err != nil {
+		h.handleEventError(c, err)
+		return
+	}
+
+	response.OK(c, result)
+}
+
+// CreateShift 添加活动班次
+// POST /api/v1/events/:id/shifts
+func (h *EventHandler) CreateShift(c *gin.Context) {
+	var req dto.CreateEventShiftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, 10001, "参数校验失败")
+		return
+	}
+
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	shift, err := h.eventSvc.CreateShift(c.Request.Context(), c.Param("id"), &req, callerID)
+	if err != nil {
+		h.handleEventError(c, err)
+		return
+	}
+
+	response.Created(c, shift)
+}
+
+// UpdateShift 更新活动班次
+// PUT /api/v1/events/shifts/:shift_id
+func (h *EventHandler) UpdateShift(c *gin.Context) {
+	var req dto.UpdateEventShiftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, 10001, "参数校验失败")
+		return
+	}
+
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	shift, err := h.eventSvc.UpdateShift(c.Request.Context(), c.Param("shift_id"), &req, callerID)
+	if err != nil {
+		h.handleEventError(c, err)
+		return
+	}
+
+	response.OK(c, shift)
+}
+
+// DeleteShift 删除活动班次（仅草稿活动）
+// DELETE /api/v1/events/shifts/:shift_id
+func (h *EventHandler) DeleteShift(c *gin.Context) {
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.eventSvc.DeleteShift(c.Request.Context(), c.Param("shift_id"), callerID); err != nil {
+		h.handleEventError(c, err)
+		return
+	}
+
+	response.OK(c, nil)
+}
+
+// SignUp 报名活动班次
+// POST /api/v1/events/shifts/:shift_id/signup
+func (h *EventHandler) SignUp(c *gin.Context) {
+	userID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.eventSvc.SignUp(c.Request.Context(), c.Param("shift_id"), userID); err != nil {
+		h.handleEventError(c, err)
+		return
+	}
+
+	response.OK(c, nil)
+}
+
+// Withdraw 退出活动班次
+// DELETE /api/v1/events/shifts/:shift_id/signup
+func (h *EventHandler) Withdraw(c *gin.Context) {
+	userID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.eventSvc.Withdraw(c.Request.Context(), c.Param("shift_id"), userID); err != nil {
+		h.handleEventError(c, err)
+		return
+	}
+
+	response.OK(c, nil)
+}
+
+// AssignMember 管理员指派班次人员
+// POST /api/v1/events/shifts/:shift_id/members
+func (h *EventHandler) AssignMember(c *gin.Context) {
+	var req dto.AssignEventMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, 10001, "参数校验失败")
+		return
+	}
+
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.eventSvc.AssignMember(c.Request.Context(), c.Param("shift_id"), req.MemberID, callerID); err != nil {
+		h.handleEventError(c, err)
+		return
+	}
+
+	response.OK(c, nil)
+}
+
+// UnassignMember 管理员移除班次人员
+// DELETE /api/v1/events/shifts/:shift_id/members/:member_id
+func (h *EventHandler) UnassignMember(c *gin.Context) {
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.eventSvc.UnassignMember(c.Request.Context(), c.Param("shift_id"), c.Param("member_id"), callerID); err != nil {
+		h.handleEventError(c, err)
+		return
+	}
+
+	response.OK(c, nil)
+}
+
+// ListMyShifts 我接下来的活动班次
+// GET /api/v1/events/my
+func (h *EventHandler) ListMyShifts(c *gin.Context) {
+	userID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	shifts, err := h.eventSvc.ListMyShifts(c.Request.Context(), userID)
+	if err != nil {
+		response.InternalError(c)
+		return
+	}
+
+	response.OK(c, gin.H{"list": shifts})
+}
+
+// handleEventError 统一处理活动值班模块业务错误
+func (h *EventHandler) handleEventError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrEventNotFound):
+		response.NotFound(c, 20001, "活动不存在")
+	case errors.Is(err, service.ErrEventNotDraft):
+		response.BadRequest(c, 20002, "仅草稿状态的活动可执行此操作")
+	case errors.Is(err, service.ErrEventCancelled):
+		response.BadRequest(c, 20003, "活动已取消")
+	case errors.Is(err, service.ErrEventNoShifts):
+		response.BadRequest(c, 20004, "活动尚未添加班次")
+	case errors.Is(err, service.ErrEventSignupClosed):
+		response.BadRequest(c, 20005, "活动未开放报名")
+	case errors.Is(err, service.ErrEventShiftNotFound):
+		response.NotFound(c, 20006, "活动班次不存在")
+	case errors.Is(err, service.ErrEventShiftInvalid):
+		response.BadRequest(c, 20007, "班次日期或时间无效")
+	case errors.Is(err, service.ErrEventShiftOutOfSemester):
+		response.BadRequest(c, 20008, "班次日期不在学期范围内")
+	case errors.Is(err, service.ErrEventShiftLocked):
+		response.BadRequest(c, 20009, "活动已发布，不能修改班次日期、时间或删除班次")
+	case errors.Is(err, service.ErrEventShiftHeadcount):
+		response.BadRequest(c, 20010, "班次人数不能少于已安排人数")
+	case errors.Is(err, service.ErrEventShiftFull):
+		response.Error(c, http.StatusConflict, 20011, "班次已满员")
+	case errors.Is(err, service.ErrEventShiftStarted):
+		response.BadRequest(c, 20012, "班次已开始")
+	case errors.Is(err, service.ErrEventAlreadyAssigned):
+		response.Error(c, http.StatusConflict, 20013, "成员已在该班次中")
+	case errors.Is(err, service.ErrEventAssignmentNotFound):
+		response.NotFound(c, 20014, "成员不在该班次中")
+	case errors.Is(err, service.ErrEventMemberUnavailable):
+		response.ErrorWithDetails(c, http.StatusBadRequest, 20015, "成员在该班次时段不可用", err.Error())
+	case errors.Is(err, service.ErrEventMemberUnqualified):
+		response.ErrorWithDetails(c, http.StatusBadRequest, 20019, "成员不具备班次所需技能", err.Error())
+	case errors.Is(err, service.ErrSemesterNotFound):
+		response.NotFound(c, 20016, "学期不存在")
+	case errors.Is(err, service.ErrLocationNotFound):
+		response.NotFound(c, 20017, "地点不存在")
+	case errors.Is(err, service.ErrUserNotFound):
+		response.NotFound(c, 20018, "用户不存在")
+	case errors.Is(err, service.ErrSkillNotFound):
+		response.BadRequest(c, 18201, "技能不存在")
+	default:
+		response.InternalError(c)
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"echo-union/backend/internal/dto"
 	"echo-union/backend/internal/service"
 	"echo-union/backend/pkg/response"
 )
@@ -36,12 +37,65 @@ func (h *ExportHandler) ExportSchedule(c *gin.Context) {
 		return
 	}
 
-	// 设置下载响应头
+	writeExcel(c, buf.Bytes(), filename)
+}
+
+// ExportEvent 导出活动值班安排
+// GET /api/v1/export/event?event_id=xxx
+func (h *ExportHandler) ExportEvent(c *gin.Context) {
+	eventID := c.Query("event_id")
+	if eventID == "" {
+		response.BadRequest(c, 10001, "event_id 不能为空")
+		return
+	}
+
+	buf, filename, err := h.exportSvc.ExportEvent(c.Request.Context(), eventID)
+	if err != nil {
+		h.handleExportError(c, err)
+		return
+	}
+
+	writeExcel(c, buf.Bytes(), filename)
+}
+
+// ExportAttendance 导出出勤统计（成员与部门汇总两个 Sheet，leader 仅本部门）
+// GET /api/v1/export/attendance?semester_id=&from=&to=&department_id=
+func (h *ExportHandler) ExportAttendance(c *gin.Context) {
+	var req dto.AttendanceStatsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, 10001, "参数校验失败")
+		return
+	}
+
+	callerRole, ok := MustGetRole(c)
+	if !ok {
+		return
+	}
+	callerDeptID, ok := MustGetDepartmentID(c)
+	if !ok {
+		return
+	}
+
+	buf, filename, err := h.exportSvc.ExportAttendance(c.Request.Context(), &req, callerRole, callerDeptID)
+	if err != nil {
+		if errors.Is(err, service.ErrExportGenerateFail) {
+			response.InternalError(c)
+			return
+		}
+		handleStatisticsError(c, err)
+		return
+	}
+
+	writeExcel(c, buf.Bytes(), filename)
+}
+
+// writeExcel 设置下载响应头并写入 Excel 内容
+func writeExcel(c *gin.Context, data []byte, filename string) {
 	encodedFilename := url.QueryEscape(filename)
 	c.Header("Content-Description", "File Transfer")
 	c.Header("Content-Disposition", "attachment; filename*=UTF-8''"+encodedFilename)
 	c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
-	c.Data(http.StatusOK, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", buf.Bytes())
+	c.Data(http.StatusOK, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", data)
 }
 
 func (h *ExportHandler) handleExportError(c *gin.Context, err error) {
@@ -50,6 +104,10 @@ func (h *ExportHandler) handleExportError(c *gin.Context, err error) {
 		response.NotFound(c, 16101, "该学期暂无排班表")
 	case errors.Is(err, service.ErrExportNoItems):
 		response.BadRequest(c, 16102, "排班表中无排班项")
+	case errors.Is(err, service.ErrEventNotFound):
+		response.NotFound(c, 16103, "活动不存在")
+	case errors.Is(err, service.ErrExportNoShifts):
+		response.BadRequest(c, 16104, "活动中无班次")
 	case errors.Is(err, service.ErrExportGenerateFail):
 		response.InternalError(c)
 	default:
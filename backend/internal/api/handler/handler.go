@@ -7,32 +7,50 @@ import (
 
 // Handler 所有 Handler 的聚合入口
 type Handler struct {
-	Auth         *AuthHandler
-	User         *UserHandler
-	Department   *DepartmentHandler
-	Semester     *SemesterHandler
-	TimeSlot     *TimeSlotHandler
-	Location     *LocationHandler
-	SystemConfig *SystemConfigHandler
-	ScheduleRule *ScheduleRuleHandler
-	Schedule     *ScheduleHandler
-	Timetable    *TimetableHandler
-	Export       *ExportHandler
+	Auth           *AuthHandler
+	User           *UserHandler
+	Department     *DepartmentHandler
+	Semester       *SemesterHandler
+	Calendar       *CalendarHandler
+	TimeSlot       *TimeSlotHandler
+	Location       *LocationHandler
+	SystemConfig   *SystemConfigHandler
+	ScheduleRule   *ScheduleRuleHandler
+	Schedule       *ScheduleHandler
+	Timetable      *TimetableHandler
+	Export         *ExportHandler
+	Notification   *NotificationHandler
+	Event          *EventHandler
+	PairConstraint *PairConstraintHandler
+	Skill          *SkillHandler
+	Swap           *SwapHandler
+	Leave          *LeaveHandler
+	Duty           *DutyHandler
+	Statistics     *StatisticsHandler
 }
 
 // NewHandler 创建 Handler 聚合
 func NewHandler(cfg *config.Config, svc *service.Service) *Handler {
 	return &Handler{
-		Auth:         NewAuthHandler(svc.Auth, &cfg.Auth.Cookie),
-		User:         NewUserHandler(svc.User),
-		Department:   NewDepartmentHandler(svc.Department),
-		Semester:     NewSemesterHandler(svc.Semester),
-		TimeSlot:     NewTimeSlotHandler(svc.TimeSlot),
-		Location:     NewLocationHandler(svc.Location),
-		SystemConfig: NewSystemConfigHandler(svc.SystemConfig),
-		ScheduleRule: NewScheduleRuleHandler(svc.ScheduleRule),
-		Schedule:     NewScheduleHandler(svc.Schedule),
-		Timetable:    NewTimetableHandler(svc.Timetable),
-		Export:       NewExportHandler(svc.Export),
+		Auth:           NewAuthHandler(svc.Auth, &cfg.Auth.Cookie),
+		User:           NewUserHandler(svc.User),
+		Department:     NewDepartmentHandler(svc.Department),
+		Semester:       NewSemesterHandler(svc.Semester),
+		Calendar:       NewCalendarHandler(svc.Calendar),
+		TimeSlot:       NewTimeSlotHandler(svc.TimeSlot),
+		Location:       NewLocationHandler(svc.Location),
+		SystemConfig:   NewSystemConfigHandler(svc.SystemConfig),
+		ScheduleRule:   NewScheduleRuleHandler(svc.ScheduleRule),
+		Schedule:       NewScheduleHandler(svc.Schedule),
+		Timetable:      NewTimetableHandler(svc.Timetable),
+		Export:         NewExportHandler(svc.Export),
+		Notification:   NewNotificationHandler(svc.Notification),
+		Event:          NewEventHandler(svc.Event),
+		PairConstraint: NewPairConstraintHandler(svc.PairConstraint),
+		Skill:          NewSkillHandler(svc.Skill),
+		Swap:           NewSwapHandler(svc.Swap),
+		Leave:          NewLeaveHandler(svc.Leave),
+		Duty:           NewDutyHandler(svc.Duty),
+		Statistics:     NewStatisticsHandler(svc.Statistics),
 	}
 }
@@ -5,15 +5,18 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/model"
 	"echo-union/backend/internal/service"
 	"echo-union/backend/pkg/response"
 )
@@ -79,11 +82,65 @@ type mockScheduleService struct {
 	changeLogsErr         error
 	scopeResult           *dto.ScopeCheckResponse
 	scopeErr              error
+	swapResult            []dto.ScheduleItemResponse
+	swapErr               error
+	moveResult            *dto.ScheduleItemResponse
+	moveErr               error
+	batchResult           []dto.ScheduleItemResponse
+	batchErr              error
+	jobResult             *dto.AutoScheduleJobResponse
+	jobErr                error
+	jobEvents             []dto.AutoScheduleJobResponse
+	candidatesList        []dto.ScheduleSummaryResponse
+	candidatesListErr     error
+	compareResult         []dto.ScheduleSummaryResponse
+	compareErr            error
+	promoteResult         *dto.ScheduleResponse
+	promoteErr            error
+	discardErr            error
+	qualityResult         *dto.ScheduleQualityReport
+	qualityErr            error
+	versionsList          []dto.ScheduleVersionResponse
+	versionsErr           error
+	diffResult            *dto.ScheduleDiffResponse
+	diffErr               error
+	restoreResult         *dto.ScheduleResponse
+	restoreErr            error
+	explainResult         *dto.SlotExplanationResponse
+	explainErr            error
+	substitutesResult     []dto.DutyRecordResponse
+	substitutesErr        error
+	conflictsList         []dto.ScheduleConflictResponse
+	conflictResult        *dto.ScheduleConflictResponse
+	conflictErr           error
 }
 
 func (m *mockScheduleService) AutoSchedule(_ context.Context, _ *dto.AutoScheduleRequest, _ string) (*dto.AutoScheduleResponse, error) {
 	return m.autoResult, m.autoErr
 }
+func (m *mockScheduleService) StartAutoScheduleJob(_ context.Context, _ *dto.AutoScheduleRequest, _ string) (*dto.AutoScheduleJobResponse, error) {
+	return m.jobResult, m.jobErr
+}
+func (m *mockScheduleService) GetAutoScheduleJob(_ context.Context, _ string) (*dto.AutoScheduleJobResponse, error) {
+	return m.jobResult, m.jobErr
+}
+func (m *mockScheduleService) CancelAutoScheduleJob(_ context.Context, _, _ string) (*dto.AutoScheduleJobResponse, error) {
+	return m.jobResult, m.jobErr
+}
+func (m *mockScheduleService) SubscribeAutoScheduleJob(_ context.Context, _ string) (<-chan dto.AutoScheduleJobResponse, func(), error) {
+	if m.jobErr != nil {
+		return nil, nil, m.jobErr
+	}
+	ch := make(chan dto.AutoScheduleJobResponse, len(m.jobEvents))
+	for _, ev := range m.jobEvents {
+		ch <- ev
+	}
+	close(ch)
+	return ch, func() {}, nil
+}
+func (m *mockScheduleService) SimulateSchedule(_ context.Context, _ *dto.SimulateScheduleRequest) (*dto.ScheduleSimulationResponse, error) {
+	return nil, nil
+}
 func (m *mockScheduleService) GetSchedule(_ context.Context, _ string) (*dto.ScheduleResponse, error) {
 	return m.getResult, m.getErr
 }
@@ -93,6 +150,15 @@ func (m *mockScheduleService) GetMySchedule(_ context.Context, _, _ string) (*dt
 func (m *mockScheduleService) UpdateItem(_ context.Context, _ string, _ *dto.UpdateScheduleItemRequest, _ string) (*dto.ScheduleItemResponse, error) {
 	return m.updateItemResult, m.updateItemErr
 }
+func (m *mockScheduleService) SwapItems(_ context.Context, _ *dto.SwapScheduleItemsRequest, _ string) ([]dto.ScheduleItemResponse, error) {
+	return m.swapResult, m.swapErr
+}
+func (m *mockScheduleService) MoveItem(_ context.Context, _ string, _ *dto.MoveScheduleItemRequest, _ string) (*dto.ScheduleItemResponse, error) {
+	return m.moveResult, m.moveErr
+}
+func (m *mockScheduleService) BatchUpdateItems(_ context.Context, _ *dto.BatchUpdateScheduleItemsRequest, _ string) ([]dto.ScheduleItemResponse, error) {
+	return m.batchResult, m.batchErr
+}
 func (m *mockScheduleService) ValidateCandidate(_ context.Context, _ string, _ *dto.ValidateCandidateRequest) (*dto.ValidateCandidateResponse, error) {
 	return m.validateResult, m.validateErr
 }
@@ -112,6 +178,55 @@ func (m *mockScheduleService) CheckScope(_ context.Context, _ string) (*dto.Scop
 	return m.scopeResult, m.scopeErr
 }
 
+func (m *mockScheduleService) ListCandidates(_ context.Context, _ string) ([]dto.ScheduleSummaryResponse, error) {
+	return m.candidatesList, m.candidatesListErr
+}
+func (m *mockScheduleService) CompareSchedules(_ context.Context, _ *dto.CompareSchedulesRequest) ([]dto.ScheduleSummaryResponse, error) {
+	return m.compareResult, m.compareErr
+}
+func (m *mockScheduleService) PromoteCandidate(_ context.Context, _, _ string) (*dto.ScheduleResponse, error) {
+	return m.promoteResult, m.promoteErr
+}
+func (m *mockScheduleService) DiscardCandidate(_ context.Context, _, _ string) error {
+	return m.discardErr
+}
+func (m *mockScheduleService) GetQualityReport(_ context.Context, _ string) (*dto.ScheduleQualityReport, error) {
+	return m.qualityResult, m.qualityErr
+}
+func (m *mockScheduleService) GetWorkload(_ context.Context, _ string) (*dto.ScheduleWorkloadResponse, error) {
+	return nil, nil
+}
+
+func (m *mockScheduleService) ListVersions(_ context.Context, _ string) ([]dto.ScheduleVersionResponse, error) {
+	return m.versionsList, m.versionsErr
+}
+func (m *mockScheduleService) DiffSchedules(_ context.Context, _ *dto.DiffSchedulesRequest) (*dto.ScheduleDiffResponse, error) {
+	return m.diffResult, m.diffErr
+}
+func (m *mockScheduleService) RestoreVersion(_ context.Context, _, _ string) (*dto.ScheduleResponse, error) {
+	return m.restoreResult, m.restoreErr
+}
+
+func (m *mockScheduleService) ExplainItem(_ context.Context, _ string) (*dto.SlotExplanationResponse, error) {
+	return m.explainResult, m.explainErr
+}
+
+func (m *mockScheduleService) ExplainSlot(_ context.Context, _ string, _ *dto.ExplainSlotRequest) (*dto.SlotExplanationResponse, error) {
+	return m.explainResult, m.explainErr
+}
+
+func (m *mockScheduleService) ListSubstituteNeeded(_ context.Context, _ string) ([]dto.DutyRecordResponse, error) {
+	return m.substitutesResult, m.substitutesErr
+}
+
+func (m *mockScheduleService) ListConflicts(_ context.Context, _ *dto.ScheduleConflictListRequest) ([]dto.ScheduleConflictResponse, int64, error) {
+	return m.conflictsList, int64(len(m.conflictsList)), m.conflictErr
+}
+
+func (m *mockScheduleService) ResolveConflict(_ context.Context, _ string, _ *dto.ResolveScheduleConflictRequest, _ string) (*dto.ScheduleConflictResponse, error) {
+	return m.conflictResult, m.conflictErr
+}
+
 // ── Mock ExportService ──
 
 type mockExportService struct {
@@ -124,6 +239,14 @@ func (m *mockExportService) ExportSchedule(_ context.Context, _ string) (*bytes.
 	return m.buf, m.filename, m.err
 }
 
+func (m *mockExportService) ExportEvent(_ context.Context, _ string) (*bytes.Buffer, string, error) {
+	return m.buf, m.filename, m.err
+}
+
+func (m *mockExportService) ExportAttendance(_ context.Context, _ *dto.AttendanceStatsRequest, _, _ string) (*bytes.Buffer, string, error) {
+	return m.buf, m.filename, m.err
+}
+
 // ═══════════════════════════════════════════════════════════
 // Test Helpers
 // ═══════════════════════════════════════════════════════════
@@ -651,6 +774,10 @@ func TestScheduleHandler_ErrorMapping(t *testing.T) {
 		{"NoTimeSlots", service.ErrNoActiveTimeSlots, 400, 13109},
 		{"CandidateNA", service.ErrCandidateNotAvailable, 400, 13110},
 		{"SemesterNotFound", service.ErrSemesterNotFound, 404, 13111},
+		{"EditConflict", fmt.Errorf("%w: 第1周 周1 周一上午: 同人同日重复排班", service.ErrScheduleEditConflict), 400, 13113},
+		{"SlotOccupied", service.ErrScheduleSlotOccupied, 400, 13114},
+		{"AutoScheduleRunning", service.ErrAutoScheduleRunning, 409, 13118},
+		{"JobNotFound", service.ErrAutoScheduleJobNotFound, 404, 13119},
 		{"InternalError", errors.New("unknown"), 500, 50000},
 	}
 
@@ -677,6 +804,182 @@ func TestScheduleHandler_ErrorMapping(t *testing.T) {
 	}
 }
 
+func TestScheduleHandler_SwapItems_Conflict(t *testing.T) {
+	mock := &mockScheduleService{
+		swapErr: fmt.Errorf("%w: 第1周 周1 周一上午: 课程冲突: 高等数学", service.ErrScheduleEditConflict),
+	}
+	h := NewScheduleHandler(mock)
+
+	_, _, w := setupGin()
+	req := httptest.NewRequest("POST", "/schedules/items/swap", jsonBody(dto.SwapScheduleItemsRequest{
+		ItemAID: "11111111-1111-1111-1111-111111111111",
+		ItemBID: "22222222-2222-2222-2222-222222222222",
+	}))
+	req.Header.Set("Content-Type", "application/json")
+
+	r := gin.New()
+	r.POST("/schedules/items/swap", func(c *gin.Context) {
+		setAuth(c)
+		h.SwapItems(c)
+	})
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", w.Code)
+	}
+	resp := parseResponse(w)
+	if resp.Code != 13113 {
+		t.Errorf("expected code 13113, got %d", resp.Code)
+	}
+	if resp.Details == "" {
+		t.Error("expected conflict details")
+	}
+}
+
+func TestScheduleHandler_StartAutoScheduleJob_Accepted(t *testing.T) {
+	mock := &mockScheduleService{
+		jobResult: &dto.AutoScheduleJobResponse{JobID: "job-1", Status: model.AutoScheduleJobQueued},
+	}
+	h := NewScheduleHandler(mock)
+
+	_, _, w := setupGin()
+	req := httptest.NewRequest("POST", "/schedules/auto/jobs", jsonBody(dto.AutoScheduleRequest{
+		SemesterID: "22222222-2222-2222-2222-222222222222",
+	}))
+	req.Header.Set("Content-Type", "application/json")
+
+	r := gin.New()
+	r.POST("/schedules/auto/jobs", func(c *gin.Context) {
+		setAuth(c)
+		h.StartAutoScheduleJob(c)
+	})
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusAccepted {
+		t.Errorf("expected 202, got %d", w.Code)
+	}
+}
+
+func TestScheduleHandler_StreamAutoScheduleJob_Events(t *testing.T) {
+	mock := &mockScheduleService{
+		jobEvents: []dto.AutoScheduleJobResponse{
+			{JobID: "job-1", Status: model.AutoScheduleJobRunning, Progress: dto.AutoScheduleProgress{Phase: "assign", FilledSlots: 3}},
+			{JobID: "job-1", Status: model.AutoScheduleJobSucceeded, Progress: dto.AutoScheduleProgress{Phase: "done"}},
+		},
+	}
+	h := NewScheduleHandler(mock)
+
+	_, _, w := setupGin()
+	req := httptest.NewRequest("GET", "/schedules/auto/jobs/job-1/events", nil)
+
+	r := gin.New()
+	r.GET("/schedules/auto/jobs/:id/events", h.StreamAutoScheduleJob)
+	r.ServeHTTP(w, req)
+
+	if ct := w.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("expected text/event-stream, got %q", ct)
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, "event:progress") || !strings.Contains(body, "event:done") {
+		t.Errorf("expected progress and done events, got %q", body)
+	}
+}
+
+func TestScheduleHandler_CompareSchedules_RequiresTwo(t *testing.T) {
+	mock := &mockScheduleService{}
+	h := NewScheduleHandler(mock)
+
+	_, _, w := setupGin()
+	req := httptest.NewRequest("GET", "/schedules/compare?schedule_ids=11111111-1111-1111-1111-111111111111", nil)
+
+	r := gin.New()
+	r.GET("/schedules/compare", h.CompareSchedules)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestScheduleHandler_PromoteCandidate_PublishedExists(t *testing.T) {
+	mock := &mockScheduleService{promoteErr: service.ErrSchedulePublishedExists}
+	h := NewScheduleHandler(mock)
+
+	_, _, w := setupGin()
+	req := httptest.NewRequest("POST", "/schedules/sched-1/promote", nil)
+
+	r := gin.New()
+	r.POST("/schedules/:id/promote", func(c *gin.Context) {
+		setAuth(c)
+		h.PromoteCandidate(c)
+	})
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("expected 409, got %d", w.Code)
+	}
+	if resp := parseResponse(w); resp.Code != 13124 {
+		t.Errorf("expected code 13124, got %d", resp.Code)
+	}
+}
+
+func TestScheduleHandler_RestoreVersion_Created(t *testing.T) {
+	mock := &mockScheduleService{restoreResult: &dto.ScheduleResponse{ID: "sched-2", Status: "draft"}}
+	h := NewScheduleHandler(mock)
+
+	_, _, w := setupGin()
+	req := httptest.NewRequest("POST", "/schedules/sched-1/restore", nil)
+
+	r := gin.New()
+	r.POST("/schedules/:id/restore", func(c *gin.Context) {
+		setAuth(c)
+		h.RestoreVersion(c)
+	})
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("expected 201, got %d", w.Code)
+	}
+}
+
+func TestScheduleHandler_RestoreVersion_NotArchived(t *testing.T) {
+	mock := &mockScheduleService{restoreErr: service.ErrScheduleNotArchived}
+	h := NewScheduleHandler(mock)
+
+	_, _, w := setupGin()
+	req := httptest.NewRequest("POST", "/schedules/sched-1/restore", nil)
+
+	r := gin.New()
+	r.POST("/schedules/:id/restore", func(c *gin.Context) {
+		setAuth(c)
+		h.RestoreVersion(c)
+	})
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", w.Code)
+	}
+	if resp := parseResponse(w); resp.Code != 13127 {
+		t.Errorf("expected code 13127, got %d", resp.Code)
+	}
+}
+
+func TestScheduleHandler_ExplainSlot_InvalidWeek(t *testing.T) {
+	mock := &mockScheduleService{}
+	h := NewScheduleHandler(mock)
+
+	_, _, w := setupGin()
+	req := httptest.NewRequest("GET", "/schedules/sched-1/slots/explain?week_number=3&time_slot_id=11111111-1111-1111-1111-111111111111", nil)
+
+	r := gin.New()
+	r.GET("/schedules/:id/slots/explain", h.ExplainSlot)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", w.Code)
+	}
+}
+
 // ═══════════════════════════════════════════════════════════
 // ExportHandler Tests
 // ═══════════════════════════════════════════════════════════
@@ -0,0 +1,247 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/service"
+	"echo-union/backend/pkg/response"
+)
+
+// LeaveHandler 请假 HTTP 处理器
+type LeaveHandler struct {
+	leaveSvc service.LeaveService
+}
+
+// NewLeaveHandler 创建 LeaveHandler
+func NewLeaveHandler(leaveSvc service.LeaveService) *LeaveHandler {
+	return &LeaveHandler{leaveSvc: leaveSvc}
+}
+
+// CreateLeave 为某一具体日期的本人值班请假
+// POST /api/v1/leaves
+func (h *LeaveHandler) CreateLeave(c *gin.Context) {
+	var req dto.CreateLeaveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, 10001, "参数校验失败")
+		return
+	}
+
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	leave, err := h.leaveSvc.Create(c.Request.Context(), &req, callerID)
+	if err != nil {
+		h.handleLeaveError(c, err)
+		return
+	}
+
+	response.Created(c, leave)
+}
+
+// ListMyLeaves 我的请假
+// GET /api/v1/leaves/me
+func (h *LeaveHandler) ListMyLeaves(c *gin.Context) {
+	var req dto.LeaveListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, 10001, "参数校验失败")
+		return
+	}
+
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	leaves, total, err := h.leaveSvc.ListMine(c.Request.Context(), &req, callerID)
+	if err != nil {
+		h.handleLeaveError(c, err)
+		return
+	}
+
+	response.OKPage(c, leaves, total, req.GetPage(), req.GetPageSize())
+}
+
+// ListReviewLeaves 审批人可见的请假（leader 仅本部门）
+// GET /api/v1/leaves/review
+func (h *LeaveHandler) ListReviewLeaves(c *gin.Context) {
+	var req dto.LeaveListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, 10001, "参数校验失败")
+		return
+	}
+
+	callerRole, ok := MustGetRole(c)
+	if !ok {
+		return
+	}
+	callerDeptID, ok := MustGetDepartmentID(c)
+	if !ok {
+		return
+	}
+
+	leaves, total, err := h.leaveSvc.ListForReview(c.Request.Context(), &req, callerRole, callerDeptID)
+	if err != nil {
+		h.handleLeaveError(c, err)
+		return
+	}
+
+	response.OKPage(c, leaves, total, req.GetPage(), req.GetPageSize())
+}
+
+// GetLeaveStats 成员学期请假次数（leader 仅本部门）
+// GET /api/v1/leaves/stats?semester_id=
+func (h *LeaveHandler) GetLeaveStats(c *gin.Context) {
+	var req dto.LeaveStatsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, 10001, "参数校验失败")
+		return
+	}
+
+	callerRole, ok := MustGetRole(c)
+	if !ok {
+		return
+	}
+	callerDeptID, ok := MustGetDepartmentID(c)
+	if !ok {
+		return
+	}
+
+	stats, err := h.leaveSvc.Stats(c.Request.Context(), &req, callerRole, callerDeptID)
+	if err != nil {
+		h.handleLeaveError(c, err)
+		return
+	}
+
+	response.OK(c, gin.H{"list": stats})
+}
+
+// GetLeave 请假详情（本人、替班成员与审批人可见）
+// GET /api/v1/leaves/:id
+func (h *LeaveHandler) GetLeave(c *gin.Context) {
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+	callerRole, ok := MustGetRole(c)
+	if !ok {
+		return
+	}
+	callerDeptID, ok := MustGetDepartmentID(c)
+	if !ok {
+		return
+	}
+
+	leave, err := h.leaveSvc.GetByID(c.Request.Context(), c.Param("id"), callerID, callerRole, callerDeptID)
+	if err != nil {
+		h.handleLeaveError(c, err)
+		return
+	}
+
+	response.OK(c, leave)
+}
+
+// ListLeaveCandidates 替班候选人（按负荷从低到高）
+// GET /api/v1/leaves/:id/candidates
+func (h *LeaveHandler) ListLeaveCandidates(c *gin.Context) {
+	callerRole, ok := MustGetRole(c)
+	if !ok {
+		return
+	}
+	callerDeptID, ok := MustGetDepartmentID(c)
+	if !ok {
+		return
+	}
+
+	candidates, err := h.leaveSvc.Candidates(c.Request.Context(), c.Param("id"), callerRole, callerDeptID)
+	if err != nil {
+		h.handleLeaveError(c, err)
+		return
+	}
+
+	response.OK(c, gin.H{"list": candidates})
+}
+
+// ReviewLeave 审批请假（通过时须指定替班成员）
+// PUT /api/v1/leaves/:id/review
+func (h *LeaveHandler) ReviewLeave(c *gin.Context) {
+	var req dto.ReviewLeaveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, 10001, "参数校验失败")
+		return
+	}
+
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+	callerRole, ok := MustGetRole(c)
+	if !ok {
+		return
+	}
+	callerDeptID, ok := MustGetDepartmentID(c)
+	if !ok {
+		return
+	}
+
+	leave, err := h.leaveSvc.Review(c.Request.Context(), c.Param("id"), &req, callerID, callerRole, callerDeptID)
+	if err != nil {
+		h.handleLeaveError(c, err)
+		return
+	}
+
+	response.OK(c, leave)
+}
+
+// CancelLeave 撤回待审批的请假
+// POST /api/v1/leaves/:id/cancel
+func (h *LeaveHandler) CancelLeave(c *gin.Context) {
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.leaveSvc.Cancel(c.Request.Context(), c.Param("id"), callerID); err != nil {
+		h.handleLeaveError(c, err)
+		return
+	}
+
+	response.OK(c, nil)
+}
+
+// handleLeaveError 统一处理请假模块业务错误
+func (h *LeaveHandler) handleLeaveError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrLeaveNotFound):
+		response.NotFound(c, 22001, "请假申请不存在")
+	case errors.Is(err, service.ErrLeaveDutyRecordNotFound):
+		response.NotFound(c, 22002, "值班记录不存在")
+	case errors.Is(err, service.ErrLeaveNotOwner):
+		response.Forbidden(c, 22003, "只能为本人的值班请假")
+	case errors.Is(err, service.ErrLeaveDutyNotEligible):
+		response.BadRequest(c, 22004, "该次值班不可请假")
+	case errors.Is(err, service.ErrLeaveDutyStarted):
+		response.BadRequest(c, 22005, "值班已开始，无法请假")
+	case errors.Is(err, service.ErrLeaveAlreadyPending):
+		response.Error(c, http.StatusConflict, 22006, "该次值班已有待审批的请假")
+	case errors.Is(err, service.ErrLeaveSwapOpen):
+		response.Error(c, http.StatusConflict, 22007, "该次值班已有进行中的换班申请")
+	case errors.Is(err, service.ErrLeaveNotPending):
+		response.BadRequest(c, 22008, "请假不在待审批状态")
+	case errors.Is(err, service.ErrLeaveForbidden):
+		response.Forbidden(c, 22009, "无权操作该请假申请")
+	case errors.Is(err, service.ErrLeaveSubstituteRequired):
+		response.BadRequest(c, 22010, "审批通过须指定替班成员")
+	case errors.Is(err, service.ErrLeaveSubstituteUnavailable):
+		response.ErrorWithDetails(c, http.StatusBadRequest, 22011, "替班成员在该次值班时段不可用", err.Error())
+	case errors.Is(err, service.ErrLeaveOutdated):
+		response.Error(c, http.StatusConflict, 22012, "值班安排已变更，请假已失效")
+	default:
+		response.InternalError(c)
+	}
+}
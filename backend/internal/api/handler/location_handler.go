@@ -135,6 +135,10 @@ func (h *LocationHandler) handleLocationError(c *gin.Context, err error) {
 	switch {
 	case errors.Is(err, service.ErrLocationNotFound):
 		response.NotFound(c, 16001, "地点不存在")
+	case errors.Is(err, service.ErrLocationWindowInvalid):
+		response.BadRequest(c, 16002, "开放时段结束时间必须晚于开始时间")
+	case errors.Is(err, service.ErrLocationCIDRInvalid):
+		response.BadRequest(c, 16005, "签到网段格式无效")
 	default:
 		response.InternalError(c)
 	}
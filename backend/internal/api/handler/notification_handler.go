@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/service"
+	"echo-union/backend/pkg/response"
+)
+
+// NotificationHandler 通知消息 HTTP 处理器
+type NotificationHandler struct {
+	svc service.NotificationService
+}
+
+// NewNotificationHandler 创建 NotificationHandler
+func NewNotificationHandler(svc service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{svc: svc}
+}
+
+// ListNotifications 获取我的通知
+// GET /api/v1/notifications?unread_only=true&page=1&page_size=20
+func (h *NotificationHandler) ListNotifications(c *gin.Context) {
+	userID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.NotificationListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, 10001, "参数校验失败")
+		return
+	}
+
+	list, total, err := h.svc.List(c.Request.Context(), userID, &req)
+	if err != nil {
+		response.InternalError(c)
+		return
+	}
+
+	response.OKPage(c, list, total, req.GetPage(), req.GetPageSize())
+}
+
+// MarkRead 标记通知已读
+// PUT /api/v1/notifications/:id/read
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	userID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.MarkRead(c.Request.Context(), c.Param("id"), userID); err != nil {
+		if errors.Is(err, service.ErrNotificationNotFound) {
+			response.NotFound(c, 19001, "通知不存在")
+			return
+		}
+		response.InternalError(c)
+		return
+	}
+
+	response.OK(c, nil)
+}
+
+// MarkAllRead 标记全部通知已读
+// PUT /api/v1/notifications/read-all
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	userID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.MarkAllRead(c.Request.Context(), userID); err != nil {
+		response.InternalError(c)
+		return
+	}
+
+	response.OK(c, nil)
+}
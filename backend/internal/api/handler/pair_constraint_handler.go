@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/service"
+	"echo-union/backend/pkg/response"
+)
+
+// PairConstraintHandler 成员搭配约束 HTTP 处理器
+type PairConstraintHandler struct {
+	svc service.PairConstraintService
+}
+
+// NewPairConstraintHandler 创建 PairConstraintHandler
+func NewPairConstraintHandler(svc service.PairConstraintService) *PairConstraintHandler {
+	return &PairConstraintHandler{svc: svc}
+}
+
+// List 获取学期的搭配约束列表
+// GET /api/v1/semesters/:id/pair-constraints
+func (h *PairConstraintHandler) List(c *gin.Context) {
+	list, err := h.svc.List(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handlePairConstraintError(c, err)
+		return
+	}
+	response.OK(c, gin.H{"list": list})
+}
+
+// Create 新增搭配约束
+// POST /api/v1/semesters/:id/pair-constraints
+func (h *PairConstraintHandler) Create(c *gin.Context) {
+	var req dto.CreatePairConstraintRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, 10001, "参数校验失败")
+		return
+	}
+
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.svc.Create(c.Request.Context(), c.Param("id"), &req, callerID)
+	if err != nil {
+		h.handlePairConstraintError(c, err)
+		return
+	}
+	response.Created(c, resp)
+}
+
+// Delete 删除搭配约束
+// DELETE /api/v1/semesters/:id/pair-constraints/:constraint_id
+func (h *PairConstraintHandler) Delete(c *gin.Context) {
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.Delete(c.Request.Context(), c.Param("id"), c.Param("constraint_id"), callerID); err != nil {
+		h.handlePairConstraintError(c, err)
+		return
+	}
+	response.OK(c, nil)
+}
+
+// handlePairConstraintError 统一处理搭配约束业务错误
+func (h *PairConstraintHandler) handlePairConstraintError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrPairConstraintNotFound):
+		response.NotFound(c, 18101, "搭配约束不存在")
+	case errors.Is(err, service.ErrPairConstraintSelf):
+		response.BadRequest(c, 18102, "搭配约束的两名成员不能相同")
+	case errors.Is(err, service.ErrPairConstraintDuplicate):
+		response.Error(c, http.StatusConflict, 18103, "该两名成员已存在搭配约束")
+	case errors.Is(err, service.ErrSemesterNotFound):
+		response.NotFound(c, 18104, "学期不存在")
+	case errors.Is(err, service.ErrUserNotFound):
+		response.NotFound(c, 18105, "用户不存在")
+	case errors.Is(err, service.ErrPairConstraintNoSlots):
+		response.BadRequest(c, 18106, "本学期没有时间重叠的并行时段，must_pair 约束无法满足")
+	case errors.Is(err, service.ErrPairConstraintNotDuty):
+		response.BadRequest(c, 18107, "搭配约束的成员须为本学期值班成员")
+	default:
+		response.InternalError(c)
+	}
+}
@@ -2,14 +2,21 @@ package handler
 
 import (
 	"errors"
+	"io"
+	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/model"
 	"echo-union/backend/internal/service"
 	"echo-union/backend/pkg/response"
 )
 
+// sseHeartbeatInterval SSE 心跳间隔
+const sseHeartbeatInterval = 15 * time.Second
+
 // ScheduleHandler 排班模块 HTTP 处理器
 type ScheduleHandler struct {
 	scheduleSvc service.ScheduleService
@@ -43,6 +50,142 @@ func (h *ScheduleHandler) AutoSchedule(c *gin.Context) {
 	response.OK(c, result)
 }
 
+// SimulateSchedule 模拟排班（不写入数据）
+// POST /api/v1/schedules/simulate
+func (h *ScheduleHandler) SimulateSchedule(c *gin.Context) {
+	var req dto.SimulateScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, 10001, "参数校验失败")
+		return
+	}
+
+	result, err := h.scheduleSvc.SimulateSchedule(c.Request.Context(), &req)
+	if err != nil {
+		h.handleScheduleError(c, err)
+		return
+	}
+
+	response.OK(c, result)
+}
+
+// StartAutoScheduleJob 提交后台自动排班任务
+// POST /api/v1/schedules/auto/jobs
+func (h *ScheduleHandler) StartAutoScheduleJob(c *gin.Context) {
+	var req dto.AutoScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, 10001, "参数校验失败")
+		return
+	}
+
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	job, err := h.scheduleSvc.StartAutoScheduleJob(c.Request.Context(), &req, callerID)
+	if err != nil {
+		h.handleScheduleError(c, err)
+		return
+	}
+
+	response.Accepted(c, job)
+}
+
+// GetAutoScheduleJob 查询自动排班任务状态
+// GET /api/v1/schedules/auto/jobs/:id
+func (h *ScheduleHandler) GetAutoScheduleJob(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		response.BadRequest(c, 10001, "任务ID不能为空")
+		return
+	}
+
+	job, err := h.scheduleSvc.GetAutoScheduleJob(c.Request.Context(), id)
+	if err != nil {
+		h.handleScheduleError(c, err)
+		return
+	}
+
+	response.OK(c, job)
+}
+
+// CancelAutoScheduleJob 取消自动排班任务
+// POST /api/v1/schedules/auto/jobs/:id/cancel
+func (h *ScheduleHandler) CancelAutoScheduleJob(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		response.BadRequest(c, 10001, "任务ID不能为空")
+		return
+	}
+
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	job, err := h.scheduleSvc.CancelAutoScheduleJob(c.Request.Context(), id, callerID)
+	if err != nil {
+		h.handleScheduleError(c, err)
+		return
+	}
+
+	response.OK(c, job)
+}
+
+// StreamAutoScheduleJob 以 SSE 推送自动排班任务进度
+// GET /api/v1/schedules/auto/jobs/:id/events
+//
+// 事件：progress（进行中快照）、done（最终状态，随后关闭连接）；
+// 空闲时每 15 秒发送注释行作为心跳，防止代理断开。
+func (h *ScheduleHandler) StreamAutoScheduleJob(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		response.BadRequest(c, 10001, "任务ID不能为空")
+		return
+	}
+
+	events, unsubscribe, err := h.scheduleSvc.SubscribeAutoScheduleJob(c.Request.Context(), id)
+	if err != nil {
+		h.handleScheduleError(c, err)
+		return
+	}
+	defer unsubscribe()
+
+	// SSE 为长连接，解除服务器 WriteTimeout 限制
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case job, ok := <-events:
+			if !ok {
+				return
+			}
+			if job.Status == model.AutoScheduleJobSucceeded ||
+				job.Status == model.AutoScheduleJobFailed ||
+				job.Status == model.AutoScheduleJobCancelled {
+				c.SSEvent("done", job)
+				c.Writer.Flush()
+				return
+			}
+			c.SSEvent("progress", job)
+			c.Writer.Flush()
+		case <-heartbeat.C:
+			_, _ = io.WriteString(c.Writer, ": ping\n\n")
+			c.Writer.Flush()
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+}
+
 // GetSchedule 获取排班表
 // GET /api/v1/schedules
 func (h *ScheduleHandler) GetSchedule(c *gin.Context) {
@@ -113,6 +256,81 @@ func (h *ScheduleHandler) UpdateItem(c *gin.Context) {
 	response.OK(c, item)
 }
 
+// SwapItems 原子交换两个排班项的成员
+// POST /api/v1/schedules/items/swap
+func (h *ScheduleHandler) SwapItems(c *gin.Context) {
+	var req dto.SwapScheduleItemsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, 10001, "参数校验失败")
+		return
+	}
+
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	items, err := h.scheduleSvc.SwapItems(c.Request.Context(), &req, callerID)
+	if err != nil {
+		h.handleScheduleError(c, err)
+		return
+	}
+
+	response.OK(c, gin.H{"list": items})
+}
+
+// MoveItem 将排班项移动到另一空闲时段
+// PUT /api/v1/schedules/items/:id/move
+func (h *ScheduleHandler) MoveItem(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		response.BadRequest(c, 10001, "排班项ID不能为空")
+		return
+	}
+
+	var req dto.MoveScheduleItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, 10001, "参数校验失败")
+		return
+	}
+
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	item, err := h.scheduleSvc.MoveItem(c.Request.Context(), id, &req, callerID)
+	if err != nil {
+		h.handleScheduleError(c, err)
+		return
+	}
+
+	response.OK(c, item)
+}
+
+// BatchUpdateItems 批量调整排班项（单事务）
+// POST /api/v1/schedules/items/batch
+func (h *ScheduleHandler) BatchUpdateItems(c *gin.Context) {
+	var req dto.BatchUpdateScheduleItemsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, 10001, "参数校验失败")
+		return
+	}
+
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	items, err := h.scheduleSvc.BatchUpdateItems(c.Request.Context(), &req, callerID)
+	if err != nil {
+		h.handleScheduleError(c, err)
+		return
+	}
+
+	response.OK(c, gin.H{"list": items})
+}
+
 // ValidateCandidate 校验候选人是否可排
 // POST /api/v1/schedules/items/:id/validate
 func (h *ScheduleHandler) ValidateCandidate(c *gin.Context) {
@@ -243,6 +461,289 @@ func (h *ScheduleHandler) CheckScope(c *gin.Context) {
 	response.OK(c, result)
 }
 
+// ListCandidates 候选方案列表（含质量报告）
+// GET /api/v1/schedules/candidates
+func (h *ScheduleHandler) ListCandidates(c *gin.Context) {
+	semesterID := c.Query("semester_id")
+	if semesterID == "" {
+		response.BadRequest(c, 10001, "semester_id不能为空")
+		return
+	}
+
+	list, err := h.scheduleSvc.ListCandidates(c.Request.Context(), semesterID)
+	if err != nil {
+		h.handleScheduleError(c, err)
+		return
+	}
+
+	response.OK(c, gin.H{"list": list})
+}
+
+// CompareSchedules 多方案质量对比
+// GET /api/v1/schedules/compare?schedule_ids=a&schedule_ids=b
+func (h *ScheduleHandler) CompareSchedules(c *gin.Context) {
+	var req dto.CompareSchedulesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, 10001, "参数校验失败")
+		return
+	}
+
+	list, err := h.scheduleSvc.CompareSchedules(c.Request.Context(), &req)
+	if err != nil {
+		h.handleScheduleError(c, err)
+		return
+	}
+
+	response.OK(c, gin.H{"list": list})
+}
+
+// PromoteCandidate 将候选方案提升为当前草稿
+// POST /api/v1/schedules/:id/promote
+func (h *ScheduleHandler) PromoteCandidate(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		response.BadRequest(c, 10001, "排班表ID不能为空")
+		return
+	}
+
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	schedule, err := h.scheduleSvc.PromoteCandidate(c.Request.Context(), id, callerID)
+	if err != nil {
+		h.handleScheduleError(c, err)
+		return
+	}
+
+	response.OK(c, schedule)
+}
+
+// DiscardCandidate 丢弃候选方案
+// DELETE /api/v1/schedules/:id
+func (h *ScheduleHandler) DiscardCandidate(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		response.BadRequest(c, 10001, "排班表ID不能为空")
+		return
+	}
+
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.scheduleSvc.DiscardCandidate(c.Request.Context(), id, callerID); err != nil {
+		h.handleScheduleError(c, err)
+		return
+	}
+
+	response.OK(c, nil)
+}
+
+// GetQualityReport 排班方案质量报告
+// GET /api/v1/schedules/:id/quality
+func (h *ScheduleHandler) GetQualityReport(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		response.BadRequest(c, 10001, "排班表ID不能为空")
+		return
+	}
+
+	report, err := h.scheduleSvc.GetQualityReport(c.Request.Context(), id)
+	if err != nil {
+		h.handleScheduleError(c, err)
+		return
+	}
+
+	response.OK(c, report)
+}
+
+// GetWorkload 排班方案成员工作量
+// GET /api/v1/schedules/:id/workload
+func (h *ScheduleHandler) GetWorkload(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		response.BadRequest(c, 10001, "排班表ID不能为空")
+		return
+	}
+
+	workload, err := h.scheduleSvc.GetWorkload(c.Request.Context(), id)
+	if err != nil {
+		h.handleScheduleError(c, err)
+		return
+	}
+
+	response.OK(c, workload)
+}
+
+// ListVersions 学期排班版本历史
+// GET /api/v1/schedules/versions
+func (h *ScheduleHandler) ListVersions(c *gin.Context) {
+	semesterID := c.Query("semester_id")
+	if semesterID == "" {
+		response.BadRequest(c, 10001, "semester_id不能为空")
+		return
+	}
+
+	list, err := h.scheduleSvc.ListVersions(c.Request.Context(), semesterID)
+	if err != nil {
+		h.handleScheduleError(c, err)
+		return
+	}
+
+	response.OK(c, gin.H{"list": list})
+}
+
+// DiffSchedules 对比两个排班版本
+// GET /api/v1/schedules/diff?base_id=a&target_id=b
+func (h *ScheduleHandler) DiffSchedules(c *gin.Context) {
+	var req dto.DiffSchedulesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, 10001, "参数校验失败")
+		return
+	}
+
+	diff, err := h.scheduleSvc.DiffSchedules(c.Request.Context(), &req)
+	if err != nil {
+		h.handleScheduleError(c, err)
+		return
+	}
+
+	response.OK(c, diff)
+}
+
+// RestoreVersion 将归档版本恢复为新草稿
+// POST /api/v1/schedules/:id/restore
+func (h *ScheduleHandler) RestoreVersion(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		response.BadRequest(c, 10001, "排班表ID不能为空")
+		return
+	}
+
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	schedule, err := h.scheduleSvc.RestoreVersion(c.Request.Context(), id, callerID)
+	if err != nil {
+		h.handleScheduleError(c, err)
+		return
+	}
+
+	response.Created(c, schedule)
+}
+
+// ExplainItem 解释排班项的人选
+// GET /api/v1/schedules/items/:id/explain
+func (h *ScheduleHandler) ExplainItem(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		response.BadRequest(c, 10001, "排班项ID不能为空")
+		return
+	}
+
+	explanation, err := h.scheduleSvc.ExplainItem(c.Request.Context(), id)
+	if err != nil {
+		h.handleScheduleError(c, err)
+		return
+	}
+
+	response.OK(c, explanation)
+}
+
+// ExplainSlot 解释槽位（含空槽位）的排班情况
+// GET /api/v1/schedules/:id/slots/explain?week_number=1&time_slot_id=xxx
+func (h *ScheduleHandler) ExplainSlot(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		response.BadRequest(c, 10001, "排班表ID不能为空")
+		return
+	}
+
+	var req dto.ExplainSlotRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, 10001, "参数校验失败")
+		return
+	}
+
+	explanation, err := h.scheduleSvc.ExplainSlot(c.Request.Context(), id, &req)
+	if err != nil {
+		h.handleScheduleError(c, err)
+		return
+	}
+
+	response.OK(c, explanation)
+}
+
+// ListSubstituteNeeded 今日起需替班的值班记录
+// GET /api/v1/schedules/:id/substitutes
+func (h *ScheduleHandler) ListSubstituteNeeded(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		response.BadRequest(c, 10001, "排班表ID不能为空")
+		return
+	}
+
+	records, err := h.scheduleSvc.ListSubstituteNeeded(c.Request.Context(), id)
+	if err != nil {
+		h.handleScheduleError(c, err)
+		return
+	}
+
+	response.OK(c, gin.H{"list": records})
+}
+
+// ListConflicts 发布后时间表变更产生的冲突处理任务
+// GET /api/v1/schedules/conflicts
+func (h *ScheduleHandler) ListConflicts(c *gin.Context) {
+	var req dto.ScheduleConflictListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, 10001, "参数校验失败")
+		return
+	}
+
+	conflicts, total, err := h.scheduleSvc.ListConflicts(c.Request.Context(), &req)
+	if err != nil {
+		h.handleScheduleError(c, err)
+		return
+	}
+
+	response.OKPage(c, conflicts, total, req.GetPage(), req.GetPageSize())
+}
+
+// ResolveConflict 关闭冲突处理任务（已处理 / 忽略）
+// PUT /api/v1/schedules/conflicts/:id
+func (h *ScheduleHandler) ResolveConflict(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		response.BadRequest(c, 10001, "冲突任务ID不能为空")
+		return
+	}
+
+	var req dto.ResolveScheduleConflictRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, 10001, "参数校验失败")
+		return
+	}
+
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	conflict, err := h.scheduleSvc.ResolveConflict(c.Request.Context(), id, &req, callerID)
+	if err != nil {
+		h.handleScheduleError(c, err)
+		return
+	}
+
+	response.OK(c, conflict)
+}
+
 // handleScheduleError 统一处理排班模块业务错误
 func (h *ScheduleHandler) handleScheduleError(c *gin.Context, err error) {
 	switch {
@@ -270,6 +771,48 @@ func (h *ScheduleHandler) handleScheduleError(c *gin.Context, err error) {
 		response.NotFound(c, 13111, "学期不存在")
 	case errors.Is(err, service.ErrPhaseNotScheduling):
 		response.BadRequest(c, 13112, "学期阶段必须为 scheduling 才能执行自动排班")
+	case errors.Is(err, service.ErrScheduleEditConflict):
+		response.ErrorWithDetails(c, http.StatusBadRequest, 13113, "调整后的排班存在冲突", err.Error())
+	case errors.Is(err, service.ErrScheduleSlotOccupied):
+		response.BadRequest(c, 13114, "目标时段已有排班，请使用交换")
+	case errors.Is(err, service.ErrScheduleItemsMismatch):
+		response.BadRequest(c, 13115, "排班项不属于同一排班表")
+	case errors.Is(err, service.ErrScheduleSwapInvalid):
+		response.BadRequest(c, 13116, "不能与自身交换")
+	case errors.Is(err, service.ErrTimeSlotNotFound):
+		response.NotFound(c, 13117, "时间段不存在")
+	case errors.Is(err, service.ErrAutoScheduleRunning):
+		response.Error(c, http.StatusConflict, 13118, "该学期已有自动排班任务在执行")
+	case errors.Is(err, service.ErrAutoScheduleJobNotFound):
+		response.NotFound(c, 13119, "排班任务不存在")
+	case errors.Is(err, service.ErrAutoScheduleJobFinished):
+		response.BadRequest(c, 13120, "排班任务已结束")
+	case errors.Is(err, service.ErrTooManyCandidates):
+		response.BadRequest(c, 13121, "候选方案数量已达上限，请先丢弃部分方案")
+	case errors.Is(err, service.ErrScheduleNotCandidate):
+		response.BadRequest(c, 13122, "排班表非候选方案")
+	case errors.Is(err, service.ErrScheduleSemesterMismatch):
+		response.BadRequest(c, 13123, "对比的排班表不属于同一学期")
+	case errors.Is(err, service.ErrSchedulePublishedExists):
+		response.Error(c, http.StatusConflict, 13124, "该学期已有发布中的排班表，不能替换")
+	case errors.Is(err, service.ErrScheduleRuleNotFound):
+		response.BadRequest(c, 13125, "规则覆盖中包含不存在的规则")
+	case errors.Is(err, service.ErrScheduleRuleNotConfigurable):
+		response.BadRequest(c, 13126, "硬约束规则不可覆盖")
+	case errors.Is(err, service.ErrScheduleNotArchived):
+		response.BadRequest(c, 13127, "仅可恢复已归档的排班版本")
+	case errors.Is(err, service.ErrScheduleConflictNotFound):
+		response.NotFound(c, 13128, "冲突处理任务不存在")
+	case errors.Is(err, service.ErrScheduleConflictClosed):
+		response.BadRequest(c, 13129, "冲突处理任务已关闭")
+	case errors.Is(err, service.ErrSimulationRuleWeight):
+		response.BadRequest(c, 13130, "仅可调整软约束规则的罚分")
+	case errors.Is(err, service.ErrSimulationTimeSlot):
+		response.BadRequest(c, 13131, "模拟时间段结束时间须晚于开始时间")
+	case errors.Is(err, service.ErrUserNotFound):
+		response.NotFound(c, 13132, "模拟新增的成员不存在")
+	case errors.Is(err, service.ErrScheduleMemberNotDuty):
+		response.ErrorWithDetails(c, http.StatusBadRequest, 13133, "成员不是本学期的值班成员", err.Error())
 	default:
 		response.InternalError(c)
 	}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/service"
+	"echo-union/backend/pkg/response"
+)
+
+// SkillHandler 技能目录 HTTP 处理器
+type SkillHandler struct {
+	skillSvc service.SkillService
+}
+
+// NewSkillHandler 创建 SkillHandler
+func NewSkillHandler(skillSvc service.SkillService) *SkillHandler {
+	return &SkillHandler{skillSvc: skillSvc}
+}
+
+// ListSkills 获取技能目录
+// GET /api/v1/skills
+func (h *SkillHandler) ListSkills(c *gin.Context) {
+	skills, err := h.skillSvc.List(c.Request.Context())
+	if err != nil {
+		response.InternalError(c)
+		return
+	}
+
+	response.OK(c, gin.H{"list": skills})
+}
+
+// CreateSkill 创建技能
+// POST /api/v1/skills
+func (h *SkillHandler) CreateSkill(c *gin.Context) {
+	var req dto.CreateSkillRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, 10001, "参数校验失败")
+		return
+	}
+
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	skill, err := h.skillSvc.Create(c.Request.Context(), &req, callerID)
+	if err != nil {
+		h.handleSkillError(c, err)
+		return
+	}
+
+	response.Created(c, skill)
+}
+
+// UpdateSkill 更新技能
+// PUT /api/v1/skills/:id
+func (h *SkillHandler) UpdateSkill(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		response.BadRequest(c, 10001, "技能ID不能为空")
+		return
+	}
+
+	var req dto.UpdateSkillRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, 10001, "参数校验失败")
+		return
+	}
+
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	skill, err := h.skillSvc.Update(c.Request.Context(), id, &req, callerID)
+	if err != nil {
+		h.handleSkillError(c, err)
+		return
+	}
+
+	response.OK(c, skill)
+}
+
+// DeleteSkill 删除技能
+// DELETE /api/v1/skills/:id
+func (h *SkillHandler) DeleteSkill(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		response.BadRequest(c, 10001, "技能ID不能为空")
+		return
+	}
+
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.skillSvc.Delete(c.Request.Context(), id, callerID); err != nil {
+		h.handleSkillError(c, err)
+		return
+	}
+
+	response.OK(c, nil)
+}
+
+// handleSkillError 统一处理技能模块业务错误
+func (h *SkillHandler) handleSkillError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrSkillNotFound):
+		response.NotFound(c, 18201, "技能不存在")
+	case errors.Is(err, service.ErrSkillNameExists):
+		response.BadRequest(c, 18202, "技能名称已存在")
+	default:
+		response.InternalError(c)
+	}
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/service"
+	"echo-union/backend/pkg/response"
+)
+
+// StatisticsHandler 统计模块 HTTP 处理器
+type StatisticsHandler struct {
+	statsSvc service.StatisticsService
+}
+
+// NewStatisticsHandler 创建 StatisticsHandler
+func NewStatisticsHandler(statsSvc service.StatisticsService) *StatisticsHandler {
+	return &StatisticsHandler{statsSvc: statsSvc}
+}
+
+// GetAttendance 成员与部门出勤统计（leader 仅本部门）
+// GET /api/v1/statistics/attendance?semester_id=&from=&to=&department_id=
+func (h *StatisticsHandler) GetAttendance(c *gin.Context) {
+	var req dto.AttendanceStatsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, 10001, "参数校验失败")
+		return
+	}
+
+	callerRole, ok := MustGetRole(c)
+	if !ok {
+		return
+	}
+	callerDeptID, ok := MustGetDepartmentID(c)
+	if !ok {
+		return
+	}
+
+	stats, err := h.statsSvc.Attendance(c.Request.Context(), &req, callerRole, callerDeptID)
+	if err != nil {
+		handleStatisticsError(c, err)
+		return
+	}
+
+	response.OK(c, stats)
+}
+
+// handleStatisticsError 统计错误映射（出勤统计导出共用）
+func handleStatisticsError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrStatsRangeInvalid):
+		response.BadRequest(c, 24001, "统计区间无效：from 与 to 须同时提供且 from 不晚于 to")
+	case errors.Is(err, service.ErrStatsNoSemester):
+		response.BadRequest(c, 24002, "当前无进行中的学期，请指定 semester_id 或统计区间")
+	case errors.Is(err, service.ErrSemesterNotFound):
+		response.NotFound(c, 24003, "学期不存在")
+	default:
+		response.InternalError(c)
+	}
+}
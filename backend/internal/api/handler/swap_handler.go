@@ -0,0 +1,348 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/model"
+	"echo-union/backend/internal/service"
+	"echo-union/backend/pkg/response"
+)
+
+// SwapHandler 换班 HTTP 处理器
+type SwapHandler struct {
+	swapSvc service.SwapService
+}
+
+// NewSwapHandler 创建 SwapHandler
+func NewSwapHandler(swapSvc service.SwapService) *SwapHandler {
+	return &SwapHandler{swapSvc: swapSvc}
+}
+
+// CreateGiveAway 发布值班转让（某一具体日期的本人值班）
+// POST /api/v1/swaps/give-aways
+func (h *SwapHandler) CreateGiveAway(c *gin.Context) {
+	var req dto.CreateGiveAwayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, 10001, "参数校验失败")
+		return
+	}
+
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	swap, err := h.swapSvc.CreateGiveAway(c.Request.Context(), &req, callerID)
+	if err != nil {
+		h.handleSwapError(c, err)
+		return
+	}
+
+	response.Created(c, swap)
+}
+
+// ListGiveAwayFeed 当前成员可认领的值班转让
+// GET /api/v1/swaps/give-aways
+func (h *SwapHandler) ListGiveAwayFeed(c *gin.Context) {
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	swaps, err := h.swapSvc.ListGiveAwayFeed(c.Request.Context(), callerID)
+	if err != nil {
+		h.handleSwapError(c, err)
+		return
+	}
+
+	response.OK(c, gin.H{"list": swaps})
+}
+
+// ClaimGiveAway 认领值班转让
+// POST /api/v1/swaps/give-aways/:id/claim
+func (h *SwapHandler) ClaimGiveAway(c *gin.Context) {
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	swap, err := h.swapSvc.ClaimGiveAway(c.Request.Context(), c.Param("id"), callerID)
+	if err != nil {
+		h.handleSwapError(c, err)
+		return
+	}
+
+	response.OK(c, swap)
+}
+
+// CreateExchange 发起双向换班（互换排班项或两次具体日期的值班）
+// POST /api/v1/swaps/exchanges
+func (h *SwapHandler) CreateExchange(c *gin.Context) {
+	var req dto.CreateExchangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, 10001, "参数校验失败")
+		return
+	}
+
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	swap, err := h.swapSvc.CreateExchange(c.Request.Context(), &req, callerID)
+	if err != nil {
+		h.handleSwapError(c, err)
+		return
+	}
+
+	response.Created(c, swap)
+}
+
+// RespondSwap 被邀请成员接受 / 拒绝双向换班
+// PUT /api/v1/swaps/:id/respond
+func (h *SwapHandler) RespondSwap(c *gin.Context) {
+	var req dto.RespondSwapRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, 10001, "参数校验失败")
+		return
+	}
+
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	swap, err := h.swapSvc.Respond(c.Request.Context(), c.Param("id"), &req, callerID)
+	if err != nil {
+		h.handleSwapError(c, err)
+		return
+	}
+
+	response.OK(c, swap)
+}
+
+// SuggestSwapCandidates 换班候选人推荐
+// GET /api/v1/swaps/suggestions?item_id= | ?duty_record_id=
+func (h *SwapHandler) SuggestSwapCandidates(c *gin.Context) {
+	var req dto.SwapSuggestionRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, 10001, "参数校验失败")
+		return
+	}
+
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+	role, ok := MustGetRole(c)
+	if !ok {
+		return
+	}
+
+	suggestions, err := h.swapSvc.Suggest(c.Request.Context(), &req, callerID, role == model.RoleAdmin)
+	if err != nil {
+		h.handleSwapError(c, err)
+		return
+	}
+
+	response.OK(c, gin.H{"list": suggestions})
+}
+
+// GetSwap 换班申请详情
+// GET /api/v1/swaps/:id
+func (h *SwapHandler) GetSwap(c *gin.Context) {
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+	role, ok := MustGetRole(c)
+	if !ok {
+		return
+	}
+
+	swap, err := h.swapSvc.GetByID(c.Request.Context(), c.Param("id"), callerID, role == model.RoleAdmin)
+	if err != nil {
+		h.handleSwapError(c, err)
+		return
+	}
+
+	response.OK(c, swap)
+}
+
+// ListMySwaps 我发起或接手的换班申请
+// GET /api/v1/swaps/me
+func (h *SwapHandler) ListMySwaps(c *gin.Context) {
+	var req dto.SwapListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, 10001, "参数校验失败")
+		return
+	}
+
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	swaps, total, err := h.swapSvc.ListMine(c.Request.Context(), &req, callerID)
+	if err != nil {
+		h.handleSwapError(c, err)
+		return
+	}
+
+	response.OKPage(c, swaps, total, req.GetPage(), req.GetPageSize())
+}
+
+// ListPendingSwaps 待审批的换班申请
+// GET /api/v1/swaps/pending
+func (h *SwapHandler) ListPendingSwaps(c *gin.Context) {
+	var req dto.SwapListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, 10001, "参数校验失败")
+		return
+	}
+
+	swaps, total, err := h.swapSvc.ListPending(c.Request.Context(), &req)
+	if err != nil {
+		h.handleSwapError(c, err)
+		return
+	}
+
+	response.OKPage(c, swaps, total, req.GetPage(), req.GetPageSize())
+}
+
+// ReviewSwap 管理员审批（通过 / 驳回）
+// PUT /api/v1/swaps/:id/approve
+func (h *SwapHandler) ReviewSwap(c *gin.Context) {
+	var req dto.ReviewSwapRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, 10001, "参数校验失败")
+		return
+	}
+
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	swap, err := h.swapSvc.Review(c.Request.Context(), c.Param("id"), &req, callerID)
+	if err != nil {
+		h.handleSwapError(c, err)
+		return
+	}
+
+	response.OK(c, swap)
+}
+
+// CancelSwap 申请人撤回进行中的申请
+// POST /api/v1/swaps/:id/cancel
+func (h *SwapHandler) CancelSwap(c *gin.Context) {
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.swapSvc.Cancel(c.Request.Context(), c.Param("id"), callerID); err != nil {
+		h.handleSwapError(c, err)
+		return
+	}
+
+	response.OK(c, nil)
+}
+
+// ListSwapPolicies 换班自动审批策略（全局与各部门覆盖）
+// GET /api/v1/swaps/policies
+func (h *SwapHandler) ListSwapPolicies(c *gin.Context) {
+	policies, err := h.swapSvc.ListPolicies(c.Request.Context())
+	if err != nil {
+		h.handleSwapError(c, err)
+		return
+	}
+
+	response.OK(c, policies)
+}
+
+// SetDepartmentSwapPolicy 设置部门换班自动审批策略（整体覆盖全局策略）
+// PUT /api/v1/swaps/policies/departments/:department_id
+func (h *SwapHandler) SetDepartmentSwapPolicy(c *gin.Context) {
+	var req dto.SetSwapPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, 10001, "参数校验失败")
+		return
+	}
+
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+
+	policy, err := h.swapSvc.SetDepartmentPolicy(c.Request.Context(), c.Param("department_id"), &req, callerID)
+	if err != nil {
+		h.handleSwapError(c, err)
+		return
+	}
+
+	response.OK(c, policy)
+}
+
+// DeleteDepartmentSwapPolicy 删除部门换班策略，恢复使用全局策略
+// DELETE /api/v1/swaps/policies/departments/:department_id
+func (h *SwapHandler) DeleteDepartmentSwapPolicy(c *gin.Context) {
+	if err := h.swapSvc.DeleteDepartmentPolicy(c.Request.Context(), c.Param("department_id")); err != nil {
+		h.handleSwapError(c, err)
+		return
+	}
+
+	response.OK(c, nil)
+}
+
+// handleSwapError 统一处理换班模块业务错误
+func (h *SwapHandler) handleSwapError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrSwapNotFound):
+		response.NotFound(c, 21001, "换班申请不存在")
+	case errors.Is(err, service.ErrSwapDutyRecordNotFound):
+		response.NotFound(c, 21002, "值班记录不存在")
+	case errors.Is(err, service.ErrSwapNotOwner):
+		response.Forbidden(c, 21003, "只能转让本人的值班")
+	case errors.Is(err, service.ErrSwapDutyNotTransferable):
+		response.BadRequest(c, 21004, "该次值班不可转让")
+	case errors.Is(err, service.ErrSwapDeadlinePassed):
+		response.BadRequest(c, 21005, "已超过换班截止时间")
+	case errors.Is(err, service.ErrSwapAlreadyOpen):
+		response.Error(c, http.StatusConflict, 21006, "该次值班已有进行中的换班申请")
+	case errors.Is(err, service.ErrSwapNotClaimable):
+		response.Error(c, http.StatusConflict, 21007, "该转让已被认领或已关闭")
+	case errors.Is(err, service.ErrSwapSelfClaim):
+		response.BadRequest(c, 21008, "不能认领自己发布的转让")
+	case errors.Is(err, service.ErrSwapMemberUnavailable):
+		response.ErrorWithDetails(c, http.StatusBadRequest, 21009, "成员在该次值班时段不可用", err.Error())
+	case errors.Is(err, service.ErrSwapNotReviewing):
+		response.BadRequest(c, 21010, "申请不在待审批状态")
+	case errors.Is(err, service.ErrSwapNotCancellable):
+		response.BadRequest(c, 21011, "申请已结束，无法撤回")
+	case errors.Is(err, service.ErrSwapForbidden):
+		response.Forbidden(c, 21012, "无权操作该换班申请")
+	case errors.Is(err, service.ErrSwapExchangeInvalid):
+		response.BadRequest(c, 21013, "换班双方须为同一已发布排班中本人与他人的值班")
+	case errors.Is(err, service.ErrSwapScheduleItemNotFound):
+		response.NotFound(c, 21014, "排班项不存在")
+	case errors.Is(err, service.ErrSwapNotPending):
+		response.BadRequest(c, 21015, "申请不在待响应状态")
+	case errors.Is(err, service.ErrSwapOutdated):
+		response.Error(c, http.StatusConflict, 21016, "值班安排已变更，申请已失效")
+	case errors.Is(err, service.ErrSwapSuggestionTarget):
+		response.BadRequest(c, 21017, "须指定 item_id 或 duty_record_id 之一")
+	case errors.Is(err, service.ErrSwapPolicyDepartmentNotFound):
+		response.NotFound(c, 21018, "部门不存在")
+	case errors.Is(err, service.ErrSwapPolicyNotFound):
+		response.NotFound(c, 21019, "该部门未设置独立的换班策略")
+	case errors.Is(err, service.ErrSwapLeavePending):
+		response.Error(c, http.StatusConflict, 21020, "该次值班已有待审批的请假")
+	default:
+		response.InternalError(c)
+	}
+}
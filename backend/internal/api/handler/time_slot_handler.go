@@ -137,6 +137,12 @@ func (h *TimeSlotHandler) handleTimeSlotError(c *gin.Context, err error) {
 		response.NotFound(c, 15001, "时间段不存在")
 	case errors.Is(err, service.ErrSemesterNotFound):
 		response.BadRequest(c, 15002, "关联的学期不存在")
+	case errors.Is(err, service.ErrLocationNotFound):
+		response.BadRequest(c, 16003, "关联的地点不存在")
+	case errors.Is(err, service.ErrTimeSlotLocationClosed):
+		response.BadRequest(c, 16004, "绑定地点已停用或在该时段未开放")
+	case errors.Is(err, service.ErrSkillNotFound):
+		response.BadRequest(c, 18201, "技能不存在")
 	default:
 		response.InternalError(c)
 	}
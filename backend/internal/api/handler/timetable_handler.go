@@ -238,6 +238,8 @@ func (h *TimetableHandler) handleTimetableError(c *gin.Context, err error) {
 		response.ErrorWithDetails(c, http.StatusForbidden, 15009, "无权操作", err.Error())
 	case errors.Is(err, service.ErrTimetableDepartmentNotFound):
 		response.NotFound(c, 15010, err.Error())
+	case errors.Is(err, service.ErrTimetableConflictsPublished):
+		response.ErrorWithDetails(c, http.StatusConflict, 15011, "时间表变更与已发布排班冲突，请先发起换班", err.Error())
 	default:
 		response.InternalError(c)
 	}
@@ -144,6 +144,43 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 	response.OK(c, user)
 }
 
+// SetUserSkills 设置成员技能（全量替换）
+// PUT /api/v1/users/:id/skills
+func (h *UserHandler) SetUserSkills(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		response.BadRequest(c, 10001, "用户ID不能为空")
+		return
+	}
+
+	var req dto.SetUserSkillsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, 10001, "参数校验失败")
+		return
+	}
+
+	callerID, ok := MustGetUserID(c)
+	if !ok {
+		return
+	}
+	callerRole, ok := MustGetRole(c)
+	if !ok {
+		return
+	}
+	callerDeptID, ok := MustGetDepartmentID(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.userSvc.SetSkills(c.Request.Context(), id, &req, callerID, callerRole, callerDeptID)
+	if err != nil {
+		h.handleUserError(c, err)
+		return
+	}
+
+	response.OK(c, user)
+}
+
 // DeleteUser 删除用户（软删除）
 // DELETE /api/v1/users/:id
 func (h *UserHandler) DeleteUser(c *gin.Context) {
@@ -278,6 +315,8 @@ func (h *UserHandler) handleUserError(c *gin.Context, err error) {
 		response.BadRequest(c, 12005, "部门不存在")
 	case errors.Is(err, service.ErrStudentIDExists):
 		response.BadRequest(c, 12006, "学号已被使用")
+	case errors.Is(err, service.ErrSkillNotFound):
+		response.BadRequest(c, 18201, "技能不存在")
 	case errors.Is(err, service.ErrNoPermission):
 		response.Forbidden(c, 10003, "无权操作")
 	default:
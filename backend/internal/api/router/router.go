@@ -19,6 +19,10 @@ func Setup(cfg *config.Config, h *handler.Handler, jwtMgr *jwt.Manager, rdb *red
 	gin.SetMode(gin.ReleaseMode)
 
 	r := gin.New()
+	// 签到网段校验依赖 ClientIP，只采信可信代理转发的 X-Forwarded-For
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		logger.Error("设置可信代理失败", zap.Error(err))
+	}
 
 	// ── 全局中间件 ──
 	r.Use(gin.Recovery())
@@ -80,6 +84,10 @@ func Setup(cfg *config.Config, h *handler.Handler, jwtMgr *jwt.Manager, rdb *red
 			auth.POST("/refresh", middleware.RateLimit(rdb, 20, time.Minute), h.Auth.RefreshToken)
 		}
 
+		// 紧急替班一键接班链接（无需认证，凭链接签名校验）：GET 仅查看详情 / 确认页面，POST 确认接班
+		v1.GET("/duties/emergencies/:id/cover-link", middleware.RateLimit(rdb, 20, time.Minute), h.Duty.PreviewEmergencyByLink)
+		v1.POST("/duties/emergencies/:id/cover-link", middleware.RateLimit(rdb, 20, time.Minute), h.Duty.CoverEmergencyByLink)
+
 		// 需要认证的路由
 		authorized := v1.Group("")
 		authorized.Use(middleware.JWTAuth(jwtMgr, rdb))
@@ -100,6 +108,7 @@ func Setup(cfg *config.Config, h *handler.Handler, jwtMgr *jwt.Manager, rdb *red
 				users.DELETE("/:id", middleware.RoleAuth("admin"), h.User.DeleteUser)
 				users.PUT("/:id/role", middleware.RoleAuth("admin"), h.User.AssignRole)
 				users.POST("/:id/reset-password", middleware.RoleAuth("admin"), h.User.ResetPassword)
+				users.PUT("/:id/skills", middleware.RoleAuth("admin", "leader"), h.User.SetUserSkills) // leader 仅限本部门（Service 层鉴权）
 				users.POST("/import", middleware.RoleAuth("admin"), h.User.ImportUsers)
 			}
 
@@ -131,10 +140,23 @@ func Setup(cfg *config.Config, h *handler.Handler, jwtMgr *jwt.Manager, rdb *red
 				// 值班人员管理（学期维度）
 				semesters.GET("/:id/duty-members", middleware.RoleAuth("admin", "leader"), h.Semester.GetDutyMembers)
 				semesters.PUT("/:id/duty-members", middleware.RoleAuth("admin"), h.Semester.SetDutyMembers)
+				// 校历（放假 / 调休上班）
+				semesters.GET("/:id/calendar", h.Calendar.ListDays)
+				semesters.GET("/:id/calendar/resolve", h.Calendar.ResolveDays)
+				semesters.POST("/:id/calendar", middleware.RoleAuth("admin"), h.Calendar.UpsertDay)
+				semesters.POST("/:id/calendar/import", middleware.RoleAuth("admin"), h.Calendar.ImportICS)
+				semesters.DELETE("/:id/calendar/:day_id", middleware.RoleAuth("admin"), h.Calendar.DeleteDay)
+				// 成员搭配约束（R7）
+				semesters.GET("/:id/pair-constraints", middleware.RoleAuth("admin"), h.PairConstraint.List)
+				semesters.POST("/:id/pair-constraints", middleware.RoleAuth("admin"), h.PairConstraint.Create)
+				semesters.DELETE("/:id/pair-constraints/:constraint_id", middleware.RoleAuth("admin"), h.PairConstraint.Delete)
 			}
 
 			// 待办通知
 			authorized.GET("/notifications/pending", h.Semester.GetPendingTodos)
+			authorized.GET("/notifications", h.Notification.ListNotifications)
+			authorized.PUT("/notifications/read-all", h.Notification.MarkAllRead)
+			authorized.PUT("/notifications/:id/read", h.Notification.MarkRead)
 
 			// 时间段模块
 			timeSlots := authorized.Group("/time-slots")
@@ -154,6 +176,16 @@ func Setup(cfg *config.Config, h *handler.Handler, jwtMgr *jwt.Manager, rdb *red
 				locations.POST("", middleware.RoleAuth("admin"), h.Location.CreateLocation)
 				locations.PUT("/:id", middleware.RoleAuth("admin"), h.Location.UpdateLocation)
 				locations.DELETE("/:id", middleware.RoleAuth("admin"), h.Location.DeleteLocation)
+				locations.GET("/:id/sign-in-qr", middleware.RoleAuth("admin", "leader"), h.Duty.GetLocationSignInQR)
+			}
+
+			// 技能模块
+			skills := authorized.Group("/skills")
+			{
+				skills.GET("", h.Skill.ListSkills)
+				skills.POST("", middleware.RoleAuth("admin"), h.Skill.CreateSkill)
+				skills.PUT("/:id", middleware.RoleAuth("admin"), h.Skill.UpdateSkill)
+				skills.DELETE("/:id", middleware.RoleAuth("admin"), h.Skill.DeleteSkill)
 			}
 
 			// 系统配置模块
@@ -188,21 +220,125 @@ func Setup(cfg *config.Config, h *handler.Handler, jwtMgr *jwt.Manager, rdb *red
 			schedules := authorized.Group("/schedules")
 			{
 				schedules.POST("/auto", middleware.RoleAuth("admin"), h.Schedule.AutoSchedule)
+				schedules.POST("/auto/jobs", middleware.RoleAuth("admin"), h.Schedule.StartAutoScheduleJob)
+				schedules.GET("/auto/jobs/:id", middleware.RoleAuth("admin"), h.Schedule.GetAutoScheduleJob)
+				schedules.GET("/auto/jobs/:id/events", middleware.RoleAuth("admin"), h.Schedule.StreamAutoScheduleJob)
+				schedules.POST("/auto/jobs/:id/cancel", middleware.RoleAuth("admin"), h.Schedule.CancelAutoScheduleJob)
+				schedules.POST("/simulate", middleware.RoleAuth("admin"), h.Schedule.SimulateSchedule)
 				schedules.GET("", h.Schedule.GetSchedule)
 				schedules.GET("/my", h.Schedule.GetMySchedule)
 				schedules.PUT("/items/:id", middleware.RoleAuth("admin"), h.Schedule.UpdateItem)
+				schedules.POST("/items/swap", middleware.RoleAuth("admin"), h.Schedule.SwapItems)
+				schedules.POST("/items/batch", middleware.RoleAuth("admin"), h.Schedule.BatchUpdateItems)
+				schedules.PUT("/items/:id/move", middleware.RoleAuth("admin"), h.Schedule.MoveItem)
 				schedules.POST("/items/:id/validate", middleware.RoleAuth("admin"), h.Schedule.ValidateCandidate)
 				schedules.GET("/items/:id/candidates", middleware.RoleAuth("admin"), h.Schedule.GetCandidates)
 				schedules.POST("/publish", middleware.RoleAuth("admin"), h.Schedule.Publish)
 				schedules.PUT("/published/items/:id", middleware.RoleAuth("admin"), h.Schedule.UpdatePublishedItem)
 				schedules.GET("/change-logs", middleware.RoleAuth("admin"), h.Schedule.ListChangeLogs)
 				schedules.POST("/:id/scope-check", middleware.RoleAuth("admin"), h.Schedule.CheckScope)
+				// 候选方案
+				schedules.GET("/candidates", middleware.RoleAuth("admin"), h.Schedule.ListCandidates)
+				schedules.GET("/compare", middleware.RoleAuth("admin"), h.Schedule.CompareSchedules)
+				schedules.GET("/:id/quality", middleware.RoleAuth("admin"), h.Schedule.GetQualityReport)
+				schedules.GET("/:id/workload", middleware.RoleAuth("admin"), h.Schedule.GetWorkload)
+				schedules.POST("/:id/promote", middleware.RoleAuth("admin"), h.Schedule.PromoteCandidate)
+				schedules.DELETE("/:id", middleware.RoleAuth("admin"), h.Schedule.DiscardCandidate)
+				// 版本历史
+				schedules.GET("/versions", middleware.RoleAuth("admin"), h.Schedule.ListVersions)
+				schedules.GET("/diff", middleware.RoleAuth("admin"), h.Schedule.DiffSchedules)
+				schedules.POST("/:id/restore", middleware.RoleAuth("admin"), h.Schedule.RestoreVersion)
+				// 排班解释
+				schedules.GET("/items/:id/explain", middleware.RoleAuth("admin"), h.Schedule.ExplainItem)
+				schedules.GET("/:id/slots/explain", middleware.RoleAuth("admin"), h.Schedule.ExplainSlot)
+				schedules.GET("/:id/substitutes", middleware.RoleAuth("admin"), h.Schedule.ListSubstituteNeeded)
+				// 发布后时间表变更冲突
+				schedules.GET("/conflicts", middleware.RoleAuth("admin"), h.Schedule.ListConflicts)
+				schedules.PUT("/conflicts/:id", middleware.RoleAuth("admin"), h.Schedule.ResolveConflict)
+			}
+
+			// 活动值班
+			events := authorized.Group("/events")
+			{
+				events.GET("", h.Event.ListEvents)
+				events.GET("/my", h.Event.ListMyShifts)
+				events.GET("/:id", h.Event.GetEvent)
+				events.POST("", middleware.RoleAuth("admin"), h.Event.CreateEvent)
+				events.PUT("/:id", middleware.RoleAuth("admin"), h.Event.UpdateEvent)
+				events.DELETE("/:id", middleware.RoleAuth("admin"), h.Event.DeleteEvent)
+				events.POST("/:id/publish", middleware.RoleAuth("admin"), h.Event.PublishEvent)
+				events.POST("/:id/cancel", middleware.RoleAuth("admin"), h.Event.CancelEvent)
+				events.POST("/:id/auto-assign", middleware.RoleAuth("admin"), h.Event.AutoAssign)
+				events.POST("/:id/shifts", middleware.RoleAuth("admin"), h.Event.CreateShift)
+				events.PUT("/shifts/:shift_id", middleware.RoleAuth("admin"), h.Event.UpdateShift)
+				events.DELETE("/shifts/:shift_id", middleware.RoleAuth("admin"), h.Event.DeleteShift)
+				events.POST("/shifts/:shift_id/signup", h.Event.SignUp)
+				events.DELETE("/shifts/:shift_id/signup", h.Event.Withdraw)
+				events.POST("/shifts/:shift_id/members", middleware.RoleAuth("admin"), h.Event.AssignMember)
+				events.DELETE("/shifts/:shift_id/members/:member_id", middleware.RoleAuth("admin"), h.Event.UnassignMember)
+			}
+
+			// 换班（值班转让）
+			swaps := authorized.Group("/swaps")
+			{
+				swaps.GET("/give-aways", h.Swap.ListGiveAwayFeed)
+				swaps.POST("/give-aways", h.Swap.CreateGiveAway)
+				swaps.POST("/give-aways/:id/claim", h.Swap.ClaimGiveAway)
+				swaps.POST("/exchanges", h.Swap.CreateExchange)
+				swaps.GET("/suggestions", h.Swap.SuggestSwapCandidates)
+				swaps.GET("/me", h.Swap.ListMySwaps)
+				swaps.GET("/pending", middleware.RoleAuth("admin"), h.Swap.ListPendingSwaps)
+				swaps.GET("/policies", middleware.RoleAuth("admin"), h.Swap.ListSwapPolicies)
+				swaps.PUT("/policies/departments/:department_id", middleware.RoleAuth("admin"), h.Swap.SetDepartmentSwapPolicy)
+				swaps.DELETE("/policies/departments/:department_id", middleware.RoleAuth("admin"), h.Swap.DeleteDepartmentSwapPolicy)
+				swaps.GET("/:id", h.Swap.GetSwap)
+				swaps.PUT("/:id/respond", h.Swap.RespondSwap)
+				swaps.PUT("/:id/approve", middleware.RoleAuth("admin"), h.Swap.ReviewSwap)
+				swaps.POST("/:id/cancel", h.Swap.CancelSwap)
+			}
+
+			// 请假（负责人 / 管理员审批并指定替班）
+			leaves := authorized.Group("/leaves")
+			{
+				leaves.POST("", h.Leave.CreateLeave)
+				leaves.GET("/me", h.Leave.ListMyLeaves)
+				leaves.GET("/review", middleware.RoleAuth("admin", "leader"), h.Leave.ListReviewLeaves)
+				leaves.GET("/stats", middleware.RoleAuth("admin", "leader"), h.Leave.GetLeaveStats)
+				leaves.GET("/:id", h.Leave.GetLeave)
+				leaves.GET("/:id/candidates", middleware.RoleAuth("admin", "leader"), h.Leave.ListLeaveCandidates)
+				leaves.PUT("/:id/review", middleware.RoleAuth("admin", "leader"), h.Leave.ReviewLeave)
+				leaves.POST("/:id/cancel", h.Leave.CancelLeave)
+			}
+
+			// 值班（签到签退、值班日志、缺勤标记与紧急替班）
+			duties := authorized.Group("/duties")
+			{
+				duties.POST("/records/:id/sign-in", h.Duty.SignIn)
+				duties.POST("/records/:id/sign-out", h.Duty.SignOut)
+				duties.PUT("/records/:id/network-override", middleware.RoleAuth("admin"), h.Duty.SetNetworkOverride)
+				duties.GET("/sign-in-rejections", middleware.RoleAuth("admin", "leader"), h.Duty.ListSignInRejections)
+				duties.PUT("/records/:id/report", h.Duty.SaveReport)
+				duties.GET("/records/:id/report", h.Duty.GetReport)
+				duties.GET("/records/:id/handover", h.Duty.GetHandover)
+				duties.GET("/reports", middleware.RoleAuth("admin", "leader"), h.Duty.ListReports)
+				duties.POST("/records/:id/absent", middleware.RoleAuth("admin", "leader"), h.Duty.MarkAbsent)
+				duties.GET("/emergencies/:id", h.Duty.GetEmergency)
+				duties.POST("/emergencies/:id/cover", h.Duty.CoverEmergency)
+				duties.POST("/emergencies/:id/cancel", middleware.RoleAuth("admin", "leader"), h.Duty.CancelEmergency)
+			}
+
+			// 统计模块（出勤统计）
+			statistics := authorized.Group("/statistics")
+			{
+				statistics.GET("/attendance", middleware.RoleAuth("admin", "leader"), h.Statistics.GetAttendance)
 			}
 
-			// 导出模块（一期：排班表导出；签到统计导出归入二期）
+			// 导出模块（排班表、活动值班安排、出勤统计）
 			export := authorized.Group("/export")
 			{
 				export.GET("/schedule", middleware.RoleAuth("admin", "leader"), h.Export.ExportSchedule)
+				export.GET("/event", middleware.RoleAuth("admin", "leader"), h.Export.ExportEvent)
+				export.GET("/attendance", middleware.RoleAuth("admin", "leader"), h.Export.ExportAttendance)
 			}
 		}
 	}
@@ -0,0 +1,137 @@
+package dto
+
+// ── 值班记录 DTO ──
+
+// DutyRecordResponse 值班记录（具体日期的一次值班）响应
+type DutyRecordResponse struct {
+	ID               string         `json:"id"`
+	ScheduleItemID   *string        `json:"schedule_item_id,omitempty"`
+	EventShiftID     *string        `json:"event_shift_id,omitempty"`
+	DutyDate         string         `json:"duty_date"`
+	Status           string         `json:"status"`
+	NeedsSubstitute  bool           `json:"needs_substitute"`
+	SubstituteReason string         `json:"substitute_reason,omitempty"`
+	SubstituteForID  *string        `json:"substitute_for_id,omitempty"` // 紧急替班记录对应的缺勤记录
+	SignInTime       *string        `json:"sign_in_time,omitempty"`
+	SignOutTime      *string        `json:"sign_out_time,omitempty"`
+	IsLate           bool           `json:"is_late"`
+	NetworkOverride  bool           `json:"network_override"` // 已豁免签到网段限制
+	TimeSlot         *TimeSlotBrief `json:"time_slot,omitempty"`
+	Location         *LocationBrief `json:"location,omitempty"`
+	Member           *MemberBrief   `json:"member,omitempty"`
+}
+
+// SignInRequest 签到请求
+type SignInRequest struct {
+	QRToken string `json:"qr_token" binding:"omitempty,max=200"` // 值班地点展示屏上的二维码内容，地点开启二维码签到时必填
+}
+
+// NetworkOverrideRequest 管理员设置 / 取消单条值班记录的签到网段豁免
+type NetworkOverrideRequest struct {
+	Enabled *bool  `json:"enabled" binding:"required"`
+	Reason  string `json:"reason"  binding:"omitempty,max=200"`
+}
+
+// SignInRejectionListRequest 签到拒绝审计查询参数
+type SignInRejectionListRequest struct {
+	MemberID string `form:"member_id" binding:"omitempty,uuid"`
+	Reason   string `form:"reason"    binding:"omitempty,oneof=network qr_invalid qr_wrong_location qr_used"`
+	From     string `form:"from"      binding:"omitempty,datetime=2006-01-02"` // 含
+	To       string `form:"to"        binding:"omitempty,datetime=2006-01-02"` // 含
+	PaginationRequest
+}
+
+// SignInRejectionResponse 被拒绝的签到 / 签退
+type SignInRejectionResponse struct {
+	ID         string             `json:"id"`
+	DutyRecord DutyRecordResponse `json:"duty_record"`
+	Member     *MemberBrief       `json:"member,omitempty"`
+	Location   *LocationBrief     `json:"location,omitempty"`
+	Action     string             `json:"action"` // sign_in | sign_out
+	Reason     string             `json:"reason"` // network | qr_invalid | qr_wrong_location | qr_used
+	ClientIP   string             `json:"client_ip"`
+	Detail     string             `json:"detail,omitempty"`
+	CreatedAt  string             `json:"created_at"`
+}
+
+// LocationSignInQRResponse 地点签到二维码（展示屏按 refresh_in 秒轮询刷新）
+type LocationSignInQRResponse struct {
+	LocationID   string `json:"location_id"`
+	LocationName string `json:"location_name"`
+	QRSignIn     bool   `json:"qr_sign_in"` // 未开启时签到不校验二维码
+	Token        string `json:"token"`      // 二维码内容
+	ExpiresAt    string `json:"expires_at"` // 当前时间窗口结束时刻（之后仍有一个窗口的宽限）
+	RefreshIn    int    `json:"refresh_in"` // 距下次刷新的秒数
+}
+
+// MarkAbsentRequest 标记缺勤请求
+type MarkAbsentRequest struct {
+	Escalate *bool `json:"escalate"` // 是否广播紧急替班，缺省按 system_config.emergency_substitute_enabled
+}
+
+// MarkAbsentResponse 标记缺勤响应
+type MarkAbsentResponse struct {
+	Record    DutyRecordResponse             `json:"record"`
+	Emergency *EmergencySubstitutionResponse `json:"emergency,omitempty"` // 已广播紧急替班时返回
+}
+
+// ── 紧急替班 DTO ──
+
+// CoverEmergencyByTokenRequest 一键接班链接参数（GET 查询参数；POST 确认时为表单或 JSON 请求体）
+type CoverEmergencyByTokenRequest struct {
+	MemberID string `form:"member" json:"member" binding:"required,uuid"`
+	Token    string `form:"token"  json:"token"  binding:"required"`
+}
+
+// EmergencySubstitutionResponse 紧急替班广播响应
+type EmergencySubstitutionResponse struct {
+	ID                 string             `json:"id"`
+	Status             string             `json:"status"` // open | filled | cancelled | expired
+	DutyRecord         DutyRecordResponse `json:"duty_record"`
+	NotifiedCount      int                `json:"notified_count"`
+	Substitute         *MemberBrief       `json:"substitute,omitempty"`
+	SubstituteRecordID *string            `json:"substitute_record_id,omitempty"`
+	ExpiresAt          string             `json:"expires_at"`
+	FilledAt           *string            `json:"filled_at,omitempty"`
+	CreatedAt          string             `json:"created_at"`
+}
+
+// ── 值班日志 DTO ──
+
+// SaveDutyReportRequest 填写 / 修改值班日志
+type SaveDutyReportRequest struct {
+	Content  string   `json:"content"  binding:"required,max=2000"`
+	Tags     []string `json:"tags"     binding:"omitempty,max=10,dive,max=20"`
+	Incident bool     `json:"incident"` // 异常标记，由未标记改为标记时通知管理员
+}
+
+// DutyReportListRequest 值班日志检索参数；from / to 未提供时按 semester_id 的起止日期
+type DutyReportListRequest struct {
+	SemesterID string `form:"semester_id" binding:"omitempty,uuid"`
+	From       string `form:"from"        binding:"omitempty,datetime=2006-01-02"` // 含
+	To         string `form:"to"          binding:"omitempty,datetime=2006-01-02"` // 含
+	MemberID   string `form:"member_id"   binding:"omitempty,uuid"`
+	LocationID string `form:"location_id" binding:"omitempty,uuid"`
+	Tag        string `form:"tag"         binding:"omitempty,max=20"`
+	Keyword    string `form:"keyword"     binding:"omitempty,max=50"`
+	Incident   *bool  `form:"incident"`
+	PaginationRequest
+}
+
+// DutyReportResponse 值班日志
+type DutyReportResponse struct {
+	ID         string             `json:"id"`
+	DutyRecord DutyRecordResponse `json:"duty_record"`
+	Member     *MemberBrief       `json:"member,omitempty"`
+	Location   *LocationBrief     `json:"location,omitempty"`
+	Content    string             `json:"content"`
+	Tags       []string           `json:"tags"`
+	Incident   bool               `json:"incident"`
+	CreatedAt  string             `json:"created_at"`
+	UpdatedAt  string             `json:"updated_at"`
+}
+
+// DutyHandoverResponse 交接班：同一地点上一班次的值班日志，无日志时 report 为 null
+type DutyHandoverResponse struct {
+	Report *DutyReportResponse `json:"report"`
+}
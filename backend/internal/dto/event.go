@@ -0,0 +1,118 @@
+package dto
+
+// ── 活动值班 DTO ──
+
+// CreateEventRequest 创建活动请求
+type CreateEventRequest struct {
+	SemesterID  string `json:"semester_id" binding:"required,uuid"`
+	Name        string `json:"name"        binding:"required,min=2,max=100"`
+	Description string `json:"description" binding:"omitempty,max=500"`
+	SignupOpen  bool   `json:"signup_open"`
+}
+
+// UpdateEventRequest 更新活动请求
+type UpdateEventRequest struct {
+	Name        *string `json:"name"        binding:"omitempty,min=2,max=100"`
+	Description *string `json:"description" binding:"omitempty,max=500"`
+	SignupOpen  *bool   `json:"signup_open"`
+}
+
+// EventListRequest 活动列表查询参数
+type EventListRequest struct {
+	SemesterID string `form:"semester_id" binding:"omitempty,uuid"`
+	Status     string `form:"status"      binding:"omitempty,oneof=draft published cancelled"`
+}
+
+// CreateEventShiftRequest 创建活动班次请求
+type CreateEventShiftRequest struct {
+	ShiftDate  string  `json:"shift_date"  binding:"required"` // "2026-09-20"
+	StartTime  string  `json:"start_time"  binding:"required"` // "18:30"
+	EndTime    string  `json:"end_time"    binding:"required"` // "21:00"
+	LocationID *string `json:"location_id" binding:"omitempty,uuid"`
+	Headcount  int     `json:"headcount"   binding:"required,min=1,max=100"`
+	Note       string  `json:"note"        binding:"omitempty,max=200"`
+	// SkillIDs 班次人员须具备的全部技能（可选）
+	SkillIDs []string `json:"skill_ids" binding:"omitempty,dive,uuid"`
+}
+
+// UpdateEventShiftRequest 更新活动班次请求（日期与时间仅草稿活动可改）
+type UpdateEventShiftRequest struct {
+	ShiftDate  *string `json:"shift_date"`
+	StartTime  *string `json:"start_time"`
+	EndTime    *string `json:"end_time"`
+	LocationID *string `json:"location_id" binding:"omitempty,uuid"`
+	Headcount  *int    `json:"headcount"   binding:"omitempty,min=1,max=100"`
+	Note       *string `json:"note"        binding:"omitempty,max=200"`
+	// SkillIDs 所需技能（全量替换，空数组表示清空，不传则不修改）
+	SkillIDs *[]string `json:"skill_ids" binding:"omitempty,dive,uuid"`
+}
+
+// AssignEventMemberRequest 管理员指派班次人员请求
+type AssignEventMemberRequest struct {
+	MemberID string `json:"member_id" binding:"required,uuid"`
+}
+
+// EventResponse 活动响应
+type EventResponse struct {
+	ID          string `json:"id"`
+	SemesterID  string `json:"semester_id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Status      string `json:"status"`
+	SignupOpen  bool   `json:"signup_open"`
+	CreatedAt   string `json:"created_at"`
+	UpdatedAt   string `json:"updated_at"`
+}
+
+// EventDetailResponse 活动详情（含班次与人员）
+type EventDetailResponse struct {
+	EventResponse
+	Shifts []EventShiftResponse `json:"shifts"`
+}
+
+// EventShiftResponse 活动班次响应
+type EventShiftResponse struct {
+	ID             string                    `json:"id"`
+	EventID        string                    `json:"event_id"`
+	ShiftDate      string                    `json:"shift_date"`
+	StartTime      string                    `json:"start_time"`
+	EndTime        string                    `json:"end_time"`
+	Location       *LocationBrief            `json:"location,omitempty"`
+	Headcount      int                       `json:"headcount"`
+	Note           string                    `json:"note,omitempty"`
+	RequiredSkills []SkillBrief              `json:"required_skills"`
+	Members        []EventAssignmentResponse `json:"members"`
+}
+
+// EventAssignmentResponse 班次人员响应
+type EventAssignmentResponse struct {
+	Member *MemberBrief `json:"member"`
+	Source string       `json:"source"`
+}
+
+// MyEventShiftResponse 我的活动班次响应
+type MyEventShiftResponse struct {
+	EventID   string         `json:"event_id"`
+	EventName string         `json:"event_name"`
+	ShiftID   string         `json:"shift_id"`
+	ShiftDate string         `json:"shift_date"`
+	StartTime string         `json:"start_time"`
+	EndTime   string         `json:"end_time"`
+	Location  *LocationBrief `json:"location,omitempty"`
+	Source    string         `json:"source"`
+}
+
+// EventAutoAssignResponse 自动分配结果
+type EventAutoAssignResponse struct {
+	Assigned int                  `json:"assigned"`
+	Unfilled []UnfilledEventShift `json:"unfilled"`
+}
+
+// UnfilledEventShift 自动分配后仍未满员的班次
+type UnfilledEventShift struct {
+	ShiftID   string `json:"shift_id"`
+	ShiftDate string `json:"shift_date"`
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+	Missing   int    `json:"missing"`
+}
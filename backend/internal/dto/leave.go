@@ -0,0 +1,61 @@
+package dto
+
+// ── 请假模块 DTO ──
+
+// CreateLeaveRequest 请假请求（某一具体日期的本人值班）
+type CreateLeaveRequest struct {
+	DutyRecordID  string `json:"duty_record_id" binding:"required,uuid"`
+	Reason        string `json:"reason"         binding:"required,max=500"`
+	AttachmentRef string `json:"attachment_ref" binding:"omitempty,max=500"` // 证明材料引用（文件地址 / 编号）
+}
+
+// ReviewLeaveRequest 审批请假请求
+type ReviewLeaveRequest struct {
+	Approve      bool   `json:"approve"`
+	SubstituteID string `json:"substitute_id" binding:"omitempty,uuid"` // 通过时必填，须为候选人之一
+	Note         string `json:"note"          binding:"omitempty,max=500"`
+}
+
+// LeaveListRequest 请假列表查询参数
+type LeaveListRequest struct {
+	Status     string `form:"status"      binding:"omitempty,oneof=pending approved rejected cancelled"`
+	SemesterID string `form:"semester_id" binding:"omitempty,uuid"`
+	PaginationRequest
+}
+
+// LeaveResponse 请假响应
+type LeaveResponse struct {
+	ID            string              `json:"id"`
+	Status        string              `json:"status"`
+	SemesterID    string              `json:"semester_id"`
+	DutyRecord    *DutyRecordResponse `json:"duty_record,omitempty"`
+	Member        *MemberBrief        `json:"member,omitempty"`
+	Substitute    *MemberBrief        `json:"substitute,omitempty"`
+	Reason        string              `json:"reason"`
+	AttachmentRef string              `json:"attachment_ref,omitempty"`
+	ReviewNote    string              `json:"review_note,omitempty"`
+	ReviewedAt    *string             `json:"reviewed_at,omitempty"`
+	CreatedAt     string              `json:"created_at"`
+}
+
+// LeaveCandidateResponse 替班候选人
+type LeaveCandidateResponse struct {
+	Member     *MemberBrief `json:"member"`
+	ShiftCount int          `json:"shift_count"` // 当前周常班次数
+	Load       float64      `json:"load"`        // 当前周常值班加权成本（时长 × 冷门系数）
+}
+
+// LeaveStatsRequest 请假统计查询参数
+type LeaveStatsRequest struct {
+	SemesterID string `form:"semester_id" binding:"required,uuid"`
+}
+
+// LeaveStatsResponse 成员学期请假次数
+type LeaveStatsResponse struct {
+	Member    *MemberBrief `json:"member"`
+	Total     int64        `json:"total"` // 不含已撤回
+	Pending   int64        `json:"pending"`
+	Approved  int64        `json:"approved"`
+	Rejected  int64        `json:"rejected"`
+	Cancelled int64        `json:"cancelled"`
+}
@@ -2,11 +2,32 @@ package dto
 
 // ── 地点模块 DTO ──
 
+// LocationWindowRequest 地点开放时段
+type LocationWindowRequest struct {
+	DayOfWeek int    `json:"day_of_week" binding:"required,min=1,max=7"`
+	StartTime string `json:"start_time"  binding:"required"` // "08:00"
+	EndTime   string `json:"end_time"    binding:"required"` // "22:00"
+}
+
+// LocationNetworkRequest 地点签到网段
+type LocationNetworkRequest struct {
+	CIDR string `json:"cidr" binding:"required,max=50"` // "10.20.0.0/16"，单个 IP 视为 /32（IPv6 为 /128）
+	Note string `json:"note" binding:"omitempty,max=100"`
+}
+
 // CreateLocationRequest 创建地点请求
 type CreateLocationRequest struct {
 	Name      string `json:"name"       binding:"required,min=2,max=100"`
 	Address   string `json:"address"    binding:"omitempty,max=200"`
 	IsDefault bool   `json:"is_default"`
+	// Capacity 同一时刻可容纳的值班人数（默认 1）
+	Capacity *int `json:"capacity" binding:"omitempty,min=1,max=50"`
+	// QRSignIn 签到是否须扫描本地点的轮换二维码（默认 true）
+	QRSignIn *bool `json:"qr_sign_in"`
+	// Windows 开放时段（可选，不传表示全天开放）
+	Windows []LocationWindowRequest `json:"windows" binding:"omitempty,max=50,dive"`
+	// Networks 允许签到 / 签退的客户端网段（可选，不传表示不限制）
+	Networks []LocationNetworkRequest `json:"networks" binding:"omitempty,max=20,dive"`
 }
 
 // UpdateLocationRequest 更新地点请求
@@ -15,6 +36,12 @@ type UpdateLocationRequest struct {
 	Address   *string `json:"address"    binding:"omitempty,max=200"`
 	IsDefault *bool   `json:"is_default"`
 	IsActive  *bool   `json:"is_active"`
+	Capacity  *int    `json:"capacity"   binding:"omitempty,min=1,max=50"`
+	QRSignIn  *bool   `json:"qr_sign_in"`
+	// Windows 开放时段（全量替换，空数组表示全天开放，不传则不修改）
+	Windows *[]LocationWindowRequest `json:"windows" binding:"omitempty,max=50,dive"`
+	// Networks 签到网段（全量替换，空数组表示不限制，不传则不修改）
+	Networks *[]LocationNetworkRequest `json:"networks" binding:"omitempty,max=20,dive"`
 }
 
 // LocationListRequest 地点列表查询参数
@@ -24,11 +51,28 @@ type LocationListRequest struct {
 
 // LocationResponse 地点信息响应
 type LocationResponse struct {
-	ID        string `json:"id"`
-	Name      string `json:"name"`
-	Address   string `json:"address,omitempty"`
-	IsDefault bool   `json:"is_default"`
-	IsActive  bool   `json:"is_active"`
-	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
+	ID        string                    `json:"id"`
+	Name      string                    `json:"name"`
+	Address   string                    `json:"address,omitempty"`
+	IsDefault bool                      `json:"is_default"`
+	IsActive  bool                      `json:"is_active"`
+	Capacity  int                       `json:"capacity"`
+	QRSignIn  bool                      `json:"qr_sign_in"`
+	Windows   []LocationWindowResponse  `json:"windows"`  // 为空表示全天开放
+	Networks  []LocationNetworkResponse `json:"networks"` // 为空表示不限制签到网络
+	CreatedAt string                    `json:"created_at"`
+	UpdatedAt string                    `json:"updated_at"`
+}
+
+// LocationWindowResponse 地点开放时段响应
+type LocationWindowResponse struct {
+	DayOfWeek int    `json:"day_of_week"`
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+}
+
+// LocationNetworkResponse 地点签到网段响应
+type LocationNetworkResponse struct {
+	CIDR string `json:"cidr"`
+	Note string `json:"note,omitempty"`
 }
@@ -20,6 +20,7 @@ type UserResponse struct {
 	StudentID          string              `json:"student_id"`
 	Role               string              `json:"role"`
 	Department         *DepartmentResponse `json:"department,omitempty"`
+	Skills             []SkillBrief        `json:"skills"`
 	MustChangePassword bool                `json:"must_change_password"`
 }
 
@@ -4,7 +4,12 @@ package dto
 
 // AutoScheduleRequest 自动排班请求
 type AutoScheduleRequest struct {
-	SemesterID string `json:"semester_id" binding:"required,uuid"`
+	SemesterID    string          `json:"semester_id"    binding:"required,uuid"`
+	Solver        string          `json:"solver"         binding:"omitempty,oneof=greedy local_search"`
+	Seed          *int64          `json:"seed"`           // 随机种子；为空时按姓名打破平局
+	RuleOverrides map[string]bool `json:"rule_overrides"` // 本次生成临时覆盖的规则启用状态，如 {"R3": false}
+	AsCandidate   bool            `json:"as_candidate"`   // true 时生成候选方案，不替换当前草稿
+	Name          string          `json:"name"           binding:"omitempty,max=100"`
 }
 
 // UpdateScheduleItemRequest 手动调整排班项请求
@@ -29,6 +34,42 @@ type ValidateCandidateRequest struct {
 	MemberID string `json:"member_id" binding:"required,uuid"`
 }
 
+// SwapScheduleItemsRequest 原子交换两个排班项成员请求
+type SwapScheduleItemsRequest struct {
+	ItemAID string `json:"item_a_id" binding:"required,uuid"`
+	ItemBID string `json:"item_b_id" binding:"required,uuid"`
+}
+
+// MoveScheduleItemRequest 将排班项移动到另一时段请求（目标时段须为空）
+type MoveScheduleItemRequest struct {
+	WeekNumber int    `json:"week_number"  binding:"required,oneof=1 2"`
+	TimeSlotID string `json:"time_slot_id" binding:"required,uuid"`
+}
+
+// ScheduleItemEdit 批量调整中的单项修改
+type ScheduleItemEdit struct {
+	ItemID     string  `json:"item_id"     binding:"required,uuid"`
+	MemberID   *string `json:"member_id"   binding:"omitempty,uuid"`
+	LocationID *string `json:"location_id" binding:"omitempty,uuid"`
+}
+
+// BatchUpdateScheduleItemsRequest 批量调整排班项请求（单事务，按最终状态校验）
+type BatchUpdateScheduleItemsRequest struct {
+	Edits []ScheduleItemEdit `json:"edits" binding:"required,min=1,max=200,dive"`
+}
+
+// DiffSchedulesRequest 排班版本差异查询参数
+type DiffSchedulesRequest struct {
+	BaseID   string `form:"base_id"   binding:"required,uuid"`
+	TargetID string `form:"target_id" binding:"required,uuid"`
+}
+
+// ExplainSlotRequest 槽位排班解释查询参数
+type ExplainSlotRequest struct {
+	WeekNumber int    `form:"week_number"  binding:"required,oneof=1 2"`
+	TimeSlotID string `form:"time_slot_id" binding:"required,uuid"`
+}
+
 // ScheduleChangeLogListRequest 变更日志列表查询参数
 type ScheduleChangeLogListRequest struct {
 	ScheduleID string `form:"schedule_id" binding:"required,uuid"`
@@ -39,14 +80,19 @@ type ScheduleChangeLogListRequest struct {
 
 // ScheduleResponse 排班表响应
 type ScheduleResponse struct {
-	ID          string                 `json:"id"`
-	SemesterID  string                 `json:"semester_id"`
-	Semester    *SemesterBrief         `json:"semester,omitempty"`
-	Status      string                 `json:"status"`
-	PublishedAt *string                `json:"published_at,omitempty"`
-	Items       []ScheduleItemResponse `json:"items,omitempty"`
-	CreatedAt   string                 `json:"created_at"`
-	UpdatedAt   string                 `json:"updated_at"`
+	ID             string                 `json:"id"`
+	SemesterID     string                 `json:"semester_id"`
+	Semester       *SemesterBrief         `json:"semester,omitempty"`
+	Status         string                 `json:"status"`
+	Name           string                 `json:"name,omitempty"`
+	Solver         string                 `json:"solver,omitempty"`
+	Seed           *int64                 `json:"seed,omitempty"`
+	RuleOverrides  map[string]bool        `json:"rule_overrides,omitempty"`
+	RestoredFromID *string                `json:"restored_from_id,omitempty"`
+	PublishedAt    *string                `json:"published_at,omitempty"`
+	Items          []ScheduleItemResponse `json:"items,omitempty"`
+	CreatedAt      string                 `json:"created_at"`
+	UpdatedAt      string                 `json:"updated_at"`
 }
 
 // ScheduleItemResponse 排班明细响应
@@ -98,6 +144,7 @@ type CandidateResponse struct {
 type ValidateCandidateResponse struct {
 	Valid     bool     `json:"valid"`
 	Conflicts []string `json:"conflicts,omitempty"`
+	Warnings  []string `json:"warnings,omitempty"` // 软约束提示（R8/R9/R10 软约束模式、R7 需同时段），不影响 valid
 }
 
 // ScheduleChangeLogResponse 变更日志响应
@@ -109,6 +156,7 @@ type ScheduleChangeLogResponse struct {
 	OriginalMemberName string `json:"original_member_name,omitempty"`
 	NewMemberID        string `json:"new_member_id"`
 	NewMemberName      string `json:"new_member_name,omitempty"`
+	DutyDate           string `json:"duty_date,omitempty"` // 单次值班变更（转让）的日期
 	ChangeType         string `json:"change_type"`
 	Reason             string `json:"reason,omitempty"`
 	OperatorID         string `json:"operator_id"`
@@ -123,6 +171,207 @@ type AutoScheduleResponse struct {
 	Warnings    []string          `json:"warnings,omitempty"`
 }
 
+// AutoScheduleProgress 自动排班进度
+type AutoScheduleProgress struct {
+	Phase          string `json:"phase"`           // prepare | matrix | assign | persist | done
+	TotalSlots     int    `json:"total_slots"`     // 待排槽位总数
+	ProcessedSlots int    `json:"processed_slots"` // 已处理槽位数
+	FilledSlots    int    `json:"filled_slots"`    // 已填充槽位数
+	BestScore      int    `json:"best_score"`      // 当前方案软约束罚分（越低越好）
+}
+
+// AutoScheduleJobResponse 自动排班任务状态响应
+type AutoScheduleJobResponse struct {
+	JobID      string                `json:"job_id"`
+	SemesterID string                `json:"semester_id"`
+	Status     string                `json:"status"` // queued | running | succeeded | failed | cancelled
+	Progress   AutoScheduleProgress  `json:"progress"`
+	Result     *AutoScheduleResponse `json:"result,omitempty"`
+	Error      string                `json:"error,omitempty"`
+	CreatedBy  string                `json:"created_by"`
+	CreatedAt  string                `json:"created_at"`
+	StartedAt  string                `json:"started_at,omitempty"`
+	FinishedAt string                `json:"finished_at,omitempty"`
+}
+
+// ── 候选方案 DTO ──
+
+// CompareSchedulesRequest 候选方案对比请求
+type CompareSchedulesRequest struct {
+	ScheduleIDs []string `form:"schedule_ids" binding:"required,min=2,max=10,dive,uuid"`
+}
+
+// RuleViolationSummary 单条软约束规则的违反统计
+type RuleViolationSummary struct {
+	RuleCode string `json:"rule_code"`
+	Count    int    `json:"count"`
+	Penalty  int    `json:"penalty"`
+}
+
+// ScheduleQualityReport 排班方案质量报告
+type ScheduleQualityReport struct {
+	TotalSlots    int                    `json:"total_slots"`
+	FilledSlots   int                    `json:"filled_slots"`
+	FillRate      float64                `json:"fill_rate"`      // 填充率 0~1
+	MemberCount   int                    `json:"member_count"`   // 快照候选人数
+	AssignedCount int                    `json:"assigned_count"` // 至少排到一个班次的人数
+	MinShifts     int                    `json:"min_shifts"`     // 候选人中最少排班次数
+	MaxShifts     int                    `json:"max_shifts"`     // 候选人中最多排班次数
+	ShiftStdDev   float64                `json:"shift_std_dev"`  // 排班次数标准差（越低越均衡）
+	TotalHours    float64                `json:"total_hours"`    // 已排班次总时长（小时）
+	TotalCost     float64                `json:"total_cost"`     // 已排班次总加权成本（时长 × 冷门系数）
+	MinCost       float64                `json:"min_cost"`       // 候选人中最低累计成本
+	MaxCost       float64                `json:"max_cost"`       // 候选人中最高累计成本
+	CostStdDev    float64                `json:"cost_std_dev"`   // 累计成本标准差（越低越均衡）
+	HardConflicts int                    `json:"hard_conflicts"` // 违反 R1/R2/R6、R7 硬搭配约束及硬约束模式 R8/R9/R10 的排班项数
+	SoftPenalty   int                    `json:"soft_penalty"`   // 软约束总罚分（越低越好）
+	Violations    []RuleViolationSummary `json:"violations"`
+}
+
+// MemberWorkload 成员工作量
+type MemberWorkload struct {
+	Member MemberBrief `json:"member"`
+	Shifts int         `json:"shifts"`
+	Hours  float64     `json:"hours"` // 班次总时长（小时）
+	Cost   float64     `json:"cost"`  // 加权成本（时长 × 冷门系数）
+}
+
+// ScheduleWorkloadResponse 排班方案的成员工作量
+type ScheduleWorkloadResponse struct {
+	ScheduleID string           `json:"schedule_id"`
+	TotalHours float64          `json:"total_hours"`
+	TotalCost  float64          `json:"total_cost"`
+	Members    []MemberWorkload `json:"members"` // 按加权成本降序，未排到班次的候选人计 0
+}
+
+// ScheduleSummaryResponse 排班方案摘要（含质量报告，不含明细）
+type ScheduleSummaryResponse struct {
+	ID            string                 `json:"id"`
+	SemesterID    string                 `json:"semester_id"`
+	Status        string                 `json:"status"`
+	Name          string                 `json:"name,omitempty"`
+	Solver        string                 `json:"solver"`
+	Seed          *int64                 `json:"seed,omitempty"`
+	RuleOverrides map[string]bool        `json:"rule_overrides,omitempty"`
+	Quality       *ScheduleQualityReport `json:"quality"`
+	CreatedAt     string                 `json:"created_at"`
+}
+
+// ── 模拟排班 DTO ──
+
+// SimulatedTimeSlot 模拟中新增的时间段（仅存在于本次模拟）
+type SimulatedTimeSlot struct {
+	Name           string   `json:"name"            binding:"required,min=2,max=50"`
+	DayOfWeek      int      `json:"day_of_week"     binding:"required,min=1,max=5"`
+	StartTime      string   `json:"start_time"      binding:"required,datetime=15:04"`
+	EndTime        string   `json:"end_time"        binding:"required,datetime=15:04"`
+	CostMultiplier *float64 `json:"cost_multiplier" binding:"omitempty,gt=0,lte=10"`
+}
+
+// SimulateScheduleRequest 模拟排班请求：在学期现有数据上叠加覆盖项后求解，不写入任何数据
+type SimulateScheduleRequest struct {
+	SemesterID    string          `json:"semester_id"    binding:"required,uuid"`
+	Solver        string          `json:"solver"         binding:"omitempty,oneof=greedy local_search"`
+	Seed          *int64          `json:"seed"`
+	RuleOverrides map[string]bool `json:"rule_overrides"` // 规则启用状态覆盖，如 {"R3": false}
+	// RuleWeights 软约束罚分覆盖，如 {"R4": 80}；0 表示不计罚分
+	RuleWeights     map[string]int      `json:"rule_weights"      binding:"omitempty,dive,min=0,max=1000"`
+	AddMembers      []string            `json:"add_members"       binding:"omitempty,max=200,dive,uuid"`
+	RemoveMembers   []string            `json:"remove_members"    binding:"omitempty,dive,uuid"`
+	AddTimeSlots    []SimulatedTimeSlot `json:"add_time_slots"    binding:"omitempty,max=50,dive"`
+	RemoveTimeSlots []string            `json:"remove_time_slots" binding:"omitempty,dive,uuid"`
+}
+
+// ScheduleSimulationResponse 模拟排班结果
+type ScheduleSimulationResponse struct {
+	SemesterID string                 `json:"semester_id"`
+	Solver     string                 `json:"solver"`
+	Baseline   *ScheduleQualityReport `json:"baseline"` // 不含覆盖项、按当前数据求解的结果
+	Quality    *ScheduleQualityReport `json:"quality"`  // 叠加覆盖项后的结果
+	Warnings   []string               `json:"warnings"`
+}
+
+// ScheduleVersionResponse 排班版本（历史记录列表项）
+type ScheduleVersionResponse struct {
+	ID             string  `json:"id"`
+	SemesterID     string  `json:"semester_id"`
+	Status         string  `json:"status"`
+	Name           string  `json:"name,omitempty"`
+	Solver         string  `json:"solver"`
+	ItemCount      int     `json:"item_count"`
+	ChangeLogCount int64   `json:"change_log_count"`
+	RestoredFromID *string `json:"restored_from_id,omitempty"`
+	PublishedAt    *string `json:"published_at,omitempty"`
+	CreatedAt      string  `json:"created_at"`
+}
+
+// ScheduleDiffItem 单个槽位的差异
+type ScheduleDiffItem struct {
+	WeekNumber   int            `json:"week_number"`
+	TimeSlot     *TimeSlotBrief `json:"time_slot,omitempty"`
+	BaseMember   *MemberBrief   `json:"base_member,omitempty"`
+	TargetMember *MemberBrief   `json:"target_member,omitempty"`
+	BaseItemID   string         `json:"base_item_id,omitempty"`
+	TargetItemID string         `json:"target_item_id,omitempty"`
+}
+
+// ScheduleMemberDiff 单个成员的差异汇总
+type ScheduleMemberDiff struct {
+	Member       MemberBrief `json:"member"`
+	BaseShifts   int         `json:"base_shifts"`
+	TargetShifts int         `json:"target_shifts"`
+	Gained       int         `json:"gained"` // 目标版本新增的班次
+	Lost         int         `json:"lost"`   // 目标版本失去的班次
+}
+
+// ScheduleDiffResponse 两个排班版本的差异
+type ScheduleDiffResponse struct {
+	BaseID     string               `json:"base_id"`
+	TargetID   string               `json:"target_id"`
+	Added      []ScheduleDiffItem   `json:"added"`      // 仅目标版本有排班的槽位
+	Removed    []ScheduleDiffItem   `json:"removed"`    // 仅基准版本有排班的槽位
+	Reassigned []ScheduleDiffItem   `json:"reassigned"` // 两版本均有排班但人员不同
+	Members    []ScheduleMemberDiff `json:"members"`
+}
+
+// SoftScoreBreakdown 成员排入槽位的打分明细（越低越优先）
+type SoftScoreBreakdown struct {
+	Workload int `json:"workload"` // 已排加权成本 × 权重
+	R3       int `json:"r3"`       // 同日部门重复罚分
+	R4       int `json:"r4"`       // 相邻班次部门重复罚分
+	R5       int `json:"r5"`       // 单双周早八部门重复罚分
+	R7       int `json:"r7"`       // 需搭配成员未同时段罚分
+	R8       int `json:"r8"`       // 超出每周班次上限罚分
+	R9       int `json:"r9"`       // 班次间隔不足罚分
+	R10      int `json:"r10"`      // 连续两天值班罚分
+	Soft     int `json:"soft"`     // 软约束罚分合计
+	Total    int `json:"total"`
+}
+
+// MemberExplanation 单个值班成员在槽位上的状态
+type MemberExplanation struct {
+	Member     MemberBrief         `json:"member"`
+	Status     string              `json:"status"` // assigned | eligible | quota_reached | rest_conflict | pair_conflict | same_day_conflict | course_conflict | unavailable | unqualified | not_submitted
+	Reasons    []string            `json:"reasons,omitempty"`
+	ShiftCount int                 `json:"shift_count"` // 该槽位以外已排班次
+	Cost       float64             `json:"cost"`        // 该槽位以外已排班次的加权成本
+	Score      *SoftScoreBreakdown `json:"score,omitempty"`
+	Rank       int                 `json:"rank,omitempty"` // 可排成员中按打分的名次（1 为最优）
+}
+
+// SlotExplanationResponse 槽位排班解释
+type SlotExplanationResponse struct {
+	ScheduleID   string              `json:"schedule_id"`
+	WeekNumber   int                 `json:"week_number"`
+	TimeSlot     *TimeSlotBrief      `json:"time_slot"`
+	Filled       bool                `json:"filled"`
+	Assigned     []MemberBrief       `json:"assigned"`
+	Quota        int                 `json:"quota"` // 人均班次上限（槽位总数 / 可排成员数，向上取整）
+	Summary      string              `json:"summary"`
+	StatusCounts map[string]int      `json:"status_counts"`
+	Members      []MemberExplanation `json:"members"`
+}
+
 // ScopeCheckResponse 范围检测响应
 type ScopeCheckResponse struct {
 	Changed      bool     `json:"changed"`
@@ -0,0 +1,33 @@
+package dto
+
+// ── 排班冲突处理任务 DTO ──
+
+// ScheduleConflictListRequest 冲突任务列表查询参数
+type ScheduleConflictListRequest struct {
+	ScheduleID string `form:"schedule_id" binding:"omitempty,uuid"`
+	MemberID   string `form:"member_id"   binding:"omitempty,uuid"`
+	Status     string `form:"status"      binding:"omitempty,oneof=open resolved dismissed"`
+	PaginationRequest
+}
+
+// ResolveScheduleConflictRequest 处理冲突任务请求
+type ResolveScheduleConflictRequest struct {
+	Status string `json:"status" binding:"required,oneof=resolved dismissed"`
+	Note   string `json:"note"   binding:"max=200"`
+}
+
+// ScheduleConflictResponse 冲突任务响应
+type ScheduleConflictResponse struct {
+	ID             string         `json:"id"`
+	ScheduleID     string         `json:"schedule_id"`
+	ScheduleItemID string         `json:"schedule_item_id"`
+	WeekNumber     int            `json:"week_number,omitempty"`
+	TimeSlot       *TimeSlotBrief `json:"time_slot,omitempty"`
+	Member         *MemberBrief   `json:"member,omitempty"`
+	Source         string         `json:"source"`
+	Detail         string         `json:"detail"`
+	Status         string         `json:"status"`
+	ResolutionNote string         `json:"resolution_note,omitempty"`
+	ResolvedAt     *string        `json:"resolved_at,omitempty"`
+	CreatedAt      string         `json:"created_at"`
+}
@@ -18,3 +18,24 @@ type ScheduleRuleResponse struct {
 	CreatedAt      string `json:"created_at"`
 	UpdatedAt      string `json:"updated_at"`
 }
+
+// ── 成员搭配约束（R7） ──
+
+// CreatePairConstraintRequest 创建成员搭配约束请求
+type CreatePairConstraintRequest struct {
+	MemberAID string `json:"member_a_id" binding:"required,uuid"`
+	MemberBID string `json:"member_b_id" binding:"required,uuid"`
+	Kind      string `json:"kind"        binding:"required,oneof=must_pair must_not_pair not_adjacent"`
+	Note      string `json:"note"        binding:"omitempty,max=200"`
+}
+
+// PairConstraintResponse 成员搭配约束响应
+type PairConstraintResponse struct {
+	ID         string       `json:"id"`
+	SemesterID string       `json:"semester_id"`
+	MemberA    *MemberBrief `json:"member_a"`
+	MemberB    *MemberBrief `json:"member_b"`
+	Kind       string       `json:"kind"`
+	Note       string       `json:"note,omitempty"`
+	CreatedAt  string       `json:"created_at"`
+}
@@ -75,3 +75,52 @@ type PendingTodoItem struct {
 	Title   string `json:"title"`
 	Message string `json:"message"`
 }
+
+// ── 校历 DTO ──
+
+// UpsertCalendarDayRequest 新增/覆盖校历特殊日期请求（按日期覆盖）
+type UpsertCalendarDayRequest struct {
+	Date            string `json:"date"               binding:"required"` // "2026-10-01"
+	Kind            string `json:"kind"               binding:"required,oneof=holiday workday"`
+	Name            string `json:"name"               binding:"max=100"`
+	RunsAsDayOfWeek *int   `json:"runs_as_day_of_week" binding:"omitempty,min=1,max=7"` // 调休上班日按星期几执行
+	RunsAsWeek      *int   `json:"runs_as_week"        binding:"omitempty,min=1"`       // 调休上班日按第几教学周执行
+}
+
+// CalendarDayResponse 校历特殊日期响应
+type CalendarDayResponse struct {
+	ID              string `json:"id"`
+	SemesterID      string `json:"semester_id"`
+	Date            string `json:"date"`
+	Kind            string `json:"kind"`
+	Name            string `json:"name"`
+	RunsAsDayOfWeek *int   `json:"runs_as_day_of_week,omitempty"`
+	RunsAsWeek      *int   `json:"runs_as_week,omitempty"`
+	Source          string `json:"source"`
+}
+
+// CalendarResolveRequest 校历换算请求（缺省为整个学期）
+type CalendarResolveRequest struct {
+	From string `form:"from"` // "2026-09-01"
+	To   string `form:"to"`
+}
+
+// ResolvedCalendarDay 换算后的单日信息
+type ResolvedCalendarDay struct {
+	Date         string `json:"date"`
+	DayOfWeek    int    `json:"day_of_week"`    // 自然星期
+	IsDutyDay    bool   `json:"is_duty_day"`    // 当天是否值班
+	RunsAs       int    `json:"runs_as"`        // 实际执行的星期
+	TeachingWeek int    `json:"teaching_week"`  // 实际执行的教学周次
+	CycleWeek    int    `json:"cycle_week"`     // 对应排班模板 week_number（1|2）
+	WeekType     string `json:"week_type"`      // odd | even
+	Kind         string `json:"kind,omitempty"` // holiday | workday
+	Name         string `json:"name,omitempty"`
+}
+
+// CalendarImportResponse 节假日 ICS 导入结果
+type CalendarImportResponse struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Skipped int `json:"skipped"` // 与手工维护日期冲突或超出学期范围而跳过
+}
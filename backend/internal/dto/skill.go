@@ -0,0 +1,35 @@
+package dto
+
+// ── 技能模块 DTO ──
+
+// CreateSkillRequest 创建技能请求
+type CreateSkillRequest struct {
+	Name        string `json:"name"        binding:"required,min=1,max=50"`
+	Description string `json:"description" binding:"omitempty,max=200"`
+}
+
+// UpdateSkillRequest 更新技能请求
+type UpdateSkillRequest struct {
+	Name        *string `json:"name"        binding:"omitempty,min=1,max=50"`
+	Description *string `json:"description" binding:"omitempty,max=200"`
+}
+
+// SetUserSkillsRequest 设置成员技能请求（全量替换，空数组表示清空）
+type SetUserSkillsRequest struct {
+	SkillIDs []string `json:"skill_ids" binding:"omitempty,dive,uuid"`
+}
+
+// SkillResponse 技能信息响应
+type SkillResponse struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	CreatedAt   string `json:"created_at"`
+	UpdatedAt   string `json:"updated_at"`
+}
+
+// SkillBrief 技能简要信息（嵌入成员、时间段与活动班次响应）
+type SkillBrief struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
@@ -0,0 +1,47 @@
+package dto
+
+// ── 统计模块 DTO ──
+
+// AttendanceStatsRequest 出勤统计查询参数
+// from / to 须同时提供；均未提供时按 semester_id（缺省为当前学期）的起止日期统计
+type AttendanceStatsRequest struct {
+	SemesterID   string `form:"semester_id"   binding:"omitempty,uuid"`
+	From         string `form:"from"          binding:"omitempty,datetime=2006-01-02"` // 含
+	To           string `form:"to"            binding:"omitempty,datetime=2006-01-02"` // 含
+	DepartmentID string `form:"department_id" binding:"omitempty,uuid"`                // 仅 admin 生效，leader 固定为本部门
+}
+
+// AttendanceCounts 出勤计数
+type AttendanceCounts struct {
+	Scheduled  int64   `json:"scheduled"`   // 应值班次数
+	Completed  int64   `json:"completed"`   // 正常签退
+	Late       int64   `json:"late"`        // 迟到签到
+	Absent     int64   `json:"absent"`      // 缺勤
+	MadeUp     int64   `json:"made_up"`     // 缺勤已补班
+	NoSignOut  int64   `json:"no_sign_out"` // 签到未签退
+	Pending    int64   `json:"pending"`     // 尚未签到 / 值班中
+	Substitute int64   `json:"substitute"`  // 其中紧急替班接手的次数
+	Hours      float64 `json:"hours"`       // 已完成值班的计划时长合计（小时）
+}
+
+// MemberAttendanceResponse 成员出勤统计
+type MemberAttendanceResponse struct {
+	Member *MemberBrief `json:"member"`
+	AttendanceCounts
+}
+
+// DepartmentAttendanceResponse 部门出勤汇总
+type DepartmentAttendanceResponse struct {
+	Department  *DepartmentResponse `json:"department"`
+	MemberCount int                 `json:"member_count"` // 区间内有值班记录的成员数
+	AttendanceCounts
+}
+
+// AttendanceStatsResponse 出勤统计
+type AttendanceStatsResponse struct {
+	From        string                         `json:"from"`
+	To          string                         `json:"to"`
+	Total       AttendanceCounts               `json:"total"`
+	Departments []DepartmentAttendanceResponse `json:"departments"`
+	Members     []MemberAttendanceResponse     `json:"members"`
+}
@@ -0,0 +1,111 @@
+package dto
+
+// ── 换班模块 DTO ──
+
+// CreateGiveAwayRequest 发布值班转让请求
+type CreateGiveAwayRequest struct {
+	DutyRecordID string `json:"duty_record_id" binding:"required,uuid"`
+	Reason       string `json:"reason"         binding:"omitempty,max=500"`
+}
+
+// CreateExchangeRequest 发起双向换班请求，两组字段二选一：
+// 互换排班项（schedule_item_id ↔ target_schedule_item_id，此后每周生效），
+// 或互换具体日期的值班（duty_record_id ↔ target_duty_record_id）
+type CreateExchangeRequest struct {
+	ScheduleItemID       string `json:"schedule_item_id"        binding:"omitempty,uuid"`
+	TargetScheduleItemID string `json:"target_schedule_item_id" binding:"omitempty,uuid"`
+	DutyRecordID         string `json:"duty_record_id"          binding:"omitempty,uuid"`
+	TargetDutyRecordID   string `json:"target_duty_record_id"   binding:"omitempty,uuid"`
+	Reason               string `json:"reason"                  binding:"omitempty,max=500"`
+}
+
+// RespondSwapRequest 被邀请成员响应换班请求
+type RespondSwapRequest struct {
+	Accept bool   `json:"accept"`
+	Reason string `json:"reason" binding:"omitempty,max=500"` // 拒绝原因
+}
+
+// ReviewSwapRequest 管理员审批请求
+type ReviewSwapRequest struct {
+	Approve bool   `json:"approve"`
+	Reason  string `json:"reason"  binding:"omitempty,max=500"` // 驳回原因
+}
+
+// SwapListRequest 换班申请列表查询参数
+type SwapListRequest struct {
+	Kind   string `form:"kind"   binding:"omitempty,oneof=transfer give_away exchange"`
+	Status string `form:"status" binding:"omitempty,oneof=pending reviewing completed rejected cancelled"`
+	PaginationRequest
+}
+
+// SwapRequestResponse 换班申请响应
+type SwapRequestResponse struct {
+	ID             string              `json:"id"`
+	Kind           string              `json:"kind"`
+	Status         string              `json:"status"`
+	ScheduleItemID string              `json:"schedule_item_id"`
+	DutyRecord     *DutyRecordResponse `json:"duty_record,omitempty"`
+	ScheduleItem   *SwapItemBrief      `json:"schedule_item,omitempty"`
+	// 双向换班的对方一侧
+	TargetScheduleItem *SwapItemBrief      `json:"target_schedule_item,omitempty"`
+	TargetDutyRecord   *DutyRecordResponse `json:"target_duty_record,omitempty"`
+	Applicant          *MemberBrief        `json:"applicant,omitempty"`
+	TargetMember       *MemberBrief        `json:"target_member,omitempty"` // 转让为认领人，认领前为空；双向换班为对方
+	Reason             string              `json:"reason,omitempty"`
+	RejectReason       string              `json:"reject_reason,omitempty"`
+	RespondedAt        *string             `json:"responded_at,omitempty"`
+	ApprovedAt         *string             `json:"approved_at,omitempty"`
+	AutoApproved       bool                `json:"auto_approved"` // 由系统按自动审批策略通过
+	CreatedAt          string              `json:"created_at"`
+}
+
+// SwapItemBrief 换班涉及的排班项
+type SwapItemBrief struct {
+	ID         string         `json:"id"`
+	WeekNumber int            `json:"week_number"`
+	TimeSlot   *TimeSlotBrief `json:"time_slot,omitempty"`
+	Location   *LocationBrief `json:"location,omitempty"`
+	Member     *MemberBrief   `json:"member,omitempty"`
+}
+
+// SwapSuggestionRequest 换班候选人推荐查询参数，二选一：
+// item_id 为排班项（此后每周），duty_record_id 为具体日期的值班
+type SwapSuggestionRequest struct {
+	ItemID       string `form:"item_id"        binding:"omitempty,uuid"`
+	DutyRecordID string `form:"duty_record_id" binding:"omitempty,uuid"`
+}
+
+// SwapSuggestionResponse 换班候选人
+type SwapSuggestionResponse struct {
+	Member     *MemberBrief `json:"member"`
+	ShiftCount int          `json:"shift_count"` // 当前周常班次数
+	Load       float64      `json:"load"`        // 当前周常值班加权成本（时长 × 冷门系数）
+	// 申请人可回换的该成员值班（双向换班）：按 item_id 查询时为排班项，按 duty_record_id 查询时为具体日期的值班
+	ReturnItems       []SwapItemBrief      `json:"return_items,omitempty"`
+	ReturnDutyRecords []DutyRecordResponse `json:"return_duty_records,omitempty"`
+}
+
+// ── 换班自动审批策略 ──
+
+// SetSwapPolicyRequest 设置部门换班自动审批策略（整体覆盖全局策略）
+type SetSwapPolicyRequest struct {
+	AutoApprove         bool `json:"auto_approve"`
+	SameDepartment      bool `json:"same_department"`                                // 双方须同部门
+	MinHoursBefore      int  `json:"min_hours_before"       binding:"min=0,max=720"` // 申请须早于值班开始的小时数
+	MaxSwapsPerSemester int  `json:"max_swaps_per_semester" binding:"min=0,max=100"` // 申请人本学期已完成换班须少于该数，0 不限
+}
+
+// SwapPolicyResponse 换班自动审批策略；Department 为空表示全局策略
+type SwapPolicyResponse struct {
+	Department          *DepartmentResponse `json:"department,omitempty"`
+	AutoApprove         bool                `json:"auto_approve"`
+	SameDepartment      bool                `json:"same_department"`
+	MinHoursBefore      int                 `json:"min_hours_before"`
+	MaxSwapsPerSemester int                 `json:"max_swaps_per_semester"`
+}
+
+// SwapPolicyListResponse 全局策略与各部门覆盖
+type SwapPolicyListResponse struct {
+	Global      SwapPolicyResponse   `json:"global"`
+	Departments []SwapPolicyResponse `json:"departments"`
+}
@@ -4,19 +4,44 @@ package dto
 
 // UpdateSystemConfigRequest 更新系统配置请求
 type UpdateSystemConfigRequest struct {
-	SwapDeadlineHours    *int    `json:"swap_deadline_hours"     binding:"omitempty,min=1,max=168"`
-	DutyReminderTime     *string `json:"duty_reminder_time"`
-	DefaultLocation      *string `json:"default_location"        binding:"omitempty,min=1,max=200"`
-	SignInWindowMinutes  *int    `json:"sign_in_window_minutes"  binding:"omitempty,min=1,max=60"`
-	SignOutWindowMinutes *int    `json:"sign_out_window_minutes" binding:"omitempty,min=1,max=60"`
+	SwapDeadlineHours       *int    `json:"swap_deadline_hours"       binding:"omitempty,min=1,max=168"`
+	DutyReminderTime        *string `json:"duty_reminder_time"`
+	DefaultLocation         *string `json:"default_location"          binding:"omitempty,min=1,max=200"`
+	SignInWindowMinutes     *int    `json:"sign_in_window_minutes"    binding:"omitempty,min=1,max=60"`
+	SignOutWindowMinutes    *int    `json:"sign_out_window_minutes"   binding:"omitempty,min=1,max=60"`
+	TimetableConflictPolicy *string `json:"timetable_conflict_policy" binding:"omitempty,oneof=notify block"` // 发布后时间表变更冲突：notify | block
+	MaxShiftsPerWeek        *int    `json:"max_shifts_per_week"       binding:"omitempty,min=0,max=14"`       // R8 每人每周班次上限，0 不限
+	MaxShiftsPerWeekMode    *string `json:"max_shifts_per_week_mode"  binding:"omitempty,oneof=hard soft"`
+	MinRestHours            *int    `json:"min_rest_hours"            binding:"omitempty,min=0,max=72"` // R9 相邻班次最小间隔小时数，0 不限
+	MinRestMode             *string `json:"min_rest_mode"             binding:"omitempty,oneof=hard soft"`
+	BackToBackDaysMode      *string `json:"back_to_back_days_mode"    binding:"omitempty,oneof=hard soft"` // R10 连续两天值班
+	SwapRequiresApproval    *bool   `json:"swap_requires_approval"`                                        // 值班转让被认领后是否需管理员审批
+	// 换班自动审批全局策略
+	SwapAutoApprove               *bool `json:"swap_auto_approve"`
+	SwapAutoApproveSameDepartment *bool `json:"swap_auto_approve_same_department"`
+	SwapAutoApproveMinHours       *int  `json:"swap_auto_approve_min_hours"        binding:"omitempty,min=0,max=720"`
+	SwapAutoApproveMaxPerSemester *int  `json:"swap_auto_approve_max_per_semester" binding:"omitempty,min=0,max=100"` // 0 不限
+	EmergencySubstituteEnabled    *bool `json:"emergency_substitute_enabled"`                                         // 标记缺勤时默认广播紧急替班
 }
 
 // SystemConfigResponse 系统配置响应
 type SystemConfigResponse struct {
-	SwapDeadlineHours    int    `json:"swap_deadline_hours"`
-	DutyReminderTime     string `json:"duty_reminder_time"`
-	DefaultLocation      string `json:"default_location"`
-	SignInWindowMinutes  int    `json:"sign_in_window_minutes"`
-	SignOutWindowMinutes int    `json:"sign_out_window_minutes"`
-	UpdatedAt            string `json:"updated_at"`
+	SwapDeadlineHours             int    `json:"swap_deadline_hours"`
+	DutyReminderTime              string `json:"duty_reminder_time"`
+	DefaultLocation               string `json:"default_location"`
+	SignInWindowMinutes           int    `json:"sign_in_window_minutes"`
+	SignOutWindowMinutes          int    `json:"sign_out_window_minutes"`
+	TimetableConflictPolicy       string `json:"timetable_conflict_policy"`
+	MaxShiftsPerWeek              int    `json:"max_shifts_per_week"`
+	MaxShiftsPerWeekMode          string `json:"max_shifts_per_week_mode"`
+	MinRestHours                  int    `json:"min_rest_hours"`
+	MinRestMode                   string `json:"min_rest_mode"`
+	BackToBackDaysMode            string `json:"back_to_back_days_mode"`
+	SwapRequiresApproval          bool   `json:"swap_requires_approval"`
+	SwapAutoApprove               bool   `json:"swap_auto_approve"`
+	SwapAutoApproveSameDepartment bool   `json:"swap_auto_approve_same_department"`
+	SwapAutoApproveMinHours       int    `json:"swap_auto_approve_min_hours"`
+	SwapAutoApproveMaxPerSemester int    `json:"swap_auto_approve_max_per_semester"`
+	EmergencySubstituteEnabled    bool   `json:"emergency_substitute_enabled"`
+	UpdatedAt                     string `json:"updated_at"`
 }
@@ -9,6 +9,12 @@ type CreateTimeSlotRequest struct {
 	StartTime  string  `json:"start_time"  binding:"required"` // "08:10"
 	EndTime    string  `json:"end_time"    binding:"required"` // "10:05"
 	DayOfWeek  int     `json:"day_of_week" binding:"required,min=1,max=5"`
+	// CostMultiplier 冷门系数（默认 1），班次成本 = 时长 × 系数
+	CostMultiplier *float64 `json:"cost_multiplier" binding:"omitempty,gt=0,lte=10"`
+	// SkillIDs 值班成员须具备的全部技能（可选）
+	SkillIDs []string `json:"skill_ids" binding:"omitempty,dive,uuid"`
+	// LocationID 绑定地点（可选，不绑定时由自动排班选择）
+	LocationID *string `json:"location_id" binding:"omitempty,uuid"`
 }
 
 // UpdateTimeSlotRequest 更新时间段请求
@@ -18,6 +24,12 @@ type UpdateTimeSlotRequest struct {
 	EndTime   *string `json:"end_time"`
 	DayOfWeek *int    `json:"day_of_week" binding:"omitempty,min=1,max=5"`
 	IsActive  *bool   `json:"is_active"`
+	// CostMultiplier 冷门系数，班次成本 = 时长 × 系数
+	CostMultiplier *float64 `json:"cost_multiplier" binding:"omitempty,gt=0,lte=10"`
+	// SkillIDs 所需技能（全量替换，空数组表示清空，不传则不修改）
+	SkillIDs *[]string `json:"skill_ids" binding:"omitempty,dive,uuid"`
+	// LocationID 绑定地点（空字符串表示解除绑定，不传则不修改）
+	LocationID *string `json:"location_id" binding:"omitempty,uuid"`
 }
 
 // TimeSlotListRequest 时间段列表查询参数
@@ -28,16 +40,21 @@ type TimeSlotListRequest struct {
 
 // TimeSlotResponse 时间段信息响应
 type TimeSlotResponse struct {
-	ID         string         `json:"id"`
-	Name       string         `json:"name"`
-	SemesterID *string        `json:"semester_id,omitempty"`
-	Semester   *SemesterBrief `json:"semester,omitempty"`
-	StartTime  string         `json:"start_time"`
-	EndTime    string         `json:"end_time"`
-	DayOfWeek  int            `json:"day_of_week"`
-	IsActive   bool           `json:"is_active"`
-	CreatedAt  string         `json:"created_at"`
-	UpdatedAt  string         `json:"updated_at"`
+	ID             string         `json:"id"`
+	Name           string         `json:"name"`
+	SemesterID     *string        `json:"semester_id,omitempty"`
+	Semester       *SemesterBrief `json:"semester,omitempty"`
+	StartTime      string         `json:"start_time"`
+	EndTime        string         `json:"end_time"`
+	DayOfWeek      int            `json:"day_of_week"`
+	IsActive       bool           `json:"is_active"`
+	Hours          float64        `json:"hours"`           // 时长（小时）
+	CostMultiplier float64        `json:"cost_multiplier"` // 冷门系数
+	Cost           float64        `json:"cost"`            // 加权成本 = 时长 × 冷门系数
+	RequiredSkills []SkillBrief   `json:"required_skills"` // 所需技能
+	Location       *LocationBrief `json:"location,omitempty"`
+	CreatedAt      string         `json:"created_at"`
+	UpdatedAt      string         `json:"updated_at"`
 }
 
 // SemesterBrief 学期简要信息（嵌入时间段响应）
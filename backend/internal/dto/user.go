@@ -48,6 +48,8 @@ type CreateUserRequest struct {
 	Email        string `json:"email"         binding:"required,email"`
 	Role         string `json:"role"          binding:"required,oneof=admin leader member"`
 	DepartmentID string `json:"department_id" binding:"required,uuid"`
+	// SkillIDs 成员技能（可选）
+	SkillIDs []string `json:"skill_ids" binding:"omitempty,dive,uuid"`
 }
 
 // CreateUserResponse 新增用户响应
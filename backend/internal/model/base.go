@@ -2,6 +2,7 @@ package model
 
 import (
 	"database/sql/driver"
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
@@ -27,6 +28,9 @@ const (
 	RoleMember = "member"
 )
 
+// SystemActorID 系统账号 ID：自动审批等系统操作的审计人（迁移 000015 创建，已删除状态、不可登录）
+const SystemActorID = "00000000-0000-0000-0000-000000000001"
+
 // ── 排班表状态枚举 ──
 
 const (
@@ -34,6 +38,24 @@ const (
 	ScheduleStatusPublished = "published"
 	ScheduleStatusArchived  = "archived"
 	ScheduleStatusNeedRegen = "need_regen"
+	ScheduleStatusCandidate = "candidate" // 候选方案：不占用学期唯一活跃排班名额，可提升为草稿
+)
+
+// ── 排班求解器枚举 ──
+
+const (
+	SolverGreedy      = "greedy"       // 贪心：最难排槽位优先
+	SolverLocalSearch = "local_search" // 贪心初解 + 交换/替换邻域局部搜索
+)
+
+// ── 自动排班任务状态枚举 ──
+
+const (
+	AutoScheduleJobQueued    = "queued"
+	AutoScheduleJobRunning   = "running"
+	AutoScheduleJobSucceeded = "succeeded"
+	AutoScheduleJobFailed    = "failed"
+	AutoScheduleJobCancelled = "cancelled"
 )
 
 // ── 时间表提交状态枚举 ──
@@ -67,6 +89,152 @@ const (
 	SemesterPhasePublished   = "published"   // 已发布
 )
 
+// ── 校历特殊日期枚举 ──
+
+const (
+	CalendarDayHoliday = "holiday" // 节假日：不值班
+	CalendarDayWorkday = "workday" // 调休上班：按指定星期/周次执行
+
+	CalendarSourceManual = "manual"
+	CalendarSourceICS    = "ics"
+)
+
+// ── 值班记录状态枚举 ──
+
+const (
+	DutyRecordStatusPending      = "pending"
+	DutyRecordStatusOnDuty       = "on_duty"
+	DutyRecordStatusCompleted    = "completed"
+	DutyRecordStatusAbsent       = "absent"
+	DutyRecordStatusAbsentMadeUp = "absent_made_up"
+	DutyRecordStatusNoSignOut    = "no_sign_out"
+)
+
+// ── 不可用时间重复类型枚举 ──
+
+const (
+	RepeatTypeWeekly   = "weekly"
+	RepeatTypeBiweekly = "biweekly"
+	RepeatTypeOnce     = "once" // 仅 SpecificDate 当天，只影响对应日期的值班记录
+)
+
+// ── 换班申请枚举 ──
+
+const (
+	SwapStatusPending   = "pending"   // 待对方响应 / 待认领
+	SwapStatusReviewing = "reviewing" // 待管理员审批
+	SwapStatusCompleted = "completed"
+	SwapStatusRejected  = "rejected"
+	SwapStatusCancelled = "cancelled"
+
+	SwapKindTransfer = "transfer"  // 指定接班人
+	SwapKindGiveAway = "give_away" // 转让某一具体日期的值班，先到先得
+	SwapKindExchange = "exchange"  // 与指定成员互换排班项或具体日期的值班
+)
+
+// ── 请假枚举 ──
+
+const (
+	LeaveStatusPending   = "pending"
+	LeaveStatusApproved  = "approved" // 已指定替班成员并改派值班
+	LeaveStatusRejected  = "rejected"
+	LeaveStatusCancelled = "cancelled"
+)
+
+// ── 紧急替班枚举 ──
+
+const (
+	EmergencyStatusOpen      = "open"   // 广播中，等待响应
+	EmergencyStatusFilled    = "filled" // 已由第一位响应者接班
+	EmergencyStatusCancelled = "cancelled"
+	EmergencyStatusExpired   = "expired" // 仅用于响应：open 且该次值班已结束
+)
+
+// ── 签到拒绝审计枚举 ──
+
+const (
+	SignInActionSignIn  = "sign_in"
+	SignInActionSignOut = "sign_out"
+
+	SignInRejectNetwork         = "network"           // 客户端 IP 不在地点允许网段内
+	SignInRejectQRInvalid       = "qr_invalid"        // 二维码无效或已过期
+	SignInRejectQRWrongLocation = "qr_wrong_location" // 二维码属于其他地点
+	SignInRejectQRUsed          = "qr_used"           // 二维码已被使用
+)
+
+// ── 通知类型枚举 ──
+
+const (
+	NotificationTypeSwapRequest      = "swap_request"         // 收到换班申请 / 有可认领的值班转让
+	NotificationTypeSwapAccepted     = "swap_accepted"        // 换班已被接受 / 值班转让已被认领
+	NotificationTypeSwapRejected     = "swap_rejected"        // 对方拒绝换班
+	NotificationTypeSwapApproved     = "swap_approved"        // 换班审批通过（已生效）
+	NotificationTypeSwapDenied       = "swap_denied"          // 换班审批驳回
+	NotificationTypeSubstituteNeeded = "substitute_needed"    // 值班需替班（成员临时不可用）
+	NotificationTypeScheduleConflict = "schedule_conflict"    // 发布后时间表变更与排班冲突
+	NotificationTypeEventAssigned    = "event_assigned"       // 被安排到活动班次
+	NotificationTypeEventCancelled   = "event_cancelled"      // 活动取消
+	NotificationTypeLeaveRequest     = "leave_request"        // 收到待审批的请假
+	NotificationTypeLeaveApproved    = "leave_approved"       // 请假审批通过
+	NotificationTypeLeaveRejected    = "leave_rejected"       // 请假审批驳回
+	NotificationTypeSubstituteAssign = "substitute_assigned"  // 被指定为替班成员
+	NotificationTypeAbsentAlert      = "absent_alert"         // 值班被标记缺勤
+	NotificationTypeEmergencyRequest = "emergency_substitute" // 紧急替班广播
+	NotificationTypeEmergencyFilled  = "emergency_filled"     // 紧急替班已有人接班
+	NotificationTypeDutyIncident     = "duty_incident"        // 值班日志标记异常
+
+	NotificationRelatedScheduleItem = "schedule_item"
+	NotificationRelatedDutyRecord   = "duty_record"
+	NotificationRelatedSwapRequest  = "swap_request"
+	NotificationRelatedLeaveRequest = "leave_request"
+	NotificationRelatedEmergency    = "emergency_substitution"
+)
+
+// ── 排班冲突处理任务枚举 ──
+
+const (
+	ScheduleConflictOpen      = "open"      // 待处理
+	ScheduleConflictResolved  = "resolved"  // 已解决（改派 / 换班 / 冲突消失）
+	ScheduleConflictDismissed = "dismissed" // 管理员确认忽略
+
+	ScheduleConflictSourceCourse      = "course"
+	ScheduleConflictSourceUnavailable = "unavailable"
+)
+
+// ── 发布后时间表变更策略 ──
+
+const (
+	TimetableConflictPolicyNotify = "notify" // 允许变更，生成冲突处理任务并通知管理员与成员
+	TimetableConflictPolicyBlock  = "block"  // 拒绝与已发布排班冲突的变更
+)
+
+// ── 活动值班枚举 ──
+
+const (
+	EventStatusDraft     = "draft"     // 编辑班次中，成员不可见
+	EventStatusPublished = "published" // 已发布：生成值班记录，开放报名时成员可报名
+	EventStatusCancelled = "cancelled" // 已取消：未开始的值班记录作废
+
+	EventAssignmentSignup = "signup" // 成员报名
+	EventAssignmentAuto   = "auto"   // 自动分配
+	EventAssignmentAdmin  = "admin"  // 管理员指派
+)
+
+// ── 成员搭配约束枚举 ──
+
+const (
+	PairMustPair    = "must_pair"     // 需同时段值班（新成员跟随老成员）
+	PairMustNotPair = "must_not_pair" // 不能同时段值班
+	PairNotAdjacent = "not_adjacent"  // 不能排在同日相邻时段
+)
+
+// ── 排班约束模式枚举（R8 / R9 / R10） ──
+
+const (
+	ConstraintModeHard = "hard" // 硬约束：自动排班跳过、手工调整拒绝
+	ConstraintModeSoft = "soft" // 软约束：自动排班罚分、质量报告计数
+)
+
 // ── PostgreSQL INT[] 自定义类型 ──
 
 // IntArray 对应 PostgreSQL INT[] 类型，实现 GORM Scanner/Valuer 接口。
@@ -117,6 +285,76 @@ func (a IntArray) Value() (driver.Value, error) {
 	return "{" + strings.Join(parts, ",") + "}", nil
 }
 
+// ── 排班规则覆盖 JSONB 自定义类型 ──
+
+// RuleOverrides 单次排班对规则启用状态的临时覆盖: ruleCode → isEnabled，对应 JSONB 列。
+type RuleOverrides map[string]bool
+
+// Scan 将 JSONB 解析为 map。
+func (r *RuleOverrides) Scan(src interface{}) error {
+	if src == nil {
+		*r = nil
+		return nil
+	}
+	var data []byte
+	switch v := src.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("RuleOverrides.Scan: unsupported type %T", src)
+	}
+	return json.Unmarshal(data, r)
+}
+
+// Value 将 map 序列化为 JSON 文本。
+func (r RuleOverrides) Value() (driver.Value, error) {
+	if r == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// ── 字符串列表 JSONB 自定义类型 ──
+
+// StringList 字符串列表，对应 JSONB 数组列（如值班日志标签）。
+type StringList []string
+
+// Scan 将 JSONB 数组解析为 []string。
+func (l *StringList) Scan(src interface{}) error {
+	if src == nil {
+		*l = nil
+		return nil
+	}
+	var data []byte
+	switch v := src.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("StringList.Scan: unsupported type %T", src)
+	}
+	return json.Unmarshal(data, l)
+}
+
+// Value 将 []string 序列化为 JSON 数组文本（nil 视为空数组）。
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
 // BaseModel 通用审计字段（所有业务模型嵌入）
 type BaseModel struct {
 	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
@@ -0,0 +1,18 @@
+package model
+
+// DepartmentSwapPolicy 部门换班自动审批策略 — 对应 department_swap_policies
+// 按申请人所在部门整体覆盖 system_config 中的全局策略；无记录时使用全局策略。
+type DepartmentSwapPolicy struct {
+	DepartmentID        string `gorm:"type:uuid;primaryKey"   json:"department_id"`
+	AutoApprove         bool   `gorm:"not null;default:false" json:"auto_approve"`
+	SameDepartment      bool   `gorm:"not null;default:true"  json:"same_department"`
+	MinHoursBefore      int    `gorm:"not null;default:48"    json:"min_hours_before"`
+	MaxSwapsPerSemester int    `gorm:"not null;default:3"     json:"max_swaps_per_semester"` // 0 不限
+	BaseModel
+
+	// 关联
+	Department *Department `gorm:"foreignKey:DepartmentID;references:DepartmentID" json:"department,omitempty"`
+}
+
+// TableName 指定表名
+func (DepartmentSwapPolicy) TableName() string { return "department_swap_policies" }
@@ -3,20 +3,29 @@ package model
 import "time"
 
 // DutyRecord 值班记录表 — 对应 duty_records
+// 每条记录来自周常排班项（ScheduleItemID）或活动班次（EventShiftID），二者有且仅有其一。
 type DutyRecord struct {
-	DutyRecordID   string     `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"duty_record_id"`
-	ScheduleItemID string     `gorm:"type:uuid;not null"                             json:"schedule_item_id"`
-	MemberID       string     `gorm:"type:uuid;not null"                             json:"member_id"` // 冗余快照
-	DutyDate       time.Time  `gorm:"type:date;not null"                             json:"duty_date"`
-	Status         string     `gorm:"type:varchar(20);not null;default:'pending'"    json:"status"` // pending | on_duty | completed | absent | absent_made_up | no_sign_out
-	SignInTime     *time.Time `json:"sign_in_time,omitempty"`
-	SignOutTime    *time.Time `json:"sign_out_time,omitempty"`
-	IsLate         bool       `gorm:"not null;default:false"                         json:"is_late"` // 冗余派生
-	MakeUpTime     *time.Time `json:"make_up_time,omitempty"`
+	DutyRecordID     string     `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"duty_record_id"`
+	ScheduleItemID   *string    `gorm:"type:uuid"                                      json:"schedule_item_id,omitempty"`
+	EventShiftID     *string    `gorm:"type:uuid"                                      json:"event_shift_id,omitempty"`
+	MemberID         string     `gorm:"type:uuid;not null"                             json:"member_id"` // 冗余快照
+	DutyDate         time.Time  `gorm:"type:date;not null"                             json:"duty_date"`
+	Status           string     `gorm:"type:varchar(20);not null;default:'pending'"    json:"status"` // pending | on_duty | completed | absent | absent_made_up | no_sign_out
+	SignInTime       *time.Time `json:"sign_in_time,omitempty"`
+	SignOutTime      *time.Time `json:"sign_out_time,omitempty"`
+	IsLate           bool       `gorm:"not null;default:false"                         json:"is_late"` // 冗余派生
+	MakeUpTime       *time.Time `json:"make_up_time,omitempty"`
+	NeedsSubstitute  bool       `gorm:"not null;default:false"                         json:"needs_substitute"` // 成员当天临时不可用，待安排替班
+	SubstituteReason string     `gorm:"type:varchar(200)"                              json:"substitute_reason,omitempty"`
+	SubstituteForID  *string    `gorm:"type:uuid"                                      json:"substitute_for_id,omitempty"` // 紧急替班生成的记录指向缺勤的原记录
+	// 管理员豁免签到网段限制（网络故障等）
+	NetworkOverride       bool   `gorm:"not null;default:false" json:"network_override"`
+	NetworkOverrideReason string `gorm:"type:varchar(200)"      json:"network_override_reason,omitempty"`
 	VersionedModel
 
 	// 关联
 	ScheduleItem *ScheduleItem `gorm:"foreignKey:ScheduleItemID;references:ScheduleItemID" json:"schedule_item,omitempty"`
+	EventShift   *EventShift   `gorm:"foreignKey:EventShiftID;references:EventShiftID"     json:"event_shift,omitempty"`
 	Member       *User         `gorm:"foreignKey:MemberID;references:UserID"                json:"member,omitempty"`
 }
 
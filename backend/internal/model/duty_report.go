@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// DutyReport 值班日志表 — 对应 duty_reports
+// 每条值班记录至多一篇日志，由值班成员签到后填写（交接班记录：来访、问题、遗留物品等）。
+// 地点与值班开始时刻为冗余快照，用于下一班次查看交接内容与按学期检索。
+type DutyReport struct {
+	DutyReportID string     `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"duty_report_id"`
+	DutyRecordID string     `gorm:"type:uuid;not null"                             json:"duty_record_id"`
+	MemberID     string     `gorm:"type:uuid;not null"                             json:"member_id"`
+	LocationID   *string    `gorm:"type:uuid"                                      json:"location_id,omitempty"`
+	DutyStart    time.Time  `gorm:"not null"                                       json:"duty_start"`
+	Content      string     `gorm:"type:varchar(2000);not null"                    json:"content"`
+	Tags         StringList `gorm:"type:jsonb;not null;default:'[]'"               json:"tags"`
+	Incident     bool       `gorm:"not null;default:false"                         json:"incident"` // 异常标记，标记时通知管理员
+	BaseModel
+
+	// 关联
+	DutyRecord *DutyRecord `gorm:"foreignKey:DutyRecordID;references:DutyRecordID" json:"duty_record,omitempty"`
+	Member     *User       `gorm:"foreignKey:MemberID;references:UserID"           json:"member,omitempty"`
+	Location   *Location   `gorm:"foreignKey:LocationID;references:LocationID"     json:"location,omitempty"`
+}
+
+// TableName 指定表名
+func (DutyReport) TableName() string { return "duty_reports" }
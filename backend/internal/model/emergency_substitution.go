@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// EmergencySubstitution 紧急替班广播表 — 对应 emergency_substitutions
+// 值班记录（DutyRecordID）被标记缺勤后向此刻空闲的成员广播替班请求，先到先得：
+// 第一位响应者（SubstituteID）获得一条新的值班记录（SubstituteRecordID，其 SubstituteForID 指向缺勤记录）。
+// ExpiresAt 为该次值班结束时间，之后不再接受响应。
+type EmergencySubstitution struct {
+	EmergencySubstitutionID string     `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"emergency_substitution_id"`
+	DutyRecordID            string     `gorm:"type:uuid;not null"                             json:"duty_record_id"`
+	Status                  string     `gorm:"type:varchar(20);not null;default:'open'"       json:"status"` // open | filled | cancelled
+	ExpiresAt               time.Time  `gorm:"not null"                                       json:"expires_at"`
+	NotifiedCount           int        `gorm:"not null;default:0"                             json:"notified_count"`
+	SubstituteID            *string    `gorm:"type:uuid"                                      json:"substitute_id,omitempty"`
+	SubstituteRecordID      *string    `gorm:"type:uuid"                                      json:"substitute_record_id,omitempty"`
+	FilledAt                *time.Time `json:"filled_at,omitempty"`
+	SoftDeleteModel
+
+	// 关联
+	DutyRecord *DutyRecord `gorm:"foreignKey:DutyRecordID;references:DutyRecordID" json:"duty_record,omitempty"`
+	Substitute *User       `gorm:"foreignKey:SubstituteID;references:UserID"       json:"substitute,omitempty"`
+}
+
+// TableName 指定表名
+func (EmergencySubstitution) TableName() string { return "emergency_substitutions" }
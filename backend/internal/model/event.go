@@ -0,0 +1,60 @@
+package model
+
+import "time"
+
+// Event 活动值班表 — 对应 events
+// 迎新、换届选举、晚会等一次性活动，不走每周排班模板，按具体日期的班次单独安排人手。
+type Event struct {
+	EventID     string `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"event_id"`
+	SemesterID  string `gorm:"type:uuid;not null"                             json:"semester_id"`
+	Name        string `gorm:"type:varchar(100);not null"                     json:"name"`
+	Description string `gorm:"type:varchar(500)"                              json:"description,omitempty"`
+	Status      string `gorm:"type:varchar(20);not null;default:'draft'"      json:"status"` // draft | published | cancelled
+	SignupOpen  bool   `gorm:"not null;default:false"                         json:"signup_open"`
+	VersionedModel
+
+	// 关联
+	Semester *Semester    `gorm:"foreignKey:SemesterID;references:SemesterID" json:"semester,omitempty"`
+	Shifts   []EventShift `gorm:"foreignKey:EventID"                          json:"shifts,omitempty"`
+}
+
+// TableName 指定表名
+func (Event) TableName() string { return "events" }
+
+// EventShift 活动班次表 — 对应 event_shifts
+type EventShift struct {
+	EventShiftID string    `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"event_shift_id"`
+	EventID      string    `gorm:"type:uuid;not null"                             json:"event_id"`
+	ShiftDate    time.Time `gorm:"type:date;not null"                             json:"shift_date"`
+	StartTime    string    `gorm:"type:time;not null"                             json:"start_time"`
+	EndTime      string    `gorm:"type:time;not null"                             json:"end_time"`
+	LocationID   *string   `gorm:"type:uuid"                                      json:"location_id,omitempty"`
+	Headcount    int       `gorm:"type:smallint;not null"                         json:"headcount"`
+	Note         string    `gorm:"type:varchar(200)"                              json:"note,omitempty"`
+	VersionedModel
+
+	// 关联
+	Event          *Event            `gorm:"foreignKey:EventID;references:EventID"       json:"event,omitempty"`
+	Location       *Location         `gorm:"foreignKey:LocationID;references:LocationID" json:"location,omitempty"`
+	Assignments    []EventAssignment `gorm:"foreignKey:EventShiftID"                     json:"assignments,omitempty"`
+	RequiredSkills []EventShiftSkill `gorm:"foreignKey:EventShiftID"                     json:"required_skills,omitempty"`
+}
+
+// TableName 指定表名
+func (EventShift) TableName() string { return "event_shifts" }
+
+// EventAssignment 活动班次人员表 — 对应 event_assignments
+type EventAssignment struct {
+	EventAssignmentID string `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"event_assignment_id"`
+	EventShiftID      string `gorm:"type:uuid;not null"                             json:"event_shift_id"`
+	MemberID          string `gorm:"type:uuid;not null"                             json:"member_id"`
+	Source            string `gorm:"type:varchar(20);not null"                      json:"source"` // signup | auto | admin
+	BaseModel
+
+	// 关联
+	EventShift *EventShift `gorm:"foreignKey:EventShiftID;references:EventShiftID" json:"event_shift,omitempty"`
+	Member     *User       `gorm:"foreignKey:MemberID;references:UserID"           json:"member,omitempty"`
+}
+
+// TableName 指定表名
+func (EventAssignment) TableName() string { return "event_assignments" }
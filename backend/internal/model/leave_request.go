@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// LeaveRequest 请假表 — 对应 leave_requests
+// 成员为某一具体日期的值班（DutyRecordID）请假，由本部门负责人或管理员审批并指定替班成员（SubstituteID），
+// 通过后该次值班记录改派给替班成员。SemesterID 冗余自排班表，用于按学期统计请假次数。
+type LeaveRequest struct {
+	LeaveRequestID string     `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"leave_request_id"`
+	DutyRecordID   string     `gorm:"type:uuid;not null"                             json:"duty_record_id"`
+	MemberID       string     `gorm:"type:uuid;not null"                             json:"member_id"`
+	SemesterID     string     `gorm:"type:uuid;not null"                             json:"semester_id"`
+	Reason         string     `gorm:"type:varchar(500);not null"                     json:"reason"`
+	AttachmentRef  string     `gorm:"type:varchar(500)"                              json:"attachment_ref,omitempty"` // 证明材料引用
+	Status         string     `gorm:"type:varchar(20);not null;default:'pending'"    json:"status"`                   // pending | approved | rejected | cancelled
+	SubstituteID   *string    `gorm:"type:uuid"                                      json:"substitute_id,omitempty"`
+	ReviewedBy     *string    `gorm:"type:uuid"                                      json:"reviewed_by,omitempty"`
+	ReviewedAt     *time.Time `json:"reviewed_at,omitempty"`
+	ReviewNote     string     `gorm:"type:varchar(500)"                              json:"review_note,omitempty"`
+	VersionedModel
+
+	// 关联
+	DutyRecord *DutyRecord `gorm:"foreignKey:DutyRecordID;references:DutyRecordID" json:"duty_record,omitempty"`
+	Member     *User       `gorm:"foreignKey:MemberID;references:UserID"           json:"member,omitempty"`
+	Substitute *User       `gorm:"foreignKey:SubstituteID;references:UserID"       json:"substitute,omitempty"`
+}
+
+// TableName 指定表名
+func (LeaveRequest) TableName() string { return "leave_requests" }
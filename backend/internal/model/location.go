@@ -1,16 +1,47 @@
 package model
 
-// Location 值班地点表 — 对应 locations（V1 预留扩展）
+// Location 值班地点表 — 对应 locations
 type Location struct {
 	LocationID string `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"location_id"`
 	Name       string `gorm:"type:varchar(100);not null"                     json:"name"`
 	Address    string `gorm:"type:varchar(200)"                              json:"address,omitempty"`
 	IsDefault  bool   `gorm:"not null;default:false"                         json:"is_default"`
 	IsActive   bool   `gorm:"not null;default:true"                          json:"is_active"`
+	Capacity   int    `gorm:"type:smallint;not null;default:1"               json:"capacity"`   // 同一时刻可容纳的值班人数
+	QRSignIn   bool   `gorm:"not null"                                       json:"qr_sign_in"` // 签到须扫描本地点展示屏上的轮换二维码
 	SoftDeleteModel
+
+	// 关联
+	Windows  []LocationWindow  `gorm:"foreignKey:LocationID" json:"windows,omitempty"`  // 开放时段，为空表示全天开放
+	Networks []LocationNetwork `gorm:"foreignKey:LocationID" json:"networks,omitempty"` // 允许签到的客户端网段，为空表示不限制
 }
 
 // TableName 指定表名
 func (Location) TableName() string { return "locations" }
 
+// LocationWindow 地点开放时段表 — 对应 location_windows
+type LocationWindow struct {
+	LocationWindowID string `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"location_window_id"`
+	LocationID       string `gorm:"type:uuid;not null"                             json:"location_id"`
+	DayOfWeek        int    `gorm:"type:smallint;not null"                         json:"day_of_week"` // 1-7
+	StartTime        string `gorm:"type:time;not null"                             json:"start_time"`
+	EndTime          string `gorm:"type:time;not null"                             json:"end_time"`
+	BaseModel
+}
+
+// TableName 指定表名
+func (LocationWindow) TableName() string { return "location_windows" }
+
+// LocationNetwork 地点签到网段表 — 对应 location_networks
+type LocationNetwork struct {
+	LocationNetworkID string `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"location_network_id"`
+	LocationID        string `gorm:"type:uuid;not null"                             json:"location_id"`
+	CIDR              string `gorm:"column:cidr;type:cidr;not null"                 json:"cidr"` // 如 10.20.0.0/16
+	Note              string `gorm:"type:varchar(100)"                              json:"note,omitempty"`
+	BaseModel
+}
+
+// TableName 指定表名
+func (LocationNetwork) TableName() string { return "location_networks" }
+
 // [自证通过] internal/model/location.go
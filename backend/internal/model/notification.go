@@ -8,7 +8,7 @@ type Notification struct {
 	Title          string  `gorm:"type:varchar(200);not null"                     json:"title"`
 	Content        string  `gorm:"type:text;not null"                             json:"content"`
 	IsRead         bool    `gorm:"not null;default:false"                         json:"is_read"`
-	RelatedType    *string `gorm:"type:varchar(20)"                               json:"related_type,omitempty"` // schedule | schedule_item | swap_request | duty_record
+	RelatedType    *string `gorm:"type:varchar(20)"                               json:"related_type,omitempty"` // schedule | schedule_item | swap_request | duty_record | leave_request | emergency_substitution
 	RelatedID      *string `gorm:"type:uuid"                                      json:"related_id,omitempty"`
 	SoftDeleteModel
 }
@@ -0,0 +1,20 @@
+package model
+
+// PairConstraint 成员搭配约束表 — 对应 pair_constraints
+// must_pair：需同时段值班；must_not_pair：不能同时段值班；not_adjacent：不能排在同日相邻时段
+type PairConstraint struct {
+	PairConstraintID string `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"pair_constraint_id"`
+	SemesterID       string `gorm:"type:uuid;not null"                             json:"semester_id"`
+	MemberAID        string `gorm:"type:uuid;not null"                             json:"member_a_id"`
+	MemberBID        string `gorm:"type:uuid;not null"                             json:"member_b_id"`
+	Kind             string `gorm:"type:varchar(20);not null"                      json:"kind"` // must_pair | must_not_pair | not_adjacent
+	Note             string `gorm:"type:varchar(200)"                              json:"note,omitempty"`
+	SoftDeleteModel
+
+	// 关联
+	MemberA *User `gorm:"foreignKey:MemberAID;references:UserID" json:"member_a,omitempty"`
+	MemberB *User `gorm:"foreignKey:MemberBID;references:UserID" json:"member_b,omitempty"`
+}
+
+// TableName 指定表名
+func (PairConstraint) TableName() string { return "pair_constraints" }
@@ -6,8 +6,17 @@ import "time"
 type Schedule struct {
 	ScheduleID  string     `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"schedule_id"`
 	SemesterID  string     `gorm:"type:uuid;not null"                             json:"semester_id"`
-	Status      string     `gorm:"type:varchar(20);not null;default:'draft'"      json:"status"` // draft | published | need_regen | archived
+	Status      string     `gorm:"type:varchar(20);not null;default:'draft'"      json:"status"` // draft | published | need_regen | archived | candidate
 	PublishedAt *time.Time `json:"published_at,omitempty"`
+
+	// 生成参数（用于区分和复现多个候选方案）
+	Name          string        `gorm:"type:varchar(100)"                          json:"name,omitempty"`
+	Solver        string        `gorm:"type:varchar(20);not null;default:'greedy'" json:"solver"` // greedy | local_search
+	Seed          *int64        `json:"seed,omitempty"`
+	RuleOverrides RuleOverrides `gorm:"type:jsonb"                                 json:"rule_overrides,omitempty"`
+
+	// 由历史版本恢复而来时记录来源排班表
+	RestoredFromID *string `gorm:"type:uuid" json:"restored_from_id,omitempty"`
 	VersionedModel
 
 	// 关联
@@ -49,17 +58,18 @@ func (ScheduleMemberSnapshot) TableName() string { return "schedule_member_snaps
 
 // ScheduleChangeLog 排班变更记录表 — 对应 schedule_change_logs（纯审计日志）
 type ScheduleChangeLog struct {
-	ChangeLogID        string    `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"change_log_id"`
-	ScheduleID         string    `gorm:"type:uuid;not null"                             json:"schedule_id"`
-	ScheduleItemID     string    `gorm:"type:uuid;not null"                             json:"schedule_item_id"`
-	OriginalMemberID   string    `gorm:"type:uuid;not null"                             json:"original_member_id"`
-	NewMemberID        string    `gorm:"type:uuid;not null"                             json:"new_member_id"`
-	OriginalTimeSlotID *string   `gorm:"type:uuid"                                      json:"original_time_slot_id,omitempty"`
-	NewTimeSlotID      *string   `gorm:"type:uuid"                                      json:"new_time_slot_id,omitempty"`
-	ChangeType         string    `gorm:"type:varchar(20);not null"                      json:"change_type"` // manual_adjust | swap | admin_modify
-	Reason             string    `gorm:"type:varchar(500)"                              json:"reason,omitempty"`
-	OperatorID         string    `gorm:"type:uuid;not null"                             json:"operator_id"`
-	CreatedAt          time.Time `gorm:"not null;default:CURRENT_TIMESTAMP"             json:"created_at"`
+	ChangeLogID        string     `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"change_log_id"`
+	ScheduleID         string     `gorm:"type:uuid;not null"                             json:"schedule_id"`
+	ScheduleItemID     string     `gorm:"type:uuid;not null"                             json:"schedule_item_id"`
+	OriginalMemberID   string     `gorm:"type:uuid;not null"                             json:"original_member_id"`
+	NewMemberID        string     `gorm:"type:uuid;not null"                             json:"new_member_id"`
+	OriginalTimeSlotID *string    `gorm:"type:uuid"                                      json:"original_time_slot_id,omitempty"`
+	NewTimeSlotID      *string    `gorm:"type:uuid"                                      json:"new_time_slot_id,omitempty"`
+	DutyDate           *time.Time `gorm:"type:date"                                      json:"duty_date,omitempty"` // 单次值班变更（转让 / 请假）的日期；为空表示排班项整体变更
+	ChangeType         string     `gorm:"type:varchar(20);not null"                      json:"change_type"`         // manual_adjust | swap | admin_modify | leave
+	Reason             string     `gorm:"type:varchar(500)"                              json:"reason,omitempty"`
+	OperatorID         string     `gorm:"type:uuid;not null"                             json:"operator_id"`
+	CreatedAt          time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP"             json:"created_at"`
 }
 
 func (ScheduleChangeLog) TableName() string { return "schedule_change_logs" }
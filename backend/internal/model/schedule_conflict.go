@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// ScheduleConflict 排班冲突处理任务表 — 对应 schedule_conflicts
+// 排班发布后成员修改时间表（重新导入课表 / 新增不可用时间），与其已发布排班项冲突时生成，
+// 同一排班项同时至多一条 open 任务。
+type ScheduleConflict struct {
+	ScheduleConflictID string     `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"schedule_conflict_id"`
+	ScheduleID         string     `gorm:"type:uuid;not null"                             json:"schedule_id"`
+	ScheduleItemID     string     `gorm:"type:uuid;not null"                             json:"schedule_item_id"`
+	MemberID           string     `gorm:"type:uuid;not null"                             json:"member_id"`
+	Source             string     `gorm:"type:varchar(20);not null"                      json:"source"` // course | unavailable
+	Detail             string     `gorm:"type:varchar(500);not null"                     json:"detail"`
+	Status             string     `gorm:"type:varchar(20);not null;default:'open'"       json:"status"` // open | resolved | dismissed
+	ResolutionNote     string     `gorm:"type:varchar(200)"                              json:"resolution_note,omitempty"`
+	ResolvedAt         *time.Time `json:"resolved_at,omitempty"`
+	ResolvedBy         *string    `gorm:"type:uuid"                                      json:"resolved_by,omitempty"`
+	BaseModel
+
+	// 关联
+	ScheduleItem *ScheduleItem `gorm:"foreignKey:ScheduleItemID;references:ScheduleItemID" json:"schedule_item,omitempty"`
+	Member       *User         `gorm:"foreignKey:MemberID;references:UserID"                json:"member,omitempty"`
+}
+
+// TableName 指定表名
+func (ScheduleConflict) TableName() string { return "schedule_conflicts" }
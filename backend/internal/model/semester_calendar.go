@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// SemesterCalendarDay 学期校历特殊日期表 — 对应 semester_calendar_days
+// holiday：节假日，当天不值班；workday：调休上班日，按 RunsAsDayOfWeek / RunsAsWeek 执行对应课表与排班
+type SemesterCalendarDay struct {
+	CalendarDayID   string    `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"calendar_day_id"`
+	SemesterID      string    `gorm:"type:uuid;not null"                             json:"semester_id"`
+	Date            time.Time `gorm:"type:date;not null"                             json:"date"`
+	Kind            string    `gorm:"type:varchar(20);not null"                      json:"kind"` // holiday | workday
+	Name            string    `gorm:"type:varchar(100)"                              json:"name,omitempty"`
+	RunsAsDayOfWeek *int      `gorm:"type:smallint"                                  json:"runs_as_day_of_week,omitempty"` // 1-7，为空表示按自然星期
+	RunsAsWeek      *int      `gorm:"type:smallint"                                  json:"runs_as_week,omitempty"`        // 教学周次，为空表示按自然周次
+	Source          string    `gorm:"type:varchar(20);not null;default:'manual'"     json:"source"`                        // manual | ics
+	SoftDeleteModel
+}
+
+// TableName 指定表名
+func (SemesterCalendarDay) TableName() string { return "semester_calendar_days" }
@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// SignInRejection 签到拒绝审计表 — 对应 sign_in_rejections
+// 因客户端网络不在地点允许网段内、或二维码无效 / 不属于该地点 / 已被使用而被拒绝的签到与签退，
+// 供负责人核查远程签到的尝试。只增不改。
+type SignInRejection struct {
+	SignInRejectionID string    `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"sign_in_rejection_id"`
+	DutyRecordID      string    `gorm:"type:uuid;not null"                             json:"duty_record_id"`
+	MemberID          string    `gorm:"type:uuid;not null"                             json:"member_id"`
+	LocationID        *string   `gorm:"type:uuid"                                      json:"location_id,omitempty"`
+	Action            string    `gorm:"type:varchar(10);not null"                      json:"action"` // sign_in | sign_out
+	Reason            string    `gorm:"type:varchar(20);not null"                      json:"reason"` // network | qr_invalid | qr_wrong_location | qr_used
+	ClientIP          string    `gorm:"type:inet;not null"                             json:"client_ip"`
+	Detail            string    `gorm:"type:varchar(200)"                              json:"detail,omitempty"`
+	CreatedAt         time.Time `gorm:"not null;default:CURRENT_TIMESTAMP"             json:"created_at"`
+
+	// 关联
+	DutyRecord *DutyRecord `gorm:"foreignKey:DutyRecordID;references:DutyRecordID" json:"duty_record,omitempty"`
+	Member     *User       `gorm:"foreignKey:MemberID;references:UserID"           json:"member,omitempty"`
+	Location   *Location   `gorm:"foreignKey:LocationID;references:LocationID"     json:"location,omitempty"`
+}
+
+// TableName 指定表名
+func (SignInRejection) TableName() string { return "sign_in_rejections" }
@@ -0,0 +1,52 @@
+package model
+
+// Skill 技能目录表 — 对应 skills
+// 如"音响设备操作""办公室钥匙"，时间段 / 活动班次可要求值班成员具备指定技能。
+type Skill struct {
+	SkillID     string `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"skill_id"`
+	Name        string `gorm:"type:varchar(50);not null"                      json:"name"`
+	Description string `gorm:"type:varchar(200)"                              json:"description,omitempty"`
+	SoftDeleteModel
+}
+
+// TableName 指定表名
+func (Skill) TableName() string { return "skills" }
+
+// UserSkill 成员技能表 — 对应 user_skills
+type UserSkill struct {
+	UserID  string `gorm:"type:uuid;primaryKey" json:"user_id"`
+	SkillID string `gorm:"type:uuid;primaryKey" json:"skill_id"`
+	BaseModel
+
+	// 关联
+	Skill *Skill `gorm:"foreignKey:SkillID;references:SkillID" json:"skill,omitempty"`
+}
+
+// TableName 指定表名
+func (UserSkill) TableName() string { return "user_skills" }
+
+// TimeSlotSkill 时间段所需技能表 — 对应 time_slot_skills
+type TimeSlotSkill struct {
+	TimeSlotID string `gorm:"type:uuid;primaryKey" json:"time_slot_id"`
+	SkillID    string `gorm:"type:uuid;primaryKey" json:"skill_id"`
+	BaseModel
+
+	// 关联
+	Skill *Skill `gorm:"foreignKey:SkillID;references:SkillID" json:"skill,omitempty"`
+}
+
+// TableName 指定表名
+func (TimeSlotSkill) TableName() string { return "time_slot_skills" }
+
+// EventShiftSkill 活动班次所需技能表 — 对应 event_shift_skills
+type EventShiftSkill struct {
+	EventShiftID string `gorm:"type:uuid;primaryKey" json:"event_shift_id"`
+	SkillID      string `gorm:"type:uuid;primaryKey" json:"skill_id"`
+	BaseModel
+
+	// 关联
+	Skill *Skill `gorm:"foreignKey:SkillID;references:SkillID" json:"skill,omitempty"`
+}
+
+// TableName 指定表名
+func (EventShiftSkill) TableName() string { return "event_shift_skills" }
@@ -3,23 +3,34 @@ package model
 import "time"
 
 // SwapRequest 换班申请表 — 对应 swap_requests
+// kind = transfer 指定接班人（TargetMemberID 必填）；
+// kind = give_away 转让某一具体日期的值班（DutyRecordID 必填），TargetMemberID 为认领人，认领前为空；
+// kind = exchange 与对方互换：ScheduleItemID ↔ TargetScheduleItemID（此后每周生效），
+// 或 DutyRecordID ↔ TargetDutyRecordID（仅互换这两次值班）。
 type SwapRequest struct {
-	SwapRequestID     string     `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"swap_request_id"`
-	ScheduleItemID    string     `gorm:"type:uuid;not null"                             json:"schedule_item_id"`
-	ApplicantID       string     `gorm:"type:uuid;not null"                             json:"applicant_id"`
-	TargetMemberID    string     `gorm:"type:uuid;not null"                             json:"target_member_id"`
-	Reason            string     `gorm:"type:varchar(500)"                              json:"reason,omitempty"`
-	Status            string     `gorm:"type:varchar(20);not null;default:'pending'"    json:"status"` // pending | reviewing | completed | rejected | cancelled
-	TargetRespondedAt *time.Time `json:"target_responded_at,omitempty"`
-	ApprovedAt        *time.Time `json:"approved_at,omitempty"`
-	ApprovedBy        *string    `gorm:"type:uuid"                                      json:"approved_by,omitempty"`
-	RejectReason      string     `gorm:"type:varchar(500)"                              json:"reject_reason,omitempty"`
+	SwapRequestID        string     `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"swap_request_id"`
+	ScheduleItemID       string     `gorm:"type:uuid;not null"                             json:"schedule_item_id"`
+	DutyRecordID         *string    `gorm:"type:uuid"                                      json:"duty_record_id,omitempty"`
+	Kind                 string     `gorm:"type:varchar(20);not null;default:'transfer'"   json:"kind"` // transfer | give_away | exchange
+	TargetScheduleItemID *string    `gorm:"type:uuid"                                      json:"target_schedule_item_id,omitempty"`
+	TargetDutyRecordID   *string    `gorm:"type:uuid"                                      json:"target_duty_record_id,omitempty"`
+	ApplicantID          string     `gorm:"type:uuid;not null"                             json:"applicant_id"`
+	TargetMemberID       *string    `gorm:"type:uuid"                                      json:"target_member_id,omitempty"`
+	Reason               string     `gorm:"type:varchar(500)"                              json:"reason,omitempty"`
+	Status               string     `gorm:"type:varchar(20);not null;default:'pending'"    json:"status"` // pending | reviewing | completed | rejected | cancelled
+	TargetRespondedAt    *time.Time `json:"target_responded_at,omitempty"`
+	ApprovedAt           *time.Time `json:"approved_at,omitempty"`
+	ApprovedBy           *string    `gorm:"type:uuid"                                      json:"approved_by,omitempty"`
+	RejectReason         string     `gorm:"type:varchar(500)"                              json:"reject_reason,omitempty"`
 	VersionedModel
 
 	// 关联
-	ScheduleItem *ScheduleItem `gorm:"foreignKey:ScheduleItemID;references:ScheduleItemID" json:"schedule_item,omitempty"`
-	Applicant    *User         `gorm:"foreignKey:ApplicantID;references:UserID"             json:"applicant,omitempty"`
-	TargetMember *User         `gorm:"foreignKey:TargetMemberID;references:UserID"          json:"target_member,omitempty"`
+	ScheduleItem       *ScheduleItem `gorm:"foreignKey:ScheduleItemID;references:ScheduleItemID" json:"schedule_item,omitempty"`
+	DutyRecord         *DutyRecord   `gorm:"foreignKey:DutyRecordID;references:DutyRecordID"     json:"duty_record,omitempty"`
+	TargetScheduleItem *ScheduleItem `gorm:"foreignKey:TargetScheduleItemID;references:ScheduleItemID" json:"target_schedule_item,omitempty"`
+	TargetDutyRecord   *DutyRecord   `gorm:"foreignKey:TargetDutyRecordID;references:DutyRecordID"     json:"target_duty_record,omitempty"`
+	Applicant          *User         `gorm:"foreignKey:ApplicantID;references:UserID"             json:"applicant,omitempty"`
+	TargetMember       *User         `gorm:"foreignKey:TargetMemberID;references:UserID"          json:"target_member,omitempty"`
 }
 
 // TableName 指定表名
@@ -2,12 +2,25 @@ package model
 
 // SystemConfig 系统配置表 — 对应 system_config（单行强类型）
 type SystemConfig struct {
-	Singleton            bool   `gorm:"primaryKey;default:true"                  json:"-"`
-	SwapDeadlineHours    int    `gorm:"not null;default:24"                      json:"swap_deadline_hours"`
-	DutyReminderTime     string `gorm:"type:time;not null;default:'09:00'"       json:"duty_reminder_time"`
-	DefaultLocation      string `gorm:"type:varchar(200);not null;default:'学生会办公室'" json:"default_location"`
-	SignInWindowMinutes  int    `gorm:"not null;default:15"                      json:"sign_in_window_minutes"`
-	SignOutWindowMinutes int    `gorm:"not null;default:15"                      json:"sign_out_window_minutes"`
+	Singleton               bool   `gorm:"primaryKey;default:true"                  json:"-"`
+	SwapDeadlineHours       int    `gorm:"not null;default:24"                      json:"swap_deadline_hours"`
+	DutyReminderTime        string `gorm:"type:time;not null;default:'09:00'"       json:"duty_reminder_time"`
+	DefaultLocation         string `gorm:"type:varchar(200);not null;default:'学生会办公室'" json:"default_location"`
+	SignInWindowMinutes     int    `gorm:"not null;default:15"                      json:"sign_in_window_minutes"`
+	SignOutWindowMinutes    int    `gorm:"not null;default:15"                      json:"sign_out_window_minutes"`
+	TimetableConflictPolicy string `gorm:"type:varchar(20);not null;default:'notify'" json:"timetable_conflict_policy"` // 发布后时间表变更与排班冲突：notify | block
+	MaxShiftsPerWeek        int    `gorm:"not null;default:2"                       json:"max_shifts_per_week"`         // R8 每人每周班次上限（0 不限）
+	MaxShiftsPerWeekMode    string `gorm:"type:varchar(10);not null;default:'soft'" json:"max_shifts_per_week_mode"`    // hard | soft
+	MinRestHours            int    `gorm:"not null;default:12"                      json:"min_rest_hours"`              // R9 相邻班次最小间隔小时数（0 不限）
+	MinRestMode             string `gorm:"type:varchar(10);not null;default:'soft'" json:"min_rest_mode"`               // hard | soft
+	BackToBackDaysMode      string `gorm:"type:varchar(10);not null;default:'soft'" json:"back_to_back_days_mode"`      // R10 hard | soft
+	SwapRequiresApproval    bool   `gorm:"not null;default:true"                    json:"swap_requires_approval"`      // 值班转让被认领后是否需管理员审批
+	// 换班自动审批全局策略（部门可在 department_swap_policies 中整体覆盖）
+	SwapAutoApprove               bool `gorm:"not null;default:false" json:"swap_auto_approve"`
+	SwapAutoApproveSameDepartment bool `gorm:"not null;default:true"  json:"swap_auto_approve_same_department"`  // 双方须同部门
+	SwapAutoApproveMinHours       int  `gorm:"not null;default:48"    json:"swap_auto_approve_min_hours"`        // 申请须早于值班开始的小时数
+	SwapAutoApproveMaxPerSemester int  `gorm:"not null;default:3"     json:"swap_auto_approve_max_per_semester"` // 申请人本学期已完成换班须少于该数，0 不限
+	EmergencySubstituteEnabled    bool `gorm:"not null;default:false" json:"emergency_substitute_enabled"`       // 标记缺勤时默认广播紧急替班
 	BaseModel
 }
 
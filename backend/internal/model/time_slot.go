@@ -9,10 +9,16 @@ type TimeSlot struct {
 	EndTime    string  `gorm:"type:time;not null"                             json:"end_time"`
 	DayOfWeek  int     `gorm:"type:smallint;not null"                         json:"day_of_week"` // 1-5
 	IsActive   bool    `gorm:"not null;default:true"                          json:"is_active"`
+	// CostMultiplier 冷门系数：班次成本 = 时长（小时）× 系数，默认 1
+	CostMultiplier float64 `gorm:"type:numeric(4,2);not null;default:1"       json:"cost_multiplier"`
+	// LocationID 绑定地点（NULL 表示由自动排班选择）
+	LocationID *string `gorm:"type:uuid" json:"location_id,omitempty"`
 	VersionedModel
 
 	// 关联
-	Semester *Semester `gorm:"foreignKey:SemesterID;references:SemesterID" json:"semester,omitempty"`
+	Semester       *Semester       `gorm:"foreignKey:SemesterID;references:SemesterID" json:"semester,omitempty"`
+	Location       *Location       `gorm:"foreignKey:LocationID;references:LocationID" json:"location,omitempty"`
+	RequiredSkills []TimeSlotSkill `gorm:"foreignKey:TimeSlotID"                       json:"required_skills,omitempty"`
 }
 
 // TableName 指定表名
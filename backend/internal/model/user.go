@@ -14,6 +14,7 @@ type User struct {
 
 	// 关联
 	Department *Department `gorm:"foreignKey:DepartmentID;references:DepartmentID" json:"department,omitempty"`
+	Skills     []UserSkill `gorm:"foreignKey:UserID"                               json:"skills,omitempty"`
 }
 
 // TableName 指定表名
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"echo-union/backend/internal/model"
+)
+
+// DepartmentSwapPolicyRepository 部门换班自动审批策略数据访问接口
+type DepartmentSwapPolicyRepository interface {
+	// GetByDepartment 获取部门策略，无覆盖时返回 gorm.ErrRecordNotFound
+	GetByDepartment(ctx context.Context, departmentID string) (*model.DepartmentSwapPolicy, error)
+	// List 列出全部部门策略（预加载部门）
+	List(ctx context.Context) ([]model.DepartmentSwapPolicy, error)
+	// Save 新建或整体覆盖部门策略
+	Save(ctx context.Context, policy *model.DepartmentSwapPolicy) error
+	// Delete 删除部门策略（恢复使用全局策略）
+	Delete(ctx context.Context, departmentID string) error
+}
+
+type departmentSwapPolicyRepo struct {
+	db *gorm.DB
+}
+
+// NewDepartmentSwapPolicyRepo 创建 DepartmentSwapPolicyRepository 实例
+func NewDepartmentSwapPolicyRepo(db *gorm.DB) DepartmentSwapPolicyRepository {
+	return &departmentSwapPolicyRepo{db: db}
+}
+
+func (r *departmentSwapPolicyRepo) GetByDepartment(ctx context.Context, departmentID string) (*model.DepartmentSwapPolicy, error) {
+	var policy model.DepartmentSwapPolicy
+	err := r.db.WithContext(ctx).
+		Preload("Department").
+		Where("department_id = ?", departmentID).
+		First(&policy).Error
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (r *departmentSwapPolicyRepo) List(ctx context.Context) ([]model.DepartmentSwapPolicy, error) {
+	var policies []model.DepartmentSwapPolicy
+	err := r.db.WithContext(ctx).
+		Preload("Department").
+		Order("created_at ASC").
+		Find(&policies).Error
+	return policies, err
+}
+
+func (r *departmentSwapPolicyRepo) Save(ctx context.Context, policy *model.DepartmentSwapPolicy) error {
+	return r.db.WithContext(ctx).Omit("Department").Save(policy).Error
+}
+
+func (r *departmentSwapPolicyRepo) Delete(ctx context.Context, departmentID string) error {
+	return r.db.WithContext(ctx).
+		Where("department_id = ?", departmentID).
+		Delete(&model.DepartmentSwapPolicy{}).Error
+}
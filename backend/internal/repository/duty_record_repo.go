@@ -0,0 +1,282 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"echo-union/backend/internal/model"
+)
+
+// AttendanceCount 按成员、状态、是否迟到、是否替班分组的值班记录数与计划时长（小时）
+type AttendanceCount struct {
+	MemberID     string
+	Status       string
+	IsLate       bool
+	IsSubstitute bool
+	Count        int64
+	Hours        float64
+}
+
+// DutyRecordRepository 值班记录数据访问接口
+type DutyRecordRepository interface {
+	BatchCreate(ctx context.Context, records []model.DutyRecord) error
+	// GetByID 获取值班记录（预加载排班项、时间段、地点、活动班次与成员）
+	GetByID(ctx context.Context, id string) (*model.DutyRecord, error)
+	// ListByScheduleFrom 列出排班表自 from（含）起的值班记录
+	ListByScheduleFrom(ctx context.Context, scheduleID string, from time.Time) ([]model.DutyRecord, error)
+	// DeletePendingByIDs 软删除指定的尚未开始的值班记录（已开始的记录不受影响）
+	DeletePendingByIDs(ctx context.Context, ids []string, deletedBy string) error
+	// UpdatePendingMemberByItemFrom 将排班项自 from（含）起尚未开始且仍属于 fromMemberID 的值班记录改派给 toMemberID
+	// （已转让、互换或替班给他人的记录不受影响）
+	UpdatePendingMemberByItemFrom(ctx context.Context, scheduleItemID string, from time.Time, fromMemberID, toMemberID, updatedBy string) error
+	// ReassignPending 将尚未开始且仍属于 fromMemberID 的单条值班记录改派给 toMemberID，返回受影响行数（0 表示记录已变更）
+	ReassignPending(ctx context.Context, id, fromMemberID, toMemberID, updatedBy string) (int64, error)
+	// ListPendingByMemberAndDate 列出成员在某日尚未开始的值班记录（预加载排班项与时间段）
+	ListPendingByMemberAndDate(ctx context.Context, scheduleID, memberID string, date time.Time) ([]model.DutyRecord, error)
+	// ListPendingByMemberFrom 列出成员自 from（含）起尚未开始的值班记录（预加载排班项、时间段与地点），按日期升序
+	ListPendingByMemberFrom(ctx context.Context, scheduleID, memberID string, from time.Time) ([]model.DutyRecord, error)
+	// ListNeedingSubstitute 列出排班表自 from（含）起需替班的值班记录（预加载排班项、时间段与成员）
+	ListNeedingSubstitute(ctx context.Context, scheduleID string, from time.Time) ([]model.DutyRecord, error)
+	// UpdateSubstitute 设置 / 清除值班记录的需替班标记
+	UpdateSubstitute(ctx context.Context, id string, needs bool, reason, updatedBy string) error
+	// MarkAbsent 将尚未签到的值班记录标记为缺勤，返回受影响行数（0 表示记录已变更）
+	MarkAbsent(ctx context.Context, id, updatedBy string) (int64, error)
+	// SignIn 为尚未签到的值班记录登记签到，返回受影响行数（0 表示记录已变更）
+	SignIn(ctx context.Context, id string, at time.Time, isLate bool, updatedBy string) (int64, error)
+	// SignOut 为值班中的记录登记签退，返回受影响行数（0 表示记录已变更）
+	SignOut(ctx context.Context, id string, at time.Time, updatedBy string) (int64, error)
+	// SetNetworkOverride 设置 / 取消值班记录的签到网段豁免
+	SetNetworkOverride(ctx context.Context, id string, enabled bool, reason, updatedBy string) error
+	// CountAttendance 统计 [from, to] 内的值班记录（周常与活动）；departmentID 非空时只统计该部门成员
+	CountAttendance(ctx context.Context, from, to time.Time, departmentID string) ([]AttendanceCount, error)
+	// DeletePendingByEventShifts 软删除活动班次尚未开始的值班记录；memberID 为空时删除班次下全部成员的记录
+	DeletePendingByEventShifts(ctx context.Context, shiftIDs []string, memberID, deletedBy string) error
+}
+
+type dutyRecordRepo struct {
+	db *gorm.DB
+}
+
+// NewDutyRecordRepo 创建 DutyRecordRepository 实例
+func NewDutyRecordRepo(db *gorm.DB) DutyRecordRepository {
+	return &dutyRecordRepo{db: db}
+}
+
+func (r *dutyRecordRepo) BatchCreate(ctx context.Context, records []model.DutyRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Create(&records).Error
+}
+
+func (r *dutyRecordRepo) GetByID(ctx context.Context, id string) (*model.DutyRecord, error) {
+	var record model.DutyRecord
+	err := r.db.WithContext(ctx).
+		Preload("ScheduleItem.TimeSlot.RequiredSkills.Skill").
+		Preload("ScheduleItem.Location").
+		Preload("EventShift.Location").
+		Preload("Member").
+		Where("duty_record_id = ?", id).
+		First(&record).Error
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// scheduleItemsOf 子查询：排班表下的排班项 ID
+func (r *dutyRecordRepo) scheduleItemsOf(ctx context.Context, scheduleID string) *gorm.DB {
+	return r.db.WithContext(ctx).
+		Model(&model.ScheduleItem{}).
+		Select("schedule_item_id").
+		Where("schedule_id = ?", scheduleID)
+}
+
+func (r *dutyRecordRepo) ListByScheduleFrom(ctx context.Context, scheduleID string, from time.Time) ([]model.DutyRecord, error) {
+	var records []model.DutyRecord
+	err := r.db.WithContext(ctx).
+		Where("schedule_item_id IN (?) AND duty_date >= ?", r.scheduleItemsOf(ctx, scheduleID), from.Format(model.TimeFormatDate)).
+		Order("duty_date ASC").
+		Find(&records).Error
+	return records, err
+}
+
+func (r *dutyRecordRepo) DeletePendingByIDs(ctx context.Context, ids []string, deletedBy string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).
+		Model(&model.DutyRecord{}).
+		Where("duty_record_id IN ? AND status = ?", ids, model.DutyRecordStatusPending).
+		Updates(map[string]interface{}{
+			"deleted_by": deletedBy,
+			"deleted_at": gorm.Expr("NOW()"),
+		}).Error
+}
+
+func (r *dutyRecordRepo) UpdatePendingMemberByItemFrom(ctx context.Context, scheduleItemID string, from time.Time, fromMemberID, toMemberID, updatedBy string) error {
+	return r.db.WithContext(ctx).
+		Model(&model.DutyRecord{}).
+		Where("schedule_item_id = ? AND member_id = ? AND duty_date >= ? AND status = ?",
+			scheduleItemID, fromMemberID, from.Format(model.TimeFormatDate), model.DutyRecordStatusPending).
+		Updates(map[string]interface{}{
+			"member_id":         toMemberID,
+			"needs_substitute":  false, // 改派即视为已安排替班
+			"substitute_reason": "",
+			"updated_by":        updatedBy,
+			"version":           gorm.Expr("version + 1"),
+		}).Error
+}
+
+func (r *dutyRecordRepo) ReassignPending(ctx context.Context, id, fromMemberID, toMemberID, updatedBy string) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.DutyRecord{}).
+		Where("duty_record_id = ? AND member_id = ? AND status = ?", id, fromMemberID, model.DutyRecordStatusPending).
+		Updates(map[string]interface{}{
+			"member_id":         toMemberID,
+			"needs_substitute":  false,
+			"substitute_reason": "",
+			"updated_by":        updatedBy,
+			"version":           gorm.Expr("version + 1"),
+		})
+	return result.RowsAffected, result.Error
+}
+
+func (r *dutyRecordRepo) ListPendingByMemberAndDate(ctx context.Context, scheduleID, memberID string, date time.Time) ([]model.DutyRecord, error) {
+	var records []model.DutyRecord
+	err := r.db.WithContext(ctx).
+		Preload("ScheduleItem.TimeSlot").
+		Preload("ScheduleItem.Location").
+		Where("schedule_item_id IN (?) AND member_id = ? AND duty_date = ? AND status = ?",
+			r.scheduleItemsOf(ctx, scheduleID), memberID, date.Format(model.TimeFormatDate), model.DutyRecordStatusPending).
+		Find(&records).Error
+	return records, err
+}
+
+func (r *dutyRecordRepo) ListPendingByMemberFrom(ctx context.Context, scheduleID, memberID string, from time.Time) ([]model.DutyRecord, error) {
+	var records []model.DutyRecord
+	err := r.db.WithContext(ctx).
+		Preload("ScheduleItem.TimeSlot.RequiredSkills.Skill").
+		Preload("ScheduleItem.Location").
+		Where("schedule_item_id IN (?) AND member_id = ? AND duty_date >= ? AND status = ?",
+			r.scheduleItemsOf(ctx, scheduleID), memberID, from.Format(model.TimeFormatDate), model.DutyRecordStatusPending).
+		Order("duty_date ASC").
+		Find(&records).Error
+	return records, err
+}
+
+func (r *dutyRecordRepo) ListNeedingSubstitute(ctx context.Context, scheduleID string, from time.Time) ([]model.DutyRecord, error) {
+	var records []model.DutyRecord
+	err := r.db.WithContext(ctx).
+		Preload("ScheduleItem.TimeSlot").
+		Preload("ScheduleItem.Location").
+		Preload("Member").
+		Where("schedule_item_id IN (?) AND duty_date >= ? AND needs_substitute AND status = ?",
+			r.scheduleItemsOf(ctx, scheduleID), from.Format(model.TimeFormatDate), model.DutyRecordStatusPending).
+		Order("duty_date ASC").
+		Find(&records).Error
+	return records, err
+}
+
+func (r *dutyRecordRepo) UpdateSubstitute(ctx context.Context, id string, needs bool, reason, updatedBy string) error {
+	return r.db.WithContext(ctx).
+		Model(&model.DutyRecord{}).
+		Where("duty_record_id = ?", id).
+		Updates(map[string]interface{}{
+			"needs_substitute":  needs,
+			"substitute_reason": reason,
+			"updated_by":        updatedBy,
+			"version":           gorm.Expr("version + 1"),
+		}).Error
+}
+
+func (r *dutyRecordRepo) MarkAbsent(ctx context.Context, id, updatedBy string) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.DutyRecord{}).
+		Where("duty_record_id = ? AND status = ?", id, model.DutyRecordStatusPending).
+		Updates(map[string]interface{}{
+			"status":     model.DutyRecordStatusAbsent,
+			"updated_by": updatedBy,
+			"version":    gorm.Expr("version + 1"),
+		})
+	return result.RowsAffected, result.Error
+}
+
+func (r *dutyRecordRepo) SignIn(ctx context.Context, id string, at time.Time, isLate bool, updatedBy string) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.DutyRecord{}).
+		Where("duty_record_id = ? AND status = ?", id, model.DutyRecordStatusPending).
+		Updates(map[string]interface{}{
+			"status":       model.DutyRecordStatusOnDuty,
+			"sign_in_time": at,
+			"is_late":      isLate,
+			"updated_by":   updatedBy,
+			"version":      gorm.Expr("version + 1"),
+		})
+	return result.RowsAffected, result.Error
+}
+
+func (r *dutyRecordRepo) SignOut(ctx context.Context, id string, at time.Time, updatedBy string) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.DutyRecord{}).
+		Where("duty_record_id = ? AND status = ?", id, model.DutyRecordStatusOnDuty).
+		Updates(map[string]interface{}{
+			"status":        model.DutyRecordStatusCompleted,
+			"sign_out_time": at,
+			"updated_by":    updatedBy,
+			"version":       gorm.Expr("version + 1"),
+		})
+	return result.RowsAffected, result.Error
+}
+
+func (r *dutyRecordRepo) SetNetworkOverride(ctx context.Context, id string, enabled bool, reason, updatedBy string) error {
+	return r.db.WithContext(ctx).
+		Model(&model.DutyRecord{}).
+		Where("duty_record_id = ?", id).
+		Updates(map[string]interface{}{
+			"network_override":        enabled,
+			"network_override_reason": reason,
+			"updated_by":              updatedBy,
+			"version":                 gorm.Expr("version + 1"),
+		}).Error
+}
+
+func (r *dutyRecordRepo) CountAttendance(ctx context.Context, from, to time.Time, departmentID string) ([]AttendanceCount, error) {
+	var counts []AttendanceCount
+	db := r.db.WithContext(ctx).
+		Model(&model.DutyRecord{}).
+		Select(`duty_records.member_id, duty_records.status, duty_records.is_late,
+			duty_records.substitute_for_id IS NOT NULL AS is_substitute,
+			COUNT(*) AS count,
+			COALESCE(SUM(EXTRACT(EPOCH FROM COALESCE(time_slots.end_time - time_slots.start_time,
+				event_shifts.end_time - event_shifts.start_time))), 0) / 3600 AS hours`).
+		Joins("LEFT JOIN schedule_items ON schedule_items.schedule_item_id = duty_records.schedule_item_id").
+		Joins("LEFT JOIN time_slots ON time_slots.time_slot_id = schedule_items.time_slot_id").
+		Joins("LEFT JOIN event_shifts ON event_shifts.event_shift_id = duty_records.event_shift_id").
+		Where("duty_records.duty_date BETWEEN ? AND ?", from.Format(model.TimeFormatDate), to.Format(model.TimeFormatDate))
+	if departmentID != "" {
+		db = db.Where("duty_records.member_id IN (?)", r.db.WithContext(ctx).
+			Model(&model.User{}).Select("user_id").Where("department_id = ?", departmentID))
+	}
+	err := db.Group("duty_records.member_id, duty_records.status, duty_records.is_late, is_substitute").
+		Scan(&counts).Error
+	return counts, err
+}
+
+func (r *dutyRecordRepo) DeletePendingByEventShifts(ctx context.Context, shiftIDs []string, memberID, deletedBy string) error {
+	if len(shiftIDs) == 0 {
+		return nil
+	}
+	db := r.db.WithContext(ctx).
+		Model(&model.DutyRecord{}).
+		Where("event_shift_id IN ? AND status = ?", shiftIDs, model.DutyRecordStatusPending)
+	if memberID != "" {
+		db = db.Where("member_id = ?", memberID)
+	}
+	return db.Updates(map[string]interface{}{
+		"deleted_by": deletedBy,
+		"deleted_at": gorm.Expr("NOW()"),
+	}).Error
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"echo-union/backend/internal/model"
+)
+
+// DutyReportFilters 值班日志检索条件
+type DutyReportFilters struct {
+	MemberID     string
+	DepartmentID string // 成员所在部门
+	LocationID   string
+	Tag          string
+	Keyword      string // 正文模糊匹配
+	Incident     *bool
+	From         *time.Time // 值班开始时刻，含
+	To           *time.Time // 值班开始时刻，不含
+}
+
+// DutyReportRepository 值班日志数据访问接口
+type DutyReportRepository interface {
+	// GetByDutyRecord 获取值班记录的日志（预加载值班记录、成员与地点），无日志时返回 gorm.ErrRecordNotFound
+	GetByDutyRecord(ctx context.Context, dutyRecordID string) (*model.DutyReport, error)
+	// Save 新建或整体覆盖值班日志
+	Save(ctx context.Context, report *model.DutyReport) error
+	// GetLatestAtLocation 同一地点值班开始时刻在 [since, before) 内的最近一篇日志
+	GetLatestAtLocation(ctx context.Context, locationID string, since, before time.Time) (*model.DutyReport, error)
+	// ListWithFilters 按值班开始时刻倒序检索
+	ListWithFilters(ctx context.Context, filters *DutyReportFilters, offset, limit int) ([]model.DutyReport, int64, error)
+}
+
+type dutyReportRepo struct {
+	db *gorm.DB
+}
+
+// NewDutyReportRepo 创建 DutyReportRepository 实例
+func NewDutyReportRepo(db *gorm.DB) DutyReportRepository {
+	return &dutyReportRepo{db: db}
+}
+
+// withDetails 预加载日志详情所需的关联
+func (r *dutyReportRepo) withDetails(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("DutyRecord.ScheduleItem.TimeSlot").
+		Preload("DutyRecord.ScheduleItem.Location").
+		Preload("DutyRecord.EventShift.Location").
+		Preload("Member.Department").
+		Preload("Location")
+}
+
+func (r *dutyReportRepo) GetByDutyRecord(ctx context.Context, dutyRecordID string) (*model.DutyReport, error) {
+	var report model.DutyReport
+	err := r.withDetails(r.db.WithContext(ctx)).
+		Where("duty_record_id = ?", dutyRecordID).
+		First(&report).Error
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+func (r *dutyReportRepo) Save(ctx context.Context, report *model.DutyReport) error {
+	return r.db.WithContext(ctx).Omit("DutyRecord", "Member", "Location").Save(report).Error
+}
+
+func (r *dutyReportRepo) GetLatestAtLocation(ctx context.Context, locationID string, since, before time.Time) (*model.DutyReport, error) {
+	var report model.DutyReport
+	err := r.withDetails(r.db.WithContext(ctx)).
+		Where("location_id = ? AND duty_start >= ? AND duty_start < ?", locationID, since, before).
+		Order("duty_start DESC").
+		First(&report).Error
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+func (r *dutyReportRepo) ListWithFilters(ctx context.Context, filters *DutyReportFilters, offset, limit int) ([]model.DutyReport, int64, error) {
+	var reports []model.DutyReport
+	var total int64
+
+	db := r.db.WithContext(ctx).Model(&model.DutyReport{})
+	if filters != nil {
+		if filters.MemberID != "" {
+			db = db.Where("member_id = ?", filters.MemberID)
+		}
+		if filters.DepartmentID != "" {
+			db = db.Where("member_id IN (?)", r.db.WithContext(ctx).
+				Model(&model.User{}).Select("user_id").Where("department_id = ?", filters.DepartmentID))
+		}
+		if filters.LocationID != "" {
+			db = db.Where("location_id = ?", filters.LocationID)
+		}
+		if filters.Tag != "" {
+			tag, _ := json.Marshal([]string{filters.Tag})
+			db = db.Where("tags @> ?::jsonb", string(tag))
+		}
+		if filters.Keyword != "" {
+			db = db.Where(`content ILIKE ? ESCAPE '\'`, "%"+escapeLike(filters.Keyword)+"%")
+		}
+		if filters.Incident != nil {
+			db = db.Where("incident = ?", *filters.Incident)
+		}
+		if filters.From != nil {
+			db = db.Where("duty_start >= ?", *filters.From)
+		}
+		if filters.To != nil {
+			db = db.Where("duty_start < ?", *filters.To)
+		}
+	}
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := r.withDetails(db).
+		Order("duty_start DESC").
+		Offset(offset).Limit(limit).
+		Find(&reports).Error
+	return reports, total, err
+}
+
+// escapeLike 转义 LIKE 模式中的通配符 % 与 _ 及转义符本身，使关键词按字面匹配
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"echo-union/backend/internal/model"
+)
+
+// EmergencySubstitutionRepository 紧急替班广播数据访问接口
+type EmergencySubstitutionRepository interface {
+	Create(ctx context.Context, e *model.EmergencySubstitution) error
+	// GetByID 获取广播（预加载缺勤记录及其排班项、时间段、地点与成员，以及接班成员）
+	GetByID(ctx context.Context, id string) (*model.EmergencySubstitution, error)
+	// GetByDutyRecord 获取缺勤记录的广播
+	GetByDutyRecord(ctx context.Context, dutyRecordID string) (*model.EmergencySubstitution, error)
+	// Fill 广播仍开放且未过期时由 substituteID 接班，返回受影响行数（0 表示已被接班、已取消或已过期）
+	Fill(ctx context.Context, id, substituteID, substituteRecordID string) (int64, error)
+	// Cancel 取消开放中的广播，返回受影响行数
+	Cancel(ctx context.Context, id, cancelledBy string) (int64, error)
+}
+
+type emergencySubstitutionRepo struct {
+	db *gorm.DB
+}
+
+// NewEmergencySubstitutionRepo 创建 EmergencySubstitutionRepository 实例
+func NewEmergencySubstitutionRepo(db *gorm.DB) EmergencySubstitutionRepository {
+	return &emergencySubstitutionRepo{db: db}
+}
+
+func (r *emergencySubstitutionRepo) Create(ctx context.Context, e *model.EmergencySubstitution) error {
+	return r.db.WithContext(ctx).Create(e).Error
+}
+
+// withDetails 预加载广播详情所需的关联
+func (r *emergencySubstitutionRepo) withDetails(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("DutyRecord.ScheduleItem.TimeSlot.RequiredSkills.Skill").
+		Preload("DutyRecord.ScheduleItem.Location").
+		Preload("DutyRecord.Member").
+		Preload("Substitute")
+}
+
+func (r *emergencySubstitutionRepo) GetByID(ctx context.Context, id string) (*model.EmergencySubstitution, error) {
+	var e model.EmergencySubstitution
+	err := r.withDetails(r.db.WithContext(ctx)).
+		Where("emergency_substitution_id = ?", id).
+		First(&e).Error
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (r *emergencySubstitutionRepo) GetByDutyRecord(ctx context.Context, dutyRecordID string) (*model.EmergencySubstitution, error) {
+	var e model.EmergencySubstitution
+	err := r.withDetails(r.db.WithContext(ctx)).
+		Where("duty_record_id = ?", dutyRecordID).
+		First(&e).Error
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (r *emergencySubstitutionRepo) Fill(ctx context.Context, id, substituteID, substituteRecordID string) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.EmergencySubstitution{}).
+		Where("emergency_substitution_id = ? AND status = ? AND expires_at > NOW()", id, model.EmergencyStatusOpen).
+		Updates(map[string]interface{}{
+			"status":               model.EmergencyStatusFilled,
+			"substitute_id":        substituteID,
+			"substitute_record_id": substituteRecordID,
+			"filled_at":            gorm.Expr("NOW()"),
+			"updated_by":           substituteID,
+		})
+	return result.RowsAffected, result.Error
+}
+
+func (r *emergencySubstitutionRepo) Cancel(ctx context.Context, id, cancelledBy string) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.EmergencySubstitution{}).
+		Where("emergency_substitution_id = ? AND status = ?", id, model.EmergencyStatusOpen).
+		Updates(map[string]interface{}{
+			"status":     model.EmergencyStatusCancelled,
+			"updated_by": cancelledBy,
+		})
+	return result.RowsAffected, result.Error
+}
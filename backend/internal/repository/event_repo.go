@@ -0,0 +1,223 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"echo-union/backend/internal/model"
+)
+
+// EventFilters 活动列表筛选条件
+type EventFilters struct {
+	SemesterID string
+	Status     string
+	// ExcludeDraft 排除草稿（非管理员只能看到已发布 / 已取消的活动）
+	ExcludeDraft bool
+}
+
+// EventRepository 活动数据访问接口
+type EventRepository interface {
+	Create(ctx context.Context, event *model.Event) error
+	GetByID(ctx context.Context, id string) (*model.Event, error)
+	List(ctx context.Context, filters *EventFilters) ([]model.Event, error)
+	Update(ctx context.Context, event *model.Event) error
+	Delete(ctx context.Context, id string, deletedBy string) error
+}
+
+// EventShiftRepository 活动班次数据访问接口
+type EventShiftRepository interface {
+	Create(ctx context.Context, shift *model.EventShift) error
+	// GetByID 获取班次（预加载活动、地点与人员）
+	GetByID(ctx context.Context, id string) (*model.EventShift, error)
+	// ListByEvent 列出活动的班次（预加载地点与人员），按日期、开始时间排序
+	ListByEvent(ctx context.Context, eventID string) ([]model.EventShift, error)
+	Update(ctx context.Context, shift *model.EventShift) error
+	Delete(ctx context.Context, id string, deletedBy string) error
+}
+
+// EventAssignmentRepository 活动班次人员数据访问接口
+type EventAssignmentRepository interface {
+	Create(ctx context.Context, assignment *model.EventAssignment) error
+	Delete(ctx context.Context, shiftID, memberID string) error
+	DeleteByShift(ctx context.Context, shiftID string) error
+	// ListActiveBySemester 列出学期内未取消活动的全部班次人员（预加载班次），用于可用性与负载计算
+	ListActiveBySemester(ctx context.Context, semesterID string) ([]model.EventAssignment, error)
+	// ListByMemberFrom 列出成员自 from（含）起已发布活动的班次（预加载班次、活动与地点）
+	ListByMemberFrom(ctx context.Context, memberID string, from time.Time) ([]model.EventAssignment, error)
+}
+
+// ── Event Repository 实现 ──
+
+type eventRepo struct {
+	db *gorm.DB
+}
+
+// NewEventRepo 创建 EventRepository 实例
+func NewEventRepo(db *gorm.DB) EventRepository {
+	return &eventRepo{db: db}
+}
+
+func (r *eventRepo) Create(ctx context.Context, event *model.Event) error {
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+func (r *eventRepo) GetByID(ctx context.Context, id string) (*model.Event, error) {
+	var event model.Event
+	err := r.db.WithContext(ctx).
+		Where("event_id = ?", id).
+		First(&event).Error
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+func (r *eventRepo) List(ctx context.Context, filters *EventFilters) ([]model.Event, error) {
+	var events []model.Event
+	db := r.db.WithContext(ctx)
+
+	if filters.SemesterID != "" {
+		db = db.Where("semester_id = ?", filters.SemesterID)
+	}
+	if filters.Status != "" {
+		db = db.Where("status = ?", filters.Status)
+	}
+	if filters.ExcludeDraft {
+		db = db.Where("status <> ?", model.EventStatusDraft)
+	}
+
+	err := db.Order("created_at DESC").Find(&events).Error
+	return events, err
+}
+
+func (r *eventRepo) Update(ctx context.Context, event *model.Event) error {
+	return r.db.WithContext(ctx).Save(event).Error
+}
+
+func (r *eventRepo) Delete(ctx context.Context, id string, deletedBy string) error {
+	return r.db.WithContext(ctx).
+		Model(&model.Event{}).
+		Where("event_id = ?", id).
+		Updates(map[string]interface{}{
+			"deleted_by": deletedBy,
+			"deleted_at": gorm.Expr("NOW()"),
+		}).Error
+}
+
+// ── EventShift Repository 实现 ──
+
+type eventShiftRepo struct {
+	db *gorm.DB
+}
+
+// NewEventShiftRepo 创建 EventShiftRepository 实例
+func NewEventShiftRepo(db *gorm.DB) EventShiftRepository {
+	return &eventShiftRepo{db: db}
+}
+
+func (r *eventShiftRepo) Create(ctx context.Context, shift *model.EventShift) error {
+	return r.db.WithContext(ctx).Create(shift).Error
+}
+
+func (r *eventShiftRepo) GetByID(ctx context.Context, id string) (*model.EventShift, error) {
+	var shift model.EventShift
+	err := r.db.WithContext(ctx).
+		Preload("Event").
+		Preload("Location").
+		Preload("Assignments", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC")
+		}).
+		Preload("Assignments.Member.Department").
+		Preload("RequiredSkills.Skill").
+		Where("event_shift_id = ?", id).
+		First(&shift).Error
+	if err != nil {
+		return nil, err
+	}
+	return &shift, nil
+}
+
+func (r *eventShiftRepo) ListByEvent(ctx context.Context, eventID string) ([]model.EventShift, error) {
+	var shifts []model.EventShift
+	err := r.db.WithContext(ctx).
+		Preload("Location").
+		Preload("Assignments", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC")
+		}).
+		Preload("Assignments.Member.Department").
+		Preload("RequiredSkills.Skill").
+		Where("event_id = ?", eventID).
+		Order("shift_date ASC, start_time ASC").
+		Find(&shifts).Error
+	return shifts, err
+}
+
+func (r *eventShiftRepo) Update(ctx context.Context, shift *model.EventShift) error {
+	return r.db.WithContext(ctx).
+		Omit("Event", "Location", "Assignments", "RequiredSkills").
+		Save(shift).Error
+}
+
+func (r *eventShiftRepo) Delete(ctx context.Context, id string, deletedBy string) error {
+	return r.db.WithContext(ctx).
+		Model(&model.EventShift{}).
+		Where("event_shift_id = ?", id).
+		Updates(map[string]interface{}{
+			"deleted_by": deletedBy,
+			"deleted_at": gorm.Expr("NOW()"),
+		}).Error
+}
+
+// ── EventAssignment Repository 实现 ──
+
+type eventAssignmentRepo struct {
+	db *gorm.DB
+}
+
+// NewEventAssignmentRepo 创建 EventAssignmentRepository 实例
+func NewEventAssignmentRepo(db *gorm.DB) EventAssignmentRepository {
+	return &eventAssignmentRepo{db: db}
+}
+
+func (r *eventAssignmentRepo) Create(ctx context.Context, assignment *model.EventAssignment) error {
+	return r.db.WithContext(ctx).Create(assignment).Error
+}
+
+func (r *eventAssignmentRepo) Delete(ctx context.Context, shiftID, memberID string) error {
+	return r.db.WithContext(ctx).
+		Where("event_shift_id = ? AND member_id = ?", shiftID, memberID).
+		Delete(&model.EventAssignment{}).Error
+}
+
+func (r *eventAssignmentRepo) DeleteByShift(ctx context.Context, shiftID string) error {
+	return r.db.WithContext(ctx).
+		Where("event_shift_id = ?", shiftID).
+		Delete(&model.EventAssignment{}).Error
+}
+
+func (r *eventAssignmentRepo) ListActiveBySemester(ctx context.Context, semesterID string) ([]model.EventAssignment, error) {
+	var assignments []model.EventAssignment
+	err := r.db.WithContext(ctx).
+		Preload("EventShift").
+		Joins("JOIN event_shifts s ON s.event_shift_id = event_assignments.event_shift_id AND s.deleted_at IS NULL").
+		Joins("JOIN events e ON e.event_id = s.event_id AND e.deleted_at IS NULL").
+		Where("e.semester_id = ? AND e.status <> ?", semesterID, model.EventStatusCancelled).
+		Find(&assignments).Error
+	return assignments, err
+}
+
+func (r *eventAssignmentRepo) ListByMemberFrom(ctx context.Context, memberID string, from time.Time) ([]model.EventAssignment, error) {
+	var assignments []model.EventAssignment
+	err := r.db.WithContext(ctx).
+		Preload("EventShift.Event").
+		Preload("EventShift.Location").
+		Joins("JOIN event_shifts s ON s.event_shift_id = event_assignments.event_shift_id AND s.deleted_at IS NULL").
+		Joins("JOIN events e ON e.event_id = s.event_id AND e.deleted_at IS NULL").
+		Where("event_assignments.member_id = ? AND s.shift_date >= ? AND e.status = ?",
+			memberID, from.Format(model.TimeFormatDate), model.EventStatusPublished).
+		Order("s.shift_date ASC, s.start_time ASC").
+		Find(&assignments).Error
+	return assignments, err
+}
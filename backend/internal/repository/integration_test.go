@@ -48,6 +48,10 @@ func TestMain(m *testing.M) {
 		&model.Semester{},
 		&model.TimeSlot{},
 		&model.Location{},
+		&model.LocationWindow{},
+		&model.Skill{},
+		&model.UserSkill{},
+		&model.TimeSlotSkill{},
 		&model.Schedule{},
 		&model.ScheduleItem{},
 		&model.ScheduleMemberSnapshot{},
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"echo-union/backend/internal/model"
+)
+
+// LeaveRequestFilters 请假列表筛选条件
+type LeaveRequestFilters struct {
+	MemberID     string
+	DepartmentID string // 请假成员所在部门
+	Status       string
+	SemesterID   string
+}
+
+// LeaveCount 成员某一状态的请假次数
+type LeaveCount struct {
+	MemberID string
+	Status   string
+	Count    int64
+}
+
+// LeaveRequestRepository 请假数据访问接口
+type LeaveRequestRepository interface {
+	Create(ctx context.Context, leave *model.LeaveRequest) error
+	// GetByID 获取请假（预加载值班记录及其时间段、地点，请假成员与替班成员）
+	GetByID(ctx context.Context, id string) (*model.LeaveRequest, error)
+	ListWithFilters(ctx context.Context, filters *LeaveRequestFilters, offset, limit int) ([]model.LeaveRequest, int64, error)
+	// CountPendingByDutyRecord 统计该值班记录待审批的请假数
+	CountPendingByDutyRecord(ctx context.Context, dutyRecordID string) (int64, error)
+	// CountByMember 按成员、状态统计学期内的请假次数；departmentID 非空时只统计该部门成员
+	CountByMember(ctx context.Context, semesterID, departmentID string) ([]LeaveCount, error)
+	// Review 审批待审批的请假，status 为 approved / rejected；返回受影响行数（0 表示已撤回或已审批）
+	Review(ctx context.Context, id, status string, substituteID *string, note, reviewedBy string) (int64, error)
+	// Cancel 请假成员撤回待审批的请假，返回受影响行数
+	Cancel(ctx context.Context, id, memberID string) (int64, error)
+}
+
+type leaveRequestRepo struct {
+	db *gorm.DB
+}
+
+// NewLeaveRequestRepo 创建 LeaveRequestRepository 实例
+func NewLeaveRequestRepo(db *gorm.DB) LeaveRequestRepository {
+	return &leaveRequestRepo{db: db}
+}
+
+func (r *leaveRequestRepo) Create(ctx context.Context, leave *model.LeaveRequest) error {
+	return r.db.WithContext(ctx).Create(leave).Error
+}
+
+// withDetails 预加载请假详情所需的关联
+func (r *leaveRequestRepo) withDetails(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("DutyRecord.ScheduleItem.TimeSlot.RequiredSkills.Skill").
+		Preload("DutyRecord.ScheduleItem.Location").
+		Preload("DutyRecord.Member").
+		Preload("Member.Department").
+		Preload("Substitute.Department")
+}
+
+func (r *leaveRequestRepo) GetByID(ctx context.Context, id string) (*model.LeaveRequest, error) {
+	var leave model.LeaveRequest
+	err := r.withDetails(r.db.WithContext(ctx)).
+		Where("leave_request_id = ?", id).
+		First(&leave).Error
+	if err != nil {
+		return nil, err
+	}
+	return &leave, nil
+}
+
+func (r *leaveRequestRepo) ListWithFilters(ctx context.Context, filters *LeaveRequestFilters, offset, limit int) ([]model.LeaveRequest, int64, error) {
+	var leaves []model.LeaveRequest
+	var total int64
+
+	db := r.db.WithContext(ctx).Model(&model.LeaveRequest{})
+	if filters != nil {
+		if filters.MemberID != "" {
+			db = db.Where("member_id = ?", filters.MemberID)
+		}
+		if filters.DepartmentID != "" {
+			db = db.Where("member_id IN (?)", r.db.WithContext(ctx).
+				Model(&model.User{}).Select("user_id").Where("department_id = ?", filters.DepartmentID))
+		}
+		if filters.Status != "" {
+			db = db.Where("status = ?", filters.Status)
+		}
+		if filters.SemesterID != "" {
+			db = db.Where("semester_id = ?", filters.SemesterID)
+		}
+	}
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := r.withDetails(db).
+		Order("created_at DESC").
+		Offset(offset).Limit(limit).
+		Find(&leaves).Error
+	return leaves, total, err
+}
+
+func (r *leaveRequestRepo) CountPendingByDutyRecord(ctx context.Context, dutyRecordID string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&model.LeaveRequest{}).
+		Where("duty_record_id = ? AND status = ?", dutyRecordID, model.LeaveStatusPending).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *leaveRequestRepo) CountByMember(ctx context.Context, semesterID, departmentID string) ([]LeaveCount, error) {
+	var counts []LeaveCount
+	db := r.db.WithContext(ctx).
+		Model(&model.LeaveRequest{}).
+		Select("member_id, status, COUNT(*) AS count").
+		Where("semester_id = ?", semesterID)
+	if departmentID != "" {
+		db = db.Where("member_id IN (?)", r.db.WithContext(ctx).
+			Model(&model.User{}).Select("user_id").Where("department_id = ?", departmentID))
+	}
+	err := db.Group("member_id, status").Scan(&counts).Error
+	return counts, err
+}
+
+func (r *leaveRequestRepo) Review(ctx context.Context, id, status string, substituteID *string, note, reviewedBy string) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.LeaveRequest{}).
+		Where("leave_request_id = ? AND status = ?", id, model.LeaveStatusPending).
+		Updates(map[string]interface{}{
+			"status":        status,
+			"substitute_id": substituteID,
+			"review_note":   note,
+			"reviewed_at":   gorm.Expr("NOW()"),
+			"reviewed_by":   reviewedBy,
+			"updated_by":    reviewedBy,
+			"version":       gorm.Expr("version + 1"),
+		})
+	return result.RowsAffected, result.Error
+}
+
+func (r *leaveRequestRepo) Cancel(ctx context.Context, id, memberID string) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.LeaveRequest{}).
+		Where("leave_request_id = ? AND member_id = ? AND status = ?", id, memberID, model.LeaveStatusPending).
+		Updates(map[string]interface{}{
+			"status":     model.LeaveStatusCancelled,
+			"updated_by": memberID,
+			"version":    gorm.Expr("version + 1"),
+		})
+	return result.RowsAffected, result.Error
+}
@@ -15,6 +15,10 @@ type LocationRepository interface {
 	List(ctx context.Context, includeInactive bool) ([]model.Location, error)
 	Update(ctx context.Context, loc *model.Location) error
 	Delete(ctx context.Context, id string, deletedBy string) error
+	// ReplaceWindows 在事务中全量替换地点开放时段
+	ReplaceWindows(ctx context.Context, locationID string, windows []model.LocationWindow) error
+	// ReplaceNetworks 在事务中全量替换地点签到网段
+	ReplaceNetworks(ctx context.Context, locationID string, networks []model.LocationNetwork) error
 }
 
 type locationRepo struct {
@@ -33,6 +37,8 @@ func (r *locationRepo) Create(ctx context.Context, loc *model.Location) error {
 func (r *locationRepo) GetByID(ctx context.Context, id string) (*model.Location, error) {
 	var loc model.Location
 	err := r.db.WithContext(ctx).
+		Preload("Windows", orderWindows).
+		Preload("Networks", orderNetworks).
 		Where("location_id = ?", id).
 		First(&loc).Error
 	if err != nil {
@@ -49,12 +55,15 @@ func (r *locationRepo) List(ctx context.Context, includeInactive bool) ([]model.
 		db = db.Where("is_active = ?", true)
 	}
 
-	err := db.Order("is_default DESC, name ASC").Find(&locations).Error
+	err := db.Preload("Windows", orderWindows).
+		Preload("Networks", orderNetworks).
+		Order("is_default DESC, name ASC").
+		Find(&locations).Error
 	return locations, err
 }
 
 func (r *locationRepo) Update(ctx context.Context, loc *model.Location) error {
-	return r.db.WithContext(ctx).Save(loc).Error
+	return r.db.WithContext(ctx).Omit("Windows", "Networks").Save(loc).Error
 }
 
 func (r *locationRepo) Delete(ctx context.Context, id string, deletedBy string) error {
@@ -66,3 +75,37 @@ func (r *locationRepo) Delete(ctx context.Context, id string, deletedBy string)
 			"deleted_at": gorm.Expr("NOW()"),
 		}).Error
 }
+
+func (r *locationRepo) ReplaceWindows(ctx context.Context, locationID string, windows []model.LocationWindow) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("location_id = ?", locationID).Delete(&model.LocationWindow{}).Error; err != nil {
+			return err
+		}
+		if len(windows) > 0 {
+			return tx.Create(&windows).Error
+		}
+		return nil
+	})
+}
+
+func (r *locationRepo) ReplaceNetworks(ctx context.Context, locationID string, networks []model.LocationNetwork) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("location_id = ?", locationID).Delete(&model.LocationNetwork{}).Error; err != nil {
+			return err
+		}
+		if len(networks) > 0 {
+			return tx.Create(&networks).Error
+		}
+		return nil
+	})
+}
+
+// orderWindows 开放时段按星期、开始时间排序
+func orderWindows(db *gorm.DB) *gorm.DB {
+	return db.Order("day_of_week ASC, start_time ASC")
+}
+
+// orderNetworks 签到网段按网段排序
+func orderNetworks(db *gorm.DB) *gorm.DB {
+	return db.Order("cidr ASC")
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"echo-union/backend/internal/model"
+)
+
+// NotificationRepository 通知消息数据访问接口
+type NotificationRepository interface {
+	BatchCreate(ctx context.Context, notifications []model.Notification) error
+	ListByUser(ctx context.Context, userID string, unreadOnly bool, offset, limit int) ([]model.Notification, int64, error)
+	// MarkRead 标记单条通知已读，返回受影响行数（0 表示不存在或不属于该用户）
+	MarkRead(ctx context.Context, id, userID string) (int64, error)
+	MarkAllRead(ctx context.Context, userID string) error
+}
+
+type notificationRepo struct {
+	db *gorm.DB
+}
+
+// NewNotificationRepo 创建 NotificationRepository 实例
+func NewNotificationRepo(db *gorm.DB) NotificationRepository {
+	return &notificationRepo{db: db}
+}
+
+func (r *notificationRepo) BatchCreate(ctx context.Context, notifications []model.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Create(&notifications).Error
+}
+
+func (r *notificationRepo) ListByUser(ctx context.Context, userID string, unreadOnly bool, offset, limit int) ([]model.Notification, int64, error) {
+	var notifications []model.Notification
+	var total int64
+
+	db := r.db.WithContext(ctx).Model(&model.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		db = db.Where("is_read = ?", false)
+	}
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := db.Order("created_at DESC").
+		Offset(offset).Limit(limit).
+		Find(&notifications).Error
+	return notifications, total, err
+}
+
+func (r *notificationRepo) MarkRead(ctx context.Context, id, userID string) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.Notification{}).
+		Where("notification_id = ? AND user_id = ?", id, userID).
+		Updates(map[string]interface{}{
+			"is_read":    true,
+			"updated_by": userID,
+		})
+	return result.RowsAffected, result.Error
+}
+
+func (r *notificationRepo) MarkAllRead(ctx context.Context, userID string) error {
+	return r.db.WithContext(ctx).
+		Model(&model.Notification{}).
+		Where("user_id = ? AND is_read = ?", userID, false).
+		Updates(map[string]interface{}{
+			"is_read":    true,
+			"updated_by": userID,
+		}).Error
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"echo-union/backend/internal/model"
+)
+
+// PairConstraintRepository 成员搭配约束数据访问接口
+type PairConstraintRepository interface {
+	Create(ctx context.Context, constraint *model.PairConstraint) error
+	GetByID(ctx context.Context, id string) (*model.PairConstraint, error)
+	// ListBySemester 列出学期全部搭配约束（预加载双方成员）
+	ListBySemester(ctx context.Context, semesterID string) ([]model.PairConstraint, error)
+	Delete(ctx context.Context, id string, deletedBy string) error
+}
+
+type pairConstraintRepo struct {
+	db *gorm.DB
+}
+
+// NewPairConstraintRepo 创建 PairConstraintRepository 实例
+func NewPairConstraintRepo(db *gorm.DB) PairConstraintRepository {
+	return &pairConstraintRepo{db: db}
+}
+
+func (r *pairConstraintRepo) Create(ctx context.Context, constraint *model.PairConstraint) error {
+	return r.db.WithContext(ctx).Create(constraint).Error
+}
+
+func (r *pairConstraintRepo) GetByID(ctx context.Context, id string) (*model.PairConstraint, error) {
+	var constraint model.PairConstraint
+	err := r.db.WithContext(ctx).
+		Preload("MemberA").
+		Preload("MemberB").
+		Where("pair_constraint_id = ?", id).
+		First(&constraint).Error
+	if err != nil {
+		return nil, err
+	}
+	return &constraint, nil
+}
+
+func (r *pairConstraintRepo) ListBySemester(ctx context.Context, semesterID string) ([]model.PairConstraint, error) {
+	var constraints []model.PairConstraint
+	err := r.db.WithContext(ctx).
+		Preload("MemberA").
+		Preload("MemberB").
+		Where("semester_id = ?", semesterID).
+		Order("created_at ASC").
+		Find(&constraints).Error
+	return constraints, err
+}
+
+func (r *pairConstraintRepo) Delete(ctx context.Context, id string, deletedBy string) error {
+	return r.db.WithContext(ctx).
+		Model(&model.PairConstraint{}).
+		Where("pair_constraint_id = ?", id).
+		Updates(map[string]interface{}{
+			"deleted_by": deletedBy,
+			"deleted_at": gorm.Expr("NOW()"),
+		}).Error
+}
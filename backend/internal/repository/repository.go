@@ -23,6 +23,21 @@ type Repository struct {
 	ScheduleItem           ScheduleItemRepository
 	ScheduleMemberSnapshot ScheduleMemberSnapshotRepository
 	ScheduleChangeLog      ScheduleChangeLogRepository
+	SemesterCalendar       SemesterCalendarRepository
+	DutyRecord             DutyRecordRepository
+	Notification           NotificationRepository
+	ScheduleConflict       ScheduleConflictRepository
+	Event                  EventRepository
+	EventShift             EventShiftRepository
+	EventAssignment        EventAssignmentRepository
+	PairConstraint         PairConstraintRepository
+	Skill                  SkillRepository
+	SwapRequest            SwapRequestRepository
+	DepartmentSwapPolicy   DepartmentSwapPolicyRepository
+	LeaveRequest           LeaveRequestRepository
+	EmergencySubstitution  EmergencySubstitutionRepository
+	SignInRejection        SignInRejectionRepository
+	DutyReport             DutyReportRepository
 }
 
 // NewRepository 创建 Repository 聚合
@@ -43,6 +58,21 @@ func NewRepository(db *gorm.DB) *Repository {
 		ScheduleItem:           NewScheduleItemRepo(db),
 		ScheduleMemberSnapshot: NewScheduleMemberSnapshotRepo(db),
 		ScheduleChangeLog:      NewScheduleChangeLogRepo(db),
+		SemesterCalendar:       NewSemesterCalendarRepo(db),
+		DutyRecord:             NewDutyRecordRepo(db),
+		Notification:           NewNotificationRepo(db),
+		ScheduleConflict:       NewScheduleConflictRepo(db),
+		Event:                  NewEventRepo(db),
+		EventShift:             NewEventShiftRepo(db),
+		EventAssignment:        NewEventAssignmentRepo(db),
+		PairConstraint:         NewPairConstraintRepo(db),
+		Skill:                  NewSkillRepo(db),
+		SwapRequest:            NewSwapRequestRepo(db),
+		DepartmentSwapPolicy:   NewDepartmentSwapPolicyRepo(db),
+		LeaveRequest:           NewLeaveRequestRepo(db),
+		EmergencySubstitution:  NewEmergencySubstitutionRepo(db),
+		SignInRejection:        NewSignInRejectionRepo(db),
+		DutyReport:             NewDutyReportRepo(db),
 	}
 }
 
@@ -83,5 +113,20 @@ func (r *Repository) WithTx(tx *gorm.DB) *Repository {
 		ScheduleItem:           NewScheduleItemRepo(tx),
 		ScheduleMemberSnapshot: NewScheduleMemberSnapshotRepo(tx),
 		ScheduleChangeLog:      NewScheduleChangeLogRepo(tx),
+		SemesterCalendar:       NewSemesterCalendarRepo(tx),
+		DutyRecord:             NewDutyRecordRepo(tx),
+		Notification:           NewNotificationRepo(tx),
+		ScheduleConflict:       NewScheduleConflictRepo(tx),
+		Event:                  NewEventRepo(tx),
+		EventShift:             NewEventShiftRepo(tx),
+		EventAssignment:        NewEventAssignmentRepo(tx),
+		PairConstraint:         NewPairConstraintRepo(tx),
+		Skill:                  NewSkillRepo(tx),
+		SwapRequest:            NewSwapRequestRepo(tx),
+		DepartmentSwapPolicy:   NewDepartmentSwapPolicyRepo(tx),
+		LeaveRequest:           NewLeaveRequestRepo(tx),
+		EmergencySubstitution:  NewEmergencySubstitutionRepo(tx),
+		SignInRejection:        NewSignInRejectionRepo(tx),
+		DutyReport:             NewDutyReportRepo(tx),
 	}
 }
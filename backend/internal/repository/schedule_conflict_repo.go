@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"echo-union/backend/internal/model"
+)
+
+// ScheduleConflictFilters 排班冲突任务列表筛选条件
+type ScheduleConflictFilters struct {
+	ScheduleID string
+	MemberID   string
+	Status     string
+}
+
+// ScheduleConflictRepository 排班冲突处理任务数据访问接口
+type ScheduleConflictRepository interface {
+	Create(ctx context.Context, conflict *model.ScheduleConflict) error
+	GetByID(ctx context.Context, id string) (*model.ScheduleConflict, error)
+	ListOpenByMember(ctx context.Context, scheduleID, memberID string) ([]model.ScheduleConflict, error)
+	ListWithFilters(ctx context.Context, filters *ScheduleConflictFilters, offset, limit int) ([]model.ScheduleConflict, int64, error)
+	// Close 关闭待处理任务（resolved / dismissed），返回受影响行数（0 表示任务已关闭）
+	Close(ctx context.Context, id, status, note, closedBy string) (int64, error)
+	// CloseOpenByItem 关闭排班项下所有待处理任务（排班项已改派）
+	CloseOpenByItem(ctx context.Context, scheduleItemID, note, closedBy string) error
+}
+
+type scheduleConflictRepo struct {
+	db *gorm.DB
+}
+
+// NewScheduleConflictRepo 创建 ScheduleConflictRepository 实例
+func NewScheduleConflictRepo(db *gorm.DB) ScheduleConflictRepository {
+	return &scheduleConflictRepo{db: db}
+}
+
+func (r *scheduleConflictRepo) Create(ctx context.Context, conflict *model.ScheduleConflict) error {
+	return r.db.WithContext(ctx).Create(conflict).Error
+}
+
+func (r *scheduleConflictRepo) GetByID(ctx context.Context, id string) (*model.ScheduleConflict, error) {
+	var conflict model.ScheduleConflict
+	err := r.db.WithContext(ctx).
+		Preload("ScheduleItem.TimeSlot").
+		Preload("Member.Department").
+		Where("schedule_conflict_id = ?", id).
+		First(&conflict).Error
+	if err != nil {
+		return nil, err
+	}
+	return &conflict, nil
+}
+
+func (r *scheduleConflictRepo) ListOpenByMember(ctx context.Context, scheduleID, memberID string) ([]model.ScheduleConflict, error) {
+	var conflicts []model.ScheduleConflict
+	err := r.db.WithContext(ctx).
+		Where("schedule_id = ? AND member_id = ? AND status = ?", scheduleID, memberID, model.ScheduleConflictOpen).
+		Find(&conflicts).Error
+	return conflicts, err
+}
+
+func (r *scheduleConflictRepo) ListWithFilters(ctx context.Context, filters *ScheduleConflictFilters, offset, limit int) ([]model.ScheduleConflict, int64, error) {
+	var conflicts []model.ScheduleConflict
+	var total int64
+
+	db := r.db.WithContext(ctx).Model(&model.ScheduleConflict{})
+	if filters != nil {
+		if filters.ScheduleID != "" {
+			db = db.Where("schedule_id = ?", filters.ScheduleID)
+		}
+		if filters.MemberID != "" {
+			db = db.Where("member_id = ?", filters.MemberID)
+		}
+		if filters.Status != "" {
+			db = db.Where("status = ?", filters.Status)
+		}
+	}
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := db.Preload("ScheduleItem.TimeSlot").
+		Preload("Member.Department").
+		Order("created_at DESC").
+		Offset(offset).Limit(limit).
+		Find(&conflicts).Error
+	return conflicts, total, err
+}
+
+func (r *scheduleConflictRepo) Close(ctx context.Context, id, status, note, closedBy string) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.ScheduleConflict{}).
+		Where("schedule_conflict_id = ? AND status = ?", id, model.ScheduleConflictOpen).
+		Updates(map[string]interface{}{
+			"status":          status,
+			"resolution_note": note,
+			"resolved_at":     gorm.Expr("NOW()"),
+			"resolved_by":     closedBy,
+			"updated_by":      closedBy,
+		})
+	return result.RowsAffected, result.Error
+}
+
+func (r *scheduleConflictRepo) CloseOpenByItem(ctx context.Context, scheduleItemID, note, closedBy string) error {
+	return r.db.WithContext(ctx).
+		Model(&model.ScheduleConflict{}).
+		Where("schedule_item_id = ? AND status = ?", scheduleItemID, model.ScheduleConflictOpen).
+		Updates(map[string]interface{}{
+			"status":          model.ScheduleConflictResolved,
+			"resolution_note": note,
+			"resolved_at":     gorm.Expr("NOW()"),
+			"resolved_by":     closedBy,
+			"updated_by":      closedBy,
+		}).Error
+}
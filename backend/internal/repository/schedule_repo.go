@@ -15,8 +15,11 @@ type ScheduleRepository interface {
 	GetByID(ctx context.Context, id string) (*model.Schedule, error)
 	GetBySemester(ctx context.Context, semesterID string) (*model.Schedule, error)
 	GetLatestBySemester(ctx context.Context, semesterID string) (*model.Schedule, error)
+	ListBySemester(ctx context.Context, semesterID string) ([]model.Schedule, error)
+	ListBySemesterAndStatus(ctx context.Context, semesterID, status string) ([]model.Schedule, error)
+	CountBySemesterAndStatus(ctx context.Context, semesterID, status string) (int64, error)
 	Update(ctx context.Context, schedule *model.Schedule) error
-	Delete(ctx context.Context, id string) error
+	Delete(ctx context.Context, id, deletedBy string) error
 }
 
 // ScheduleItemRepository 排班明细数据访问接口
@@ -25,6 +28,8 @@ type ScheduleItemRepository interface {
 	GetByID(ctx context.Context, id string) (*model.ScheduleItem, error)
 	ListBySchedule(ctx context.Context, scheduleID string) ([]model.ScheduleItem, error)
 	ListByScheduleAndMember(ctx context.Context, scheduleID, memberID string) ([]model.ScheduleItem, error)
+	// BatchCountBySchedules 批量统计多个排班表的排班项数量（单次 SQL），返回 scheduleID -> count 的映射
+	BatchCountBySchedules(ctx context.Context, scheduleIDs []string) (map[string]int64, error)
 	Update(ctx context.Context, item *model.ScheduleItem) error
 	DeleteBySchedule(ctx context.Context, scheduleID string) error
 }
@@ -72,7 +77,8 @@ func (r *scheduleRepo) GetBySemester(ctx context.Context, semesterID string) (*m
 	var schedule model.Schedule
 	err := r.db.WithContext(ctx).
 		Preload("Semester").
-		Where("semester_id = ? AND status != ?", semesterID, "archived").
+		Where("semester_id = ? AND status NOT IN ?", semesterID,
+			[]string{model.ScheduleStatusArchived, model.ScheduleStatusCandidate}).
 		Order("created_at DESC").
 		First(&schedule).Error
 	if err != nil {
@@ -94,6 +100,35 @@ func (r *scheduleRepo) GetLatestBySemester(ctx context.Context, semesterID strin
 	return &schedule, nil
 }
 
+func (r *scheduleRepo) ListBySemester(ctx context.Context, semesterID string) ([]model.Schedule, error) {
+	var schedules []model.Schedule
+	err := r.db.WithContext(ctx).
+		Preload("Semester").
+		Where("semester_id = ?", semesterID).
+		Order("created_at DESC").
+		Find(&schedules).Error
+	return schedules, err
+}
+
+func (r *scheduleRepo) ListBySemesterAndStatus(ctx context.Context, semesterID, status string) ([]model.Schedule, error) {
+	var schedules []model.Schedule
+	err := r.db.WithContext(ctx).
+		Preload("Semester").
+		Where("semester_id = ? AND status = ?", semesterID, status).
+		Order("created_at ASC").
+		Find(&schedules).Error
+	return schedules, err
+}
+
+func (r *scheduleRepo) CountBySemesterAndStatus(ctx context.Context, semesterID, status string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&model.Schedule{}).
+		Where("semester_id = ? AND status = ?", semesterID, status).
+		Count(&count).Error
+	return count, err
+}
+
 func (r *scheduleRepo) Update(ctx context.Context, schedule *model.Schedule) error {
 	oldVersion := schedule.Version
 	result := r.db.WithContext(ctx).
@@ -102,6 +137,7 @@ func (r *scheduleRepo) Update(ctx context.Context, schedule *model.Schedule) err
 		Updates(map[string]interface{}{
 			"semester_id":  schedule.SemesterID,
 			"status":       schedule.Status,
+			"name":         schedule.Name,
 			"published_at": schedule.PublishedAt,
 			"updated_by":   schedule.UpdatedBy,
 			"version":      oldVersion + 1,
@@ -116,10 +152,14 @@ func (r *scheduleRepo) Update(ctx context.Context, schedule *model.Schedule) err
 	return nil
 }
 
-func (r *scheduleRepo) Delete(ctx context.Context, id string) error {
+func (r *scheduleRepo) Delete(ctx context.Context, id, deletedBy string) error {
 	return r.db.WithContext(ctx).
+		Model(&model.Schedule{}).
 		Where("schedule_id = ?", id).
-		Delete(&model.Schedule{}).Error
+		Updates(map[string]interface{}{
+			"deleted_by": deletedBy,
+			"deleted_at": gorm.Expr("NOW()"),
+		}).Error
 }
 
 // ── ScheduleItem Repository 实现 ──
@@ -142,7 +182,7 @@ func (r *scheduleItemRepo) BatchCreate(ctx context.Context, items []model.Schedu
 func (r *scheduleItemRepo) GetByID(ctx context.Context, id string) (*model.ScheduleItem, error) {
 	var item model.ScheduleItem
 	err := r.db.WithContext(ctx).
-		Preload("TimeSlot").
+		Preload("TimeSlot.RequiredSkills.Skill").
 		Preload("Member").Preload("Member.Department").
 		Preload("Location").
 		Where("schedule_item_id = ?", id).
@@ -156,7 +196,7 @@ func (r *scheduleItemRepo) GetByID(ctx context.Context, id string) (*model.Sched
 func (r *scheduleItemRepo) ListBySchedule(ctx context.Context, scheduleID string) ([]model.ScheduleItem, error) {
 	var items []model.ScheduleItem
 	err := r.db.WithContext(ctx).
-		Preload("TimeSlot").
+		Preload("TimeSlot.RequiredSkills.Skill").
 		Preload("Member").Preload("Member.Department").
 		Preload("Location").
 		Where("schedule_id = ?", scheduleID).
@@ -177,6 +217,33 @@ func (r *scheduleItemRepo) ListByScheduleAndMember(ctx context.Context, schedule
 	return items, err
 }
 
+func (r *scheduleItemRepo) BatchCountBySchedules(ctx context.Context, scheduleIDs []string) (map[string]int64, error) {
+	if len(scheduleIDs) == 0 {
+		return make(map[string]int64), nil
+	}
+
+	type result struct {
+		ScheduleID string
+		Count      int64
+	}
+	var results []result
+	err := r.db.WithContext(ctx).
+		Model(&model.ScheduleItem{}).
+		Select("schedule_id, COUNT(*) as count").
+		Where("schedule_id IN ?", scheduleIDs).
+		Group("schedule_id").
+		Find(&results).Error
+	if err != nil {
+		return nil, err
+	}
+
+	countMap := make(map[string]int64, len(results))
+	for _, r := range results {
+		countMap[r.ScheduleID] = r.Count
+	}
+	return countMap, nil
+}
+
 func (r *scheduleItemRepo) Update(ctx context.Context, item *model.ScheduleItem) error {
 	oldVersion := item.Version
 	result := r.db.WithContext(ctx).
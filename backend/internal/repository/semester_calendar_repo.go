@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"echo-union/backend/internal/model"
+)
+
+// SemesterCalendarRepository 学期校历数据访问接口
+type SemesterCalendarRepository interface {
+	Create(ctx context.Context, day *model.SemesterCalendarDay) error
+	GetByID(ctx context.Context, id string) (*model.SemesterCalendarDay, error)
+	GetBySemesterAndDate(ctx context.Context, semesterID string, date time.Time) (*model.SemesterCalendarDay, error)
+	ListBySemester(ctx context.Context, semesterID string) ([]model.SemesterCalendarDay, error)
+	Update(ctx context.Context, day *model.SemesterCalendarDay) error
+	Delete(ctx context.Context, id string, deletedBy string) error
+}
+
+type semesterCalendarRepo struct {
+	db *gorm.DB
+}
+
+// NewSemesterCalendarRepo 创建 SemesterCalendarRepository 实例
+func NewSemesterCalendarRepo(db *gorm.DB) SemesterCalendarRepository {
+	return &semesterCalendarRepo{db: db}
+}
+
+func (r *semesterCalendarRepo) Create(ctx context.Context, day *model.SemesterCalendarDay) error {
+	return r.db.WithContext(ctx).Create(day).Error
+}
+
+func (r *semesterCalendarRepo) GetByID(ctx context.Context, id string) (*model.SemesterCalendarDay, error) {
+	var day model.SemesterCalendarDay
+	err := r.db.WithContext(ctx).
+		Where("calendar_day_id = ?", id).
+		First(&day).Error
+	if err != nil {
+		return nil, err
+	}
+	return &day, nil
+}
+
+func (r *semesterCalendarRepo) GetBySemesterAndDate(ctx context.Context, semesterID string, date time.Time) (*model.SemesterCalendarDay, error) {
+	var day model.SemesterCalendarDay
+	err := r.db.WithContext(ctx).
+		Where("semester_id = ? AND date = ?", semesterID, date.Format(model.TimeFormatDate)).
+		First(&day).Error
+	if err != nil {
+		return nil, err
+	}
+	return &day, nil
+}
+
+func (r *semesterCalendarRepo) ListBySemester(ctx context.Context, semesterID string) ([]model.SemesterCalendarDay, error) {
+	var days []model.SemesterCalendarDay
+	err := r.db.WithContext(ctx).
+		Where("semester_id = ?", semesterID).
+		Order("date ASC").
+		Find(&days).Error
+	return days, err
+}
+
+func (r *semesterCalendarRepo) Update(ctx context.Context, day *model.SemesterCalendarDay) error {
+	return r.db.WithContext(ctx).Save(day).Error
+}
+
+func (r *semesterCalendarRepo) Delete(ctx context.Context, id string, deletedBy string) error {
+	return r.db.WithContext(ctx).
+		Model(&model.SemesterCalendarDay{}).
+		Where("calendar_day_id = ?", id).
+		Updates(map[string]interface{}{
+			"deleted_by": deletedBy,
+			"deleted_at": gorm.Expr("NOW()"),
+		}).Error
+}
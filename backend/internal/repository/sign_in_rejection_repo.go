@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"echo-union/backend/internal/model"
+)
+
+// SignInRejectionFilters 签到拒绝审计筛选条件
+type SignInRejectionFilters struct {
+	MemberID     string
+	DepartmentID string // 成员所在部门
+	Reason       string
+	From         *time.Time // 含
+	To           *time.Time // 不含
+}
+
+// SignInRejectionRepository 签到拒绝审计数据访问接口
+type SignInRejectionRepository interface {
+	Create(ctx context.Context, rejection *model.SignInRejection) error
+	// ListWithFilters 按时间倒序列出（预加载成员、地点与值班记录的时间段）
+	ListWithFilters(ctx context.Context, filters *SignInRejectionFilters, offset, limit int) ([]model.SignInRejection, int64, error)
+}
+
+type signInRejectionRepo struct {
+	db *gorm.DB
+}
+
+// NewSignInRejectionRepo 创建 SignInRejectionRepository 实例
+func NewSignInRejectionRepo(db *gorm.DB) SignInRejectionRepository {
+	return &signInRejectionRepo{db: db}
+}
+
+func (r *signInRejectionRepo) Create(ctx context.Context, rejection *model.SignInRejection) error {
+	return r.db.WithContext(ctx).Create(rejection).Error
+}
+
+func (r *signInRejectionRepo) ListWithFilters(ctx context.Context, filters *SignInRejectionFilters, offset, limit int) ([]model.SignInRejection, int64, error) {
+	var rejections []model.SignInRejection
+	var total int64
+
+	db := r.db.WithContext(ctx).Model(&model.SignInRejection{})
+	if filters != nil {
+		if filters.MemberID != "" {
+			db = db.Where("member_id = ?", filters.MemberID)
+		}
+		if filters.DepartmentID != "" {
+			db = db.Where("member_id IN (?)", r.db.WithContext(ctx).
+				Model(&model.User{}).Select("user_id").Where("department_id = ?", filters.DepartmentID))
+		}
+		if filters.Reason != "" {
+			db = db.Where("reason = ?", filters.Reason)
+		}
+		if filters.From != nil {
+			db = db.Where("created_at >= ?", *filters.From)
+		}
+		if filters.To != nil {
+			db = db.Where("created_at < ?", *filters.To)
+		}
+	}
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := db.
+		Preload("DutyRecord.ScheduleItem.TimeSlot").
+		Preload("DutyRecord.EventShift").
+		Preload("Member.Department").
+		Preload("Location").
+		Order("created_at DESC").
+		Offset(offset).Limit(limit).
+		Find(&rejections).Error
+	return rejections, total, err
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"echo-union/backend/internal/model"
+)
+
+// SkillRepository 技能目录与技能关联数据访问接口
+type SkillRepository interface {
+	Create(ctx context.Context, skill *model.Skill) error
+	GetByID(ctx context.Context, id string) (*model.Skill, error)
+	GetByName(ctx context.Context, name string) (*model.Skill, error)
+	List(ctx context.Context) ([]model.Skill, error)
+	ListByIDs(ctx context.Context, ids []string) ([]model.Skill, error)
+	Update(ctx context.Context, skill *model.Skill) error
+	// Delete 软删除技能，并在同一事务中移除成员 / 时间段 / 活动班次上的关联
+	Delete(ctx context.Context, id string, deletedBy string) error
+
+	// ListHolders 列出具备任一指定技能的成员技能记录
+	ListHolders(ctx context.Context, skillIDs []string) ([]model.UserSkill, error)
+	// ReplaceUserSkills 在事务中全量替换成员技能
+	ReplaceUserSkills(ctx context.Context, userID string, skills []model.UserSkill) error
+	// ReplaceTimeSlotSkills 在事务中全量替换时间段所需技能
+	ReplaceTimeSlotSkills(ctx context.Context, timeSlotID string, skills []model.TimeSlotSkill) error
+	// ReplaceEventShiftSkills 在事务中全量替换活动班次所需技能
+	ReplaceEventShiftSkills(ctx context.Context, shiftID string, skills []model.EventShiftSkill) error
+}
+
+type skillRepo struct {
+	db *gorm.DB
+}
+
+// NewSkillRepo 创建 SkillRepository 实例
+func NewSkillRepo(db *gorm.DB) SkillRepository {
+	return &skillRepo{db: db}
+}
+
+func (r *skillRepo) Create(ctx context.Context, skill *model.Skill) error {
+	return r.db.WithContext(ctx).Create(skill).Error
+}
+
+func (r *skillRepo) GetByID(ctx context.Context, id string) (*model.Skill, error) {
+	var skill model.Skill
+	err := r.db.WithContext(ctx).
+		Where("skill_id = ?", id).
+		First(&skill).Error
+	if err != nil {
+		return nil, err
+	}
+	return &skill, nil
+}
+
+func (r *skillRepo) GetByName(ctx context.Context, name string) (*model.Skill, error) {
+	var skill model.Skill
+	err := r.db.WithContext(ctx).
+		Where("name = ?", name).
+		First(&skill).Error
+	if err != nil {
+		return nil, err
+	}
+	return &skill, nil
+}
+
+func (r *skillRepo) List(ctx context.Context) ([]model.Skill, error) {
+	var skills []model.Skill
+	err := r.db.WithContext(ctx).
+		Order("name ASC").
+		Find(&skills).Error
+	return skills, err
+}
+
+func (r *skillRepo) ListByIDs(ctx context.Context, ids []string) ([]model.Skill, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var skills []model.Skill
+	err := r.db.WithContext(ctx).
+		Where("skill_id IN ?", ids).
+		Order("name ASC").
+		Find(&skills).Error
+	return skills, err
+}
+
+func (r *skillRepo) Update(ctx context.Context, skill *model.Skill) error {
+	return r.db.WithContext(ctx).Save(skill).Error
+}
+
+func (r *skillRepo) Delete(ctx context.Context, id string, deletedBy string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, join := range []interface{}{&model.UserSkill{}, &model.TimeSlotSkill{}, &model.EventShiftSkill{}} {
+			if err := tx.Where("skill_id = ?", id).Delete(join).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&model.Skill{}).
+			Where("skill_id = ?", id).
+			Updates(map[string]interface{}{
+				"deleted_by": deletedBy,
+				"deleted_at": gorm.Expr("NOW()"),
+			}).Error
+	})
+}
+
+func (r *skillRepo) ListHolders(ctx context.Context, skillIDs []string) ([]model.UserSkill, error) {
+	if len(skillIDs) == 0 {
+		return nil, nil
+	}
+	var holders []model.UserSkill
+	err := r.db.WithContext(ctx).
+		Where("skill_id IN ?", skillIDs).
+		Find(&holders).Error
+	return holders, err
+}
+
+func (r *skillRepo) ReplaceUserSkills(ctx context.Context, userID string, skills []model.UserSkill) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.UserSkill{}).Error; err != nil {
+			return err
+		}
+		if len(skills) > 0 {
+			return tx.Omit("Skill").Create(&skills).Error
+		}
+		return nil
+	})
+}
+
+func (r *skillRepo) ReplaceTimeSlotSkills(ctx context.Context, timeSlotID string, skills []model.TimeSlotSkill) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("time_slot_id = ?", timeSlotID).Delete(&model.TimeSlotSkill{}).Error; err != nil {
+			return err
+		}
+		if len(skills) > 0 {
+			return tx.Omit("Skill").Create(&skills).Error
+		}
+		return nil
+	})
+}
+
+func (r *skillRepo) ReplaceEventShiftSkills(ctx context.Context, shiftID string, skills []model.EventShiftSkill) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("event_shift_id = ?", shiftID).Delete(&model.EventShiftSkill{}).Error; err != nil {
+			return err
+		}
+		if len(skills) > 0 {
+			return tx.Omit("Skill").Create(&skills).Error
+		}
+		return nil
+	})
+}
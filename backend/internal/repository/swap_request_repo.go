@@ -0,0 +1,233 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"echo-union/backend/internal/model"
+)
+
+// SwapRequestFilters 换班申请列表筛选条件
+type SwapRequestFilters struct {
+	Kind   string
+	Status string
+	// MemberID 申请人或接班人 / 认领人
+	MemberID string
+}
+
+// SwapRequestRepository 换班申请数据访问接口
+type SwapRequestRepository interface {
+	Create(ctx context.Context, req *model.SwapRequest) error
+	// GetByID 获取申请（预加载两侧的排班项、值班记录及其时间段、地点，申请人与接班人）
+	GetByID(ctx context.Context, id string) (*model.SwapRequest, error)
+	// ListOpenGiveAways 列出值班日期在 from（含）之后、尚未被认领的值班转让
+	ListOpenGiveAways(ctx context.Context, from time.Time) ([]model.SwapRequest, error)
+	ListWithFilters(ctx context.Context, filters *SwapRequestFilters, offset, limit int) ([]model.SwapRequest, int64, error)
+	// CountOpenByDutyRecord 统计涉及该值班记录（任一侧）的进行中（pending / reviewing）申请数
+	CountOpenByDutyRecord(ctx context.Context, dutyRecordID string) (int64, error)
+	// CountOpenItemExchanges 统计涉及该排班项（任一侧）的进行中排班项互换申请数
+	CountOpenItemExchanges(ctx context.Context, scheduleItemID string) (int64, error)
+	// CountCompletedByApplicantInSemester 统计申请人在学期内已生效的换班申请数
+	CountCompletedByApplicantInSemester(ctx context.Context, applicantID, semesterID string) (int64, error)
+	// Claim 认领待认领的值班转让，status 为认领后的状态（reviewing / completed）；返回受影响行数（0 表示已被认领或已关闭）
+	Claim(ctx context.Context, id, memberID, status string) (int64, error)
+	// Respond 被邀请成员响应待响应的双向换班，status 为响应后的状态（reviewing / completed / rejected）；
+	// 返回受影响行数（0 表示已撤回或已响应）
+	Respond(ctx context.Context, id, memberID, status, reason string) (int64, error)
+	// Approve 审批通过待审批的申请，返回受影响行数
+	Approve(ctx context.Context, id, approvedBy string) (int64, error)
+	// Reject 驳回待审批的申请，返回受影响行数
+	Reject(ctx context.Context, id, reason, rejectedBy string) (int64, error)
+	// Cancel 申请人撤回进行中的申请，返回受影响行数
+	Cancel(ctx context.Context, id, cancelledBy string) (int64, error)
+}
+
+type swapRequestRepo struct {
+	db *gorm.DB
+}
+
+// NewSwapRequestRepo 创建 SwapRequestRepository 实例
+func NewSwapRequestRepo(db *gorm.DB) SwapRequestRepository {
+	return &swapRequestRepo{db: db}
+}
+
+func (r *swapRequestRepo) Create(ctx context.Context, req *model.SwapRequest) error {
+	return r.db.WithContext(ctx).Create(req).Error
+}
+
+// withDetails 预加载申请详情所需的关联
+func (r *swapRequestRepo) withDetails(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("DutyRecord.ScheduleItem.TimeSlot.RequiredSkills.Skill").
+		Preload("DutyRecord.ScheduleItem.Location").
+		Preload("DutyRecord.Member").
+		Preload("ScheduleItem.TimeSlot.RequiredSkills.Skill").
+		Preload("ScheduleItem.Location").
+		Preload("ScheduleItem.Member").
+		Preload("TargetScheduleItem.TimeSlot.RequiredSkills.Skill").
+		Preload("TargetScheduleItem.Location").
+		Preload("TargetScheduleItem.Member").
+		Preload("TargetDutyRecord.ScheduleItem.TimeSlot.RequiredSkills.Skill").
+		Preload("TargetDutyRecord.ScheduleItem.Location").
+		Preload("TargetDutyRecord.Member").
+		Preload("Applicant.Department").
+		Preload("TargetMember.Department")
+}
+
+func (r *swapRequestRepo) GetByID(ctx context.Context, id string) (*model.SwapRequest, error) {
+	var req model.SwapRequest
+	err := r.withDetails(r.db.WithContext(ctx)).
+		Where("swap_request_id = ?", id).
+		First(&req).Error
+	if err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+func (r *swapRequestRepo) ListOpenGiveAways(ctx context.Context, from time.Time) ([]model.SwapRequest, error) {
+	var reqs []model.SwapRequest
+	err := r.withDetails(r.db.WithContext(ctx)).
+		Joins("JOIN duty_records dr ON dr.duty_record_id = swap_requests.duty_record_id").
+		Where("swap_requests.kind = ? AND swap_requests.status = ? AND swap_requests.target_member_id IS NULL AND dr.duty_date >= ?",
+			model.SwapKindGiveAway, model.SwapStatusPending, from.Format(model.TimeFormatDate)).
+		Order("dr.duty_date ASC, swap_requests.created_at ASC").
+		Find(&reqs).Error
+	return reqs, err
+}
+
+func (r *swapRequestRepo) ListWithFilters(ctx context.Context, filters *SwapRequestFilters, offset, limit int) ([]model.SwapRequest, int64, error) {
+	var reqs []model.SwapRequest
+	var total int64
+
+	db := r.db.WithContext(ctx).Model(&model.SwapRequest{})
+	if filters != nil {
+		if filters.Kind != "" {
+			db = db.Where("kind = ?", filters.Kind)
+		}
+		if filters.Status != "" {
+			db = db.Where("status = ?", filters.Status)
+		}
+		if filters.MemberID != "" {
+			db = db.Where("(applicant_id = ? OR target_member_id = ?)", filters.MemberID, filters.MemberID)
+		}
+	}
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := r.withDetails(db).
+		Order("created_at DESC").
+		Offset(offset).Limit(limit).
+		Find(&reqs).Error
+	return reqs, total, err
+}
+
+func (r *swapRequestRepo) CountOpenByDutyRecord(ctx context.Context, dutyRecordID string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&model.SwapRequest{}).
+		Where("(duty_record_id = ? OR target_duty_record_id = ?) AND status IN ?", dutyRecordID, dutyRecordID,
+			[]string{model.SwapStatusPending, model.SwapStatusReviewing}).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *swapRequestRepo) CountOpenItemExchanges(ctx context.Context, scheduleItemID string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&model.SwapRequest{}).
+		Where("kind = ? AND duty_record_id IS NULL AND (schedule_item_id = ? OR target_schedule_item_id = ?) AND status IN ?",
+			model.SwapKindExchange, scheduleItemID, scheduleItemID,
+			[]string{model.SwapStatusPending, model.SwapStatusReviewing}).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *swapRequestRepo) CountCompletedByApplicantInSemester(ctx context.Context, applicantID, semesterID string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&model.SwapRequest{}).
+		Joins("JOIN schedule_items si ON si.schedule_item_id = swap_requests.schedule_item_id").
+		Joins("JOIN schedules s ON s.schedule_id = si.schedule_id").
+		Where("swap_requests.applicant_id = ? AND swap_requests.status = ? AND s.semester_id = ?",
+			applicantID, model.SwapStatusCompleted, semesterID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *swapRequestRepo) Claim(ctx context.Context, id, memberID, status string) (int64, error) {
+	updates := map[string]interface{}{
+		"target_member_id":    memberID,
+		"target_responded_at": gorm.Expr("NOW()"),
+		"status":              status,
+		"updated_by":          memberID,
+		"version":             gorm.Expr("version + 1"),
+	}
+	result := r.db.WithContext(ctx).
+		Model(&model.SwapRequest{}).
+		Where("swap_request_id = ? AND status = ? AND target_member_id IS NULL", id, model.SwapStatusPending).
+		Updates(updates)
+	return result.RowsAffected, result.Error
+}
+
+func (r *swapRequestRepo) Respond(ctx context.Context, id, memberID, status, reason string) (int64, error) {
+	updates := map[string]interface{}{
+		"target_responded_at": gorm.Expr("NOW()"),
+		"status":              status,
+		"updated_by":          memberID,
+		"version":             gorm.Expr("version + 1"),
+	}
+	if status == model.SwapStatusRejected {
+		updates["reject_reason"] = reason
+	}
+	result := r.db.WithContext(ctx).
+		Model(&model.SwapRequest{}).
+		Where("swap_request_id = ? AND kind = ? AND status = ? AND target_member_id = ?",
+			id, model.SwapKindExchange, model.SwapStatusPending, memberID).
+		Updates(updates)
+	return result.RowsAffected, result.Error
+}
+
+func (r *swapRequestRepo) Approve(ctx context.Context, id, approvedBy string) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.SwapRequest{}).
+		Where("swap_request_id = ? AND status = ?", id, model.SwapStatusReviewing).
+		Updates(map[string]interface{}{
+			"status":      model.SwapStatusCompleted,
+			"approved_at": gorm.Expr("NOW()"),
+			"approved_by": approvedBy,
+			"updated_by":  approvedBy,
+			"version":     gorm.Expr("version + 1"),
+		})
+	return result.RowsAffected, result.Error
+}
+
+func (r *swapRequestRepo) Reject(ctx context.Context, id, reason, rejectedBy string) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.SwapRequest{}).
+		Where("swap_request_id = ? AND status = ?", id, model.SwapStatusReviewing).
+		Updates(map[string]interface{}{
+			"status":        model.SwapStatusRejected,
+			"reject_reason": reason,
+			"approved_at":   gorm.Expr("NOW()"),
+			"approved_by":   rejectedBy,
+			"updated_by":    rejectedBy,
+			"version":       gorm.Expr("version + 1"),
+		})
+	return result.RowsAffected, result.Error
+}
+
+func (r *swapRequestRepo) Cancel(ctx context.Context, id, cancelledBy string) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.SwapRequest{}).
+		Where("swap_request_id = ? AND applicant_id = ? AND status IN ?", id, cancelledBy,
+			[]string{model.SwapStatusPending, model.SwapStatusReviewing}).
+		Updates(map[string]interface{}{
+			"status":     model.SwapStatusCancelled,
+			"updated_by": cancelledBy,
+			"version":    gorm.Expr("version + 1"),
+		})
+	return result.RowsAffected, result.Error
+}
@@ -34,6 +34,8 @@ func (r *timeSlotRepo) GetByID(ctx context.Context, id string) (*model.TimeSlot,
 	var slot model.TimeSlot
 	err := r.db.WithContext(ctx).
 		Preload("Semester").
+		Preload("Location").
+		Preload("RequiredSkills.Skill").
 		Where("time_slot_id = ?", id).
 		First(&slot).Error
 	if err != nil {
@@ -54,13 +56,15 @@ func (r *timeSlotRepo) List(ctx context.Context, semesterID string, dayOfWeek *i
 	}
 
 	err := db.Preload("Semester").
+		Preload("Location").
+		Preload("RequiredSkills.Skill").
 		Order("day_of_week ASC, start_time ASC").
 		Find(&slots).Error
 	return slots, err
 }
 
 func (r *timeSlotRepo) Update(ctx context.Context, slot *model.TimeSlot) error {
-	return r.db.WithContext(ctx).Save(slot).Error
+	return r.db.WithContext(ctx).Omit("Location", "RequiredSkills").Save(slot).Error
 }
 
 func (r *timeSlotRepo) Delete(ctx context.Context, id string, deletedBy string) error {
@@ -48,6 +48,7 @@ func (r *userRepo) GetByID(ctx context.Context, id string) (*model.User, error)
 	var user model.User
 	err := r.db.WithContext(ctx).
 		Preload("Department").
+		Preload("Skills.Skill").
 		Where("user_id = ?", id).
 		First(&user).Error
 	if err != nil {
@@ -80,7 +81,7 @@ func (r *userRepo) GetByEmail(ctx context.Context, email string) (*model.User, e
 }
 
 func (r *userRepo) Update(ctx context.Context, user *model.User) error {
-	return r.db.WithContext(ctx).Save(user).Error
+	return r.db.WithContext(ctx).Omit("Skills").Save(user).Error
 }
 
 func (r *userRepo) List(ctx context.Context, offset, limit int) ([]model.User, int64, error) {
@@ -112,6 +113,7 @@ func (r *userRepo) ListWithFilters(ctx context.Context, filters *UserListFilters
 	}
 
 	if err := db.Preload("Department").
+		Preload("Skills.Skill").
 		Offset(offset).Limit(limit).
 		Order("created_at DESC").
 		Find(&users).Error; err != nil {
@@ -0,0 +1,336 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/model"
+	"echo-union/backend/internal/repository"
+)
+
+// ── 校历模块业务错误 ──
+
+var (
+	ErrCalendarDateOutOfRange = errors.New("日期不在学期范围内")
+	ErrCalendarDayNotFound    = errors.New("校历日期不存在")
+	ErrCalendarDayInvalid     = errors.New("校历日期参数无效")
+	ErrCalendarICSParseFailed = errors.New("节假日 ICS 解析失败")
+	ErrCalendarICSEmpty       = errors.New("节假日 ICS 中无有效日期")
+)
+
+// CalendarService 学期校历业务接口
+type CalendarService interface {
+	ListDays(ctx context.Context, semesterID string) ([]dto.CalendarDayResponse, error)
+	UpsertDay(ctx context.Context, semesterID string, req *dto.UpsertCalendarDayRequest, callerID string) (*dto.CalendarDayResponse, error)
+	DeleteDay(ctx context.Context, semesterID, dayID, callerID string) error
+	ImportICS(ctx context.Context, semesterID string, reader io.Reader, callerID string) (*dto.CalendarImportResponse, error)
+	Resolve(ctx context.Context, semesterID string, req *dto.CalendarResolveRequest) ([]dto.ResolvedCalendarDay, error)
+}
+
+type calendarService struct {
+	repo   *repository.Repository
+	logger *zap.Logger
+}
+
+// NewCalendarService 创建 CalendarService 实例
+func NewCalendarService(repo *repository.Repository, logger *zap.Logger) CalendarService {
+	return &calendarService{repo: repo, logger: logger}
+}
+
+// ────────────────────── ListDays ──────────────────────
+
+func (s *calendarService) ListDays(ctx context.Context, semesterID string) ([]dto.CalendarDayResponse, error) {
+	if _, err := s.getSemester(ctx, semesterID); err != nil {
+		return nil, err
+	}
+	days, err := s.repo.SemesterCalendar.ListBySemester(ctx, semesterID)
+	if err != nil {
+		s.logger.Error("查询校历失败", zap.Error(err))
+		return nil, err
+	}
+	result := make([]dto.CalendarDayResponse, 0, len(days))
+	for i := range days {
+		result = append(result, toCalendarDayResponse(&days[i]))
+	}
+	return result, nil
+}
+
+// ────────────────────── UpsertDay ──────────────────────
+
+func (s *calendarService) UpsertDay(ctx context.Context, semesterID string, req *dto.UpsertCalendarDayRequest, callerID string) (*dto.CalendarDayResponse, error) {
+	semester, err := s.getSemester(ctx, semesterID)
+	if err != nil {
+		return nil, err
+	}
+	date, err := time.Parse(model.TimeFormatDate, req.Date)
+	if err != nil {
+		return nil, ErrCalendarDayInvalid
+	}
+	if !newSemesterCalendar(semester, nil).contains(date) {
+		return nil, ErrCalendarDateOutOfRange
+	}
+	// 放假日不允许携带调休参数
+	if req.Kind == model.CalendarDayHoliday && (req.RunsAsDayOfWeek != nil || req.RunsAsWeek != nil) {
+		return nil, ErrCalendarDayInvalid
+	}
+
+	var day *model.SemesterCalendarDay
+	err = s.withResync(ctx, semester, callerID, func(txRepo *repository.Repository) error {
+		existing, err := txRepo.SemesterCalendar.GetBySemesterAndDate(ctx, semesterID, date)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if existing == nil {
+			existing = &model.SemesterCalendarDay{SemesterID: semesterID, Date: date}
+			existing.CreatedBy = &callerID
+		}
+		existing.Kind = req.Kind
+		existing.Name = req.Name
+		existing.RunsAsDayOfWeek = req.RunsAsDayOfWeek
+		existing.RunsAsWeek = req.RunsAsWeek
+		existing.Source = model.CalendarSourceManual
+		existing.UpdatedBy = &callerID
+
+		if existing.CalendarDayID == "" {
+			err = txRepo.SemesterCalendar.Create(ctx, existing)
+		} else {
+			err = txRepo.SemesterCalendar.Update(ctx, existing)
+		}
+		day = existing
+		return err
+	})
+	if err != nil {
+		s.logger.Error("保存校历日期失败", zap.Error(err))
+		return nil, err
+	}
+
+	resp := toCalendarDayResponse(day)
+	return &resp, nil
+}
+
+// ────────────────────── DeleteDay ──────────────────────
+
+func (s *calendarService) DeleteDay(ctx context.Context, semesterID, dayID, callerID string) error {
+	semester, err := s.getSemester(ctx, semesterID)
+	if err != nil {
+		return err
+	}
+	day, err := s.repo.SemesterCalendar.GetByID(ctx, dayID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCalendarDayNotFound
+		}
+		return err
+	}
+	if day.SemesterID != semesterID {
+		return ErrCalendarDayNotFound
+	}
+
+	err = s.withResync(ctx, semester, callerID, func(txRepo *repository.Repository) error {
+		return txRepo.SemesterCalendar.Delete(ctx, dayID, callerID)
+	})
+	if err != nil {
+		s.logger.Error("删除校历日期失败", zap.Error(err))
+	}
+	return err
+}
+
+// ────────────────────── ImportICS ──────────────────────
+
+// ImportICS 导入节假日 ICS：学期范围外的日期与手工维护的日期跳过，其余按日期覆盖
+func (s *calendarService) ImportICS(ctx context.Context, semesterID string, reader io.Reader, callerID string) (*dto.CalendarImportResponse, error) {
+	semester, err := s.getSemester(ctx, semesterID)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := ParseHolidayICS(reader)
+	if err != nil {
+		s.logger.Warn("节假日 ICS 解析失败", zap.Error(err))
+		return nil, ErrCalendarICSParseFailed
+	}
+
+	cal := newSemesterCalendar(semester, nil)
+	result := &dto.CalendarImportResponse{}
+	var inRange []parsedCalendarDay
+	for _, p := range parsed {
+		if !cal.contains(p.Date) {
+			result.Skipped++
+			continue
+		}
+		inRange = append(inRange, p)
+	}
+	if len(inRange) == 0 {
+		return nil, ErrCalendarICSEmpty
+	}
+
+	err = s.withResync(ctx, semester, callerID, func(txRepo *repository.Repository) error {
+		for _, p := range inRange {
+			existing, err := txRepo.SemesterCalendar.GetBySemesterAndDate(ctx, semesterID, p.Date)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			switch {
+			case existing == nil:
+				day := &model.SemesterCalendarDay{
+					SemesterID: semesterID,
+					Date:       p.Date,
+					Kind:       p.Kind,
+					Name:       p.Name,
+					Source:     model.CalendarSourceICS,
+				}
+				day.CreatedBy = &callerID
+				day.UpdatedBy = &callerID
+				if err := txRepo.SemesterCalendar.Create(ctx, day); err != nil {
+					return err
+				}
+				result.Created++
+			case existing.Source == model.CalendarSourceManual:
+				result.Skipped++
+			default:
+				existing.Kind = p.Kind
+				existing.Name = p.Name
+				existing.RunsAsDayOfWeek = nil
+				existing.RunsAsWeek = nil
+				existing.UpdatedBy = &callerID
+				if err := txRepo.SemesterCalendar.Update(ctx, existing); err != nil {
+					return err
+				}
+				result.Updated++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.Error("导入节假日失败", zap.Error(err))
+		return nil, err
+	}
+	return result, nil
+}
+
+// ────────────────────── Resolve ──────────────────────
+
+func (s *calendarService) Resolve(ctx context.Context, semesterID string, req *dto.CalendarResolveRequest) ([]dto.ResolvedCalendarDay, error) {
+	semester, err := s.getSemester(ctx, semesterID)
+	if err != nil {
+		return nil, err
+	}
+	cal, err := loadSemesterCalendar(ctx, s.repo, semester)
+	if err != nil {
+		s.logger.Error("加载校历失败", zap.Error(err))
+		return nil, err
+	}
+
+	from, to := cal.startDate, cal.endDate
+	if req.From != "" {
+		if from, err = time.Parse(model.TimeFormatDate, req.From); err != nil {
+			return nil, ErrCalendarDayInvalid
+		}
+	}
+	if req.To != "" {
+		if to, err = time.Parse(model.TimeFormatDate, req.To); err != nil {
+			return nil, ErrCalendarDayInvalid
+		}
+	}
+	if to.Before(from) {
+		return nil, ErrCalendarDayInvalid
+	}
+
+	days := cal.days(from, to)
+	result := make([]dto.ResolvedCalendarDay, 0, len(days))
+	for _, d := range days {
+		result = append(result, dto.ResolvedCalendarDay{
+			Date:         d.date.Format(model.TimeFormatDate),
+			DayOfWeek:    goWeekdayToISO(d.date.Weekday()),
+			IsDutyDay:    d.dutyDay,
+			RunsAs:       d.dayOfWeek,
+			TeachingWeek: d.teachingWeek,
+			CycleWeek:    d.cycleWeek,
+			WeekType:     d.weekType,
+			Kind:         d.kind,
+			Name:         d.name,
+		})
+	}
+	return result, nil
+}
+
+// ── 内部方法 ──
+
+func (s *calendarService) getSemester(ctx context.Context, semesterID string) (*model.Semester, error) {
+	semester, err := s.repo.Semester.GetByID(ctx, semesterID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSemesterNotFound
+		}
+		s.logger.Error("查询学期失败", zap.Error(err))
+		return nil, err
+	}
+	return semester, nil
+}
+
+// withResync 在事务内执行校历变更，并为已发布排班表重建今日起的待值班记录
+func (s *calendarService) withResync(ctx context.Context, semester *model.Semester, callerID string, fn func(txRepo *repository.Repository) error) error {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	rollbackTx := func() {
+		if tx != nil {
+			tx.Rollback()
+		}
+	}
+	txRepo := s.repo.WithTx(tx)
+
+	if err := fn(txRepo); err != nil {
+		rollbackTx()
+		return err
+	}
+
+	published, err := txRepo.Schedule.ListBySemesterAndStatus(ctx, semester.SemesterID, model.ScheduleStatusPublished)
+	if err != nil {
+		rollbackTx()
+		return err
+	}
+	var flagged []model.DutyRecord
+	if len(published) > 0 {
+		cal, err := loadSemesterCalendar(ctx, txRepo, semester)
+		if err != nil {
+			rollbackTx()
+			return err
+		}
+		for i := range published {
+			records, err := syncDutyRecords(ctx, txRepo, &published[i], cal, time.Now(), callerID)
+			if err != nil {
+				rollbackTx()
+				return err
+			}
+			flagged = append(flagged, needingSubstitute(records)...)
+		}
+	}
+
+	if tx != nil {
+		if err := tx.Commit().Error; err != nil {
+			return err
+		}
+	}
+	notifySubstituteNeeded(ctx, s.repo, s.logger, flagged)
+	return nil
+}
+
+func toCalendarDayResponse(d *model.SemesterCalendarDay) dto.CalendarDayResponse {
+	return dto.CalendarDayResponse{
+		ID:              d.CalendarDayID,
+		SemesterID:      d.SemesterID,
+		Date:            d.Date.Format(model.TimeFormatDate),
+		Kind:            d.Kind,
+		Name:            d.Name,
+		RunsAsDayOfWeek: d.RunsAsDayOfWeek,
+		RunsAsWeek:      d.RunsAsWeek,
+		Source:          d.Source,
+	}
+}
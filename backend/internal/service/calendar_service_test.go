@@ -0,0 +1,286 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/model"
+)
+
+// ── 测试辅助 ──
+
+func setupTestCalendarService() (CalendarService, *testScheduleRepos) {
+	repos := newTestScheduleRepos()
+	svc := NewCalendarService(repos.toRepository(), zap.NewNop())
+	return svc, repos
+}
+
+func mustDate(t *testing.T, s string) time.Time {
+	t.Helper()
+	d, err := time.Parse(model.TimeFormatDate, s)
+	if err != nil {
+		t.Fatalf("解析日期失败: %v", err)
+	}
+	return d
+}
+
+func strPtr(s string) *string { return &s }
+
+// seedCalendarData 种子数据：4 周学期（2025-09-01 周一起）+ 周一时段 + 已发布排班（单周 user-1，双周 user-2）
+func seedCalendarData(t *testing.T, repos *testScheduleRepos) *model.Schedule {
+	t.Helper()
+	repos.semester.semesters["sem-cal"] = &model.Semester{
+		SemesterID:    "sem-cal",
+		Name:          "校历测试学期",
+		StartDate:     mustDate(t, "2025-09-01"),
+		EndDate:       mustDate(t, "2025-09-28"),
+		FirstWeekType: "odd",
+		Phase:         model.SemesterPhasePublished,
+	}
+	semID := "sem-cal"
+	repos.timeSlot.slots["ts-mon"] = &model.TimeSlot{
+		TimeSlotID: "ts-mon", Name: "周一上午", SemesterID: &semID,
+		DayOfWeek: 1, StartTime: "08:10", EndTime: "10:05", IsActive: true,
+	}
+	schedule := &model.Schedule{ScheduleID: "sched-cal", SemesterID: "sem-cal", Status: model.ScheduleStatusPublished}
+	repos.schedule.schedules[schedule.ScheduleID] = schedule
+	repos.scheduleItem.items["item-w1"] = &model.ScheduleItem{
+		ScheduleItemID: "item-w1", ScheduleID: "sched-cal", WeekNumber: 1, TimeSlotID: "ts-mon", MemberID: "user-1",
+	}
+	repos.scheduleItem.items["item-w2"] = &model.ScheduleItem{
+		ScheduleItemID: "item-w2", ScheduleID: "sched-cal", WeekNumber: 2, TimeSlotID: "ts-mon", MemberID: "user-2",
+	}
+	return schedule
+}
+
+func dutyDates(repos *testScheduleRepos) map[string]string {
+	result := make(map[string]string)
+	for _, r := range repos.dutyRecord.records {
+		result[r.DutyDate.Format(model.TimeFormatDate)] = r.MemberID
+	}
+	return result
+}
+
+// ── 校历换算 ──
+
+func TestSemesterCalendar_Resolve(t *testing.T) {
+	repos := newTestScheduleRepos()
+	seedCalendarData(t, repos)
+	runsAsMon, runsAsWeek := 1, 3
+	cal := newSemesterCalendar(repos.semester.semesters["sem-cal"], []model.SemesterCalendarDay{
+		{Date: mustDate(t, "2025-09-15"), Kind: model.CalendarDayHoliday, Name: "中秋节"},
+		{Date: mustDate(t, "2025-09-20"), Kind: model.CalendarDayWorkday, Name: "调休", RunsAsDayOfWeek: &runsAsMon, RunsAsWeek: &runsAsWeek},
+	})
+
+	day := cal.resolve(mustDate(t, "2025-09-08"))
+	if !day.dutyDay || day.teachingWeek != 2 || day.cycleWeek != 2 || day.weekType != "even" {
+		t.Errorf("09-08 应为第 2 周双周值班日，实际: %+v", day)
+	}
+	if day := cal.resolve(mustDate(t, "2025-09-15")); day.dutyDay {
+		t.Error("放假日不应值班")
+	}
+	day = cal.resolve(mustDate(t, "2025-09-20"))
+	if !day.dutyDay || day.dayOfWeek != 1 || day.cycleWeek != 1 {
+		t.Errorf("调休日应按第 3 周周一执行，实际: %+v", day)
+	}
+	if day := cal.resolve(mustDate(t, "2025-10-01")); day.dutyDay {
+		t.Error("学期范围外不应值班")
+	}
+}
+
+// ── 值班记录生成 ──
+
+func TestSyncDutyRecords_HolidayAndWorkday(t *testing.T) {
+	repos := newTestScheduleRepos()
+	schedule := seedCalendarData(t, repos)
+	runsAsMon := 1
+	repos.calendar.days["cal-h"] = &model.SemesterCalendarDay{
+		CalendarDayID: "cal-h", SemesterID: "sem-cal", Date: mustDate(t, "2025-09-15"), Kind: model.CalendarDayHoliday,
+	}
+	repos.calendar.days["cal-w"] = &model.SemesterCalendarDay{
+		CalendarDayID: "cal-w", SemesterID: "sem-cal", Date: mustDate(t, "2025-09-20"), Kind: model.CalendarDayWorkday, RunsAsDayOfWeek: &runsAsMon,
+	}
+
+	repo := repos.toRepository()
+	cal, err := loadSemesterCalendar(context.Background(), repo, repos.semester.semesters["sem-cal"])
+	if err != nil {
+		t.Fatalf("加载校历失败: %v", err)
+	}
+	created, err := syncDutyRecords(context.Background(), repo, schedule, cal, mustDate(t, "2025-09-01"), "admin-1")
+	if err != nil {
+		t.Fatalf("生成值班记录失败: %v", err)
+	}
+
+	want := map[string]string{
+		"2025-09-01": "user-1", // 第 1 周
+		"2025-09-08": "user-2", // 第 2 周
+		"2025-09-20": "user-1", // 调休：第 3 周按周一执行
+		"2025-09-22": "user-2", // 第 4 周
+	}
+	got := dutyDates(repos)
+	if len(created) != len(want) || len(got) != len(want) {
+		t.Fatalf("期望 %d 条值班记录，实际 %d: %v", len(want), len(got), got)
+	}
+	for date, member := range want {
+		if got[date] != member {
+			t.Errorf("%s 期望值班人 %s，实际 %q", date, member, got[date])
+		}
+	}
+
+	// 重复同步应幂等
+	if _, err := syncDutyRecords(context.Background(), repo, schedule, cal, mustDate(t, "2025-09-01"), "admin-1"); err != nil {
+		t.Fatalf("重复同步失败: %v", err)
+	}
+	if len(repos.dutyRecord.records) != len(want) {
+		t.Errorf("重复同步后记录数应不变，实际 %d", len(repos.dutyRecord.records))
+	}
+}
+
+func TestSyncDutyRecords_KeepsStartedRecords(t *testing.T) {
+	repos := newTestScheduleRepos()
+	schedule := seedCalendarData(t, repos)
+	repos.dutyRecord.records["done"] = &model.DutyRecord{
+		DutyRecordID: "done", ScheduleItemID: strPtr("item-w1"), MemberID: "user-9",
+		DutyDate: mustDate(t, "2025-09-01"), Status: model.DutyRecordStatusCompleted,
+	}
+
+	repo := repos.toRepository()
+	cal := newSemesterCalendar(repos.semester.semesters["sem-cal"], nil)
+	if _, err := syncDutyRecords(context.Background(), repo, schedule, cal, mustDate(t, "2025-09-01"), "admin-1"); err != nil {
+		t.Fatalf("生成值班记录失败: %v", err)
+	}
+
+	if got := dutyDates(repos)["2025-09-01"]; got != "user-9" {
+		t.Errorf("已完成的记录不应被覆盖，实际值班人 %q", got)
+	}
+	if len(repos.dutyRecord.records) != 4 {
+		t.Errorf("期望 4 条记录，实际 %d", len(repos.dutyRecord.records))
+	}
+}
+
+// ── CalendarService ──
+
+func TestCalendarService_UpsertDay_OutOfRange(t *testing.T) {
+	svc, repos := setupTestCalendarService()
+	seedCalendarData(t, repos)
+
+	_, err := svc.UpsertDay(context.Background(), "sem-cal", &dto.UpsertCalendarDayRequest{
+		Date: "2025-10-01", Kind: model.CalendarDayHoliday,
+	}, "admin-1")
+	if !errors.Is(err, ErrCalendarDateOutOfRange) {
+		t.Errorf("期望 ErrCalendarDateOutOfRange，实际: %v", err)
+	}
+}
+
+func TestCalendarService_UpsertDay_HolidayWithRunsAs(t *testing.T) {
+	svc, repos := setupTestCalendarService()
+	seedCalendarData(t, repos)
+
+	runsAs := 1
+	_, err := svc.UpsertDay(context.Background(), "sem-cal", &dto.UpsertCalendarDayRequest{
+		Date: "2025-09-15", Kind: model.CalendarDayHoliday, RunsAsDayOfWeek: &runsAs,
+	}, "admin-1")
+	if !errors.Is(err, ErrCalendarDayInvalid) {
+		t.Errorf("期望 ErrCalendarDayInvalid，实际: %v", err)
+	}
+}
+
+func TestCalendarService_UpsertDay_OverwritesByDate(t *testing.T) {
+	svc, repos := setupTestCalendarService()
+	seedCalendarData(t, repos)
+
+	ctx := context.Background()
+	first, err := svc.UpsertDay(ctx, "sem-cal", &dto.UpsertCalendarDayRequest{Date: "2025-09-15", Kind: model.CalendarDayHoliday, Name: "中秋节"}, "admin-1")
+	if err != nil {
+		t.Fatalf("UpsertDay 应成功: %v", err)
+	}
+	second, err := svc.UpsertDay(ctx, "sem-cal", &dto.UpsertCalendarDayRequest{Date: "2025-09-15", Kind: model.CalendarDayWorkday, Name: "调休"}, "admin-1")
+	if err != nil {
+		t.Fatalf("UpsertDay 应成功: %v", err)
+	}
+	if first.ID != second.ID || second.Kind != model.CalendarDayWorkday {
+		t.Errorf("同一日期应覆盖原记录，first=%+v second=%+v", first, second)
+	}
+	if len(repos.calendar.days) != 1 {
+		t.Errorf("期望 1 条校历记录，实际 %d", len(repos.calendar.days))
+	}
+}
+
+func TestCalendarService_ImportICS_SkipsManual(t *testing.T) {
+	svc, repos := setupTestCalendarService()
+	seedCalendarData(t, repos)
+	repos.calendar.days["cal-m"] = &model.SemesterCalendarDay{
+		CalendarDayID: "cal-m", SemesterID: "sem-cal", Date: mustDate(t, "2025-09-15"),
+		Kind: model.CalendarDayHoliday, Name: "手工维护", Source: model.CalendarSourceManual,
+	}
+
+	ics := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//test//holiday//CN",
+		"BEGIN:VEVENT",
+		"UID:h1",
+		"SUMMARY:中秋节",
+		"DTSTART;VALUE=DATE:20250915",
+		"DTEND;VALUE=DATE:20250917",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:w1",
+		"SUMMARY:中秋节补班",
+		"DTSTART;VALUE=DATE:20250920",
+		"DTEND;VALUE=DATE:20250921",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:h2",
+		"SUMMARY:国庆节",
+		"DTSTART;VALUE=DATE:20251001",
+		"DTEND;VALUE=DATE:20251002",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	resp, err := svc.ImportICS(context.Background(), "sem-cal", strings.NewReader(ics), "admin-1")
+	if err != nil {
+		t.Fatalf("ImportICS 应成功: %v", err)
+	}
+	// 09-16 新建、09-20 新建；09-15 手工维护跳过、10-01 超出学期跳过
+	if resp.Created != 2 || resp.Updated != 0 || resp.Skipped != 2 {
+		t.Errorf("导入结果不符: %+v", resp)
+	}
+	if repos.calendar.days["cal-m"].Name != "手工维护" {
+		t.Error("手工维护的日期不应被覆盖")
+	}
+
+	day, _ := repos.calendar.GetBySemesterAndDate(context.Background(), "sem-cal", mustDate(t, "2025-09-20"))
+	if day == nil || day.Kind != model.CalendarDayWorkday || day.Source != model.CalendarSourceICS {
+		t.Errorf("09-20 应导入为 ICS 来源的调休上班日，实际: %+v", day)
+	}
+}
+
+func TestCalendarService_Resolve(t *testing.T) {
+	svc, repos := setupTestCalendarService()
+	seedCalendarData(t, repos)
+	repos.calendar.days["cal-h"] = &model.SemesterCalendarDay{
+		CalendarDayID: "cal-h", SemesterID: "sem-cal", Date: mustDate(t, "2025-09-15"), Kind: model.CalendarDayHoliday, Name: "中秋节",
+	}
+
+	days, err := svc.Resolve(context.Background(), "sem-cal", &dto.CalendarResolveRequest{From: "2025-09-14", To: "2025-09-16"})
+	if err != nil {
+		t.Fatalf("Resolve 应成功: %v", err)
+	}
+	if len(days) != 3 {
+		t.Fatalf("期望 3 天，实际 %d", len(days))
+	}
+	if days[1].IsDutyDay || days[1].Kind != model.CalendarDayHoliday || days[1].TeachingWeek != 3 {
+		t.Errorf("09-15 应为第 3 周放假日，实际: %+v", days[1])
+	}
+	if !days[2].IsDutyDay || days[2].WeekType != "odd" {
+		t.Errorf("09-16 应为单周值班日，实际: %+v", days[2])
+	}
+}
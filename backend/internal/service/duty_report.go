@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/model"
+	"echo-union/backend/internal/repository"
+)
+
+// ── 值班日志（交接班记录） ──
+//
+// 成员签到后为本次值班填写日志（正文、可选标签与异常标记），值班结束 dutyReportEditWindow 内可修改。
+// 日志冗余值班地点（同签到校验所用地点）与开始时刻：下一班次的成员通过 Handover 查看同一地点
+// 上一篇日志；管理员与负责人（仅本部门）按学期 / 日期、标签、关键词、异常标记检索。
+// 日志由未标记改为标记异常时，站内通知全部管理员并推送 Webhook（duty_report.incident）。
+
+const (
+	// dutyReportEditWindow 值班结束后仍可填写 / 修改日志的时长
+	dutyReportEditWindow = 24 * time.Hour
+	// dutyHandoverLookback 交接班向前查找上一篇日志的范围
+	dutyHandoverLookback = 7 * 24 * time.Hour
+)
+
+func (s *dutyService) SaveReport(ctx context.Context, recordID string, req *dto.SaveDutyReportRequest, callerID string) (*dto.DutyReportResponse, error) {
+	record, err := s.getOwnRecord(ctx, recordID, callerID)
+	if err != nil {
+		return nil, err
+	}
+	switch record.Status {
+	case model.DutyRecordStatusOnDuty, model.DutyRecordStatusCompleted, model.DutyRecordStatusNoSignOut:
+	default:
+		return nil, ErrDutyReportNotSignedIn
+	}
+	if time.Now().After(dutyEndTime(record).Add(dutyReportEditWindow)) {
+		return nil, ErrDutyReportClosed
+	}
+
+	report, err := s.repo.DutyReport.GetByDutyRecord(ctx, recordID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			s.logger.Error("查询值班日志失败", zap.Error(err))
+			return nil, err
+		}
+		report = &model.DutyReport{DutyRecordID: recordID, MemberID: record.MemberID}
+		report.CreatedBy = &callerID
+	}
+	wasIncident := report.Incident
+
+	loc, err := s.signInLocation(ctx, record)
+	if err != nil {
+		return nil, err
+	}
+	report.LocationID = nil
+	if loc != nil {
+		report.LocationID = &loc.LocationID
+	}
+	report.DutyStart = dutyStartTime(record)
+	report.Content = strings.TrimSpace(req.Content)
+	report.Tags = normalizeReportTags(req.Tags)
+	report.Incident = req.Incident
+	report.UpdatedBy = &callerID
+
+	if err := s.repo.DutyReport.Save(ctx, report); err != nil {
+		s.logger.Error("保存值班日志失败", zap.Error(err))
+		return nil, err
+	}
+	s.logger.Info("值班日志已保存",
+		zap.String("duty_record_id", recordID),
+		zap.String("member_id", callerID),
+		zap.Bool("incident", report.Incident),
+	)
+
+	if report.Incident && !wasIncident {
+		s.notifyIncident(ctx, record, report)
+	}
+
+	saved, err := s.repo.DutyReport.GetByDutyRecord(ctx, recordID)
+	if err != nil {
+		s.logger.Error("查询值班日志失败", zap.Error(err))
+		return nil, err
+	}
+	resp := toDutyReportResponse(saved)
+	return &resp, nil
+}
+
+// GetReport 值班日志详情：填写人、管理员与填写人所在部门的负责人可见
+func (s *dutyService) GetReport(ctx context.Context, recordID, callerID, callerRole, callerDeptID string) (*dto.DutyReportResponse, error) {
+	report, err := s.repo.DutyReport.GetByDutyRecord(ctx, recordID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDutyReportNotFound
+		}
+		s.logger.Error("查询值班日志失败", zap.Error(err))
+		return nil, err
+	}
+
+	allowed := report.MemberID == callerID || callerRole == model.RoleAdmin ||
+		(callerRole == model.RoleLeader && report.Member != nil && report.Member.DepartmentID == callerDeptID)
+	if !allowed {
+		return nil, ErrDutyForbidden
+	}
+	resp := toDutyReportResponse(report)
+	return &resp, nil
+}
+
+// Handover 本人值班的交接内容：同一地点在本次值班开始前最近一篇日志；值班无地点时没有交接内容
+func (s *dutyService) Handover(ctx context.Context, recordID, callerID string) (*dto.DutyHandoverResponse, error) {
+	record, err := s.getOwnRecord(ctx, recordID, callerID)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := s.signInLocation(ctx, record)
+	if err != nil {
+		return nil, err
+	}
+	if loc == nil {
+		return &dto.DutyHandoverResponse{}, nil
+	}
+
+	start := dutyStartTime(record)
+	report, err := s.repo.DutyReport.GetLatestAtLocation(ctx, loc.LocationID, start.Add(-dutyHandoverLookback), start)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &dto.DutyHandoverResponse{}, nil
+		}
+		s.logger.Error("查询交接日志失败", zap.Error(err))
+		return nil, err
+	}
+	resp := toDutyReportResponse(report)
+	return &dto.DutyHandoverResponse{Report: &resp}, nil
+}
+
+// ListReports 检索值班日志（leader 仅本部门）
+func (s *dutyService) ListReports(ctx context.Context, req *dto.DutyReportListRequest, callerRole, callerDeptID string) ([]dto.DutyReportResponse, int64, error) {
+	filters := &repository.DutyReportFilters{
+		MemberID:   req.MemberID,
+		LocationID: req.LocationID,
+		Tag:        strings.TrimSpace(req.Tag),
+		Keyword:    strings.TrimSpace(req.Keyword),
+		Incident:   req.Incident,
+	}
+	// leader 自动过滤为本部门
+	if callerRole == model.RoleLeader {
+		filters.DepartmentID = callerDeptID
+	}
+
+	fromDate, toDate := req.From, req.To
+	if fromDate == "" && toDate == "" && req.SemesterID != "" {
+		semester, err := s.repo.Semester.GetByID(ctx, req.SemesterID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, 0, ErrSemesterNotFound
+			}
+			s.logger.Error("查询学期失败", zap.Error(err))
+			return nil, 0, err
+		}
+		fromDate = semester.StartDate.Format(model.TimeFormatDate)
+		toDate = semester.EndDate.Format(model.TimeFormatDate)
+	}
+	if fromDate != "" {
+		from, _ := time.ParseInLocation(model.TimeFormatDate, fromDate, time.Local)
+		filters.From = &from
+	}
+	if toDate != "" {
+		to, _ := time.ParseInLocation(model.TimeFormatDate, toDate, time.Local)
+		to = to.AddDate(0, 0, 1)
+		filters.To = &to
+	}
+
+	reports, total, err := s.repo.DutyReport.ListWithFilters(ctx, filters, req.GetOffset(), req.GetPageSize())
+	if err != nil {
+		s.logger.Error("检索值班日志失败", zap.Error(err))
+		return nil, 0, err
+	}
+	result := make([]dto.DutyReportResponse, 0, len(reports))
+	for i := range reports {
+		result = append(result, toDutyReportResponse(&reports[i]))
+	}
+	return result, total, nil
+}
+
+// ── 内部辅助方法 ──
+
+// notifyIncident 日志标记异常：通知全部管理员（不含填写人）并推送 Webhook
+func (s *dutyService) notifyIncident(ctx context.Context, record *model.DutyRecord, report *model.DutyReport) {
+	admins, err := adminUserIDs(ctx, s.repo)
+	if err != nil {
+		s.logger.Warn("查询管理员失败，值班异常通知未发送", zap.Error(err))
+	}
+	ids := make([]string, 0, len(admins))
+	for _, id := range admins {
+		if id != report.MemberID {
+			ids = append(ids, id)
+		}
+	}
+
+	desc := dutyDescription(record)
+	name := s.swap.memberName(ctx, report.MemberID)
+	excerpt := report.Content
+	if r := []rune(excerpt); len(r) > 100 {
+		excerpt = string(r[:100]) + "…"
+	}
+	s.sendNotifications(ctx, model.NotificationRelatedDutyRecord, record.DutyRecordID,
+		model.NotificationTypeDutyIncident, "值班异常",
+		fmt.Sprintf("%s 在 %s 的值班日志标记了异常：%s", name, desc, excerpt), ids)
+
+	event := map[string]interface{}{
+		"duty_record_id": record.DutyRecordID,
+		"duty":           desc,
+		"member_id":      report.MemberID,
+		"member":         name,
+		"content":        report.Content,
+		"tags":           []string(report.Tags),
+	}
+	go s.postWebhook("duty_report.incident", event)
+}
+
+// normalizeReportTags 标签去除首尾空白、空值与重复
+func normalizeReportTags(tags []string) model.StringList {
+	result := make(model.StringList, 0, len(tags))
+	for _, t := range tags {
+		t = strings.TrimSpace(t)
+		if t != "" && !containsString(result, t) {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+// toDutyReportResponse 转换值班日志响应（需预加载值班记录、成员与地点）
+func toDutyReportResponse(r *model.DutyReport) dto.DutyReportResponse {
+	tags := []string(r.Tags)
+	if tags == nil {
+		tags = []string{}
+	}
+	resp := dto.DutyReportResponse{
+		ID:        r.DutyReportID,
+		Member:    toMemberBrief(r.Member),
+		Content:   r.Content,
+		Tags:      tags,
+		Incident:  r.Incident,
+		CreatedAt: r.CreatedAt.Format(model.TimeFormatDateTime),
+		UpdatedAt: r.UpdatedAt.Format(model.TimeFormatDateTime),
+	}
+	if r.DutyRecord != nil {
+		resp.DutyRecord = toDutyRecordResponse(r.DutyRecord)
+	}
+	if r.Location != nil {
+		resp.Location = &dto.LocationBrief{ID: r.Location.LocationID, Name: r.Location.Name}
+	}
+	return resp
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/model"
+)
+
+// ── 值班日志与交接班测试 ──
+
+func TestDutyService_SaveReport(t *testing.T) {
+	repos, svc, recordID := setupDutyTest(t)
+	ctx := context.Background()
+
+	req := &dto.SaveDutyReportRequest{Content: " 上午有两位访客咨询招新 ", Tags: []string{" 访客 ", "访客", "", "招新"}}
+	if _, err := svc.SaveReport(ctx, recordID, req, "user-1"); !errors.Is(err, ErrDutyReportNotSignedIn) {
+		t.Errorf("未签到填写日志应返回 ErrDutyReportNotSignedIn，实际 %v", err)
+	}
+	repos.dutyRecord.records[recordID].Status = model.DutyRecordStatusOnDuty
+	if _, err := svc.SaveReport(ctx, recordID, req, "user-2"); !errors.Is(err, ErrDutyNotOwner) {
+		t.Errorf("为他人值班填写日志应返回 ErrDutyNotOwner，实际 %v", err)
+	}
+
+	report, err := svc.SaveReport(ctx, recordID, req, "user-1")
+	if err != nil {
+		t.Fatalf("SaveReport 应成功: %v", err)
+	}
+	if report.Content != "上午有两位访客咨询招新" || len(report.Tags) != 2 || report.Tags[0] != "访客" || report.Tags[1] != "招新" {
+		t.Errorf("正文应去除首尾空白、标签应去空去重，实际 %q %v", report.Content, report.Tags)
+	}
+	if report.DutyRecord.ID != recordID || report.Member == nil || report.Member.ID != "user-1" {
+		t.Errorf("日志应关联值班记录与填写人，实际 %+v", report)
+	}
+	if notificationsOf(repos, "admin-1", model.NotificationTypeDutyIncident) != 0 {
+		t.Error("未标记异常不应通知管理员")
+	}
+
+	// 修改为异常：通知管理员一次，重复保存不再通知
+	req.Incident = true
+	req.Content = "饮水机漏水，已报修"
+	updated, err := svc.SaveReport(ctx, recordID, req, "user-1")
+	if err != nil {
+		t.Fatalf("SaveReport 应成功: %v", err)
+	}
+	if updated.ID != report.ID || !updated.Incident || len(repos.report.reports) != 1 {
+		t.Errorf("修改应覆盖原日志，实际 id=%s incident=%v", updated.ID, updated.Incident)
+	}
+	if _, err := svc.SaveReport(ctx, recordID, req, "user-1"); err != nil {
+		t.Fatalf("SaveReport 应成功: %v", err)
+	}
+	if n := notificationsOf(repos, "admin-1", model.NotificationTypeDutyIncident); n != 1 {
+		t.Errorf("标记异常应通知管理员一次，实际 %d", n)
+	}
+
+	// 值班结束超过 24 小时不能再修改
+	repos.dutyRecord.records[recordID].DutyDate = dateOnly(time.Now().AddDate(0, 0, -2))
+	if _, err := svc.SaveReport(ctx, recordID, req, "user-1"); !errors.Is(err, ErrDutyReportClosed) {
+		t.Errorf("超过修改期限应返回 ErrDutyReportClosed，实际 %v", err)
+	}
+}
+
+func TestDutyService_ReportHandoverAndSearch(t *testing.T) {
+	repos, svc, recordID := setupDutyTest(t)
+	ctx := context.Background()
+
+	repos.dutyRecord.records[recordID].Status = model.DutyRecordStatusCompleted
+	if _, err := svc.SaveReport(ctx, recordID, &dto.SaveDutyReportRequest{Content: "钥匙放在前台抽屉", Tags: []string{"遗留物品"}}, "user-1"); err != nil {
+		t.Fatalf("SaveReport 应成功: %v", err)
+	}
+
+	// 下一班次：user-3 明天的值班
+	itemID := "item-2"
+	next := []model.DutyRecord{{
+		ScheduleItemID: &itemID,
+		MemberID:       "user-3",
+		DutyDate:       dateOnly(time.Now().AddDate(0, 0, 1)),
+		Status:         model.DutyRecordStatusPending,
+	}}
+	if err := repos.dutyRecord.BatchCreate(ctx, next); err != nil {
+		t.Fatalf("创建值班记录失败: %v", err)
+	}
+	nextID := next[0].DutyRecordID
+
+	// 值班无地点时没有交接内容，不会取到其他无地点值班的日志
+	if handover, err := svc.Handover(ctx, nextID, "user-3"); err != nil || handover.Report != nil {
+		t.Errorf("无地点时交接内容应为空，实际 %+v %v", handover, err)
+	}
+
+	// 两次值班都在默认地点：重新保存日志以记录地点
+	repos.location.locations["loc-office"] = &model.Location{LocationID: "loc-office", Name: "办公室", IsDefault: true, IsActive: true}
+	if _, err := svc.SaveReport(ctx, recordID, &dto.SaveDutyReportRequest{Content: "钥匙放在前台抽屉", Tags: []string{"遗留物品"}}, "user-1"); err != nil {
+		t.Fatalf("SaveReport 应成功: %v", err)
+	}
+	handover, err := svc.Handover(ctx, nextID, "user-3")
+	if err != nil {
+		t.Fatalf("Handover 应成功: %v", err)
+	}
+	if handover.Report == nil || handover.Report.Content != "钥匙放在前台抽屉" {
+		t.Errorf("下一班次应看到上一班次的日志，实际 %+v", handover.Report)
+	}
+	if own, _ := svc.Handover(ctx, recordID, "user-1"); own == nil || own.Report != nil {
+		t.Errorf("此前无日志时 report 应为空，实际 %+v", own)
+	}
+	if _, err := svc.Handover(ctx, nextID, "user-1"); !errors.Is(err, ErrDutyNotOwner) {
+		t.Errorf("查看他人值班的交接应返回 ErrDutyNotOwner，实际 %v", err)
+	}
+
+	// 日志详情：填写人、管理员、本部门负责人
+	if _, err := svc.GetReport(ctx, recordID, "user-3", model.RoleMember, "dept-1"); !errors.Is(err, ErrDutyForbidden) {
+		t.Errorf("其他成员查看日志详情应返回 ErrDutyForbidden，实际 %v", err)
+	}
+	if _, err := svc.GetReport(ctx, recordID, "leader-2", model.RoleLeader, "dept-2"); !errors.Is(err, ErrDutyForbidden) {
+		t.Errorf("其他部门负责人查看应返回 ErrDutyForbidden，实际 %v", err)
+	}
+	if _, err := svc.GetReport(ctx, recordID, "leader-1", model.RoleLeader, "dept-1"); err != nil {
+		t.Errorf("本部门负责人应可查看: %v", err)
+	}
+	if _, err := svc.GetReport(ctx, nextID, "admin-1", model.RoleAdmin, ""); !errors.Is(err, ErrDutyReportNotFound) {
+		t.Errorf("无日志应返回 ErrDutyReportNotFound，实际 %v", err)
+	}
+
+	// 检索
+	search := func(req *dto.DutyReportListRequest, role, deptID string) int64 {
+		t.Helper()
+		_, total, err := svc.ListReports(ctx, req, role, deptID)
+		if err != nil {
+			t.Fatalf("ListReports 应成功: %v", err)
+		}
+		return total
+	}
+	incident := true
+	if n := search(&dto.DutyReportListRequest{SemesterID: "sem-1", Tag: "遗留物品"}, model.RoleAdmin, ""); n != 1 {
+		t.Errorf("按学期与标签应检索到 1 篇，实际 %d", n)
+	}
+	if n := search(&dto.DutyReportListRequest{Keyword: "钥匙"}, model.RoleLeader, "dept-1"); n != 1 {
+		t.Errorf("本部门负责人按关键词应检索到 1 篇，实际 %d", n)
+	}
+	if n := search(&dto.DutyReportListRequest{}, model.RoleLeader, "dept-2"); n != 0 {
+		t.Errorf("其他部门负责人不应检索到，实际 %d", n)
+	}
+	if n := search(&dto.DutyReportListRequest{Incident: &incident}, model.RoleAdmin, ""); n != 0 {
+		t.Errorf("未标记异常的日志不应出现在异常检索中，实际 %d", n)
+	}
+	if _, _, err := svc.ListReports(ctx, &dto.DutyReportListRequest{SemesterID: "sem-x"}, model.RoleAdmin, ""); !errors.Is(err, ErrSemesterNotFound) {
+		t.Errorf("学期不存在应返回 ErrSemesterNotFound，实际 %v", err)
+	}
+}
@@ -0,0 +1,619 @@
+package service
+
+import (
+	"context"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"echo-union/backend/config"
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/model"
+	"echo-union/backend/internal/repository"
+	"echo-union/backend/pkg/mailer"
+	"echo-union/backend/pkg/redis"
+	"echo-union/backend/pkg/webhook"
+)
+
+// ── 值班模块业务错误 ──
+
+var (
+	ErrDutyRecordNotFound          = errors.New("值班记录不存在")
+	ErrDutyForbidden               = errors.New("无权操作该值班记录")
+	ErrDutyNotStarted              = errors.New("值班尚未开始，不能标记缺勤")
+	ErrDutyRecordNotPending        = errors.New("值班记录已签到或已处理")
+	ErrEmergencyNotFound           = errors.New("紧急替班不存在")
+	ErrEmergencyNotEligible        = errors.New("该次值班不支持紧急替班")
+	ErrEmergencyClosed             = errors.New("紧急替班已有人接班或已取消")
+	ErrEmergencyExpired            = errors.New("该次值班已结束")
+	ErrEmergencyInvalidToken       = errors.New("接班链接无效")
+	ErrEmergencyMemberUnavailable  = errors.New("你在该次值班时段不可用")
+	ErrEmergencyAbsentMemberCannot = errors.New("缺勤成员不能为自己替班")
+	ErrDutyNotOwner                = errors.New("只能为本人的值班签到签退")
+	ErrSignInWindow                = errors.New("当前不在签到时间窗口")
+	ErrSignOutWindow               = errors.New("当前不在签退时间窗口")
+	ErrDutyAlreadySignedIn         = errors.New("已签到，请勿重复签到")
+	ErrDutyNotSignedIn             = errors.New("未签到或已签退，无法签退")
+	ErrSignInQRRequired            = errors.New("该地点须扫描二维码签到")
+	ErrSignInQRInvalid             = errors.New("二维码无效或已过期")
+	ErrSignInQRWrongLocation       = errors.New("二维码不属于本次值班的地点")
+	ErrSignInQRUsed                = errors.New("您已使用过该二维码，请等待刷新后重新扫描")
+	ErrSignInNetwork               = errors.New("当前网络不在该地点允许的签到范围内")
+	ErrDutyReportNotFound          = errors.New("该次值班暂无值班日志")
+	ErrDutyReportNotSignedIn       = errors.New("签到后才能填写值班日志")
+	ErrDutyReportClosed            = errors.New("值班结束已超过 24 小时，不能再填写或修改值班日志")
+)
+
+// DutyService 值班业务接口
+//
+// 设计说明：
+//   - MarkAbsent 是缺勤判定的入口：值班开始后仍未签到的记录由管理员 / 本部门负责人（或后续的自动判定）标记为缺勤
+//   - 标记缺勤时可选择广播紧急替班（缺省按 system_config.emergency_substitute_enabled）：
+//     向此刻空闲的成员（课表、不可用时间、当天其他值班等与换班相同的校验；
+//     该次值班有地点时优先本地点的常驻值班成员）发送站内通知、邮件与 Webhook，附一键接班链接
+//   - 先到先得：第一位响应者获得一条新的值班记录（substitute_for_id 指向缺勤记录），缺勤记录保持 absent，
+//     考勤统计据此区分缺勤与替班
+//   - 签到 / 签退的时间窗口、地点二维码与网段校验见 duty_sign_in.go，值班日志与交接班见 duty_report.go
+type DutyService interface {
+	// SignIn / SignOut 的 clientIP 用于地点签到网段校验与拒绝审计
+	SignIn(ctx context.Context, recordID string, req *dto.SignInRequest, callerID, clientIP string) (*dto.DutyRecordResponse, error)
+	SignOut(ctx context.Context, recordID, callerID, clientIP string) (*dto.DutyRecordResponse, error)
+	// SetNetworkOverride 管理员为单条值班记录豁免 / 恢复签到网段限制
+	SetNetworkOverride(ctx context.Context, recordID string, req *dto.NetworkOverrideRequest, callerID string) (*dto.DutyRecordResponse, error)
+	// ListSignInRejections 被拒绝的签到 / 签退（leader 仅本部门）
+	ListSignInRejections(ctx context.Context, req *dto.SignInRejectionListRequest, callerRole, callerDeptID string) ([]dto.SignInRejectionResponse, int64, error)
+	// LocationSignInQR 地点展示屏的当前签到二维码
+	LocationSignInQR(ctx context.Context, locationID string) (*dto.LocationSignInQRResponse, error)
+
+	// SaveReport 值班成员填写 / 修改本次值班日志
+	SaveReport(ctx context.Context, recordID string, req *dto.SaveDutyReportRequest, callerID string) (*dto.DutyReportResponse, error)
+	GetReport(ctx context.Context, recordID, callerID, callerRole, callerDeptID string) (*dto.DutyReportResponse, error)
+	// Handover 同一地点上一班次的值班日志（本人值班）
+	Handover(ctx context.Context, recordID, callerID string) (*dto.DutyHandoverResponse, error)
+	// ListReports 检索值班日志（leader 仅本部门）
+	ListReports(ctx context.Context, req *dto.DutyReportListRequest, callerRole, callerDeptID string) ([]dto.DutyReportResponse, int64, error)
+
+	MarkAbsent(ctx context.Context, recordID string, req *dto.MarkAbsentRequest, callerID, callerRole, callerDeptID string) (*dto.MarkAbsentResponse, error)
+	GetEmergency(ctx context.Context, id string) (*dto.EmergencySubstitutionResponse, error)
+	// CoverEmergency 登录成员响应紧急替班
+	CoverEmergency(ctx context.Context, id, callerID string) (*dto.EmergencySubstitutionResponse, error)
+	// PreviewEmergencyByToken 一键接班链接的详情（无需登录，凭签名校验；仅查看，不接班）
+	PreviewEmergencyByToken(ctx context.Context, id string, req *dto.CoverEmergencyByTokenRequest) (*dto.EmergencySubstitutionResponse, error)
+	// CoverEmergencyByToken 在一键接班页面确认后响应（无需登录，凭签名校验）
+	CoverEmergencyByToken(ctx context.Context, id string, req *dto.CoverEmergencyByTokenRequest) (*dto.EmergencySubstitutionResponse, error)
+	CancelEmergency(ctx context.Context, id, callerID, callerRole, callerDeptID string) error
+}
+
+// emailSender 邮件渠道（pkg/mailer），未配置时为 nil
+type emailSender interface {
+	Send(to []string, subject, body string) error
+}
+
+// webhookPoster Webhook 渠道（pkg/webhook），未配置时为 nil
+type webhookPoster interface {
+	Post(ctx context.Context, event string, data interface{}) error
+}
+
+type dutyService struct {
+	repo    *repository.Repository
+	logger  *zap.Logger
+	swap    *swapService // 复用换班的排班上下文与冲突校验
+	mailer  emailSender
+	webhook webhookPoster
+	replay  *onceGuard // 签到二维码防重放
+	baseURL string     // 一键接班链接前缀
+	secret  []byte     // 一键接班链接与签到二维码签名密钥（由 auth.jwt_secret 派生，见 dutySigningKey）
+}
+
+// NewDutyService 创建 DutyService 实例
+// rdb 可为 nil（签到二维码防重放降级为进程内登记，仅保证单实例内同一成员的令牌只用一次）
+func NewDutyService(cfg *config.Config, repo *repository.Repository, rdb *redis.Client, logger *zap.Logger) DutyService {
+	s := &dutyService{
+		repo:   repo,
+		logger: logger,
+		swap: &swapService{
+			repo:     repo,
+			logger:   logger,
+			schedule: &scheduleService{repo: repo, logger: logger},
+		},
+		replay:  newOnceGuard(rdb, logger),
+		baseURL: strings.TrimRight(cfg.Server.BaseURL, "/"),
+		secret:  dutySigningKey(cfg.Auth.JWTSecret),
+	}
+	if m := mailer.New(&cfg.Mail); m != nil {
+		s.mailer = m
+	}
+	if w := webhook.New(&cfg.Webhook); w != nil {
+		s.webhook = w
+	}
+	return s
+}
+
+// dutySigningKey 以 HKDF-SHA256 从 auth.jwt_secret 派生值班链接签名密钥，不与 JWT 直接共用同一密钥
+func dutySigningKey(jwtSecret string) []byte {
+	key, err := hkdf.Key(sha256.New, []byte(jwtSecret), nil, "echo-union duty link signing", sha256.Size)
+	if err != nil { // 仅在输出长度超出上限时出错
+		panic(err)
+	}
+	return key
+}
+
+// ════════════════════════════════════════════════════════════
+// MarkAbsent — 标记缺勤
+// ════════════════════════════════════════════════════════════
+
+func (s *dutyService) MarkAbsent(ctx context.Context, recordID string, req *dto.MarkAbsentRequest, callerID, callerRole, callerDeptID string) (*dto.MarkAbsentResponse, error) {
+	record, err := s.repo.DutyRecord.GetByID(ctx, recordID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDutyRecordNotFound
+		}
+		s.logger.Error("查询值班记录失败", zap.Error(err))
+		return nil, err
+	}
+	if callerRole == model.RoleLeader && (record.Member == nil || record.Member.DepartmentID != callerDeptID) {
+		return nil, ErrDutyForbidden
+	}
+	if record.Status != model.DutyRecordStatusPending {
+		return nil, ErrDutyRecordNotPending
+	}
+	if time.Now().Before(dutyStartTime(record)) {
+		return nil, ErrDutyNotStarted
+	}
+
+	affected, err := s.repo.DutyRecord.MarkAbsent(ctx, recordID, callerID)
+	if err != nil {
+		s.logger.Error("标记缺勤失败", zap.Error(err))
+		return nil, err
+	}
+	if affected == 0 {
+		return nil, ErrDutyRecordNotPending
+	}
+	record.Status = model.DutyRecordStatusAbsent
+
+	s.logger.Info("值班已标记缺勤",
+		zap.String("duty_record_id", recordID),
+		zap.String("member_id", record.MemberID),
+		zap.String("marked_by", callerID),
+	)
+	s.sendNotifications(ctx, model.NotificationRelatedDutyRecord, recordID, model.NotificationTypeAbsentAlert, "值班缺勤",
+		fmt.Sprintf("你 %s 的值班未签到，已被标记为缺勤", dutyDescription(record)), []string{record.MemberID})
+
+	resp := &dto.MarkAbsentResponse{Record: toDutyRecordResponse(record)}
+
+	escalate := false
+	if req.Escalate != nil {
+		escalate = *req.Escalate
+	} else {
+		cfg, err := s.repo.SystemConfig.Get(ctx)
+		if err != nil {
+			s.logger.Error("查询系统配置失败", zap.Error(err))
+			return nil, err
+		}
+		escalate = cfg.EmergencySubstituteEnabled
+	}
+	// 活动班次不对应排班时段，已结束的值班无需替班
+	if !escalate || record.ScheduleItem == nil || record.ScheduleItem.TimeSlot == nil || !time.Now().Before(dutyEndTime(record)) {
+		return resp, nil
+	}
+
+	emergency, err := s.broadcast(ctx, record, callerID)
+	if err != nil {
+		return nil, err
+	}
+	emergencyResp := toEmergencyResponse(emergency)
+	resp.Emergency = &emergencyResp
+	return resp, nil
+}
+
+// ════════════════════════════════════════════════════════════
+// 紧急替班广播
+// ════════════════════════════════════════════════════════════
+
+// broadcast 为缺勤记录创建紧急替班并通知此刻空闲的成员
+func (s *dutyService) broadcast(ctx context.Context, record *model.DutyRecord, callerID string) (*model.EmergencySubstitution, error) {
+	candidates, err := s.emergencyCandidates(ctx, record)
+	if err != nil {
+		return nil, err
+	}
+
+	emergency := &model.EmergencySubstitution{
+		DutyRecordID:  record.DutyRecordID,
+		Status:        model.EmergencyStatusOpen,
+		ExpiresAt:     dutyEndTime(record),
+		NotifiedCount: len(candidates),
+	}
+	emergency.CreatedBy = &callerID
+	emergency.UpdatedBy = &callerID
+	if err := s.repo.EmergencySubstitution.Create(ctx, emergency); err != nil {
+		s.logger.Error("创建紧急替班失败", zap.Error(err))
+		return nil, err
+	}
+	emergency.DutyRecord = record
+
+	s.logger.Info("紧急替班已广播",
+		zap.String("emergency_substitution_id", emergency.EmergencySubstitutionID),
+		zap.String("duty_record_id", record.DutyRecordID),
+		zap.Int("notified", len(candidates)),
+	)
+	if len(candidates) == 0 {
+		return emergency, nil
+	}
+
+	desc := dutyDescription(record)
+	absentName := s.swap.memberName(ctx, record.MemberID)
+	ids := make([]string, 0, len(candidates))
+	for _, u := range candidates {
+		ids = append(ids, u.UserID)
+	}
+	s.sendNotifications(ctx, model.NotificationRelatedEmergency, emergency.EmergencySubstitutionID,
+		model.NotificationTypeEmergencyRequest, "紧急替班",
+		fmt.Sprintf("%s 缺勤，%s 的值班急需替班，先到先得，可在通知中直接接班", absentName, desc), ids)
+
+	// 站外渠道较慢，异步发送；失败只记录日志。一键接班链接只发给本人（邮件），Webhook 只推送摘要
+	type mail struct{ to, body string }
+	var mails []mail
+	for _, u := range candidates {
+		if u.Email != "" {
+			link := s.coverLink(emergency.EmergencySubstitutionID, u.UserID)
+			mails = append(mails, mail{u.Email, fmt.Sprintf(
+				"%s 同学：\n\n%s 缺勤，%s 的值班急需替班。\n如果你现在可以接班，请点击以下链接（先到先得）：\n\n%s\n\n该链接在值班结束或已有人接班后失效。",
+				u.Name, absentName, desc, link)})
+		}
+	}
+	event := map[string]interface{}{
+		"emergency_substitution_id": emergency.EmergencySubstitutionID,
+		"duty_record_id":            record.DutyRecordID,
+		"duty":                      desc,
+		"absent_member":             absentName,
+		"expires_at":                emergency.ExpiresAt.Format(time.RFC3339),
+		"notified_count":            emergency.NotifiedCount,
+	}
+	go func() {
+		for _, m := range mails {
+			s.sendMail([]string{m.to}, "紧急替班："+desc, m.body)
+		}
+		s.postWebhook("emergency_substitute.broadcast", event)
+	}()
+	return emergency, nil
+}
+
+// emergencyCandidates 此刻空闲、可接手该次值班的成员：本学期值班成员中与换班认领相同的校验均无冲突
+// （课表、不可用时间、技能、当天其他值班、搭配与休息约束）；该次值班有地点时只取在该地点有排班项的成员，
+// 本地点无人空闲时退回全部空闲成员
+func (s *dutyService) emergencyCandidates(ctx context.Context, record *model.DutyRecord) ([]model.User, error) {
+	item := record.ScheduleItem
+	sc, err := s.swap.loadSwapContext(ctx, item.ScheduleID)
+	if err != nil {
+		return nil, err
+	}
+	assignments, err := s.repo.UserSemesterAssignment.ListDutyRequiredSubmitted(ctx, sc.schedule.SemesterID)
+	if err != nil {
+		s.logger.Error("查询值班成员失败", zap.Error(err))
+		return nil, err
+	}
+
+	days := []calendarDay{sc.calendar.resolve(record.DutyDate)}
+	var free, local []model.User
+	for _, a := range assignments {
+		if a.UserID == record.MemberID || a.User == nil {
+			continue
+		}
+		conflicts, err := s.swap.memberConflicts(ctx, sc, a.UserID, item, sc.allItems, days, true)
+		if err != nil {
+			return nil, err
+		}
+		if len(conflicts) > 0 {
+			continue
+		}
+		free = append(free, *a.User)
+		if item.LocationID != nil && servesLocation(sc.allItems, a.UserID, *item.LocationID) {
+			local = append(local, *a.User)
+		}
+	}
+	if len(local) > 0 {
+		return local, nil
+	}
+	return free, nil
+}
+
+// servesLocation 成员在排班中是否有该地点的排班项
+func servesLocation(items []model.ScheduleItem, memberID, locationID string) bool {
+	for _, it := range items {
+		if it.MemberID == memberID && it.LocationID != nil && *it.LocationID == locationID {
+			return true
+		}
+	}
+	return false
+}
+
+// ════════════════════════════════════════════════════════════
+// Cover — 响应紧急替班（先到先得）
+// ════════════════════════════════════════════════════════════
+
+func (s *dutyService) CoverEmergency(ctx context.Context, id, callerID string) (*dto.EmergencySubstitutionResponse, error) {
+	emergency, err := s.getEmergency(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.cover(ctx, emergency, callerID)
+}
+
+func (s *dutyService) PreviewEmergencyByToken(ctx context.Context, id string, req *dto.CoverEmergencyByTokenRequest) (*dto.EmergencySubstitutionResponse, error) {
+	if !hmac.Equal([]byte(req.Token), []byte(s.coverToken(id, req.MemberID))) {
+		return nil, ErrEmergencyInvalidToken
+	}
+	return s.getResponse(ctx, id)
+}
+
+func (s *dutyService) CoverEmergencyByToken(ctx context.Context, id string, req *dto.CoverEmergencyByTokenRequest) (*dto.EmergencySubstitutionResponse, error) {
+	if !hmac.Equal([]byte(req.Token), []byte(s.coverToken(id, req.MemberID))) {
+		return nil, ErrEmergencyInvalidToken
+	}
+	emergency, err := s.getEmergency(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.cover(ctx, emergency, req.MemberID)
+}
+
+func (s *dutyService) cover(ctx context.Context, emergency *model.EmergencySubstitution, memberID string) (*dto.EmergencySubstitutionResponse, error) {
+	if emergency.Status != model.EmergencyStatusOpen {
+		return nil, ErrEmergencyClosed
+	}
+	if !time.Now().Before(emergency.ExpiresAt) {
+		return nil, ErrEmergencyExpired
+	}
+	record := emergency.DutyRecord
+	if record == nil || record.ScheduleItem == nil || record.ScheduleItemID == nil {
+		return nil, ErrEmergencyNotEligible
+	}
+	if record.MemberID == memberID {
+		return nil, ErrEmergencyAbsentMemberCannot
+	}
+	conflicts, err := s.swap.claimConflicts(ctx, record, memberID)
+	if err != nil {
+		return nil, err
+	}
+	if len(conflicts) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrEmergencyMemberUnavailable, strings.Join(conflicts, "；"))
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		s.logger.Error("开启事务失败", zap.Error(err))
+		return nil, err
+	}
+	rollbackTx := func() {
+		if tx != nil {
+			tx.Rollback()
+		}
+	}
+	txRepo := s.repo.WithTx(tx)
+
+	absentID := record.DutyRecordID
+	substitute := model.DutyRecord{
+		ScheduleItemID:  record.ScheduleItemID,
+		MemberID:        memberID,
+		DutyDate:        record.DutyDate,
+		Status:          model.DutyRecordStatusPending,
+		SubstituteForID: &absentID,
+	}
+	substitute.CreatedBy = &memberID
+	substitute.UpdatedBy = &memberID
+	records := []model.DutyRecord{substitute}
+	if err := txRepo.DutyRecord.BatchCreate(ctx, records); err != nil {
+		rollbackTx()
+		s.logger.Error("创建替班值班记录失败", zap.Error(err))
+		return nil, err
+	}
+	affected, err := txRepo.EmergencySubstitution.Fill(ctx, emergency.EmergencySubstitutionID, memberID, records[0].DutyRecordID)
+	if err != nil {
+		rollbackTx()
+		s.logger.Error("接班失败", zap.Error(err))
+		return nil, err
+	}
+	if affected == 0 {
+		rollbackTx()
+		return nil, ErrEmergencyClosed // 已被他人抢先接班、已取消或已过期
+	}
+
+	if tx != nil {
+		if err := tx.Commit().Error; err != nil {
+			s.logger.Error("提交事务失败", zap.Error(err))
+			return nil, err
+		}
+	}
+
+	s.logger.Info("紧急替班已接班",
+		zap.String("emergency_substitution_id", emergency.EmergencySubstitutionID),
+		zap.String("substitute_id", memberID),
+		zap.String("substitute_record_id", records[0].DutyRecordID),
+	)
+	s.notifyFilled(ctx, emergency, memberID)
+	return s.getResponse(ctx, emergency.EmergencySubstitutionID)
+}
+
+// notifyFilled 通知管理员与发起人已有人接班
+func (s *dutyService) notifyFilled(ctx context.Context, emergency *model.EmergencySubstitution, memberID string) {
+	ids, err := adminUserIDs(ctx, s.repo)
+	if err != nil {
+		s.logger.Warn("查询管理员失败，接班通知未发送", zap.Error(err))
+	}
+	if emergency.CreatedBy != nil && !containsString(ids, *emergency.CreatedBy) {
+		ids = append(ids, *emergency.CreatedBy)
+	}
+	desc := dutyDescription(emergency.DutyRecord)
+	name := s.swap.memberName(ctx, memberID)
+	s.sendNotifications(ctx, model.NotificationRelatedEmergency, emergency.EmergencySubstitutionID,
+		model.NotificationTypeEmergencyFilled, "紧急替班已接班",
+		fmt.Sprintf("%s 的紧急替班已由 %s 接班", desc, name), ids)
+
+	event := map[string]interface{}{
+		"emergency_substitution_id": emergency.EmergencySubstitutionID,
+		"duty_record_id":            emergency.DutyRecordID,
+		"duty":                      desc,
+		"substitute_id":             memberID,
+		"substitute":                name,
+	}
+	go s.postWebhook("emergency_substitute.filled", event)
+}
+
+// ════════════════════════════════════════════════════════════
+// 查询 / 取消
+// ════════════════════════════════════════════════════════════
+
+func (s *dutyService) GetEmergency(ctx context.Context, id string) (*dto.EmergencySubstitutionResponse, error) {
+	return s.getResponse(ctx, id)
+}
+
+func (s *dutyService) CancelEmergency(ctx context.Context, id, callerID, callerRole, callerDeptID string) error {
+	emergency, err := s.getEmergency(ctx, id)
+	if err != nil {
+		return err
+	}
+	if callerRole == model.RoleLeader {
+		if r := emergency.DutyRecord; r == nil || r.Member == nil || r.Member.DepartmentID != callerDeptID {
+			return ErrDutyForbidden
+		}
+	}
+	affected, err := s.repo.EmergencySubstitution.Cancel(ctx, id, callerID)
+	if err != nil {
+		s.logger.Error("取消紧急替班失败", zap.Error(err))
+		return err
+	}
+	if affected == 0 {
+		return ErrEmergencyClosed
+	}
+	return nil
+}
+
+// ── 内部辅助方法 ──
+
+func (s *dutyService) getEmergency(ctx context.Context, id string) (*model.EmergencySubstitution, error) {
+	emergency, err := s.repo.EmergencySubstitution.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEmergencyNotFound
+		}
+		s.logger.Error("查询紧急替班失败", zap.Error(err))
+		return nil, err
+	}
+	return emergency, nil
+}
+
+func (s *dutyService) getResponse(ctx context.Context, id string) (*dto.EmergencySubstitutionResponse, error) {
+	emergency, err := s.getEmergency(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	resp := toEmergencyResponse(emergency)
+	return &resp, nil
+}
+
+// coverToken 一键接班链接签名：HMAC-SHA256(secret, "emergency:<id>:<member>")
+func (s *dutyService) coverToken(emergencyID, memberID string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte("emergency:" + emergencyID + ":" + memberID))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// coverLink 一键接班链接：打开确认页面（GET /api/v1/duties/emergencies/:id/cover-link?format=html），
+// 成员点击确认后才提交接班（POST 同一路径），避免邮件安全扫描等预取请求误接班
+func (s *dutyService) coverLink(emergencyID, memberID string) string {
+	q := url.Values{}
+	q.Set("format", "html")
+	q.Set("member", memberID)
+	q.Set("token", s.coverToken(emergencyID, memberID))
+	return fmt.Sprintf("%s/api/v1/duties/emergencies/%s/cover-link?%s", s.baseURL, emergencyID, q.Encode())
+}
+
+func (s *dutyService) sendNotifications(ctx context.Context, relatedType, relatedID, notifType, title, content string, userIDs []string) {
+	if len(userIDs) == 0 {
+		return
+	}
+	notifications := make([]model.Notification, 0, len(userIDs))
+	for _, userID := range userIDs {
+		rt, rid := relatedType, relatedID
+		notifications = append(notifications, model.Notification{
+			UserID:      userID,
+			Type:        notifType,
+			Title:       title,
+			Content:     content,
+			RelatedType: &rt,
+			RelatedID:   &rid,
+		})
+	}
+	if err := s.repo.Notification.BatchCreate(ctx, notifications); err != nil {
+		s.logger.Warn("发送值班通知失败", zap.Error(err))
+	}
+}
+
+func (s *dutyService) sendMail(to []string, subject, body string) {
+	if s.mailer == nil {
+		return
+	}
+	if err := s.mailer.Send(to, subject, body); err != nil {
+		s.logger.Warn("发送邮件失败", zap.Strings("to", to), zap.Error(err))
+	}
+}
+
+func (s *dutyService) postWebhook(event string, data interface{}) {
+	if s.webhook == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := s.webhook.Post(ctx, event, data); err != nil {
+		s.logger.Warn("推送 Webhook 失败", zap.String("event", event), zap.Error(err))
+	}
+}
+
+// dutyEndTime 值班结束时间（取排班时段或活动班次）；无时段信息时取当天末尾
+func dutyEndTime(record *model.DutyRecord) time.Time {
+	d := record.DutyDate
+	end := ""
+	if record.ScheduleItem != nil && record.ScheduleItem.TimeSlot != nil {
+		end = record.ScheduleItem.TimeSlot.EndTime
+	} else if record.EventShift != nil {
+		end = record.EventShift.EndTime
+	}
+	if t, err := time.Parse("15:04", hhmm(end)); err == nil {
+		return time.Date(d.Year(), d.Month(), d.Day(), t.Hour(), t.Minute(), 0, 0, time.Local)
+	}
+	return time.Date(d.Year(), d.Month(), d.Day(), 23, 59, 59, 0, time.Local)
+}
+
+// toEmergencyResponse 转换紧急替班响应（需预加载缺勤记录及其时间段、地点与成员，以及接班成员）
+func toEmergencyResponse(e *model.EmergencySubstitution) dto.EmergencySubstitutionResponse {
+	status := e.Status
+	if status == model.EmergencyStatusOpen && !time.Now().Before(e.ExpiresAt) {
+		status = model.EmergencyStatusExpired
+	}
+	resp := dto.EmergencySubstitutionResponse{
+		ID:                 e.EmergencySubstitutionID,
+		Status:             status,
+		NotifiedCount:      e.NotifiedCount,
+		Substitute:         toMemberBrief(e.Substitute),
+		SubstituteRecordID: e.SubstituteRecordID,
+		ExpiresAt:          e.ExpiresAt.Format(model.TimeFormatDateTime),
+		CreatedAt:          e.CreatedAt.Format(model.TimeFormatDateTime),
+	}
+	if e.DutyRecord != nil {
+		resp.DutyRecord = toDutyRecordResponse(e.DutyRecord)
+	}
+	if e.FilledAt != nil {
+		at := e.FilledAt.Format(model.TimeFormatDateTime)
+		resp.FilledAt = &at
+	}
+	return resp
+}
@@ -0,0 +1,370 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"echo-union/backend/config"
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/model"
+)
+
+// setupDutyTest 在换班测试数据基础上将 ts-1 调整为覆盖当前时刻，使今天的值班记录处于进行中
+func setupDutyTest(t *testing.T) (*testScheduleRepos, *dutyService, string) {
+	t.Helper()
+	repos, _, recordID := setupSwapTest(t, 0)
+
+	now := time.Now()
+	start, end := now.Add(-time.Hour), now.Add(time.Hour)
+	if start.Day() != now.Day() {
+		start = dateOnly(now)
+	}
+	if end.Day() != now.Day() {
+		end = dateOnly(now).Add(24*time.Hour - time.Minute)
+	}
+	ts := repos.timeSlot.slots["ts-1"]
+	ts.StartTime, ts.EndTime = start.Format("15:04"), end.Format("15:04")
+
+	cfg := &config.Config{
+		Server: config.ServerConfig{BaseURL: "http://localhost:8080"},
+		Auth:   config.AuthConfig{JWTSecret: "test-secret-key-for-unit-testing-2026"},
+	}
+	svc := NewDutyService(cfg, repos.toRepository(), nil, zap.NewNop()).(*dutyService)
+	return repos, svc, recordID
+}
+
+func TestDutyService_MarkAbsent_EmergencyCover(t *testing.T) {
+	repos, svc, recordID := setupDutyTest(t)
+	ctx := context.Background()
+
+	escalate := true
+	result, err := svc.MarkAbsent(ctx, recordID, &dto.MarkAbsentRequest{Escalate: &escalate}, "admin-1", model.RoleAdmin, "")
+	if err != nil {
+		t.Fatalf("MarkAbsent 应成功: %v", err)
+	}
+	if result.Record.Status != model.DutyRecordStatusAbsent || repos.dutyRecord.records[recordID].Status != model.DutyRecordStatusAbsent {
+		t.Error("值班记录应标记为缺勤")
+	}
+	if notificationsOf(repos, "user-1", model.NotificationTypeAbsentAlert) != 1 {
+		t.Error("应通知缺勤成员")
+	}
+	if result.Emergency == nil || result.Emergency.Status != model.EmergencyStatusOpen {
+		t.Fatalf("应广播紧急替班，实际 %+v", result.Emergency)
+	}
+	// user-2 当天已有值班（R6），只有 user-3 空闲
+	if result.Emergency.NotifiedCount != 1 ||
+		notificationsOf(repos, "user-3", model.NotificationTypeEmergencyRequest) != 1 ||
+		notificationsOf(repos, "user-2", model.NotificationTypeEmergencyRequest) != 0 {
+		t.Errorf("紧急替班应只通知 user-3，实际通知 %d 人", result.Emergency.NotifiedCount)
+	}
+
+	if _, err := svc.MarkAbsent(ctx, recordID, &dto.MarkAbsentRequest{}, "admin-1", model.RoleAdmin, ""); !errors.Is(err, ErrDutyRecordNotPending) {
+		t.Errorf("重复标记应返回 ErrDutyRecordNotPending，实际 %v", err)
+	}
+
+	id := result.Emergency.ID
+	if _, err := svc.CoverEmergencyByToken(ctx, id, &dto.CoverEmergencyByTokenRequest{MemberID: "user-3", Token: svc.coverToken(id, "user-2")}); !errors.Is(err, ErrEmergencyInvalidToken) {
+		t.Errorf("他人的接班链接应返回 ErrEmergencyInvalidToken，实际 %v", err)
+	}
+	if _, err := svc.CoverEmergency(ctx, id, "user-1"); !errors.Is(err, ErrEmergencyAbsentMemberCannot) {
+		t.Errorf("缺勤成员接班应返回 ErrEmergencyAbsentMemberCannot，实际 %v", err)
+	}
+
+	if _, err := svc.PreviewEmergencyByToken(ctx, id, &dto.CoverEmergencyByTokenRequest{MemberID: "user-3", Token: svc.coverToken(id, "user-2")}); !errors.Is(err, ErrEmergencyInvalidToken) {
+		t.Errorf("他人的接班链接查看详情应返回 ErrEmergencyInvalidToken，实际 %v", err)
+	}
+	recordCount := len(repos.dutyRecord.records)
+	preview, err := svc.PreviewEmergencyByToken(ctx, id, &dto.CoverEmergencyByTokenRequest{MemberID: "user-3", Token: svc.coverToken(id, "user-3")})
+	if err != nil {
+		t.Fatalf("查看接班链接详情应成功: %v", err)
+	}
+	if preview.Status != model.EmergencyStatusOpen || preview.Substitute != nil || len(repos.dutyRecord.records) != recordCount {
+		t.Errorf("查看详情不应接班，实际 %+v", preview)
+	}
+
+	covered, err := svc.CoverEmergencyByToken(ctx, id, &dto.CoverEmergencyByTokenRequest{MemberID: "user-3", Token: svc.coverToken(id, "user-3")})
+	if err != nil {
+		t.Fatalf("一键接班应成功: %v", err)
+	}
+	if covered.Status != model.EmergencyStatusFilled || covered.Substitute == nil || covered.Substitute.ID != "user-3" || covered.SubstituteRecordID == nil {
+		t.Fatalf("接班后应为 filled 且接班人为 user-3，实际 %+v", covered)
+	}
+	sub := repos.dutyRecord.records[*covered.SubstituteRecordID]
+	if sub == nil || sub.MemberID != "user-3" || sub.SubstituteForID == nil || *sub.SubstituteForID != recordID || sub.Status != model.DutyRecordStatusPending {
+		t.Errorf("应为 user-3 生成指向缺勤记录的替班值班记录，实际 %+v", sub)
+	}
+	if repos.dutyRecord.records[recordID].Status != model.DutyRecordStatusAbsent {
+		t.Error("缺勤记录应保持 absent")
+	}
+	if notificationsOf(repos, "admin-1", model.NotificationTypeEmergencyFilled) != 1 {
+		t.Error("接班后应通知管理员")
+	}
+
+	if _, err := svc.CoverEmergency(ctx, id, "user-2"); !errors.Is(err, ErrEmergencyClosed) {
+		t.Errorf("已接班后再响应应返回 ErrEmergencyClosed，实际 %v", err)
+	}
+}
+
+func TestDutyService_MarkAbsent_Rules(t *testing.T) {
+	repos, svc, recordID := setupDutyTest(t)
+	ctx := context.Background()
+
+	// 未开始的值班不能标记缺勤
+	itemID := "item-1"
+	future := []model.DutyRecord{{
+		ScheduleItemID: &itemID, MemberID: "user-1",
+		DutyDate: dateOnly(time.Now().AddDate(0, 0, 7)), Status: model.DutyRecordStatusPending,
+	}}
+	if err := repos.dutyRecord.BatchCreate(ctx, future); err != nil {
+		t.Fatalf("创建值班记录失败: %v", err)
+	}
+	if _, err := svc.MarkAbsent(ctx, future[0].DutyRecordID, &dto.MarkAbsentRequest{}, "admin-1", model.RoleAdmin, ""); !errors.Is(err, ErrDutyNotStarted) {
+		t.Errorf("未开始的值班应返回 ErrDutyNotStarted，实际 %v", err)
+	}
+
+	// 未开启 emergency_substitute_enabled 且未指定时不广播
+	result, err := svc.MarkAbsent(ctx, recordID, &dto.MarkAbsentRequest{}, "admin-1", model.RoleAdmin, "")
+	if err != nil {
+		t.Fatalf("MarkAbsent 应成功: %v", err)
+	}
+	if result.Emergency != nil || len(repos.emergency.emergencies) != 0 {
+		t.Error("未开启紧急替班时不应广播")
+	}
+}
+
+func TestDutyService_CoverLink(t *testing.T) {
+	_, svc, _ := setupDutyTest(t)
+	link := svc.coverLink("emergency-1", "user-3")
+	want := fmt.Sprintf("http://localhost:8080/api/v1/duties/emergencies/emergency-1/cover-link?format=html&member=user-3&token=%s", svc.coverToken("emergency-1", "user-3"))
+	if link != want {
+		t.Errorf("接班链接不符，期望 %s，实际 %s", want, link)
+	}
+	if svc.coverToken("emergency-1", "user-3") == svc.coverToken("emergency-1", "user-2") {
+		t.Error("不同成员的链接签名应不同")
+	}
+	if string(svc.secret) == "test-secret-key-for-unit-testing-2026" || len(svc.secret) != sha256.Size {
+		t.Error("链接签名密钥应由 JWT 密钥派生，而非直接使用")
+	}
+}
+
+func TestDutyService_SignInWithQR(t *testing.T) {
+	repos, svc, recordID := setupDutyTest(t)
+	ctx := context.Background()
+
+	// ts-1 调整为 5 分钟后开始，处于正常签到窗口
+	now := time.Now()
+	start := now.Add(5 * time.Minute)
+	if start.Day() != now.Day() {
+		t.Skip("临近午夜，跳过")
+	}
+	repos.timeSlot.slots["ts-1"].StartTime = start.Format("15:04")
+	repos.location.locations["loc-office"] = &model.Location{LocationID: "loc-office", Name: "办公室", IsActive: true, QRSignIn: true}
+	repos.location.locations["loc-hall"] = &model.Location{LocationID: "loc-hall", Name: "大厅", IsActive: true, QRSignIn: true}
+	office := "loc-office"
+	repos.scheduleItem.items["item-1"].LocationID = &office
+
+	if _, err := svc.SignIn(ctx, recordID, &dto.SignInRequest{}, "user-3", "10.20.1.5"); !errors.Is(err, ErrDutyNotOwner) {
+		t.Errorf("为他人签到应返回 ErrDutyNotOwner，实际 %v", err)
+	}
+	if _, err := svc.SignIn(ctx, recordID, &dto.SignInRequest{}, "user-1", "10.20.1.5"); !errors.Is(err, ErrSignInQRRequired) {
+		t.Errorf("未扫码应返回 ErrSignInQRRequired，实际 %v", err)
+	}
+	hall, err := svc.LocationSignInQR(ctx, "loc-hall")
+	if err != nil {
+		t.Fatalf("LocationSignInQR 应成功: %v", err)
+	}
+	if _, err := svc.SignIn(ctx, recordID, &dto.SignInRequest{QRToken: hall.Token}, "user-1", "10.20.1.5"); !errors.Is(err, ErrSignInQRWrongLocation) {
+		t.Errorf("其他地点的二维码应返回 ErrSignInQRWrongLocation，实际 %v", err)
+	}
+	stale := svc.qrToken("loc-office", qrWindow(now)-2)
+	if _, err := svc.SignIn(ctx, recordID, &dto.SignInRequest{QRToken: stale}, "user-1", "10.20.1.5"); !errors.Is(err, ErrSignInQRInvalid) {
+		t.Errorf("过期的二维码应返回 ErrSignInQRInvalid，实际 %v", err)
+	}
+	forged := "loc-office." + strconv.FormatInt(qrWindow(now), 10) + ".forged"
+	if _, err := svc.SignIn(ctx, recordID, &dto.SignInRequest{QRToken: forged}, "user-1", "10.20.1.5"); !errors.Is(err, ErrSignInQRInvalid) {
+		t.Errorf("伪造的二维码应返回 ErrSignInQRInvalid，实际 %v", err)
+	}
+
+	qr, err := svc.LocationSignInQR(ctx, "loc-office")
+	if err != nil {
+		t.Fatalf("LocationSignInQR 应成功: %v", err)
+	}
+	if qr.RefreshIn <= 0 || qr.RefreshIn > int(qrSignInPeriod.Seconds()) {
+		t.Errorf("刷新间隔应在 (0, %v] 内，实际 %d", qrSignInPeriod, qr.RefreshIn)
+	}
+	record, err := svc.SignIn(ctx, recordID, &dto.SignInRequest{QRToken: qr.Token}, "user-1", "10.20.1.5")
+	if err != nil {
+		t.Fatalf("SignIn 应成功: %v", err)
+	}
+	if record.Status != model.DutyRecordStatusOnDuty || record.IsLate || record.SignInTime == nil {
+		t.Errorf("应为准时签到的值班中状态，实际 %+v", record)
+	}
+	if _, err := svc.SignIn(ctx, recordID, &dto.SignInRequest{QRToken: qr.Token}, "user-1", "10.20.1.5"); !errors.Is(err, ErrDutyAlreadySignedIn) {
+		t.Errorf("重复签到应返回 ErrDutyAlreadySignedIn，实际 %v", err)
+	}
+
+	// 同一地点的其他成员在同一窗口内扫同一块屏幕可以签到
+	itemID := "item-1"
+	other := []model.DutyRecord{
+		{ScheduleItemID: &itemID, MemberID: "user-3", DutyDate: dateOnly(now), Status: model.DutyRecordStatusPending},
+		{ScheduleItemID: &itemID, MemberID: "user-3", DutyDate: dateOnly(now), Status: model.DutyRecordStatusPending},
+	}
+	if err := repos.dutyRecord.BatchCreate(ctx, other); err != nil {
+		t.Fatalf("创建值班记录失败: %v", err)
+	}
+	if _, err := svc.SignIn(ctx, other[0].DutyRecordID, &dto.SignInRequest{QRToken: qr.Token}, "user-3", "10.20.1.5"); err != nil {
+		t.Errorf("同一窗口内其他成员使用同一二维码应可签到: %v", err)
+	}
+	// 同一成员不能重复使用同一令牌
+	if _, err := svc.SignIn(ctx, other[1].DutyRecordID, &dto.SignInRequest{QRToken: qr.Token}, "user-3", "10.20.1.5"); !errors.Is(err, ErrSignInQRUsed) {
+		t.Errorf("同一成员重复使用二维码应返回 ErrSignInQRUsed，实际 %v", err)
+	}
+
+	if n := len(repos.rejection.rejections); n != 4 {
+		t.Errorf("错误地点、无效与已使用的二维码应写入 4 条拒绝审计，实际 %d", n)
+	}
+
+	// 签退窗口为结束前后 15 分钟
+	if _, err := svc.SignOut(ctx, recordID, "user-1", "10.20.1.5"); !errors.Is(err, ErrSignOutWindow) {
+		t.Errorf("未到签退窗口应返回 ErrSignOutWindow，实际 %v", err)
+	}
+	repos.timeSlot.slots["ts-1"].EndTime = now.Format("15:04")
+	signedOut, err := svc.SignOut(ctx, recordID, "user-1", "10.20.1.5")
+	if err != nil {
+		t.Fatalf("SignOut 应成功: %v", err)
+	}
+	if signedOut.Status != model.DutyRecordStatusCompleted || signedOut.SignOutTime == nil {
+		t.Errorf("签退后应为已完成，实际 %+v", signedOut)
+	}
+	if _, err := svc.SignOut(ctx, recordID, "user-1", "10.20.1.5"); !errors.Is(err, ErrDutyNotSignedIn) {
+		t.Errorf("重复签退应返回 ErrDutyNotSignedIn，实际 %v", err)
+	}
+}
+
+func TestOnceGuard_Release(t *testing.T) {
+	ctx := context.Background()
+	g := newOnceGuard(nil, zap.NewNop())
+	if !g.claim(ctx, "k", time.Minute) || g.claim(ctx, "k", time.Minute) {
+		t.Fatal("首次登记应成功、重复登记应失败")
+	}
+	// 签到未成功时撤销登记，令牌可重试
+	g.release(ctx, "k")
+	if !g.claim(ctx, "k", time.Minute) {
+		t.Error("撤销后应可再次登记")
+	}
+}
+
+func TestDutyService_SignInNetwork(t *testing.T) {
+	repos, svc, recordID := setupDutyTest(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	start := now.Add(5 * time.Minute)
+	if start.Day() != now.Day() {
+		t.Skip("临近午夜，跳过")
+	}
+	repos.timeSlot.slots["ts-1"].StartTime = start.Format("15:04")
+	// 仅限制网段、不要求扫码的默认地点（排班项未指定地点）
+	repos.location.locations["loc-office"] = &model.Location{
+		LocationID: "loc-office", Name: "办公室", IsDefault: true, IsActive: true,
+		Networks: []model.LocationNetwork{{LocationID: "loc-office", CIDR: "10.20.0.0/16"}},
+	}
+
+	if _, err := svc.SignIn(ctx, recordID, &dto.SignInRequest{}, "user-1", "203.0.113.9"); !errors.Is(err, ErrSignInNetwork) {
+		t.Fatalf("网段外签到应返回 ErrSignInNetwork，实际 %v", err)
+	}
+	logs := repos.rejection.rejections
+	if len(logs) != 1 || logs[0].Reason != model.SignInRejectNetwork || logs[0].ClientIP != "203.0.113.9" ||
+		logs[0].Action != model.SignInActionSignIn || logs[0].LocationID == nil || *logs[0].LocationID != "loc-office" {
+		t.Fatalf("应写入一条网段拒绝审计，实际 %+v", logs)
+	}
+
+	// leader 只能看到本部门成员的拒绝记录
+	if list, total, _ := svc.ListSignInRejections(ctx, &dto.SignInRejectionListRequest{}, model.RoleLeader, "dept-2"); total != 0 || len(list) != 0 {
+		t.Errorf("dept-2 负责人不应看到 user-1 的拒绝记录，实际 %d 条", total)
+	}
+	list, total, err := svc.ListSignInRejections(ctx, &dto.SignInRejectionListRequest{}, model.RoleLeader, "dept-1")
+	if err != nil || total != 1 || list[0].Member == nil || list[0].Member.ID != "user-1" {
+		t.Errorf("dept-1 负责人应看到 user-1 的拒绝记录，实际 %+v, %v", list, err)
+	}
+
+	// IPv4 映射地址按 IPv4 判断
+	record, err := svc.SignIn(ctx, recordID, &dto.SignInRequest{}, "user-1", "::ffff:10.20.3.4")
+	if err != nil {
+		t.Fatalf("网段内签到应成功: %v", err)
+	}
+	if record.Status != model.DutyRecordStatusOnDuty {
+		t.Errorf("签到后应为值班中，实际 %s", record.Status)
+	}
+
+	// 管理员豁免后可在网段外签退
+	repos.timeSlot.slots["ts-1"].EndTime = now.Format("15:04")
+	if _, err := svc.SignOut(ctx, recordID, "user-1", "203.0.113.9"); !errors.Is(err, ErrSignInNetwork) {
+		t.Errorf("网段外签退应返回 ErrSignInNetwork，实际 %v", err)
+	}
+	enabled := true
+	overridden, err := svc.SetNetworkOverride(ctx, recordID, &dto.NetworkOverrideRequest{Enabled: &enabled, Reason: "办公室网络故障"}, "admin-1")
+	if err != nil || !overridden.NetworkOverride {
+		t.Fatalf("SetNetworkOverride 应成功: %+v, %v", overridden, err)
+	}
+	if _, err := svc.SignOut(ctx, recordID, "user-1", "203.0.113.9"); err != nil {
+		t.Errorf("豁免后签退应成功: %v", err)
+	}
+	if n := len(repos.rejection.rejections); n != 2 {
+		t.Errorf("应共有 2 条拒绝审计，实际 %d", n)
+	}
+}
+
+func TestDutyService_SignInWindow(t *testing.T) {
+	repos, svc, recordID := setupDutyTest(t)
+	ctx := context.Background()
+
+	// setupDutyTest 的值班已开始约 1 小时，超过签到窗口；未指定地点且无默认地点时不校验二维码
+	if _, err := svc.SignIn(ctx, recordID, &dto.SignInRequest{}, "user-1", "10.20.1.5"); !errors.Is(err, ErrSignInWindow) {
+		t.Errorf("超过签到窗口应返回 ErrSignInWindow，实际 %v", err)
+	}
+	if _, err := svc.SignOut(ctx, recordID, "user-1", "10.20.1.5"); !errors.Is(err, ErrDutyNotSignedIn) {
+		t.Errorf("未签到签退应返回 ErrDutyNotSignedIn，实际 %v", err)
+	}
+
+	// 紧急替班记录在值班结束前均可签到，且不记迟到
+	itemID, absentID := "item-1", recordID
+	sub := []model.DutyRecord{{
+		ScheduleItemID: &itemID, MemberID: "user-3", DutyDate: dateOnly(time.Now()),
+		Status: model.DutyRecordStatusPending, SubstituteForID: &absentID,
+	}}
+	if err := repos.dutyRecord.BatchCreate(ctx, sub); err != nil {
+		t.Fatalf("创建值班记录失败: %v", err)
+	}
+	record, err := svc.SignIn(ctx, sub[0].DutyRecordID, &dto.SignInRequest{}, "user-3", "10.20.1.5")
+	if err != nil {
+		t.Fatalf("替班签到应成功: %v", err)
+	}
+	if record.IsLate {
+		t.Error("替班签到不应记迟到")
+	}
+}
+
+func TestOnceGuard_LocalFallback(t *testing.T) {
+	g := newOnceGuard(nil, zap.NewNop())
+	ctx := context.Background()
+	if !g.claim(ctx, "token-a", time.Minute) {
+		t.Fatal("首次使用应成功")
+	}
+	if g.claim(ctx, "token-a", time.Minute) {
+		t.Error("重复使用应被拒绝")
+	}
+	if !g.claim(ctx, "token-b", time.Minute) {
+		t.Error("不同令牌互不影响")
+	}
+	if !g.claim(ctx, "token-c", -time.Second) || !g.claim(ctx, "token-c", time.Minute) {
+		t.Error("过期登记应被清理")
+	}
+}
@@ -0,0 +1,474 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"math"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/model"
+	"echo-union/backend/internal/repository"
+	"echo-union/backend/pkg/redis"
+)
+
+// ── 签到 / 签退 ──
+//
+// 时间窗口（LLD 2.7.3，窗口分钟数取 system_config）：
+//   - 签到：[开始 - sign_in_window, 开始 + sign_in_window]，开始后签到记为迟到；
+//     紧急替班生成的记录在值班结束前均可签到，且不记迟到
+//   - 签退：[结束 - sign_out_window, 结束 + sign_out_window]，须已签到
+//
+// 二维码签到：值班地点（排班项 / 活动班次的地点，未指定时取默认地点）开启 qr_sign_in 时，
+// 签到须提交该地点展示屏上的当前令牌。令牌为 HMAC(地点 + 时间窗口)，每 qrSignInPeriod 轮换，
+// 上一窗口的令牌仍可使用（扫码到提交的延迟），过期即失效，拍照转发后难以远程签到。同一地点的多名成员
+// 可扫同一块屏幕，令牌按成员登记使用：同一成员不能重复使用同一令牌；签到未成功时撤销登记，令牌仍可重试。
+//
+// 网段限制：地点配置了 location_networks 时，签到与签退的客户端 IP（按 server.trusted_proxies 解析）
+// 须落在其中之一，管理员可为单条记录豁免。网段与二维码被拒绝的尝试写入 sign_in_rejections 供负责人核查。
+
+const (
+	// qrSignInPeriod 签到二维码轮换周期
+	qrSignInPeriod = 15 * time.Second
+	// qrSignInTokenTTL 令牌最长有效期（当前窗口 + 上一窗口），同时作为防重放登记的保留时间
+	qrSignInTokenTTL = 2 * qrSignInPeriod
+)
+
+func (s *dutyService) SignIn(ctx context.Context, recordID string, req *dto.SignInRequest, callerID, clientIP string) (*dto.DutyRecordResponse, error) {
+	record, err := s.getOwnRecord(ctx, recordID, callerID)
+	if err != nil {
+		return nil, err
+	}
+	switch record.Status {
+	case model.DutyRecordStatusPending:
+	case model.DutyRecordStatusOnDuty, model.DutyRecordStatusCompleted, model.DutyRecordStatusNoSignOut:
+		return nil, ErrDutyAlreadySignedIn
+	default:
+		return nil, ErrDutyRecordNotPending
+	}
+
+	cfg, err := s.repo.SystemConfig.Get(ctx)
+	if err != nil {
+		s.logger.Error("查询系统配置失败", zap.Error(err))
+		return nil, err
+	}
+	now := time.Now()
+	start := dutyStartTime(record)
+	window := time.Duration(cfg.SignInWindowMinutes) * time.Minute
+	deadline := start.Add(window)
+	if record.SubstituteForID != nil {
+		deadline = dutyEndTime(record)
+	}
+	if now.Before(start.Add(-window)) || now.After(deadline) {
+		return nil, ErrSignInWindow
+	}
+	isLate := record.SubstituteForID == nil && now.After(start)
+
+	loc, err := s.signInLocation(ctx, record)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkNetwork(ctx, record, loc, model.SignInActionSignIn, clientIP); err != nil {
+		return nil, err
+	}
+	var replayKey string
+	if loc != nil && loc.QRSignIn {
+		if req.QRToken == "" {
+			return nil, ErrSignInQRRequired
+		}
+		if err := s.verifyQRToken(req.QRToken, loc.LocationID, now); err != nil {
+			reason := model.SignInRejectQRInvalid
+			if errors.Is(err, ErrSignInQRWrongLocation) {
+				reason = model.SignInRejectQRWrongLocation
+			}
+			s.recordRejection(ctx, record, loc, model.SignInActionSignIn, reason, clientIP, "")
+			return nil, err
+		}
+		replayKey = "sign_in_qr:" + callerID + ":" + req.QRToken
+		if !s.replay.claim(ctx, replayKey, qrSignInTokenTTL) {
+			s.recordRejection(ctx, record, loc, model.SignInActionSignIn, model.SignInRejectQRUsed, clientIP, "")
+			return nil, ErrSignInQRUsed
+		}
+	}
+
+	affected, err := s.repo.DutyRecord.SignIn(ctx, recordID, now, isLate, callerID)
+	if err != nil || affected == 0 {
+		if replayKey != "" {
+			s.replay.release(ctx, replayKey)
+		}
+		if err != nil {
+			s.logger.Error("签到失败", zap.Error(err))
+			return nil, err
+		}
+		return nil, ErrDutyAlreadySignedIn
+	}
+	record.Status = model.DutyRecordStatusOnDuty
+	record.SignInTime = &now
+	record.IsLate = isLate
+
+	s.logger.Info("值班已签到",
+		zap.String("duty_record_id", recordID),
+		zap.String("member_id", callerID),
+		zap.Bool("is_late", isLate),
+	)
+	resp := toDutyRecordResponse(record)
+	return &resp, nil
+}
+
+func (s *dutyService) SignOut(ctx context.Context, recordID, callerID, clientIP string) (*dto.DutyRecordResponse, error) {
+	record, err := s.getOwnRecord(ctx, recordID, callerID)
+	if err != nil {
+		return nil, err
+	}
+	if record.Status != model.DutyRecordStatusOnDuty {
+		return nil, ErrDutyNotSignedIn
+	}
+
+	cfg, err := s.repo.SystemConfig.Get(ctx)
+	if err != nil {
+		s.logger.Error("查询系统配置失败", zap.Error(err))
+		return nil, err
+	}
+	now := time.Now()
+	end := dutyEndTime(record)
+	window := time.Duration(cfg.SignOutWindowMinutes) * time.Minute
+	if now.Before(end.Add(-window)) || now.After(end.Add(window)) {
+		return nil, ErrSignOutWindow
+	}
+	loc, err := s.signInLocation(ctx, record)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkNetwork(ctx, record, loc, model.SignInActionSignOut, clientIP); err != nil {
+		return nil, err
+	}
+
+	affected, err := s.repo.DutyRecord.SignOut(ctx, recordID, now, callerID)
+	if err != nil {
+		s.logger.Error("签退失败", zap.Error(err))
+		return nil, err
+	}
+	if affected == 0 {
+		return nil, ErrDutyNotSignedIn
+	}
+	record.Status = model.DutyRecordStatusCompleted
+	record.SignOutTime = &now
+
+	s.logger.Info("值班已签退", zap.String("duty_record_id", recordID), zap.String("member_id", callerID))
+	resp := toDutyRecordResponse(record)
+	return &resp, nil
+}
+
+// ════════════════════════════════════════════════════════════
+// 签到网段限制
+// ════════════════════════════════════════════════════════════
+
+func (s *dutyService) SetNetworkOverride(ctx context.Context, recordID string, req *dto.NetworkOverrideRequest, callerID string) (*dto.DutyRecordResponse, error) {
+	record, err := s.repo.DutyRecord.GetByID(ctx, recordID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDutyRecordNotFound
+		}
+		s.logger.Error("查询值班记录失败", zap.Error(err))
+		return nil, err
+	}
+
+	reason := ""
+	if *req.Enabled {
+		reason = req.Reason
+	}
+	if err := s.repo.DutyRecord.SetNetworkOverride(ctx, recordID, *req.Enabled, reason, callerID); err != nil {
+		s.logger.Error("设置签到网段豁免失败", zap.Error(err))
+		return nil, err
+	}
+	record.NetworkOverride = *req.Enabled
+	record.NetworkOverrideReason = reason
+
+	s.logger.Info("签到网段豁免已更新",
+		zap.String("duty_record_id", recordID),
+		zap.Bool("enabled", *req.Enabled),
+		zap.String("operator_id", callerID),
+	)
+	resp := toDutyRecordResponse(record)
+	return &resp, nil
+}
+
+func (s *dutyService) ListSignInRejections(ctx context.Context, req *dto.SignInRejectionListRequest, callerRole, callerDeptID string) ([]dto.SignInRejectionResponse, int64, error) {
+	filters := &repository.SignInRejectionFilters{MemberID: req.MemberID, Reason: req.Reason}
+	// leader 自动过滤为本部门
+	if callerRole == model.RoleLeader {
+		filters.DepartmentID = callerDeptID
+	}
+	if req.From != "" {
+		from, _ := time.ParseInLocation(model.TimeFormatDate, req.From, time.Local)
+		filters.From = &from
+	}
+	if req.To != "" {
+		to, _ := time.ParseInLocation(model.TimeFormatDate, req.To, time.Local)
+		to = to.AddDate(0, 0, 1)
+		filters.To = &to
+	}
+
+	rejections, total, err := s.repo.SignInRejection.ListWithFilters(ctx, filters, req.GetOffset(), req.GetPageSize())
+	if err != nil {
+		s.logger.Error("查询签到拒绝记录失败", zap.Error(err))
+		return nil, 0, err
+	}
+	result := make([]dto.SignInRejectionResponse, 0, len(rejections))
+	for i := range rejections {
+		result = append(result, toSignInRejectionResponse(&rejections[i]))
+	}
+	return result, total, nil
+}
+
+// checkNetwork 地点配置了签到网段且该记录未豁免时，客户端 IP 须落在其中之一；拒绝时写入审计
+func (s *dutyService) checkNetwork(ctx context.Context, record *model.DutyRecord, loc *model.Location, action, clientIP string) error {
+	if loc == nil || len(loc.Networks) == 0 || record.NetworkOverride {
+		return nil
+	}
+	if ipInNetworks(clientIP, loc.Networks) {
+		return nil
+	}
+	allowed := make([]string, 0, len(loc.Networks))
+	for _, n := range loc.Networks {
+		allowed = append(allowed, n.CIDR)
+	}
+	detail := "允许网段：" + strings.Join(allowed, ", ")
+	if r := []rune(detail); len(r) > 200 {
+		detail = string(r[:200])
+	}
+	s.recordRejection(ctx, record, loc, action, model.SignInRejectNetwork, clientIP, detail)
+	return ErrSignInNetwork
+}
+
+// ipInNetworks 客户端 IP 是否落在任一网段内
+func ipInNetworks(clientIP string, networks []model.LocationNetwork) bool {
+	addr, err := netip.ParseAddr(clientIP)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, n := range networks {
+		if prefix, err := netip.ParsePrefix(n.CIDR); err == nil && prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// recordRejection 写入签到拒绝审计；失败只记录日志，不影响对请求的拒绝
+func (s *dutyService) recordRejection(ctx context.Context, record *model.DutyRecord, loc *model.Location, action, reason, clientIP, detail string) {
+	s.logger.Warn("签到被拒绝",
+		zap.String("duty_record_id", record.DutyRecordID),
+		zap.String("member_id", record.MemberID),
+		zap.String("action", action),
+		zap.String("reason", reason),
+		zap.String("client_ip", clientIP),
+	)
+	rejection := &model.SignInRejection{
+		DutyRecordID: record.DutyRecordID,
+		MemberID:     record.MemberID,
+		Action:       action,
+		Reason:       reason,
+		ClientIP:     clientIP,
+		Detail:       detail,
+	}
+	if loc != nil {
+		rejection.LocationID = &loc.LocationID
+	}
+	if err := s.repo.SignInRejection.Create(ctx, rejection); err != nil {
+		s.logger.Warn("写入签到拒绝审计失败", zap.Error(err))
+	}
+}
+
+// toSignInRejectionResponse 转换签到拒绝响应（需预加载值班记录、成员与地点）
+func toSignInRejectionResponse(r *model.SignInRejection) dto.SignInRejectionResponse {
+	resp := dto.SignInRejectionResponse{
+		ID:        r.SignInRejectionID,
+		Member:    toMemberBrief(r.Member),
+		Action:    r.Action,
+		Reason:    r.Reason,
+		ClientIP:  r.ClientIP,
+		Detail:    r.Detail,
+		CreatedAt: r.CreatedAt.Format(model.TimeFormatDateTime),
+	}
+	if r.DutyRecord != nil {
+		resp.DutyRecord = toDutyRecordResponse(r.DutyRecord)
+	}
+	if r.Location != nil {
+		resp.Location = &dto.LocationBrief{ID: r.Location.LocationID, Name: r.Location.Name}
+	}
+	return resp
+}
+
+// ════════════════════════════════════════════════════════════
+// 地点签到二维码
+// ════════════════════════════════════════════════════════════
+
+func (s *dutyService) LocationSignInQR(ctx context.Context, locationID string) (*dto.LocationSignInQRResponse, error) {
+	loc, err := s.repo.Location.GetByID(ctx, locationID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrLocationNotFound
+		}
+		s.logger.Error("查询地点失败", zap.Error(err))
+		return nil, err
+	}
+
+	now := time.Now()
+	window := qrWindow(now)
+	expiresAt := time.Unix(0, (window+1)*int64(qrSignInPeriod))
+	return &dto.LocationSignInQRResponse{
+		LocationID:   loc.LocationID,
+		LocationName: loc.Name,
+		QRSignIn:     loc.QRSignIn,
+		Token:        s.qrToken(loc.LocationID, window),
+		ExpiresAt:    expiresAt.Format(model.TimeFormatDateTime),
+		RefreshIn:    int(math.Ceil(expiresAt.Sub(now).Seconds())),
+	}, nil
+}
+
+// qrWindow 时刻所在的二维码时间窗口序号
+func qrWindow(t time.Time) int64 {
+	return t.UnixNano() / int64(qrSignInPeriod)
+}
+
+// qrToken 签到二维码令牌："<地点>.<窗口>.<签名>"，签名为 HMAC-SHA256(secret, "sign-in:<地点>:<窗口>") 前 16 字节
+func (s *dutyService) qrToken(locationID string, window int64) string {
+	w := strconv.FormatInt(window, 10)
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte("sign-in:" + locationID + ":" + w))
+	return locationID + "." + w + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// verifyQRToken 校验令牌签名、地点与时间窗口（仅接受当前与上一窗口）
+func (s *dutyService) verifyQRToken(token, locationID string, now time.Time) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return ErrSignInQRInvalid
+	}
+	window, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || !hmac.Equal([]byte(token), []byte(s.qrToken(parts[0], window))) {
+		return ErrSignInQRInvalid
+	}
+	if parts[0] != locationID {
+		return ErrSignInQRWrongLocation
+	}
+	if current := qrWindow(now); window != current && window != current-1 {
+		return ErrSignInQRInvalid
+	}
+	return nil
+}
+
+// ── 内部辅助方法 ──
+
+// getOwnRecord 获取本人的值班记录
+func (s *dutyService) getOwnRecord(ctx context.Context, recordID, callerID string) (*model.DutyRecord, error) {
+	record, err := s.repo.DutyRecord.GetByID(ctx, recordID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDutyRecordNotFound
+		}
+		s.logger.Error("查询值班记录失败", zap.Error(err))
+		return nil, err
+	}
+	if record.MemberID != callerID {
+		return nil, ErrDutyNotOwner
+	}
+	return record, nil
+}
+
+// signInLocation 签到校验所用的地点：排班项 / 活动班次的地点，未指定时取默认地点；均无时返回 nil
+func (s *dutyService) signInLocation(ctx context.Context, record *model.DutyRecord) (*model.Location, error) {
+	var locationID *string
+	if record.ScheduleItem != nil {
+		locationID = record.ScheduleItem.LocationID
+	} else if record.EventShift != nil {
+		locationID = record.EventShift.LocationID
+	}
+	if locationID != nil {
+		loc, err := s.repo.Location.GetByID(ctx, *locationID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			s.logger.Error("查询地点失败", zap.Error(err))
+			return nil, err
+		}
+		return loc, nil
+	}
+
+	locations, err := s.repo.Location.List(ctx, false)
+	if err != nil {
+		s.logger.Error("查询地点失败", zap.Error(err))
+		return nil, err
+	}
+	for i := range locations {
+		if locations[i].IsDefault {
+			return &locations[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// ════════════════════════════════════════════════════════════
+// onceGuard — 一次性令牌防重放
+// ════════════════════════════════════════════════════════════
+
+// onceGuard Redis 可用时登记在 Redis（多实例共享），否则降级为进程内登记
+type onceGuard struct {
+	rdb    *redis.Client
+	logger *zap.Logger
+
+	mu    sync.Mutex
+	local map[string]time.Time // key → 过期时刻
+}
+
+func newOnceGuard(rdb *redis.Client, logger *zap.Logger) *onceGuard {
+	return &onceGuard{rdb: rdb, logger: logger, local: make(map[string]time.Time)}
+}
+
+// claim 登记 key 的首次使用；返回 false 表示 ttl 内已使用过
+func (g *onceGuard) claim(ctx context.Context, key string, ttl time.Duration) bool {
+	if g.rdb != nil {
+		ok, err := g.rdb.ClaimOnce(ctx, key, ttl)
+		if err == nil {
+			return ok
+		}
+		g.logger.Warn("登记一次性令牌失败，降级为进程内防重放", zap.String("key", key), zap.Error(err))
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	now := time.Now()
+	for k, exp := range g.local {
+		if !now.Before(exp) {
+			delete(g.local, k)
+		}
+	}
+	if _, used := g.local[key]; used {
+		return false
+	}
+	g.local[key] = now.Add(ttl)
+	return true
+}
+
+// release 撤销 key 的登记（使用未生效时调用），失败只记录日志
+func (g *onceGuard) release(ctx context.Context, key string) {
+	if g.rdb != nil {
+		if err := g.rdb.ReleaseOnce(ctx, key); err != nil {
+			g.logger.Warn("撤销一次性令牌登记失败", zap.String("key", key), zap.Error(err))
+		}
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.local, key)
+}
@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/model"
+	"echo-union/backend/internal/repository"
+)
+
+// ── 临时不可用与替班 ──
+//
+// repeat_type = once 的不可用时间只作用于 SpecificDate 当天：
+//   - 排班模板（week_number × 时间段）不受影响，自动排班与候选人校验均忽略
+//   - 排班发布后，命中当天时间段的待值班记录标记为需替班，并通知管理员
+
+// isOnceOff 是否为指定日期的临时不可用
+func isOnceOff(ut model.UnavailableTime) bool {
+	return ut.RepeatType == model.RepeatTypeOnce && ut.SpecificDate != nil
+}
+
+// onceOffConflict 返回 date 当天与时间段冲突的临时不可用，无冲突返回 nil
+func onceOffConflict(uts []model.UnavailableTime, date time.Time, ts *model.TimeSlot) *model.UnavailableTime {
+	if ts == nil {
+		return nil
+	}
+	day := dateOnly(date)
+	for i := range uts {
+		ut := uts[i]
+		if !isOnceOff(ut) || !dateOnly(*ut.SpecificDate).Equal(day) {
+			continue
+		}
+		if ts.StartTime < ut.EndTime && ut.StartTime < ts.EndTime {
+			return &uts[i]
+		}
+	}
+	return nil
+}
+
+// substituteReason 需替班原因
+func substituteReason(ut *model.UnavailableTime) string {
+	if ut.Reason != "" {
+		return "临时不可用：" + ut.Reason
+	}
+	return "临时不可用"
+}
+
+// refreshSubstituteFlags 按成员当前的临时不可用重新评估其在指定日期的待值班记录，
+// 返回本次新标记为需替班的记录（已预置 ScheduleItem.TimeSlot，便于生成通知）。
+func refreshSubstituteFlags(ctx context.Context, repo *repository.Repository, semesterID, memberID string, dates []time.Time, operatorID string) ([]model.DutyRecord, error) {
+	if len(dates) == 0 {
+		return nil, nil
+	}
+	published, err := repo.Schedule.ListBySemesterAndStatus(ctx, semesterID, model.ScheduleStatusPublished)
+	if err != nil || len(published) == 0 {
+		return nil, err
+	}
+	uts, err := repo.UnavailableTime.ListByUserAndSemester(ctx, memberID, semesterID)
+	if err != nil {
+		return nil, err
+	}
+
+	slotCache := make(map[string]*model.TimeSlot)
+	var flagged []model.DutyRecord
+	for _, schedule := range published {
+		for _, date := range dates {
+			records, err := repo.DutyRecord.ListPendingByMemberAndDate(ctx, schedule.ScheduleID, memberID, date)
+			if err != nil {
+				return nil, err
+			}
+			for _, record := range records {
+				ts, err := dutyRecordTimeSlot(ctx, repo, &record, slotCache)
+				if err != nil {
+					return nil, err
+				}
+
+				needs, reason := false, ""
+				if ut := onceOffConflict(uts, date, ts); ut != nil {
+					needs, reason = true, substituteReason(ut)
+				}
+				if needs == record.NeedsSubstitute && reason == record.SubstituteReason {
+					continue
+				}
+				if err := repo.DutyRecord.UpdateSubstitute(ctx, record.DutyRecordID, needs, reason, operatorID); err != nil {
+					return nil, err
+				}
+				if needs && !record.NeedsSubstitute {
+					record.NeedsSubstitute, record.SubstituteReason = needs, reason
+					flagged = append(flagged, record)
+				}
+			}
+		}
+	}
+	return flagged, nil
+}
+
+// dutyRecordTimeSlot 获取值班记录对应的时间段（优先使用预加载数据），并回填到 record.ScheduleItem
+func dutyRecordTimeSlot(ctx context.Context, repo *repository.Repository, record *model.DutyRecord, cache map[string]*model.TimeSlot) (*model.TimeSlot, error) {
+	if record.ScheduleItem == nil {
+		if record.ScheduleItemID == nil {
+			return nil, nil // 活动班次记录，不对应排班模板时间段
+		}
+		item, err := repo.ScheduleItem.GetByID(ctx, *record.ScheduleItemID)
+		if err != nil {
+			return nil, err
+		}
+		record.ScheduleItem = item
+	}
+	if record.ScheduleItem.TimeSlot != nil {
+		return record.ScheduleItem.TimeSlot, nil
+	}
+	slotID := record.ScheduleItem.TimeSlotID
+	if ts, ok := cache[slotID]; ok {
+		return ts, nil
+	}
+	ts, err := repo.TimeSlot.GetByID(ctx, slotID)
+	if err != nil {
+		return nil, err
+	}
+	cache[slotID] = ts
+	record.ScheduleItem.TimeSlot = ts
+	return ts, nil
+}
+
+// notifySubstituteNeeded 通知所有管理员安排替班。
+// 通知为尽力而为：失败只记录日志，不影响已完成的业务操作。
+func notifySubstituteNeeded(ctx context.Context, repo *repository.Repository, logger *zap.Logger, records []model.DutyRecord) {
+	if len(records) == 0 {
+		return
+	}
+	admins, err := adminUserIDs(ctx, repo)
+	if err != nil {
+		logger.Warn("查询管理员失败，替班通知未发送", zap.Error(err))
+		return
+	}
+	if len(admins) == 0 {
+		return
+	}
+
+	memberIDs := make([]string, 0, len(records))
+	for _, r := range records {
+		memberIDs = append(memberIDs, r.MemberID)
+	}
+	names := make(map[string]string)
+	if users, err := repo.User.ListByIDs(ctx, memberIDs); err == nil {
+		for _, u := range users {
+			names[u.UserID] = u.Name
+		}
+	}
+
+	relatedType := model.NotificationRelatedDutyRecord
+	var notifications []model.Notification
+	for i := range records {
+		r := records[i]
+		name := names[r.MemberID]
+		if name == "" {
+			name = r.MemberID
+		}
+		slot := ""
+		if r.ScheduleItem != nil && r.ScheduleItem.TimeSlot != nil {
+			ts := r.ScheduleItem.TimeSlot
+			slot = fmt.Sprintf(" %s（%s-%s）", ts.Name, ts.StartTime, ts.EndTime)
+			if loc := r.ScheduleItem.Location; loc != nil {
+				slot += "@" + loc.Name
+			}
+		}
+		content := fmt.Sprintf("%s 在 %s%s %s，该次值班需安排替班",
+			name, r.DutyDate.Format(model.TimeFormatDate), slot, r.SubstituteReason)
+
+		for _, adminID := range admins {
+			relatedID := r.DutyRecordID
+			notifications = append(notifications, model.Notification{
+				UserID:      adminID,
+				Type:        model.NotificationTypeSubstituteNeeded,
+				Title:       "值班需替班",
+				Content:     content,
+				RelatedType: &relatedType,
+				RelatedID:   &relatedID,
+			})
+		}
+	}
+
+	if err := repo.Notification.BatchCreate(ctx, notifications); err != nil {
+		logger.Warn("发送替班通知失败", zap.Error(err))
+	}
+}
+
+// ════════════════════════════════════════════════════════════
+// ListSubstituteNeeded — 待安排替班的值班记录
+// ════════════════════════════════════════════════════════════
+
+func (s *scheduleService) ListSubstituteNeeded(ctx context.Context, scheduleID string) ([]dto.DutyRecordResponse, error) {
+	schedule, err := s.repo.Schedule.GetByID(ctx, scheduleID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrScheduleNotFound
+		}
+		s.logger.Error("查询排班表失败", zap.Error(err))
+		return nil, err
+	}
+	if schedule.Status != model.ScheduleStatusPublished {
+		return nil, ErrScheduleNotPublished
+	}
+
+	records, err := s.repo.DutyRecord.ListNeedingSubstitute(ctx, scheduleID, dateOnly(time.Now()))
+	if err != nil {
+		s.logger.Error("查询需替班记录失败", zap.Error(err))
+		return nil, err
+	}
+	result := make([]dto.DutyRecordResponse, 0, len(records))
+	for i := range records {
+		result = append(result, toDutyRecordResponse(&records[i]))
+	}
+	return result, nil
+}
+
+// toDutyRecordResponse 转换值班记录响应（需预加载 ScheduleItem.TimeSlot、ScheduleItem.Location 与 Member）
+func toDutyRecordResponse(r *model.DutyRecord) dto.DutyRecordResponse {
+	resp := dto.DutyRecordResponse{
+		ID:               r.DutyRecordID,
+		ScheduleItemID:   r.ScheduleItemID,
+		EventShiftID:     r.EventShiftID,
+		DutyDate:         r.DutyDate.Format(model.TimeFormatDate),
+		Status:           r.Status,
+		NeedsSubstitute:  r.NeedsSubstitute,
+		SubstituteReason: r.SubstituteReason,
+		SubstituteForID:  r.SubstituteForID,
+		IsLate:           r.IsLate,
+		NetworkOverride:  r.NetworkOverride,
+		Member:           toMemberBrief(r.Member),
+	}
+	if r.SignInTime != nil {
+		at := r.SignInTime.Format(model.TimeFormatDateTime)
+		resp.SignInTime = &at
+	}
+	if r.SignOutTime != nil {
+		at := r.SignOutTime.Format(model.TimeFormatDateTime)
+		resp.SignOutTime = &at
+	}
+	if r.ScheduleItem != nil && r.ScheduleItem.TimeSlot != nil {
+		resp.TimeSlot = toTimeSlotBrief(r.ScheduleItem.TimeSlot)
+	}
+	if r.ScheduleItem != nil && r.ScheduleItem.Location != nil {
+		resp.Location = &dto.LocationBrief{ID: r.ScheduleItem.Location.LocationID, Name: r.ScheduleItem.Location.Name}
+	} else if r.EventShift != nil && r.EventShift.Location != nil {
+		resp.Location = &dto.LocationBrief{ID: r.EventShift.Location.LocationID, Name: r.EventShift.Location.Name}
+	}
+	return resp
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"echo-union/backend/internal/model"
+)
+
+// ── 临时不可用与替班测试 ──
+
+func onceOffUnavailable(t *testing.T, id, userID, date, start, end string) model.UnavailableTime {
+	t.Helper()
+	d := mustDate(t, date)
+	return model.UnavailableTime{
+		UnavailableTimeID: id,
+		UserID:            userID,
+		SemesterID:        "sem-cal",
+		DayOfWeek:         goWeekdayToISO(d.Weekday()),
+		StartTime:         start,
+		EndTime:           end,
+		Reason:            "看病",
+		RepeatType:        model.RepeatTypeOnce,
+		SpecificDate:      &d,
+		WeekType:          "all",
+	}
+}
+
+func TestHasUnavailableConflict_IgnoresOnceOff(t *testing.T) {
+	ut := onceOffUnavailable(t, "ut-1", "user-1", "2025-09-01", "08:00", "12:00")
+	if hasUnavailableConflict(ut, 1, "08:10", "10:05", "odd") {
+		t.Error("临时不可用不应影响排班模板")
+	}
+}
+
+func TestSyncDutyRecords_FlagsOnceOffConflict(t *testing.T) {
+	repos := newTestScheduleRepos()
+	schedule := seedCalendarData(t, repos)
+	repos.unavailable.times = []model.UnavailableTime{
+		onceOffUnavailable(t, "ut-1", "user-1", "2025-09-15", "09:00", "11:00"),
+		onceOffUnavailable(t, "ut-2", "user-1", "2025-09-01", "14:00", "16:00"), // 时间不重叠
+	}
+
+	repo := repos.toRepository()
+	cal, err := loadSemesterCalendar(context.Background(), repo, repos.semester.semesters["sem-cal"])
+	if err != nil {
+		t.Fatalf("加载校历失败: %v", err)
+	}
+	created, err := syncDutyRecords(context.Background(), repo, schedule, cal, mustDate(t, "2025-09-01"), "admin-1")
+	if err != nil {
+		t.Fatalf("生成值班记录失败: %v", err)
+	}
+
+	flagged := needingSubstitute(created)
+	if len(flagged) != 1 {
+		t.Fatalf("期望 1 条需替班记录，实际 %d", len(flagged))
+	}
+	if got := flagged[0].DutyDate.Format(model.TimeFormatDate); got != "2025-09-15" {
+		t.Errorf("需替班日期期望 2025-09-15，实际 %s", got)
+	}
+	if flagged[0].SubstituteReason != "临时不可用：看病" {
+		t.Errorf("替班原因不符: %q", flagged[0].SubstituteReason)
+	}
+	if flagged[0].ScheduleItem == nil || flagged[0].ScheduleItem.TimeSlot == nil {
+		t.Error("需替班记录应回填排班项与时间段")
+	}
+}
+
+func TestRefreshSubstituteFlags_FlagAndClear(t *testing.T) {
+	repos := newTestScheduleRepos()
+	schedule := seedCalendarData(t, repos)
+	repos.user.users["admin-1"] = &model.User{UserID: "admin-1", Name: "管理员", Role: model.RoleAdmin}
+	repos.user.users["user-1"] = &model.User{UserID: "user-1", Name: "张三", Role: "member"}
+	ctx := context.Background()
+
+	repo := repos.toRepository()
+	cal, _ := loadSemesterCalendar(ctx, repo, repos.semester.semesters["sem-cal"])
+	if _, err := syncDutyRecords(ctx, repo, schedule, cal, mustDate(t, "2025-09-01"), "admin-1"); err != nil {
+		t.Fatalf("生成值班记录失败: %v", err)
+	}
+
+	// 登记临时不可用 → 标记当天记录并通知管理员
+	date := mustDate(t, "2025-09-15")
+	repos.unavailable.times = []model.UnavailableTime{onceOffUnavailable(t, "ut-1", "user-1", "2025-09-15", "09:00", "11:00")}
+	flagged, err := refreshSubstituteFlags(ctx, repo, "sem-cal", "user-1", onceOffDates(repos.unavailable.times[0]), "user-1")
+	if err != nil {
+		t.Fatalf("刷新替班标记失败: %v", err)
+	}
+	if len(flagged) != 1 || !flagged[0].DutyDate.Equal(date) {
+		t.Fatalf("期望标记 09-15 的值班记录，实际 %+v", flagged)
+	}
+	notifySubstituteNeeded(ctx, repo, zap.NewNop(), flagged)
+	if len(repos.notification.notifications) != 1 {
+		t.Fatalf("期望 1 条管理员通知，实际 %d", len(repos.notification.notifications))
+	}
+	n := repos.notification.notifications[0]
+	if n.UserID != "admin-1" || n.Type != model.NotificationTypeSubstituteNeeded || n.RelatedID == nil || *n.RelatedID != flagged[0].DutyRecordID {
+		t.Errorf("通知内容不符: %+v", n)
+	}
+
+	// 重复刷新不应重复标记
+	again, err := refreshSubstituteFlags(ctx, repo, "sem-cal", "user-1", []time.Time{date}, "user-1")
+	if err != nil || len(again) != 0 {
+		t.Errorf("已标记的记录不应重复返回: %v, %d", err, len(again))
+	}
+
+	// 撤销临时不可用 → 清除标记
+	repos.unavailable.times = nil
+	if _, err := refreshSubstituteFlags(ctx, repo, "sem-cal", "user-1", []time.Time{date}, "user-1"); err != nil {
+		t.Fatalf("刷新替班标记失败: %v", err)
+	}
+	for _, r := range repos.dutyRecord.records {
+		if r.NeedsSubstitute {
+			t.Errorf("撤销后 %s 不应仍需替班", r.DutyDate.Format(model.TimeFormatDate))
+		}
+	}
+}
+
+func TestScheduleService_ListSubstituteNeeded(t *testing.T) {
+	svc, repos := setupTestScheduleService()
+	seedCalendarData(t, repos)
+	repos.dutyRecord.records["duty-1"] = &model.DutyRecord{
+		DutyRecordID: "duty-1", ScheduleItemID: strPtr("item-w1"), MemberID: "user-1",
+		DutyDate: mustDate(t, "2099-01-05"), Status: model.DutyRecordStatusPending,
+		NeedsSubstitute: true, SubstituteReason: "临时不可用",
+	}
+	repos.dutyRecord.records["duty-2"] = &model.DutyRecord{
+		DutyRecordID: "duty-2", ScheduleItemID: strPtr("item-w2"), MemberID: "user-2",
+		DutyDate: mustDate(t, "2099-01-12"), Status: model.DutyRecordStatusPending,
+	}
+
+	list, err := svc.ListSubstituteNeeded(context.Background(), "sched-cal")
+	if err != nil {
+		t.Fatalf("查询需替班记录失败: %v", err)
+	}
+	if len(list) != 1 || list[0].ID != "duty-1" || !list[0].NeedsSubstitute {
+		t.Errorf("期望仅返回 duty-1，实际 %+v", list)
+	}
+
+	repos.schedule.schedules["sched-cal"].Status = model.ScheduleStatusDraft
+	if _, err := svc.ListSubstituteNeeded(context.Background(), "sched-cal"); !errors.Is(err, ErrScheduleNotPublished) {
+		t.Errorf("草稿排班表应返回 ErrScheduleNotPublished，实际 %v", err)
+	}
+}
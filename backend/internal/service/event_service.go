@@ -0,0 +1,1242 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/model"
+	"echo-union/backend/internal/repository"
+)
+
+// ── 活动值班模块业务错误 ──
+
+var (
+	ErrEventNotFound           = errors.New("活动不存在")
+	ErrEventNotDraft           = errors.New("仅草稿状态的活动可执行此操作")
+	ErrEventCancelled          = errors.New("活动已取消")
+	ErrEventNoShifts           = errors.New("活动尚未添加班次")
+	ErrEventSignupClosed       = errors.New("活动未开放报名")
+	ErrEventShiftNotFound      = errors.New("活动班次不存在")
+	ErrEventShiftInvalid       = errors.New("班次日期或时间无效")
+	ErrEventShiftOutOfSemester = errors.New("班次日期不在学期范围内")
+	ErrEventShiftLocked        = errors.New("活动已发布，不能修改班次日期、时间或删除班次")
+	ErrEventShiftHeadcount     = errors.New("班次人数不能少于已安排人数")
+	ErrEventShiftFull          = errors.New("班次已满员")
+	ErrEventShiftStarted       = errors.New("班次已开始")
+	ErrEventAlreadyAssigned    = errors.New("成员已在该班次中")
+	ErrEventAssignmentNotFound = errors.New("成员不在该班次中")
+	ErrEventMemberUnavailable  = errors.New("成员在该班次时段不可用")
+	ErrEventMemberUnqualified  = errors.New("成员不具备班次所需技能")
+)
+
+// EventService 活动值班业务接口
+//
+// 设计说明：
+//   - 活动（迎新、换届选举、晚会等）由若干具体日期的班次组成，不走每周排班模板
+//   - 草稿阶段编辑班次、预先指派；发布后按班次人员生成值班记录，此后人员变更同步增删记录
+//   - 成员报名与自动分配均按课表、不可用时间、周常值班与其他活动班次校验可用性
+type EventService interface {
+	CreateEvent(ctx context.Context, req *dto.CreateEventRequest, callerID string) (*dto.EventResponse, error)
+	ListEvents(ctx context.Context, req *dto.EventListRequest, includeDraft bool) ([]dto.EventResponse, error)
+	GetEvent(ctx context.Context, id string, includeDraft bool) (*dto.EventDetailResponse, error)
+	UpdateEvent(ctx context.Context, id string, req *dto.UpdateEventRequest, callerID string) (*dto.EventResponse, error)
+	DeleteEvent(ctx context.Context, id, callerID string) error
+	PublishEvent(ctx context.Context, id, callerID string) (*dto.EventResponse, error)
+	CancelEvent(ctx context.Context, id, callerID string) (*dto.EventResponse, error)
+
+	CreateShift(ctx context.Context, eventID string, req *dto.CreateEventShiftRequest, callerID string) (*dto.EventShiftResponse, error)
+	UpdateShift(ctx context.Context, shiftID string, req *dto.UpdateEventShiftRequest, callerID string) (*dto.EventShiftResponse, error)
+	DeleteShift(ctx context.Context, shiftID, callerID string) error
+
+	SignUp(ctx context.Context, shiftID, userID string) error
+	Withdraw(ctx context.Context, shiftID, userID string) error
+	AssignMember(ctx context.Context, shiftID, memberID, callerID string) error
+	UnassignMember(ctx context.Context, shiftID, memberID, callerID string) error
+	AutoAssign(ctx context.Context, eventID, callerID string) (*dto.EventAutoAssignResponse, error)
+	ListMyShifts(ctx context.Context, userID string) ([]dto.MyEventShiftResponse, error)
+}
+
+type eventService struct {
+	repo   *repository.Repository
+	logger *zap.Logger
+}
+
+// NewEventService 创建 EventService 实例
+func NewEventService(repo *repository.Repository, logger *zap.Logger) EventService {
+	return &eventService{repo: repo, logger: logger}
+}
+
+// ════════════════════════════════════════════════════════════
+// 活动
+// ════════════════════════════════════════════════════════════
+
+func (s *eventService) CreateEvent(ctx context.Context, req *dto.CreateEventRequest, callerID string) (*dto.EventResponse, error) {
+	if _, err := s.repo.Semester.GetByID(ctx, req.SemesterID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSemesterNotFound
+		}
+		s.logger.Error("查询学期失败", zap.Error(err))
+		return nil, err
+	}
+
+	event := &model.Event{
+		SemesterID:  req.SemesterID,
+		Name:        req.Name,
+		Description: req.Description,
+		Status:      model.EventStatusDraft,
+		SignupOpen:  req.SignupOpen,
+	}
+	event.CreatedBy = &callerID
+	event.UpdatedBy = &callerID
+
+	if err := s.repo.Event.Create(ctx, event); err != nil {
+		s.logger.Error("创建活动失败", zap.Error(err))
+		return nil, err
+	}
+	return toEventResponse(event), nil
+}
+
+func (s *eventService) ListEvents(ctx context.Context, req *dto.EventListRequest, includeDraft bool) ([]dto.EventResponse, error) {
+	events, err := s.repo.Event.List(ctx, &repository.EventFilters{
+		SemesterID:   req.SemesterID,
+		Status:       req.Status,
+		ExcludeDraft: !includeDraft,
+	})
+	if err != nil {
+		s.logger.Error("查询活动列表失败", zap.Error(err))
+		return nil, err
+	}
+
+	result := make([]dto.EventResponse, 0, len(events))
+	for i := range events {
+		result = append(result, *toEventResponse(&events[i]))
+	}
+	return result, nil
+}
+
+// GetEvent 活动详情；非管理员看不到草稿活动
+func (s *eventService) GetEvent(ctx context.Context, id string, includeDraft bool) (*dto.EventDetailResponse, error) {
+	event, err := s.getEvent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !includeDraft && event.Status == model.EventStatusDraft {
+		return nil, ErrEventNotFound
+	}
+
+	shifts, err := s.repo.EventShift.ListByEvent(ctx, id)
+	if err != nil {
+		s.logger.Error("查询活动班次失败", zap.Error(err))
+		return nil, err
+	}
+
+	resp := &dto.EventDetailResponse{
+		EventResponse: *toEventResponse(event),
+		Shifts:        make([]dto.EventShiftResponse, 0, len(shifts)),
+	}
+	for i := range shifts {
+		resp.Shifts = append(resp.Shifts, toEventShiftResponse(&shifts[i]))
+	}
+	return resp, nil
+}
+
+func (s *eventService) UpdateEvent(ctx context.Context, id string, req *dto.UpdateEventRequest, callerID string) (*dto.EventResponse, error) {
+	event, err := s.getEvent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if event.Status == model.EventStatusCancelled {
+		return nil, ErrEventCancelled
+	}
+
+	if req.Name != nil {
+		event.Name = *req.Name
+	}
+	if req.Description != nil {
+		event.Description = *req.Description
+	}
+	if req.SignupOpen != nil {
+		event.SignupOpen = *req.SignupOpen
+	}
+	event.UpdatedBy = &callerID
+
+	if err := s.repo.Event.Update(ctx, event); err != nil {
+		s.logger.Error("更新活动失败", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+	return toEventResponse(event), nil
+}
+
+// DeleteEvent 删除草稿活动；已发布的活动请取消
+func (s *eventService) DeleteEvent(ctx context.Context, id, callerID string) error {
+	event, err := s.getEvent(ctx, id)
+	if err != nil {
+		return err
+	}
+	if event.Status != model.EventStatusDraft {
+		return ErrEventNotDraft
+	}
+
+	if err := s.repo.Event.Delete(ctx, id, callerID); err != nil {
+		s.logger.Error("删除活动失败", zap.String("id", id), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// PublishEvent 发布活动：为已安排的班次人员生成值班记录并通知成员
+func (s *eventService) PublishEvent(ctx context.Context, id, callerID string) (*dto.EventResponse, error) {
+	event, err := s.getEvent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if event.Status != model.EventStatusDraft {
+		return nil, ErrEventNotDraft
+	}
+	shifts, err := s.repo.EventShift.ListByEvent(ctx, id)
+	if err != nil {
+		s.logger.Error("查询活动班次失败", zap.Error(err))
+		return nil, err
+	}
+	if len(shifts) == 0 {
+		return nil, ErrEventNoShifts
+	}
+
+	var records []model.DutyRecord
+	for i := range shifts {
+		for _, a := range shifts[i].Assignments {
+			records = append(records, eventDutyRecord(&shifts[i], a.MemberID, callerID))
+		}
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rollbackTx := func() {
+		if tx != nil {
+			tx.Rollback()
+		}
+	}
+	txRepo := s.repo.WithTx(tx)
+
+	event.Status = model.EventStatusPublished
+	event.UpdatedBy = &callerID
+	if err := txRepo.Event.Update(ctx, event); err != nil {
+		rollbackTx()
+		s.logger.Error("发布活动失败", zap.Error(err))
+		return nil, err
+	}
+	if err := txRepo.DutyRecord.BatchCreate(ctx, records); err != nil {
+		rollbackTx()
+		s.logger.Error("生成活动值班记录失败", zap.Error(err))
+		return nil, err
+	}
+	if tx != nil {
+		if err := tx.Commit().Error; err != nil {
+			return nil, err
+		}
+	}
+
+	// 回填班次，便于生成通知（BatchCreate 之后，避免 GORM 级联写入关联）
+	shiftByID := make(map[string]*model.EventShift, len(shifts))
+	for i := range shifts {
+		shiftByID[shifts[i].EventShiftID] = &shifts[i]
+	}
+	for i := range records {
+		records[i].EventShift = shiftByID[*records[i].EventShiftID]
+	}
+	s.notifyAssigned(ctx, event, records)
+	return toEventResponse(event), nil
+}
+
+// CancelEvent 取消活动：作废尚未开始的值班记录，已发布的活动通知班次人员
+func (s *eventService) CancelEvent(ctx context.Context, id, callerID string) (*dto.EventResponse, error) {
+	event, err := s.getEvent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if event.Status == model.EventStatusCancelled {
+		return nil, ErrEventCancelled
+	}
+	wasPublished := event.Status == model.EventStatusPublished
+
+	shifts, err := s.repo.EventShift.ListByEvent(ctx, id)
+	if err != nil {
+		s.logger.Error("查询活动班次失败", zap.Error(err))
+		return nil, err
+	}
+	shiftIDs := make([]string, 0, len(shifts))
+	for _, shift := range shifts {
+		shiftIDs = append(shiftIDs, shift.EventShiftID)
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rollbackTx := func() {
+		if tx != nil {
+			tx.Rollback()
+		}
+	}
+	txRepo := s.repo.WithTx(tx)
+
+	event.Status = model.EventStatusCancelled
+	event.UpdatedBy = &callerID
+	if err := txRepo.Event.Update(ctx, event); err != nil {
+		rollbackTx()
+		s.logger.Error("取消活动失败", zap.Error(err))
+		return nil, err
+	}
+	if err := txRepo.DutyRecord.DeletePendingByEventShifts(ctx, shiftIDs, "", callerID); err != nil {
+		rollbackTx()
+		s.logger.Error("作废活动值班记录失败", zap.Error(err))
+		return nil, err
+	}
+	if tx != nil {
+		if err := tx.Commit().Error; err != nil {
+			return nil, err
+		}
+	}
+
+	if wasPublished {
+		s.notifyCancelled(ctx, event, shifts)
+	}
+	return toEventResponse(event), nil
+}
+
+// ════════════════════════════════════════════════════════════
+// 班次
+// ════════════════════════════════════════════════════════════
+
+func (s *eventService) CreateShift(ctx context.Context, eventID string, req *dto.CreateEventShiftRequest, callerID string) (*dto.EventShiftResponse, error) {
+	event, err := s.getEvent(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if event.Status != model.EventStatusDraft {
+		return nil, ErrEventShiftLocked
+	}
+
+	date, err := parseShiftTime(req.ShiftDate, req.StartTime, req.EndTime)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkShiftDate(ctx, event.SemesterID, date); err != nil {
+		return nil, err
+	}
+	if err := s.checkLocation(ctx, req.LocationID); err != nil {
+		return nil, err
+	}
+	skills, err := resolveSkills(ctx, s.repo, req.SkillIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	shift := &model.EventShift{
+		EventID:    eventID,
+		ShiftDate:  date,
+		StartTime:  req.StartTime,
+		EndTime:    req.EndTime,
+		LocationID: req.LocationID,
+		Headcount:  req.Headcount,
+		Note:       req.Note,
+	}
+	shift.CreatedBy = &callerID
+	shift.UpdatedBy = &callerID
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rollbackTx := func() {
+		if tx != nil {
+			tx.Rollback()
+		}
+	}
+	txRepo := s.repo.WithTx(tx)
+
+	if err := txRepo.EventShift.Create(ctx, shift); err != nil {
+		rollbackTx()
+		s.logger.Error("创建活动班次失败", zap.Error(err))
+		return nil, err
+	}
+	if len(skills) > 0 {
+		if err := txRepo.Skill.ReplaceEventShiftSkills(ctx, shift.EventShiftID, eventShiftSkillRows(shift.EventShiftID, skills, callerID)); err != nil {
+			rollbackTx()
+			s.logger.Error("设置活动班次技能要求失败", zap.Error(err))
+			return nil, err
+		}
+	}
+	if tx != nil {
+		if err := tx.Commit().Error; err != nil {
+			return nil, err
+		}
+	}
+
+	created, err := s.getShift(ctx, shift.EventShiftID)
+	if err != nil {
+		return nil, err
+	}
+	resp := toEventShiftResponse(created)
+	return &resp, nil
+}
+
+// UpdateShift 更新班次；日期与时间只能在草稿阶段修改，人数不能少于已安排人数。
+// 技能要求修改后只约束此后的报名与指派，已安排的人员保持不变。
+func (s *eventService) UpdateShift(ctx context.Context, shiftID string, req *dto.UpdateEventShiftRequest, callerID string) (*dto.EventShiftResponse, error) {
+	shift, err := s.getShift(ctx, shiftID)
+	if err != nil {
+		return nil, err
+	}
+	switch shift.Event.Status {
+	case model.EventStatusCancelled:
+		return nil, ErrEventCancelled
+	case model.EventStatusPublished:
+		if req.ShiftDate != nil || req.StartTime != nil || req.EndTime != nil {
+			return nil, ErrEventShiftLocked
+		}
+	}
+
+	if req.ShiftDate != nil || req.StartTime != nil || req.EndTime != nil {
+		dateStr, start, end := shift.ShiftDate.Format(model.TimeFormatDate), shift.StartTime, shift.EndTime
+		if req.ShiftDate != nil {
+			dateStr = *req.ShiftDate
+		}
+		if req.StartTime != nil {
+			start = *req.StartTime
+		}
+		if req.EndTime != nil {
+			end = *req.EndTime
+		}
+		date, err := parseShiftTime(dateStr, start, end)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.checkShiftDate(ctx, shift.Event.SemesterID, date); err != nil {
+			return nil, err
+		}
+		shift.ShiftDate, shift.StartTime, shift.EndTime = date, start, end
+	}
+	if req.LocationID != nil {
+		if err := s.checkLocation(ctx, req.LocationID); err != nil {
+			return nil, err
+		}
+		shift.LocationID = req.LocationID
+		shift.Location = nil
+	}
+	if req.Headcount != nil {
+		if *req.Headcount < len(shift.Assignments) {
+			return nil, ErrEventShiftHeadcount
+		}
+		shift.Headcount = *req.Headcount
+	}
+	if req.Note != nil {
+		shift.Note = *req.Note
+	}
+	var skills []model.Skill
+	if req.SkillIDs != nil {
+		if skills, err = resolveSkills(ctx, s.repo, *req.SkillIDs); err != nil {
+			return nil, err
+		}
+	}
+	shift.UpdatedBy = &callerID
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rollbackTx := func() {
+		if tx != nil {
+			tx.Rollback()
+		}
+	}
+	txRepo := s.repo.WithTx(tx)
+
+	if err := txRepo.EventShift.Update(ctx, shift); err != nil {
+		rollbackTx()
+		s.logger.Error("更新活动班次失败", zap.String("id", shiftID), zap.Error(err))
+		return nil, err
+	}
+	if req.SkillIDs != nil {
+		if err := txRepo.Skill.ReplaceEventShiftSkills(ctx, shiftID, eventShiftSkillRows(shiftID, skills, callerID)); err != nil {
+			rollbackTx()
+			s.logger.Error("设置活动班次技能要求失败", zap.String("id", shiftID), zap.Error(err))
+			return nil, err
+		}
+	}
+	if tx != nil {
+		if err := tx.Commit().Error; err != nil {
+			return nil, err
+		}
+	}
+
+	updated, err := s.getShift(ctx, shiftID)
+	if err != nil {
+		return nil, err
+	}
+	resp := toEventShiftResponse(updated)
+	return &resp, nil
+}
+
+// DeleteShift 删除草稿活动的班次（连同预先安排的人员）
+func (s *eventService) DeleteShift(ctx context.Context, shiftID, callerID string) error {
+	shift, err := s.getShift(ctx, shiftID)
+	if err != nil {
+		return err
+	}
+	if shift.Event.Status != model.EventStatusDraft {
+		return ErrEventShiftLocked
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	rollbackTx := func() {
+		if tx != nil {
+			tx.Rollback()
+		}
+	}
+	txRepo := s.repo.WithTx(tx)
+
+	if err := txRepo.EventAssignment.DeleteByShift(ctx, shiftID); err != nil {
+		rollbackTx()
+		s.logger.Error("删除班次人员失败", zap.Error(err))
+		return err
+	}
+	if err := txRepo.EventShift.Delete(ctx, shiftID, callerID); err != nil {
+		rollbackTx()
+		s.logger.Error("删除活动班次失败", zap.Error(err))
+		return err
+	}
+	if tx != nil {
+		return tx.Commit().Error
+	}
+	return nil
+}
+
+// ════════════════════════════════════════════════════════════
+// 班次人员：报名 / 退出 / 指派 / 自动分配
+// ════════════════════════════════════════════════════════════
+
+// SignUp 成员报名班次：活动已发布且开放报名，班次未开始、未满员，成员具备所需技能且该时段可用
+func (s *eventService) SignUp(ctx context.Context, shiftID, userID string) error {
+	shift, err := s.getShift(ctx, shiftID)
+	if err != nil {
+		return err
+	}
+	switch {
+	case shift.Event.Status == model.EventStatusCancelled:
+		return ErrEventCancelled
+	case shift.Event.Status != model.EventStatusPublished || !shift.Event.SignupOpen:
+		return ErrEventSignupClosed
+	case shiftStarted(shift, time.Now()):
+		return ErrEventShiftStarted
+	}
+
+	_, err = s.addAssignment(ctx, shift, userID, model.EventAssignmentSignup, userID)
+	return err
+}
+
+// Withdraw 成员退出尚未开始的班次
+func (s *eventService) Withdraw(ctx context.Context, shiftID, userID string) error {
+	shift, err := s.getShift(ctx, shiftID)
+	if err != nil {
+		return err
+	}
+	if shiftStarted(shift, time.Now()) {
+		return ErrEventShiftStarted
+	}
+	return s.removeAssignment(ctx, shift, userID, userID)
+}
+
+// AssignMember 管理员指派成员（同样校验技能与可用性；活动已发布时同步生成值班记录并通知成员）
+func (s *eventService) AssignMember(ctx context.Context, shiftID, memberID, callerID string) error {
+	shift, err := s.getShift(ctx, shiftID)
+	if err != nil {
+		return err
+	}
+	if shift.Event.Status == model.EventStatusCancelled {
+		return ErrEventCancelled
+	}
+	if _, err := s.repo.User.GetByID(ctx, memberID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+
+	record, err := s.addAssignment(ctx, shift, memberID, model.EventAssignmentAdmin, callerID)
+	if err != nil {
+		return err
+	}
+	if record != nil {
+		s.notifyAssigned(ctx, shift.Event, []model.DutyRecord{*record})
+	}
+	return nil
+}
+
+// UnassignMember 管理员移除班次人员（已开始的值班记录保持不变）
+func (s *eventService) UnassignMember(ctx context.Context, shiftID, memberID, callerID string) error {
+	shift, err := s.getShift(ctx, shiftID)
+	if err != nil {
+		return err
+	}
+	if shift.Event.Status == model.EventStatusCancelled {
+		return ErrEventCancelled
+	}
+	return s.removeAssignment(ctx, shift, memberID, callerID)
+}
+
+// AutoAssign 按技能要求与可用性为未满员、未开始的班次补足人手。
+// 候选人为学期值班人员，优先选择本学期活动班次最少的成员，同等负载按用户 ID 排序保证结果稳定。
+func (s *eventService) AutoAssign(ctx context.Context, eventID, callerID string) (*dto.EventAutoAssignResponse, error) {
+	event, err := s.getEvent(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if event.Status == model.EventStatusCancelled {
+		return nil, ErrEventCancelled
+	}
+
+	shifts, err := s.repo.EventShift.ListByEvent(ctx, eventID)
+	if err != nil {
+		s.logger.Error("查询活动班次失败", zap.Error(err))
+		return nil, err
+	}
+	dutyMembers, err := s.repo.UserSemesterAssignment.ListDutyRequiredBySemester(ctx, event.SemesterID)
+	if err != nil {
+		s.logger.Error("查询值班人员失败", zap.Error(err))
+		return nil, err
+	}
+	avail, err := s.loadAvailability(ctx, event.SemesterID)
+	if err != nil {
+		return nil, err
+	}
+	var required []model.Skill
+	for i := range shifts {
+		required = append(required, eventShiftSkills(&shifts[i])...)
+	}
+	holders, err := loadSkillHolders(ctx, s.repo, required)
+	if err != nil {
+		s.logger.Error("查询成员技能失败", zap.Error(err))
+		return nil, err
+	}
+
+	now := time.Now()
+	var created []model.EventAssignment
+	resp := &dto.EventAutoAssignResponse{Unfilled: []dto.UnfilledEventShift{}}
+	for i := range shifts {
+		shift := &shifts[i]
+		need := shift.Headcount - len(shift.Assignments)
+		if need <= 0 || shiftStarted(shift, now) {
+			continue
+		}
+
+		assigned := make(map[string]bool, len(shift.Assignments))
+		for _, a := range shift.Assignments {
+			assigned[a.MemberID] = true
+		}
+		skills := eventShiftSkills(shift)
+		var candidates []string
+		for _, m := range dutyMembers {
+			if !assigned[m.UserID] && len(holders.missing(m.UserID, skills)) == 0 && len(avail.conflicts(m.UserID, shift)) == 0 {
+				candidates = append(candidates, m.UserID)
+			}
+		}
+		sort.Slice(candidates, func(a, b int) bool {
+			la, lb := avail.load(candidates[a]), avail.load(candidates[b])
+			if la != lb {
+				return la < lb
+			}
+			return candidates[a] < candidates[b]
+		})
+
+		for _, memberID := range candidates {
+			if need == 0 {
+				break
+			}
+			a := model.EventAssignment{EventShiftID: shift.EventShiftID, MemberID: memberID, Source: model.EventAssignmentAuto}
+			a.CreatedBy = &callerID
+			a.UpdatedBy = &callerID
+			a.EventShift = shift
+			created = append(created, a)
+			avail.add(a) // 后续班次需看到本次分配
+			need--
+		}
+		if need > 0 {
+			resp.Unfilled = append(resp.Unfilled, dto.UnfilledEventShift{
+				ShiftID:   shift.EventShiftID,
+				ShiftDate: shift.ShiftDate.Format(model.TimeFormatDate),
+				StartTime: shift.StartTime,
+				EndTime:   shift.EndTime,
+				Missing:   need,
+			})
+		}
+	}
+	resp.Assigned = len(created)
+	if len(created) == 0 {
+		return resp, nil
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rollbackTx := func() {
+		if tx != nil {
+			tx.Rollback()
+		}
+	}
+	txRepo := s.repo.WithTx(tx)
+
+	var records []model.DutyRecord
+	var recordShifts []*model.EventShift
+	for i := range created {
+		a := created[i]
+		a.EventShift = nil // 避免 GORM 级联写入班次
+		if err := txRepo.EventAssignment.Create(ctx, &a); err != nil {
+			rollbackTx()
+			s.logger.Error("自动分配活动班次失败", zap.Error(err))
+			return nil, err
+		}
+		if event.Status == model.EventStatusPublished {
+			records = append(records, eventDutyRecord(created[i].EventShift, a.MemberID, callerID))
+			recordShifts = append(recordShifts, created[i].EventShift)
+		}
+	}
+	if err := txRepo.DutyRecord.BatchCreate(ctx, records); err != nil {
+		rollbackTx()
+		s.logger.Error("生成活动值班记录失败", zap.Error(err))
+		return nil, err
+	}
+	if tx != nil {
+		if err := tx.Commit().Error; err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range records {
+		records[i].EventShift = recordShifts[i]
+	}
+	s.notifyAssigned(ctx, event, records)
+	return resp, nil
+}
+
+// ListMyShifts 我接下来的活动班次（仅已发布活动）
+func (s *eventService) ListMyShifts(ctx context.Context, userID string) ([]dto.MyEventShiftResponse, error) {
+	assignments, err := s.repo.EventAssignment.ListByMemberFrom(ctx, userID, dateOnly(time.Now()))
+	if err != nil {
+		s.logger.Error("查询我的活动班次失败", zap.Error(err))
+		return nil, err
+	}
+
+	result := make([]dto.MyEventShiftResponse, 0, len(assignments))
+	for _, a := range assignments {
+		shift := a.EventShift
+		if shift == nil {
+			continue
+		}
+		item := dto.MyEventShiftResponse{
+			EventID:   shift.EventID,
+			ShiftID:   shift.EventShiftID,
+			ShiftDate: shift.ShiftDate.Format(model.TimeFormatDate),
+			StartTime: shift.StartTime,
+			EndTime:   shift.EndTime,
+			Location:  toEventLocationBrief(shift.Location),
+			Source:    a.Source,
+		}
+		if shift.Event != nil {
+			item.EventName = shift.Event.Name
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+// addAssignment 校验名额、技能与可用性后加入班次；活动已发布时同步生成值班记录并返回
+func (s *eventService) addAssignment(ctx context.Context, shift *model.EventShift, memberID, source, operatorID string) (*model.DutyRecord, error) {
+	for _, a := range shift.Assignments {
+		if a.MemberID == memberID {
+			return nil, ErrEventAlreadyAssigned
+		}
+	}
+	if len(shift.Assignments) >= shift.Headcount {
+		return nil, ErrEventShiftFull
+	}
+
+	if required := eventShiftSkills(shift); len(required) > 0 {
+		holders, err := loadSkillHolders(ctx, s.repo, required)
+		if err != nil {
+			return nil, err
+		}
+		if missing := holders.missing(memberID, required); len(missing) > 0 {
+			return nil, fmt.Errorf("%w: 缺少技能「%s」", ErrEventMemberUnqualified, strings.Join(missing, "、"))
+		}
+	}
+
+	avail, err := s.loadAvailability(ctx, shift.Event.SemesterID)
+	if err != nil {
+		return nil, err
+	}
+	if reasons := avail.conflicts(memberID, shift); len(reasons) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrEventMemberUnavailable, strings.Join(reasons, "; "))
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rollbackTx := func() {
+		if tx != nil {
+			tx.Rollback()
+		}
+	}
+	txRepo := s.repo.WithTx(tx)
+
+	assignment := &model.EventAssignment{EventShiftID: shift.EventShiftID, MemberID: memberID, Source: source}
+	assignment.CreatedBy = &operatorID
+	assignment.UpdatedBy = &operatorID
+	if err := txRepo.EventAssignment.Create(ctx, assignment); err != nil {
+		rollbackTx()
+		s.logger.Error("加入活动班次失败", zap.Error(err))
+		return nil, err
+	}
+
+	var record *model.DutyRecord
+	if shift.Event.Status == model.EventStatusPublished {
+		records := []model.DutyRecord{eventDutyRecord(shift, memberID, operatorID)}
+		if err := txRepo.DutyRecord.BatchCreate(ctx, records); err != nil {
+			rollbackTx()
+			s.logger.Error("生成活动值班记录失败", zap.Error(err))
+			return nil, err
+		}
+		record = &records[0]
+	}
+	if tx != nil {
+		if err := tx.Commit().Error; err != nil {
+			return nil, err
+		}
+	}
+	if record != nil {
+		record.EventShift = shift
+	}
+	return record, nil
+}
+
+// removeAssignment 移出班次并作废对应的待值班记录
+func (s *eventService) removeAssignment(ctx context.Context, shift *model.EventShift, memberID, operatorID string) error {
+	found := false
+	for _, a := range shift.Assignments {
+		if a.MemberID == memberID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return ErrEventAssignmentNotFound
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	rollbackTx := func() {
+		if tx != nil {
+			tx.Rollback()
+		}
+	}
+	txRepo := s.repo.WithTx(tx)
+
+	if err := txRepo.EventAssignment.Delete(ctx, shift.EventShiftID, memberID); err != nil {
+		rollbackTx()
+		s.logger.Error("移出活动班次失败", zap.Error(err))
+		return err
+	}
+	if err := txRepo.DutyRecord.DeletePendingByEventShifts(ctx, []string{shift.EventShiftID}, memberID, operatorID); err != nil {
+		rollbackTx()
+		s.logger.Error("作废活动值班记录失败", zap.Error(err))
+		return err
+	}
+	if tx != nil {
+		return tx.Commit().Error
+	}
+	return nil
+}
+
+// ════════════════════════════════════════════════════════════
+// 可用性
+// ════════════════════════════════════════════════════════════
+
+// eventAvailability 学期内成员时间占用情况（课表、不可用时间、周常值班、活动班次）
+type eventAvailability struct {
+	cal          *semesterCalendar
+	courses      map[string][]model.CourseSchedule
+	unavailables map[string][]model.UnavailableTime
+	weekly       map[string][]model.ScheduleItem    // 已发布排班中的排班项（TimeSlot 已预加载）
+	shifts       map[string][]model.EventAssignment // 未取消活动的班次人员（EventShift 已预加载）
+}
+
+// loadAvailability 加载学期内全部成员的时间占用；规则 R1 / R2 停用时不校验课表 / 不可用时间
+func (s *eventService) loadAvailability(ctx context.Context, semesterID string) (*eventAvailability, error) {
+	semester, err := s.repo.Semester.GetByID(ctx, semesterID)
+	if err != nil {
+		s.logger.Error("查询学期失败", zap.Error(err))
+		return nil, err
+	}
+	cal, err := loadSemesterCalendar(ctx, s.repo, semester)
+	if err != nil {
+		s.logger.Error("加载校历失败", zap.Error(err))
+		return nil, err
+	}
+
+	avail := &eventAvailability{
+		cal:          cal,
+		courses:      make(map[string][]model.CourseSchedule),
+		unavailables: make(map[string][]model.UnavailableTime),
+		weekly:       make(map[string][]model.ScheduleItem),
+		shifts:       make(map[string][]model.EventAssignment),
+	}
+
+	rules := loadEnabledRules(ctx, s.repo)
+	if rules["R1"] {
+		courses, err := s.repo.CourseSchedule.ListBySemester(ctx, semesterID)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range courses {
+			avail.courses[c.UserID] = append(avail.courses[c.UserID], c)
+		}
+	}
+	if rules["R2"] {
+		uts, err := s.repo.UnavailableTime.ListBySemester(ctx, semesterID)
+		if err != nil {
+			return nil, err
+		}
+		for _, ut := range uts {
+			avail.unavailables[ut.UserID] = append(avail.unavailables[ut.UserID], ut)
+		}
+	}
+
+	published, err := s.repo.Schedule.ListBySemesterAndStatus(ctx, semesterID, model.ScheduleStatusPublished)
+	if err != nil {
+		return nil, err
+	}
+	for _, schedule := range published {
+		items, err := s.repo.ScheduleItem.ListBySchedule(ctx, schedule.ScheduleID)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			if item.TimeSlot != nil {
+				avail.weekly[item.MemberID] = append(avail.weekly[item.MemberID], item)
+			}
+		}
+	}
+
+	assignments, err := s.repo.EventAssignment.ListActiveBySemester(ctx, semesterID)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range assignments {
+		avail.add(a)
+	}
+	return avail, nil
+}
+
+// add 记录一条班次占用
+func (a *eventAvailability) add(assignment model.EventAssignment) {
+	if assignment.EventShift != nil {
+		a.shifts[assignment.MemberID] = append(a.shifts[assignment.MemberID], assignment)
+	}
+}
+
+// load 成员本学期已安排的活动班次数
+func (a *eventAvailability) load(memberID string) int {
+	return len(a.shifts[memberID])
+}
+
+// conflicts 返回成员在班次时段的全部冲突原因，空表示可用
+func (a *eventAvailability) conflicts(memberID string, shift *model.EventShift) []string {
+	day := a.cal.resolve(shift.ShiftDate)
+	start, end := shift.StartTime, shift.EndTime
+	var reasons []string
+
+	// 课表：放假当天不上课；有周次数组时按教学周精确匹配，否则按单双周
+	if day.kind != model.CalendarDayHoliday {
+		for _, c := range a.courses[memberID] {
+			if c.DayOfWeek != day.dayOfWeek || !(start < c.EndTime && c.StartTime < end) {
+				continue
+			}
+			if courseRunsInWeek(c, day) {
+				reasons = append(reasons, fmt.Sprintf("与课程「%s」冲突", c.CourseName))
+			}
+		}
+	}
+
+	slot := &model.TimeSlot{StartTime: start, EndTime: end}
+	uts := a.unavailables[memberID]
+	for _, ut := range uts {
+		if hasUnavailableConflict(ut, day.dayOfWeek, start, end, day.weekType) {
+			reasons = append(reasons, unavailableReason(ut))
+		}
+	}
+	if ut := onceOffConflict(uts, shift.ShiftDate, slot); ut != nil {
+		reasons = append(reasons, unavailableReason(*ut))
+	}
+
+	if day.dutyDay && day.cycleWeek > 0 {
+		for _, item := range a.weekly[memberID] {
+			ts := item.TimeSlot
+			if item.WeekNumber == day.cycleWeek && ts.DayOfWeek == day.dayOfWeek && start < ts.EndTime && ts.StartTime < end {
+				reasons = append(reasons, fmt.Sprintf("与周常值班「%s」冲突", ts.Name))
+			}
+		}
+	}
+
+	for _, other := range a.shifts[memberID] {
+		os := other.EventShift
+		if os.EventShiftID == shift.EventShiftID || !dateOnly(os.ShiftDate).Equal(dateOnly(shift.ShiftDate)) {
+			continue
+		}
+		if start < os.EndTime && os.StartTime < end {
+			reasons = append(reasons, fmt.Sprintf("与其他活动班次（%s-%s）冲突", os.StartTime, os.EndTime))
+		}
+	}
+	return reasons
+}
+
+// courseRunsInWeek 课程是否在该教学周上课
+func courseRunsInWeek(c model.CourseSchedule, day calendarDay) bool {
+	if len(c.Weeks) > 0 {
+		for _, w := range c.Weeks {
+			if w == day.teachingWeek {
+				return true
+			}
+		}
+		return false
+	}
+	return c.WeekType == model.WeekTypeAll || day.weekType == "" || c.WeekType == day.weekType
+}
+
+func unavailableReason(ut model.UnavailableTime) string {
+	if ut.Reason != "" {
+		return fmt.Sprintf("与不可用时间「%s」冲突", ut.Reason)
+	}
+	return "与不可用时间冲突"
+}
+
+// ════════════════════════════════════════════════════════════
+// 通知
+// ════════════════════════════════════════════════════════════
+
+// notifyAssigned 通知成员被安排到活动班次（尽力而为，失败只记录日志）
+func (s *eventService) notifyAssigned(ctx context.Context, event *model.Event, records []model.DutyRecord) {
+	if len(records) == 0 {
+		return
+	}
+	relatedType := model.NotificationRelatedDutyRecord
+	notifications := make([]model.Notification, 0, len(records))
+	for _, r := range records {
+		relatedID := r.DutyRecordID
+		slot := ""
+		if r.EventShift != nil {
+			slot = fmt.Sprintf("（%s-%s）", r.EventShift.StartTime, r.EventShift.EndTime)
+		}
+		notifications = append(notifications, model.Notification{
+			UserID:      r.MemberID,
+			Type:        model.NotificationTypeEventAssigned,
+			Title:       "活动值班安排",
+			Content:     fmt.Sprintf("你已被安排在 %s%s 参加「%s」值班", r.DutyDate.Format(model.TimeFormatDate), slot, event.Name),
+			RelatedType: &relatedType,
+			RelatedID:   &relatedID,
+		})
+	}
+	if err := s.repo.Notification.BatchCreate(ctx, notifications); err != nil {
+		s.logger.Warn("发送活动值班通知失败", zap.Error(err))
+	}
+}
+
+// notifyCancelled 通知班次人员活动已取消（尽力而为）
+func (s *eventService) notifyCancelled(ctx context.Context, event *model.Event, shifts []model.EventShift) {
+	seen := make(map[string]bool)
+	var notifications []model.Notification
+	for _, shift := range shifts {
+		for _, a := range shift.Assignments {
+			if seen[a.MemberID] {
+				continue
+			}
+			seen[a.MemberID] = true
+			notifications = append(notifications, model.Notification{
+				UserID:  a.MemberID,
+				Type:    model.NotificationTypeEventCancelled,
+				Title:   "活动已取消",
+				Content: fmt.Sprintf("「%s」已取消，相关值班安排作废", event.Name),
+			})
+		}
+	}
+	if len(notifications) == 0 {
+		return
+	}
+	if err := s.repo.Notification.BatchCreate(ctx, notifications); err != nil {
+		s.logger.Warn("发送活动取消通知失败", zap.Error(err))
+	}
+}
+
+// ── 内部辅助方法 ──
+
+func (s *eventService) getEvent(ctx context.Context, id string) (*model.Event, error) {
+	event, err := s.repo.Event.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEventNotFound
+		}
+		s.logger.Error("查询活动失败", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+	return event, nil
+}
+
+// getShift 查询班次（含所属活动；活动已删除时视为班次不存在）
+func (s *eventService) getShift(ctx context.Context, id string) (*model.EventShift, error) {
+	shift, err := s.repo.EventShift.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEventShiftNotFound
+		}
+		s.logger.Error("查询活动班次失败", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+	if shift.Event == nil {
+		return nil, ErrEventShiftNotFound
+	}
+	return shift, nil
+}
+
+func (s *eventService) checkShiftDate(ctx context.Context, semesterID string, date time.Time) error {
+	semester, err := s.repo.Semester.GetByID(ctx, semesterID)
+	if err != nil {
+		s.logger.Error("查询学期失败", zap.Error(err))
+		return err
+	}
+	if !newSemesterCalendar(semester, nil).contains(date) {
+		return ErrEventShiftOutOfSemester
+	}
+	return nil
+}
+
+func (s *eventService) checkLocation(ctx context.Context, locationID *string) error {
+	if locationID == nil {
+		return nil
+	}
+	if _, err := s.repo.Location.GetByID(ctx, *locationID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrLocationNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// parseShiftTime 校验班次日期（YYYY-MM-DD）与起止时间（HH:MM，结束晚于开始）
+func parseShiftTime(date, start, end string) (time.Time, error) {
+	d, err := time.Parse(model.TimeFormatDate, date)
+	if err != nil {
+		return time.Time{}, ErrEventShiftInvalid
+	}
+	st, err1 := time.Parse("15:04", start)
+	et, err2 := time.Parse("15:04", end)
+	if err1 != nil || err2 != nil || !et.After(st) {
+		return time.Time{}, ErrEventShiftInvalid
+	}
+	return dateOnly(d), nil
+}
+
+// shiftStarted 班次是否已开始
+func shiftStarted(shift *model.EventShift, now time.Time) bool {
+	today := dateOnly(now)
+	date := dateOnly(shift.ShiftDate)
+	if date.Before(today) {
+		return true
+	}
+	return date.Equal(today) && now.Format("15:04") >= shift.StartTime
+}
+
+// eventDutyRecord 班次人员对应的值班记录
+func eventDutyRecord(shift *model.EventShift, memberID, operatorID string) model.DutyRecord {
+	shiftID := shift.EventShiftID
+	record := model.DutyRecord{
+		EventShiftID: &shiftID,
+		MemberID:     memberID,
+		DutyDate:     dateOnly(shift.ShiftDate),
+		Status:       model.DutyRecordStatusPending,
+	}
+	record.CreatedBy = &operatorID
+	record.UpdatedBy = &operatorID
+	return record
+}
+
+func toEventResponse(e *model.Event) *dto.EventResponse {
+	return &dto.EventResponse{
+		ID:          e.EventID,
+		SemesterID:  e.SemesterID,
+		Name:        e.Name,
+		Description: e.Description,
+		Status:      e.Status,
+		SignupOpen:  e.SignupOpen,
+		CreatedAt:   e.CreatedAt.Format(model.TimeFormatDateTime),
+		UpdatedAt:   e.UpdatedAt.Format(model.TimeFormatDateTime),
+	}
+}
+
+func toEventShiftResponse(shift *model.EventShift) dto.EventShiftResponse {
+	resp := dto.EventShiftResponse{
+		ID:             shift.EventShiftID,
+		EventID:        shift.EventID,
+		ShiftDate:      shift.ShiftDate.Format(model.TimeFormatDate),
+		StartTime:      shift.StartTime,
+		EndTime:        shift.EndTime,
+		Location:       toEventLocationBrief(shift.Location),
+		Headcount:      shift.Headcount,
+		Note:           shift.Note,
+		RequiredSkills: toSkillBriefs(eventShiftSkills(shift)),
+		Members:        make([]dto.EventAssignmentResponse, 0, len(shift.Assignments)),
+	}
+	for _, a := range shift.Assignments {
+		member := toMemberBrief(a.Member)
+		if member == nil {
+			member = &dto.MemberBrief{ID: a.MemberID}
+		}
+		resp.Members = append(resp.Members, dto.EventAssignmentResponse{Member: member, Source: a.Source})
+	}
+	return resp
+}
+
+// eventShiftSkillRows 构建活动班次技能要求记录
+func eventShiftSkillRows(shiftID string, skills []model.Skill, callerID string) []model.EventShiftSkill {
+	rows := make([]model.EventShiftSkill, 0, len(skills))
+	for i := range skills {
+		row := model.EventShiftSkill{EventShiftID: shiftID, SkillID: skills[i].SkillID, Skill: &skills[i]}
+		row.CreatedBy = &callerID
+		row.UpdatedBy = &callerID
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func toEventLocationBrief(loc *model.Location) *dto.LocationBrief {
+	if loc == nil {
+		return nil
+	}
+	return &dto.LocationBrief{ID: loc.LocationID, Name: loc.Name}
+}
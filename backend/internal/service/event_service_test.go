@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/model"
+)
+
+// seedEventData 构造活动测试数据：2099 年秋季学期（班次日期必须晚于当前时间），
+// 周一上午周常值班第 1 周由 user-1 负责；user-1..3 均为值班人员
+func seedEventData(t *testing.T, repos *testScheduleRepos) {
+	t.Helper()
+	repos.semester.semesters["sem-evt"] = &model.Semester{
+		SemesterID:    "sem-evt",
+		Name:          "活动测试学期",
+		StartDate:     mustDate(t, "2099-09-07"),
+		EndDate:       mustDate(t, "2099-10-04"),
+		FirstWeekType: "odd",
+		Phase:         model.SemesterPhasePublished,
+	}
+	semID := "sem-evt"
+	ts := &model.TimeSlot{
+		TimeSlotID: "ts-mon", Name: "周一上午", SemesterID: &semID,
+		DayOfWeek: 1, StartTime: "08:10", EndTime: "10:05", IsActive: true,
+	}
+	repos.timeSlot.slots[ts.TimeSlotID] = ts
+	repos.schedule.schedules["sched-evt"] = &model.Schedule{ScheduleID: "sched-evt", SemesterID: "sem-evt", Status: model.ScheduleStatusPublished}
+	repos.scheduleItem.items["item-w1"] = &model.ScheduleItem{
+		ScheduleItemID: "item-w1", ScheduleID: "sched-evt", WeekNumber: 1, TimeSlotID: ts.TimeSlotID, TimeSlot: ts, MemberID: "user-1",
+	}
+	repos.scheduleRule.rules["r1"] = &model.ScheduleRule{RuleID: "r1", RuleCode: "R1", IsEnabled: true}
+	repos.scheduleRule.rules["r2"] = &model.ScheduleRule{RuleID: "r2", RuleCode: "R2", IsEnabled: true}
+	for _, id := range []string{"user-1", "user-2", "user-3"} {
+		repos.user.users[id] = &model.User{UserID: id, Name: id, Role: model.RoleMember}
+		repos.assignment.assignments = append(repos.assignment.assignments, model.UserSemesterAssignment{
+			UserID: id, SemesterID: "sem-evt", DutyRequired: true,
+		})
+	}
+}
+
+func setupEventTest(t *testing.T) (*testScheduleRepos, EventService) {
+	t.Helper()
+	repos := newTestScheduleRepos()
+	seedEventData(t, repos)
+	return repos, NewEventService(repos.toRepository(), zap.NewNop())
+}
+
+func createTestEvent(t *testing.T, svc EventService, signupOpen bool, shifts ...dto.CreateEventShiftRequest) (string, []string) {
+	t.Helper()
+	ctx := context.Background()
+	event, err := svc.CreateEvent(ctx, &dto.CreateEventRequest{SemesterID: "sem-evt", Name: "迎新晚会", SignupOpen: signupOpen}, "admin-1")
+	if err != nil {
+		t.Fatalf("创建活动失败: %v", err)
+	}
+	var shiftIDs []string
+	for i := range shifts {
+		shift, err := svc.CreateShift(ctx, event.ID, &shifts[i], "admin-1")
+		if err != nil {
+			t.Fatalf("创建班次失败: %v", err)
+		}
+		shiftIDs = append(shiftIDs, shift.ID)
+	}
+	return event.ID, shiftIDs
+}
+
+func eventRecordCount(repos *testScheduleRepos, shiftID string) int {
+	count := 0
+	for _, r := range repos.dutyRecord.records {
+		if r.EventShiftID != nil && *r.EventShiftID == shiftID {
+			count++
+		}
+	}
+	return count
+}
+
+// ── 班次校验 ──
+
+func TestEventService_CreateShiftValidation(t *testing.T) {
+	_, svc := setupEventTest(t)
+	eventID, _ := createTestEvent(t, svc, false)
+	ctx := context.Background()
+
+	_, err := svc.CreateShift(ctx, eventID, &dto.CreateEventShiftRequest{ShiftDate: "2099-09-07", StartTime: "20:00", EndTime: "18:00", Headcount: 1}, "admin-1")
+	if !errors.Is(err, ErrEventShiftInvalid) {
+		t.Errorf("结束时间早于开始时间应返回 ErrEventShiftInvalid，实际: %v", err)
+	}
+	_, err = svc.CreateShift(ctx, eventID, &dto.CreateEventShiftRequest{ShiftDate: "2099-12-01", StartTime: "18:00", EndTime: "20:00", Headcount: 1}, "admin-1")
+	if !errors.Is(err, ErrEventShiftOutOfSemester) {
+		t.Errorf("学期外日期应返回 ErrEventShiftOutOfSemester，实际: %v", err)
+	}
+}
+
+// ── 发布与取消 ──
+
+func TestEventService_PublishAndCancel(t *testing.T) {
+	repos, svc := setupEventTest(t)
+	eventID, shiftIDs := createTestEvent(t, svc, false,
+		dto.CreateEventShiftRequest{ShiftDate: "2099-09-07", StartTime: "18:00", EndTime: "20:00", Headcount: 2})
+	ctx := context.Background()
+
+	if err := svc.AssignMember(ctx, shiftIDs[0], "user-2", "admin-1"); err != nil {
+		t.Fatalf("草稿阶段指派失败: %v", err)
+	}
+	if n := eventRecordCount(repos, shiftIDs[0]); n != 0 {
+		t.Fatalf("草稿活动不应生成值班记录，实际 %d 条", n)
+	}
+
+	if _, err := svc.PublishEvent(ctx, eventID, "admin-1"); err != nil {
+		t.Fatalf("发布失败: %v", err)
+	}
+	if n := eventRecordCount(repos, shiftIDs[0]); n != 1 {
+		t.Fatalf("发布后应生成 1 条值班记录，实际 %d 条", n)
+	}
+	if len(repos.notification.notifications) != 1 || repos.notification.notifications[0].Type != model.NotificationTypeEventAssigned {
+		t.Fatalf("发布后应通知被安排成员，实际: %+v", repos.notification.notifications)
+	}
+	if _, err := svc.PublishEvent(ctx, eventID, "admin-1"); !errors.Is(err, ErrEventNotDraft) {
+		t.Errorf("重复发布应返回 ErrEventNotDraft，实际: %v", err)
+	}
+	_, err := svc.UpdateShift(ctx, shiftIDs[0], &dto.UpdateEventShiftRequest{StartTime: strPtr("19:00")}, "admin-1")
+	if !errors.Is(err, ErrEventShiftLocked) {
+		t.Errorf("发布后修改时间应返回 ErrEventShiftLocked，实际: %v", err)
+	}
+
+	if _, err := svc.CancelEvent(ctx, eventID, "admin-1"); err != nil {
+		t.Fatalf("取消失败: %v", err)
+	}
+	if n := eventRecordCount(repos, shiftIDs[0]); n != 0 {
+		t.Errorf("取消后待值班记录应作废，实际剩余 %d 条", n)
+	}
+	last := repos.notification.notifications[len(repos.notification.notifications)-1]
+	if last.Type != model.NotificationTypeEventCancelled || last.UserID != "user-2" {
+		t.Errorf("取消后应通知班次人员，实际: %+v", last)
+	}
+}
+
+func TestEventService_PublishWithoutShifts(t *testing.T) {
+	_, svc := setupEventTest(t)
+	eventID, _ := createTestEvent(t, svc, false)
+
+	if _, err := svc.PublishEvent(context.Background(), eventID, "admin-1"); !errors.Is(err, ErrEventNoShifts) {
+		t.Errorf("无班次发布应返回 ErrEventNoShifts，实际: %v", err)
+	}
+}
+
+// ── 报名 ──
+
+func TestEventService_SignUpAvailability(t *testing.T) {
+	repos, svc := setupEventTest(t)
+	repos.courseSchedule.courses = append(repos.courseSchedule.courses, model.CourseSchedule{
+		UserID: "user-2", SemesterID: "sem-evt", CourseName: "高等数学",
+		DayOfWeek: 1, StartTime: "08:00", EndTime: "09:40", WeekType: model.WeekTypeAll,
+	})
+	repos.user.users["user-4"] = &model.User{UserID: "user-4", Name: "user-4", Role: model.RoleMember}
+	eventID, shiftIDs := createTestEvent(t, svc, true,
+		dto.CreateEventShiftRequest{ShiftDate: "2099-09-07", StartTime: "08:30", EndTime: "09:30", Headcount: 1})
+	ctx := context.Background()
+
+	if err := svc.SignUp(ctx, shiftIDs[0], "user-3"); !errors.Is(err, ErrEventSignupClosed) {
+		t.Errorf("草稿活动报名应返回 ErrEventSignupClosed，实际: %v", err)
+	}
+	if _, err := svc.PublishEvent(ctx, eventID, "admin-1"); err != nil {
+		t.Fatalf("发布失败: %v", err)
+	}
+
+	if err := svc.SignUp(ctx, shiftIDs[0], "user-1"); !errors.Is(err, ErrEventMemberUnavailable) {
+		t.Errorf("与周常值班冲突应返回 ErrEventMemberUnavailable，实际: %v", err)
+	}
+	if err := svc.SignUp(ctx, shiftIDs[0], "user-2"); !errors.Is(err, ErrEventMemberUnavailable) {
+		t.Errorf("与课程冲突应返回 ErrEventMemberUnavailable，实际: %v", err)
+	}
+	if err := svc.SignUp(ctx, shiftIDs[0], "user-3"); err != nil {
+		t.Fatalf("无冲突成员报名失败: %v", err)
+	}
+	if n := eventRecordCount(repos, shiftIDs[0]); n != 1 {
+		t.Errorf("已发布活动报名应生成值班记录，实际 %d 条", n)
+	}
+	if err := svc.SignUp(ctx, shiftIDs[0], "user-3"); !errors.Is(err, ErrEventAlreadyAssigned) {
+		t.Errorf("重复报名应返回 ErrEventAlreadyAssigned，实际: %v", err)
+	}
+	if err := svc.SignUp(ctx, shiftIDs[0], "user-4"); !errors.Is(err, ErrEventShiftFull) {
+		t.Errorf("满员后报名应返回 ErrEventShiftFull，实际: %v", err)
+	}
+
+	if err := svc.Withdraw(ctx, shiftIDs[0], "user-3"); err != nil {
+		t.Fatalf("退出失败: %v", err)
+	}
+	if n := eventRecordCount(repos, shiftIDs[0]); n != 0 {
+		t.Errorf("退出后值班记录应作废，实际 %d 条", n)
+	}
+}
+
+func TestEventService_SignUpOverlappingShifts(t *testing.T) {
+	_, svc := setupEventTest(t)
+	eventID, shiftIDs := createTestEvent(t, svc, true,
+		dto.CreateEventShiftRequest{ShiftDate: "2099-09-09", StartTime: "18:00", EndTime: "20:00", Headcount: 2},
+		dto.CreateEventShiftRequest{ShiftDate: "2099-09-09", StartTime: "19:00", EndTime: "21:00", Headcount: 2})
+	ctx := context.Background()
+	if _, err := svc.PublishEvent(ctx, eventID, "admin-1"); err != nil {
+		t.Fatalf("发布失败: %v", err)
+	}
+
+	if err := svc.SignUp(ctx, shiftIDs[0], "user-3"); err != nil {
+		t.Fatalf("报名失败: %v", err)
+	}
+	if err := svc.SignUp(ctx, shiftIDs[1], "user-3"); !errors.Is(err, ErrEventMemberUnavailable) {
+		t.Errorf("同日时段重叠的班次应返回 ErrEventMemberUnavailable，实际: %v", err)
+	}
+}
+
+// ── 自动分配 ──
+
+func TestEventService_AutoAssign(t *testing.T) {
+	repos, svc := setupEventTest(t)
+	day := mustDate(t, "2099-09-07")
+	repos.unavailable.times = append(repos.unavailable.times, model.UnavailableTime{
+		UnavailableTimeID: "ut-1", UserID: "user-2", SemesterID: "sem-evt", RepeatType: model.RepeatTypeOnce,
+		SpecificDate: &day, StartTime: "00:00", EndTime: "23:59",
+	})
+	eventID, shiftIDs := createTestEvent(t, svc, false,
+		dto.CreateEventShiftRequest{ShiftDate: "2099-09-07", StartTime: "08:30", EndTime: "09:30", Headcount: 3},
+		dto.CreateEventShiftRequest{ShiftDate: "2099-09-08", StartTime: "18:00", EndTime: "20:00", Headcount: 2})
+	ctx := context.Background()
+
+	result, err := svc.AutoAssign(ctx, eventID, "admin-1")
+	if err != nil {
+		t.Fatalf("自动分配失败: %v", err)
+	}
+	// 班次一：user-1 周常值班冲突、user-2 当天不可用，只能安排 user-3，缺 2 人
+	// 班次二：user-3 已有 1 个班次，优先安排负载更低的 user-1、user-2
+	if result.Assigned != 3 {
+		t.Errorf("应分配 3 人次，实际 %d", result.Assigned)
+	}
+	if len(result.Unfilled) != 1 || result.Unfilled[0].ShiftID != shiftIDs[0] || result.Unfilled[0].Missing != 2 {
+		t.Errorf("班次一应缺 2 人，实际: %+v", result.Unfilled)
+	}
+
+	detail, err := svc.GetEvent(ctx, eventID, true)
+	if err != nil {
+		t.Fatalf("查询活动失败: %v", err)
+	}
+	members := func(shift dto.EventShiftResponse) map[string]bool {
+		m := make(map[string]bool)
+		for _, a := range shift.Members {
+			m[a.Member.ID] = true
+		}
+		return m
+	}
+	if m := members(detail.Shifts[0]); len(m) != 1 || !m["user-3"] {
+		t.Errorf("班次一应只安排 user-3，实际: %v", m)
+	}
+	if m := members(detail.Shifts[1]); len(m) != 2 || !m["user-1"] || !m["user-2"] {
+		t.Errorf("班次二应安排 user-1、user-2，实际: %v", m)
+	}
+	if len(repos.dutyRecord.records) != 0 {
+		t.Errorf("草稿活动自动分配不应生成值班记录，实际 %d 条", len(repos.dutyRecord.records))
+	}
+}
@@ -6,11 +6,14 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/xuri/excelize/v2"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/model"
 	"echo-union/backend/internal/repository"
 )
 
@@ -20,28 +23,37 @@ var (
 	ErrExportNoSchedule   = errors.New("该学期暂无排班表")
 	ErrExportNoItems      = errors.New("排班表中无排班项")
 	ErrExportGenerateFail = errors.New("生成 Excel 文件失败")
+	ErrExportNoShifts     = errors.New("活动中无班次")
 )
 
 // ExportService 导出业务接口
 //
 // 设计说明：
-//   - 一期仅实现排班表导出为 Excel (.xlsx)
-//   - 签到统计导出依赖签到模块，归入二期
+//   - 排班表、活动值班安排与出勤统计导出为 Excel (.xlsx)
 //   - 导出以 bytes.Buffer 返回，由 Handler 层设置 HTTP 响应头后写入 Response
 //   - Excel 格式：按周次分 Sheet，每个 Sheet 按 day_of_week 列 × time_slot 行呈现
 type ExportService interface {
 	// ExportSchedule 导出排班表为 Excel
 	ExportSchedule(ctx context.Context, semesterID string) (*bytes.Buffer, string, error)
+	// ExportEvent 导出活动值班安排为 Excel
+	ExportEvent(ctx context.Context, eventID string) (*bytes.Buffer, string, error)
+	// ExportAttendance 导出出勤统计为 Excel（leader 仅本部门）
+	ExportAttendance(ctx context.Context, req *dto.AttendanceStatsRequest, callerRole, callerDeptID string) (*bytes.Buffer, string, error)
 }
 
 type exportService struct {
 	repo   *repository.Repository
 	logger *zap.Logger
+	stats  *statisticsService // 复用出勤统计
 }
 
 // NewExportService 创建 ExportService 实例
 func NewExportService(repo *repository.Repository, logger *zap.Logger) ExportService {
-	return &exportService{repo: repo, logger: logger}
+	return &exportService{
+		repo:   repo,
+		logger: logger,
+		stats:  &statisticsService{repo: repo, logger: logger},
+	}
 }
 
 // ═══════════════════════════════════════════════════════════
@@ -51,8 +63,9 @@ func NewExportService(repo *repository.Repository, logger *zap.Logger) ExportSer
 // 输出格式：
 //   - Sheet "第1周" / "第2周"（按 week_number 分）
 //   - 行头：时间段名称（按 day_of_week + start_time 排序）
+//   - 每行附时间段所需技能（无要求时为 "-"）
 //   - 列头：周一 ~ 周五
-//   - 单元格：成员姓名 (部门名)
+//   - 单元格：成员姓名 (部门名) @地点
 //
 // 返回值：buf（Excel 内容）, filename（建议文件名）, error
 
@@ -90,6 +103,7 @@ func (s *exportService) ExportSchedule(ctx context.Context, semesterID string) (
 		name      string
 		startTime string
 		endTime   string
+		skills    string
 	}
 
 	itemIndex := make(map[string]string) // "wn:dow:name:start" → cellText
@@ -110,6 +124,9 @@ func (s *exportService) ExportSchedule(ctx context.Context, semesterID string) (
 				cellText += " (" + item.Member.Department.Name + ")"
 			}
 		}
+		if item.Location != nil {
+			cellText += " @" + item.Location.Name
+		}
 
 		key := fmt.Sprintf("%d:%d:%s:%s", item.WeekNumber, ts.DayOfWeek, ts.Name, ts.StartTime)
 		itemIndex[key] = cellText
@@ -123,6 +140,7 @@ func (s *exportService) ExportSchedule(ctx context.Context, semesterID string) (
 				name:      ts.Name,
 				startTime: ts.StartTime,
 				endTime:   ts.EndTime,
+				skills:    strings.Join(skillNames(timeSlotSkills(ts)), "、"),
 			})
 		}
 	}
@@ -157,6 +175,7 @@ func (s *exportService) ExportSchedule(ctx context.Context, semesterID string) (
 		slotName  string
 		startTime string
 		endTime   string
+		skills    string
 	}
 	var rows []rowDef
 	for _, dow := range dayOrder {
@@ -166,12 +185,13 @@ func (s *exportService) ExportSchedule(ctx context.Context, semesterID string) (
 				slotName:  sl.name,
 				startTime: sl.startTime,
 				endTime:   sl.endTime,
+				skills:    sl.skills,
 			})
 		}
 	}
 
 	// 重新构建：以 (星期, 时间段) 为行，week_number 为列
-	// 表头: | 星期 | 时间段 | 时间 | 第1周 | 第2周 |
+	// 表头: | 星期 | 时间段 | 时间 | 所需技能 | 第1周 | 第2周 |
 	dayNames := map[int]string{1: "周一", 2: "周二", 3: "周三", 4: "周四", 5: "周五"}
 
 	// 找出所有 weekNumbers
@@ -202,8 +222,9 @@ func (s *exportService) ExportSchedule(ctx context.Context, semesterID string) (
 	f.SetColWidth(sheetName, "A", "A", 8)
 	f.SetColWidth(sheetName, "B", "B", 14)
 	f.SetColWidth(sheetName, "C", "C", 18)
+	f.SetColWidth(sheetName, "D", "D", 18)
 	for i := range weekNumbers {
-		col, _ := excelize.ColumnNumberToName(4 + i)
+		col, _ := excelize.ColumnNumberToName(5 + i)
 		f.SetColWidth(sheetName, col, col, 22)
 	}
 
@@ -225,8 +246,9 @@ func (s *exportService) ExportSchedule(ctx context.Context, semesterID string) (
 	f.SetCellValue(sheetName, cell("A", row), "星期")
 	f.SetCellValue(sheetName, cell("B", row), "时间段")
 	f.SetCellValue(sheetName, cell("C", row), "时间")
+	f.SetCellValue(sheetName, cell("D", row), "所需技能")
 	for i, wn := range weekNumbers {
-		f.SetCellValue(sheetName, cell(colName(3+i), row), fmt.Sprintf("第%d周", wn))
+		f.SetCellValue(sheetName, cell(colName(4+i), row), fmt.Sprintf("第%d周", wn))
 	}
 
 	// 数据行
@@ -235,13 +257,18 @@ func (s *exportService) ExportSchedule(ctx context.Context, semesterID string) (
 		f.SetCellValue(sheetName, cell("A", row), dayNames[rd.dayOfWeek])
 		f.SetCellValue(sheetName, cell("B", row), rd.slotName)
 		f.SetCellValue(sheetName, cell("C", row), fmt.Sprintf("%s-%s", rd.startTime, rd.endTime))
+		skills := rd.skills
+		if skills == "" {
+			skills = "-"
+		}
+		f.SetCellValue(sheetName, cell("D", row), skills)
 
 		for i, wn := range weekNumbers {
 			key := fmt.Sprintf("%d:%d:%s:%s", wn, rd.dayOfWeek, rd.slotName, rd.startTime)
 			if text, ok := itemIndex[key]; ok {
-				f.SetCellValue(sheetName, cell(colName(3+i), row), text)
+				f.SetCellValue(sheetName, cell(colName(4+i), row), text)
 			} else {
-				f.SetCellValue(sheetName, cell(colName(3+i), row), "-")
+				f.SetCellValue(sheetName, cell(colName(4+i), row), "-")
 			}
 		}
 		row++
@@ -258,8 +285,206 @@ func (s *exportService) ExportSchedule(ctx context.Context, semesterID string) (
 	return buf, filename, nil
 }
 
+// ═══════════════════════════════════════════════════════════
+// ExportEvent — 导出活动值班安排为 Excel
+// ═══════════════════════════════════════════════════════════
+//
+// 输出格式：单个 Sheet，每个班次一行
+//   | 日期 | 时间 | 地点 | 所需技能 | 人数 | 人员 | 备注 |
+//   人员单元格：成员姓名 (部门名)，多人以顿号分隔；未满员时人数列显示 已安排/需求
+
+func (s *exportService) ExportEvent(ctx context.Context, eventID string) (*bytes.Buffer, string, error) {
+	event, err := s.repo.Event.GetByID(ctx, eventID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", ErrEventNotFound
+		}
+		s.logger.Error("查询活动失败", zap.Error(err))
+		return nil, "", err
+	}
+	shifts, err := s.repo.EventShift.ListByEvent(ctx, eventID)
+	if err != nil {
+		s.logger.Error("查询活动班次失败", zap.Error(err))
+		return nil, "", err
+	}
+	if len(shifts) == 0 {
+		return nil, "", ErrExportNoShifts
+	}
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	sheetName := "活动值班"
+	idx, _ := f.NewSheet(sheetName)
+	f.SetActiveSheet(idx)
+	f.DeleteSheet("Sheet1")
+
+	f.SetColWidth(sheetName, "A", "A", 12)
+	f.SetColWidth(sheetName, "B", "B", 14)
+	f.SetColWidth(sheetName, "C", "C", 16)
+	f.SetColWidth(sheetName, "D", "D", 18)
+	f.SetColWidth(sheetName, "E", "E", 8)
+	f.SetColWidth(sheetName, "F", "F", 48)
+	f.SetColWidth(sheetName, "G", "G", 24)
+
+	headerStyle, _ := f.NewStyle(&excelize.Style{
+		Font:      &excelize.Font{Bold: true, Size: 11},
+		Fill:      excelize.Fill{Type: "pattern", Color: []string{"#4472C4"}, Pattern: 1},
+		Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center"},
+	})
+
+	title := event.Name
+	if event.Status == model.EventStatusCancelled {
+		title += "（已取消）"
+	}
+	f.SetCellValue(sheetName, "A1", fmt.Sprintf("%s — 活动值班表", title))
+	f.MergeCell(sheetName, "A1", "G1")
+	f.SetCellStyle(sheetName, "A1", "A1", headerStyle)
+
+	row := 2
+	for i, h := range []string{"日期", "时间", "地点", "所需技能", "人数", "人员", "备注"} {
+		f.SetCellValue(sheetName, cell(colName(i), row), h)
+	}
+
+	row = 3
+	for _, shift := range shifts {
+		names := make([]string, 0, len(shift.Assignments))
+		for _, a := range shift.Assignments {
+			name := a.MemberID
+			if a.Member != nil {
+				name = a.Member.Name
+				if a.Member.Department != nil {
+					name += " (" + a.Member.Department.Name + ")"
+				}
+			}
+			names = append(names, name)
+		}
+		location := "-"
+		if shift.Location != nil {
+			location = shift.Location.Name
+		}
+		skills := "-"
+		if required := eventShiftSkills(&shift); len(required) > 0 {
+			skills = strings.Join(skillNames(required), "、")
+		}
+		headcount := fmt.Sprintf("%d", shift.Headcount)
+		if len(names) < shift.Headcount {
+			headcount = fmt.Sprintf("%d/%d", len(names), shift.Headcount)
+		}
+
+		f.SetCellValue(sheetName, cell("A", row), shift.ShiftDate.Format(model.TimeFormatDate))
+		f.SetCellValue(sheetName, cell("B", row), fmt.Sprintf("%s-%s", shift.StartTime, shift.EndTime))
+		f.SetCellValue(sheetName, cell("C", row), location)
+		f.SetCellValue(sheetName, cell("D", row), skills)
+		f.SetCellValue(sheetName, cell("E", row), headcount)
+		f.SetCellValue(sheetName, cell("F", row), strings.Join(names, "、"))
+		f.SetCellValue(sheetName, cell("G", row), shift.Note)
+		row++
+	}
+
+	buf := new(bytes.Buffer)
+	if err := f.Write(buf); err != nil {
+		s.logger.Error("写入 Excel 失败", zap.Error(err))
+		return nil, "", ErrExportGenerateFail
+	}
+
+	filename := fmt.Sprintf("活动值班_%s.xlsx", event.Name)
+	return buf, filename, nil
+}
+
+// ═══════════════════════════════════════════════════════════
+// ExportAttendance — 导出出勤统计为 Excel
+// ═══════════════════════════════════════════════════════════
+//
+// 输出格式（口径同 StatisticsService.Attendance）：
+//   - Sheet "成员出勤"：每位成员一行
+//     | 部门 | 学号 | 姓名 | 应值班 | 已完成 | 迟到 | 缺勤 | 已补班 | 未签退 | 未签到/值班中 | 替班 | 时长(小时) |
+//   - Sheet "部门汇总"：每个部门一行（成员数 + 同上计数），末行为合计
+
+func (s *exportService) ExportAttendance(ctx context.Context, req *dto.AttendanceStatsRequest, callerRole, callerDeptID string) (*bytes.Buffer, string, error) {
+	stats, err := s.stats.Attendance(ctx, req, callerRole, callerDeptID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	headerStyle, _ := f.NewStyle(&excelize.Style{
+		Font:      &excelize.Font{Bold: true, Size: 11},
+		Fill:      excelize.Fill{Type: "pattern", Color: []string{"#4472C4"}, Pattern: 1},
+		Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center"},
+	})
+	countHeaders := []string{"应值班", "已完成", "迟到", "缺勤", "已补班", "未签退", "未签到/值班中", "替班", "时长(小时)"}
+	period := fmt.Sprintf("%s ~ %s", stats.From, stats.To)
+
+	// 成员出勤
+	memberSheet := "成员出勤"
+	idx, _ := f.NewSheet(memberSheet)
+	f.SetActiveSheet(idx)
+	f.DeleteSheet("Sheet1")
+
+	headers := append([]string{"部门", "学号", "姓名"}, countHeaders...)
+	f.SetColWidth(memberSheet, "A", "C", 14)
+	f.SetColWidth(memberSheet, "D", colName(len(headers)-1), 12)
+	f.SetCellValue(memberSheet, "A1", fmt.Sprintf("出勤统计（%s）", period))
+	f.MergeCell(memberSheet, "A1", cell(colName(len(headers)-1), 1))
+	f.SetCellStyle(memberSheet, "A1", "A1", headerStyle)
+	for i, h := range headers {
+		f.SetCellValue(memberSheet, cell(colName(i), 2), h)
+	}
+	row := 3
+	for _, m := range stats.Members {
+		f.SetCellValue(memberSheet, cell("A", row), memberDepartmentName(m.Member))
+		f.SetCellValue(memberSheet, cell("B", row), m.Member.StudentID)
+		f.SetCellValue(memberSheet, cell("C", row), m.Member.Name)
+		setAttendanceCells(f, memberSheet, row, 3, &m.AttendanceCounts)
+		row++
+	}
+
+	// 部门汇总
+	deptSheet := "部门汇总"
+	f.NewSheet(deptSheet)
+	headers = append([]string{"部门", "成员数"}, countHeaders...)
+	f.SetColWidth(deptSheet, "A", "A", 14)
+	f.SetColWidth(deptSheet, "B", colName(len(headers)-1), 12)
+	f.SetCellValue(deptSheet, "A1", fmt.Sprintf("部门出勤汇总（%s）", period))
+	f.MergeCell(deptSheet, "A1", cell(colName(len(headers)-1), 1))
+	f.SetCellStyle(deptSheet, "A1", "A1", headerStyle)
+	for i, h := range headers {
+		f.SetCellValue(deptSheet, cell(colName(i), 2), h)
+	}
+	row = 3
+	for _, d := range stats.Departments {
+		f.SetCellValue(deptSheet, cell("A", row), d.Department.Name)
+		f.SetCellValue(deptSheet, cell("B", row), d.MemberCount)
+		setAttendanceCells(f, deptSheet, row, 2, &d.AttendanceCounts)
+		row++
+	}
+	f.SetCellValue(deptSheet, cell("A", row), "合计")
+	f.SetCellValue(deptSheet, cell("B", row), len(stats.Members))
+	setAttendanceCells(f, deptSheet, row, 2, &stats.Total)
+
+	buf := new(bytes.Buffer)
+	if err := f.Write(buf); err != nil {
+		s.logger.Error("写入 Excel 失败", zap.Error(err))
+		return nil, "", ErrExportGenerateFail
+	}
+
+	filename := fmt.Sprintf("出勤统计_%s_%s.xlsx", stats.From, stats.To)
+	return buf, filename, nil
+}
+
 // ── 辅助函数 ──
 
+// setAttendanceCells 自第 startCol 列（0 起）写入出勤计数
+func setAttendanceCells(f *excelize.File, sheet string, row, startCol int, c *dto.AttendanceCounts) {
+	values := []interface{}{c.Scheduled, c.Completed, c.Late, c.Absent, c.MadeUp, c.NoSignOut, c.Pending, c.Substitute, c.Hours}
+	for i, v := range values {
+		f.SetCellValue(sheet, cell(colName(startCol+i), row), v)
+	}
+}
+
 func colName(idx int) string {
 	name, _ := excelize.ColumnNumberToName(idx + 1)
 	return name
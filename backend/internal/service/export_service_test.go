@@ -3,10 +3,13 @@ package service
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 
+	"github.com/xuri/excelize/v2"
 	"go.uber.org/zap"
 
+	"echo-union/backend/internal/dto"
 	"echo-union/backend/internal/model"
 	"echo-union/backend/internal/repository"
 )
@@ -173,3 +176,37 @@ func TestExportService_ExportSchedule_MultipleWeeksAndSlots(t *testing.T) {
 		t.Error("文件名不应为空")
 	}
 }
+
+// ── ExportAttendance 测试 ──
+
+func TestExportService_ExportAttendance(t *testing.T) {
+	repos, _ := setupAttendanceTest(t)
+	svc := NewExportService(repos.toRepository(), zap.NewNop())
+
+	buf, filename, err := svc.ExportAttendance(context.Background(), &dto.AttendanceStatsRequest{}, model.RoleAdmin, "")
+	if err != nil {
+		t.Fatalf("ExportAttendance 应成功: %v", err)
+	}
+	if !strings.HasPrefix(filename, "出勤统计_") {
+		t.Errorf("文件名不符: %s", filename)
+	}
+
+	f, err := excelize.OpenReader(buf)
+	if err != nil {
+		t.Fatalf("输出内容不是有效的 xlsx: %v", err)
+	}
+	defer f.Close()
+	if sheets := f.GetSheetList(); len(sheets) != 2 || sheets[0] != "成员出勤" || sheets[1] != "部门汇总" {
+		t.Fatalf("应有成员出勤、部门汇总两个 Sheet，实际 %v", sheets)
+	}
+	if name, _ := f.GetCellValue("成员出勤", "C3"); name != "张三" {
+		t.Errorf("成员出勤首行应为张三，实际 %q", name)
+	}
+	if total, _ := f.GetCellValue("部门汇总", "C5"); total != "7" {
+		t.Errorf("部门汇总合计行应有 7 次应值班，实际 %q", total)
+	}
+
+	if _, _, err := svc.ExportAttendance(context.Background(), &dto.AttendanceStatsRequest{To: "2026-01-01"}, model.RoleAdmin, ""); !errors.Is(err, ErrStatsRangeInvalid) {
+		t.Errorf("期望 ErrStatsRangeInvalid，实际: %v", err)
+	}
+}
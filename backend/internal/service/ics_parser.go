@@ -412,3 +412,66 @@ func parseICSDateTime(evt *ics.VEvent, propName ics.ComponentProperty, loc *time
 
 	return time.Time{}, fmt.Errorf("无法解析日期: %s", val)
 }
+
+// ── 节假日 ICS ──
+
+// parsedCalendarDay 节假日 ICS 解析结果（单日）
+type parsedCalendarDay struct {
+	Date time.Time // UTC 零点
+	Kind string    // holiday | workday
+	Name string
+}
+
+// ParseHolidayICS 解析节假日日历（如系统/第三方发布的法定节假日订阅）
+//
+// 约定：
+//   - 每个 VEVENT 按 [DTSTART, DTEND) 展开为逐日条目，缺少 DTEND 时视为单日
+//   - SUMMARY 含"补班"/"上班"或以"班"结尾的事件为调休上班日，其余为放假日
+//   - 同一日期出现多次时以先出现者为准
+func ParseHolidayICS(reader io.Reader) ([]parsedCalendarDay, error) {
+	cal, err := ics.ParseCalendar(reader)
+	if err != nil {
+		return nil, fmt.Errorf("ICS 格式解析失败: %w", err)
+	}
+
+	loc, _ := time.LoadLocation(shanghaiTimezone)
+	seen := make(map[string]bool)
+	var result []parsedCalendarDay
+
+	for _, evt := range cal.Events() {
+		summary := evt.GetProperty(ics.ComponentPropertySummary)
+		if summary == nil || strings.TrimSpace(summary.Value) == "" {
+			continue
+		}
+		name := strings.TrimSpace(summary.Value)
+
+		dtStart, err := parseICSDateTime(evt, ics.ComponentPropertyDtStart, loc)
+		if err != nil {
+			continue
+		}
+		start := dateOnly(dtStart)
+		end := start.AddDate(0, 0, 1)
+		if dtEnd, err := parseICSDateTime(evt, ics.ComponentPropertyDtEnd, loc); err == nil {
+			if e := dateOnly(dtEnd); e.After(start) {
+				end = e
+			}
+		}
+
+		kind := model.CalendarDayHoliday
+		if strings.Contains(name, "补班") || strings.Contains(name, "上班") || strings.HasSuffix(name, "班") {
+			kind = model.CalendarDayWorkday
+		}
+
+		for d := start; d.Before(end); d = d.AddDate(0, 0, 1) {
+			key := d.Format(model.TimeFormatDate)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			result = append(result, parsedCalendarDay{Date: d, Kind: kind, Name: name})
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Date.Before(result[j].Date) })
+	return result, nil
+}
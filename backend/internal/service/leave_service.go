@@ -0,0 +1,604 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/model"
+	"echo-union/backend/internal/repository"
+)
+
+// ── 请假模块业务错误 ──
+
+var (
+	ErrLeaveNotFound              = errors.New("请假申请不存在")
+	ErrLeaveDutyRecordNotFound    = errors.New("值班记录不存在")
+	ErrLeaveNotOwner              = errors.New("只能为本人的值班请假")
+	ErrLeaveDutyNotEligible       = errors.New("该次值班不可请假")
+	ErrLeaveDutyStarted           = errors.New("值班已开始，无法请假")
+	ErrLeaveAlreadyPending        = errors.New("该次值班已有待审批的请假")
+	ErrLeaveSwapOpen              = errors.New("该次值班已有进行中的换班申请")
+	ErrLeaveNotPending            = errors.New("请假不在待审批状态")
+	ErrLeaveForbidden             = errors.New("无权操作该请假申请")
+	ErrLeaveSubstituteRequired    = errors.New("审批通过须指定替班成员")
+	ErrLeaveSubstituteUnavailable = errors.New("替班成员在该次值班时段不可用")
+	ErrLeaveOutdated              = errors.New("值班安排已变更，请假已失效")
+)
+
+// LeaveService 请假业务接口
+//
+// 设计说明：
+//   - 成员为某一具体日期的本人值班请假（原因、可选证明材料引用），无需自行寻找接班人
+//   - 请假成员所在部门的负责人（leader）或管理员审批；审批时从候选人中指定替班成员，
+//     候选人与换班使用同一套校验（memberConflicts，按该次值班的具体日期），按当前周常负荷排序
+//   - 通过后在同一事务内改派该次值班记录，并写入 change_type = leave 的单次变更日志
+//   - 待审批的请假与进行中的换班互斥；请假次数按学期统计
+type LeaveService interface {
+	Create(ctx context.Context, req *dto.CreateLeaveRequest, callerID string) (*dto.LeaveResponse, error)
+	GetByID(ctx context.Context, id, callerID, callerRole, callerDeptID string) (*dto.LeaveResponse, error)
+	ListMine(ctx context.Context, req *dto.LeaveListRequest, callerID string) ([]dto.LeaveResponse, int64, error)
+	// ListForReview 审批人可见的请假：管理员为全部，负责人为本部门成员
+	ListForReview(ctx context.Context, req *dto.LeaveListRequest, callerRole, callerDeptID string) ([]dto.LeaveResponse, int64, error)
+	Candidates(ctx context.Context, id, callerRole, callerDeptID string) ([]dto.LeaveCandidateResponse, error)
+	Review(ctx context.Context, id string, req *dto.ReviewLeaveRequest, callerID, callerRole, callerDeptID string) (*dto.LeaveResponse, error)
+	Cancel(ctx context.Context, id, callerID string) error
+	Stats(ctx context.Context, req *dto.LeaveStatsRequest, callerRole, callerDeptID string) ([]dto.LeaveStatsResponse, error)
+}
+
+type leaveService struct {
+	repo   *repository.Repository
+	logger *zap.Logger
+	swap   *swapService // 复用换班的排班上下文与冲突校验
+}
+
+// NewLeaveService 创建 LeaveService 实例
+func NewLeaveService(repo *repository.Repository, logger *zap.Logger) LeaveService {
+	return &leaveService{
+		repo:   repo,
+		logger: logger,
+		swap: &swapService{
+			repo:     repo,
+			logger:   logger,
+			schedule: &scheduleService{repo: repo, logger: logger},
+		},
+	}
+}
+
+// ════════════════════════════════════════════════════════════
+// Create — 成员请假
+// ════════════════════════════════════════════════════════════
+
+func (s *leaveService) Create(ctx context.Context, req *dto.CreateLeaveRequest, callerID string) (*dto.LeaveResponse, error) {
+	record, err := s.repo.DutyRecord.GetByID(ctx, req.DutyRecordID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrLeaveDutyRecordNotFound
+		}
+		s.logger.Error("查询值班记录失败", zap.Error(err))
+		return nil, err
+	}
+	if record.MemberID != callerID {
+		return nil, ErrLeaveNotOwner
+	}
+	// 活动班次按报名 / 退出处理，不走请假
+	if record.ScheduleItemID == nil || record.ScheduleItem == nil || record.Status != model.DutyRecordStatusPending {
+		return nil, ErrLeaveDutyNotEligible
+	}
+	if !time.Now().Before(dutyStartTime(record)) {
+		return nil, ErrLeaveDutyStarted
+	}
+
+	pending, err := s.repo.LeaveRequest.CountPendingByDutyRecord(ctx, record.DutyRecordID)
+	if err != nil {
+		s.logger.Error("查询待审批的请假失败", zap.Error(err))
+		return nil, err
+	}
+	if pending > 0 {
+		return nil, ErrLeaveAlreadyPending
+	}
+	open, err := s.repo.SwapRequest.CountOpenByDutyRecord(ctx, record.DutyRecordID)
+	if err != nil {
+		s.logger.Error("查询进行中的换班申请失败", zap.Error(err))
+		return nil, err
+	}
+	if open > 0 {
+		return nil, ErrLeaveSwapOpen
+	}
+
+	schedule, err := s.repo.Schedule.GetByID(ctx, record.ScheduleItem.ScheduleID)
+	if err != nil {
+		s.logger.Error("查询排班表失败", zap.Error(err))
+		return nil, err
+	}
+
+	leave := &model.LeaveRequest{
+		DutyRecordID:  record.DutyRecordID,
+		MemberID:      callerID,
+		SemesterID:    schedule.SemesterID,
+		Reason:        req.Reason,
+		AttachmentRef: req.AttachmentRef,
+		Status:        model.LeaveStatusPending,
+	}
+	leave.CreatedBy = &callerID
+	leave.UpdatedBy = &callerID
+	if err := s.repo.LeaveRequest.Create(ctx, leave); err != nil {
+		s.logger.Error("创建请假失败", zap.Error(err))
+		return nil, err
+	}
+
+	s.logger.Info("请假已提交",
+		zap.String("leave_request_id", leave.LeaveRequestID),
+		zap.String("duty_record_id", record.DutyRecordID),
+		zap.String("member_id", callerID),
+	)
+
+	leave.DutyRecord = record
+	s.notifyCreated(ctx, leave)
+	return s.getResponse(ctx, leave.LeaveRequestID)
+}
+
+// ════════════════════════════════════════════════════════════
+// Candidates — 替班候选人
+// ════════════════════════════════════════════════════════════
+
+// Candidates 可接手该次值班的成员（冲突校验同换班），按当前周常负荷从低到高排序
+func (s *leaveService) Candidates(ctx context.Context, id, callerRole, callerDeptID string) ([]dto.LeaveCandidateResponse, error) {
+	leave, err := s.getLeave(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !canReviewLeave(leave, callerRole, callerDeptID) {
+		return nil, ErrLeaveForbidden
+	}
+	if leave.Status != model.LeaveStatusPending {
+		return nil, ErrLeaveNotPending
+	}
+	record := leave.DutyRecord
+	if record == nil || record.ScheduleItem == nil || record.ScheduleItem.TimeSlot == nil {
+		return nil, ErrLeaveDutyNotEligible
+	}
+
+	sc, err := s.swap.loadSwapContext(ctx, record.ScheduleItem.ScheduleID)
+	if err != nil {
+		return nil, err
+	}
+	assignments, err := s.repo.UserSemesterAssignment.ListDutyRequiredSubmitted(ctx, sc.schedule.SemesterID)
+	if err != nil {
+		s.logger.Error("查询值班成员失败", zap.Error(err))
+		return nil, err
+	}
+
+	loads := sc.memberLoads()
+	days := []calendarDay{sc.calendar.resolve(record.DutyDate)}
+	result := make([]dto.LeaveCandidateResponse, 0)
+	for _, a := range assignments {
+		if a.UserID == leave.MemberID {
+			continue
+		}
+		conflicts, err := s.swap.memberConflicts(ctx, sc, a.UserID, record.ScheduleItem, sc.allItems, days, true)
+		if err != nil {
+			return nil, err
+		}
+		if len(conflicts) > 0 {
+			continue
+		}
+		candidate := dto.LeaveCandidateResponse{Member: toMemberBrief(a.User)}
+		if load := loads[a.UserID]; load != nil {
+			candidate.ShiftCount = load.shifts
+			candidate.Load = round2(load.cost)
+		}
+		result = append(result, candidate)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Load != result[j].Load {
+			return result[i].Load < result[j].Load
+		}
+		return result[i].ShiftCount < result[j].ShiftCount
+	})
+	return result, nil
+}
+
+// ════════════════════════════════════════════════════════════
+// Review — 负责人 / 管理员审批
+// ════════════════════════════════════════════════════════════
+
+func (s *leaveService) Review(ctx context.Context, id string, req *dto.ReviewLeaveRequest, callerID, callerRole, callerDeptID string) (*dto.LeaveResponse, error) {
+	leave, err := s.getLeave(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !canReviewLeave(leave, callerRole, callerDeptID) {
+		return nil, ErrLeaveForbidden
+	}
+	if leave.Status != model.LeaveStatusPending {
+		return nil, ErrLeaveNotPending
+	}
+
+	if !req.Approve {
+		affected, err := s.repo.LeaveRequest.Review(ctx, id, model.LeaveStatusRejected, nil, req.Note, callerID)
+		if err != nil {
+			s.logger.Error("驳回请假失败", zap.Error(err))
+			return nil, err
+		}
+		if affected == 0 {
+			return nil, ErrLeaveNotPending
+		}
+		leave.Status = model.LeaveStatusRejected
+		leave.ReviewNote = req.Note
+		s.notifyReviewed(ctx, leave)
+		return s.getResponse(ctx, id)
+	}
+
+	if req.SubstituteID == "" {
+		return nil, ErrLeaveSubstituteRequired
+	}
+	record := leave.DutyRecord
+	if record == nil || record.ScheduleItem == nil || record.Status != model.DutyRecordStatusPending ||
+		record.MemberID != leave.MemberID || !time.Now().Before(dutyStartTime(record)) {
+		return nil, ErrLeaveOutdated
+	}
+	if req.SubstituteID == leave.MemberID {
+		return nil, fmt.Errorf("%w: 替班成员不能是请假成员本人", ErrLeaveSubstituteUnavailable)
+	}
+	conflicts, err := s.swap.claimConflicts(ctx, record, req.SubstituteID)
+	if err != nil {
+		return nil, err
+	}
+	if len(conflicts) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrLeaveSubstituteUnavailable, strings.Join(conflicts, "；"))
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		s.logger.Error("开启事务失败", zap.Error(err))
+		return nil, err
+	}
+	rollbackTx := func() {
+		if tx != nil {
+			tx.Rollback()
+		}
+	}
+	txRepo := s.repo.WithTx(tx)
+
+	substituteID := req.SubstituteID
+	affected, err := txRepo.LeaveRequest.Review(ctx, id, model.LeaveStatusApproved, &substituteID, req.Note, callerID)
+	if err != nil {
+		rollbackTx()
+		s.logger.Error("审批请假失败", zap.Error(err))
+		return nil, err
+	}
+	if affected == 0 {
+		rollbackTx()
+		return nil, ErrLeaveNotPending
+	}
+	affected, err = txRepo.DutyRecord.ReassignPending(ctx, record.DutyRecordID, leave.MemberID, substituteID, callerID)
+	if err != nil {
+		rollbackTx()
+		s.logger.Error("改派值班记录失败", zap.Error(err))
+		return nil, err
+	}
+	if affected == 0 {
+		rollbackTx()
+		return nil, ErrLeaveOutdated // 值班已开始或已被改派
+	}
+
+	dutyDate := record.DutyDate
+	changeLog := &model.ScheduleChangeLog{
+		ScheduleID:       record.ScheduleItem.ScheduleID,
+		ScheduleItemID:   *record.ScheduleItemID,
+		OriginalMemberID: leave.MemberID,
+		NewMemberID:      substituteID,
+		DutyDate:         &dutyDate,
+		ChangeType:       "leave",
+		Reason:           "请假：" + leave.Reason,
+		OperatorID:       callerID,
+		CreatedAt:        time.Now(),
+	}
+	if err := txRepo.ScheduleChangeLog.Create(ctx, changeLog); err != nil {
+		rollbackTx()
+		s.logger.Error("创建变更日志失败", zap.Error(err))
+		return nil, err
+	}
+
+	if tx != nil {
+		if err := tx.Commit().Error; err != nil {
+			s.logger.Error("提交事务失败", zap.Error(err))
+			return nil, err
+		}
+	}
+
+	s.logger.Info("请假已审批通过",
+		zap.String("leave_request_id", id),
+		zap.String("substitute_id", substituteID),
+		zap.String("reviewed_by", callerID),
+	)
+
+	leave.Status = model.LeaveStatusApproved
+	leave.SubstituteID = &substituteID
+	leave.ReviewNote = req.Note
+	s.notifyReviewed(ctx, leave)
+	return s.getResponse(ctx, id)
+}
+
+// ════════════════════════════════════════════════════════════
+// Cancel — 请假成员撤回
+// ════════════════════════════════════════════════════════════
+
+func (s *leaveService) Cancel(ctx context.Context, id, callerID string) error {
+	leave, err := s.getLeave(ctx, id)
+	if err != nil {
+		return err
+	}
+	if leave.MemberID != callerID {
+		return ErrLeaveForbidden
+	}
+	affected, err := s.repo.LeaveRequest.Cancel(ctx, id, callerID)
+	if err != nil {
+		s.logger.Error("撤回请假失败", zap.Error(err))
+		return err
+	}
+	if affected == 0 {
+		return ErrLeaveNotPending
+	}
+	return nil
+}
+
+// ════════════════════════════════════════════════════════════
+// 查询
+// ════════════════════════════════════════════════════════════
+
+func (s *leaveService) GetByID(ctx context.Context, id, callerID, callerRole, callerDeptID string) (*dto.LeaveResponse, error) {
+	leave, err := s.getLeave(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	isSubstitute := leave.SubstituteID != nil && *leave.SubstituteID == callerID
+	if leave.MemberID != callerID && !isSubstitute && !canReviewLeave(leave, callerRole, callerDeptID) {
+		return nil, ErrLeaveForbidden
+	}
+	resp := toLeaveResponse(leave)
+	return &resp, nil
+}
+
+func (s *leaveService) ListMine(ctx context.Context, req *dto.LeaveListRequest, callerID string) ([]dto.LeaveResponse, int64, error) {
+	return s.list(ctx, &repository.LeaveRequestFilters{
+		MemberID:   callerID,
+		Status:     req.Status,
+		SemesterID: req.SemesterID,
+	}, &req.PaginationRequest)
+}
+
+func (s *leaveService) ListForReview(ctx context.Context, req *dto.LeaveListRequest, callerRole, callerDeptID string) ([]dto.LeaveResponse, int64, error) {
+	filters := &repository.LeaveRequestFilters{Status: req.Status, SemesterID: req.SemesterID}
+	// leader 自动过滤为本部门
+	if callerRole == model.RoleLeader {
+		filters.DepartmentID = callerDeptID
+	}
+	return s.list(ctx, filters, &req.PaginationRequest)
+}
+
+func (s *leaveService) list(ctx context.Context, filters *repository.LeaveRequestFilters, page *dto.PaginationRequest) ([]dto.LeaveResponse, int64, error) {
+	leaves, total, err := s.repo.LeaveRequest.ListWithFilters(ctx, filters, page.GetOffset(), page.GetPageSize())
+	if err != nil {
+		s.logger.Error("查询请假失败", zap.Error(err))
+		return nil, 0, err
+	}
+	result := make([]dto.LeaveResponse, 0, len(leaves))
+	for i := range leaves {
+		result = append(result, toLeaveResponse(&leaves[i]))
+	}
+	return result, total, nil
+}
+
+// Stats 成员学期请假次数，按有效请假次数从多到少排序
+func (s *leaveService) Stats(ctx context.Context, req *dto.LeaveStatsRequest, callerRole, callerDeptID string) ([]dto.LeaveStatsResponse, error) {
+	deptID := ""
+	if callerRole == model.RoleLeader {
+		deptID = callerDeptID
+	}
+	counts, err := s.repo.LeaveRequest.CountByMember(ctx, req.SemesterID, deptID)
+	if err != nil {
+		s.logger.Error("统计请假次数失败", zap.Error(err))
+		return nil, err
+	}
+
+	stats := make(map[string]*dto.LeaveStatsResponse)
+	var memberIDs []string
+	for _, c := range counts {
+		st := stats[c.MemberID]
+		if st == nil {
+			st = &dto.LeaveStatsResponse{}
+			stats[c.MemberID] = st
+			memberIDs = append(memberIDs, c.MemberID)
+		}
+		switch c.Status {
+		case model.LeaveStatusPending:
+			st.Pending += c.Count
+		case model.LeaveStatusApproved:
+			st.Approved += c.Count
+		case model.LeaveStatusRejected:
+			st.Rejected += c.Count
+		case model.LeaveStatusCancelled:
+			st.Cancelled += c.Count
+		}
+		if c.Status != model.LeaveStatusCancelled {
+			st.Total += c.Count
+		}
+	}
+	if len(memberIDs) == 0 {
+		return []dto.LeaveStatsResponse{}, nil
+	}
+
+	users, err := s.repo.User.ListByIDs(ctx, memberIDs)
+	if err != nil {
+		s.logger.Error("查询成员失败", zap.Error(err))
+		return nil, err
+	}
+	for i := range users {
+		if st := stats[users[i].UserID]; st != nil {
+			st.Member = toMemberBrief(&users[i])
+		}
+	}
+
+	result := make([]dto.LeaveStatsResponse, 0, len(memberIDs))
+	for _, id := range memberIDs {
+		st := stats[id]
+		if st.Member == nil {
+			st.Member = &dto.MemberBrief{ID: id}
+		}
+		result = append(result, *st)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Total != result[j].Total {
+			return result[i].Total > result[j].Total
+		}
+		return result[i].Member.StudentID < result[j].Member.StudentID
+	})
+	return result, nil
+}
+
+// ── 内部辅助方法 ──
+
+func (s *leaveService) getLeave(ctx context.Context, id string) (*model.LeaveRequest, error) {
+	leave, err := s.repo.LeaveRequest.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrLeaveNotFound
+		}
+		s.logger.Error("查询请假失败", zap.Error(err))
+		return nil, err
+	}
+	return leave, nil
+}
+
+func (s *leaveService) getResponse(ctx context.Context, id string) (*dto.LeaveResponse, error) {
+	leave, err := s.getLeave(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	resp := toLeaveResponse(leave)
+	return &resp, nil
+}
+
+// canReviewLeave 管理员可审批全部请假，负责人可审批本部门成员的请假
+func canReviewLeave(leave *model.LeaveRequest, callerRole, callerDeptID string) bool {
+	switch callerRole {
+	case model.RoleAdmin:
+		return true
+	case model.RoleLeader:
+		return leave.Member != nil && leave.Member.DepartmentID == callerDeptID
+	}
+	return false
+}
+
+// leaveReviewerIDs 请假审批人：请假成员所在部门的负责人与全部管理员（不含本人）
+func (s *leaveService) leaveReviewerIDs(ctx context.Context, leave *model.LeaveRequest) ([]string, error) {
+	ids, err := adminUserIDs(ctx, s.repo)
+	if err != nil {
+		return nil, err
+	}
+	if member, err := s.repo.User.GetByID(ctx, leave.MemberID); err == nil {
+		leaders, _, err := s.repo.User.ListWithFilters(ctx,
+			&repository.UserListFilters{DepartmentID: member.DepartmentID, Role: model.RoleLeader}, 0, 100)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range leaders {
+			ids = append(ids, u.UserID)
+		}
+	}
+
+	result := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id != leave.MemberID && !containsString(result, id) {
+			result = append(result, id)
+		}
+	}
+	return result, nil
+}
+
+func (s *leaveService) sendLeaveNotifications(ctx context.Context, leaveID, notifType, title, content string, userIDs []string) {
+	if len(userIDs) == 0 {
+		return
+	}
+	relatedType := model.NotificationRelatedLeaveRequest
+	notifications := make([]model.Notification, 0, len(userIDs))
+	for _, userID := range userIDs {
+		relatedID := leaveID
+		notifications = append(notifications, model.Notification{
+			UserID:      userID,
+			Type:        notifType,
+			Title:       title,
+			Content:     content,
+			RelatedType: &relatedType,
+			RelatedID:   &relatedID,
+		})
+	}
+	if err := s.repo.Notification.BatchCreate(ctx, notifications); err != nil {
+		s.logger.Warn("发送请假通知失败", zap.Error(err))
+	}
+}
+
+// notifyCreated 通知审批人
+func (s *leaveService) notifyCreated(ctx context.Context, leave *model.LeaveRequest) {
+	reviewers, err := s.leaveReviewerIDs(ctx, leave)
+	if err != nil {
+		s.logger.Warn("查询请假审批人失败，审批通知未发送", zap.Error(err))
+		return
+	}
+	content := fmt.Sprintf("%s 为 %s 的值班请假（%s），请审批并安排替班",
+		s.swap.memberName(ctx, leave.MemberID), dutyDescription(leave.DutyRecord), leave.Reason)
+	s.sendLeaveNotifications(ctx, leave.LeaveRequestID, model.NotificationTypeLeaveRequest, "请假待审批", content, reviewers)
+}
+
+// notifyReviewed 通知请假成员审批结果；通过时同时通知替班成员
+func (s *leaveService) notifyReviewed(ctx context.Context, leave *model.LeaveRequest) {
+	desc := dutyDescription(leave.DutyRecord)
+	if leave.Status != model.LeaveStatusApproved {
+		content := fmt.Sprintf("你 %s 的请假未通过审批，请按时值班", desc)
+		if leave.ReviewNote != "" {
+			content += "（" + leave.ReviewNote + "）"
+		}
+		s.sendLeaveNotifications(ctx, leave.LeaveRequestID, model.NotificationTypeLeaveRejected, "请假审批驳回", content, []string{leave.MemberID})
+		return
+	}
+
+	substitute := s.swap.memberName(ctx, *leave.SubstituteID)
+	s.sendLeaveNotifications(ctx, leave.LeaveRequestID, model.NotificationTypeLeaveApproved, "请假审批通过",
+		fmt.Sprintf("你 %s 的请假已通过，由 %s 替班", desc, substitute), []string{leave.MemberID})
+	s.sendLeaveNotifications(ctx, leave.LeaveRequestID, model.NotificationTypeSubstituteAssign, "替班安排",
+		fmt.Sprintf("%s 请假，由你替班 %s 的值班", s.swap.memberName(ctx, leave.MemberID), desc), []string{*leave.SubstituteID})
+}
+
+// toLeaveResponse 转换请假响应（需预加载值班记录及其时间段、地点，请假成员与替班成员）
+func toLeaveResponse(l *model.LeaveRequest) dto.LeaveResponse {
+	resp := dto.LeaveResponse{
+		ID:            l.LeaveRequestID,
+		Status:        l.Status,
+		SemesterID:    l.SemesterID,
+		Member:        toMemberBrief(l.Member),
+		Substitute:    toMemberBrief(l.Substitute),
+		Reason:        l.Reason,
+		AttachmentRef: l.AttachmentRef,
+		ReviewNote:    l.ReviewNote,
+		CreatedAt:     l.CreatedAt.Format(model.TimeFormatDateTime),
+	}
+	if l.DutyRecord != nil {
+		record := toDutyRecordResponse(l.DutyRecord)
+		resp.DutyRecord = &record
+	}
+	if l.ReviewedAt != nil {
+		at := l.ReviewedAt.Format(model.TimeFormatDateTime)
+		resp.ReviewedAt = &at
+	}
+	return resp
+}
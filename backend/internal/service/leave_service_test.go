@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/model"
+)
+
+// setupLeaveTest 在换班测试数据基础上增加 dept-1 与 dept-2 的负责人
+func setupLeaveTest(t *testing.T, daysAhead int) (*testScheduleRepos, LeaveService, string) {
+	t.Helper()
+	repos, _, recordID := setupSwapTest(t, daysAhead)
+	repos.user.users["leader-1"] = &model.User{UserID: "leader-1", Name: "负责人甲", Role: model.RoleLeader, DepartmentID: "dept-1"}
+	repos.user.users["leader-2"] = &model.User{UserID: "leader-2", Name: "负责人乙", Role: model.RoleLeader, DepartmentID: "dept-2"}
+	return repos, NewLeaveService(repos.toRepository(), zap.NewNop()), recordID
+}
+
+func TestLeaveService_ApproveWithSubstitute(t *testing.T) {
+	repos, svc, recordID := setupLeaveTest(t, 7)
+	ctx := context.Background()
+
+	if _, err := svc.Create(ctx, &dto.CreateLeaveRequest{DutyRecordID: recordID, Reason: "生病"}, "user-2"); !errors.Is(err, ErrLeaveNotOwner) {
+		t.Errorf("为他人值班请假应返回 ErrLeaveNotOwner，实际 %v", err)
+	}
+
+	leave, err := svc.Create(ctx, &dto.CreateLeaveRequest{DutyRecordID: recordID, Reason: "生病", AttachmentRef: "files/sick-note.pdf"}, "user-1")
+	if err != nil {
+		t.Fatalf("Create 应成功: %v", err)
+	}
+	if leave.Status != model.LeaveStatusPending || leave.SemesterID != "sem-1" {
+		t.Errorf("新请假应为待审批且归属 sem-1，实际 status=%s semester=%s", leave.Status, leave.SemesterID)
+	}
+	if notificationsOf(repos, "leader-1", model.NotificationTypeLeaveRequest) != 1 ||
+		notificationsOf(repos, "admin-1", model.NotificationTypeLeaveRequest) != 1 ||
+		notificationsOf(repos, "leader-2", model.NotificationTypeLeaveRequest) != 0 {
+		t.Error("请假通知应发给本部门负责人与管理员")
+	}
+	if _, err := svc.Create(ctx, &dto.CreateLeaveRequest{DutyRecordID: recordID, Reason: "再次请假"}, "user-1"); !errors.Is(err, ErrLeaveAlreadyPending) {
+		t.Errorf("重复请假应返回 ErrLeaveAlreadyPending，实际 %v", err)
+	}
+
+	// 其他部门的负责人无权查看候选人或审批
+	if _, err := svc.Candidates(ctx, leave.ID, model.RoleLeader, "dept-2"); !errors.Is(err, ErrLeaveForbidden) {
+		t.Errorf("其他部门负责人应返回 ErrLeaveForbidden，实际 %v", err)
+	}
+
+	// user-2 周一下午已有值班（R6），只有 user-3 可替班
+	candidates, err := svc.Candidates(ctx, leave.ID, model.RoleLeader, "dept-1")
+	if err != nil {
+		t.Fatalf("Candidates 应成功: %v", err)
+	}
+	if len(candidates) != 1 || candidates[0].Member.ID != "user-3" {
+		t.Fatalf("候选人应只有 user-3，实际 %+v", candidates)
+	}
+
+	if _, err := svc.Review(ctx, leave.ID, &dto.ReviewLeaveRequest{Approve: true}, "leader-1", model.RoleLeader, "dept-1"); !errors.Is(err, ErrLeaveSubstituteRequired) {
+		t.Errorf("未指定替班成员应返回 ErrLeaveSubstituteRequired，实际 %v", err)
+	}
+	if _, err := svc.Review(ctx, leave.ID, &dto.ReviewLeaveRequest{Approve: true, SubstituteID: "user-2"}, "leader-1", model.RoleLeader, "dept-1"); !errors.Is(err, ErrLeaveSubstituteUnavailable) {
+		t.Errorf("冲突的替班成员应返回 ErrLeaveSubstituteUnavailable，实际 %v", err)
+	}
+
+	approved, err := svc.Review(ctx, leave.ID, &dto.ReviewLeaveRequest{Approve: true, SubstituteID: "user-3", Note: "同意"}, "leader-1", model.RoleLeader, "dept-1")
+	if err != nil {
+		t.Fatalf("Review 应成功: %v", err)
+	}
+	if approved.Status != model.LeaveStatusApproved || approved.Substitute == nil || approved.Substitute.ID != "user-3" {
+		t.Errorf("审批后应为 approved 且替班为 user-3，实际 %+v", approved)
+	}
+	if r := repos.dutyRecord.records[recordID]; r.MemberID != "user-3" {
+		t.Errorf("值班记录应改派给 user-3，实际 %s", r.MemberID)
+	}
+	logs := repos.changeLog.logs
+	if len(logs) != 1 || logs[0].ChangeType != "leave" || logs[0].DutyDate == nil || logs[0].NewMemberID != "user-3" {
+		t.Errorf("应写入一条 leave 类型的单次变更日志，实际 %+v", logs)
+	}
+	if notificationsOf(repos, "user-1", model.NotificationTypeLeaveApproved) != 1 ||
+		notificationsOf(repos, "user-3", model.NotificationTypeSubstituteAssign) != 1 {
+		t.Error("应通知请假成员与替班成员")
+	}
+
+	if _, err := svc.Review(ctx, leave.ID, &dto.ReviewLeaveRequest{Approve: false}, "admin-1", model.RoleAdmin, ""); !errors.Is(err, ErrLeaveNotPending) {
+		t.Errorf("重复审批应返回 ErrLeaveNotPending，实际 %v", err)
+	}
+}
+
+func TestLeaveService_RejectCancelAndStats(t *testing.T) {
+	repos, svc, recordID := setupLeaveTest(t, 7)
+	ctx := context.Background()
+
+	leave, err := svc.Create(ctx, &dto.CreateLeaveRequest{DutyRecordID: recordID, Reason: "考试"}, "user-1")
+	if err != nil {
+		t.Fatalf("Create 应成功: %v", err)
+	}
+	if _, err := svc.Review(ctx, leave.ID, &dto.ReviewLeaveRequest{Approve: false, Note: "请自行换班"}, "admin-1", model.RoleAdmin, ""); err != nil {
+		t.Fatalf("驳回应成功: %v", err)
+	}
+	if r := repos.dutyRecord.records[recordID]; r.MemberID != "user-1" {
+		t.Error("驳回不应改派值班记录")
+	}
+	if notificationsOf(repos, "user-1", model.NotificationTypeLeaveRejected) != 1 {
+		t.Error("驳回应通知请假成员")
+	}
+
+	again, err := svc.Create(ctx, &dto.CreateLeaveRequest{DutyRecordID: recordID, Reason: "考试"}, "user-1")
+	if err != nil {
+		t.Fatalf("驳回后可再次请假: %v", err)
+	}
+	if err := svc.Cancel(ctx, again.ID, "user-3"); !errors.Is(err, ErrLeaveForbidden) {
+		t.Errorf("他人撤回应返回 ErrLeaveForbidden，实际 %v", err)
+	}
+	if err := svc.Cancel(ctx, again.ID, "user-1"); err != nil {
+		t.Fatalf("撤回应成功: %v", err)
+	}
+
+	stats, err := svc.Stats(ctx, &dto.LeaveStatsRequest{SemesterID: "sem-1"}, model.RoleLeader, "dept-1")
+	if err != nil {
+		t.Fatalf("Stats 应成功: %v", err)
+	}
+	if len(stats) != 1 || stats[0].Member.ID != "user-1" || stats[0].Total != 1 || stats[0].Rejected != 1 || stats[0].Cancelled != 1 {
+		t.Errorf("user-1 应有 1 次有效请假（驳回）与 1 次撤回，实际 %+v", stats)
+	}
+	if stats, _ := svc.Stats(ctx, &dto.LeaveStatsRequest{SemesterID: "sem-1"}, model.RoleLeader, "dept-2"); len(stats) != 0 {
+		t.Errorf("dept-2 负责人不应看到 dept-1 成员的请假，实际 %+v", stats)
+	}
+}
+
+func TestLeaveService_BlocksSwapWhilePending(t *testing.T) {
+	repos, svc, recordID := setupLeaveTest(t, 7)
+	ctx := context.Background()
+
+	if _, err := svc.Create(ctx, &dto.CreateLeaveRequest{DutyRecordID: recordID, Reason: "临时有事"}, "user-1"); err != nil {
+		t.Fatalf("Create 应成功: %v", err)
+	}
+	swapSvc := NewSwapService(repos.toRepository(), zap.NewNop())
+	if _, err := swapSvc.CreateGiveAway(ctx, &dto.CreateGiveAwayRequest{DutyRecordID: recordID}, "user-1"); !errors.Is(err, ErrSwapLeavePending) {
+		t.Errorf("请假待审批时转让应返回 ErrSwapLeavePending，实际 %v", err)
+	}
+}
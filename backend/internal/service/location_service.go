@@ -3,6 +3,8 @@ package service
 import (
 	"context"
 	"errors"
+	"net/netip"
+	"strings"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -15,7 +17,9 @@ import (
 // ── 地点模块业务错误 ──
 
 var (
-	ErrLocationNotFound = errors.New("地点不存在")
+	ErrLocationNotFound      = errors.New("地点不存在")
+	ErrLocationWindowInvalid = errors.New("开放时段结束时间必须晚于开始时间")
+	ErrLocationCIDRInvalid   = errors.New("签到网段格式无效")
 )
 
 // LocationService 地点业务接口
@@ -40,21 +44,71 @@ func NewLocationService(repo *repository.Repository, logger *zap.Logger) Locatio
 // ────────────────────── Create ──────────────────────
 
 func (s *locationService) Create(ctx context.Context, req *dto.CreateLocationRequest, callerID string) (*dto.LocationResponse, error) {
+	if err := validateLocationWindows(req.Windows); err != nil {
+		return nil, err
+	}
+	if err := validateLocationNetworks(req.Networks); err != nil {
+		return nil, err
+	}
+
 	loc := &model.Location{
 		Name:      req.Name,
 		Address:   req.Address,
 		IsDefault: req.IsDefault,
 		IsActive:  true,
+		Capacity:  1,
+		QRSignIn:  true,
+	}
+	if req.Capacity != nil {
+		loc.Capacity = *req.Capacity
+	}
+	if req.QRSignIn != nil {
+		loc.QRSignIn = *req.QRSignIn
 	}
 	loc.CreatedBy = &callerID
 	loc.UpdatedBy = &callerID
 
-	if err := s.repo.Location.Create(ctx, loc); err != nil {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rollbackTx := func() {
+		if tx != nil {
+			tx.Rollback()
+		}
+	}
+	txRepo := s.repo.WithTx(tx)
+
+	if err := txRepo.Location.Create(ctx, loc); err != nil {
+		rollbackTx()
 		s.logger.Error("创建地点失败", zap.Error(err))
 		return nil, err
 	}
+	if len(req.Windows) > 0 {
+		if err := txRepo.Location.ReplaceWindows(ctx, loc.LocationID, locationWindowRows(loc.LocationID, req.Windows, callerID)); err != nil {
+			rollbackTx()
+			s.logger.Error("设置地点开放时段失败", zap.Error(err))
+			return nil, err
+		}
+	}
+	if len(req.Networks) > 0 {
+		if err := txRepo.Location.ReplaceNetworks(ctx, loc.LocationID, locationNetworkRows(loc.LocationID, req.Networks, callerID)); err != nil {
+			rollbackTx()
+			s.logger.Error("设置地点签到网段失败", zap.Error(err))
+			return nil, err
+		}
+	}
+	if tx != nil {
+		if err := tx.Commit().Error; err != nil {
+			return nil, err
+		}
+	}
 
-	return s.toLocationResponse(loc), nil
+	created, err := s.repo.Location.GetByID(ctx, loc.LocationID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toLocationResponse(created), nil
 }
 
 // ────────────────────── GetByID ──────────────────────
@@ -113,15 +167,66 @@ func (s *locationService) Update(ctx context.Context, id string, req *dto.Update
 	if req.IsActive != nil {
 		loc.IsActive = *req.IsActive
 	}
+	if req.Capacity != nil {
+		loc.Capacity = *req.Capacity
+	}
+	if req.QRSignIn != nil {
+		loc.QRSignIn = *req.QRSignIn
+	}
+	if req.Windows != nil {
+		if err := validateLocationWindows(*req.Windows); err != nil {
+			return nil, err
+		}
+	}
+	if req.Networks != nil {
+		if err := validateLocationNetworks(*req.Networks); err != nil {
+			return nil, err
+		}
+	}
 
 	loc.UpdatedBy = &callerID
 
-	if err := s.repo.Location.Update(ctx, loc); err != nil {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rollbackTx := func() {
+		if tx != nil {
+			tx.Rollback()
+		}
+	}
+	txRepo := s.repo.WithTx(tx)
+
+	if err := txRepo.Location.Update(ctx, loc); err != nil {
+		rollbackTx()
 		s.logger.Error("更新地点失败", zap.String("id", id), zap.Error(err))
 		return nil, err
 	}
+	if req.Windows != nil {
+		if err := txRepo.Location.ReplaceWindows(ctx, id, locationWindowRows(id, *req.Windows, callerID)); err != nil {
+			rollbackTx()
+			s.logger.Error("设置地点开放时段失败", zap.String("id", id), zap.Error(err))
+			return nil, err
+		}
+	}
+	if req.Networks != nil {
+		if err := txRepo.Location.ReplaceNetworks(ctx, id, locationNetworkRows(id, *req.Networks, callerID)); err != nil {
+			rollbackTx()
+			s.logger.Error("设置地点签到网段失败", zap.String("id", id), zap.Error(err))
+			return nil, err
+		}
+	}
+	if tx != nil {
+		if err := tx.Commit().Error; err != nil {
+			return nil, err
+		}
+	}
 
-	return s.toLocationResponse(loc), nil
+	updated, err := s.repo.Location.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.toLocationResponse(updated), nil
 }
 
 // ────────────────────── Delete ──────────────────────
@@ -147,13 +252,102 @@ func (s *locationService) Delete(ctx context.Context, id string, callerID string
 // ── 内部辅助方法 ──
 
 func (s *locationService) toLocationResponse(loc *model.Location) *dto.LocationResponse {
+	windows := make([]dto.LocationWindowResponse, 0, len(loc.Windows))
+	for _, w := range loc.Windows {
+		windows = append(windows, dto.LocationWindowResponse{
+			DayOfWeek: w.DayOfWeek,
+			StartTime: w.StartTime,
+			EndTime:   w.EndTime,
+		})
+	}
+	networks := make([]dto.LocationNetworkResponse, 0, len(loc.Networks))
+	for _, n := range loc.Networks {
+		networks = append(networks, dto.LocationNetworkResponse{CIDR: n.CIDR, Note: n.Note})
+	}
 	return &dto.LocationResponse{
 		ID:        loc.LocationID,
 		Name:      loc.Name,
 		Address:   loc.Address,
 		IsDefault: loc.IsDefault,
 		IsActive:  loc.IsActive,
+		Capacity:  loc.Capacity,
+		QRSignIn:  loc.QRSignIn,
+		Windows:   windows,
+		Networks:  networks,
 		CreatedAt: loc.CreatedAt.Format("2006-01-02T15:04:05Z"),
 		UpdatedAt: loc.UpdatedAt.Format("2006-01-02T15:04:05Z"),
 	}
 }
+
+// validateLocationWindows 开放时段须结束晚于开始
+func validateLocationWindows(windows []dto.LocationWindowRequest) error {
+	for _, w := range windows {
+		if w.EndTime <= w.StartTime {
+			return ErrLocationWindowInvalid
+		}
+	}
+	return nil
+}
+
+func locationWindowRows(locationID string, windows []dto.LocationWindowRequest, callerID string) []model.LocationWindow {
+	rows := make([]model.LocationWindow, 0, len(windows))
+	for _, w := range windows {
+		row := model.LocationWindow{
+			LocationID: locationID,
+			DayOfWeek:  w.DayOfWeek,
+			StartTime:  w.StartTime,
+			EndTime:    w.EndTime,
+		}
+		row.CreatedBy = &callerID
+		row.UpdatedBy = &callerID
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// validateLocationNetworks 签到网段须为合法的 CIDR 或单个 IP
+func validateLocationNetworks(networks []dto.LocationNetworkRequest) error {
+	for _, n := range networks {
+		if _, err := parseNetwork(n.CIDR); err != nil {
+			return ErrLocationCIDRInvalid
+		}
+	}
+	return nil
+}
+
+func locationNetworkRows(locationID string, networks []dto.LocationNetworkRequest, callerID string) []model.LocationNetwork {
+	rows := make([]model.LocationNetwork, 0, len(networks))
+	for _, n := range networks {
+		prefix, _ := parseNetwork(n.CIDR)
+		row := model.LocationNetwork{
+			LocationID: locationID,
+			CIDR:       prefix.String(),
+			Note:       n.Note,
+		}
+		row.CreatedBy = &callerID
+		row.UpdatedBy = &callerID
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// parseNetwork 解析网段并归一化为网络地址（"10.20.1.5/16" → "10.20.0.0/16"），单个 IP 视为主机网段
+func parseNetwork(s string) (netip.Prefix, error) {
+	s = strings.TrimSpace(s)
+	if !strings.Contains(s, "/") {
+		addr, err := netip.ParseAddr(s)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		addr = addr.Unmap()
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+	prefix, err := netip.ParsePrefix(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	if prefix.Addr().Is4In6() {
+		return netip.Prefix{}, errors.New("不支持 IPv4 映射的 IPv6 网段")
+	}
+	return prefix.Masked(), nil
+}
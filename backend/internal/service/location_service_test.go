@@ -53,6 +53,63 @@ func TestLocationService_Create_Success(t *testing.T) {
 	}
 }
 
+func TestLocationService_Create_CapacityAndWindows(t *testing.T) {
+	svc, _ := setupTestLocationService()
+
+	result, err := svc.Create(context.Background(), &dto.CreateLocationRequest{Name: "学生会办公室"}, "admin-001")
+	if err != nil {
+		t.Fatalf("Create 应成功: %v", err)
+	}
+	if result.Capacity != 1 || len(result.Windows) != 0 {
+		t.Errorf("默认容量 1、全天开放，实际 capacity=%d windows=%v", result.Capacity, result.Windows)
+	}
+
+	capacity := 3
+	req := &dto.CreateLocationRequest{
+		Name:     "活动室",
+		Capacity: &capacity,
+		Windows:  []dto.LocationWindowRequest{{DayOfWeek: 1, StartTime: "13:00", EndTime: "18:00"}},
+	}
+	result, err = svc.Create(context.Background(), req, "admin-001")
+	if err != nil {
+		t.Fatalf("Create 应成功: %v", err)
+	}
+	if result.Capacity != 3 || len(result.Windows) != 1 {
+		t.Errorf("期望 capacity=3 且 1 个开放时段，实际 capacity=%d windows=%v", result.Capacity, result.Windows)
+	}
+
+	req.Name = "会议室"
+	req.Windows = []dto.LocationWindowRequest{{DayOfWeek: 1, StartTime: "18:00", EndTime: "13:00"}}
+	if _, err := svc.Create(context.Background(), req, "admin-001"); !errors.Is(err, ErrLocationWindowInvalid) {
+		t.Errorf("期望 ErrLocationWindowInvalid，实际: %v", err)
+	}
+}
+
+func TestLocationService_Create_Networks(t *testing.T) {
+	svc, _ := setupTestLocationService()
+
+	req := &dto.CreateLocationRequest{
+		Name: "学生会办公室",
+		Networks: []dto.LocationNetworkRequest{
+			{CIDR: "10.20.1.5/16", Note: "办公楼"},
+			{CIDR: " 192.168.1.10 "},
+		},
+	}
+	result, err := svc.Create(context.Background(), req, "admin-001")
+	if err != nil {
+		t.Fatalf("Create 应成功: %v", err)
+	}
+	if len(result.Networks) != 2 || result.Networks[0].CIDR != "10.20.0.0/16" || result.Networks[1].CIDR != "192.168.1.10/32" {
+		t.Errorf("网段应归一化为网络地址、单个 IP 视为主机网段，实际 %+v", result.Networks)
+	}
+
+	req.Name = "活动室"
+	req.Networks = []dto.LocationNetworkRequest{{CIDR: "10.20.0.0/33"}}
+	if _, err := svc.Create(context.Background(), req, "admin-001"); !errors.Is(err, ErrLocationCIDRInvalid) {
+		t.Errorf("期望 ErrLocationCIDRInvalid，实际: %v", err)
+	}
+}
+
 // ── GetByID 测试 ──
 
 func TestLocationService_GetByID_Success(t *testing.T) {
@@ -3,11 +3,14 @@ package service
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
 
 	"echo-union/backend/internal/model"
+	"echo-union/backend/internal/repository"
 )
 
 // ── Mock SemesterRepository ──
@@ -182,6 +185,20 @@ func (m *mockLocationRepo) Delete(_ context.Context, id string, _ string) error
 	return nil
 }
 
+func (m *mockLocationRepo) ReplaceWindows(_ context.Context, locationID string, windows []model.LocationWindow) error {
+	if l, ok := m.locations[locationID]; ok {
+		l.Windows = windows
+	}
+	return nil
+}
+
+func (m *mockLocationRepo) ReplaceNetworks(_ context.Context, locationID string, networks []model.LocationNetwork) error {
+	if l, ok := m.locations[locationID]; ok {
+		l.Networks = networks
+	}
+	return nil
+}
+
 // ── Mock SystemConfigRepository ──
 
 type mockSystemConfigRepo struct {
@@ -197,6 +214,7 @@ func newMockSystemConfigRepo() *mockSystemConfigRepo {
 			DefaultLocation:      "学生会办公室",
 			SignInWindowMinutes:  15,
 			SignOutWindowMinutes: 15,
+			SwapRequiresApproval: true,
 		},
 	}
 }
@@ -539,7 +557,7 @@ func (m *mockScheduleRepo) GetByID(_ context.Context, id string) (*model.Schedul
 
 func (m *mockScheduleRepo) GetBySemester(_ context.Context, semesterID string) (*model.Schedule, error) {
 	for _, s := range m.schedules {
-		if s.SemesterID == semesterID && s.Status != "archived" {
+		if s.SemesterID == semesterID && s.Status != model.ScheduleStatusArchived && s.Status != model.ScheduleStatusCandidate {
 			return s, nil
 		}
 	}
@@ -561,7 +579,39 @@ func (m *mockScheduleRepo) Update(_ context.Context, schedule *model.Schedule) e
 	return nil
 }
 
-func (m *mockScheduleRepo) Delete(_ context.Context, id string) error {
+func (m *mockScheduleRepo) ListBySemester(_ context.Context, semesterID string) ([]model.Schedule, error) {
+	var result []model.Schedule
+	for _, s := range m.schedules {
+		if s.SemesterID == semesterID {
+			result = append(result, *s)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.After(result[j].CreatedAt) })
+	return result, nil
+}
+
+func (m *mockScheduleRepo) ListBySemesterAndStatus(_ context.Context, semesterID, status string) ([]model.Schedule, error) {
+	var result []model.Schedule
+	for _, s := range m.schedules {
+		if s.SemesterID == semesterID && s.Status == status {
+			result = append(result, *s)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ScheduleID < result[j].ScheduleID })
+	return result, nil
+}
+
+func (m *mockScheduleRepo) CountBySemesterAndStatus(_ context.Context, semesterID, status string) (int64, error) {
+	var count int64
+	for _, s := range m.schedules {
+		if s.SemesterID == semesterID && s.Status == status {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *mockScheduleRepo) Delete(_ context.Context, id, _ string) error {
 	delete(m.schedules, id)
 	return nil
 }
@@ -616,6 +666,18 @@ func (m *mockScheduleItemRepo) ListByScheduleAndMember(_ context.Context, schedu
 	return result, nil
 }
 
+func (m *mockScheduleItemRepo) BatchCountBySchedules(_ context.Context, scheduleIDs []string) (map[string]int64, error) {
+	counts := make(map[string]int64, len(scheduleIDs))
+	for _, id := range scheduleIDs {
+		for _, item := range m.items {
+			if item.ScheduleID == id {
+				counts[id]++
+			}
+		}
+	}
+	return counts, nil
+}
+
 func (m *mockScheduleItemRepo) Update(_ context.Context, item *model.ScheduleItem) error {
 	item.UpdatedAt = time.Now()
 	m.items[item.ScheduleItemID] = item
@@ -699,3 +761,1434 @@ func (m *mockScheduleChangeLogRepo) ListBySchedule(_ context.Context, scheduleID
 	}
 	return filtered[offset:end], total, nil
 }
+
+// ── Mock SemesterCalendarRepository ──
+
+type mockSemesterCalendarRepo struct {
+	days      map[string]*model.SemesterCalendarDay
+	idCounter int
+}
+
+func newMockSemesterCalendarRepo() *mockSemesterCalendarRepo {
+	return &mockSemesterCalendarRepo{days: make(map[string]*model.SemesterCalendarDay)}
+}
+
+func (m *mockSemesterCalendarRepo) Create(_ context.Context, day *model.SemesterCalendarDay) error {
+	m.idCounter++
+	day.CalendarDayID = fmt.Sprintf("cal-%d", m.idCounter)
+	m.days[day.CalendarDayID] = day
+	return nil
+}
+
+func (m *mockSemesterCalendarRepo) GetByID(_ context.Context, id string) (*model.SemesterCalendarDay, error) {
+	if d, ok := m.days[id]; ok {
+		return d, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *mockSemesterCalendarRepo) GetBySemesterAndDate(_ context.Context, semesterID string, date time.Time) (*model.SemesterCalendarDay, error) {
+	for _, d := range m.days {
+		if d.SemesterID == semesterID && d.Date.Format(model.TimeFormatDate) == date.Format(model.TimeFormatDate) {
+			return d, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *mockSemesterCalendarRepo) ListBySemester(_ context.Context, semesterID string) ([]model.SemesterCalendarDay, error) {
+	var result []model.SemesterCalendarDay
+	for _, d := range m.days {
+		if d.SemesterID == semesterID {
+			result = append(result, *d)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Date.Before(result[j].Date) })
+	return result, nil
+}
+
+func (m *mockSemesterCalendarRepo) Update(_ context.Context, day *model.SemesterCalendarDay) error {
+	m.days[day.CalendarDayID] = day
+	return nil
+}
+
+func (m *mockSemesterCalendarRepo) Delete(_ context.Context, id string, _ string) error {
+	delete(m.days, id)
+	return nil
+}
+
+// ── Mock DutyRecordRepository ──
+
+type mockDutyRecordRepo struct {
+	records   map[string]*model.DutyRecord
+	items     *mockScheduleItemRepo // 用于按排班表过滤
+	users     *mockUserRepo         // 用于按部门过滤（可为 nil）
+	idCounter int
+}
+
+func newMockDutyRecordRepo(items *mockScheduleItemRepo) *mockDutyRecordRepo {
+	return &mockDutyRecordRepo{records: make(map[string]*model.DutyRecord), items: items}
+}
+
+func (m *mockDutyRecordRepo) inSchedule(r *model.DutyRecord, scheduleID string) bool {
+	if r.ScheduleItemID == nil {
+		return false
+	}
+	item, ok := m.items.items[*r.ScheduleItemID]
+	return ok && item.ScheduleID == scheduleID
+}
+
+func (m *mockDutyRecordRepo) BatchCreate(_ context.Context, records []model.DutyRecord) error {
+	for i := range records {
+		m.idCounter++
+		records[i].DutyRecordID = fmt.Sprintf("duty-%d", m.idCounter)
+		cp := records[i]
+		m.records[cp.DutyRecordID] = &cp
+	}
+	return nil
+}
+
+func (m *mockDutyRecordRepo) GetByID(_ context.Context, id string) (*model.DutyRecord, error) {
+	r, ok := m.records[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	cp := *r
+	if r.ScheduleItemID != nil {
+		cp.ScheduleItem = m.items.items[*r.ScheduleItemID]
+	}
+	return &cp, nil
+}
+
+func (m *mockDutyRecordRepo) ReassignPending(_ context.Context, id, fromMemberID, toMemberID, _ string) (int64, error) {
+	r, ok := m.records[id]
+	if !ok || r.MemberID != fromMemberID || r.Status != model.DutyRecordStatusPending {
+		return 0, nil
+	}
+	r.MemberID = toMemberID
+	r.NeedsSubstitute = false
+	r.SubstituteReason = ""
+	return 1, nil
+}
+
+func (m *mockDutyRecordRepo) ListByScheduleFrom(_ context.Context, scheduleID string, from time.Time) ([]model.DutyRecord, error) {
+	var result []model.DutyRecord
+	for _, r := range m.records {
+		if m.inSchedule(r, scheduleID) && !r.DutyDate.Before(from) {
+			result = append(result, *r)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].DutyDate.Before(result[j].DutyDate) })
+	return result, nil
+}
+
+func (m *mockDutyRecordRepo) DeletePendingByIDs(_ context.Context, ids []string, _ string) error {
+	for _, id := range ids {
+		if r, ok := m.records[id]; ok && r.Status == model.DutyRecordStatusPending {
+			delete(m.records, id)
+		}
+	}
+	return nil
+}
+
+func (m *mockDutyRecordRepo) UpdatePendingMemberByItemFrom(_ context.Context, scheduleItemID string, from time.Time, fromMemberID, toMemberID, _ string) error {
+	for _, r := range m.records {
+		if r.ScheduleItemID != nil && *r.ScheduleItemID == scheduleItemID && r.MemberID == fromMemberID &&
+			!r.DutyDate.Before(from) && r.Status == model.DutyRecordStatusPending {
+			r.MemberID = toMemberID
+			r.NeedsSubstitute = false
+			r.SubstituteReason = ""
+		}
+	}
+	return nil
+}
+
+func (m *mockDutyRecordRepo) ListPendingByMemberAndDate(_ context.Context, scheduleID, memberID string, date time.Time) ([]model.DutyRecord, error) {
+	var result []model.DutyRecord
+	for _, r := range m.records {
+		if m.inSchedule(r, scheduleID) && r.MemberID == memberID && r.DutyDate.Equal(date) && r.Status == model.DutyRecordStatusPending {
+			result = append(result, *r)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockDutyRecordRepo) ListPendingByMemberFrom(_ context.Context, scheduleID, memberID string, from time.Time) ([]model.DutyRecord, error) {
+	var result []model.DutyRecord
+	for _, r := range m.records {
+		if m.inSchedule(r, scheduleID) && r.MemberID == memberID && !r.DutyDate.Before(from) && r.Status == model.DutyRecordStatusPending {
+			cp := *r
+			cp.ScheduleItem = m.items.items[*r.ScheduleItemID]
+			result = append(result, cp)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].DutyDate.Before(result[j].DutyDate) })
+	return result, nil
+}
+
+func (m *mockDutyRecordRepo) ListNeedingSubstitute(_ context.Context, scheduleID string, from time.Time) ([]model.DutyRecord, error) {
+	var result []model.DutyRecord
+	for _, r := range m.records {
+		if m.inSchedule(r, scheduleID) && !r.DutyDate.Before(from) && r.NeedsSubstitute && r.Status == model.DutyRecordStatusPending {
+			cp := *r
+			cp.ScheduleItem = m.items.items[*r.ScheduleItemID]
+			result = append(result, cp)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].DutyDate.Before(result[j].DutyDate) })
+	return result, nil
+}
+
+func (m *mockDutyRecordRepo) UpdateSubstitute(_ context.Context, id string, needs bool, reason, _ string) error {
+	if r, ok := m.records[id]; ok {
+		r.NeedsSubstitute = needs
+		r.SubstituteReason = reason
+	}
+	return nil
+}
+
+func (m *mockDutyRecordRepo) CountAttendance(_ context.Context, from, to time.Time, departmentID string) ([]repository.AttendanceCount, error) {
+	type groupKey struct {
+		memberID, status   string
+		isLate, substitute bool
+	}
+	groups := make(map[groupKey]*repository.AttendanceCount)
+	var keys []groupKey
+	for _, r := range m.records {
+		if r.DutyDate.Before(from) || r.DutyDate.After(to) {
+			continue
+		}
+		if departmentID != "" {
+			if m.users == nil || m.users.users[r.MemberID] == nil || m.users.users[r.MemberID].DepartmentID != departmentID {
+				continue
+			}
+		}
+		key := groupKey{r.MemberID, r.Status, r.IsLate, r.SubstituteForID != nil}
+		g := groups[key]
+		if g == nil {
+			g = &repository.AttendanceCount{MemberID: r.MemberID, Status: r.Status, IsLate: r.IsLate, IsSubstitute: key.substitute}
+			groups[key] = g
+			keys = append(keys, key)
+		}
+		g.Count++
+		if r.ScheduleItemID != nil {
+			if item := m.items.items[*r.ScheduleItemID]; item != nil && item.TimeSlot != nil {
+				start, _ := time.Parse("15:04", hhmm(item.TimeSlot.StartTime))
+				end, _ := time.Parse("15:04", hhmm(item.TimeSlot.EndTime))
+				g.Hours += end.Sub(start).Hours()
+			}
+		}
+	}
+	result := make([]repository.AttendanceCount, 0, len(keys))
+	for _, k := range keys {
+		result = append(result, *groups[k])
+	}
+	return result, nil
+}
+
+func (m *mockDutyRecordRepo) MarkAbsent(_ context.Context, id, _ string) (int64, error) {
+	r, ok := m.records[id]
+	if !ok || r.Status != model.DutyRecordStatusPending {
+		return 0, nil
+	}
+	r.Status = model.DutyRecordStatusAbsent
+	return 1, nil
+}
+
+func (m *mockDutyRecordRepo) SignIn(_ context.Context, id string, at time.Time, isLate bool, _ string) (int64, error) {
+	r, ok := m.records[id]
+	if !ok || r.Status != model.DutyRecordStatusPending {
+		return 0, nil
+	}
+	r.Status = model.DutyRecordStatusOnDuty
+	r.SignInTime = &at
+	r.IsLate = isLate
+	return 1, nil
+}
+
+func (m *mockDutyRecordRepo) SignOut(_ context.Context, id string, at time.Time, _ string) (int64, error) {
+	r, ok := m.records[id]
+	if !ok || r.Status != model.DutyRecordStatusOnDuty {
+		return 0, nil
+	}
+	r.Status = model.DutyRecordStatusCompleted
+	r.SignOutTime = &at
+	return 1, nil
+}
+
+func (m *mockDutyRecordRepo) SetNetworkOverride(_ context.Context, id string, enabled bool, reason, _ string) error {
+	if r, ok := m.records[id]; ok {
+		r.NetworkOverride = enabled
+		r.NetworkOverrideReason = reason
+	}
+	return nil
+}
+
+func (m *mockDutyRecordRepo) DeletePendingByEventShifts(_ context.Context, shiftIDs []string, memberID, _ string) error {
+	for id, r := range m.records {
+		if r.EventShiftID == nil || r.Status != model.DutyRecordStatusPending || (memberID != "" && r.MemberID != memberID) {
+			continue
+		}
+		for _, shiftID := range shiftIDs {
+			if *r.EventShiftID == shiftID {
+				delete(m.records, id)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// ── Mock SwapRequestRepository ──
+
+type mockSwapRequestRepo struct {
+	swaps     map[string]*model.SwapRequest
+	records   *mockDutyRecordRepo // 用于预加载值班记录
+	users     *mockUserRepo
+	schedules *mockScheduleRepo // 用于按学期统计
+	idCounter int
+}
+
+func newMockSwapRequestRepo(records *mockDutyRecordRepo, users *mockUserRepo, schedules *mockScheduleRepo) *mockSwapRequestRepo {
+	return &mockSwapRequestRepo{swaps: make(map[string]*model.SwapRequest), records: records, users: users, schedules: schedules}
+}
+
+// withDetails 模拟预加载
+func (m *mockSwapRequestRepo) withDetails(r *model.SwapRequest) model.SwapRequest {
+	cp := *r
+	if r.DutyRecordID != nil {
+		cp.DutyRecord, _ = m.records.GetByID(context.Background(), *r.DutyRecordID)
+	}
+	if r.TargetDutyRecordID != nil {
+		cp.TargetDutyRecord, _ = m.records.GetByID(context.Background(), *r.TargetDutyRecordID)
+	}
+	cp.ScheduleItem = m.records.items.items[r.ScheduleItemID]
+	if r.TargetScheduleItemID != nil {
+		cp.TargetScheduleItem = m.records.items.items[*r.TargetScheduleItemID]
+	}
+	cp.Applicant = m.users.users[r.ApplicantID]
+	if r.TargetMemberID != nil {
+		cp.TargetMember = m.users.users[*r.TargetMemberID]
+	}
+	return cp
+}
+
+func (m *mockSwapRequestRepo) Create(_ context.Context, req *model.SwapRequest) error {
+	m.idCounter++
+	req.SwapRequestID = fmt.Sprintf("swap-%d", m.idCounter)
+	req.CreatedAt = time.Now()
+	cp := *req
+	cp.DutyRecord, cp.TargetDutyRecord = nil, nil
+	cp.ScheduleItem, cp.TargetScheduleItem = nil, nil
+	m.swaps[req.SwapRequestID] = &cp
+	return nil
+}
+
+func (m *mockSwapRequestRepo) GetByID(_ context.Context, id string) (*model.SwapRequest, error) {
+	r, ok := m.swaps[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	cp := m.withDetails(r)
+	return &cp, nil
+}
+
+func (m *mockSwapRequestRepo) ListOpenGiveAways(_ context.Context, from time.Time) ([]model.SwapRequest, error) {
+	var result []model.SwapRequest
+	for _, r := range m.swaps {
+		if r.Kind != model.SwapKindGiveAway || r.Status != model.SwapStatusPending || r.TargetMemberID != nil {
+			continue
+		}
+		cp := m.withDetails(r)
+		if cp.DutyRecord != nil && !cp.DutyRecord.DutyDate.Before(from) {
+			result = append(result, cp)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].DutyRecord.DutyDate.Before(result[j].DutyRecord.DutyDate) })
+	return result, nil
+}
+
+func (m *mockSwapRequestRepo) ListWithFilters(_ context.Context, filters *repository.SwapRequestFilters, offset, limit int) ([]model.SwapRequest, int64, error) {
+	var filtered []model.SwapRequest
+	for _, r := range m.swaps {
+		if filters != nil {
+			if filters.Kind != "" && r.Kind != filters.Kind {
+				continue
+			}
+			if filters.Status != "" && r.Status != filters.Status {
+				continue
+			}
+			if filters.MemberID != "" && r.ApplicantID != filters.MemberID &&
+				(r.TargetMemberID == nil || *r.TargetMemberID != filters.MemberID) {
+				continue
+			}
+		}
+		filtered = append(filtered, m.withDetails(r))
+	}
+	sort.Slice(filtered, func(i, j int) bool { return filtered[i].SwapRequestID < filtered[j].SwapRequestID })
+	total := int64(len(filtered))
+	if offset >= len(filtered) {
+		return nil, total, nil
+	}
+	end := offset + limit
+	if end > len(filtered) {
+		end = len(filtered)
+	}
+	return filtered[offset:end], total, nil
+}
+
+func (m *mockSwapRequestRepo) CountOpenByDutyRecord(_ context.Context, dutyRecordID string) (int64, error) {
+	var count int64
+	for _, r := range m.swaps {
+		involved := (r.DutyRecordID != nil && *r.DutyRecordID == dutyRecordID) ||
+			(r.TargetDutyRecordID != nil && *r.TargetDutyRecordID == dutyRecordID)
+		if involved && (r.Status == model.SwapStatusPending || r.Status == model.SwapStatusReviewing) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *mockSwapRequestRepo) CountOpenItemExchanges(_ context.Context, scheduleItemID string) (int64, error) {
+	var count int64
+	for _, r := range m.swaps {
+		if r.Kind != model.SwapKindExchange || r.DutyRecordID != nil ||
+			(r.Status != model.SwapStatusPending && r.Status != model.SwapStatusReviewing) {
+			continue
+		}
+		if r.ScheduleItemID == scheduleItemID || (r.TargetScheduleItemID != nil && *r.TargetScheduleItemID == scheduleItemID) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *mockSwapRequestRepo) CountCompletedByApplicantInSemester(_ context.Context, applicantID, semesterID string) (int64, error) {
+	var count int64
+	for _, r := range m.swaps {
+		if r.ApplicantID != applicantID || r.Status != model.SwapStatusCompleted {
+			continue
+		}
+		item := m.records.items.items[r.ScheduleItemID]
+		if item == nil {
+			continue
+		}
+		if schedule := m.schedules.schedules[item.ScheduleID]; schedule != nil && schedule.SemesterID == semesterID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *mockSwapRequestRepo) Claim(_ context.Context, id, memberID, status string) (int64, error) {
+	r, ok := m.swaps[id]
+	if !ok || r.Status != model.SwapStatusPending || r.TargetMemberID != nil {
+		return 0, nil
+	}
+	now := time.Now()
+	r.TargetMemberID = &memberID
+	r.TargetRespondedAt = &now
+	r.Status = status
+	return 1, nil
+}
+
+func (m *mockSwapRequestRepo) Respond(_ context.Context, id, memberID, status, reason string) (int64, error) {
+	r, ok := m.swaps[id]
+	if !ok || r.Kind != model.SwapKindExchange || r.Status != model.SwapStatusPending ||
+		r.TargetMemberID == nil || *r.TargetMemberID != memberID {
+		return 0, nil
+	}
+	now := time.Now()
+	r.TargetRespondedAt = &now
+	r.Status = status
+	if status == model.SwapStatusRejected {
+		r.RejectReason = reason
+	}
+	return 1, nil
+}
+
+func (m *mockSwapRequestRepo) Approve(_ context.Context, id, approvedBy string) (int64, error) {
+	r, ok := m.swaps[id]
+	if !ok || r.Status != model.SwapStatusReviewing {
+		return 0, nil
+	}
+	now := time.Now()
+	r.Status = model.SwapStatusCompleted
+	r.ApprovedAt = &now
+	r.ApprovedBy = &approvedBy
+	return 1, nil
+}
+
+func (m *mockSwapRequestRepo) Reject(_ context.Context, id, reason, rejectedBy string) (int64, error) {
+	r, ok := m.swaps[id]
+	if !ok || r.Status != model.SwapStatusReviewing {
+		return 0, nil
+	}
+	now := time.Now()
+	r.Status = model.SwapStatusRejected
+	r.RejectReason = reason
+	r.ApprovedAt = &now
+	r.ApprovedBy = &rejectedBy
+	return 1, nil
+}
+
+func (m *mockSwapRequestRepo) Cancel(_ context.Context, id, cancelledBy string) (int64, error) {
+	r, ok := m.swaps[id]
+	if !ok || r.ApplicantID != cancelledBy ||
+		(r.Status != model.SwapStatusPending && r.Status != model.SwapStatusReviewing) {
+		return 0, nil
+	}
+	r.Status = model.SwapStatusCancelled
+	return 1, nil
+}
+
+// ── Mock DepartmentSwapPolicyRepository ──
+
+type mockDepartmentSwapPolicyRepo struct {
+	policies map[string]*model.DepartmentSwapPolicy
+}
+
+func newMockDepartmentSwapPolicyRepo() *mockDepartmentSwapPolicyRepo {
+	return &mockDepartmentSwapPolicyRepo{policies: make(map[string]*model.DepartmentSwapPolicy)}
+}
+
+func (m *mockDepartmentSwapPolicyRepo) GetByDepartment(_ context.Context, departmentID string) (*model.DepartmentSwapPolicy, error) {
+	if p, ok := m.policies[departmentID]; ok {
+		cp := *p
+		return &cp, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *mockDepartmentSwapPolicyRepo) List(_ context.Context) ([]model.DepartmentSwapPolicy, error) {
+	var result []model.DepartmentSwapPolicy
+	for _, p := range m.policies {
+		result = append(result, *p)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].DepartmentID < result[j].DepartmentID })
+	return result, nil
+}
+
+func (m *mockDepartmentSwapPolicyRepo) Save(_ context.Context, policy *model.DepartmentSwapPolicy) error {
+	cp := *policy
+	m.policies[policy.DepartmentID] = &cp
+	return nil
+}
+
+func (m *mockDepartmentSwapPolicyRepo) Delete(_ context.Context, departmentID string) error {
+	delete(m.policies, departmentID)
+	return nil
+}
+
+// ── Mock LeaveRequestRepository ──
+
+type mockLeaveRequestRepo struct {
+	leaves    map[string]*model.LeaveRequest
+	records   *mockDutyRecordRepo // 用于预加载值班记录
+	users     *mockUserRepo
+	idCounter int
+}
+
+func newMockLeaveRequestRepo(records *mockDutyRecordRepo, users *mockUserRepo) *mockLeaveRequestRepo {
+	return &mockLeaveRequestRepo{leaves: make(map[string]*model.LeaveRequest), records: records, users: users}
+}
+
+// withDetails 模拟预加载
+func (m *mockLeaveRequestRepo) withDetails(l *model.LeaveRequest) model.LeaveRequest {
+	cp := *l
+	cp.DutyRecord, _ = m.records.GetByID(context.Background(), l.DutyRecordID)
+	cp.Member = m.users.users[l.MemberID]
+	if l.SubstituteID != nil {
+		cp.Substitute = m.users.users[*l.SubstituteID]
+	}
+	return cp
+}
+
+func (m *mockLeaveRequestRepo) Create(_ context.Context, leave *model.LeaveRequest) error {
+	m.idCounter++
+	leave.LeaveRequestID = fmt.Sprintf("leave-%d", m.idCounter)
+	leave.CreatedAt = time.Now()
+	cp := *leave
+	cp.DutyRecord, cp.Member, cp.Substitute = nil, nil, nil
+	m.leaves[leave.LeaveRequestID] = &cp
+	return nil
+}
+
+func (m *mockLeaveRequestRepo) GetByID(_ context.Context, id string) (*model.LeaveRequest, error) {
+	l, ok := m.leaves[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	cp := m.withDetails(l)
+	return &cp, nil
+}
+
+func (m *mockLeaveRequestRepo) ListWithFilters(_ context.Context, filters *repository.LeaveRequestFilters, offset, limit int) ([]model.LeaveRequest, int64, error) {
+	var filtered []model.LeaveRequest
+	for _, l := range m.leaves {
+		if filters != nil {
+			if filters.MemberID != "" && l.MemberID != filters.MemberID {
+				continue
+			}
+			if filters.DepartmentID != "" {
+				if u := m.users.users[l.MemberID]; u == nil || u.DepartmentID != filters.DepartmentID {
+					continue
+				}
+			}
+			if filters.Status != "" && l.Status != filters.Status {
+				continue
+			}
+			if filters.SemesterID != "" && l.SemesterID != filters.SemesterID {
+				continue
+			}
+		}
+		filtered = append(filtered, m.withDetails(l))
+	}
+	sort.Slice(filtered, func(i, j int) bool { return filtered[i].LeaveRequestID < filtered[j].LeaveRequestID })
+	total := int64(len(filtered))
+	if offset >= len(filtered) {
+		return nil, total, nil
+	}
+	end := offset + limit
+	if end > len(filtered) {
+		end = len(filtered)
+	}
+	return filtered[offset:end], total, nil
+}
+
+func (m *mockLeaveRequestRepo) CountPendingByDutyRecord(_ context.Context, dutyRecordID string) (int64, error) {
+	var count int64
+	for _, l := range m.leaves {
+		if l.DutyRecordID == dutyRecordID && l.Status == model.LeaveStatusPending {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *mockLeaveRequestRepo) CountByMember(_ context.Context, semesterID, departmentID string) ([]repository.LeaveCount, error) {
+	type key struct{ member, status string }
+	counts := make(map[key]int64)
+	for _, l := range m.leaves {
+		if l.SemesterID != semesterID {
+			continue
+		}
+		if departmentID != "" {
+			if u := m.users.users[l.MemberID]; u == nil || u.DepartmentID != departmentID {
+				continue
+			}
+		}
+		counts[key{l.MemberID, l.Status}]++
+	}
+	result := make([]repository.LeaveCount, 0, len(counts))
+	for k, c := range counts {
+		result = append(result, repository.LeaveCount{MemberID: k.member, Status: k.status, Count: c})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].MemberID != result[j].MemberID {
+			return result[i].MemberID < result[j].MemberID
+		}
+		return result[i].Status < result[j].Status
+	})
+	return result, nil
+}
+
+func (m *mockLeaveRequestRepo) Review(_ context.Context, id, status string, substituteID *string, note, reviewedBy string) (int64, error) {
+	l, ok := m.leaves[id]
+	if !ok || l.Status != model.LeaveStatusPending {
+		return 0, nil
+	}
+	now := time.Now()
+	l.Status = status
+	l.SubstituteID = substituteID
+	l.ReviewNote = note
+	l.ReviewedAt = &now
+	l.ReviewedBy = &reviewedBy
+	return 1, nil
+}
+
+func (m *mockLeaveRequestRepo) Cancel(_ context.Context, id, memberID string) (int64, error) {
+	l, ok := m.leaves[id]
+	if !ok || l.MemberID != memberID || l.Status != model.LeaveStatusPending {
+		return 0, nil
+	}
+	l.Status = model.LeaveStatusCancelled
+	return 1, nil
+}
+
+// ── Mock EmergencySubstitutionRepository ──
+
+type mockEmergencySubstitutionRepo struct {
+	emergencies map[string]*model.EmergencySubstitution
+	records     *mockDutyRecordRepo // 用于预加载缺勤记录
+	users       *mockUserRepo
+	idCounter   int
+}
+
+func newMockEmergencySubstitutionRepo(records *mockDutyRecordRepo, users *mockUserRepo) *mockEmergencySubstitutionRepo {
+	return &mockEmergencySubstitutionRepo{emergencies: make(map[string]*model.EmergencySubstitution), records: records, users: users}
+}
+
+// withDetails 模拟预加载
+func (m *mockEmergencySubstitutionRepo) withDetails(e *model.EmergencySubstitution) *model.EmergencySubstitution {
+	cp := *e
+	cp.DutyRecord, _ = m.records.GetByID(context.Background(), e.DutyRecordID)
+	if cp.DutyRecord != nil {
+		cp.DutyRecord.Member = m.users.users[cp.DutyRecord.MemberID]
+	}
+	if e.SubstituteID != nil {
+		cp.Substitute = m.users.users[*e.SubstituteID]
+	}
+	return &cp
+}
+
+func (m *mockEmergencySubstitutionRepo) Create(_ context.Context, e *model.EmergencySubstitution) error {
+	m.idCounter++
+	e.EmergencySubstitutionID = fmt.Sprintf("emergency-%d", m.idCounter)
+	e.CreatedAt = time.Now()
+	cp := *e
+	cp.DutyRecord, cp.Substitute = nil, nil
+	m.emergencies[e.EmergencySubstitutionID] = &cp
+	return nil
+}
+
+func (m *mockEmergencySubstitutionRepo) GetByID(_ context.Context, id string) (*model.EmergencySubstitution, error) {
+	e, ok := m.emergencies[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return m.withDetails(e), nil
+}
+
+func (m *mockEmergencySubstitutionRepo) GetByDutyRecord(_ context.Context, dutyRecordID string) (*model.EmergencySubstitution, error) {
+	for _, e := range m.emergencies {
+		if e.DutyRecordID == dutyRecordID {
+			return m.withDetails(e), nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *mockEmergencySubstitutionRepo) Fill(_ context.Context, id, substituteID, substituteRecordID string) (int64, error) {
+	e, ok := m.emergencies[id]
+	if !ok || e.Status != model.EmergencyStatusOpen || !time.Now().Before(e.ExpiresAt) {
+		return 0, nil
+	}
+	now := time.Now()
+	e.Status = model.EmergencyStatusFilled
+	e.SubstituteID = &substituteID
+	e.SubstituteRecordID = &substituteRecordID
+	e.FilledAt = &now
+	return 1, nil
+}
+
+func (m *mockEmergencySubstitutionRepo) Cancel(_ context.Context, id, _ string) (int64, error) {
+	e, ok := m.emergencies[id]
+	if !ok || e.Status != model.EmergencyStatusOpen {
+		return 0, nil
+	}
+	e.Status = model.EmergencyStatusCancelled
+	return 1, nil
+}
+
+// ── Mock SignInRejectionRepository ──
+
+type mockSignInRejectionRepo struct {
+	rejections []model.SignInRejection
+	users      *mockUserRepo // 用于按部门过滤
+}
+
+func newMockSignInRejectionRepo(users *mockUserRepo) *mockSignInRejectionRepo {
+	return &mockSignInRejectionRepo{users: users}
+}
+
+func (m *mockSignInRejectionRepo) Create(_ context.Context, rejection *model.SignInRejection) error {
+	rejection.SignInRejectionID = fmt.Sprintf("rejection-%d", len(m.rejections)+1)
+	rejection.CreatedAt = time.Now()
+	m.rejections = append(m.rejections, *rejection)
+	return nil
+}
+
+func (m *mockSignInRejectionRepo) ListWithFilters(_ context.Context, filters *repository.SignInRejectionFilters, offset, limit int) ([]model.SignInRejection, int64, error) {
+	var result []model.SignInRejection
+	for i := len(m.rejections) - 1; i >= 0; i-- {
+		r := m.rejections[i]
+		if filters.MemberID != "" && r.MemberID != filters.MemberID {
+			continue
+		}
+		if filters.DepartmentID != "" {
+			if u := m.users.users[r.MemberID]; u == nil || u.DepartmentID != filters.DepartmentID {
+				continue
+			}
+		}
+		if filters.Reason != "" && r.Reason != filters.Reason {
+			continue
+		}
+		r.Member = m.users.users[r.MemberID]
+		result = append(result, r)
+	}
+	total := int64(len(result))
+	if offset >= len(result) {
+		return nil, total, nil
+	}
+	end := offset + limit
+	if end > len(result) {
+		end = len(result)
+	}
+	return result[offset:end], total, nil
+}
+
+// ── Mock DutyReportRepository ──
+
+type mockDutyReportRepo struct {
+	reports map[string]*model.DutyReport // key: duty_record_id
+	records *mockDutyRecordRepo
+	users   *mockUserRepo // 用于预加载成员与按部门过滤
+}
+
+func newMockDutyReportRepo(records *mockDutyRecordRepo, users *mockUserRepo) *mockDutyReportRepo {
+	return &mockDutyReportRepo{reports: make(map[string]*model.DutyReport), records: records, users: users}
+}
+
+func (m *mockDutyReportRepo) withDetails(r *model.DutyReport) model.DutyReport {
+	cp := *r
+	cp.Member = m.users.users[r.MemberID]
+	if record, err := m.records.GetByID(context.Background(), r.DutyRecordID); err == nil {
+		cp.DutyRecord = record
+	}
+	return cp
+}
+
+func (m *mockDutyReportRepo) GetByDutyRecord(_ context.Context, dutyRecordID string) (*model.DutyReport, error) {
+	r, ok := m.reports[dutyRecordID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	cp := m.withDetails(r)
+	return &cp, nil
+}
+
+func (m *mockDutyReportRepo) Save(_ context.Context, report *model.DutyReport) error {
+	if report.DutyReportID == "" {
+		report.DutyReportID = fmt.Sprintf("report-%d", len(m.reports)+1)
+		report.CreatedAt = time.Now()
+	}
+	report.UpdatedAt = time.Now()
+	cp := *report
+	cp.DutyRecord, cp.Member, cp.Location = nil, nil, nil
+	m.reports[report.DutyRecordID] = &cp
+	return nil
+}
+
+func (m *mockDutyReportRepo) GetLatestAtLocation(_ context.Context, locationID string, since, before time.Time) (*model.DutyReport, error) {
+	var latest *model.DutyReport
+	for _, r := range m.reports {
+		if r.LocationID == nil || *r.LocationID != locationID {
+			continue
+		}
+		if r.DutyStart.Before(since) || !r.DutyStart.Before(before) {
+			continue
+		}
+		if latest == nil || r.DutyStart.After(latest.DutyStart) {
+			latest = r
+		}
+	}
+	if latest == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	cp := m.withDetails(latest)
+	return &cp, nil
+}
+
+func (m *mockDutyReportRepo) ListWithFilters(_ context.Context, filters *repository.DutyReportFilters, offset, limit int) ([]model.DutyReport, int64, error) {
+	var result []model.DutyReport
+	for _, r := range m.reports {
+		if filters.MemberID != "" && r.MemberID != filters.MemberID {
+			continue
+		}
+		if filters.DepartmentID != "" {
+			if u := m.users.users[r.MemberID]; u == nil || u.DepartmentID != filters.DepartmentID {
+				continue
+			}
+		}
+		if filters.Tag != "" && !containsString(r.Tags, filters.Tag) {
+			continue
+		}
+		if filters.Keyword != "" && !strings.Contains(strings.ToLower(r.Content), strings.ToLower(filters.Keyword)) {
+			continue
+		}
+		if filters.Incident != nil && r.Incident != *filters.Incident {
+			continue
+		}
+		if (filters.From != nil && r.DutyStart.Before(*filters.From)) || (filters.To != nil && !r.DutyStart.Before(*filters.To)) {
+			continue
+		}
+		result = append(result, m.withDetails(r))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].DutyStart.After(result[j].DutyStart) })
+	total := int64(len(result))
+	if offset >= len(result) {
+		return nil, total, nil
+	}
+	end := offset + limit
+	if end > len(result) {
+		end = len(result)
+	}
+	return result[offset:end], total, nil
+}
+
+// ── Mock NotificationRepository ──
+
+type mockNotificationRepo struct {
+	notifications []model.Notification
+}
+
+func newMockNotificationRepo() *mockNotificationRepo {
+	return &mockNotificationRepo{}
+}
+
+func (m *mockNotificationRepo) BatchCreate(_ context.Context, notifications []model.Notification) error {
+	for i := range notifications {
+		notifications[i].NotificationID = fmt.Sprintf("notif-%d", len(m.notifications)+1)
+		m.notifications = append(m.notifications, notifications[i])
+	}
+	return nil
+}
+
+func (m *mockNotificationRepo) ListByUser(_ context.Context, userID string, unreadOnly bool, offset, limit int) ([]model.Notification, int64, error) {
+	var filtered []model.Notification
+	for _, n := range m.notifications {
+		if n.UserID == userID && (!unreadOnly || !n.IsRead) {
+			filtered = append(filtered, n)
+		}
+	}
+	total := int64(len(filtered))
+	if offset >= len(filtered) {
+		return nil, total, nil
+	}
+	end := offset + limit
+	if end > len(filtered) {
+		end = len(filtered)
+	}
+	return filtered[offset:end], total, nil
+}
+
+func (m *mockNotificationRepo) MarkRead(_ context.Context, id, userID string) (int64, error) {
+	for i := range m.notifications {
+		if m.notifications[i].NotificationID == id && m.notifications[i].UserID == userID {
+			m.notifications[i].IsRead = true
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func (m *mockNotificationRepo) MarkAllRead(_ context.Context, userID string) error {
+	for i := range m.notifications {
+		if m.notifications[i].UserID == userID {
+			m.notifications[i].IsRead = true
+		}
+	}
+	return nil
+}
+
+// ── Mock ScheduleConflictRepository ──
+
+type mockScheduleConflictRepo struct {
+	conflicts map[string]*model.ScheduleConflict
+	idCounter int
+}
+
+func newMockScheduleConflictRepo() *mockScheduleConflictRepo {
+	return &mockScheduleConflictRepo{conflicts: make(map[string]*model.ScheduleConflict)}
+}
+
+func (m *mockScheduleConflictRepo) Create(_ context.Context, conflict *model.ScheduleConflict) error {
+	m.idCounter++
+	conflict.ScheduleConflictID = fmt.Sprintf("conflict-%d", m.idCounter)
+	conflict.CreatedAt = time.Now()
+	cp := *conflict
+	m.conflicts[cp.ScheduleConflictID] = &cp
+	return nil
+}
+
+func (m *mockScheduleConflictRepo) GetByID(_ context.Context, id string) (*model.ScheduleConflict, error) {
+	if c, ok := m.conflicts[id]; ok {
+		cp := *c
+		return &cp, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *mockScheduleConflictRepo) ListOpenByMember(_ context.Context, scheduleID, memberID string) ([]model.ScheduleConflict, error) {
+	var result []model.ScheduleConflict
+	for _, c := range m.conflicts {
+		if c.ScheduleID == scheduleID && c.MemberID == memberID && c.Status == model.ScheduleConflictOpen {
+			result = append(result, *c)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockScheduleConflictRepo) ListWithFilters(_ context.Context, filters *repository.ScheduleConflictFilters, offset, limit int) ([]model.ScheduleConflict, int64, error) {
+	var filtered []model.ScheduleConflict
+	for _, c := range m.conflicts {
+		if filters != nil {
+			if (filters.ScheduleID != "" && c.ScheduleID != filters.ScheduleID) ||
+				(filters.MemberID != "" && c.MemberID != filters.MemberID) ||
+				(filters.Status != "" && c.Status != filters.Status) {
+				continue
+			}
+		}
+		filtered = append(filtered, *c)
+	}
+	sort.Slice(filtered, func(i, j int) bool { return filtered[i].ScheduleConflictID < filtered[j].ScheduleConflictID })
+	total := int64(len(filtered))
+	if offset >= len(filtered) {
+		return nil, total, nil
+	}
+	end := offset + limit
+	if end > len(filtered) {
+		end = len(filtered)
+	}
+	return filtered[offset:end], total, nil
+}
+
+func (m *mockScheduleConflictRepo) Close(_ context.Context, id, status, note, closedBy string) (int64, error) {
+	c, ok := m.conflicts[id]
+	if !ok || c.Status != model.ScheduleConflictOpen {
+		return 0, nil
+	}
+	now := time.Now()
+	c.Status = status
+	c.ResolutionNote = note
+	c.ResolvedAt = &now
+	c.ResolvedBy = &closedBy
+	return 1, nil
+}
+
+func (m *mockScheduleConflictRepo) CloseOpenByItem(ctx context.Context, scheduleItemID, note, closedBy string) error {
+	for id, c := range m.conflicts {
+		if c.ScheduleItemID == scheduleItemID {
+			if _, err := m.Close(ctx, id, model.ScheduleConflictResolved, note, closedBy); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ── Mock EventRepository / EventShiftRepository / EventAssignmentRepository ──
+
+type mockEventRepo struct {
+	events    map[string]*model.Event
+	idCounter int
+}
+
+func newMockEventRepo() *mockEventRepo {
+	return &mockEventRepo{events: make(map[string]*model.Event)}
+}
+
+func (m *mockEventRepo) Create(_ context.Context, event *model.Event) error {
+	m.idCounter++
+	event.EventID = fmt.Sprintf("event-%d", m.idCounter)
+	m.events[event.EventID] = event
+	return nil
+}
+
+func (m *mockEventRepo) GetByID(_ context.Context, id string) (*model.Event, error) {
+	if e, ok := m.events[id]; ok {
+		cp := *e
+		return &cp, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *mockEventRepo) List(_ context.Context, filters *repository.EventFilters) ([]model.Event, error) {
+	var result []model.Event
+	for _, e := range m.events {
+		if (filters.SemesterID == "" || e.SemesterID == filters.SemesterID) &&
+			(filters.Status == "" || e.Status == filters.Status) &&
+			!(filters.ExcludeDraft && e.Status == model.EventStatusDraft) {
+			result = append(result, *e)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].EventID < result[j].EventID })
+	return result, nil
+}
+
+func (m *mockEventRepo) Update(_ context.Context, event *model.Event) error {
+	cp := *event
+	m.events[event.EventID] = &cp
+	return nil
+}
+
+func (m *mockEventRepo) Delete(_ context.Context, id string, _ string) error {
+	delete(m.events, id)
+	return nil
+}
+
+type mockEventShiftRepo struct {
+	shifts      map[string]*model.EventShift
+	events      *mockEventRepo
+	assignments *mockEventAssignmentRepo
+	idCounter   int
+}
+
+func newMockEventShiftRepo(events *mockEventRepo) *mockEventShiftRepo {
+	return &mockEventShiftRepo{shifts: make(map[string]*model.EventShift), events: events}
+}
+
+// withRelations 返回班次副本并回填活动与人员（模拟 Preload）
+func (m *mockEventShiftRepo) withRelations(shift *model.EventShift) model.EventShift {
+	cp := *shift
+	cp.Event = m.events.events[shift.EventID]
+	cp.Assignments = nil
+	for _, a := range m.assignments.assignments {
+		if a.EventShiftID == shift.EventShiftID {
+			cp.Assignments = append(cp.Assignments, a)
+		}
+	}
+	return cp
+}
+
+func (m *mockEventShiftRepo) Create(_ context.Context, shift *model.EventShift) error {
+	m.idCounter++
+	shift.EventShiftID = fmt.Sprintf("shift-%d", m.idCounter)
+	cp := *shift
+	m.shifts[shift.EventShiftID] = &cp
+	return nil
+}
+
+func (m *mockEventShiftRepo) GetByID(_ context.Context, id string) (*model.EventShift, error) {
+	shift, ok := m.shifts[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	cp := m.withRelations(shift)
+	return &cp, nil
+}
+
+func (m *mockEventShiftRepo) ListByEvent(_ context.Context, eventID string) ([]model.EventShift, error) {
+	var result []model.EventShift
+	for _, shift := range m.shifts {
+		if shift.EventID == eventID {
+			cp := m.withRelations(shift)
+			cp.Event = nil
+			result = append(result, cp)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].ShiftDate.Equal(result[j].ShiftDate) {
+			return result[i].ShiftDate.Before(result[j].ShiftDate)
+		}
+		return result[i].StartTime < result[j].StartTime
+	})
+	return result, nil
+}
+
+func (m *mockEventShiftRepo) Update(_ context.Context, shift *model.EventShift) error {
+	cp := *shift
+	cp.Event, cp.Assignments = nil, nil
+	m.shifts[shift.EventShiftID] = &cp
+	return nil
+}
+
+func (m *mockEventShiftRepo) Delete(_ context.Context, id string, _ string) error {
+	delete(m.shifts, id)
+	return nil
+}
+
+type mockEventAssignmentRepo struct {
+	assignments []model.EventAssignment
+	shifts      *mockEventShiftRepo
+	idCounter   int
+}
+
+func newMockEventAssignmentRepo(shifts *mockEventShiftRepo) *mockEventAssignmentRepo {
+	m := &mockEventAssignmentRepo{shifts: shifts}
+	shifts.assignments = m
+	return m
+}
+
+func (m *mockEventAssignmentRepo) Create(_ context.Context, assignment *model.EventAssignment) error {
+	for _, a := range m.assignments {
+		if a.EventShiftID == assignment.EventShiftID && a.MemberID == assignment.MemberID {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+	m.idCounter++
+	assignment.EventAssignmentID = fmt.Sprintf("ea-%d", m.idCounter)
+	m.assignments = append(m.assignments, *assignment)
+	return nil
+}
+
+func (m *mockEventAssignmentRepo) Delete(_ context.Context, shiftID, memberID string) error {
+	kept := m.assignments[:0]
+	for _, a := range m.assignments {
+		if !(a.EventShiftID == shiftID && a.MemberID == memberID) {
+			kept = append(kept, a)
+		}
+	}
+	m.assignments = kept
+	return nil
+}
+
+func (m *mockEventAssignmentRepo) DeleteByShift(_ context.Context, shiftID string) error {
+	kept := m.assignments[:0]
+	for _, a := range m.assignments {
+		if a.EventShiftID != shiftID {
+			kept = append(kept, a)
+		}
+	}
+	m.assignments = kept
+	return nil
+}
+
+func (m *mockEventAssignmentRepo) ListActiveBySemester(_ context.Context, semesterID string) ([]model.EventAssignment, error) {
+	var result []model.EventAssignment
+	for _, a := range m.assignments {
+		shift, ok := m.shifts.shifts[a.EventShiftID]
+		if !ok {
+			continue
+		}
+		event, ok := m.shifts.events.events[shift.EventID]
+		if !ok || event.SemesterID != semesterID || event.Status == model.EventStatusCancelled {
+			continue
+		}
+		a.EventShift = shift
+		result = append(result, a)
+	}
+	return result, nil
+}
+
+func (m *mockEventAssignmentRepo) ListByMemberFrom(_ context.Context, memberID string, from time.Time) ([]model.EventAssignment, error) {
+	var result []model.EventAssignment
+	for _, a := range m.assignments {
+		shift, ok := m.shifts.shifts[a.EventShiftID]
+		if !ok || a.MemberID != memberID || shift.ShiftDate.Before(from) {
+			continue
+		}
+		event, ok := m.shifts.events.events[shift.EventID]
+		if !ok || event.Status != model.EventStatusPublished {
+			continue
+		}
+		cp := *shift
+		cp.Event = event
+		a.EventShift = &cp
+		result = append(result, a)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].EventShift.ShiftDate.Before(result[j].EventShift.ShiftDate) })
+	return result, nil
+}
+
+// ── Mock PairConstraintRepository ──
+
+type mockPairConstraintRepo struct {
+	constraints []model.PairConstraint
+	users       *mockUserRepo
+	idCounter   int
+}
+
+func newMockPairConstraintRepo(users *mockUserRepo) *mockPairConstraintRepo {
+	return &mockPairConstraintRepo{users: users}
+}
+
+func (m *mockPairConstraintRepo) Create(_ context.Context, constraint *model.PairConstraint) error {
+	m.idCounter++
+	constraint.PairConstraintID = fmt.Sprintf("pair-%d", m.idCounter)
+	m.constraints = append(m.constraints, *constraint)
+	return nil
+}
+
+func (m *mockPairConstraintRepo) GetByID(_ context.Context, id string) (*model.PairConstraint, error) {
+	for _, c := range m.constraints {
+		if c.PairConstraintID == id {
+			m.preload(&c)
+			return &c, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *mockPairConstraintRepo) ListBySemester(_ context.Context, semesterID string) ([]model.PairConstraint, error) {
+	var result []model.PairConstraint
+	for _, c := range m.constraints {
+		if c.SemesterID == semesterID {
+			m.preload(&c)
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockPairConstraintRepo) Delete(_ context.Context, id string, _ string) error {
+	kept := m.constraints[:0]
+	for _, c := range m.constraints {
+		if c.PairConstraintID != id {
+			kept = append(kept, c)
+		}
+	}
+	m.constraints = kept
+	return nil
+}
+
+func (m *mockPairConstraintRepo) preload(c *model.PairConstraint) {
+	if m.users == nil {
+		return
+	}
+	c.MemberA = m.users.users[c.MemberAID]
+	c.MemberB = m.users.users[c.MemberBID]
+}
+
+// ── Mock SkillRepository ──
+
+type mockSkillRepo struct {
+	skills     map[string]*model.Skill
+	userSkills []model.UserSkill
+	users      *mockUserRepo
+	timeSlots  *mockTimeSlotRepo
+	shifts     *mockEventShiftRepo
+	idCounter  int
+}
+
+func newMockSkillRepo(users *mockUserRepo, timeSlots *mockTimeSlotRepo, shifts *mockEventShiftRepo) *mockSkillRepo {
+	return &mockSkillRepo{skills: make(map[string]*model.Skill), users: users, timeSlots: timeSlots, shifts: shifts}
+}
+
+func (m *mockSkillRepo) Create(_ context.Context, skill *model.Skill) error {
+	m.idCounter++
+	skill.SkillID = fmt.Sprintf("skill-%d", m.idCounter)
+	cp := *skill
+	m.skills[skill.SkillID] = &cp
+	return nil
+}
+
+func (m *mockSkillRepo) GetByID(_ context.Context, id string) (*model.Skill, error) {
+	if s, ok := m.skills[id]; ok {
+		cp := *s
+		return &cp, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *mockSkillRepo) GetByName(_ context.Context, name string) (*model.Skill, error) {
+	for _, s := range m.skills {
+		if s.Name == name {
+			cp := *s
+			return &cp, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *mockSkillRepo) List(_ context.Context) ([]model.Skill, error) {
+	var result []model.Skill
+	for _, s := range m.skills {
+		result = append(result, *s)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+func (m *mockSkillRepo) ListByIDs(_ context.Context, ids []string) ([]model.Skill, error) {
+	var result []model.Skill
+	for _, id := range ids {
+		if s, ok := m.skills[id]; ok {
+			result = append(result, *s)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+func (m *mockSkillRepo) Update(_ context.Context, skill *model.Skill) error {
+	cp := *skill
+	m.skills[skill.SkillID] = &cp
+	return nil
+}
+
+func (m *mockSkillRepo) Delete(_ context.Context, id string, _ string) error {
+	delete(m.skills, id)
+	kept := m.userSkills[:0]
+	for _, us := range m.userSkills {
+		if us.SkillID != id {
+			kept = append(kept, us)
+		}
+	}
+	m.userSkills = kept
+	return nil
+}
+
+func (m *mockSkillRepo) ListHolders(_ context.Context, skillIDs []string) ([]model.UserSkill, error) {
+	wanted := make(map[string]bool, len(skillIDs))
+	for _, id := range skillIDs {
+		wanted[id] = true
+	}
+	var result []model.UserSkill
+	for _, us := range m.userSkills {
+		if wanted[us.SkillID] {
+			result = append(result, us)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockSkillRepo) ReplaceUserSkills(_ context.Context, userID string, skills []model.UserSkill) error {
+	kept := m.userSkills[:0]
+	for _, us := range m.userSkills {
+		if us.UserID != userID {
+			kept = append(kept, us)
+		}
+	}
+	m.userSkills = append(kept, skills...)
+	if m.users != nil {
+		if u, ok := m.users.users[userID]; ok {
+			u.Skills = m.withSkill(skills)
+		}
+	}
+	return nil
+}
+
+func (m *mockSkillRepo) ReplaceTimeSlotSkills(_ context.Context, timeSlotID string, skills []model.TimeSlotSkill) error {
+	if m.timeSlots != nil {
+		if ts, ok := m.timeSlots.slots[timeSlotID]; ok {
+			ts.RequiredSkills = make([]model.TimeSlotSkill, 0, len(skills))
+			for _, req := range skills {
+				req.Skill = m.skills[req.SkillID]
+				ts.RequiredSkills = append(ts.RequiredSkills, req)
+			}
+		}
+	}
+	return nil
+}
+
+func (m *mockSkillRepo) ReplaceEventShiftSkills(_ context.Context, shiftID string, skills []model.EventShiftSkill) error {
+	if m.shifts != nil {
+		if shift, ok := m.shifts.shifts[shiftID]; ok {
+			shift.RequiredSkills = make([]model.EventShiftSkill, 0, len(skills))
+			for _, req := range skills {
+				req.Skill = m.skills[req.SkillID]
+				shift.RequiredSkills = append(shift.RequiredSkills, req)
+			}
+		}
+	}
+	return nil
+}
+
+// withSkill 回填技能详情（模拟 Preload("Skills.Skill")）
+func (m *mockSkillRepo) withSkill(skills []model.UserSkill) []model.UserSkill {
+	result := make([]model.UserSkill, 0, len(skills))
+	for _, us := range skills {
+		us.Skill = m.skills[us.SkillID]
+		result = append(result, us)
+	}
+	return result
+}
+
+// seedSkill 直接写入技能目录
+func (m *mockSkillRepo) seedSkill(id, name string) *model.Skill {
+	skill := &model.Skill{SkillID: id, Name: name}
+	m.skills[id] = skill
+	return skill
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/model"
+	"echo-union/backend/internal/repository"
+)
+
+// ── 通知模块业务错误 ──
+
+var (
+	ErrNotificationNotFound = errors.New("通知不存在")
+)
+
+// NotificationService 通知消息业务接口
+type NotificationService interface {
+	List(ctx context.Context, userID string, req *dto.NotificationListRequest) ([]dto.NotificationResponse, int64, error)
+	MarkRead(ctx context.Context, id, userID string) error
+	MarkAllRead(ctx context.Context, userID string) error
+}
+
+type notificationService struct {
+	repo   *repository.Repository
+	logger *zap.Logger
+}
+
+// NewNotificationService 创建 NotificationService 实例
+func NewNotificationService(repo *repository.Repository, logger *zap.Logger) NotificationService {
+	return &notificationService{repo: repo, logger: logger}
+}
+
+func (s *notificationService) List(ctx context.Context, userID string, req *dto.NotificationListRequest) ([]dto.NotificationResponse, int64, error) {
+	offset := (req.GetPage() - 1) * req.GetPageSize()
+	notifications, total, err := s.repo.Notification.ListByUser(ctx, userID, req.UnreadOnly, offset, req.GetPageSize())
+	if err != nil {
+		s.logger.Error("查询通知失败", zap.Error(err))
+		return nil, 0, err
+	}
+
+	result := make([]dto.NotificationResponse, 0, len(notifications))
+	for _, n := range notifications {
+		result = append(result, dto.NotificationResponse{
+			ID:          n.NotificationID,
+			Type:        n.Type,
+			Title:       n.Title,
+			Content:     n.Content,
+			IsRead:      n.IsRead,
+			RelatedType: n.RelatedType,
+			RelatedID:   n.RelatedID,
+			CreatedAt:   n.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		})
+	}
+	return result, total, nil
+}
+
+func (s *notificationService) MarkRead(ctx context.Context, id, userID string) error {
+	affected, err := s.repo.Notification.MarkRead(ctx, id, userID)
+	if err != nil {
+		s.logger.Error("标记通知已读失败", zap.Error(err))
+		return err
+	}
+	if affected == 0 {
+		return ErrNotificationNotFound
+	}
+	return nil
+}
+
+func (s *notificationService) MarkAllRead(ctx context.Context, userID string) error {
+	if err := s.repo.Notification.MarkAllRead(ctx, userID); err != nil {
+		s.logger.Error("标记全部通知已读失败", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// adminUserIDs 查询管理员用户 ID，用于业务事件通知
+func adminUserIDs(ctx context.Context, repo *repository.Repository) ([]string, error) {
+	admins, _, err := repo.User.ListWithFilters(ctx, &repository.UserListFilters{Role: model.RoleAdmin}, 0, 100)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(admins))
+	for _, u := range admins {
+		ids = append(ids, u.UserID)
+	}
+	return ids, nil
+}
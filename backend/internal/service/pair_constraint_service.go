@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/model"
+	"echo-union/backend/internal/repository"
+)
+
+// ── 成员搭配约束模块业务错误 ──
+
+var (
+	ErrPairConstraintNotFound  = errors.New("搭配约束不存在")
+	ErrPairConstraintSelf      = errors.New("搭配约束的两名成员不能相同")
+	ErrPairConstraintDuplicate = errors.New("该两名成员已存在搭配约束")
+	ErrPairConstraintNoSlots   = errors.New("本学期没有时间重叠的并行时段，must_pair 约束无法满足")
+	ErrPairConstraintNotDuty   = errors.New("搭配约束的成员须为本学期值班成员")
+)
+
+// PairConstraintService 成员搭配约束（R7）业务接口
+type PairConstraintService interface {
+	List(ctx context.Context, semesterID string) ([]dto.PairConstraintResponse, error)
+	Create(ctx context.Context, semesterID string, req *dto.CreatePairConstraintRequest, callerID string) (*dto.PairConstraintResponse, error)
+	Delete(ctx context.Context, semesterID, id, callerID string) error
+}
+
+type pairConstraintService struct {
+	repo   *repository.Repository
+	logger *zap.Logger
+}
+
+// NewPairConstraintService 创建 PairConstraintService 实例
+func NewPairConstraintService(repo *repository.Repository, logger *zap.Logger) PairConstraintService {
+	return &pairConstraintService{repo: repo, logger: logger}
+}
+
+// ────────────────────── List ──────────────────────
+
+func (s *pairConstraintService) List(ctx context.Context, semesterID string) ([]dto.PairConstraintResponse, error) {
+	if err := s.checkSemester(ctx, semesterID); err != nil {
+		return nil, err
+	}
+	constraints, err := s.repo.PairConstraint.ListBySemester(ctx, semesterID)
+	if err != nil {
+		s.logger.Error("查询搭配约束失败", zap.Error(err))
+		return nil, err
+	}
+	result := make([]dto.PairConstraintResponse, 0, len(constraints))
+	for i := range constraints {
+		result = append(result, toPairConstraintResponse(&constraints[i]))
+	}
+	return result, nil
+}
+
+// ────────────────────── Create ──────────────────────
+
+// Create 新增搭配约束；两名成员须为本学期值班成员，同一学期同一对成员（与顺序无关）只允许一条。
+// 每个时段只排一人，must_pair 需要本学期存在同日时间重叠的并行时段，否则拒绝创建
+func (s *pairConstraintService) Create(ctx context.Context, semesterID string, req *dto.CreatePairConstraintRequest, callerID string) (*dto.PairConstraintResponse, error) {
+	if req.MemberAID == req.MemberBID {
+		return nil, ErrPairConstraintSelf
+	}
+	if err := s.checkSemester(ctx, semesterID); err != nil {
+		return nil, err
+	}
+	for _, id := range []string{req.MemberAID, req.MemberBID} {
+		if _, err := s.repo.User.GetByID(ctx, id); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrUserNotFound
+			}
+			return nil, err
+		}
+		assignment, err := s.repo.UserSemesterAssignment.GetByUserAndSemester(ctx, id, semesterID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			s.logger.Error("查询学期分配失败", zap.Error(err))
+			return nil, err
+		}
+		if assignment == nil || !assignment.DutyRequired {
+			return nil, ErrPairConstraintNotDuty
+		}
+	}
+
+	existing, err := s.repo.PairConstraint.ListBySemester(ctx, semesterID)
+	if err != nil {
+		s.logger.Error("查询搭配约束失败", zap.Error(err))
+		return nil, err
+	}
+	for _, c := range existing {
+		if (c.MemberAID == req.MemberAID && c.MemberBID == req.MemberBID) ||
+			(c.MemberAID == req.MemberBID && c.MemberBID == req.MemberAID) {
+			return nil, ErrPairConstraintDuplicate
+		}
+	}
+
+	if req.Kind == model.PairMustPair {
+		if err := s.checkConcurrentSlots(ctx, semesterID); err != nil {
+			return nil, err
+		}
+	}
+
+	constraint := &model.PairConstraint{
+		SemesterID: semesterID,
+		MemberAID:  req.MemberAID,
+		MemberBID:  req.MemberBID,
+		Kind:       req.Kind,
+		Note:       req.Note,
+	}
+	constraint.CreatedBy = &callerID
+	constraint.UpdatedBy = &callerID
+	if err := s.repo.PairConstraint.Create(ctx, constraint); err != nil {
+		s.logger.Error("创建搭配约束失败", zap.Error(err))
+		return nil, err
+	}
+
+	created, err := s.repo.PairConstraint.GetByID(ctx, constraint.PairConstraintID)
+	if err != nil {
+		return nil, err
+	}
+	resp := toPairConstraintResponse(created)
+	return &resp, nil
+}
+
+// ────────────────────── Delete ──────────────────────
+
+func (s *pairConstraintService) Delete(ctx context.Context, semesterID, id, callerID string) error {
+	constraint, err := s.repo.PairConstraint.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrPairConstraintNotFound
+		}
+		return err
+	}
+	if constraint.SemesterID != semesterID {
+		return ErrPairConstraintNotFound
+	}
+	if err := s.repo.PairConstraint.Delete(ctx, id, callerID); err != nil {
+		s.logger.Error("删除搭配约束失败", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// ── 内部方法 ──
+
+func (s *pairConstraintService) checkSemester(ctx context.Context, semesterID string) error {
+	if _, err := s.repo.Semester.GetByID(ctx, semesterID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSemesterNotFound
+		}
+		s.logger.Error("查询学期失败", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// checkConcurrentSlots 学期内须至少有两个同日时间重叠的启用时段，must_pair 才可能满足
+func (s *pairConstraintService) checkConcurrentSlots(ctx context.Context, semesterID string) error {
+	timeSlots, err := s.repo.TimeSlot.List(ctx, semesterID, nil)
+	if err != nil {
+		s.logger.Error("查询时间段失败", zap.Error(err))
+		return err
+	}
+	for i := range timeSlots {
+		for j := i + 1; j < len(timeSlots); j++ {
+			if slotsConcurrent(timeSlots[i], timeSlots[j]) {
+				return nil
+			}
+		}
+	}
+	return ErrPairConstraintNoSlots
+}
+
+func toPairConstraintResponse(c *model.PairConstraint) dto.PairConstraintResponse {
+	resp := dto.PairConstraintResponse{
+		ID:         c.PairConstraintID,
+		SemesterID: c.SemesterID,
+		MemberA:    toMemberBrief(c.MemberA),
+		MemberB:    toMemberBrief(c.MemberB),
+		Kind:       c.Kind,
+		Note:       c.Note,
+		CreatedAt:  c.CreatedAt.Format(model.TimeFormatDateTime),
+	}
+	if resp.MemberA == nil {
+		resp.MemberA = &dto.MemberBrief{ID: c.MemberAID}
+	}
+	if resp.MemberB == nil {
+		resp.MemberB = &dto.MemberBrief{ID: c.MemberBID}
+	}
+	return resp
+}
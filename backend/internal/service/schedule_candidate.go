@@ -0,0 +1,369 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/model"
+)
+
+// ── 候选方案 ──
+//
+// 通过 AutoScheduleRequest.AsCandidate 生成的排班表处于 candidate 状态，
+// 不占用学期唯一活跃排班名额；管理员对比质量报告后将其中一个提升为草稿。
+
+// maxScheduleCandidates 每学期最多保留的候选方案数
+const maxScheduleCandidates = 10
+
+// ════════════════════════════════════════════════════════════
+// ListCandidates — 候选方案列表
+// ════════════════════════════════════════════════════════════
+
+func (s *scheduleService) ListCandidates(ctx context.Context, semesterID string) ([]dto.ScheduleSummaryResponse, error) {
+	if _, err := s.repo.Semester.GetByID(ctx, semesterID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSemesterNotFound
+		}
+		s.logger.Error("查询学期失败", zap.Error(err))
+		return nil, err
+	}
+
+	schedules, err := s.repo.Schedule.ListBySemesterAndStatus(ctx, semesterID, model.ScheduleStatusCandidate)
+	if err != nil {
+		s.logger.Error("查询候选方案失败", zap.Error(err))
+		return nil, err
+	}
+
+	result := make([]dto.ScheduleSummaryResponse, 0, len(schedules))
+	for i := range schedules {
+		summary, err := s.buildScheduleSummary(ctx, &schedules[i])
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *summary)
+	}
+	return result, nil
+}
+
+// ════════════════════════════════════════════════════════════
+// CompareSchedules — 多方案质量对比
+// ════════════════════════════════════════════════════════════
+
+func (s *scheduleService) CompareSchedules(ctx context.Context, req *dto.CompareSchedulesRequest) ([]dto.ScheduleSummaryResponse, error) {
+	schedules := make([]*model.Schedule, 0, len(req.ScheduleIDs))
+	for _, id := range req.ScheduleIDs {
+		schedule, err := s.repo.Schedule.GetByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrScheduleNotFound
+			}
+			s.logger.Error("查询排班表失败", zap.Error(err))
+			return nil, err
+		}
+		if len(schedules) > 0 && schedule.SemesterID != schedules[0].SemesterID {
+			return nil, ErrScheduleSemesterMismatch
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	result := make([]dto.ScheduleSummaryResponse, 0, len(schedules))
+	for _, schedule := range schedules {
+		summary, err := s.buildScheduleSummary(ctx, schedule)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *summary)
+	}
+	return result, nil
+}
+
+// ════════════════════════════════════════════════════════════
+// PromoteCandidate — 候选方案提升为草稿
+// ════════════════════════════════════════════════════════════
+
+func (s *scheduleService) PromoteCandidate(ctx context.Context, scheduleID, callerID string) (*dto.ScheduleResponse, error) {
+	candidate, err := s.repo.Schedule.GetByID(ctx, scheduleID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrScheduleNotFound
+		}
+		s.logger.Error("查询排班表失败", zap.Error(err))
+		return nil, err
+	}
+	if candidate.Status != model.ScheduleStatusCandidate {
+		return nil, ErrScheduleNotCandidate
+	}
+
+	existing, err := s.repo.Schedule.GetBySemester(ctx, candidate.SemesterID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		s.logger.Error("查询已有排班表失败", zap.Error(err))
+		return nil, err
+	}
+	if existing != nil && existing.Status == model.ScheduleStatusPublished {
+		return nil, ErrSchedulePublishedExists
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		s.logger.Error("开启事务失败", zap.Error(err))
+		return nil, err
+	}
+	rollbackTx := func() {
+		if tx != nil {
+			tx.Rollback()
+		}
+	}
+	txRepo := s.repo.WithTx(tx)
+
+	// 先让出活跃名额：当前草稿降为候选方案（便于继续对比），need_regen 直接归档
+	if existing != nil {
+		if existing.Status == model.ScheduleStatusDraft {
+			existing.Status = model.ScheduleStatusCandidate
+		} else {
+			existing.Status = model.ScheduleStatusArchived
+		}
+		existing.UpdatedBy = &callerID
+		if err := txRepo.Schedule.Update(ctx, existing); err != nil {
+			rollbackTx()
+			s.logger.Error("更新原排班表状态失败", zap.Error(err))
+			return nil, err
+		}
+	}
+
+	candidate.Status = model.ScheduleStatusDraft
+	candidate.UpdatedBy = &callerID
+	if err := txRepo.Schedule.Update(ctx, candidate); err != nil {
+		rollbackTx()
+		s.logger.Error("提升候选方案失败", zap.Error(err))
+		return nil, err
+	}
+
+	if tx != nil {
+		if err := tx.Commit().Error; err != nil {
+			s.logger.Error("提交事务失败", zap.Error(err))
+			return nil, err
+		}
+	}
+
+	s.logger.Info("候选方案已提升为草稿",
+		zap.String("schedule_id", scheduleID),
+		zap.String("caller_id", callerID),
+	)
+
+	return s.buildScheduleResponse(ctx, candidate)
+}
+
+// ════════════════════════════════════════════════════════════
+// DiscardCandidate — 丢弃候选方案
+// ════════════════════════════════════════════════════════════
+
+func (s *scheduleService) DiscardCandidate(ctx context.Context, scheduleID, callerID string) error {
+	schedule, err := s.repo.Schedule.GetByID(ctx, scheduleID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrScheduleNotFound
+		}
+		s.logger.Error("查询排班表失败", zap.Error(err))
+		return err
+	}
+	if schedule.Status != model.ScheduleStatusCandidate {
+		return ErrScheduleNotCandidate
+	}
+
+	if err := s.repo.Schedule.Delete(ctx, scheduleID, callerID); err != nil {
+		s.logger.Error("删除候选方案失败", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// ════════════════════════════════════════════════════════════
+// GetQualityReport — 方案质量报告
+// ════════════════════════════════════════════════════════════
+
+func (s *scheduleService) GetQualityReport(ctx context.Context, scheduleID string) (*dto.ScheduleQualityReport, error) {
+	schedule, err := s.repo.Schedule.GetByID(ctx, scheduleID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrScheduleNotFound
+		}
+		s.logger.Error("查询排班表失败", zap.Error(err))
+		return nil, err
+	}
+	return s.evaluateSchedule(ctx, schedule)
+}
+
+// buildScheduleSummary 构建方案摘要（含质量报告）
+func (s *scheduleService) buildScheduleSummary(ctx context.Context, schedule *model.Schedule) (*dto.ScheduleSummaryResponse, error) {
+	quality, err := s.evaluateSchedule(ctx, schedule)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.ScheduleSummaryResponse{
+		ID:            schedule.ScheduleID,
+		SemesterID:    schedule.SemesterID,
+		Status:        schedule.Status,
+		Name:          schedule.Name,
+		Solver:        schedule.Solver,
+		Seed:          schedule.Seed,
+		RuleOverrides: schedule.RuleOverrides,
+		Quality:       quality,
+		CreatedAt:     schedule.CreatedAt.Format(model.TimeFormatDateTime),
+	}, nil
+}
+
+// evaluateSchedule 按当前全局规则评估排班方案质量。
+// 统一使用全局规则（忽略方案生成时的规则覆盖），保证不同方案之间的对比口径一致。
+func (s *scheduleService) evaluateSchedule(ctx context.Context, schedule *model.Schedule) (*dto.ScheduleQualityReport, error) {
+	input, assignments, _, err := s.loadScheduleAssignments(ctx, schedule)
+	if err != nil {
+		return nil, err
+	}
+	return input.qualityReport(assignments), nil
+}
+
+// loadScheduleAssignments 加载方案的求解输入（候选人取成员快照）与排班项
+func (s *scheduleService) loadScheduleAssignments(ctx context.Context, schedule *model.Schedule) (*solverInput, []scheduleAssignment, []model.ScheduleItem, error) {
+	semester := schedule.Semester
+	if semester == nil {
+		var err error
+		semester, err = s.repo.Semester.GetByID(ctx, schedule.SemesterID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, nil, nil, ErrSemesterNotFound
+			}
+			s.logger.Error("查询学期失败", zap.Error(err))
+			return nil, nil, nil, err
+		}
+	}
+
+	timeSlots, err := s.repo.TimeSlot.List(ctx, schedule.SemesterID, nil)
+	if err != nil {
+		s.logger.Error("查询时间段失败", zap.Error(err))
+		return nil, nil, nil, err
+	}
+	input, err := s.loadSolverConstraints(ctx, semester, timeSlots, nil)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	snapshots, err := s.repo.ScheduleMemberSnapshot.ListBySchedule(ctx, schedule.ScheduleID)
+	if err != nil {
+		s.logger.Error("查询成员快照失败", zap.Error(err))
+		return nil, nil, nil, err
+	}
+	for _, snap := range snapshots {
+		input.candidates = append(input.candidates, scheduleCandidate{userID: snap.UserID, departmentID: snap.DepartmentID})
+	}
+
+	items, err := s.repo.ScheduleItem.ListBySchedule(ctx, schedule.ScheduleID)
+	if err != nil {
+		s.logger.Error("查询排班项失败", zap.Error(err))
+		return nil, nil, nil, err
+	}
+	assignments := make([]scheduleAssignment, 0, len(items))
+	for _, item := range items {
+		assignments = append(assignments, scheduleAssignment{
+			weekNumber: item.WeekNumber,
+			timeSlotID: item.TimeSlotID,
+			memberID:   item.MemberID,
+		})
+	}
+
+	return input, assignments, items, nil
+}
+
+// qualityReport 基于内存数据计算方案质量报告
+func (in *solverInput) qualityReport(assignments []scheduleAssignment) *dto.ScheduleQualityReport {
+	report := &dto.ScheduleQualityReport{
+		TotalSlots:  len(in.slots),
+		FilledSlots: len(assignments),
+		MemberCount: len(in.candidates),
+		Violations:  make([]dto.RuleViolationSummary, 0),
+	}
+	if report.TotalSlots > 0 {
+		report.FillRate = math.Round(float64(report.FilledSlots)/float64(report.TotalSlots)*10000) / 10000
+	}
+
+	// 工作量分布（未排到班次的候选人计 0 次）：班次数、时长与加权成本
+	loads := in.memberLoads(assignments)
+	if len(loads) > 0 {
+		first := true
+		shifts, costs := make([]float64, 0, len(loads)), make([]float64, 0, len(loads))
+		for _, load := range loads {
+			if load.shifts > 0 {
+				report.AssignedCount++
+			}
+			if first || load.shifts < report.MinShifts {
+				report.MinShifts = load.shifts
+			}
+			if first || load.shifts > report.MaxShifts {
+				report.MaxShifts = load.shifts
+			}
+			if first || load.cost < report.MinCost {
+				report.MinCost = load.cost
+			}
+			if first || load.cost > report.MaxCost {
+				report.MaxCost = load.cost
+			}
+			first = false
+			report.TotalHours += load.hours
+			report.TotalCost += load.cost
+			shifts = append(shifts, float64(load.shifts))
+			costs = append(costs, load.cost)
+		}
+		report.ShiftStdDev = round2(stdDev(shifts))
+		report.CostStdDev = round2(stdDev(costs))
+		report.TotalHours = round2(report.TotalHours)
+		report.TotalCost = round2(report.TotalCost)
+		report.MinCost = round2(report.MinCost)
+		report.MaxCost = round2(report.MaxCost)
+	}
+
+	// 硬约束冲突：R1/R2 按当前课表与不可用时间重新判定，R6 按同周同日重复判定，R7 按不同时段 / 不相邻判定，
+	// R8/R9/R10 仅在配置为硬约束时计入
+	slots := in.slotIndex()
+	placed := in.placedSlots(assignments)
+	memberDay := make(map[string]int)
+	for _, a := range assignments {
+		sl, ok := slots[slotKey(a.weekNumber, a.timeSlotID)]
+		if !ok {
+			continue
+		}
+		memberDay[fmt.Sprintf("%s:%d:%d", a.memberID, a.weekNumber, sl.timeSlot.DayOfWeek)]++
+	}
+	for _, a := range assignments {
+		sl, ok := slots[slotKey(a.weekNumber, a.timeSlotID)]
+		if !ok {
+			continue
+		}
+		if !in.availabilityOf(a.memberID, sl).available ||
+			memberDay[fmt.Sprintf("%s:%d:%d", a.memberID, a.weekNumber, sl.timeSlot.DayOfWeek)] > 1 ||
+			hasHardPairViolation(in.pairViolations(a.memberID, sl, placed)) ||
+			hasHardRestViolation(in.restViolations(a.memberID, sl, placed)) {
+			report.HardConflicts++
+		}
+	}
+
+	// 软约束：列出所有已启用的软约束规则（含 0 次），便于并排对比
+	penalty := in.evaluateSoftPenalty(assignments)
+	report.SoftPenalty = penalty.total
+	for _, code := range []string{"R3", "R4", "R5", "R7", "R8", "R9", "R10"} {
+		if !in.rules[code] || in.rest.hard(code) {
+			continue
+		}
+		report.Violations = append(report.Violations, dto.RuleViolationSummary{
+			RuleCode: code,
+			Count:    penalty.violations[code],
+			Penalty:  penalty.penalties[code],
+		})
+	}
+
+	return report
+}
@@ -0,0 +1,307 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/model"
+	"echo-union/backend/internal/repository"
+)
+
+// ── 发布后时间表变更检测 ──
+//
+// 排班发布后成员仍可重新导入课表或维护不可用时间。每次写入后在同一事务内
+// 重新评估成员时间表与其已发布排班项的冲突：
+//   - 新出现的冲突：策略为 block 时拒绝本次变更，否则生成冲突处理任务，
+//     提交后通知管理员处理、提示成员发起换班
+//   - 已消失的冲突：自动关闭对应的待处理任务
+//
+// 临时不可用（once）只影响具体日期，走替班流程（见 duty_substitute.go），不参与此检测。
+
+// timetableClash 成员时间表与已发布排班项的一处冲突
+type timetableClash struct {
+	item   model.ScheduleItem
+	source string // course | unavailable
+	detail string
+}
+
+// detectPublishedClashes 检查成员当前时间表与其在已发布排班中的排班项的冲突。
+// 规则 R1 / R2 停用时对应来源不参与检测；每个排班项只记录第一处冲突。
+func detectPublishedClashes(ctx context.Context, repo *repository.Repository, semester *model.Semester, published []model.Schedule, memberID string) ([]timetableClash, error) {
+	rules := loadEnabledRules(ctx, repo)
+
+	var courses []model.CourseSchedule
+	var unavailables []model.UnavailableTime
+	var err error
+	if rules["R1"] {
+		if courses, err = repo.CourseSchedule.ListByUserAndSemester(ctx, memberID, semester.SemesterID); err != nil {
+			return nil, err
+		}
+	}
+	if rules["R2"] {
+		if unavailables, err = repo.UnavailableTime.ListByUserAndSemester(ctx, memberID, semester.SemesterID); err != nil {
+			return nil, err
+		}
+	}
+	if len(courses) == 0 && len(unavailables) == 0 {
+		return nil, nil
+	}
+
+	var clashes []timetableClash
+	for _, schedule := range published {
+		items, err := repo.ScheduleItem.ListByScheduleAndMember(ctx, schedule.ScheduleID, memberID)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			if item.TimeSlot == nil {
+				continue
+			}
+			if clash, ok := itemTimetableClash(item, weekNumberToType(item.WeekNumber, semester.FirstWeekType), courses, unavailables); ok {
+				clashes = append(clashes, clash)
+			}
+		}
+	}
+	return clashes, nil
+}
+
+// itemTimetableClash 检查单个排班项与课表 / 不可用时间的冲突
+func itemTimetableClash(item model.ScheduleItem, weekType string, courses []model.CourseSchedule, unavailables []model.UnavailableTime) (timetableClash, bool) {
+	ts := item.TimeSlot
+	for _, c := range courses {
+		if hasTimeConflict(c.DayOfWeek, c.StartTime, c.EndTime, c.WeekType, ts.DayOfWeek, ts.StartTime, ts.EndTime, weekType) {
+			return timetableClash{
+				item:   item,
+				source: model.ScheduleConflictSourceCourse,
+				detail: fmt.Sprintf("%s 与课程「%s」冲突", describeItem(&item), c.CourseName),
+			}, true
+		}
+	}
+	for _, ut := range unavailables {
+		if hasUnavailableConflict(ut, ts.DayOfWeek, ts.StartTime, ts.EndTime, weekType) {
+			detail := fmt.Sprintf("%s 与不可用时间冲突", describeItem(&item))
+			if ut.Reason != "" {
+				detail = fmt.Sprintf("%s 与不可用时间「%s」冲突", describeItem(&item), ut.Reason)
+			}
+			return timetableClash{item: item, source: model.ScheduleConflictSourceUnavailable, detail: detail}, true
+		}
+	}
+	return timetableClash{}, false
+}
+
+// timetableConflictPolicy 读取发布后时间表变更策略（配置缺失时按 notify 处理）
+func timetableConflictPolicy(ctx context.Context, repo *repository.Repository) string {
+	cfg, err := repo.SystemConfig.Get(ctx)
+	if err != nil || cfg.TimetableConflictPolicy == "" {
+		return model.TimetableConflictPolicyNotify
+	}
+	return cfg.TimetableConflictPolicy
+}
+
+// reconcilePublishedConflicts 时间表写入后（事务内）同步成员的冲突处理任务。
+// 策略为 block 且出现新冲突时返回 ErrTimetableConflictsPublished，调用方应回滚事务。
+// 返回新生成的任务，由调用方在事务提交后发送通知。
+func (s *timetableService) reconcilePublishedConflicts(ctx context.Context, repo *repository.Repository, semesterID, userID string) ([]model.ScheduleConflict, error) {
+	published, err := repo.Schedule.ListBySemesterAndStatus(ctx, semesterID, model.ScheduleStatusPublished)
+	if err != nil || len(published) == 0 {
+		return nil, err
+	}
+	semester, err := repo.Semester.GetByID(ctx, semesterID)
+	if err != nil {
+		return nil, err
+	}
+	clashes, err := detectPublishedClashes(ctx, repo, semester, published, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 已有的待处理任务：scheduleItemID → 任务
+	open := make(map[string]model.ScheduleConflict)
+	for _, schedule := range published {
+		existing, err := repo.ScheduleConflict.ListOpenByMember(ctx, schedule.ScheduleID, userID)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range existing {
+			open[c.ScheduleItemID] = c
+		}
+	}
+
+	var fresh []timetableClash
+	clashing := make(map[string]bool, len(clashes))
+	for _, clash := range clashes {
+		clashing[clash.item.ScheduleItemID] = true
+		if _, ok := open[clash.item.ScheduleItemID]; !ok {
+			fresh = append(fresh, clash)
+		}
+	}
+
+	if len(fresh) > 0 && timetableConflictPolicy(ctx, repo) == model.TimetableConflictPolicyBlock {
+		details := make([]string, 0, len(fresh))
+		for _, clash := range fresh {
+			details = append(details, clash.detail)
+		}
+		return nil, fmt.Errorf("%w: %s", ErrTimetableConflictsPublished, strings.Join(details, "; "))
+	}
+
+	// 冲突已消失的任务自动关闭
+	for itemID, c := range open {
+		if clashing[itemID] {
+			continue
+		}
+		if _, err := repo.ScheduleConflict.Close(ctx, c.ScheduleConflictID, model.ScheduleConflictResolved, "时间表变更后冲突已消失", userID); err != nil {
+			return nil, err
+		}
+	}
+
+	created := make([]model.ScheduleConflict, 0, len(fresh))
+	for _, clash := range fresh {
+		item := clash.item
+		conflict := model.ScheduleConflict{
+			ScheduleID:     item.ScheduleID,
+			ScheduleItemID: item.ScheduleItemID,
+			MemberID:       userID,
+			Source:         clash.source,
+			Detail:         clash.detail,
+			Status:         model.ScheduleConflictOpen,
+		}
+		conflict.CreatedBy = &userID
+		conflict.UpdatedBy = &userID
+		if err := repo.ScheduleConflict.Create(ctx, &conflict); err != nil {
+			return nil, err
+		}
+		conflict.ScheduleItem = &item
+		created = append(created, conflict)
+	}
+	return created, nil
+}
+
+// notifyScheduleConflicts 通知管理员处理冲突任务，并提示成员发起换班。
+// 通知为尽力而为：失败只记录日志。
+func notifyScheduleConflicts(ctx context.Context, repo *repository.Repository, logger *zap.Logger, conflicts []model.ScheduleConflict) {
+	if len(conflicts) == 0 {
+		return
+	}
+	admins, err := adminUserIDs(ctx, repo)
+	if err != nil {
+		logger.Warn("查询管理员失败，冲突通知未完整发送", zap.Error(err))
+	}
+
+	relatedType := model.NotificationRelatedScheduleItem
+	var notifications []model.Notification
+	for _, c := range conflicts {
+		relatedID := c.ScheduleItemID
+		notifications = append(notifications, model.Notification{
+			UserID:      c.MemberID,
+			Type:        model.NotificationTypeScheduleConflict,
+			Title:       "时间表变更与排班冲突",
+			Content:     fmt.Sprintf("你修改的时间表与已发布排班冲突：%s。请发起换班或联系管理员调整。", c.Detail),
+			RelatedType: &relatedType,
+			RelatedID:   &relatedID,
+		})
+		name := c.MemberID
+		if users, err := repo.User.ListByIDs(ctx, []string{c.MemberID}); err == nil && len(users) > 0 {
+			name = users[0].Name
+		}
+		for _, adminID := range admins {
+			notifications = append(notifications, model.Notification{
+				UserID:      adminID,
+				Type:        model.NotificationTypeScheduleConflict,
+				Title:       "排班冲突待处理",
+				Content:     fmt.Sprintf("%s 修改时间表后与已发布排班冲突：%s", name, c.Detail),
+				RelatedType: &relatedType,
+				RelatedID:   &relatedID,
+			})
+		}
+	}
+
+	if err := repo.Notification.BatchCreate(ctx, notifications); err != nil {
+		logger.Warn("发送冲突通知失败", zap.Error(err))
+	}
+}
+
+// ════════════════════════════════════════════════════════════
+// 冲突处理任务 — 管理员查看 / 处理
+// ════════════════════════════════════════════════════════════
+
+func (s *scheduleService) ListConflicts(ctx context.Context, req *dto.ScheduleConflictListRequest) ([]dto.ScheduleConflictResponse, int64, error) {
+	filters := &repository.ScheduleConflictFilters{
+		ScheduleID: req.ScheduleID,
+		MemberID:   req.MemberID,
+		Status:     req.Status,
+	}
+	conflicts, total, err := s.repo.ScheduleConflict.ListWithFilters(ctx, filters, req.GetOffset(), req.GetPageSize())
+	if err != nil {
+		s.logger.Error("查询冲突任务失败", zap.Error(err))
+		return nil, 0, err
+	}
+
+	result := make([]dto.ScheduleConflictResponse, 0, len(conflicts))
+	for i := range conflicts {
+		result = append(result, toScheduleConflictResponse(&conflicts[i]))
+	}
+	return result, total, nil
+}
+
+// ResolveConflict 关闭冲突任务：resolved（已线下处理 / 换班）或 dismissed（确认忽略）。
+// 改派排班项请使用 UpdatePublishedItem，改派后对应任务自动关闭。
+func (s *scheduleService) ResolveConflict(ctx context.Context, id string, req *dto.ResolveScheduleConflictRequest, callerID string) (*dto.ScheduleConflictResponse, error) {
+	conflict, err := s.repo.ScheduleConflict.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrScheduleConflictNotFound
+		}
+		s.logger.Error("查询冲突任务失败", zap.Error(err))
+		return nil, err
+	}
+	if conflict.Status != model.ScheduleConflictOpen {
+		return nil, ErrScheduleConflictClosed
+	}
+
+	affected, err := s.repo.ScheduleConflict.Close(ctx, id, req.Status, req.Note, callerID)
+	if err != nil {
+		s.logger.Error("关闭冲突任务失败", zap.Error(err))
+		return nil, err
+	}
+	if affected == 0 {
+		return nil, ErrScheduleConflictClosed // 并发关闭
+	}
+
+	updated, err := s.repo.ScheduleConflict.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	resp := toScheduleConflictResponse(updated)
+	return &resp, nil
+}
+
+func toScheduleConflictResponse(c *model.ScheduleConflict) dto.ScheduleConflictResponse {
+	resp := dto.ScheduleConflictResponse{
+		ID:             c.ScheduleConflictID,
+		ScheduleID:     c.ScheduleID,
+		ScheduleItemID: c.ScheduleItemID,
+		Member:         toMemberBrief(c.Member),
+		Source:         c.Source,
+		Detail:         c.Detail,
+		Status:         c.Status,
+		ResolutionNote: c.ResolutionNote,
+		CreatedAt:      c.CreatedAt.Format(model.TimeFormatDateTime),
+	}
+	if c.ScheduleItem != nil {
+		resp.WeekNumber = c.ScheduleItem.WeekNumber
+		if c.ScheduleItem.TimeSlot != nil {
+			resp.TimeSlot = toTimeSlotBrief(c.ScheduleItem.TimeSlot)
+		}
+	}
+	if c.ResolvedAt != nil {
+		t := c.ResolvedAt.Format(model.TimeFormatDateTime)
+		resp.ResolvedAt = &t
+	}
+	return resp
+}
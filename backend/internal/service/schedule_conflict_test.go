@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/model"
+)
+
+// ── 发布后时间表变更检测测试 ──
+
+// seedPublishedForTimetable 在时间表测试数据上追加：已发布排班（user-1 第 1 周周三下午）+ R1/R2 启用 + 管理员
+func seedPublishedForTimetable(repos *testTimetableRepos) {
+	seedTimetableBasicData(repos)
+	semID := "sem-1"
+	ts := &model.TimeSlot{
+		TimeSlotID: "ts-wed", Name: "周三下午", SemesterID: &semID,
+		DayOfWeek: 3, StartTime: "14:00", EndTime: "16:00", IsActive: true,
+	}
+	repos.timeSlot.slots[ts.TimeSlotID] = ts
+	repos.schedule.schedules["sched-1"] = &model.Schedule{ScheduleID: "sched-1", SemesterID: "sem-1", Status: model.ScheduleStatusPublished}
+	repos.scheduleItem.items["item-1"] = &model.ScheduleItem{
+		ScheduleItemID: "item-1", ScheduleID: "sched-1", WeekNumber: 1,
+		TimeSlotID: ts.TimeSlotID, TimeSlot: ts, MemberID: "user-1",
+	}
+	repos.scheduleRule.rules["r1"] = &model.ScheduleRule{RuleID: "r1", RuleCode: "R1", IsEnabled: true}
+	repos.scheduleRule.rules["r2"] = &model.ScheduleRule{RuleID: "r2", RuleCode: "R2", IsEnabled: true}
+	repos.user.users["admin-1"] = &model.User{UserID: "admin-1", Name: "管理员", Role: model.RoleAdmin}
+}
+
+func weeklyUnavailable(reason string) *CreateUnavailableTimeParams {
+	return &CreateUnavailableTimeParams{
+		DayOfWeek:  3,
+		StartTime:  "15:00",
+		EndTime:    "17:00",
+		Reason:     reason,
+		RepeatType: model.RepeatTypeWeekly,
+		WeekType:   "all",
+		SemesterID: "sem-1",
+	}
+}
+
+func openConflicts(repos *testTimetableRepos) []*model.ScheduleConflict {
+	var result []*model.ScheduleConflict
+	for _, c := range repos.conflict.conflicts {
+		if c.Status == model.ScheduleConflictOpen {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+func TestTimetableService_PublishedConflict_OpensTaskAndNotifies(t *testing.T) {
+	svc, repos := setupTestTimetableService()
+	seedPublishedForTimetable(repos)
+	ctx := context.Background()
+
+	if _, err := svc.CreateUnavailableTime(ctx, weeklyUnavailable("社团活动"), "user-1"); err != nil {
+		t.Fatalf("Create 失败: %v", err)
+	}
+
+	open := openConflicts(repos)
+	if len(open) != 1 {
+		t.Fatalf("期望 1 条待处理冲突任务，实际 %d", len(open))
+	}
+	c := open[0]
+	if c.ScheduleItemID != "item-1" || c.Source != model.ScheduleConflictSourceUnavailable {
+		t.Errorf("冲突任务内容不符: %+v", c)
+	}
+
+	// 成员 + 管理员各一条通知
+	recipients := make(map[string]bool)
+	for _, n := range repos.notification.notifications {
+		if n.Type == model.NotificationTypeScheduleConflict {
+			recipients[n.UserID] = true
+		}
+	}
+	if !recipients["user-1"] || !recipients["admin-1"] {
+		t.Errorf("应通知成员与管理员，实际 %v", recipients)
+	}
+
+	// 同一排班项再次冲突不重复开任务
+	if _, err := svc.CreateUnavailableTime(ctx, weeklyUnavailable("训练"), "user-1"); err != nil {
+		t.Fatalf("Create 失败: %v", err)
+	}
+	if len(repos.conflict.conflicts) != 1 {
+		t.Errorf("同一排班项不应重复生成任务，实际 %d", len(repos.conflict.conflicts))
+	}
+
+	// 删除全部冲突来源后任务自动关闭
+	for _, ut := range append([]model.UnavailableTime(nil), repos.unavailable.times...) {
+		if err := svc.DeleteUnavailableTime(ctx, ut.UnavailableTimeID, "user-1"); err != nil {
+			t.Fatalf("Delete 失败: %v", err)
+		}
+	}
+	if got := repos.conflict.conflicts[c.ScheduleConflictID].Status; got != model.ScheduleConflictResolved {
+		t.Errorf("冲突消失后任务应自动关闭，实际 %s", got)
+	}
+}
+
+func TestTimetableService_PublishedConflict_BlockPolicy(t *testing.T) {
+	svc, repos := setupTestTimetableService()
+	seedPublishedForTimetable(repos)
+	repos.systemConfig.cfg.TimetableConflictPolicy = model.TimetableConflictPolicyBlock
+
+	_, err := svc.CreateUnavailableTime(context.Background(), weeklyUnavailable("社团活动"), "user-1")
+	if !errors.Is(err, ErrTimetableConflictsPublished) {
+		t.Fatalf("期望 ErrTimetableConflictsPublished，实际 %v", err)
+	}
+	if len(repos.conflict.conflicts) != 0 || len(repos.notification.notifications) != 0 {
+		t.Error("被拒绝的变更不应生成任务或通知")
+	}
+}
+
+func TestTimetableService_PublishedConflict_NoOverlap(t *testing.T) {
+	svc, repos := setupTestTimetableService()
+	seedPublishedForTimetable(repos)
+
+	req := weeklyUnavailable("社团活动")
+	req.StartTime, req.EndTime = "08:00", "10:00"
+	if _, err := svc.CreateUnavailableTime(context.Background(), req, "user-1"); err != nil {
+		t.Fatalf("Create 失败: %v", err)
+	}
+	if len(repos.conflict.conflicts) != 0 {
+		t.Errorf("不重叠的变更不应生成任务，实际 %d", len(repos.conflict.conflicts))
+	}
+}
+
+func TestScheduleService_ResolveConflict(t *testing.T) {
+	svc, repos := setupTestScheduleService()
+	ctx := context.Background()
+	conflict := &model.ScheduleConflict{
+		ScheduleID: "sched-1", ScheduleItemID: "item-1", MemberID: "user-1",
+		Source: model.ScheduleConflictSourceCourse, Detail: "第1周 周3 周三下午 与课程「高数」冲突",
+		Status: model.ScheduleConflictOpen,
+	}
+	_ = repos.conflict.Create(ctx, conflict)
+
+	list, total, err := svc.ListConflicts(ctx, &dto.ScheduleConflictListRequest{Status: model.ScheduleConflictOpen})
+	if err != nil || total != 1 || len(list) != 1 {
+		t.Fatalf("期望 1 条待处理任务，实际 %d (err=%v)", total, err)
+	}
+
+	resp, err := svc.ResolveConflict(ctx, conflict.ScheduleConflictID, &dto.ResolveScheduleConflictRequest{
+		Status: model.ScheduleConflictDismissed, Note: "成员已自行调课",
+	}, "admin-1")
+	if err != nil {
+		t.Fatalf("关闭任务失败: %v", err)
+	}
+	if resp.Status != model.ScheduleConflictDismissed || resp.ResolvedAt == nil || resp.ResolutionNote != "成员已自行调课" {
+		t.Errorf("关闭结果不符: %+v", resp)
+	}
+
+	if _, err := svc.ResolveConflict(ctx, conflict.ScheduleConflictID, &dto.ResolveScheduleConflictRequest{
+		Status: model.ScheduleConflictResolved,
+	}, "admin-1"); !errors.Is(err, ErrScheduleConflictClosed) {
+		t.Errorf("重复关闭应返回 ErrScheduleConflictClosed，实际 %v", err)
+	}
+	if _, err := svc.ResolveConflict(ctx, "missing", &dto.ResolveScheduleConflictRequest{
+		Status: model.ScheduleConflictResolved,
+	}, "admin-1"); !errors.Is(err, ErrScheduleConflictNotFound) {
+		t.Errorf("不存在的任务应返回 ErrScheduleConflictNotFound，实际 %v", err)
+	}
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/model"
+)
+
+// ── 班次加权成本 ──
+//
+// 班次成本 = 时长（小时）× 冷门系数。早八、午间等冷门时段由管理员调高系数，
+// 自动排班按成员累计成本（而非班次数）均衡工作量。
+
+// slotHours 时段时长（小时）
+func slotHours(ts model.TimeSlot) float64 {
+	minutes := clockMinutes(ts.EndTime) - clockMinutes(ts.StartTime)
+	if minutes < 0 {
+		minutes += minutesOfDay
+	}
+	return float64(minutes) / 60
+}
+
+// costMultiplier 时段冷门系数（未设置时为 1）
+func costMultiplier(ts model.TimeSlot) float64 {
+	if ts.CostMultiplier <= 0 {
+		return 1
+	}
+	return ts.CostMultiplier
+}
+
+// slotCost 时段加权成本
+func slotCost(ts model.TimeSlot) float64 {
+	return slotHours(ts) * costMultiplier(ts)
+}
+
+// round2 保留两位小数
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// memberLoad 成员的班次数、时长与加权成本
+type memberLoad struct {
+	shifts int
+	hours  float64
+	cost   float64
+}
+
+func (l *memberLoad) add(ts model.TimeSlot) {
+	l.shifts++
+	l.hours += slotHours(ts)
+	l.cost += slotCost(ts)
+}
+
+// memberLoads 统计方案中各成员的工作量（候选人未排到班次时计 0）
+func (in *solverInput) memberLoads(assignments []scheduleAssignment) map[string]*memberLoad {
+	slots := in.slotIndex()
+	loads := make(map[string]*memberLoad, len(in.candidates))
+	for _, c := range in.candidates {
+		loads[c.userID] = &memberLoad{}
+	}
+	for _, a := range assignments {
+		load, ok := loads[a.memberID]
+		if !ok {
+			load = &memberLoad{}
+			loads[a.memberID] = load
+		}
+		if sl, ok := slots[slotKey(a.weekNumber, a.timeSlotID)]; ok {
+			load.add(sl.timeSlot)
+		} else {
+			load.shifts++
+		}
+	}
+	return loads
+}
+
+// workloadScore 成员累计成本对应的贪心打分（每加权小时 greedyWorkloadWeight 分）
+func workloadScore(cost float64) int {
+	return int(math.Round(cost * greedyWorkloadWeight))
+}
+
+// stdDev 总体标准差
+func stdDev(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(variance / float64(len(values)))
+}
+
+// ════════════════════════════════════════════════════════════
+// GetWorkload — 成员工作量
+// ════════════════════════════════════════════════════════════
+
+func (s *scheduleService) GetWorkload(ctx context.Context, scheduleID string) (*dto.ScheduleWorkloadResponse, error) {
+	schedule, err := s.repo.Schedule.GetByID(ctx, scheduleID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrScheduleNotFound
+		}
+		s.logger.Error("查询排班表失败", zap.Error(err))
+		return nil, err
+	}
+
+	input, assignments, items, err := s.loadScheduleAssignments(ctx, schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make(map[string]*model.User)
+	for _, item := range items {
+		if item.Member != nil {
+			users[item.MemberID] = item.Member
+		}
+	}
+
+	loads := input.memberLoads(assignments)
+	resp := &dto.ScheduleWorkloadResponse{
+		ScheduleID: schedule.ScheduleID,
+		Members:    make([]dto.MemberWorkload, 0, len(loads)),
+	}
+	for userID, load := range loads {
+		user, ok := users[userID]
+		if !ok {
+			// 未排到班次的候选人从用户表补全姓名
+			user, _ = s.repo.User.GetByID(ctx, userID)
+		}
+		member := dto.MemberBrief{ID: userID}
+		if brief := toMemberBrief(user); brief != nil {
+			member = *brief
+		}
+		resp.TotalHours += load.hours
+		resp.TotalCost += load.cost
+		resp.Members = append(resp.Members, dto.MemberWorkload{
+			Member: member,
+			Shifts: load.shifts,
+			Hours:  round2(load.hours),
+			Cost:   round2(load.cost),
+		})
+	}
+	resp.TotalHours = round2(resp.TotalHours)
+	resp.TotalCost = round2(resp.TotalCost)
+
+	sort.Slice(resp.Members, func(i, j int) bool {
+		a, b := resp.Members[i], resp.Members[j]
+		if a.Cost != b.Cost {
+			return a.Cost > b.Cost
+		}
+		return a.Member.Name < b.Member.Name
+	})
+	return resp, nil
+}
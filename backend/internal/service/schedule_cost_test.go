@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/model"
+)
+
+func TestSlotCost(t *testing.T) {
+	ts := model.TimeSlot{StartTime: "08:00:00", EndTime: "10:30:00", CostMultiplier: 1.5}
+	if h := slotHours(ts); h != 2.5 {
+		t.Errorf("期望时长 2.5 小时，实际=%v", h)
+	}
+	if c := slotCost(ts); c != 3.75 {
+		t.Errorf("期望成本 3.75，实际=%v", c)
+	}
+	// 未设置系数时按 1 计
+	ts.CostMultiplier = 0
+	if c := slotCost(ts); c != 2.5 {
+		t.Errorf("未设置系数时成本应等于时长，实际=%v", c)
+	}
+}
+
+func TestScheduleService_AutoSchedule_BalancesCost(t *testing.T) {
+	svc, repos := setupTestScheduleService()
+	seedBasicData(repos)
+	// 周一一个 4 小时长班，周二至周五各一个 1 小时短班
+	semID := "sem-1"
+	repos.timeSlot.slots = map[string]*model.TimeSlot{
+		"ts-long": {TimeSlotID: "ts-long", Name: "周一长班", SemesterID: &semID, DayOfWeek: 1, StartTime: "08:00", EndTime: "12:00", IsActive: true},
+	}
+	for day := 2; day <= 5; day++ {
+		id := fmt.Sprintf("ts-short-%d", day)
+		repos.timeSlot.slots[id] = &model.TimeSlot{
+			TimeSlotID: id, Name: "短班", SemesterID: &semID, DayOfWeek: day, StartTime: "12:00", EndTime: "13:00", IsActive: true,
+		}
+	}
+
+	result, err := svc.AutoSchedule(context.Background(), &dto.AutoScheduleRequest{SemesterID: "sem-1"}, "admin-1")
+	if err != nil {
+		t.Fatalf("AutoSchedule 应成功: %v", err)
+	}
+	report, err := svc.GetQualityReport(context.Background(), result.Schedule.ID)
+	if err != nil {
+		t.Fatalf("GetQualityReport 应成功: %v", err)
+	}
+	if report.TotalHours != 16 || report.TotalCost != 16 {
+		t.Errorf("期望总时长与总成本均为 16，实际=%v / %v", report.TotalHours, report.TotalCost)
+	}
+	// 按成本均衡：两人累计成本之差不超过单个最长班次
+	if report.MaxCost-report.MinCost > 4 {
+		t.Errorf("累计成本未均衡: min=%v max=%v", report.MinCost, report.MaxCost)
+	}
+}
+
+func TestScheduleService_GetWorkload(t *testing.T) {
+	svc, repos := setupTestScheduleService()
+	seedBasicData(repos)
+	seedDraftItems(repos)
+	repos.timeSlot.slots["ts-2"].CostMultiplier = 1.5
+
+	workload, err := svc.GetWorkload(context.Background(), "sched-1")
+	if err != nil {
+		t.Fatalf("GetWorkload 应成功: %v", err)
+	}
+	if len(workload.Members) != 2 {
+		t.Fatalf("期望 2 名成员，实际=%d", len(workload.Members))
+	}
+	top := workload.Members[0]
+	if top.Member.ID != "user-2" || top.Hours != 2 || top.Cost != 3 {
+		t.Errorf("期望 user-2 时长 2、成本 3 居首，实际=%+v", top)
+	}
+	if workload.TotalHours != 3.92 {
+		t.Errorf("期望总时长 3.92，实际=%v", workload.TotalHours)
+	}
+}
+
+func TestScheduleService_GetWorkload_NotFound(t *testing.T) {
+	svc, _ := setupTestScheduleService()
+	if _, err := svc.GetWorkload(context.Background(), "missing"); !errors.Is(err, ErrScheduleNotFound) {
+		t.Errorf("期望 ErrScheduleNotFound，实际: %v", err)
+	}
+}
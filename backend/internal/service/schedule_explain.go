@@ -0,0 +1,368 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/model"
+)
+
+// ── 排班解释 ──
+//
+// 针对某个排班项或空槽位，按当前课表、不可用时间与排班表现状逐一说明每位值班成员的状态：
+// 被哪条硬约束挡住、是否已达人均班次，以及可排成员的打分明细（与贪心排班打分口径一致）。
+
+// 成员在槽位上的状态，按展示优先级排列
+const (
+	explainStatusAssigned     = "assigned"
+	explainStatusEligible     = "eligible"
+	explainStatusQuotaReached = "quota_reached"
+	explainStatusRest         = "rest_conflict"
+	explainStatusPair         = "pair_conflict"
+	explainStatusSameDay      = "same_day_conflict"
+	explainStatusCourse       = "course_conflict"
+	explainStatusUnavailable  = "unavailable"
+	explainStatusUnqualified  = "unqualified"
+	explainStatusNotSubmitted = "not_submitted"
+)
+
+var explainStatusOrder = map[string]int{
+	explainStatusAssigned:     0,
+	explainStatusEligible:     1,
+	explainStatusQuotaReached: 2,
+	explainStatusRest:         3,
+	explainStatusPair:         4,
+	explainStatusSameDay:      5,
+	explainStatusCourse:       6,
+	explainStatusUnavailable:  7,
+	explainStatusUnqualified:  8,
+	explainStatusNotSubmitted: 9,
+}
+
+// ════════════════════════════════════════════════════════════
+// ExplainItem / ExplainSlot — 排班解释
+// ════════════════════════════════════════════════════════════
+
+func (s *scheduleService) ExplainItem(ctx context.Context, itemID string) (*dto.SlotExplanationResponse, error) {
+	item, err := s.repo.ScheduleItem.GetByID(ctx, itemID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrScheduleItemNotFound
+		}
+		s.logger.Error("查询排班项失败", zap.Error(err))
+		return nil, err
+	}
+
+	schedule, err := s.repo.Schedule.GetByID(ctx, item.ScheduleID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrScheduleNotFound
+		}
+		s.logger.Error("查询排班表失败", zap.Error(err))
+		return nil, err
+	}
+
+	return s.explainSlot(ctx, schedule, item.WeekNumber, item.TimeSlotID)
+}
+
+func (s *scheduleService) ExplainSlot(ctx context.Context, scheduleID string, req *dto.ExplainSlotRequest) (*dto.SlotExplanationResponse, error) {
+	schedule, err := s.repo.Schedule.GetByID(ctx, scheduleID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrScheduleNotFound
+		}
+		s.logger.Error("查询排班表失败", zap.Error(err))
+		return nil, err
+	}
+
+	return s.explainSlot(ctx, schedule, req.WeekNumber, req.TimeSlotID)
+}
+
+// explainSlot 解释排班表中某个槽位的排班情况（规则按排班表生成时的覆盖设置判定）
+func (s *scheduleService) explainSlot(ctx context.Context, schedule *model.Schedule, weekNumber int, timeSlotID string) (*dto.SlotExplanationResponse, error) {
+	semester := schedule.Semester
+	if semester == nil {
+		var err error
+		semester, err = s.repo.Semester.GetByID(ctx, schedule.SemesterID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrSemesterNotFound
+			}
+			s.logger.Error("查询学期失败", zap.Error(err))
+			return nil, err
+		}
+	}
+
+	timeSlots, err := s.repo.TimeSlot.List(ctx, schedule.SemesterID, nil)
+	if err != nil {
+		s.logger.Error("查询时间段失败", zap.Error(err))
+		return nil, err
+	}
+	input, err := s.loadSolverConstraints(ctx, semester, timeSlots, schedule.RuleOverrides)
+	if err != nil {
+		return nil, err
+	}
+
+	target, ok := input.slotIndex()[slotKey(weekNumber, timeSlotID)]
+	if !ok {
+		return nil, ErrTimeSlotNotFound
+	}
+
+	dutyMembers, err := s.repo.UserSemesterAssignment.ListDutyRequiredBySemester(ctx, schedule.SemesterID)
+	if err != nil {
+		s.logger.Error("查询值班成员失败", zap.Error(err))
+		return nil, err
+	}
+
+	items, err := s.repo.ScheduleItem.ListBySchedule(ctx, schedule.ScheduleID)
+	if err != nil {
+		s.logger.Error("查询排班项失败", zap.Error(err))
+		return nil, err
+	}
+
+	// 拆分：本槽位的在岗成员 / 其他槽位的排班
+	var occupants []model.ScheduleItem
+	others := make([]scheduleAssignment, 0, len(items))
+	for _, item := range items {
+		if item.WeekNumber == weekNumber && item.TimeSlotID == timeSlotID {
+			occupants = append(occupants, item)
+			continue
+		}
+		others = append(others, scheduleAssignment{
+			weekNumber: item.WeekNumber,
+			timeSlotID: item.TimeSlotID,
+			memberID:   item.MemberID,
+		})
+	}
+
+	users := make(map[string]*model.User, len(dutyMembers))
+	submitted := make(map[string]bool, len(dutyMembers))
+	for _, a := range dutyMembers {
+		if a.User == nil {
+			continue
+		}
+		users[a.UserID] = a.User
+		submitted[a.UserID] = a.TimetableStatus == "submitted"
+		input.candidates = append(input.candidates, scheduleCandidate{
+			userID:       a.UserID,
+			departmentID: a.User.DepartmentID,
+			name:         a.User.Name,
+		})
+	}
+	assignedHere := make(map[string]bool, len(occupants))
+	for _, occ := range occupants {
+		assignedHere[occ.MemberID] = true
+		if _, ok := users[occ.MemberID]; !ok && occ.Member != nil {
+			// 已不在值班名单中的在岗成员仍需展示
+			users[occ.MemberID] = occ.Member
+			input.candidates = append(input.candidates, scheduleCandidate{
+				userID:       occ.MemberID,
+				departmentID: occ.Member.DepartmentID,
+				name:         occ.Member.Name,
+			})
+		}
+	}
+
+	// 人均班次上限：槽位总数按已提交课表的成员平均分配
+	submittedCount := 0
+	for _, ok := range submitted {
+		if ok {
+			submittedCount++
+		}
+	}
+	quota := 1
+	if submittedCount > 0 {
+		quota = (len(input.slots) + submittedCount - 1) / submittedCount
+	}
+
+	slots := input.slotIndex()
+	loads := input.memberLoads(others)
+	busyDay := make(map[string]bool) // "userID:week:day"
+	for _, a := range others {
+		if sl, ok := slots[slotKey(a.weekNumber, a.timeSlotID)]; ok {
+			busyDay[fmt.Sprintf("%s:%d:%d", a.memberID, a.weekNumber, sl.timeSlot.DayOfWeek)] = true
+		}
+	}
+
+	// 软约束基线：本槽位仅保留其他在岗成员
+	withOccupants := func(exclude string) []scheduleAssignment {
+		list := append([]scheduleAssignment(nil), others...)
+		for _, occ := range occupants {
+			if occ.MemberID != exclude {
+				list = append(list, scheduleAssignment{weekNumber: weekNumber, timeSlotID: timeSlotID, memberID: occ.MemberID})
+			}
+		}
+		return list
+	}
+
+	resp := &dto.SlotExplanationResponse{
+		ScheduleID:   schedule.ScheduleID,
+		WeekNumber:   weekNumber,
+		TimeSlot:     toTimeSlotBrief(&target.timeSlot),
+		Filled:       len(occupants) > 0,
+		Assigned:     make([]dto.MemberBrief, 0, len(occupants)),
+		Quota:        quota,
+		StatusCounts: make(map[string]int),
+		Members:      make([]dto.MemberExplanation, 0, len(input.candidates)),
+	}
+	for _, occ := range occupants {
+		if brief := toMemberBrief(occ.Member); brief != nil {
+			resp.Assigned = append(resp.Assigned, *brief)
+		} else {
+			resp.Assigned = append(resp.Assigned, dto.MemberBrief{ID: occ.MemberID})
+		}
+	}
+
+	for _, c := range input.candidates {
+		exp := dto.MemberExplanation{
+			Member: dto.MemberBrief{ID: c.userID},
+		}
+		if load := loads[c.userID]; load != nil {
+			exp.ShiftCount = load.shifts
+			exp.Cost = round2(load.cost)
+		}
+		if brief := toMemberBrief(users[c.userID]); brief != nil {
+			exp.Member = *brief
+		}
+
+		var blocked []string
+		if ok, inDuty := submitted[c.userID]; !inDuty {
+			exp.Reasons = append(exp.Reasons, "已不在值班名单中")
+		} else if !ok {
+			blocked = append(blocked, explainStatusNotSubmitted)
+			exp.Reasons = append(exp.Reasons, "课表未提交，未参与排班")
+		}
+
+		avail := input.availabilityOf(c.userID, target)
+		exp.Reasons = append(exp.Reasons, avail.conflicts...)
+		if len(avail.courses) > 0 {
+			blocked = append(blocked, explainStatusCourse)
+		}
+		if len(avail.reasons) > 0 {
+			blocked = append(blocked, explainStatusUnavailable)
+		}
+		if len(avail.skills) > 0 {
+			blocked = append(blocked, explainStatusUnqualified)
+		}
+
+		if input.rules["R6"] && busyDay[fmt.Sprintf("%s:%d:%d", c.userID, weekNumber, target.timeSlot.DayOfWeek)] {
+			blocked = append(blocked, explainStatusSameDay)
+			exp.Reasons = append(exp.Reasons, "R6: 当天已有其他班次")
+		}
+
+		placed := input.placedSlots(withOccupants(c.userID))
+		pairs := input.pairViolations(c.userID, target, placed)
+		for _, v := range pairs {
+			exp.Reasons = append(exp.Reasons, v.String())
+		}
+		if hasHardPairViolation(pairs) {
+			blocked = append(blocked, explainStatusPair)
+		}
+
+		rest := input.restViolations(c.userID, target, placed)
+		for _, v := range rest {
+			exp.Reasons = append(exp.Reasons, v.String())
+		}
+		if hasHardRestViolation(rest) {
+			blocked = append(blocked, explainStatusRest)
+		}
+
+		quotaReached := exp.ShiftCount >= quota
+		if quotaReached {
+			exp.Reasons = append(exp.Reasons, fmt.Sprintf("已排 %d 次，达到人均班次上限 %d", exp.ShiftCount, quota))
+		}
+
+		switch {
+		case assignedHere[c.userID]:
+			exp.Status = explainStatusAssigned
+		case len(blocked) > 0:
+			// 多个阻断原因时取最根本的一个（未提交 > 技能 > 课程 > 不可用 > 同日 > 搭配 > 休息）
+			sort.Slice(blocked, func(i, j int) bool { return explainStatusOrder[blocked[i]] > explainStatusOrder[blocked[j]] })
+			exp.Status = blocked[0]
+		case quotaReached:
+			exp.Status = explainStatusQuotaReached
+		default:
+			exp.Status = explainStatusEligible
+		}
+
+		// 未被硬约束挡住的成员给出打分明细
+		if exp.Status == explainStatusAssigned || exp.Status == explainStatusEligible || exp.Status == explainStatusQuotaReached {
+			base := input.evaluateSoftPenalty(withOccupants(c.userID))
+			with := input.evaluateSoftPenalty(append(withOccupants(c.userID),
+				scheduleAssignment{weekNumber: weekNumber, timeSlotID: timeSlotID, memberID: c.userID}))
+			score := &dto.SoftScoreBreakdown{
+				Workload: workloadScore(loads[c.userID].cost),
+				R3:       with.penalties["R3"] - base.penalties["R3"],
+				R4:       with.penalties["R4"] - base.penalties["R4"],
+				R5:       with.penalties["R5"] - base.penalties["R5"],
+				R7:       with.penalties["R7"] - base.penalties["R7"],
+				R8:       with.penalties["R8"] - base.penalties["R8"],
+				R9:       with.penalties["R9"] - base.penalties["R9"],
+				R10:      with.penalties["R10"] - base.penalties["R10"],
+			}
+			score.Soft = score.R3 + score.R4 + score.R5 + score.R7 + score.R8 + score.R9 + score.R10
+			score.Total = score.Workload + score.Soft
+			exp.Score = score
+		}
+
+		resp.StatusCounts[exp.Status]++
+		resp.Members = append(resp.Members, exp)
+	}
+
+	sort.SliceStable(resp.Members, func(i, j int) bool {
+		a, b := resp.Members[i], resp.Members[j]
+		if a.Score != nil && b.Score != nil {
+			if a.Status == explainStatusAssigned || b.Status == explainStatusAssigned {
+				if a.Status != b.Status {
+					return a.Status == explainStatusAssigned
+				}
+			}
+			if a.Score.Total != b.Score.Total {
+				return a.Score.Total < b.Score.Total
+			}
+			return a.Member.Name < b.Member.Name
+		}
+		if explainStatusOrder[a.Status] != explainStatusOrder[b.Status] {
+			return explainStatusOrder[a.Status] < explainStatusOrder[b.Status]
+		}
+		return a.Member.Name < b.Member.Name
+	})
+	rank := 0
+	for i := range resp.Members {
+		if resp.Members[i].Score != nil {
+			rank++
+			resp.Members[i].Rank = rank
+		}
+	}
+
+	resp.Summary = explainSummary(resp)
+	return resp, nil
+}
+
+// explainSummary 生成一句话结论
+func explainSummary(resp *dto.SlotExplanationResponse) string {
+	if resp.Filled {
+		names := make([]string, 0, len(resp.Assigned))
+		for _, m := range resp.Assigned {
+			names = append(names, m.Name)
+		}
+		return fmt.Sprintf("已排班：%s", strings.Join(names, "、"))
+	}
+	counts := resp.StatusCounts
+	if n := counts[explainStatusEligible]; n > 0 {
+		return fmt.Sprintf("该时段有 %d 名成员可排，可手动补排", n)
+	}
+	if n := counts[explainStatusQuotaReached]; n > 0 {
+		return fmt.Sprintf("可排的 %d 名成员均已达到人均班次上限（%d），可手动补排", n, resp.Quota)
+	}
+	return fmt.Sprintf("无可用候选人：课程冲突 %d 人，不可用时间 %d 人，当天已排 %d 人，未提交课表 %d 人",
+		counts[explainStatusCourse], counts[explainStatusUnavailable],
+		counts[explainStatusSameDay], counts[explainStatusNotSubmitted])
+}
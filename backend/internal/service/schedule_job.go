@@ -0,0 +1,596 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/model"
+	"echo-union/backend/pkg/redis"
+)
+
+// ── 自动排班后台任务 ──
+//
+// 自动排班在 500 人规模下可能超过 HTTP WriteTimeout，因此提供后台任务模式：
+// 提交后立即返回任务 ID，客户端通过轮询或 SSE 订阅获取进度，可随时取消。
+// 同一学期同时只允许一个排班流程（同步或后台）执行，由学期锁保证。
+//
+// 多实例部署：任务在提交它的实例上执行，状态快照同时写入 Redis（autoScheduleJobStore）。
+// 查询、订阅与取消请求落在其他实例时读取共享快照（订阅按 autoScheduleJobPollInterval 轮询），
+// 取消请求登记在 Redis，由执行实例轮询后中止。未配置 Redis 时任务状态仅在本实例可见，须单实例部署。
+
+const (
+	// autoScheduleJobTimeout 单个排班任务最长执行时间
+	autoScheduleJobTimeout = 10 * time.Minute
+	// autoScheduleLockTTL 学期锁过期时间，略长于任务超时，防止进程崩溃后锁永久残留
+	autoScheduleLockTTL = autoScheduleJobTimeout + time.Minute
+	// autoScheduleJobRetention 已结束任务在内存中的保留时间
+	autoScheduleJobRetention = time.Hour
+	// autoScheduleSubscriberBuffer 进度订阅通道缓冲
+	autoScheduleSubscriberBuffer = 16
+	// autoScheduleJobSaveInterval 进度写入共享存储的最小间隔（状态变化时立即写入）
+	autoScheduleJobSaveInterval = time.Second
+	// autoScheduleJobPollInterval 跨实例订阅与取消检查的轮询间隔
+	autoScheduleJobPollInterval = time.Second
+)
+
+func autoScheduleLockKey(semesterID string) string {
+	return "auto_schedule:" + semesterID
+}
+
+// ════════════════════════════════════════════════════════════
+// semesterLocker — 学期级互斥锁
+// ════════════════════════════════════════════════════════════
+
+// semesterLocker Redis 可用时使用分布式锁（多实例互斥），否则降级为进程内锁
+type semesterLocker struct {
+	rdb    *redis.Client
+	logger *zap.Logger
+
+	mu    sync.Mutex
+	local map[string]string // key → token
+}
+
+func newSemesterLocker(rdb *redis.Client, logger *zap.Logger) *semesterLocker {
+	return &semesterLocker{rdb: rdb, logger: logger, local: make(map[string]string)}
+}
+
+// tryLock 非阻塞获取锁；ok=false 表示锁已被占用
+func (l *semesterLocker) tryLock(ctx context.Context, key string, ttl time.Duration) (unlock func(), ok bool, err error) {
+	token := uuid.New().String()
+
+	if l.rdb != nil {
+		acquired, err := l.rdb.AcquireLock(ctx, key, token, ttl)
+		if err == nil {
+			if !acquired {
+				return nil, false, nil
+			}
+			return func() {
+				// 使用独立 context，确保请求取消后仍能释放锁
+				releaseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				if err := l.rdb.ReleaseLock(releaseCtx, key, token); err != nil {
+					l.logger.Warn("释放分布式锁失败", zap.String("key", key), zap.Error(err))
+				}
+			}, true, nil
+		}
+		l.logger.Warn("获取分布式锁失败，降级为进程内锁", zap.String("key", key), zap.Error(err))
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, held := l.local[key]; held {
+		return nil, false, nil
+	}
+	l.local[key] = token
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if l.local[key] == token {
+			delete(l.local, key)
+		}
+	}, true, nil
+}
+
+// ════════════════════════════════════════════════════════════
+// autoScheduleJobStore — 任务状态跨实例共享
+// ════════════════════════════════════════════════════════════
+
+// autoScheduleJobStore 任务状态共享存储
+type autoScheduleJobStore interface {
+	// save 写入任务快照
+	save(ctx context.Context, snap dto.AutoScheduleJobResponse) error
+	// load 读取任务快照，任务不存在（或已过保留期）时返回 nil
+	load(ctx context.Context, jobID string) (*dto.AutoScheduleJobResponse, error)
+	// requestCancel 登记取消请求
+	requestCancel(ctx context.Context, jobID string) error
+	// cancelRequested 是否已登记取消请求
+	cancelRequested(ctx context.Context, jobID string) (bool, error)
+}
+
+// newAutoScheduleJobStore rdb 为 nil 时返回 nil（任务状态仅在本实例可见）
+func newAutoScheduleJobStore(rdb *redis.Client) autoScheduleJobStore {
+	if rdb == nil {
+		return nil
+	}
+	return &redisJobStore{rdb: rdb}
+}
+
+// redisJobStore 基于 Redis 的任务状态存储，快照保留至任务超时后再保留 autoScheduleJobRetention
+type redisJobStore struct {
+	rdb *redis.Client
+}
+
+func (st *redisJobStore) save(ctx context.Context, snap dto.AutoScheduleJobResponse) error {
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	return st.rdb.SetJobState(ctx, snap.JobID, data, autoScheduleJobTimeout+autoScheduleJobRetention)
+}
+
+func (st *redisJobStore) load(ctx context.Context, jobID string) (*dto.AutoScheduleJobResponse, error) {
+	data, err := st.rdb.GetJobState(ctx, jobID)
+	if err != nil || data == nil {
+		return nil, err
+	}
+	var snap dto.AutoScheduleJobResponse
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, err
+	}
+	return &snap, nil
+}
+
+func (st *redisJobStore) requestCancel(ctx context.Context, jobID string) error {
+	return st.rdb.RequestJobCancel(ctx, jobID, autoScheduleLockTTL)
+}
+
+func (st *redisJobStore) cancelRequested(ctx context.Context, jobID string) (bool, error) {
+	return st.rdb.IsJobCancelRequested(ctx, jobID)
+}
+
+// ════════════════════════════════════════════════════════════
+// autoScheduleJob — 任务状态与进度广播
+// ════════════════════════════════════════════════════════════
+
+type autoScheduleJob struct {
+	mu sync.Mutex
+
+	id         string
+	semesterID string
+	createdBy  string
+	status     string
+	progress   dto.AutoScheduleProgress
+	result     *dto.AutoScheduleResponse
+	errMsg     string
+	createdAt  time.Time
+	startedAt  time.Time
+	finishedAt time.Time
+
+	cancel          context.CancelFunc
+	cancelRequested bool
+	subscribers     map[chan dto.AutoScheduleJobResponse]struct{}
+
+	store   autoScheduleJobStore // 可为 nil
+	savedAt time.Time
+	logger  *zap.Logger
+}
+
+func isAutoScheduleJobFinished(status string) bool {
+	return status == model.AutoScheduleJobSucceeded ||
+		status == model.AutoScheduleJobFailed ||
+		status == model.AutoScheduleJobCancelled
+}
+
+// snapshotLocked 生成任务快照（调用方需持有 j.mu）
+func (j *autoScheduleJob) snapshotLocked() dto.AutoScheduleJobResponse {
+	resp := dto.AutoScheduleJobResponse{
+		JobID:      j.id,
+		SemesterID: j.semesterID,
+		Status:     j.status,
+		Progress:   j.progress,
+		Result:     j.result,
+		Error:      j.errMsg,
+		CreatedBy:  j.createdBy,
+		CreatedAt:  j.createdAt.Format(model.TimeFormatDateTime),
+	}
+	if !j.startedAt.IsZero() {
+		resp.StartedAt = j.startedAt.Format(model.TimeFormatDateTime)
+	}
+	if !j.finishedAt.IsZero() {
+		resp.FinishedAt = j.finishedAt.Format(model.TimeFormatDateTime)
+	}
+	return resp
+}
+
+func (j *autoScheduleJob) snapshot() dto.AutoScheduleJobResponse {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.snapshotLocked()
+}
+
+// publishLocked 向所有订阅者推送最新快照；订阅者消费过慢时丢弃旧事件，保证最新状态可达
+func (j *autoScheduleJob) publishLocked() {
+	snap := j.snapshotLocked()
+	for ch := range j.subscribers {
+		select {
+		case ch <- snap:
+		default:
+			select {
+			case <-ch:
+			default:
+			}
+			select {
+			case ch <- snap:
+			default:
+			}
+		}
+	}
+}
+
+// persistLocked 将快照写入共享存储；进度更新按 autoScheduleJobSaveInterval 节流，force 时立即写入
+func (j *autoScheduleJob) persistLocked(force bool) {
+	if j.store == nil || (!force && time.Since(j.savedAt) < autoScheduleJobSaveInterval) {
+		return
+	}
+	j.savedAt = time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := j.store.save(ctx, j.snapshotLocked()); err != nil {
+		j.logger.Warn("写入排班任务状态失败", zap.String("job_id", j.id), zap.Error(err))
+	}
+}
+
+func (j *autoScheduleJob) report(progress dto.AutoScheduleProgress) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.progress = progress
+	j.publishLocked()
+	j.persistLocked(false)
+}
+
+func (j *autoScheduleJob) start() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.status = model.AutoScheduleJobRunning
+	j.startedAt = time.Now()
+	j.publishLocked()
+	j.persistLocked(true)
+}
+
+// requestCancel 标记取消并中止执行；任务已结束时返回 false
+func (j *autoScheduleJob) requestCancel() (dto.AutoScheduleJobResponse, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if isAutoScheduleJobFinished(j.status) {
+		return dto.AutoScheduleJobResponse{}, false
+	}
+	j.cancelRequested = true
+	j.cancel()
+	return j.snapshotLocked(), true
+}
+
+// finish 记录最终状态，推送最后一次快照并关闭全部订阅通道
+func (j *autoScheduleJob) finish(status string, result *dto.AutoScheduleResponse, errMsg string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.status = status
+	j.result = result
+	j.errMsg = errMsg
+	j.finishedAt = time.Now()
+	j.publishLocked()
+	j.persistLocked(true)
+	for ch := range j.subscribers {
+		close(ch)
+	}
+	j.subscribers = nil
+}
+
+// ════════════════════════════════════════════════════════════
+// StartAutoScheduleJob — 提交后台排班任务
+// ════════════════════════════════════════════════════════════
+
+func (s *scheduleService) StartAutoScheduleJob(ctx context.Context, req *dto.AutoScheduleRequest, callerID string) (*dto.AutoScheduleJobResponse, error) {
+	// 提前校验学期，避免明显无效的任务进入后台
+	semester, err := s.repo.Semester.GetByID(ctx, req.SemesterID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSemesterNotFound
+		}
+		s.logger.Error("查询学期失败", zap.Error(err))
+		return nil, err
+	}
+	if semester.Phase != model.SemesterPhaseScheduling {
+		return nil, ErrPhaseNotScheduling
+	}
+
+	unlock, ok, err := s.locker.tryLock(ctx, autoScheduleLockKey(req.SemesterID), autoScheduleLockTTL)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrAutoScheduleRunning
+	}
+
+	jobCtx, cancel := context.WithTimeout(context.Background(), autoScheduleJobTimeout)
+	job := &autoScheduleJob{
+		id:          uuid.New().String(),
+		semesterID:  req.SemesterID,
+		createdBy:   callerID,
+		status:      model.AutoScheduleJobQueued,
+		createdAt:   time.Now(),
+		cancel:      cancel,
+		subscribers: make(map[chan dto.AutoScheduleJobResponse]struct{}),
+		store:       s.jobStore,
+		logger:      s.logger,
+	}
+	// 返回前写入共享存储，其他实例随即可查询
+	job.mu.Lock()
+	job.persistLocked(true)
+	job.mu.Unlock()
+
+	s.jobsMu.Lock()
+	s.pruneJobsLocked()
+	s.jobs[job.id] = job
+	s.jobsMu.Unlock()
+
+	jobReq := *req
+	go s.executeAutoScheduleJob(jobCtx, job, &jobReq, callerID, unlock)
+
+	s.logger.Info("自动排班任务已提交",
+		zap.String("job_id", job.id),
+		zap.String("semester_id", req.SemesterID),
+		zap.String("caller_id", callerID),
+	)
+
+	resp := job.snapshot()
+	return &resp, nil
+}
+
+// executeAutoScheduleJob 后台执行排班任务，结束时释放学期锁
+func (s *scheduleService) executeAutoScheduleJob(ctx context.Context, job *autoScheduleJob, req *dto.AutoScheduleRequest, callerID string, unlock func()) {
+	defer unlock()
+	defer job.cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Error("自动排班任务异常", zap.String("job_id", job.id), zap.Any("panic", r))
+			job.finish(model.AutoScheduleJobFailed, nil, "排班任务异常终止")
+		}
+	}()
+
+	if s.jobStore != nil {
+		go s.watchJobCancel(ctx, job)
+	}
+	job.start()
+	result, err := s.runAutoSchedule(ctx, req, callerID, job.report)
+
+	job.mu.Lock()
+	cancelled := job.cancelRequested
+	job.mu.Unlock()
+
+	switch {
+	case err == nil:
+		job.finish(model.AutoScheduleJobSucceeded, result, "")
+	case cancelled && errors.Is(err, context.Canceled):
+		job.finish(model.AutoScheduleJobCancelled, nil, "排班任务已取消")
+	case errors.Is(err, context.DeadlineExceeded):
+		s.logger.Error("自动排班任务超时", zap.String("job_id", job.id))
+		job.finish(model.AutoScheduleJobFailed, nil, fmt.Sprintf("排班任务超时（超过 %s）", autoScheduleJobTimeout))
+	default:
+		s.logger.Error("自动排班任务失败", zap.String("job_id", job.id), zap.Error(err))
+		job.finish(model.AutoScheduleJobFailed, nil, err.Error())
+	}
+}
+
+// pruneJobsLocked 清理超过保留期的已结束任务（调用方需持有 s.jobsMu）
+func (s *scheduleService) pruneJobsLocked() {
+	cutoff := time.Now().Add(-autoScheduleJobRetention)
+	for id, job := range s.jobs {
+		job.mu.Lock()
+		expired := isAutoScheduleJobFinished(job.status) && job.finishedAt.Before(cutoff)
+		job.mu.Unlock()
+		if expired {
+			delete(s.jobs, id)
+		}
+	}
+}
+
+// watchJobCancel 轮询其他实例登记的取消请求，直至任务结束（ctx 随任务结束取消）
+func (s *scheduleService) watchJobCancel(ctx context.Context, job *autoScheduleJob) {
+	ticker := time.NewTicker(autoScheduleJobPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			requested, err := s.jobStore.cancelRequested(ctx, job.id)
+			if err != nil {
+				if ctx.Err() == nil {
+					s.logger.Warn("查询排班任务取消请求失败", zap.String("job_id", job.id), zap.Error(err))
+				}
+				continue
+			}
+			if requested {
+				job.requestCancel()
+				return
+			}
+		}
+	}
+}
+
+// getJob 获取本实例执行的任务
+func (s *scheduleService) getJob(jobID string) (*autoScheduleJob, error) {
+	s.jobsMu.Lock()
+	defer s.jobsMu.Unlock()
+	job, ok := s.jobs[jobID]
+	if !ok {
+		return nil, ErrAutoScheduleJobNotFound
+	}
+	return job, nil
+}
+
+// loadSharedJob 从共享存储读取其他实例执行的任务快照
+func (s *scheduleService) loadSharedJob(ctx context.Context, jobID string) (*dto.AutoScheduleJobResponse, error) {
+	if s.jobStore == nil {
+		return nil, ErrAutoScheduleJobNotFound
+	}
+	snap, err := s.jobStore.load(ctx, jobID)
+	if err != nil {
+		s.logger.Error("读取排班任务状态失败", zap.String("job_id", jobID), zap.Error(err))
+		return nil, err
+	}
+	if snap == nil {
+		return nil, ErrAutoScheduleJobNotFound
+	}
+	return snap, nil
+}
+
+// ════════════════════════════════════════════════════════════
+// GetAutoScheduleJob — 查询任务状态
+// ════════════════════════════════════════════════════════════
+
+func (s *scheduleService) GetAutoScheduleJob(ctx context.Context, jobID string) (*dto.AutoScheduleJobResponse, error) {
+	job, err := s.getJob(jobID)
+	if err != nil {
+		return s.loadSharedJob(ctx, jobID)
+	}
+	resp := job.snapshot()
+	return &resp, nil
+}
+
+// ════════════════════════════════════════════════════════════
+// CancelAutoScheduleJob — 取消任务（已写入的事务会整体回滚）
+// ════════════════════════════════════════════════════════════
+
+func (s *scheduleService) CancelAutoScheduleJob(ctx context.Context, jobID, callerID string) (*dto.AutoScheduleJobResponse, error) {
+	var resp dto.AutoScheduleJobResponse
+	if job, err := s.getJob(jobID); err == nil {
+		snap, ok := job.requestCancel()
+		if !ok {
+			return nil, ErrAutoScheduleJobFinished
+		}
+		resp = snap
+	} else {
+		// 任务在其他实例执行：登记取消请求，由执行实例轮询后中止
+		snap, err := s.loadSharedJob(ctx, jobID)
+		if err != nil {
+			return nil, err
+		}
+		if isAutoScheduleJobFinished(snap.Status) {
+			return nil, ErrAutoScheduleJobFinished
+		}
+		if err := s.jobStore.requestCancel(ctx, jobID); err != nil {
+			s.logger.Error("登记排班任务取消请求失败", zap.String("job_id", jobID), zap.Error(err))
+			return nil, err
+		}
+		resp = *snap
+	}
+
+	s.logger.Info("自动排班任务取消请求",
+		zap.String("job_id", jobID),
+		zap.String("caller_id", callerID),
+	)
+	return &resp, nil
+}
+
+// ════════════════════════════════════════════════════════════
+// SubscribeAutoScheduleJob — 订阅任务进度
+// ════════════════════════════════════════════════════════════
+
+func (s *scheduleService) SubscribeAutoScheduleJob(ctx context.Context, jobID string) (<-chan dto.AutoScheduleJobResponse, func(), error) {
+	job, err := s.getJob(jobID)
+	if err != nil {
+		return s.subscribeSharedJob(ctx, jobID)
+	}
+
+	ch := make(chan dto.AutoScheduleJobResponse, autoScheduleSubscriberBuffer)
+
+	job.mu.Lock()
+	defer job.mu.Unlock()
+
+	// 首个事件为当前快照；任务已结束则直接关闭通道
+	ch <- job.snapshotLocked()
+	if isAutoScheduleJobFinished(job.status) {
+		close(ch)
+		return ch, func() {}, nil
+	}
+	job.subscribers[ch] = struct{}{}
+
+	unsubscribe := func() {
+		job.mu.Lock()
+		defer job.mu.Unlock()
+		if _, ok := job.subscribers[ch]; ok {
+			delete(job.subscribers, ch)
+			close(ch)
+		}
+	}
+	return ch, unsubscribe, nil
+}
+
+// subscribeSharedJob 订阅其他实例执行的任务：轮询共享存储，快照变化时推送，任务结束或取消订阅时关闭通道
+func (s *scheduleService) subscribeSharedJob(ctx context.Context, jobID string) (<-chan dto.AutoScheduleJobResponse, func(), error) {
+	snap, err := s.loadSharedJob(ctx, jobID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ch := make(chan dto.AutoScheduleJobResponse, autoScheduleSubscriberBuffer)
+	ch <- *snap
+	if isAutoScheduleJobFinished(snap.Status) {
+		close(ch)
+		return ch, func() {}, nil
+	}
+
+	stop := make(chan struct{})
+	go func() {
+		defer close(ch)
+		ticker := time.NewTicker(autoScheduleJobPollInterval)
+		defer ticker.Stop()
+		last := *snap
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			next, err := s.jobStore.load(ctx, jobID)
+			if err != nil {
+				if ctx.Err() == nil {
+					s.logger.Warn("读取排班任务状态失败", zap.String("job_id", jobID), zap.Error(err))
+				}
+				continue
+			}
+			if next == nil {
+				return
+			}
+			if next.Status == last.Status && next.Progress == last.Progress {
+				continue
+			}
+			last = *next
+			select {
+			case ch <- last:
+			case <-stop:
+				return
+			case <-ctx.Done():
+				return
+			}
+			if isAutoScheduleJobFinished(last.Status) {
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return ch, func() { once.Do(func() { close(stop) }) }, nil
+}
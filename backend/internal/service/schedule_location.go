@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"echo-union/backend/internal/model"
+	"echo-union/backend/internal/repository"
+)
+
+// ── 值班地点分配 ──
+//
+// 地点声明容量（同一时刻可容纳的值班人数）与开放时段（未配置表示全天开放）。
+// 绑定地点的时段只能使用该地点；未绑定的时段按"默认地点优先、名称排序"选择
+// 开放且未满员的地点。"同一时刻"指同周同日时间重叠的排班项。
+// 自动排班在求解完成后为每个排班项分配地点；手工调整地点或移动排班项时按同一规则校验。
+
+// locationPlanner 地点占用情况
+type locationPlanner struct {
+	locations []model.Location // 启用地点（默认地点在前）
+	byID      map[string]*model.Location
+	occupied  map[string][]locationUse // locationID → 已占用的排班
+}
+
+// locationUse 一次地点占用
+type locationUse struct {
+	itemID     string
+	weekNumber int
+	timeSlot   model.TimeSlot
+}
+
+func newLocationPlanner(locations []model.Location) *locationPlanner {
+	sorted := make([]model.Location, 0, len(locations))
+	for _, loc := range locations {
+		if loc.IsActive {
+			sorted = append(sorted, loc)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].IsDefault != sorted[j].IsDefault {
+			return sorted[i].IsDefault
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	p := &locationPlanner{
+		locations: sorted,
+		byID:      make(map[string]*model.Location, len(sorted)),
+		occupied:  make(map[string][]locationUse),
+	}
+	for i := range p.locations {
+		p.byID[p.locations[i].LocationID] = &p.locations[i]
+	}
+	return p
+}
+
+// loadLocationPlanner 加载启用地点；items 中已分配地点的排班项计入占用
+func loadLocationPlanner(ctx context.Context, repo *repository.Repository, items []model.ScheduleItem) (*locationPlanner, error) {
+	locations, err := repo.Location.List(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	p := newLocationPlanner(locations)
+	for _, item := range items {
+		if item.LocationID != nil && item.TimeSlot != nil {
+			p.occupy(*item.LocationID, item.ScheduleItemID, item.WeekNumber, *item.TimeSlot)
+		}
+	}
+	return p, nil
+}
+
+func (p *locationPlanner) occupy(locationID, itemID string, weekNumber int, ts model.TimeSlot) {
+	p.occupied[locationID] = append(p.occupied[locationID], locationUse{itemID: itemID, weekNumber: weekNumber, timeSlot: ts})
+}
+
+// locationOpen 地点开放时段是否覆盖整个时段
+func locationOpen(loc *model.Location, ts model.TimeSlot) bool {
+	if len(loc.Windows) == 0 {
+		return true
+	}
+	for _, w := range loc.Windows {
+		if w.DayOfWeek == ts.DayOfWeek && w.StartTime <= ts.StartTime && ts.EndTime <= w.EndTime {
+			return true
+		}
+	}
+	return false
+}
+
+// check 返回把排班项放在地点上的冲突原因，空表示可用（itemID 对应的已有占用不计入容量）
+func (p *locationPlanner) check(locationID, itemID string, weekNumber int, ts model.TimeSlot) string {
+	loc, ok := p.byID[locationID]
+	if !ok {
+		return "地点不存在或已停用"
+	}
+	if ts.LocationID != nil && *ts.LocationID != locationID {
+		bound := *ts.LocationID
+		if b, ok := p.byID[bound]; ok {
+			bound = b.Name
+		}
+		return fmt.Sprintf("时段已绑定地点「%s」", bound)
+	}
+	if !locationOpen(loc, ts) {
+		return fmt.Sprintf("地点「%s」在该时段未开放", loc.Name)
+	}
+	used := 0
+	for _, u := range p.occupied[locationID] {
+		if u.itemID != itemID && u.weekNumber == weekNumber && u.timeSlot.DayOfWeek == ts.DayOfWeek &&
+			u.timeSlot.StartTime < ts.EndTime && ts.StartTime < u.timeSlot.EndTime {
+			used++
+		}
+	}
+	if used >= loc.Capacity {
+		return fmt.Sprintf("地点「%s」容量已满（%d 人）", loc.Name, loc.Capacity)
+	}
+	return ""
+}
+
+// pick 为排班项选择地点并登记占用，无可用地点时返回 nil
+func (p *locationPlanner) pick(itemID string, weekNumber int, ts model.TimeSlot) *string {
+	candidates := p.locations
+	if ts.LocationID != nil {
+		loc, ok := p.byID[*ts.LocationID]
+		if !ok {
+			return nil
+		}
+		candidates = []model.Location{*loc}
+	}
+	for _, loc := range candidates {
+		if p.check(loc.LocationID, itemID, weekNumber, ts) == "" {
+			id := loc.LocationID
+			p.occupy(id, itemID, weekNumber, ts)
+			return &id
+		}
+	}
+	return nil
+}
+
+// assignLocations 为排班项分配地点（写入 LocationID），返回无法分配的提示。
+// 绑定地点的时段优先落位，其余按星期、开始时间顺序选择。
+func (p *locationPlanner) assignLocations(items []model.ScheduleItem, timeSlots map[string]model.TimeSlot) []string {
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		ta, tb := timeSlots[items[order[a]].TimeSlotID], timeSlots[items[order[b]].TimeSlotID]
+		if (ta.LocationID != nil) != (tb.LocationID != nil) {
+			return ta.LocationID != nil
+		}
+		if items[order[a]].WeekNumber != items[order[b]].WeekNumber {
+			return items[order[a]].WeekNumber < items[order[b]].WeekNumber
+		}
+		if ta.DayOfWeek != tb.DayOfWeek {
+			return ta.DayOfWeek < tb.DayOfWeek
+		}
+		return ta.StartTime < tb.StartTime
+	})
+
+	var warnings []string
+	for n, i := range order {
+		item := &items[i]
+		ts, ok := timeSlots[item.TimeSlotID]
+		if !ok {
+			continue
+		}
+		// 新建排班项尚无 ID，以序号区分
+		itemID := item.ScheduleItemID
+		if itemID == "" {
+			itemID = fmt.Sprintf("new-%d", n)
+		}
+		item.LocationID = p.pick(itemID, item.WeekNumber, ts)
+		if item.LocationID == nil {
+			warnings = append(warnings, fmt.Sprintf("第%d周 %s 无可用地点（未开放或容量已满）", item.WeekNumber, ts.Name))
+		}
+	}
+	return warnings
+}
+
+// itemLocationConflicts 校验排班项最终状态下的地点（未分配地点时不校验）
+func itemLocationConflicts(p *locationPlanner, item *model.ScheduleItem) []string {
+	if item.LocationID == nil || item.TimeSlot == nil {
+		return nil
+	}
+	if reason := p.check(*item.LocationID, item.ScheduleItemID, item.WeekNumber, *item.TimeSlot); reason != "" {
+		return []string{reason}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/model"
+)
+
+// seedLocations 办公室（默认，全天开放）+ 活动室（仅周一下午开放）
+func seedLocations(repos *testScheduleRepos) {
+	repos.location.locations["loc-office"] = &model.Location{
+		LocationID: "loc-office", Name: "学生会办公室", IsDefault: true, IsActive: true, Capacity: 1,
+	}
+	repos.location.locations["loc-hall"] = &model.Location{
+		LocationID: "loc-hall", Name: "活动室", IsActive: true, Capacity: 1,
+		Windows: []model.LocationWindow{{LocationID: "loc-hall", DayOfWeek: 1, StartTime: "13:00", EndTime: "18:00"}},
+	}
+}
+
+func TestLocationPlanner_Check(t *testing.T) {
+	hall := "loc-hall"
+	planner := newLocationPlanner([]model.Location{
+		{LocationID: "loc-office", Name: "学生会办公室", IsDefault: true, IsActive: true, Capacity: 2},
+		{LocationID: "loc-hall", Name: "活动室", IsActive: true, Capacity: 1,
+			Windows: []model.LocationWindow{{DayOfWeek: 1, StartTime: "13:00", EndTime: "18:00"}}},
+	})
+	morning := model.TimeSlot{TimeSlotID: "ts-1", DayOfWeek: 1, StartTime: "08:10", EndTime: "10:05"}
+	afternoon := model.TimeSlot{TimeSlotID: "ts-2", DayOfWeek: 1, StartTime: "14:00", EndTime: "16:00", LocationID: &hall}
+
+	if reason := planner.check("loc-hall", "item-1", 1, morning); !strings.Contains(reason, "未开放") {
+		t.Errorf("活动室上午未开放，实际=%q", reason)
+	}
+	if reason := planner.check("loc-office", "item-2", 1, afternoon); !strings.Contains(reason, "已绑定") {
+		t.Errorf("绑定活动室的时段不能使用办公室，实际=%q", reason)
+	}
+
+	planner.occupy("loc-office", "item-a", 1, morning)
+	if reason := planner.check("loc-office", "item-b", 1, morning); reason != "" {
+		t.Errorf("办公室容量为 2，第二人应可用，实际=%q", reason)
+	}
+	planner.occupy("loc-office", "item-b", 1, morning)
+	if reason := planner.check("loc-office", "item-c", 1, morning); !strings.Contains(reason, "容量已满") {
+		t.Errorf("办公室已满，实际=%q", reason)
+	}
+	if reason := planner.check("loc-office", "item-c", 2, morning); reason != "" {
+		t.Errorf("不同周次不占用容量，实际=%q", reason)
+	}
+}
+
+func TestScheduleService_AutoSchedule_AssignsLocations(t *testing.T) {
+	svc, repos := setupTestScheduleService()
+	seedBasicData(repos)
+	seedLocations(repos)
+	hall := "loc-hall"
+	repos.timeSlot.slots["ts-2"].LocationID = &hall
+
+	result, err := svc.AutoSchedule(context.Background(), &dto.AutoScheduleRequest{SemesterID: "sem-1"}, "admin-1")
+	if err != nil {
+		t.Fatalf("AutoSchedule 应成功: %v", err)
+	}
+	for _, w := range result.Warnings {
+		if strings.Contains(w, "地点") {
+			t.Errorf("不应出现地点提示: %s", w)
+		}
+	}
+	want := map[string]string{"ts-1": "loc-office", "ts-2": "loc-hall"}
+	count := 0
+	for _, item := range repos.scheduleItem.items {
+		if item.ScheduleID != result.Schedule.ID {
+			continue
+		}
+		count++
+		if item.LocationID == nil || *item.LocationID != want[item.TimeSlotID] {
+			t.Errorf("第%d周 %s 期望地点 %s，实际=%v", item.WeekNumber, item.TimeSlotID, want[item.TimeSlotID], item.LocationID)
+		}
+	}
+	if count == 0 {
+		t.Fatal("应生成排班项")
+	}
+}
+
+func TestScheduleService_AutoSchedule_LocationCapacity(t *testing.T) {
+	svc, repos := setupTestScheduleService()
+	seedBasicData(repos)
+	repos.location.locations["loc-office"] = &model.Location{
+		LocationID: "loc-office", Name: "学生会办公室", IsDefault: true, IsActive: true, Capacity: 1,
+	}
+	// 周一下午改为与上午并行的时段，办公室只容纳 1 人
+	repos.timeSlot.slots["ts-2"].StartTime = "08:10"
+	repos.timeSlot.slots["ts-2"].EndTime = "10:05"
+
+	result, err := svc.AutoSchedule(context.Background(), &dto.AutoScheduleRequest{SemesterID: "sem-1"}, "admin-1")
+	if err != nil {
+		t.Fatalf("AutoSchedule 应成功: %v", err)
+	}
+	perWeek := make(map[int]int)
+	for _, item := range repos.scheduleItem.items {
+		if item.ScheduleID == result.Schedule.ID && item.LocationID != nil {
+			perWeek[item.WeekNumber]++
+		}
+	}
+	for week, n := range perWeek {
+		if n > 1 {
+			t.Errorf("第%d周办公室同时安排了 %d 人，超出容量", week, n)
+		}
+	}
+	found := false
+	for _, w := range result.Warnings {
+		if strings.Contains(w, "无可用地点") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("容量不足时应给出提示，实际=%v", result.Warnings)
+	}
+}
+
+func TestScheduleService_BatchUpdateItems_LocationClosed(t *testing.T) {
+	svc, repos := setupTestScheduleService()
+	seedBasicData(repos)
+	seedDraftItems(repos)
+	seedLocations(repos)
+
+	hall := "loc-hall"
+	req := &dto.BatchUpdateScheduleItemsRequest{Edits: []dto.ScheduleItemEdit{
+		{ItemID: "item-1", LocationID: &hall},
+	}}
+	_, err := svc.BatchUpdateItems(context.Background(), req, "admin-1")
+	if !errors.Is(err, ErrScheduleEditConflict) || !strings.Contains(err.Error(), "未开放") {
+		t.Fatalf("活动室上午未开放，期望 ErrScheduleEditConflict，实际: %v", err)
+	}
+
+	req.Edits[0].ItemID = "item-2"
+	if _, err := svc.BatchUpdateItems(context.Background(), req, "admin-1"); err != nil {
+		t.Fatalf("下午使用活动室应成功: %v", err)
+	}
+	if loc := repos.scheduleItem.items["item-2"].LocationID; loc == nil || *loc != hall {
+		t.Errorf("item-2 地点应为活动室，实际=%v", loc)
+	}
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"fmt"
+
+	"echo-union/backend/internal/model"
+)
+
+// ── 成员搭配约束（R7） ──
+//
+// 每个时段只排一人，多人值守通过同一时间的并行时段（如不同地点）配置：
+// "同时段"指同周同日时间重叠的两个时段；"相邻"指同周同日不重叠、且两者之间没有其他时段开始。
+// must_not_pair / not_adjacent 为硬约束；must_pair 需要双方同时落位，
+// 自动排班中按软约束罚分引导（penaltyR7），手工调整时与硬约束一样强制校验；
+// 学期内没有并行时段时 must_pair 无法满足，创建时即拒绝（ErrPairConstraintNoSlots）。
+
+// pairPartner 成员的一条搭配约束
+type pairPartner struct {
+	userID string
+	name   string
+	kind   string
+}
+
+// pairIndex userID → 搭配约束（双方各登记一次）
+type pairIndex map[string][]pairPartner
+
+func newPairIndex(constraints []model.PairConstraint) pairIndex {
+	index := make(pairIndex)
+	for _, c := range constraints {
+		index[c.MemberAID] = append(index[c.MemberAID], pairPartner{userID: c.MemberBID, name: pairMemberName(c.MemberB), kind: c.Kind})
+		index[c.MemberBID] = append(index[c.MemberBID], pairPartner{userID: c.MemberAID, name: pairMemberName(c.MemberA), kind: c.Kind})
+	}
+	return index
+}
+
+func pairMemberName(user *model.User) string {
+	if user == nil {
+		return ""
+	}
+	return user.Name
+}
+
+// pairViolation 一次搭配约束违反
+type pairViolation struct {
+	partner pairPartner
+}
+
+// hard must_pair 以外的约束为硬约束
+func (v pairViolation) hard() bool {
+	return v.partner.kind != model.PairMustPair
+}
+
+func (v pairViolation) String() string {
+	name := v.partner.name
+	if name == "" {
+		name = v.partner.userID
+	}
+	switch v.partner.kind {
+	case model.PairMustPair:
+		return fmt.Sprintf("R7: 需与 %s 同时段值班", name)
+	case model.PairMustNotPair:
+		return fmt.Sprintf("R7: 不能与 %s 同时段值班", name)
+	default:
+		return fmt.Sprintf("R7: 不能与 %s 排在相邻时段", name)
+	}
+}
+
+// slotsConcurrent 两个时段是否同日且时间重叠
+func slotsConcurrent(a, b model.TimeSlot) bool {
+	return a.DayOfWeek == b.DayOfWeek && a.StartTime < b.EndTime && b.StartTime < a.EndTime
+}
+
+// slotsAdjacent 两个时段是否同日相邻：不重叠，且早的结束到晚的开始之间没有其他时段开始
+func slotsAdjacent(a, b model.TimeSlot, daySlots []model.TimeSlot) bool {
+	if a.DayOfWeek != b.DayOfWeek || slotsConcurrent(a, b) {
+		return false
+	}
+	if b.StartTime < a.StartTime {
+		a, b = b, a
+	}
+	for _, ts := range daySlots {
+		if ts.DayOfWeek == a.DayOfWeek && ts.StartTime >= a.EndTime && ts.StartTime < b.StartTime {
+			return false
+		}
+	}
+	return true
+}
+
+// check 返回成员排在 ts 时违反的搭配约束。
+// partnerSlots 给出搭档在同一周次已排的时段；daySlots 为判定相邻所用的时段全集
+func (index pairIndex) check(userID string, ts model.TimeSlot, partnerSlots func(partnerID string) []model.TimeSlot, daySlots []model.TimeSlot) []pairViolation {
+	var violations []pairViolation
+	for _, p := range index[userID] {
+		slots := partnerSlots(p.userID)
+		violated := false
+		switch p.kind {
+		case model.PairMustPair:
+			violated = true
+			for _, other := range slots {
+				if slotsConcurrent(ts, other) {
+					violated = false
+					break
+				}
+			}
+		case model.PairMustNotPair:
+			for _, other := range slots {
+				if slotsConcurrent(ts, other) {
+					violated = true
+					break
+				}
+			}
+		case model.PairNotAdjacent:
+			for _, other := range slots {
+				if slotsAdjacent(ts, other, daySlots) {
+					violated = true
+					break
+				}
+			}
+		}
+		if violated {
+			violations = append(violations, pairViolation{partner: p})
+		}
+	}
+	return violations
+}
+
+// placedSlots 按成员归集方案中已排的槽位
+func (in *solverInput) placedSlots(assignments []scheduleAssignment) map[string][]scheduleSlot {
+	slots := in.slotIndex()
+	placed := make(map[string][]scheduleSlot)
+	for _, a := range assignments {
+		if sl, ok := slots[slotKey(a.weekNumber, a.timeSlotID)]; ok {
+			placed[a.memberID] = append(placed[a.memberID], sl)
+		}
+	}
+	return placed
+}
+
+// pairViolations 成员排在槽位 sl 时违反的搭配约束（R7 未启用时为空）
+func (in *solverInput) pairViolations(userID string, sl scheduleSlot, placed map[string][]scheduleSlot) []pairViolation {
+	if !in.rules["R7"] || len(in.pairs[userID]) == 0 {
+		return nil
+	}
+	return in.pairs.check(userID, sl.timeSlot, func(partnerID string) []model.TimeSlot {
+		var list []model.TimeSlot
+		for _, other := range placed[partnerID] {
+			if other.weekNumber == sl.weekNumber {
+				list = append(list, other.timeSlot)
+			}
+		}
+		return list
+	}, in.timeSlots)
+}
+
+// hasHardPairViolation 是否违反硬搭配约束
+func hasHardPairViolation(violations []pairViolation) bool {
+	for _, v := range violations {
+		if v.hard() {
+			return true
+		}
+	}
+	return false
+}
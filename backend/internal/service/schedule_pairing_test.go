@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/model"
+)
+
+// seedPairRule 启用 R7 并登记一条搭配约束
+func seedPairRule(repos *testScheduleRepos, a, b, kind string) {
+	repos.scheduleRule.rules["r7"] = &model.ScheduleRule{RuleID: "r7", RuleCode: "R7", IsEnabled: true}
+	repos.pair.constraints = append(repos.pair.constraints, model.PairConstraint{
+		PairConstraintID: "pair-" + a + "-" + b, SemesterID: "sem-1",
+		MemberAID: a, MemberBID: b, Kind: kind,
+	})
+}
+
+func TestSlotsAdjacent(t *testing.T) {
+	morning := model.TimeSlot{DayOfWeek: 1, StartTime: "08:10", EndTime: "10:05"}
+	noon := model.TimeSlot{DayOfWeek: 1, StartTime: "10:20", EndTime: "12:00"}
+	afternoon := model.TimeSlot{DayOfWeek: 1, StartTime: "14:00", EndTime: "16:00"}
+	tuesday := model.TimeSlot{DayOfWeek: 2, StartTime: "10:20", EndTime: "12:00"}
+	all := []model.TimeSlot{morning, noon, afternoon, tuesday}
+
+	if !slotsAdjacent(morning, noon, all) {
+		t.Error("上午与中午之间无其他时段，应相邻")
+	}
+	if slotsAdjacent(morning, afternoon, all) {
+		t.Error("上午与下午之间隔着中午，不应相邻")
+	}
+	if slotsAdjacent(morning, tuesday, all) {
+		t.Error("不同日不应相邻")
+	}
+	if slotsAdjacent(morning, morning, all) {
+		t.Error("时间重叠的时段属于同时段而非相邻")
+	}
+}
+
+func TestScheduleService_AutoSchedule_MustNotPair(t *testing.T) {
+	svc, repos := setupTestScheduleService()
+	seedBasicData(repos)
+	// 把周一下午改为与周一上午并行的时段（如另一地点）
+	repos.timeSlot.slots["ts-2"].StartTime = "08:10"
+	repos.timeSlot.slots["ts-2"].EndTime = "10:05"
+	seedPairRule(repos, "user-1", "user-2", model.PairMustNotPair)
+
+	result, err := svc.AutoSchedule(context.Background(), &dto.AutoScheduleRequest{SemesterID: "sem-1"}, "admin-1")
+	if err != nil {
+		t.Fatalf("AutoSchedule 应成功: %v", err)
+	}
+
+	members := make(map[int]map[string]bool)
+	for _, item := range result.Schedule.Items {
+		if item.Member == nil {
+			continue
+		}
+		if members[item.WeekNumber] == nil {
+			members[item.WeekNumber] = make(map[string]bool)
+		}
+		members[item.WeekNumber][item.Member.ID] = true
+	}
+	for week, set := range members {
+		if set["user-1"] && set["user-2"] {
+			t.Errorf("第%d周 user-1 与 user-2 不应被排在并行时段", week)
+		}
+	}
+}
+
+func TestScheduleService_BatchUpdateItems_PairNotAdjacent(t *testing.T) {
+	svc, repos := setupTestScheduleService()
+	seedBasicData(repos)
+	seedDraftItems(repos)
+	user3 := &model.User{UserID: "user-3", Name: "王五", StudentID: "2021003"}
+	repos.user.users["user-3"] = user3
+	repos.assignment.assignments = append(repos.assignment.assignments, model.UserSemesterAssignment{
+		AssignmentID: "a-3", UserID: "user-3", SemesterID: "sem-1",
+		DutyRequired: true, TimetableStatus: "submitted", User: user3,
+	})
+	seedPairRule(repos, "user-1", "user-3", model.PairNotAdjacent)
+
+	// 周一上午与下午之间没有其他时段 → 相邻
+	member := "user-3"
+	req := &dto.BatchUpdateScheduleItemsRequest{Edits: []dto.ScheduleItemEdit{
+		{ItemID: "item-2", MemberID: &member},
+	}}
+	_, err := svc.BatchUpdateItems(context.Background(), req, "admin-1")
+	if !errors.Is(err, ErrScheduleEditConflict) {
+		t.Fatalf("期望 ErrScheduleEditConflict，实际: %v", err)
+	}
+	if !strings.Contains(err.Error(), "R7") {
+		t.Errorf("冲突信息应包含 R7，实际: %v", err)
+	}
+	if repos.scheduleItem.items["item-2"].MemberID != "user-2" {
+		t.Error("校验失败时不应写入任何修改")
+	}
+}
+
+func TestScheduleService_BatchUpdateItems_MustPairIsWarning(t *testing.T) {
+	svc, repos := setupTestScheduleService()
+	seedBasicData(repos)
+	seedDraftItems(repos)
+	user3 := &model.User{UserID: "user-3", Name: "王五", StudentID: "2021003"}
+	repos.user.users["user-3"] = user3
+	repos.assignment.assignments = append(repos.assignment.assignments, model.UserSemesterAssignment{
+		AssignmentID: "a-3", UserID: "user-3", SemesterID: "sem-1",
+		DutyRequired: true, TimetableStatus: "submitted", User: user3,
+	})
+	seedPairRule(repos, "user-1", "user-3", model.PairMustPair)
+
+	// 与自动排班一致：需同时段未满足只提示，不阻止手工调整
+	member := "user-3"
+	req := &dto.BatchUpdateScheduleItemsRequest{Edits: []dto.ScheduleItemEdit{
+		{ItemID: "item-2", MemberID: &member},
+	}}
+	if _, err := svc.BatchUpdateItems(context.Background(), req, "admin-1"); err != nil {
+		t.Fatalf("需同时段未满足不应阻止调整: %v", err)
+	}
+
+	result, err := svc.ValidateCandidate(context.Background(), "item-2", &dto.ValidateCandidateRequest{MemberID: "user-3"})
+	if err != nil {
+		t.Fatalf("ValidateCandidate 应成功: %v", err)
+	}
+	if !result.Valid || len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "R7") {
+		t.Errorf("需同时段未满足应仅作为 R7 提示，实际 %+v", result)
+	}
+}
+
+func TestScheduleService_GetQualityReport_PairViolation(t *testing.T) {
+	svc, repos := setupTestScheduleService()
+	seedBasicData(repos)
+	seedDraftItems(repos)
+	seedPairRule(repos, "user-1", "user-2", model.PairNotAdjacent)
+
+	report, err := svc.GetQualityReport(context.Background(), "sched-1")
+	if err != nil {
+		t.Fatalf("GetQualityReport 应成功: %v", err)
+	}
+	if report.HardConflicts != 2 {
+		t.Errorf("期望 HardConflicts=2（双方各计一次），实际=%d", report.HardConflicts)
+	}
+}
+
+// ════════════════════════════════════════════════════════════
+// PairConstraintService 测试
+// ════════════════════════════════════════════════════════════
+
+func TestPairConstraintService_Create(t *testing.T) {
+	repos := newTestScheduleRepos()
+	seedBasicData(repos)
+	for _, a := range repos.assignment.assignments {
+		repos.user.users[a.UserID] = a.User
+	}
+	svc := NewPairConstraintService(repos.toRepository(), zap.NewNop())
+	ctx := context.Background()
+
+	// 每个时段只排一人：没有并行时段时 must_pair 无法满足
+	req := &dto.CreatePairConstraintRequest{MemberAID: "user-1", MemberBID: "user-2", Kind: model.PairMustPair}
+	if _, err := svc.Create(ctx, "sem-1", req, "admin-1"); !errors.Is(err, ErrPairConstraintNoSlots) {
+		t.Errorf("期望 ErrPairConstraintNoSlots，实际: %v", err)
+	}
+	parallel := *repos.timeSlot.slots["ts-1"]
+	parallel.TimeSlotID, parallel.Name = "ts-1b", "周一上午（大厅）"
+	repos.timeSlot.slots["ts-1b"] = &parallel
+
+	resp, err := svc.Create(ctx, "sem-1", req, "admin-1")
+	if err != nil {
+		t.Fatalf("Create 应成功: %v", err)
+	}
+	if resp.MemberA == nil || resp.MemberA.Name != "张三" {
+		t.Errorf("期望返回成员 A 信息，实际=%+v", resp.MemberA)
+	}
+
+	// 同一对成员（顺序相反）不可重复
+	reversed := &dto.CreatePairConstraintRequest{MemberAID: "user-2", MemberBID: "user-1", Kind: model.PairNotAdjacent}
+	if _, err := svc.Create(ctx, "sem-1", reversed, "admin-1"); !errors.Is(err, ErrPairConstraintDuplicate) {
+		t.Errorf("期望 ErrPairConstraintDuplicate，实际: %v", err)
+	}
+
+	self := &dto.CreatePairConstraintRequest{MemberAID: "user-1", MemberBID: "user-1", Kind: model.PairMustPair}
+	if _, err := svc.Create(ctx, "sem-1", self, "admin-1"); !errors.Is(err, ErrPairConstraintSelf) {
+		t.Errorf("期望 ErrPairConstraintSelf，实际: %v", err)
+	}
+
+	unknown := &dto.CreatePairConstraintRequest{MemberAID: "user-1", MemberBID: "user-404", Kind: model.PairMustPair}
+	if _, err := svc.Create(ctx, "sem-1", unknown, "admin-1"); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("期望 ErrUserNotFound，实际: %v", err)
+	}
+
+	// 非本学期值班成员不能设置搭配约束
+	repos.user.users["user-3"] = &model.User{UserID: "user-3", Name: "王五"}
+	notAssigned := &dto.CreatePairConstraintRequest{MemberAID: "user-1", MemberBID: "user-3", Kind: model.PairMustNotPair}
+	if _, err := svc.Create(ctx, "sem-1", notAssigned, "admin-1"); !errors.Is(err, ErrPairConstraintNotDuty) {
+		t.Errorf("未分配到本学期期望 ErrPairConstraintNotDuty，实际: %v", err)
+	}
+	repos.assignment.assignments = append(repos.assignment.assignments, model.UserSemesterAssignment{
+		AssignmentID: "a-3", UserID: "user-3", SemesterID: "sem-1", DutyRequired: false,
+	})
+	if _, err := svc.Create(ctx, "sem-1", notAssigned, "admin-1"); !errors.Is(err, ErrPairConstraintNotDuty) {
+		t.Errorf("无需值班的成员期望 ErrPairConstraintNotDuty，实际: %v", err)
+	}
+
+	if err := svc.Delete(ctx, "sem-other", resp.ID, "admin-1"); !errors.Is(err, ErrPairConstraintNotFound) {
+		t.Errorf("跨学期删除期望 ErrPairConstraintNotFound，实际: %v", err)
+	}
+	if err := svc.Delete(ctx, "sem-1", resp.ID, "admin-1"); err != nil {
+		t.Errorf("Delete 应成功: %v", err)
+	}
+}
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"echo-union/backend/internal/model"
+	"echo-union/backend/internal/repository"
+)
+
+// ── 休息与负载分散约束（R8 / R9 / R10） ──
+//
+// 排班以两周为一个周期循环执行：第2周结束后紧接下一周期的第1周。
+// 班次在周期内的位置按分钟换算，间隔与"连续两天"均按环形计算，
+// 因而第2周周五与第1周周一之间同样参与判定。阈值与硬 / 软模式取自系统配置。
+
+const (
+	penaltyR8  = 40 // 超出每周班次上限（每超 1 班计一次）
+	penaltyR9  = 40 // 相邻班次间隔不足
+	penaltyR10 = 25 // 连续两天值班
+
+	// cycleDays 排班周期天数（单双周）
+	cycleDays    = 14
+	minutesOfDay = 24 * 60
+	cycleMinutes = cycleDays * minutesOfDay
+)
+
+// restPolicy 休息约束参数
+type restPolicy struct {
+	maxPerWeek     int // 0 表示不限
+	maxPerWeekHard bool
+	minRestMinutes int // 0 表示不限
+	minRestHard    bool
+	backToBackHard bool
+}
+
+// newRestPolicy 由系统配置构建休息约束参数；cfg 为 nil 时不限制上限与间隔
+func newRestPolicy(cfg *model.SystemConfig) restPolicy {
+	if cfg == nil {
+		return restPolicy{}
+	}
+	return restPolicy{
+		maxPerWeek:     cfg.MaxShiftsPerWeek,
+		maxPerWeekHard: cfg.MaxShiftsPerWeekMode == model.ConstraintModeHard,
+		minRestMinutes: cfg.MinRestHours * 60,
+		minRestHard:    cfg.MinRestMode == model.ConstraintModeHard,
+		backToBackHard: cfg.BackToBackDaysMode == model.ConstraintModeHard,
+	}
+}
+
+// hard 规则是否配置为硬约束
+func (p restPolicy) hard(ruleCode string) bool {
+	switch ruleCode {
+	case "R8":
+		return p.maxPerWeekHard
+	case "R9":
+		return p.minRestHard
+	case "R10":
+		return p.backToBackHard
+	}
+	return false
+}
+
+// loadRestPolicy 读取休息约束参数（配置缺失时不限制上限与间隔）
+func loadRestPolicy(ctx context.Context, repo *repository.Repository) restPolicy {
+	cfg, err := repo.SystemConfig.Get(ctx)
+	if err != nil {
+		return restPolicy{}
+	}
+	return newRestPolicy(cfg)
+}
+
+// restViolation 一次休息约束违反
+type restViolation struct {
+	rule    string // R8 | R9 | R10
+	hard    bool
+	message string
+}
+
+func (v restViolation) String() string {
+	return fmt.Sprintf("%s: %s", v.rule, v.message)
+}
+
+// clockMinutes 将 HH:MM（或 HH:MM:SS）换算为当日分钟数
+func clockMinutes(clock string) int {
+	parts := strings.SplitN(clock, ":", 3)
+	if len(parts) < 2 {
+		return 0
+	}
+	h, _ := strconv.Atoi(parts[0])
+	m, _ := strconv.Atoi(parts[1])
+	return h*60 + m
+}
+
+// cycleDay 班次在两周周期内的天序号（0 ~ 13）
+func cycleDay(weekNumber int, ts model.TimeSlot) int {
+	return (weekNumber-1)*7 + ts.DayOfWeek - 1
+}
+
+// cycleSpan 班次在周期内的起止分钟
+func cycleSpan(weekNumber int, ts model.TimeSlot) (start, end int) {
+	base := cycleDay(weekNumber, ts) * minutesOfDay
+	start = base + clockMinutes(ts.StartTime)
+	end = base + clockMinutes(ts.EndTime)
+	if end < start {
+		end += minutesOfDay
+	}
+	return start, end
+}
+
+// restGap 两个班次之间的环形休息间隔（分钟），时间重叠时为 0
+func restGap(weekA int, a model.TimeSlot, weekB int, b model.TimeSlot) int {
+	aStart, aEnd := cycleSpan(weekA, a)
+	bStart, bEnd := cycleSpan(weekB, b)
+	if aStart < bEnd && bStart < aEnd {
+		return 0
+	}
+	after := ((bStart-aEnd)%cycleMinutes + cycleMinutes) % cycleMinutes  // a 结束到 b 开始
+	before := ((aStart-bEnd)%cycleMinutes + cycleMinutes) % cycleMinutes // b 结束到 a 开始
+	if after < before {
+		return after
+	}
+	return before
+}
+
+// consecutiveDays 两个班次是否落在周期内相邻的两天（含第2周末与第1周初）
+func consecutiveDays(weekA int, a model.TimeSlot, weekB int, b model.TimeSlot) bool {
+	diff := ((cycleDay(weekA, a)-cycleDay(weekB, b))%cycleDays + cycleDays) % cycleDays
+	return diff == 1 || diff == cycleDays-1
+}
+
+// restShift 参与休息约束判定的班次
+type restShift struct {
+	weekNumber int
+	timeSlot   model.TimeSlot
+}
+
+func (r restShift) describe() string {
+	return fmt.Sprintf("第%d周 周%d %s-%s", r.weekNumber, r.timeSlot.DayOfWeek, r.timeSlot.StartTime, r.timeSlot.EndTime)
+}
+
+// check 返回成员排在 target 时违反的休息约束；others 为该成员的其他班次（不含 target）
+func (p restPolicy) check(rules map[string]bool, target restShift, others []restShift) []restViolation {
+	var violations []restViolation
+
+	// R8: 每周班次上限
+	if rules["R8"] && p.maxPerWeek > 0 {
+		count := 1
+		for _, o := range others {
+			if o.weekNumber == target.weekNumber {
+				count++
+			}
+		}
+		if count > p.maxPerWeek {
+			violations = append(violations, restViolation{rule: "R8", hard: p.maxPerWeekHard,
+				message: fmt.Sprintf("第%d周将排 %d 个班次，超过上限 %d", target.weekNumber, count, p.maxPerWeek)})
+		}
+	}
+
+	sorted := append([]restShift(nil), others...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		si, _ := cycleSpan(sorted[i].weekNumber, sorted[i].timeSlot)
+		sj, _ := cycleSpan(sorted[j].weekNumber, sorted[j].timeSlot)
+		return si < sj
+	})
+
+	// R9: 相邻班次最小间隔
+	if rules["R9"] && p.minRestMinutes > 0 {
+		for _, o := range sorted {
+			if gap := restGap(target.weekNumber, target.timeSlot, o.weekNumber, o.timeSlot); gap < p.minRestMinutes {
+				violations = append(violations, restViolation{rule: "R9", hard: p.minRestHard,
+					message: fmt.Sprintf("与 %s 的班次间隔不足 %d 小时", o.describe(), p.minRestMinutes/60)})
+			}
+		}
+	}
+
+	// R10: 避免连续两天值班
+	if rules["R10"] {
+		for _, o := range sorted {
+			if consecutiveDays(target.weekNumber, target.timeSlot, o.weekNumber, o.timeSlot) {
+				violations = append(violations, restViolation{rule: "R10", hard: p.backToBackHard,
+					message: fmt.Sprintf("与 %s 的班次为连续两天", o.describe())})
+			}
+		}
+	}
+
+	return violations
+}
+
+// restViolations 成员排在槽位 sl 时违反的休息约束；placed 中与 sl 相同的槽位不计入
+func (in *solverInput) restViolations(userID string, sl scheduleSlot, placed map[string][]scheduleSlot) []restViolation {
+	if !in.rules["R8"] && !in.rules["R9"] && !in.rules["R10"] {
+		return nil
+	}
+	others := make([]restShift, 0, len(placed[userID]))
+	for _, o := range placed[userID] {
+		if o.key() == sl.key() {
+			continue
+		}
+		others = append(others, restShift{weekNumber: o.weekNumber, timeSlot: o.timeSlot})
+	}
+	return in.rest.check(in.rules, restShift{weekNumber: sl.weekNumber, timeSlot: sl.timeSlot}, others)
+}
+
+// hasHardRestViolation 是否违反硬休息约束
+func hasHardRestViolation(violations []restViolation) bool {
+	for _, v := range violations {
+		if v.hard {
+			return true
+		}
+	}
+	return false
+}
+
+// softRestPenalty 软休息约束罚分
+func (in *solverInput) softRestPenalty(violations []restViolation) int {
+	penalty := 0
+	for _, v := range violations {
+		if v.hard {
+			continue
+		}
+		switch v.rule {
+		case "R8":
+			penalty += in.penalty("R8")
+		case "R9":
+			penalty += in.penalty("R9")
+		case "R10":
+			penalty += in.penalty("R10")
+		}
+	}
+	return penalty
+}
+
+// evaluateRestPenalty 统计整体方案中的软休息约束违反：
+// R8 每周每超上限 1 班计一次，R9 / R10 按成员的班次对各计一次
+func (in *solverInput) evaluateRestPenalty(placed map[string][]scheduleSlot, result *softPenalty) {
+	p := in.rest
+	for _, shifts := range placed {
+		if in.rules["R8"] && p.maxPerWeek > 0 && !p.maxPerWeekHard {
+			perWeek := make(map[int]int)
+			for _, sl := range shifts {
+				perWeek[sl.weekNumber]++
+			}
+			for _, n := range perWeek {
+				for i := p.maxPerWeek; i < n; i++ {
+					result.add("R8", in.penalty("R8"))
+				}
+			}
+		}
+		for i := 0; i < len(shifts); i++ {
+			for j := i + 1; j < len(shifts); j++ {
+				a, b := shifts[i], shifts[j]
+				if in.rules["R9"] && p.minRestMinutes > 0 && !p.minRestHard &&
+					restGap(a.weekNumber, a.timeSlot, b.weekNumber, b.timeSlot) < p.minRestMinutes {
+					result.add("R9", in.penalty("R9"))
+				}
+				if in.rules["R10"] && !p.backToBackHard &&
+					consecutiveDays(a.weekNumber, a.timeSlot, b.weekNumber, b.timeSlot) {
+					result.add("R10", in.penalty("R10"))
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/model"
+)
+
+// seedRestRule 启用休息约束规则
+func seedRestRule(repos *testScheduleRepos, codes ...string) {
+	for _, code := range codes {
+		id := "r-" + strings.ToLower(code)
+		repos.scheduleRule.rules[id] = &model.ScheduleRule{RuleID: id, RuleCode: code, IsEnabled: true}
+	}
+}
+
+// seedTuesdaySlot 新增周二上午时段 ts-3
+func seedTuesdaySlot(repos *testScheduleRepos) *model.TimeSlot {
+	semID := "sem-1"
+	ts := &model.TimeSlot{
+		TimeSlotID: "ts-3", Name: "周二上午", SemesterID: &semID,
+		DayOfWeek: 2, StartTime: "08:10", EndTime: "10:05", IsActive: true,
+	}
+	repos.timeSlot.slots["ts-3"] = ts
+	return ts
+}
+
+func TestRestGap_CycleWrap(t *testing.T) {
+	monEvening := model.TimeSlot{DayOfWeek: 1, StartTime: "18:00", EndTime: "20:00"}
+	tueMorning := model.TimeSlot{DayOfWeek: 2, StartTime: "08:00", EndTime: "10:00"}
+	if gap := restGap(1, monEvening, 1, tueMorning); gap != 12*60 {
+		t.Errorf("周一晚到周二早应间隔 12 小时，实际=%d 分钟", gap)
+	}
+
+	// 第2周周日晚班与下一周期第1周周一早班首尾相接
+	sunEvening := model.TimeSlot{DayOfWeek: 7, StartTime: "20:00", EndTime: "22:00"}
+	monMorning := model.TimeSlot{DayOfWeek: 1, StartTime: "08:00", EndTime: "10:00"}
+	if gap := restGap(2, sunEvening, 1, monMorning); gap != 10*60 {
+		t.Errorf("跨周期应间隔 10 小时，实际=%d 分钟", gap)
+	}
+	if !consecutiveDays(2, sunEvening, 1, monMorning) {
+		t.Error("第2周周日与第1周周一应视为连续两天")
+	}
+	friday := model.TimeSlot{DayOfWeek: 5, StartTime: "08:00", EndTime: "10:00"}
+	if consecutiveDays(2, friday, 1, monMorning) {
+		t.Error("第2周周五与第1周周一不应视为连续两天")
+	}
+}
+
+func TestScheduleService_AutoSchedule_MaxShiftsPerWeekHard(t *testing.T) {
+	svc, repos := setupTestScheduleService()
+	seedBasicData(repos)
+	seedTuesdaySlot(repos)
+	seedRestRule(repos, "R8")
+	repos.systemConfig.cfg.MaxShiftsPerWeek = 1
+	repos.systemConfig.cfg.MaxShiftsPerWeekMode = model.ConstraintModeHard
+
+	result, err := svc.AutoSchedule(context.Background(), &dto.AutoScheduleRequest{SemesterID: "sem-1"}, "admin-1")
+	if err != nil {
+		t.Fatalf("AutoSchedule 应成功: %v", err)
+	}
+
+	perWeek := make(map[string]int)
+	for _, item := range result.Schedule.Items {
+		if item.Member == nil {
+			continue
+		}
+		key := fmt.Sprintf("%s:%d", item.Member.ID, item.WeekNumber)
+		perWeek[key]++
+		if perWeek[key] > 1 {
+			t.Errorf("成员 %s 第%d周超过每周 1 班上限", item.Member.ID, item.WeekNumber)
+		}
+	}
+	// 3 个时段 × 2 周，2 人每周各 1 班 → 只能排 4 班
+	if result.FilledSlots != 4 {
+		t.Errorf("期望 FilledSlots=4，实际=%d", result.FilledSlots)
+	}
+}
+
+func TestScheduleService_BatchUpdateItems_BackToBackHard(t *testing.T) {
+	svc, repos := setupTestScheduleService()
+	seedBasicData(repos)
+	seedDraftItems(repos)
+	ts3 := seedTuesdaySlot(repos)
+	repos.scheduleItem.items["item-3"] = &model.ScheduleItem{
+		ScheduleItemID: "item-3", ScheduleID: "sched-1", WeekNumber: 1,
+		TimeSlotID: "ts-3", MemberID: "user-2", TimeSlot: ts3,
+	}
+	seedRestRule(repos, "R10")
+	repos.systemConfig.cfg.BackToBackDaysMode = model.ConstraintModeHard
+
+	// user-1 周一已有班次，再排周二即连续两天
+	member := "user-1"
+	req := &dto.BatchUpdateScheduleItemsRequest{Edits: []dto.ScheduleItemEdit{
+		{ItemID: "item-3", MemberID: &member},
+	}}
+	_, err := svc.BatchUpdateItems(context.Background(), req, "admin-1")
+	if !errors.Is(err, ErrScheduleEditConflict) {
+		t.Fatalf("期望 ErrScheduleEditConflict，实际: %v", err)
+	}
+	if !strings.Contains(err.Error(), "R10") {
+		t.Errorf("冲突信息应包含 R10，实际: %v", err)
+	}
+}
+
+func TestScheduleService_ValidateCandidate_RestSoftWarning(t *testing.T) {
+	svc, repos := setupTestScheduleService()
+	seedBasicData(repos)
+	seedDraftItems(repos)
+	ts3 := seedTuesdaySlot(repos)
+	repos.scheduleItem.items["item-3"] = &model.ScheduleItem{
+		ScheduleItemID: "item-3", ScheduleID: "sched-1", WeekNumber: 1,
+		TimeSlotID: "ts-3", MemberID: "user-2", TimeSlot: ts3,
+	}
+	seedRestRule(repos, "R10")
+	repos.systemConfig.cfg.BackToBackDaysMode = model.ConstraintModeSoft
+
+	result, err := svc.ValidateCandidate(context.Background(), "item-3", &dto.ValidateCandidateRequest{MemberID: "user-1"})
+	if err != nil {
+		t.Fatalf("ValidateCandidate 应成功: %v", err)
+	}
+	if !result.Valid {
+		t.Errorf("软约束不应阻止调整，冲突: %v", result.Conflicts)
+	}
+	if len(result.Warnings) != 1 || !strings.HasPrefix(result.Warnings[0], "R10") {
+		t.Errorf("期望一条 R10 提示，实际: %v", result.Warnings)
+	}
+}
+
+func TestScheduleService_GetQualityReport_RestSoftViolations(t *testing.T) {
+	svc, repos := setupTestScheduleService()
+	seedBasicData(repos)
+	seedDraftItems(repos)
+	// user-1 第1周周一上午、周二上午各一班：间隔不足 24 小时且连续两天
+	ts3 := seedTuesdaySlot(repos)
+	repos.scheduleItem.items["item-3"] = &model.ScheduleItem{
+		ScheduleItemID: "item-3", ScheduleID: "sched-1", WeekNumber: 1,
+		TimeSlotID: "ts-3", MemberID: "user-1", TimeSlot: ts3,
+	}
+	seedRestRule(repos, "R9", "R10")
+	repos.systemConfig.cfg.MinRestHours = 24
+	repos.systemConfig.cfg.MinRestMode = model.ConstraintModeSoft
+	repos.systemConfig.cfg.BackToBackDaysMode = model.ConstraintModeSoft
+
+	report, err := svc.GetQualityReport(context.Background(), "sched-1")
+	if err != nil {
+		t.Fatalf("GetQualityReport 应成功: %v", err)
+	}
+	if report.HardConflicts != 0 {
+		t.Errorf("软约束不应计入硬冲突，实际=%d", report.HardConflicts)
+	}
+	counts := make(map[string]int)
+	for _, v := range report.Violations {
+		counts[v.RuleCode] = v.Count
+	}
+	if counts["R9"] != 1 || counts["R10"] != 1 {
+		t.Errorf("期望 R9=1、R10=1，实际=%v", counts)
+	}
+}
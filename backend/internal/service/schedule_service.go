@@ -4,7 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
@@ -13,6 +14,7 @@ import (
 	"echo-union/backend/internal/dto"
 	"echo-union/backend/internal/model"
 	"echo-union/backend/internal/repository"
+	"echo-union/backend/pkg/redis"
 )
 
 // ── 排班模块业务错误 ──
@@ -29,18 +31,51 @@ var (
 	ErrNoActiveTimeSlots        = errors.New("无可用时间段")
 	ErrCandidateNotAvailable    = errors.New("候选人在该时段不可用")
 	ErrPhaseNotScheduling       = errors.New("学期阶段必须为 scheduling 才能执行自动排班")
+	ErrScheduleEditConflict     = errors.New("调整后的排班存在冲突")
+	ErrScheduleSlotOccupied     = errors.New("目标时段已有排班")
+	ErrScheduleItemsMismatch    = errors.New("排班项不属于同一排班表")
+	ErrScheduleSwapInvalid      = errors.New("不能与自身交换")
+	ErrAutoScheduleRunning      = errors.New("该学期已有自动排班任务在执行")
+	ErrAutoScheduleJobNotFound  = errors.New("排班任务不存在")
+	ErrAutoScheduleJobFinished  = errors.New("排班任务已结束")
+	ErrTooManyCandidates        = errors.New("候选方案数量已达上限")
+	ErrScheduleNotCandidate     = errors.New("排班表非候选方案")
+	ErrScheduleSemesterMismatch = errors.New("排班表不属于同一学期")
+	ErrSchedulePublishedExists  = errors.New("该学期已有发布中的排班表，不能替换")
+	ErrScheduleNotArchived      = errors.New("排班表非归档版本，不可恢复")
+	ErrScheduleConflictNotFound = errors.New("冲突处理任务不存在")
+	ErrScheduleConflictClosed   = errors.New("冲突处理任务已关闭")
+	ErrSimulationRuleWeight     = errors.New("仅可调整软约束规则的罚分")
+	ErrSimulationTimeSlot       = errors.New("模拟时间段结束时间须晚于开始时间")
+	ErrScheduleMemberNotDuty    = errors.New("成员不是本学期的值班成员")
 )
 
 // ScheduleService 排班业务接口
 type ScheduleService interface {
 	// 自动排班
 	AutoSchedule(ctx context.Context, req *dto.AutoScheduleRequest, callerID string) (*dto.AutoScheduleResponse, error)
+	// 提交后台自动排班任务
+	StartAutoScheduleJob(ctx context.Context, req *dto.AutoScheduleRequest, callerID string) (*dto.AutoScheduleJobResponse, error)
+	// 查询自动排班任务状态
+	GetAutoScheduleJob(ctx context.Context, jobID string) (*dto.AutoScheduleJobResponse, error)
+	// 取消自动排班任务
+	CancelAutoScheduleJob(ctx context.Context, jobID, callerID string) (*dto.AutoScheduleJobResponse, error)
+	// 订阅自动排班任务进度（任务结束后通道关闭）
+	SubscribeAutoScheduleJob(ctx context.Context, jobID string) (<-chan dto.AutoScheduleJobResponse, func(), error)
+	// 模拟排班：叠加规则、成员与时间段覆盖后求解，只返回质量报告，不写入数据
+	SimulateSchedule(ctx context.Context, req *dto.SimulateScheduleRequest) (*dto.ScheduleSimulationResponse, error)
 	// 获取排班表（含明细）
 	GetSchedule(ctx context.Context, semesterID string) (*dto.ScheduleResponse, error)
 	// 获取我的排班
 	GetMySchedule(ctx context.Context, semesterID, userID string) (*dto.ScheduleResponse, error)
 	// 手动调整排班项（草稿状态）
 	UpdateItem(ctx context.Context, itemID string, req *dto.UpdateScheduleItemRequest, callerID string) (*dto.ScheduleItemResponse, error)
+	// 原子交换两个排班项的成员（草稿状态）
+	SwapItems(ctx context.Context, req *dto.SwapScheduleItemsRequest, callerID string) ([]dto.ScheduleItemResponse, error)
+	// 将排班项移动到另一空闲时段（草稿状态）
+	MoveItem(ctx context.Context, itemID string, req *dto.MoveScheduleItemRequest, callerID string) (*dto.ScheduleItemResponse, error)
+	// 批量调整排班项（草稿状态，单事务）
+	BatchUpdateItems(ctx context.Context, req *dto.BatchUpdateScheduleItemsRequest, callerID string) ([]dto.ScheduleItemResponse, error)
 	// 校验候选人
 	ValidateCandidate(ctx context.Context, itemID string, req *dto.ValidateCandidateRequest) (*dto.ValidateCandidateResponse, error)
 	// 获取时段可用候选人
@@ -53,16 +88,55 @@ type ScheduleService interface {
 	ListChangeLogs(ctx context.Context, req *dto.ScheduleChangeLogListRequest) ([]dto.ScheduleChangeLogResponse, int64, error)
 	// 范围检测
 	CheckScope(ctx context.Context, scheduleID string) (*dto.ScopeCheckResponse, error)
+	// 候选方案列表（含质量报告）
+	ListCandidates(ctx context.Context, semesterID string) ([]dto.ScheduleSummaryResponse, error)
+	// 多方案质量对比
+	CompareSchedules(ctx context.Context, req *dto.CompareSchedulesRequest) ([]dto.ScheduleSummaryResponse, error)
+	// 将候选方案提升为当前草稿
+	PromoteCandidate(ctx context.Context, scheduleID, callerID string) (*dto.ScheduleResponse, error)
+	// 丢弃候选方案
+	DiscardCandidate(ctx context.Context, scheduleID, callerID string) error
+	// 排班方案质量报告
+	GetQualityReport(ctx context.Context, scheduleID string) (*dto.ScheduleQualityReport, error)
+	// 排班方案成员工作量（班次数、时长与加权成本）
+	GetWorkload(ctx context.Context, scheduleID string) (*dto.ScheduleWorkloadResponse, error)
+	// 学期排班版本历史
+	ListVersions(ctx context.Context, semesterID string) ([]dto.ScheduleVersionResponse, error)
+	// 两个排班版本的差异
+	DiffSchedules(ctx context.Context, req *dto.DiffSchedulesRequest) (*dto.ScheduleDiffResponse, error)
+	// 将归档版本恢复为新草稿
+	RestoreVersion(ctx context.Context, scheduleID, callerID string) (*dto.ScheduleResponse, error)
+	// 解释排班项的人选（列出每位值班成员的状态与打分）
+	ExplainItem(ctx context.Context, itemID string) (*dto.SlotExplanationResponse, error)
+	// 解释槽位（含空槽位）的排班情况
+	ExplainSlot(ctx context.Context, scheduleID string, req *dto.ExplainSlotRequest) (*dto.SlotExplanationResponse, error)
+	// 今日起需替班的值班记录（成员临时不可用）
+	ListSubstituteNeeded(ctx context.Context, scheduleID string) ([]dto.DutyRecordResponse, error)
+	// 发布后时间表变更产生的冲突处理任务
+	ListConflicts(ctx context.Context, req *dto.ScheduleConflictListRequest) ([]dto.ScheduleConflictResponse, int64, error)
+	ResolveConflict(ctx context.Context, id string, req *dto.ResolveScheduleConflictRequest, callerID string) (*dto.ScheduleConflictResponse, error)
 }
 
 type scheduleService struct {
 	repo   *repository.Repository
 	logger *zap.Logger
+	locker *semesterLocker
+
+	jobsMu   sync.Mutex
+	jobs     map[string]*autoScheduleJob // 本实例执行的后台任务
+	jobStore autoScheduleJobStore        // 任务状态跨实例共享，可为 nil
 }
 
 // NewScheduleService 创建 ScheduleService 实例
-func NewScheduleService(repo *repository.Repository, logger *zap.Logger) ScheduleService {
-	return &scheduleService{repo: repo, logger: logger}
+// rdb 可为 nil（降级为进程内锁与进程内任务状态，仅支持单实例部署）
+func NewScheduleService(repo *repository.Repository, rdb *redis.Client, logger *zap.Logger) ScheduleService {
+	return &scheduleService{
+		repo:     repo,
+		logger:   logger,
+		locker:   newSemesterLocker(rdb, logger),
+		jobs:     make(map[string]*autoScheduleJob),
+		jobStore: newAutoScheduleJobStore(rdb),
+	}
 }
 
 // ════════════════════════════════════════════════════════════
@@ -70,7 +144,32 @@ func NewScheduleService(repo *repository.Repository, logger *zap.Logger) Schedul
 // ════════════════════════════════════════════════════════════
 
 func (s *scheduleService) AutoSchedule(ctx context.Context, req *dto.AutoScheduleRequest, callerID string) (*dto.AutoScheduleResponse, error) {
+	// 同步排班与后台任务共用学期锁，避免同一学期并发生成排班表
+	unlock, ok, err := s.locker.tryLock(ctx, autoScheduleLockKey(req.SemesterID), autoScheduleLockTTL)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrAutoScheduleRunning
+	}
+	defer unlock()
+
+	return s.runAutoSchedule(ctx, req, callerID, nil)
+}
+
+// autoScheduleReporter 排班进度回调（可为 nil）
+type autoScheduleReporter func(progress dto.AutoScheduleProgress)
+
+// runAutoSchedule 执行排班主流程；ctx 取消时中止并回滚，report 用于上报进度
+func (s *scheduleService) runAutoSchedule(ctx context.Context, req *dto.AutoScheduleRequest, callerID string, report autoScheduleReporter) (*dto.AutoScheduleResponse, error) {
 	semesterID := req.SemesterID
+	progress := dto.AutoScheduleProgress{Phase: "prepare"}
+	notify := func() {
+		if report != nil {
+			report(progress)
+		}
+	}
+	notify()
 
 	// 0. 校验学期
 	semester, err := s.repo.Semester.GetByID(ctx, semesterID)
@@ -87,13 +186,26 @@ func (s *scheduleService) AutoSchedule(ctx context.Context, req *dto.AutoSchedul
 		return nil, ErrPhaseNotScheduling
 	}
 
-	// 0.2 检查是否已有非归档排班表 → 如有则在写入阶段归档
+	// 0.2 检查是否已有非归档排班表 → 如有则在写入阶段归档（生成候选方案时保留）
 	existing, err := s.repo.Schedule.GetBySemester(ctx, semesterID)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		s.logger.Error("查询已有排班表失败", zap.Error(err))
 		return nil, err
 	}
 
+	// 0.3 候选方案数量上限
+	var candidateCount int64
+	if req.AsCandidate {
+		candidateCount, err = s.repo.Schedule.CountBySemesterAndStatus(ctx, semesterID, model.ScheduleStatusCandidate)
+		if err != nil {
+			s.logger.Error("统计候选方案失败", zap.Error(err))
+			return nil, err
+		}
+		if candidateCount >= maxScheduleCandidates {
+			return nil, ErrTooManyCandidates
+		}
+	}
+
 	// ── 阶段1: 数据准备 ──
 
 	// 1.1 检查课表提交率
@@ -111,272 +223,44 @@ func (s *scheduleService) AutoSchedule(ctx context.Context, req *dto.AutoSchedul
 		return nil, ErrSubmissionRateIncomplete
 	}
 
-	// 1.2 获取候选人（duty_required + submitted）
-	assignments, err := s.repo.UserSemesterAssignment.ListDutyRequiredSubmitted(ctx, semesterID)
+	// 1.2 加载候选人、时间段、课表、不可用时间、排班规则
+	input, err := s.loadSolverInput(ctx, semester, req.RuleOverrides)
 	if err != nil {
-		s.logger.Error("查询候选人失败", zap.Error(err))
 		return nil, err
 	}
-	if len(assignments) == 0 {
-		return nil, ErrNoEligibleMembers
-	}
 
-	// 1.3 获取时间段
-	timeSlots, err := s.repo.TimeSlot.List(ctx, semesterID, nil)
-	if err != nil {
-		s.logger.Error("查询时间段失败", zap.Error(err))
+	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
-	if len(timeSlots) == 0 {
-		return nil, ErrNoActiveTimeSlots
-	}
 
-	// 1.4 获取课表
-	courses, err := s.repo.CourseSchedule.ListBySemester(ctx, semesterID)
+	// ── 阶段2/3: 可用性矩阵构建 + 求解 ──
+
+	solverName := req.Solver
+	if solverName == "" {
+		solverName = model.SolverGreedy
+	}
+	solver := newScheduleSolver(input, solverOptions{solver: solverName, seed: req.Seed}, &progress, notify)
+	result, err := solver.solve(ctx)
 	if err != nil {
-		s.logger.Error("查询课表失败", zap.Error(err))
 		return nil, err
 	}
 
-	// 1.5 获取不可用时间
-	unavailables, err := s.repo.UnavailableTime.ListBySemester(ctx, semesterID)
-	if err != nil {
-		s.logger.Error("查询不可用时间失败", zap.Error(err))
+	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
 
-	// 1.6 获取排班规则
-	rules, err := s.repo.ScheduleRule.List(ctx)
+	// 为排班结果分配值班地点（无可用地点时仅提示，不阻止生成）
+	planner, err := loadLocationPlanner(ctx, s.repo, nil)
 	if err != nil {
-		s.logger.Error("查询排班规则失败", zap.Error(err))
+		s.logger.Error("查询地点失败", zap.Error(err))
 		return nil, err
 	}
-	rulesMap := make(map[string]bool)
-	for _, r := range rules {
-		rulesMap[r.RuleCode] = r.IsEnabled
-	}
-
-	// ── 阶段2: 可用性矩阵构建 ──
-	// key: "userID:weekNumber:timeSlotID" → bool（true=可用）
-	// 同时记录冲突原因
-
-	// 构建用户课表索引: userID → []CourseSchedule
-	userCourses := make(map[string][]model.CourseSchedule)
-	for _, c := range courses {
-		userCourses[c.UserID] = append(userCourses[c.UserID], c)
-	}
-
-	// 构建用户不可用时间索引: userID → []UnavailableTime
-	userUnavailables := make(map[string][]model.UnavailableTime)
-	for _, u := range unavailables {
-		userUnavailables[u.UserID] = append(userUnavailables[u.UserID], u)
-	}
-
-	// 候选人列表
-	type candidate struct {
-		userID       string
-		departmentID string
-		name         string
-	}
-	candidates := make([]candidate, 0, len(assignments))
-	for _, a := range assignments {
-		if a.User != nil {
-			candidates = append(candidates, candidate{
-				userID:       a.UserID,
-				departmentID: a.User.DepartmentID,
-				name:         a.User.Name,
-			})
-		}
-	}
-
-	// 排班槽位: week_number × time_slot
-	type slot struct {
-		weekNumber int
-		timeSlot   model.TimeSlot
-	}
-	var slots []slot
-	for _, ts := range timeSlots {
-		slots = append(slots, slot{weekNumber: 1, timeSlot: ts})
-		slots = append(slots, slot{weekNumber: 2, timeSlot: ts})
-	}
-
-	// 可用性矩阵
-	type availability struct {
-		available bool
-		conflicts []string
-	}
-	availMatrix := make(map[string]*availability) // "userID:week:slotID"
-
-	for _, c := range candidates {
-		for _, sl := range slots {
-			key := fmt.Sprintf("%s:%d:%s", c.userID, sl.weekNumber, sl.timeSlot.TimeSlotID)
-			avail := &availability{available: true}
-
-			weekType := weekNumberToType(sl.weekNumber, semester.FirstWeekType)
-
-			// R1: 课表冲突检测（硬约束）
-			if rulesMap["R1"] {
-				for _, course := range userCourses[c.userID] {
-					if hasTimeConflict(course.DayOfWeek, course.StartTime, course.EndTime, course.WeekType,
-						sl.timeSlot.DayOfWeek, sl.timeSlot.StartTime, sl.timeSlot.EndTime, weekType) {
-						avail.available = false
-						avail.conflicts = append(avail.conflicts, fmt.Sprintf("课程冲突: %s", course.CourseName))
-					}
-				}
-			}
-
-			// R2: 不可用时间检测（硬约束）
-			if rulesMap["R2"] {
-				for _, ut := range userUnavailables[c.userID] {
-					if hasUnavailableConflict(ut, sl.timeSlot.DayOfWeek, sl.timeSlot.StartTime, sl.timeSlot.EndTime, weekType) {
-						avail.available = false
-						reason := "不可用时间冲突"
-						if ut.Reason != "" {
-							reason = fmt.Sprintf("不可用时间: %s", ut.Reason)
-						}
-						avail.conflicts = append(avail.conflicts, reason)
-					}
-				}
-			}
-
-			availMatrix[key] = avail
-		}
-	}
-
-	// ── 阶段3: 贪心排班 ──
-
-	// 统计每个槽位的可用人数
-	type slotInfo struct {
-		slot           slot
-		availableCount int
-	}
-	slotInfos := make([]slotInfo, 0, len(slots))
-	for _, sl := range slots {
-		count := 0
-		for _, c := range candidates {
-			key := fmt.Sprintf("%s:%d:%s", c.userID, sl.weekNumber, sl.timeSlot.TimeSlotID)
-			if a, ok := availMatrix[key]; ok && a.available {
-				count++
-			}
-		}
-		slotInfos = append(slotInfos, slotInfo{slot: sl, availableCount: count})
-	}
-
-	// 按可用人数升序排列（最难排的槽位优先）
-	sort.Slice(slotInfos, func(i, j int) bool {
-		return slotInfos[i].availableCount < slotInfos[j].availableCount
-	})
-
-	// 排班结果
-	type assignment struct {
-		weekNumber int
-		timeSlotID string
-		memberID   string
-	}
-	var result []assignment
-	warnings := make([]string, 0)
-
-	// 跟踪每人排班次数
-	memberCount := make(map[string]int)
-	// 跟踪每人每天已排（R6: 同人同日不重复）
-	memberDayWeek := make(map[string]bool) // "userID:week:dayOfWeek"
-	// 跟踪每天每部门已排（R3: 同日部门不重复）
-	dayDeptWeek := make(map[string]bool) // "week:dayOfWeek:deptID"
-	// 跟踪相邻班次部门（R4）
-	slotDeptWeek := make(map[string]string) // "week:slotID" → deptID
-
-	for _, si := range slotInfos {
-		sl := si.slot
-		dayKey := fmt.Sprintf("%d:%d", sl.weekNumber, sl.timeSlot.DayOfWeek)
-
-		// 收集可用候选人
-		type scoredCandidate struct {
-			candidate candidate
-			score     int // 越小越优先
-		}
-		var availCandidates []scoredCandidate
-
-		for _, c := range candidates {
-			key := fmt.Sprintf("%s:%d:%s", c.userID, sl.weekNumber, sl.timeSlot.TimeSlotID)
-			avail, ok := availMatrix[key]
-			if !ok || !avail.available {
-				continue
-			}
-
-			// R6: 同人同日不重复（硬约束）
-			mdKey := fmt.Sprintf("%s:%s", c.userID, dayKey)
-			if memberDayWeek[mdKey] {
-				continue
-			}
-
-			score := memberCount[c.userID] * 100 // 当前排班少优先
-
-			// R3: 同日部门不重复（软约束）
-			if rulesMap["R3"] {
-				ddKey := fmt.Sprintf("%s:%s", dayKey, c.departmentID)
-				if dayDeptWeek[ddKey] {
-					score += 50
-				}
-			}
-
-			// R4: 相邻班次部门不重复（软约束）
-			if rulesMap["R4"] {
-				slotKey := fmt.Sprintf("%d:%s", sl.weekNumber, sl.timeSlot.TimeSlotID)
-				if prevDept, exists := slotDeptWeek[slotKey]; exists && prevDept == c.departmentID {
-					score += 30
-				}
-			}
-
-			// R5: 单双周早八不重复（软约束）
-			if rulesMap["R5"] {
-				if sl.timeSlot.StartTime <= "08:30" {
-					otherWeek := 3 - sl.weekNumber // 1→2, 2→1
-					for _, otherSl := range slots {
-						if otherSl.weekNumber == otherWeek &&
-							otherSl.timeSlot.DayOfWeek == sl.timeSlot.DayOfWeek &&
-							otherSl.timeSlot.StartTime <= "08:30" {
-							otherKey := fmt.Sprintf("%d:%s", otherWeek, otherSl.timeSlot.TimeSlotID)
-							if assignedDept, exists := slotDeptWeek[otherKey]; exists && assignedDept == c.departmentID {
-								score += 20
-							}
-						}
-					}
-				}
-			}
-
-			availCandidates = append(availCandidates, scoredCandidate{candidate: c, score: score})
-		}
-
-		if len(availCandidates) == 0 {
-			warnings = append(warnings, fmt.Sprintf("时段 %s (第%d周 周%d %s-%s) 无可用候选人",
-				sl.timeSlot.Name, sl.weekNumber, sl.timeSlot.DayOfWeek, sl.timeSlot.StartTime, sl.timeSlot.EndTime))
-			continue
-		}
-
-		// 按分数排序，分数相同则随机（通过名字排序保证稳定性）
-		sort.Slice(availCandidates, func(i, j int) bool {
-			if availCandidates[i].score != availCandidates[j].score {
-				return availCandidates[i].score < availCandidates[j].score
-			}
-			return availCandidates[i].candidate.name < availCandidates[j].candidate.name
-		})
-
-		chosen := availCandidates[0].candidate
-		result = append(result, assignment{
-			weekNumber: sl.weekNumber,
-			timeSlotID: sl.timeSlot.TimeSlotID,
-			memberID:   chosen.userID,
-		})
-
-		// 更新跟踪状态
-		memberCount[chosen.userID]++
-		memberDayWeek[fmt.Sprintf("%s:%s", chosen.userID, dayKey)] = true
-		dayDeptWeek[fmt.Sprintf("%s:%s", dayKey, chosen.departmentID)] = true
-		slotDeptWeek[fmt.Sprintf("%d:%s", sl.weekNumber, sl.timeSlot.TimeSlotID)] = chosen.departmentID
-	}
 
 	// ── 阶段4: 输出（事务写入，保证原子性）──
 
+	progress.Phase = "persist"
+	notify()
+
 	// 开启事务
 	tx, err := s.repo.BeginTx(ctx)
 	if err != nil {
@@ -391,8 +275,8 @@ func (s *scheduleService) AutoSchedule(ctx context.Context, req *dto.AutoSchedul
 	txRepo := s.repo.WithTx(tx)
 
 	// 归档旧排班表（在事务内，避免并发创建多个活跃排班）
-	if existing != nil {
-		existing.Status = "archived"
+	if existing != nil && !req.AsCandidate {
+		existing.Status = model.ScheduleStatusArchived
 		existing.UpdatedBy = &callerID
 		if err := txRepo.Schedule.Update(ctx, existing); err != nil {
 			rollbackTx()
@@ -403,8 +287,18 @@ func (s *scheduleService) AutoSchedule(ctx context.Context, req *dto.AutoSchedul
 
 	// 创建排班表
 	schedule := &model.Schedule{
-		SemesterID: semesterID,
-		Status:     "draft",
+		SemesterID:    semesterID,
+		Status:        model.ScheduleStatusDraft,
+		Name:          req.Name,
+		Solver:        solverName,
+		Seed:          req.Seed,
+		RuleOverrides: req.RuleOverrides,
+	}
+	if req.AsCandidate {
+		schedule.Status = model.ScheduleStatusCandidate
+		if schedule.Name == "" {
+			schedule.Name = fmt.Sprintf("候选方案 %d", candidateCount+1)
+		}
 	}
 	schedule.CreatedBy = &callerID
 	schedule.UpdatedBy = &callerID
@@ -416,8 +310,8 @@ func (s *scheduleService) AutoSchedule(ctx context.Context, req *dto.AutoSchedul
 	}
 
 	// 批量创建排班项
-	items := make([]model.ScheduleItem, 0, len(result))
-	for _, r := range result {
+	items := make([]model.ScheduleItem, 0, len(result.assignments))
+	for _, r := range result.assignments {
 		item := model.ScheduleItem{
 			ScheduleID: schedule.ScheduleID,
 			WeekNumber: r.weekNumber,
@@ -428,6 +322,11 @@ func (s *scheduleService) AutoSchedule(ctx context.Context, req *dto.AutoSchedul
 		item.UpdatedBy = &callerID
 		items = append(items, item)
 	}
+	slotsByID := make(map[string]model.TimeSlot, len(input.timeSlots))
+	for _, ts := range input.timeSlots {
+		slotsByID[ts.TimeSlotID] = ts
+	}
+	warnings := append(result.warnings, planner.assignLocations(items, slotsByID)...)
 
 	if len(items) > 0 {
 		if err := txRepo.ScheduleItem.BatchCreate(ctx, items); err != nil {
@@ -438,9 +337,9 @@ func (s *scheduleService) AutoSchedule(ctx context.Context, req *dto.AutoSchedul
 	}
 
 	// 保存成员快照
-	snapshots := make([]model.ScheduleMemberSnapshot, 0, len(candidates))
+	snapshots := make([]model.ScheduleMemberSnapshot, 0, len(input.candidates))
 	now := time.Now()
-	for _, c := range candidates {
+	for _, c := range input.candidates {
 		snapshots = append(snapshots, model.ScheduleMemberSnapshot{
 			ScheduleID:   schedule.ScheduleID,
 			UserID:       c.userID,
@@ -472,14 +371,137 @@ func (s *scheduleService) AutoSchedule(ctx context.Context, req *dto.AutoSchedul
 		return nil, err
 	}
 
+	progress.Phase = "done"
+	notify()
+
 	return &dto.AutoScheduleResponse{
 		Schedule:    scheduleResp,
-		TotalSlots:  len(slots),
-		FilledSlots: len(result),
+		TotalSlots:  len(input.slots),
+		FilledSlots: len(result.assignments),
 		Warnings:    warnings,
 	}, nil
 }
 
+// loadSolverInput 加载求解所需数据（duty_required + submitted 的候选人、时间段、课表、不可用时间、规则）
+// ruleOverrides 非空时覆盖对应规则的启用状态，仅作用于本次求解
+func (s *scheduleService) loadSolverInput(ctx context.Context, semester *model.Semester, ruleOverrides map[string]bool) (*solverInput, error) {
+	semesterID := semester.SemesterID
+
+	// 获取候选人
+	assignments, err := s.repo.UserSemesterAssignment.ListDutyRequiredSubmitted(ctx, semesterID)
+	if err != nil {
+		s.logger.Error("查询候选人失败", zap.Error(err))
+		return nil, err
+	}
+	if len(assignments) == 0 {
+		return nil, ErrNoEligibleMembers
+	}
+
+	// 获取时间段
+	timeSlots, err := s.repo.TimeSlot.List(ctx, semesterID, nil)
+	if err != nil {
+		s.logger.Error("查询时间段失败", zap.Error(err))
+		return nil, err
+	}
+	if len(timeSlots) == 0 {
+		return nil, ErrNoActiveTimeSlots
+	}
+
+	input, err := s.loadSolverConstraints(ctx, semester, timeSlots, ruleOverrides)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range assignments {
+		if a.User != nil {
+			input.candidates = append(input.candidates, scheduleCandidate{
+				userID:       a.UserID,
+				departmentID: a.User.DepartmentID,
+				name:         a.User.Name,
+			})
+		}
+	}
+	return input, nil
+}
+
+// loadSolverConstraints 加载课表、不可用时间、规则、搭配约束、休息约束参数与技能要求并构建排班槽位（不含候选人）
+func (s *scheduleService) loadSolverConstraints(ctx context.Context, semester *model.Semester, timeSlots []model.TimeSlot, ruleOverrides map[string]bool) (*solverInput, error) {
+	semesterID := semester.SemesterID
+
+	courses, err := s.repo.CourseSchedule.ListBySemester(ctx, semesterID)
+	if err != nil {
+		s.logger.Error("查询课表失败", zap.Error(err))
+		return nil, err
+	}
+
+	unavailables, err := s.repo.UnavailableTime.ListBySemester(ctx, semesterID)
+	if err != nil {
+		s.logger.Error("查询不可用时间失败", zap.Error(err))
+		return nil, err
+	}
+
+	rules, err := s.repo.ScheduleRule.List(ctx)
+	if err != nil {
+		s.logger.Error("查询排班规则失败", zap.Error(err))
+		return nil, err
+	}
+	rulesMap := make(map[string]bool)
+	configurable := make(map[string]bool)
+	for _, r := range rules {
+		rulesMap[r.RuleCode] = r.IsEnabled
+		configurable[r.RuleCode] = r.IsConfigurable
+	}
+	// 规则覆盖仅允许作用于可配置的软约束
+	for code, enabled := range ruleOverrides {
+		allowed, ok := configurable[code]
+		if !ok {
+			return nil, ErrScheduleRuleNotFound
+		}
+		if !allowed {
+			return nil, ErrScheduleRuleNotConfigurable
+		}
+		rulesMap[code] = enabled
+	}
+
+	pairs, err := s.repo.PairConstraint.ListBySemester(ctx, semesterID)
+	if err != nil {
+		s.logger.Error("查询搭配约束失败", zap.Error(err))
+		return nil, err
+	}
+
+	var required []model.Skill
+	for i := range timeSlots {
+		required = append(required, timeSlotSkills(&timeSlots[i])...)
+	}
+	skills, err := loadSkillHolders(ctx, s.repo, required)
+	if err != nil {
+		s.logger.Error("查询成员技能失败", zap.Error(err))
+		return nil, err
+	}
+
+	input := &solverInput{
+		semester:         semester,
+		userCourses:      make(map[string][]model.CourseSchedule),
+		userUnavailables: make(map[string][]model.UnavailableTime),
+		rules:            rulesMap,
+		timeSlots:        timeSlots,
+		pairs:            newPairIndex(pairs),
+		rest:             loadRestPolicy(ctx, s.repo),
+		skills:           skills,
+	}
+	for _, c := range courses {
+		input.userCourses[c.UserID] = append(input.userCourses[c.UserID], c)
+	}
+	for _, u := range unavailables {
+		input.userUnavailables[u.UserID] = append(input.userUnavailables[u.UserID], u)
+	}
+	for _, ts := range timeSlots {
+		input.slots = append(input.slots, scheduleSlot{weekNumber: 1, timeSlot: ts})
+		input.slots = append(input.slots, scheduleSlot{weekNumber: 2, timeSlot: ts})
+	}
+	return input, nil
+}
+
 // ════════════════════════════════════════════════════════════
 // GetSchedule — 获取排班表
 // ════════════════════════════════════════════════════════════
@@ -518,11 +540,16 @@ func (s *scheduleService) GetMySchedule(ctx context.Context, semesterID, userID
 	}
 
 	resp := &dto.ScheduleResponse{
-		ID:         schedule.ScheduleID,
-		SemesterID: schedule.SemesterID,
-		Status:     schedule.Status,
-		CreatedAt:  schedule.CreatedAt.Format("2006-01-02T15:04:05Z"),
-		UpdatedAt:  schedule.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+		ID:             schedule.ScheduleID,
+		SemesterID:     schedule.SemesterID,
+		Status:         schedule.Status,
+		Name:           schedule.Name,
+		Solver:         schedule.Solver,
+		Seed:           schedule.Seed,
+		RuleOverrides:  schedule.RuleOverrides,
+		RestoredFromID: schedule.RestoredFromID,
+		CreatedAt:      schedule.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		UpdatedAt:      schedule.UpdatedAt.Format("2006-01-02T15:04:05Z"),
 	}
 
 	if schedule.PublishedAt != nil {
@@ -573,6 +600,17 @@ func (s *scheduleService) UpdateItem(ctx context.Context, itemID string, req *dt
 	}
 	if req.LocationID != nil {
 		item.LocationID = req.LocationID
+		allItems, err := s.repo.ScheduleItem.ListBySchedule(ctx, schedule.ScheduleID)
+		if err != nil {
+			return nil, err
+		}
+		planner, err := loadLocationPlanner(ctx, s.repo, allItems)
+		if err != nil {
+			return nil, err
+		}
+		if conflicts := itemLocationConflicts(planner, item); len(conflicts) > 0 {
+			return nil, fmt.Errorf("%w: %s", ErrScheduleEditConflict, strings.Join(conflicts, "; "))
+		}
 	}
 	item.UpdatedBy = &callerID
 
@@ -591,6 +629,275 @@ func (s *scheduleService) UpdateItem(ctx context.Context, itemID string, req *dt
 	return &resp, nil
 }
 
+// ════════════════════════════════════════════════════════════
+// SwapItems / MoveItem / BatchUpdateItems — 草稿原子调整
+// ════════════════════════════════════════════════════════════
+//
+// 三者共用 applyDraftEdits：
+//   1. 在内存中把修改应用到整张排班表的副本，得到"最终状态"
+//   2. 仅对发生变化的排班项，按最终状态校验 R1/R2/R6/R7 及硬约束模式下的 R8/R9/R10
+//      （避免逐条 PUT 时的中间态误报，例如两人互换同日班次）；
+//      手工指定的地点校验绑定、开放时段与容量，移动的排班项按新时段重新分配地点
+//   3. 校验通过后在单个事务中写入全部修改
+
+// draftEdit 单个排班项的待应用修改（nil 表示不变）
+type draftEdit struct {
+	itemID     string
+	memberID   *string
+	locationID *string
+	weekNumber *int
+	timeSlotID *string
+}
+
+func (s *scheduleService) SwapItems(ctx context.Context, req *dto.SwapScheduleItemsRequest, callerID string) ([]dto.ScheduleItemResponse, error) {
+	if req.ItemAID == req.ItemBID {
+		return nil, ErrScheduleSwapInvalid
+	}
+
+	itemA, err := s.repo.ScheduleItem.GetByID(ctx, req.ItemAID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrScheduleItemNotFound
+		}
+		return nil, err
+	}
+	itemB, err := s.repo.ScheduleItem.GetByID(ctx, req.ItemBID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrScheduleItemNotFound
+		}
+		return nil, err
+	}
+
+	memberA, memberB := itemA.MemberID, itemB.MemberID
+	return s.applyDraftEdits(ctx, []draftEdit{
+		{itemID: itemA.ScheduleItemID, memberID: &memberB},
+		{itemID: itemB.ScheduleItemID, memberID: &memberA},
+	}, callerID)
+}
+
+func (s *scheduleService) MoveItem(ctx context.Context, itemID string, req *dto.MoveScheduleItemRequest, callerID string) (*dto.ScheduleItemResponse, error) {
+	weekNumber, timeSlotID := req.WeekNumber, req.TimeSlotID
+	result, err := s.applyDraftEdits(ctx, []draftEdit{
+		{itemID: itemID, weekNumber: &weekNumber, timeSlotID: &timeSlotID},
+	}, callerID)
+	if err != nil {
+		return nil, err
+	}
+	return &result[0], nil
+}
+
+func (s *scheduleService) BatchUpdateItems(ctx context.Context, req *dto.BatchUpdateScheduleItemsRequest, callerID string) ([]dto.ScheduleItemResponse, error) {
+	edits := make([]draftEdit, 0, len(req.Edits))
+	for _, e := range req.Edits {
+		edits = append(edits, draftEdit{itemID: e.ItemID, memberID: e.MemberID, locationID: e.LocationID})
+	}
+	return s.applyDraftEdits(ctx, edits, callerID)
+}
+
+// checkDutyMembers 校验成员均为学期内需要值班的成员
+func (s *scheduleService) checkDutyMembers(ctx context.Context, semesterID string, memberIDs []string) error {
+	if len(memberIDs) == 0 {
+		return nil
+	}
+	assignments, err := s.repo.UserSemesterAssignment.ListDutyRequiredBySemester(ctx, semesterID)
+	if err != nil {
+		s.logger.Error("查询值班成员失败", zap.Error(err))
+		return err
+	}
+	duty := make(map[string]bool, len(assignments))
+	for _, a := range assignments {
+		duty[a.UserID] = true
+	}
+	for _, id := range memberIDs {
+		if !duty[id] {
+			return fmt.Errorf("%w: %s", ErrScheduleMemberNotDuty, id)
+		}
+	}
+	return nil
+}
+
+// applyDraftEdits 按最终状态校验并在单事务中应用一组草稿调整，返回调整后的排班项（顺序与 edits 一致）
+func (s *scheduleService) applyDraftEdits(ctx context.Context, edits []draftEdit, callerID string) ([]dto.ScheduleItemResponse, error) {
+	first, err := s.repo.ScheduleItem.GetByID(ctx, edits[0].itemID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrScheduleItemNotFound
+		}
+		s.logger.Error("查询排班项失败", zap.Error(err))
+		return nil, err
+	}
+
+	schedule, err := s.repo.Schedule.GetByID(ctx, first.ScheduleID)
+	if err != nil {
+		s.logger.Error("查询排班表失败", zap.Error(err))
+		return nil, err
+	}
+	if schedule.Status != model.ScheduleStatusDraft {
+		return nil, ErrScheduleNotDraft
+	}
+
+	semester, err := s.repo.Semester.GetByID(ctx, schedule.SemesterID)
+	if err != nil {
+		s.logger.Error("查询学期失败", zap.Error(err))
+		return nil, err
+	}
+
+	// 0. 新成员须为本学期值班成员（未知用户同样拒绝，避免写入时外键报错）
+	var memberIDs []string
+	for _, e := range edits {
+		if e.memberID != nil {
+			memberIDs = append(memberIDs, *e.memberID)
+		}
+	}
+	if err := s.checkDutyMembers(ctx, semester.SemesterID, memberIDs); err != nil {
+		return nil, err
+	}
+
+	// 1. 构建最终状态
+	allItems, err := s.repo.ScheduleItem.ListBySchedule(ctx, schedule.ScheduleID)
+	if err != nil {
+		s.logger.Error("查询排班明细失败", zap.Error(err))
+		return nil, err
+	}
+	index := make(map[string]int, len(allItems))
+	for i := range allItems {
+		index[allItems[i].ScheduleItemID] = i
+	}
+
+	changed := make(map[string]bool)
+	relocate := make(map[string]bool)  // 移动后需重新分配地点
+	locChecks := make(map[string]bool) // 手工指定地点需校验
+	for _, e := range edits {
+		i, ok := index[e.itemID]
+		if !ok {
+			if _, err := s.repo.ScheduleItem.GetByID(ctx, e.itemID); errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrScheduleItemNotFound
+			}
+			return nil, ErrScheduleItemsMismatch
+		}
+		item := &allItems[i]
+		if e.memberID != nil {
+			item.MemberID = *e.memberID
+			item.Member = nil
+		}
+		if e.locationID != nil {
+			item.LocationID = e.locationID
+			item.Location = nil
+			locChecks[item.ScheduleItemID] = true
+		}
+		if e.timeSlotID != nil && e.weekNumber != nil {
+			if item.TimeSlotID != *e.timeSlotID || item.WeekNumber != *e.weekNumber {
+				ts, err := s.repo.TimeSlot.GetByID(ctx, *e.timeSlotID)
+				if err != nil {
+					if errors.Is(err, gorm.ErrRecordNotFound) {
+						return nil, ErrTimeSlotNotFound
+					}
+					return nil, err
+				}
+				// 只能移动到本学期启用中的时间段
+				if ts.SemesterID == nil || *ts.SemesterID != semester.SemesterID || !ts.IsActive {
+					return nil, ErrTimeSlotNotFound
+				}
+				item.TimeSlotID = ts.TimeSlotID
+				item.TimeSlot = ts
+				item.WeekNumber = *e.weekNumber
+				if e.locationID == nil {
+					item.LocationID, item.Location = nil, nil
+					relocate[item.ScheduleItemID] = true
+				}
+			}
+		}
+		changed[item.ScheduleItemID] = true
+	}
+
+	// 同一 (周次, 时段) 只允许一个排班项
+	occupied := make(map[string]bool, len(allItems))
+	for _, item := range allItems {
+		key := fmt.Sprintf("%d:%s", item.WeekNumber, item.TimeSlotID)
+		if occupied[key] {
+			return nil, ErrScheduleSlotOccupied
+		}
+		occupied[key] = true
+	}
+
+	// 移动的排班项按新时段重新分配地点
+	planner, err := loadLocationPlanner(ctx, s.repo, allItems)
+	if err != nil {
+		s.logger.Error("查询地点失败", zap.Error(err))
+		return nil, err
+	}
+	for i := range allItems {
+		item := &allItems[i]
+		if relocate[item.ScheduleItemID] && item.TimeSlot != nil {
+			item.LocationID = planner.pick(item.ScheduleItemID, item.WeekNumber, *item.TimeSlot)
+		}
+	}
+
+	// 2. 按最终状态校验变化的排班项
+	rulesMap := s.enabledRules(ctx)
+	var conflicts []string
+	for i := range allItems {
+		item := &allItems[i]
+		if !changed[item.ScheduleItemID] {
+			continue
+		}
+		itemConflicts := s.memberSlotConflicts(ctx, item.MemberID, semester, rulesMap, item, allItems)
+		if locChecks[item.ScheduleItemID] {
+			itemConflicts = append(itemConflicts, itemLocationConflicts(planner, item)...)
+		}
+		for _, c := range itemConflicts {
+			conflicts = append(conflicts, fmt.Sprintf("%s: %s", describeItem(item), c))
+		}
+	}
+	if len(conflicts) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrScheduleEditConflict, strings.Join(conflicts, "; "))
+	}
+
+	// 3. 事务写入
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		s.logger.Error("开启事务失败", zap.Error(err))
+		return nil, err
+	}
+	rollbackTx := func() {
+		if tx != nil {
+			tx.Rollback()
+		}
+	}
+	txRepo := s.repo.WithTx(tx)
+
+	for i := range allItems {
+		item := &allItems[i]
+		if !changed[item.ScheduleItemID] {
+			continue
+		}
+		item.UpdatedBy = &callerID
+		if err := txRepo.ScheduleItem.Update(ctx, item); err != nil {
+			rollbackTx()
+			s.logger.Error("批量更新排班项失败", zap.String("itemID", item.ScheduleItemID), zap.Error(err))
+			return nil, err
+		}
+	}
+
+	if tx != nil {
+		if err := tx.Commit().Error; err != nil {
+			s.logger.Error("提交事务失败", zap.Error(err))
+			return nil, err
+		}
+	}
+
+	result := make([]dto.ScheduleItemResponse, 0, len(edits))
+	for _, e := range edits {
+		updated, err := s.repo.ScheduleItem.GetByID(ctx, e.itemID)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, s.toScheduleItemResponse(updated))
+	}
+	return result, nil
+}
+
 // ════════════════════════════════════════════════════════════
 // ValidateCandidate — 校验候选人
 // ════════════════════════════════════════════════════════════
@@ -610,9 +917,23 @@ func (s *scheduleService) ValidateCandidate(ctx context.Context, itemID string,
 	}
 
 	conflicts := s.checkCandidateConflicts(ctx, req.MemberID, schedule.SemesterID, item, schedule)
+
+	// 软约束模式的休息约束与未满足的需同时段搭配不阻止调整，仅作提示
+	var warnings []string
+	if item.TimeSlot != nil {
+		allItems, _ := s.repo.ScheduleItem.ListBySchedule(ctx, schedule.ScheduleID)
+		rulesMap := s.enabledRules(ctx)
+		_, warnings = s.restConflicts(ctx, req.MemberID, rulesMap, item, allItems)
+		if rulesMap["R7"] {
+			_, pairWarnings := s.pairConflicts(ctx, req.MemberID, schedule.SemesterID, item, allItems)
+			warnings = append(warnings, pairWarnings...)
+		}
+	}
+
 	return &dto.ValidateCandidateResponse{
 		Valid:     len(conflicts) == 0,
 		Conflicts: conflicts,
+		Warnings:  warnings,
 	}, nil
 }
 
@@ -682,19 +1003,62 @@ func (s *scheduleService) Publish(ctx context.Context, req *dto.PublishScheduleR
 		return nil, ErrScheduleCannotPublish
 	}
 
+	semester, err := s.repo.Semester.GetByID(ctx, schedule.SemesterID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSemesterNotFound
+		}
+		s.logger.Error("查询学期失败", zap.Error(err))
+		return nil, err
+	}
+
+	// 发布 + 按校历生成值班记录（事务保证原子性）
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		s.logger.Error("开启事务失败", zap.Error(err))
+		return nil, err
+	}
+	rollbackTx := func() {
+		if tx != nil {
+			tx.Rollback()
+		}
+	}
+	txRepo := s.repo.WithTx(tx)
+
 	now := time.Now()
 	schedule.Status = "published"
 	schedule.PublishedAt = &now
 	schedule.UpdatedBy = &callerID
 
-	if err := s.repo.Schedule.Update(ctx, schedule); err != nil {
+	if err := txRepo.Schedule.Update(ctx, schedule); err != nil {
+		rollbackTx()
 		s.logger.Error("发布排班表失败", zap.Error(err))
 		return nil, err
 	}
 
+	cal, err := loadSemesterCalendar(ctx, txRepo, semester)
+	if err != nil {
+		rollbackTx()
+		s.logger.Error("加载校历失败", zap.Error(err))
+		return nil, err
+	}
+	records, err := syncDutyRecords(ctx, txRepo, schedule, cal, now, callerID)
+	if err != nil {
+		rollbackTx()
+		s.logger.Error("生成值班记录失败", zap.Error(err))
+		return nil, err
+	}
+
+	if tx != nil {
+		if err := tx.Commit().Error; err != nil {
+			s.logger.Error("提交事务失败", zap.Error(err))
+			return nil, err
+		}
+	}
+	notifySubstituteNeeded(ctx, s.repo, s.logger, needingSubstitute(records))
+
 	// 发布成功后自动将学期 Phase 推进到 published
-	semester, semErr := s.repo.Semester.GetByID(ctx, schedule.SemesterID)
-	if semErr == nil && semester.Phase == model.SemesterPhaseScheduling {
+	if semester.Phase == model.SemesterPhaseScheduling {
 		semester.Phase = model.SemesterPhasePublished
 		semester.UpdatedBy = &callerID
 		if updateErr := s.repo.Semester.Update(ctx, semester); updateErr != nil {
@@ -772,6 +1136,20 @@ func (s *scheduleService) UpdatePublishedItem(ctx context.Context, itemID string
 		return nil, err
 	}
 
+	// 今日起仍由原成员负责的待值班记录随之改派
+	if err := txRepo.DutyRecord.UpdatePendingMemberByItemFrom(ctx, item.ScheduleItemID, dateOnly(time.Now()), changeLog.OriginalMemberID, req.MemberID, callerID); err != nil {
+		rollbackTx()
+		s.logger.Error("改派值班记录失败", zap.Error(err))
+		return nil, err
+	}
+
+	// 原成员的时间表冲突随改派解除
+	if err := txRepo.ScheduleConflict.CloseOpenByItem(ctx, item.ScheduleItemID, "排班项已改派", callerID); err != nil {
+		rollbackTx()
+		s.logger.Error("关闭冲突任务失败", zap.Error(err))
+		return nil, err
+	}
+
 	if tx != nil {
 		if err := tx.Commit().Error; err != nil {
 			s.logger.Error("提交事务失败", zap.Error(err))
@@ -801,7 +1179,7 @@ func (s *scheduleService) ListChangeLogs(ctx context.Context, req *dto.ScheduleC
 
 	result := make([]dto.ScheduleChangeLogResponse, 0, len(logs))
 	for _, l := range logs {
-		result = append(result, dto.ScheduleChangeLogResponse{
+		resp := dto.ScheduleChangeLogResponse{
 			ID:               l.ChangeLogID,
 			ScheduleID:       l.ScheduleID,
 			ScheduleItemID:   l.ScheduleItemID,
@@ -811,7 +1189,11 @@ func (s *scheduleService) ListChangeLogs(ctx context.Context, req *dto.ScheduleC
 			Reason:           l.Reason,
 			OperatorID:       l.OperatorID,
 			CreatedAt:        l.CreatedAt.Format("2006-01-02T15:04:05Z"),
-		})
+		}
+		if l.DutyDate != nil {
+			resp.DutyDate = l.DutyDate.Format(model.TimeFormatDate)
+		}
+		result = append(result, resp)
 	}
 
 	return result, total, nil
@@ -924,8 +1306,12 @@ func hasTimeConflict(courseDOW int, courseStart, courseEnd, courseWeekType strin
 	return slotStart < courseEnd && courseStart < slotEnd
 }
 
-// hasUnavailableConflict 检查不可用时间冲突
+// hasUnavailableConflict 检查不可用时间与排班模板时段的冲突。
+// once 类型只影响 SpecificDate 当天的值班记录（见 refreshSubstituteFlags），不阻塞模板排班。
 func hasUnavailableConflict(ut model.UnavailableTime, slotDOW int, slotStart, slotEnd, slotWeekType string) bool {
+	if isOnceOff(ut) {
+		return false
+	}
 	// 检查星期
 	if ut.DayOfWeek != slotDOW {
 		return false
@@ -934,33 +1320,31 @@ func hasUnavailableConflict(ut model.UnavailableTime, slotDOW int, slotStart, sl
 	if ut.WeekType != "all" && slotWeekType != "all" && ut.WeekType != slotWeekType {
 		return false
 	}
-	// weekly 类型直接检查时间重叠
-	// once 类型也检查（简化处理：按模板排班不区分具体日期）
 	return slotStart < ut.EndTime && ut.StartTime < slotEnd
 }
 
 // checkCandidateConflicts 检查候选人在指定排班项时段的冲突
 func (s *scheduleService) checkCandidateConflicts(ctx context.Context, memberID, semesterID string, item *model.ScheduleItem, schedule *model.Schedule) []string {
-	var conflicts []string
-
 	// 获取学期信息
 	semester, err := s.repo.Semester.GetByID(ctx, semesterID)
 	if err != nil {
 		return []string{"无法获取学期信息"}
 	}
 
-	// 获取规则
-	rules, _ := s.repo.ScheduleRule.List(ctx)
-	rulesMap := make(map[string]bool)
-	for _, r := range rules {
-		rulesMap[r.RuleCode] = r.IsEnabled
-	}
+	allItems, _ := s.repo.ScheduleItem.ListBySchedule(ctx, schedule.ScheduleID)
+	return s.memberSlotConflicts(ctx, memberID, semester, s.enabledRules(ctx), item, allItems)
+}
+
+// memberSlotConflicts 检查成员在 item 时段的冲突。
+// allItems 为用于 R6 / R7 / R8 / R9 / R10 判定的排班项全集，可以是数据库当前状态，也可以是批量调整后的最终状态。
+func (s *scheduleService) memberSlotConflicts(ctx context.Context, memberID string, semester *model.Semester, rulesMap map[string]bool, item *model.ScheduleItem, allItems []model.ScheduleItem) []string {
+	var conflicts []string
 
 	weekType := weekNumberToType(item.WeekNumber, semester.FirstWeekType)
 
 	// R1: 课表冲突
 	if rulesMap["R1"] {
-		courses, _ := s.repo.CourseSchedule.ListByUserAndSemester(ctx, memberID, semesterID)
+		courses, _ := s.repo.CourseSchedule.ListByUserAndSemester(ctx, memberID, semester.SemesterID)
 		if item.TimeSlot != nil {
 			for _, c := range courses {
 				if hasTimeConflict(c.DayOfWeek, c.StartTime, c.EndTime, c.WeekType,
@@ -973,7 +1357,7 @@ func (s *scheduleService) checkCandidateConflicts(ctx context.Context, memberID,
 
 	// R2: 不可用时间
 	if rulesMap["R2"] {
-		unavailables, _ := s.repo.UnavailableTime.ListByUserAndSemester(ctx, memberID, semesterID)
+		unavailables, _ := s.repo.UnavailableTime.ListByUserAndSemester(ctx, memberID, semester.SemesterID)
 		if item.TimeSlot != nil {
 			for _, ut := range unavailables {
 				if hasUnavailableConflict(ut, item.TimeSlot.DayOfWeek, item.TimeSlot.StartTime, item.TimeSlot.EndTime, weekType) {
@@ -987,8 +1371,20 @@ func (s *scheduleService) checkCandidateConflicts(ctx context.Context, memberID,
 		}
 	}
 
+	// 时段技能要求
+	if item.TimeSlot != nil {
+		if required := timeSlotSkills(item.TimeSlot); len(required) > 0 {
+			holders, err := loadSkillHolders(ctx, s.repo, required)
+			if err != nil {
+				s.logger.Warn("查询成员技能失败", zap.Error(err))
+			}
+			for _, name := range holders.missing(memberID, required) {
+				conflicts = append(conflicts, fmt.Sprintf("缺少技能: %s", name))
+			}
+		}
+	}
+
 	// R6: 同人同日不重复
-	allItems, _ := s.repo.ScheduleItem.ListBySchedule(ctx, schedule.ScheduleID)
 	if item.TimeSlot != nil {
 		for _, other := range allItems {
 			if other.ScheduleItemID == item.ScheduleItemID {
@@ -1002,9 +1398,101 @@ func (s *scheduleService) checkCandidateConflicts(ctx context.Context, memberID,
 		}
 	}
 
+	// R7: 成员搭配约束（与自动排班一致：需同时段为软约束，不阻止调整）
+	if rulesMap["R7"] && item.TimeSlot != nil {
+		hard, _ := s.pairConflicts(ctx, memberID, semester.SemesterID, item, allItems)
+		conflicts = append(conflicts, hard...)
+	}
+
+	// R8 / R9 / R10: 休息约束（仅硬约束模式拒绝调整）
+	if item.TimeSlot != nil {
+		hard, _ := s.restConflicts(ctx, memberID, rulesMap, item, allItems)
+		conflicts = append(conflicts, hard...)
+	}
+
 	return conflicts
 }
 
+// pairConflicts 检查成员排在 item 时段是否违反搭配约束，按硬 / 软（需同时段）分别返回；搭档的时段取自 allItems
+func (s *scheduleService) pairConflicts(ctx context.Context, memberID, semesterID string, item *model.ScheduleItem, allItems []model.ScheduleItem) (hard, soft []string) {
+	constraints, err := s.repo.PairConstraint.ListBySemester(ctx, semesterID)
+	if err != nil {
+		s.logger.Warn("查询搭配约束失败", zap.Error(err))
+		return nil, nil
+	}
+	index := newPairIndex(constraints)
+	if len(index[memberID]) == 0 {
+		return nil, nil
+	}
+
+	day := item.TimeSlot.DayOfWeek
+	daySlots, _ := s.repo.TimeSlot.List(ctx, semesterID, &day)
+	violations := index.check(memberID, *item.TimeSlot, func(partnerID string) []model.TimeSlot {
+		var list []model.TimeSlot
+		for _, other := range allItems {
+			if other.ScheduleItemID != item.ScheduleItemID && other.MemberID == partnerID &&
+				other.WeekNumber == item.WeekNumber && other.TimeSlot != nil {
+				list = append(list, *other.TimeSlot)
+			}
+		}
+		return list
+	}, daySlots)
+
+	for _, v := range violations {
+		if v.hard() {
+			hard = append(hard, v.String())
+		} else {
+			soft = append(soft, v.String())
+		}
+	}
+	return hard, soft
+}
+
+// restConflicts 检查成员排在 item 时段的休息约束，按硬 / 软模式分别返回；成员的其他班次取自 allItems
+func (s *scheduleService) restConflicts(ctx context.Context, memberID string, rulesMap map[string]bool, item *model.ScheduleItem, allItems []model.ScheduleItem) (hard, soft []string) {
+	if !rulesMap["R8"] && !rulesMap["R9"] && !rulesMap["R10"] {
+		return nil, nil
+	}
+	var others []restShift
+	for _, other := range allItems {
+		if other.ScheduleItemID != item.ScheduleItemID && other.MemberID == memberID && other.TimeSlot != nil {
+			others = append(others, restShift{weekNumber: other.WeekNumber, timeSlot: *other.TimeSlot})
+		}
+	}
+	target := restShift{weekNumber: item.WeekNumber, timeSlot: *item.TimeSlot}
+	for _, v := range loadRestPolicy(ctx, s.repo).check(rulesMap, target, others) {
+		if v.hard {
+			hard = append(hard, v.String())
+		} else {
+			soft = append(soft, v.String())
+		}
+	}
+	return hard, soft
+}
+
+// enabledRules 加载排班规则启用状态: ruleCode → isEnabled
+func (s *scheduleService) enabledRules(ctx context.Context) map[string]bool {
+	return loadEnabledRules(ctx, s.repo)
+}
+
+// loadEnabledRules 在指定 repo（可为事务 repo）上加载排班规则启用状态
+func loadEnabledRules(ctx context.Context, repo *repository.Repository) map[string]bool {
+	rules, _ := repo.ScheduleRule.List(ctx)
+	rulesMap := make(map[string]bool, len(rules))
+	for _, r := range rules {
+		rulesMap[r.RuleCode] = r.IsEnabled
+	}
+	return rulesMap
+}
+
+// describeItem 生成排班项的可读描述，用于冲突提示
+func describeItem(item *model.ScheduleItem) string {
+	if item.TimeSlot == nil {
+		return fmt.Sprintf("第%d周", item.WeekNumber)
+	}
+	return fmt.Sprintf("第%d周 周%d %s", item.WeekNumber, item.TimeSlot.DayOfWeek, item.TimeSlot.Name)
+}
+
 // buildScheduleResponse 构建排班表完整响应
 func (s *scheduleService) buildScheduleResponse(ctx context.Context, schedule *model.Schedule) (*dto.ScheduleResponse, error) {
 	items, err := s.repo.ScheduleItem.ListBySchedule(ctx, schedule.ScheduleID)
@@ -1013,11 +1501,16 @@ func (s *scheduleService) buildScheduleResponse(ctx context.Context, schedule *m
 	}
 
 	resp := &dto.ScheduleResponse{
-		ID:         schedule.ScheduleID,
-		SemesterID: schedule.SemesterID,
-		Status:     schedule.Status,
-		CreatedAt:  schedule.CreatedAt.Format("2006-01-02T15:04:05Z"),
-		UpdatedAt:  schedule.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+		ID:             schedule.ScheduleID,
+		SemesterID:     schedule.SemesterID,
+		Status:         schedule.Status,
+		Name:           schedule.Name,
+		Solver:         schedule.Solver,
+		Seed:           schedule.Seed,
+		RuleOverrides:  schedule.RuleOverrides,
+		RestoredFromID: schedule.RestoredFromID,
+		CreatedAt:      schedule.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		UpdatedAt:      schedule.UpdatedAt.Format("2006-01-02T15:04:05Z"),
 	}
 
 	if schedule.PublishedAt != nil {
@@ -1051,27 +1544,11 @@ func (s *scheduleService) toScheduleItemResponse(item *model.ScheduleItem) dto.S
 	}
 
 	if item.TimeSlot != nil {
-		resp.TimeSlot = &dto.TimeSlotBrief{
-			ID:        item.TimeSlot.TimeSlotID,
-			Name:      item.TimeSlot.Name,
-			DayOfWeek: item.TimeSlot.DayOfWeek,
-			StartTime: item.TimeSlot.StartTime,
-			EndTime:   item.TimeSlot.EndTime,
-		}
+		resp.TimeSlot = toTimeSlotBrief(item.TimeSlot)
 	}
 
 	if item.Member != nil {
-		resp.Member = &dto.MemberBrief{
-			ID:        item.Member.UserID,
-			Name:      item.Member.Name,
-			StudentID: item.Member.StudentID,
-		}
-		if item.Member.Department != nil {
-			resp.Member.Department = &dto.DepartmentResponse{
-				ID:   item.Member.Department.DepartmentID,
-				Name: item.Member.Department.Name,
-			}
-		}
+		resp.Member = toMemberBrief(item.Member)
 	}
 
 	if item.Location != nil {
@@ -1083,3 +1560,33 @@ func (s *scheduleService) toScheduleItemResponse(item *model.ScheduleItem) dto.S
 
 	return resp
 }
+
+// toTimeSlotBrief 转换时间段为简要信息
+func toTimeSlotBrief(ts *model.TimeSlot) *dto.TimeSlotBrief {
+	return &dto.TimeSlotBrief{
+		ID:        ts.TimeSlotID,
+		Name:      ts.Name,
+		DayOfWeek: ts.DayOfWeek,
+		StartTime: ts.StartTime,
+		EndTime:   ts.EndTime,
+	}
+}
+
+// toMemberBrief 转换成员为简要信息（user 为 nil 时返回 nil）
+func toMemberBrief(user *model.User) *dto.MemberBrief {
+	if user == nil {
+		return nil
+	}
+	brief := &dto.MemberBrief{
+		ID:        user.UserID,
+		Name:      user.Name,
+		StudentID: user.StudentID,
+	}
+	if user.Department != nil {
+		brief.Department = &dto.DepartmentResponse{
+			ID:   user.Department.DepartmentID,
+			Name: user.Department.Name,
+		}
+	}
+	return brief
+}
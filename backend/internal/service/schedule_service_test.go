@@ -3,7 +3,10 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync"
 	"testing"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -18,7 +21,7 @@ func setupTestScheduleService() (ScheduleService, *testScheduleRepos) {
 	repos := newTestScheduleRepos()
 	repoAgg := repos.toRepository()
 	logger := zap.NewNop()
-	svc := NewScheduleService(repoAgg, logger)
+	svc := NewScheduleService(repoAgg, nil, logger)
 	return svc, repos
 }
 
@@ -34,31 +37,77 @@ type testScheduleRepos struct {
 	scheduleItem   *mockScheduleItemRepo
 	snapshot       *mockScheduleMemberSnapshotRepo
 	changeLog      *mockScheduleChangeLogRepo
+	calendar       *mockSemesterCalendarRepo
+	dutyRecord     *mockDutyRecordRepo
+	user           *mockUserRepo
+	notification   *mockNotificationRepo
+	conflict       *mockScheduleConflictRepo
+	event          *mockEventRepo
+	eventShift     *mockEventShiftRepo
+	eventAssign    *mockEventAssignmentRepo
+	pair           *mockPairConstraintRepo
+	systemConfig   *mockSystemConfigRepo
+	skill          *mockSkillRepo
+	location       *mockLocationRepo
+	swap           *mockSwapRequestRepo
+	swapPolicy     *mockDepartmentSwapPolicyRepo
+	department     *mockDeptRepo
+	leave          *mockLeaveRequestRepo
+	emergency      *mockEmergencySubstitutionRepo
+	rejection      *mockSignInRejectionRepo
+	report         *mockDutyReportRepo
 }
 
 func newTestScheduleRepos() *testScheduleRepos {
+	items := newMockScheduleItemRepo()
+	events := newMockEventRepo()
+	shifts := newMockEventShiftRepo(events)
+	users := newMockUserRepo()
+	slots := newMockTimeSlotRepo()
+	records := newMockDutyRecordRepo(items)
+	records.users = users
+	schedules := newMockScheduleRepo()
 	return &testScheduleRepos{
 		semester:       newMockSemesterRepo(),
-		timeSlot:       newMockTimeSlotRepo(),
+		timeSlot:       slots,
 		scheduleRule:   newMockScheduleRuleRepo(),
 		courseSchedule: newMockCourseScheduleRepo(),
 		unavailable:    newMockUnavailableTimeRepo(),
 		assignment:     newMockUserSemesterAssignmentRepo(),
-		schedule:       newMockScheduleRepo(),
-		scheduleItem:   newMockScheduleItemRepo(),
+		schedule:       schedules,
+		scheduleItem:   items,
 		snapshot:       newMockScheduleMemberSnapshotRepo(),
 		changeLog:      newMockScheduleChangeLogRepo(),
+		calendar:       newMockSemesterCalendarRepo(),
+		dutyRecord:     records,
+		user:           users,
+		notification:   newMockNotificationRepo(),
+		conflict:       newMockScheduleConflictRepo(),
+		event:          events,
+		eventShift:     shifts,
+		eventAssign:    newMockEventAssignmentRepo(shifts),
+		pair:           newMockPairConstraintRepo(users),
+		systemConfig:   newMockSystemConfigRepo(),
+		skill:          newMockSkillRepo(users, slots, shifts),
+		location:       newMockLocationRepo(),
+		swap:           newMockSwapRequestRepo(records, users, schedules),
+		swapPolicy:     newMockDepartmentSwapPolicyRepo(),
+		department:     newMockDeptRepo(),
+		leave:          newMockLeaveRequestRepo(records, users),
+		emergency:      newMockEmergencySubstitutionRepo(records, users),
+		rejection:      newMockSignInRejectionRepo(users),
+		report:         newMockDutyReportRepo(records, users),
 	}
 }
 
 func (r *testScheduleRepos) toRepository() *repository.Repository {
 	return &repository.Repository{
-		User:                   newMockUserRepo(),
-		Department:             newMockDeptRepo(),
+		User:                   r.user,
+		Department:             r.department,
 		Semester:               r.semester,
 		TimeSlot:               r.timeSlot,
-		Location:               newMockLocationRepo(),
-		SystemConfig:           newMockSystemConfigRepo(),
+		Location:               r.location,
+		SystemConfig:           r.systemConfig,
 		ScheduleRule:           r.scheduleRule,
 		CourseSchedule:         r.courseSchedule,
 		UnavailableTime:        r.unavailable,
@@ -67,6 +116,21 @@ func (r *testScheduleRepos) toRepository() *repository.Repository {
 		ScheduleItem:           r.scheduleItem,
 		ScheduleMemberSnapshot: r.snapshot,
 		ScheduleChangeLog:      r.changeLog,
+		SemesterCalendar:       r.calendar,
+		DutyRecord:             r.dutyRecord,
+		Notification:           r.notification,
+		ScheduleConflict:       r.conflict,
+		Event:                  r.event,
+		EventShift:             r.eventShift,
+		EventAssignment:        r.eventAssign,
+		PairConstraint:         r.pair,
+		Skill:                  r.skill,
+		SwapRequest:            r.swap,
+		DepartmentSwapPolicy:   r.swapPolicy,
+		LeaveRequest:           r.leave,
+		EmergencySubstitution:  r.emergency,
+		SignInRejection:        r.rejection,
+		DutyReport:             r.report,
 	}
 }
 
@@ -78,6 +142,7 @@ func seedBasicData(repos *testScheduleRepos) {
 		Name:          "2025-2026秋",
 		IsActive:      true,
 		FirstWeekType: "odd",
+		Phase:         model.SemesterPhaseScheduling,
 	}
 
 	// 时间段（2个 = 周一上午/下午）
@@ -451,6 +516,26 @@ func TestScheduleService_UpdatePublishedItem_Success(t *testing.T) {
 		},
 	}
 
+	// 已结束的历史记录 + 未开始的记录
+	repos.dutyRecord.records["duty-past"] = &model.DutyRecord{
+		DutyRecordID: "duty-past", ScheduleItemID: strPtr("item-pub"), MemberID: "user-1",
+		DutyDate: dateOnly(time.Now()).AddDate(0, 0, -7), Status: model.DutyRecordStatusCompleted,
+	}
+	repos.dutyRecord.records["duty-next"] = &model.DutyRecord{
+		DutyRecordID: "duty-next", ScheduleItemID: strPtr("item-pub"), MemberID: "user-1",
+		DutyDate: dateOnly(time.Now()).AddDate(0, 0, 7), Status: model.DutyRecordStatusPending,
+	}
+	// 已转让给他人且待替班的记录
+	repos.dutyRecord.records["duty-given"] = &model.DutyRecord{
+		DutyRecordID: "duty-given", ScheduleItemID: strPtr("item-pub"), MemberID: "user-3",
+		DutyDate: dateOnly(time.Now()).AddDate(0, 0, 14), Status: model.DutyRecordStatusPending,
+		NeedsSubstitute: true, SubstituteReason: "临时不可用",
+	}
+	// 原成员时间表变更产生的冲突任务
+	repos.conflict.conflicts["conflict-1"] = &model.ScheduleConflict{
+		ScheduleConflictID: "conflict-1", ScheduleItemID: "item-pub", MemberID: "user-1", Status: model.ScheduleConflictOpen,
+	}
+
 	req := &dto.UpdatePublishedItemRequest{
 		MemberID: "user-2",
 		Reason:   "人员调整",
@@ -460,6 +545,20 @@ func TestScheduleService_UpdatePublishedItem_Success(t *testing.T) {
 		t.Fatalf("UpdatePublishedItem 应成功: %v", err)
 	}
 
+	// 未开始的值班记录随之改派，历史记录保持不变
+	if got := repos.dutyRecord.records["duty-next"].MemberID; got != "user-2" {
+		t.Errorf("未开始的值班记录应改派给 user-2，实际=%s", got)
+	}
+	if got := repos.dutyRecord.records["duty-given"]; got.MemberID != "user-3" || !got.NeedsSubstitute {
+		t.Errorf("已转让给他人的记录不应改派或清除替班标记，实际 member=%s needs=%v", got.MemberID, got.NeedsSubstitute)
+	}
+	if got := repos.conflict.conflicts["conflict-1"].Status; got != model.ScheduleConflictResolved {
+		t.Errorf("改派后冲突任务应关闭，实际=%s", got)
+	}
+	if got := repos.dutyRecord.records["duty-past"].MemberID; got != "user-1" {
+		t.Errorf("历史值班记录不应改派，实际=%s", got)
+	}
+
 	// 验证变更日志已记录
 	if len(repos.changeLog.logs) == 0 {
 		t.Error("应有变更日志记录")
@@ -738,3 +837,789 @@ func TestScheduleService_ValidateCandidate_WithConflict(t *testing.T) {
 		t.Error("应有冲突原因")
 	}
 }
+
+// ════════════════════════════════════════════════════════════
+// SwapItems / MoveItem / BatchUpdateItems 测试
+// ════════════════════════════════════════════════════════════
+
+// seedDraftItems 种子数据：草稿排班表，第1周周一上午 user-1、周一下午 user-2
+func seedDraftItems(repos *testScheduleRepos) {
+	repos.schedule.schedules["sched-1"] = &model.Schedule{
+		ScheduleID: "sched-1",
+		SemesterID: "sem-1",
+		Status:     "draft",
+	}
+	repos.scheduleItem.items["item-1"] = &model.ScheduleItem{
+		ScheduleItemID: "item-1", ScheduleID: "sched-1", WeekNumber: 1,
+		TimeSlotID: "ts-1", MemberID: "user-1", TimeSlot: repos.timeSlot.slots["ts-1"],
+	}
+	repos.scheduleItem.items["item-2"] = &model.ScheduleItem{
+		ScheduleItemID: "item-2", ScheduleID: "sched-1", WeekNumber: 1,
+		TimeSlotID: "ts-2", MemberID: "user-2", TimeSlot: repos.timeSlot.slots["ts-2"],
+	}
+}
+
+func TestScheduleService_SwapItems_Success(t *testing.T) {
+	svc, repos := setupTestScheduleService()
+	seedBasicData(repos)
+	seedDraftItems(repos)
+
+	// 同日互换：逐条修改会在中间态触发 R6，按最终状态校验应通过
+	req := &dto.SwapScheduleItemsRequest{ItemAID: "item-1", ItemBID: "item-2"}
+	result, err := svc.SwapItems(context.Background(), req, "admin-1")
+	if err != nil {
+		t.Fatalf("SwapItems 应成功: %v", err)
+	}
+	if len(result) != 2 {
+		t.Fatalf("期望返回2项，实际=%d", len(result))
+	}
+	if repos.scheduleItem.items["item-1"].MemberID != "user-2" {
+		t.Errorf("item-1 应为 user-2，实际=%s", repos.scheduleItem.items["item-1"].MemberID)
+	}
+	if repos.scheduleItem.items["item-2"].MemberID != "user-1" {
+		t.Errorf("item-2 应为 user-1，实际=%s", repos.scheduleItem.items["item-2"].MemberID)
+	}
+}
+
+func TestScheduleService_SwapItems_ConflictRollsBack(t *testing.T) {
+	svc, repos := setupTestScheduleService()
+	seedBasicData(repos)
+	seedDraftItems(repos)
+
+	// user-2 周一上午有课，不能换到 item-1
+	repos.courseSchedule.courses = []model.CourseSchedule{
+		{
+			CourseScheduleID: "cs-1", UserID: "user-2", SemesterID: "sem-1",
+			CourseName: "高等数学", DayOfWeek: 1,
+			StartTime: "08:00", EndTime: "09:50", WeekType: "all",
+		},
+	}
+
+	req := &dto.SwapScheduleItemsRequest{ItemAID: "item-1", ItemBID: "item-2"}
+	_, err := svc.SwapItems(context.Background(), req, "admin-1")
+	if !errors.Is(err, ErrScheduleEditConflict) {
+		t.Fatalf("期望 ErrScheduleEditConflict，实际: %v", err)
+	}
+	if repos.scheduleItem.items["item-1"].MemberID != "user-1" {
+		t.Error("校验失败时不应写入任何修改")
+	}
+}
+
+func TestScheduleService_SwapItems_NotDraft(t *testing.T) {
+	svc, repos := setupTestScheduleService()
+	seedBasicData(repos)
+	seedDraftItems(repos)
+	repos.schedule.schedules["sched-1"].Status = "published"
+
+	req := &dto.SwapScheduleItemsRequest{ItemAID: "item-1", ItemBID: "item-2"}
+	_, err := svc.SwapItems(context.Background(), req, "admin-1")
+	if !errors.Is(err, ErrScheduleNotDraft) {
+		t.Errorf("期望 ErrScheduleNotDraft，实际: %v", err)
+	}
+}
+
+func TestScheduleService_MoveItem_Success(t *testing.T) {
+	svc, repos := setupTestScheduleService()
+	seedBasicData(repos)
+	seedDraftItems(repos)
+
+	req := &dto.MoveScheduleItemRequest{WeekNumber: 2, TimeSlotID: "ts-1"}
+	result, err := svc.MoveItem(context.Background(), "item-2", req, "admin-1")
+	if err != nil {
+		t.Fatalf("MoveItem 应成功: %v", err)
+	}
+	if result.WeekNumber != 2 {
+		t.Errorf("期望 week_number=2，实际=%d", result.WeekNumber)
+	}
+	if repos.scheduleItem.items["item-2"].TimeSlotID != "ts-1" {
+		t.Errorf("期望 time_slot_id=ts-1，实际=%s", repos.scheduleItem.items["item-2"].TimeSlotID)
+	}
+}
+
+func TestScheduleService_MoveItem_SlotOccupied(t *testing.T) {
+	svc, repos := setupTestScheduleService()
+	seedBasicData(repos)
+	seedDraftItems(repos)
+
+	req := &dto.MoveScheduleItemRequest{WeekNumber: 1, TimeSlotID: "ts-1"}
+	_, err := svc.MoveItem(context.Background(), "item-2", req, "admin-1")
+	if !errors.Is(err, ErrScheduleSlotOccupied) {
+		t.Errorf("期望 ErrScheduleSlotOccupied，实际: %v", err)
+	}
+}
+
+func TestScheduleService_MoveItem_TimeSlotNotInSemester(t *testing.T) {
+	semID, otherSem := "sem-1", "sem-2"
+	cases := map[string]*model.TimeSlot{
+		"全局时间段": {TimeSlotID: "ts-x", Name: "全局", DayOfWeek: 2, StartTime: "08:10", EndTime: "10:05", IsActive: true},
+		"其他学期":  {TimeSlotID: "ts-x", Name: "其他学期", SemesterID: &otherSem, DayOfWeek: 2, StartTime: "08:10", EndTime: "10:05", IsActive: true},
+		"已停用":   {TimeSlotID: "ts-x", Name: "已停用", SemesterID: &semID, DayOfWeek: 2, StartTime: "08:10", EndTime: "10:05"},
+	}
+	for name, ts := range cases {
+		t.Run(name, func(t *testing.T) {
+			svc, repos := setupTestScheduleService()
+			seedBasicData(repos)
+			seedDraftItems(repos)
+			repos.timeSlot.slots["ts-x"] = ts
+
+			req := &dto.MoveScheduleItemRequest{WeekNumber: 1, TimeSlotID: "ts-x"}
+			if _, err := svc.MoveItem(context.Background(), "item-2", req, "admin-1"); !errors.Is(err, ErrTimeSlotNotFound) {
+				t.Errorf("期望 ErrTimeSlotNotFound，实际: %v", err)
+			}
+			if repos.scheduleItem.items["item-2"].TimeSlotID != "ts-2" {
+				t.Error("校验失败时不应移动排班项")
+			}
+		})
+	}
+}
+
+func TestScheduleService_BatchUpdateItems_FinalStateR6(t *testing.T) {
+	svc, repos := setupTestScheduleService()
+	seedBasicData(repos)
+	seedDraftItems(repos)
+
+	// 两项都改为 user-1 → 最终状态同人同日
+	member := "user-1"
+	req := &dto.BatchUpdateScheduleItemsRequest{Edits: []dto.ScheduleItemEdit{
+		{ItemID: "item-2", MemberID: &member},
+	}}
+	_, err := svc.BatchUpdateItems(context.Background(), req, "admin-1")
+	if !errors.Is(err, ErrScheduleEditConflict) {
+		t.Errorf("期望 ErrScheduleEditConflict，实际: %v", err)
+	}
+}
+
+func TestScheduleService_BatchUpdateItems_MemberNotDuty(t *testing.T) {
+	svc, repos := setupTestScheduleService()
+	seedBasicData(repos)
+	seedDraftItems(repos)
+	repos.assignment.assignments = append(repos.assignment.assignments, model.UserSemesterAssignment{
+		AssignmentID: "a-3", UserID: "user-3", SemesterID: "sem-1", DutyRequired: false, TimetableStatus: "submitted",
+	})
+
+	// 不需要值班的成员与不存在的用户均在写入前拒绝
+	for _, member := range []string{"user-3", "user-404"} {
+		req := &dto.BatchUpdateScheduleItemsRequest{Edits: []dto.ScheduleItemEdit{
+			{ItemID: "item-1", MemberID: &member},
+		}}
+		if _, err := svc.BatchUpdateItems(context.Background(), req, "admin-1"); !errors.Is(err, ErrScheduleMemberNotDuty) {
+			t.Errorf("%s: 期望 ErrScheduleMemberNotDuty，实际: %v", member, err)
+		}
+	}
+	if repos.scheduleItem.items["item-1"].MemberID != "user-1" {
+		t.Error("校验失败时不应修改排班项")
+	}
+}
+
+func TestScheduleService_BatchUpdateItems_ItemsMismatch(t *testing.T) {
+	svc, repos := setupTestScheduleService()
+	seedBasicData(repos)
+	seedDraftItems(repos)
+	repos.scheduleItem.items["item-other"] = &model.ScheduleItem{
+		ScheduleItemID: "item-other", ScheduleID: "sched-other", WeekNumber: 1,
+		TimeSlotID: "ts-1", MemberID: "user-1",
+	}
+
+	member := "user-2"
+	req := &dto.BatchUpdateScheduleItemsRequest{Edits: []dto.ScheduleItemEdit{
+		{ItemID: "item-1", MemberID: &member},
+		{ItemID: "item-other", MemberID: &member},
+	}}
+	_, err := svc.BatchUpdateItems(context.Background(), req, "admin-1")
+	if !errors.Is(err, ErrScheduleItemsMismatch) {
+		t.Errorf("期望 ErrScheduleItemsMismatch，实际: %v", err)
+	}
+}
+
+// ════════════════════════════════════════════════════════════
+// 自动排班后台任务 测试
+// ════════════════════════════════════════════════════════════
+
+// waitAutoScheduleJob 订阅任务直至结束，返回最后一次快照及收到的事件数
+func waitAutoScheduleJob(t *testing.T, svc ScheduleService, jobID string) (dto.AutoScheduleJobResponse, int) {
+	t.Helper()
+	events, unsubscribe, err := svc.SubscribeAutoScheduleJob(context.Background(), jobID)
+	if err != nil {
+		t.Fatalf("订阅任务失败: %v", err)
+	}
+	defer unsubscribe()
+
+	var last dto.AutoScheduleJobResponse
+	count := 0
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				return last, count
+			}
+			last = ev
+			count++
+		case <-timeout:
+			t.Fatal("等待排班任务结束超时")
+		}
+	}
+}
+
+func TestScheduleService_AutoScheduleJob_Success(t *testing.T) {
+	svc, repos := setupTestScheduleService()
+	seedBasicData(repos)
+
+	job, err := svc.StartAutoScheduleJob(context.Background(), &dto.AutoScheduleRequest{SemesterID: "sem-1"}, "admin-1")
+	if err != nil {
+		t.Fatalf("提交任务应成功: %v", err)
+	}
+	if job.JobID == "" {
+		t.Fatal("任务 ID 不应为空")
+	}
+
+	final, _ := waitAutoScheduleJob(t, svc, job.JobID)
+	if final.Status != model.AutoScheduleJobSucceeded {
+		t.Fatalf("期望任务成功，实际=%s (%s)", final.Status, final.Error)
+	}
+	if final.Result == nil || final.Result.Schedule == nil {
+		t.Fatal("成功任务应包含排班结果")
+	}
+	if final.Progress.Phase != "done" || final.Progress.TotalSlots != 4 {
+		t.Errorf("期望进度 done/4，实际=%s/%d", final.Progress.Phase, final.Progress.TotalSlots)
+	}
+
+	// 任务结束后锁已释放，可再次排班
+	if _, err := svc.AutoSchedule(context.Background(), &dto.AutoScheduleRequest{SemesterID: "sem-1"}, "admin-1"); err != nil {
+		t.Errorf("任务结束后应可再次排班: %v", err)
+	}
+}
+
+func TestScheduleService_AutoScheduleJob_FailurePropagates(t *testing.T) {
+	svc, repos := setupTestScheduleService()
+	seedBasicData(repos)
+	repos.assignment.assignments[1].TimetableStatus = "not_submitted"
+
+	job, err := svc.StartAutoScheduleJob(context.Background(), &dto.AutoScheduleRequest{SemesterID: "sem-1"}, "admin-1")
+	if err != nil {
+		t.Fatalf("提交任务应成功: %v", err)
+	}
+
+	final, _ := waitAutoScheduleJob(t, svc, job.JobID)
+	if final.Status != model.AutoScheduleJobFailed {
+		t.Fatalf("期望任务失败，实际=%s", final.Status)
+	}
+	if final.Error != ErrSubmissionRateIncomplete.Error() {
+		t.Errorf("期望错误信息=%s，实际=%s", ErrSubmissionRateIncomplete.Error(), final.Error)
+	}
+
+	if _, err := svc.CancelAutoScheduleJob(context.Background(), job.JobID, "admin-1"); !errors.Is(err, ErrAutoScheduleJobFinished) {
+		t.Errorf("期望 ErrAutoScheduleJobFinished，实际=%v", err)
+	}
+}
+
+func TestScheduleService_AutoSchedule_SemesterLocked(t *testing.T) {
+	svc, repos := setupTestScheduleService()
+	seedBasicData(repos)
+
+	impl := svc.(*scheduleService)
+	unlock, ok, err := impl.locker.tryLock(context.Background(), autoScheduleLockKey("sem-1"), time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("获取锁应成功: ok=%v err=%v", ok, err)
+	}
+	defer unlock()
+
+	req := &dto.AutoScheduleRequest{SemesterID: "sem-1"}
+	if _, err := svc.AutoSchedule(context.Background(), req, "admin-1"); !errors.Is(err, ErrAutoScheduleRunning) {
+		t.Errorf("期望 ErrAutoScheduleRunning，实际=%v", err)
+	}
+	if _, err := svc.StartAutoScheduleJob(context.Background(), req, "admin-1"); !errors.Is(err, ErrAutoScheduleRunning) {
+		t.Errorf("期望 ErrAutoScheduleRunning，实际=%v", err)
+	}
+}
+
+func TestScheduleService_RunAutoSchedule_Cancelled(t *testing.T) {
+	svc, repos := setupTestScheduleService()
+	seedBasicData(repos)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	impl := svc.(*scheduleService)
+	_, err := impl.runAutoSchedule(ctx, &dto.AutoScheduleRequest{SemesterID: "sem-1"}, "admin-1", nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("期望 context.Canceled，实际=%v", err)
+	}
+	if len(repos.scheduleItem.items) != 0 {
+		t.Error("取消后不应写入排班项")
+	}
+}
+
+func TestScheduleService_GetAutoScheduleJob_NotFound(t *testing.T) {
+	svc, _ := setupTestScheduleService()
+
+	if _, err := svc.GetAutoScheduleJob(context.Background(), "missing"); !errors.Is(err, ErrAutoScheduleJobNotFound) {
+		t.Errorf("期望 ErrAutoScheduleJobNotFound，实际=%v", err)
+	}
+}
+
+// memoryJobStore 进程内的任务状态共享存储，模拟多实例共用的 Redis
+type memoryJobStore struct {
+	mu        sync.Mutex
+	snapshots map[string]dto.AutoScheduleJobResponse
+	cancels   map[string]bool
+}
+
+func newMemoryJobStore() *memoryJobStore {
+	return &memoryJobStore{snapshots: make(map[string]dto.AutoScheduleJobResponse), cancels: make(map[string]bool)}
+}
+
+func (m *memoryJobStore) save(_ context.Context, snap dto.AutoScheduleJobResponse) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.snapshots[snap.JobID] = snap
+	return nil
+}
+
+func (m *memoryJobStore) load(_ context.Context, jobID string) (*dto.AutoScheduleJobResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	snap, ok := m.snapshots[jobID]
+	if !ok {
+		return nil, nil
+	}
+	return &snap, nil
+}
+
+func (m *memoryJobStore) requestCancel(_ context.Context, jobID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cancels[jobID] = true
+	return nil
+}
+
+func (m *memoryJobStore) cancelRequested(_ context.Context, jobID string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.cancels[jobID], nil
+}
+
+func TestScheduleService_AutoScheduleJob_SharedAcrossInstances(t *testing.T) {
+	store := newMemoryJobStore()
+	svcA, repos := setupTestScheduleService()
+	seedBasicData(repos)
+	svcB := NewScheduleService(repos.toRepository(), nil, zap.NewNop())
+	svcA.(*scheduleService).jobStore = store
+	svcB.(*scheduleService).jobStore = store
+
+	job, err := svcA.StartAutoScheduleJob(context.Background(), &dto.AutoScheduleRequest{SemesterID: "sem-1"}, "admin-1")
+	if err != nil {
+		t.Fatalf("提交任务应成功: %v", err)
+	}
+
+	// 实例 B 查询与订阅实例 A 执行的任务
+	if got, err := svcB.GetAutoScheduleJob(context.Background(), job.JobID); err != nil || got.JobID != job.JobID {
+		t.Fatalf("其他实例应可查询任务: %+v %v", got, err)
+	}
+	final, _ := waitAutoScheduleJob(t, svcB, job.JobID)
+	if final.Status != model.AutoScheduleJobSucceeded || final.Result == nil {
+		t.Fatalf("其他实例订阅应收到成功结果，实际=%s (%s)", final.Status, final.Error)
+	}
+	if _, err := svcB.CancelAutoScheduleJob(context.Background(), job.JobID, "admin-1"); !errors.Is(err, ErrAutoScheduleJobFinished) {
+		t.Errorf("期望 ErrAutoScheduleJobFinished，实际=%v", err)
+	}
+
+	// 实例 B 取消运行中的任务：登记取消请求，执行实例轮询后中止
+	running := dto.AutoScheduleJobResponse{JobID: "job-running", SemesterID: "sem-1", Status: model.AutoScheduleJobRunning}
+	_ = store.save(context.Background(), running)
+	if _, err := svcB.CancelAutoScheduleJob(context.Background(), running.JobID, "admin-1"); err != nil {
+		t.Fatalf("其他实例取消应成功: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	remote := &autoScheduleJob{id: running.JobID, status: model.AutoScheduleJobRunning, cancel: cancel, logger: zap.NewNop()}
+	svcA.(*scheduleService).watchJobCancel(ctx, remote)
+	if !remote.cancelRequested || ctx.Err() == nil {
+		t.Error("执行实例应响应取消请求并中止任务")
+	}
+}
+
+// ════════════════════════════════════════════════════════════
+// 候选方案测试
+// ════════════════════════════════════════════════════════════
+
+func TestScheduleService_AutoSchedule_CandidateKeepsDraft(t *testing.T) {
+	svc, repos := setupTestScheduleService()
+	seedBasicData(repos)
+
+	repos.schedule.schedules["old-sched"] = &model.Schedule{
+		ScheduleID: "old-sched",
+		SemesterID: "sem-1",
+		Status:     model.ScheduleStatusDraft,
+	}
+
+	seed := int64(42)
+	req := &dto.AutoScheduleRequest{
+		SemesterID:  "sem-1",
+		Solver:      model.SolverLocalSearch,
+		Seed:        &seed,
+		AsCandidate: true,
+	}
+	result, err := svc.AutoSchedule(context.Background(), req, "admin-1")
+	if err != nil {
+		t.Fatalf("AutoSchedule 应成功: %v", err)
+	}
+
+	if result.Schedule.Status != model.ScheduleStatusCandidate {
+		t.Errorf("期望 status=candidate，实际=%s", result.Schedule.Status)
+	}
+	if result.Schedule.Solver != model.SolverLocalSearch {
+		t.Errorf("期望 solver=local_search，实际=%s", result.Schedule.Solver)
+	}
+	if result.Schedule.Name == "" {
+		t.Error("候选方案应有默认名称")
+	}
+	if repos.schedule.schedules["old-sched"].Status != model.ScheduleStatusDraft {
+		t.Errorf("生成候选方案不应归档现有草稿，实际status=%s", repos.schedule.schedules["old-sched"].Status)
+	}
+}
+
+func TestScheduleService_AutoSchedule_SeedDeterministic(t *testing.T) {
+	run := func() map[string]string {
+		svc, repos := setupTestScheduleService()
+		seedBasicData(repos)
+		seed := int64(7)
+		req := &dto.AutoScheduleRequest{SemesterID: "sem-1", Solver: model.SolverLocalSearch, Seed: &seed, AsCandidate: true}
+		result, err := svc.AutoSchedule(context.Background(), req, "admin-1")
+		if err != nil {
+			t.Fatalf("AutoSchedule 应成功: %v", err)
+		}
+		assigned := make(map[string]string)
+		for _, item := range result.Schedule.Items {
+			if item.Member != nil {
+				assigned[fmt.Sprintf("%d-%s", item.WeekNumber, item.TimeSlot.ID)] = item.Member.ID
+			}
+		}
+		return assigned
+	}
+
+	first, second := run(), run()
+	if len(first) != len(second) {
+		t.Fatalf("相同种子结果数量不一致: %d vs %d", len(first), len(second))
+	}
+	for k, v := range first {
+		if second[k] != v {
+			t.Errorf("相同种子应得到相同结果，槽位 %s: %s vs %s", k, v, second[k])
+		}
+	}
+}
+
+func TestScheduleService_AutoSchedule_TooManyCandidates(t *testing.T) {
+	svc, repos := setupTestScheduleService()
+	seedBasicData(repos)
+
+	for i := 0; i < maxScheduleCandidates; i++ {
+		id := fmt.Sprintf("cand-%d", i)
+		repos.schedule.schedules[id] = &model.Schedule{ScheduleID: id, SemesterID: "sem-1", Status: model.ScheduleStatusCandidate}
+	}
+
+	req := &dto.AutoScheduleRequest{SemesterID: "sem-1", AsCandidate: true}
+	if _, err := svc.AutoSchedule(context.Background(), req, "admin-1"); !errors.Is(err, ErrTooManyCandidates) {
+		t.Errorf("期望 ErrTooManyCandidates，实际=%v", err)
+	}
+}
+
+func TestScheduleService_AutoSchedule_RuleOverrideHardRule(t *testing.T) {
+	svc, repos := setupTestScheduleService()
+	seedBasicData(repos)
+	repos.scheduleRule.rules["r1"].IsConfigurable = false
+
+	req := &dto.AutoScheduleRequest{SemesterID: "sem-1", RuleOverrides: map[string]bool{"R1": false}}
+	if _, err := svc.AutoSchedule(context.Background(), req, "admin-1"); !errors.Is(err, ErrScheduleRuleNotConfigurable) {
+		t.Errorf("期望 ErrScheduleRuleNotConfigurable，实际=%v", err)
+	}
+}
+
+func TestScheduleService_PromoteCandidate_DemotesDraft(t *testing.T) {
+	svc, repos := setupTestScheduleService()
+	seedBasicData(repos)
+
+	repos.schedule.schedules["draft"] = &model.Schedule{ScheduleID: "draft", SemesterID: "sem-1", Status: model.ScheduleStatusDraft}
+	repos.schedule.schedules["cand"] = &model.Schedule{ScheduleID: "cand", SemesterID: "sem-1", Status: model.ScheduleStatusCandidate}
+
+	result, err := svc.PromoteCandidate(context.Background(), "cand", "admin-1")
+	if err != nil {
+		t.Fatalf("PromoteCandidate 应成功: %v", err)
+	}
+	if result.Status != model.ScheduleStatusDraft {
+		t.Errorf("期望提升后 status=draft，实际=%s", result.Status)
+	}
+	if repos.schedule.schedules["draft"].Status != model.ScheduleStatusCandidate {
+		t.Errorf("原草稿应降为候选方案，实际=%s", repos.schedule.schedules["draft"].Status)
+	}
+}
+
+func TestScheduleService_PromoteCandidate_PublishedExists(t *testing.T) {
+	svc, repos := setupTestScheduleService()
+	seedBasicData(repos)
+
+	repos.schedule.schedules["pub"] = &model.Schedule{ScheduleID: "pub", SemesterID: "sem-1", Status: model.ScheduleStatusPublished}
+	repos.schedule.schedules["cand"] = &model.Schedule{ScheduleID: "cand", SemesterID: "sem-1", Status: model.ScheduleStatusCandidate}
+
+	if _, err := svc.PromoteCandidate(context.Background(), "cand", "admin-1"); !errors.Is(err, ErrSchedulePublishedExists) {
+		t.Errorf("期望 ErrSchedulePublishedExists，实际=%v", err)
+	}
+}
+
+func TestScheduleService_CompareSchedules_SemesterMismatch(t *testing.T) {
+	svc, repos := setupTestScheduleService()
+	seedBasicData(repos)
+
+	repos.schedule.schedules["a"] = &model.Schedule{ScheduleID: "a", SemesterID: "sem-1", Status: model.ScheduleStatusCandidate}
+	repos.schedule.schedules["b"] = &model.Schedule{ScheduleID: "b", SemesterID: "sem-2", Status: model.ScheduleStatusCandidate}
+
+	req := &dto.CompareSchedulesRequest{ScheduleIDs: []string{"a", "b"}}
+	if _, err := svc.CompareSchedules(context.Background(), req); !errors.Is(err, ErrScheduleSemesterMismatch) {
+		t.Errorf("期望 ErrScheduleSemesterMismatch，实际=%v", err)
+	}
+}
+
+func TestScheduleService_GetQualityReport_Success(t *testing.T) {
+	svc, repos := setupTestScheduleService()
+	seedBasicData(repos)
+
+	result, err := svc.AutoSchedule(context.Background(), &dto.AutoScheduleRequest{SemesterID: "sem-1"}, "admin-1")
+	if err != nil {
+		t.Fatalf("AutoSchedule 应成功: %v", err)
+	}
+
+	report, err := svc.GetQualityReport(context.Background(), result.Schedule.ID)
+	if err != nil {
+		t.Fatalf("GetQualityReport 应成功: %v", err)
+	}
+	if report.TotalSlots != 4 {
+		t.Errorf("期望 TotalSlots=4，实际=%d", report.TotalSlots)
+	}
+	if report.FilledSlots != result.FilledSlots {
+		t.Errorf("期望 FilledSlots=%d，实际=%d", result.FilledSlots, report.FilledSlots)
+	}
+	if report.HardConflicts != 0 {
+		t.Errorf("自动排班结果不应有硬冲突，实际=%d", report.HardConflicts)
+	}
+}
+
+// ════════════════════════════════════════════════════════════
+// 版本历史测试
+// ════════════════════════════════════════════════════════════
+
+func TestScheduleService_ListVersions_IncludesArchived(t *testing.T) {
+	svc, repos := setupTestScheduleService()
+	seedBasicData(repos)
+
+	if _, err := svc.AutoSchedule(context.Background(), &dto.AutoScheduleRequest{SemesterID: "sem-1"}, "admin-1"); err != nil {
+		t.Fatalf("AutoSchedule 应成功: %v", err)
+	}
+	if _, err := svc.AutoSchedule(context.Background(), &dto.AutoScheduleRequest{SemesterID: "sem-1"}, "admin-1"); err != nil {
+		t.Fatalf("AutoSchedule 应成功: %v", err)
+	}
+
+	versions, err := svc.ListVersions(context.Background(), "sem-1")
+	if err != nil {
+		t.Fatalf("ListVersions 应成功: %v", err)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("期望 2 个版本，实际=%d", len(versions))
+	}
+	statuses := map[string]bool{}
+	for _, v := range versions {
+		statuses[v.Status] = true
+		if v.ItemCount == 0 {
+			t.Errorf("版本 %s 排班项数不应为0", v.ID)
+		}
+	}
+	if !statuses[model.ScheduleStatusArchived] || !statuses[model.ScheduleStatusDraft] {
+		t.Errorf("期望包含 archived 与 draft 版本，实际=%v", statuses)
+	}
+}
+
+func TestDiffScheduleItems(t *testing.T) {
+	ts1 := &model.TimeSlot{TimeSlotID: "ts-1", DayOfWeek: 1, StartTime: "08:10"}
+	ts2 := &model.TimeSlot{TimeSlotID: "ts-2", DayOfWeek: 1, StartTime: "14:00"}
+	ts3 := &model.TimeSlot{TimeSlotID: "ts-3", DayOfWeek: 2, StartTime: "08:10"}
+	u1 := &model.User{UserID: "user-1", Name: "张三"}
+	u2 := &model.User{UserID: "user-2", Name: "李四"}
+
+	base := []model.ScheduleItem{
+		{ScheduleItemID: "b1", WeekNumber: 1, TimeSlotID: "ts-1", TimeSlot: ts1, MemberID: "user-1", Member: u1},
+		{ScheduleItemID: "b2", WeekNumber: 1, TimeSlotID: "ts-2", TimeSlot: ts2, MemberID: "user-1", Member: u1},
+		{ScheduleItemID: "b3", WeekNumber: 2, TimeSlotID: "ts-1", TimeSlot: ts1, MemberID: "user-2", Member: u2},
+	}
+	target := []model.ScheduleItem{
+		{ScheduleItemID: "t1", WeekNumber: 1, TimeSlotID: "ts-1", TimeSlot: ts1, MemberID: "user-1", Member: u1},
+		{ScheduleItemID: "t2", WeekNumber: 1, TimeSlotID: "ts-2", TimeSlot: ts2, MemberID: "user-2", Member: u2},
+		{ScheduleItemID: "t3", WeekNumber: 1, TimeSlotID: "ts-3", TimeSlot: ts3, MemberID: "user-2", Member: u2},
+	}
+
+	diff := diffScheduleItems(base, target)
+
+	if len(diff.Reassigned) != 1 || diff.Reassigned[0].BaseItemID != "b2" || diff.Reassigned[0].TargetItemID != "t2" {
+		t.Errorf("期望 b2→t2 改派，实际=%+v", diff.Reassigned)
+	}
+	if len(diff.Added) != 1 || diff.Added[0].TargetItemID != "t3" {
+		t.Errorf("期望新增 t3，实际=%+v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].BaseItemID != "b3" {
+		t.Errorf("期望移除 b3，实际=%+v", diff.Removed)
+	}
+
+	members := map[string]dto.ScheduleMemberDiff{}
+	for _, m := range diff.Members {
+		members[m.Member.ID] = m
+	}
+	if m := members["user-1"]; m.Lost != 1 || m.Gained != 0 || m.BaseShifts != 2 || m.TargetShifts != 1 {
+		t.Errorf("user-1 汇总不符: %+v", m)
+	}
+	if m := members["user-2"]; m.Lost != 1 || m.Gained != 2 {
+		t.Errorf("user-2 汇总不符: %+v", m)
+	}
+}
+
+func TestScheduleService_RestoreVersion_Success(t *testing.T) {
+	svc, repos := setupTestScheduleService()
+	seedBasicData(repos)
+
+	first, err := svc.AutoSchedule(context.Background(), &dto.AutoScheduleRequest{SemesterID: "sem-1"}, "admin-1")
+	if err != nil {
+		t.Fatalf("AutoSchedule 应成功: %v", err)
+	}
+	second, err := svc.AutoSchedule(context.Background(), &dto.AutoScheduleRequest{SemesterID: "sem-1"}, "admin-1")
+	if err != nil {
+		t.Fatalf("AutoSchedule 应成功: %v", err)
+	}
+
+	restored, err := svc.RestoreVersion(context.Background(), first.Schedule.ID, "admin-1")
+	if err != nil {
+		t.Fatalf("RestoreVersion 应成功: %v", err)
+	}
+
+	if restored.Status != model.ScheduleStatusDraft {
+		t.Errorf("恢复后应为草稿，实际=%s", restored.Status)
+	}
+	if restored.RestoredFromID == nil || *restored.RestoredFromID != first.Schedule.ID {
+		t.Errorf("restored_from_id 应指向原版本")
+	}
+	if len(restored.Items) != len(first.Schedule.Items) {
+		t.Errorf("期望复制 %d 个排班项，实际=%d", len(first.Schedule.Items), len(restored.Items))
+	}
+	if repos.schedule.schedules[first.Schedule.ID].Status != model.ScheduleStatusArchived {
+		t.Error("原归档版本应保持 archived")
+	}
+	if repos.schedule.schedules[second.Schedule.ID].Status != model.ScheduleStatusArchived {
+		t.Error("恢复前的草稿应被归档")
+	}
+}
+
+func TestScheduleService_RestoreVersion_NotArchived(t *testing.T) {
+	svc, repos := setupTestScheduleService()
+	seedBasicData(repos)
+
+	result, err := svc.AutoSchedule(context.Background(), &dto.AutoScheduleRequest{SemesterID: "sem-1"}, "admin-1")
+	if err != nil {
+		t.Fatalf("AutoSchedule 应成功: %v", err)
+	}
+
+	if _, err := svc.RestoreVersion(context.Background(), result.Schedule.ID, "admin-1"); !errors.Is(err, ErrScheduleNotArchived) {
+		t.Errorf("期望 ErrScheduleNotArchived，实际=%v", err)
+	}
+}
+
+// ════════════════════════════════════════════════════════════
+// 排班解释测试
+// ════════════════════════════════════════════════════════════
+
+func TestScheduleService_ExplainSlot_EmptySlot(t *testing.T) {
+	svc, repos := setupTestScheduleService()
+	seedBasicData(repos)
+
+	repos.courseSchedule.courses = []model.CourseSchedule{
+		{
+			CourseScheduleID: "cs-1", UserID: "user-1", SemesterID: "sem-1",
+			CourseName: "高等数学", DayOfWeek: 1,
+			StartTime: "08:00", EndTime: "09:50", WeekType: "all",
+		},
+	}
+	repos.schedule.schedules["sched-empty"] = &model.Schedule{ScheduleID: "sched-empty", SemesterID: "sem-1", Status: model.ScheduleStatusDraft}
+
+	resp, err := svc.ExplainSlot(context.Background(), "sched-empty", &dto.ExplainSlotRequest{WeekNumber: 1, TimeSlotID: "ts-1"})
+	if err != nil {
+		t.Fatalf("ExplainSlot 应成功: %v", err)
+	}
+
+	if resp.Filled {
+		t.Error("空槽位 Filled 应为 false")
+	}
+	members := map[string]dto.MemberExplanation{}
+	for _, m := range resp.Members {
+		members[m.Member.ID] = m
+	}
+	if m := members["user-1"]; m.Status != "course_conflict" || len(m.Reasons) == 0 || m.Reasons[0] != "课程冲突: 高等数学" {
+		t.Errorf("user-1 应为课程冲突并给出课程名，实际=%+v", m)
+	}
+	if m := members["user-2"]; m.Status != "eligible" || m.Score == nil || m.Rank != 1 {
+		t.Errorf("user-2 应可排且排名第一，实际=%+v", m)
+	}
+	if resp.StatusCounts["eligible"] != 1 {
+		t.Errorf("期望 1 名可排成员，实际=%d", resp.StatusCounts["eligible"])
+	}
+}
+
+func TestScheduleService_ExplainItem_BlockedReasons(t *testing.T) {
+	svc, repos := setupTestScheduleService()
+	seedBasicData(repos)
+
+	repos.unavailable.times = []model.UnavailableTime{
+		{
+			UnavailableTimeID: "ut-1", UserID: "user-2", SemesterID: "sem-1",
+			DayOfWeek: 1, StartTime: "13:00", EndTime: "17:00",
+			RepeatType: "weekly", WeekType: "all", Reason: "兼职",
+		},
+	}
+
+	result, err := svc.AutoSchedule(context.Background(), &dto.AutoScheduleRequest{SemesterID: "sem-1"}, "admin-1")
+	if err != nil {
+		t.Fatalf("AutoSchedule 应成功: %v", err)
+	}
+
+	var itemID string
+	for _, item := range repos.scheduleItem.items {
+		if item.ScheduleID == result.Schedule.ID && item.WeekNumber == 1 && item.TimeSlotID == "ts-2" {
+			itemID = item.ScheduleItemID
+		}
+	}
+	if itemID == "" {
+		t.Fatal("第1周 ts-2 应已排班")
+	}
+
+	resp, err := svc.ExplainItem(context.Background(), itemID)
+	if err != nil {
+		t.Fatalf("ExplainItem 应成功: %v", err)
+	}
+	if !resp.Filled {
+		t.Error("Filled 应为 true")
+	}
+	for _, m := range resp.Members {
+		if m.Member.ID != "user-2" {
+			continue
+		}
+		if m.Status != "unavailable" {
+			t.Errorf("user-2 应为 unavailable，实际=%s", m.Status)
+		}
+		found := false
+		for _, r := range m.Reasons {
+			if r == "不可用时间: 兼职" {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("应给出不可用原因，实际=%v", m.Reasons)
+		}
+	}
+	if len(resp.Members) == 0 || resp.Members[0].Status != "assigned" {
+		t.Error("在岗成员应排在首位")
+	}
+}
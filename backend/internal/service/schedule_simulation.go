@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/model"
+)
+
+// ── 模拟排班（What-if） ──
+//
+// 在学期现有数据（值班成员、时间段、课表、不可用时间、规则）的内存副本上叠加覆盖项后求解，
+// 返回与当前数据求解结果（baseline）并列的质量报告。整个流程只读，不加学期锁、不写任何数据。
+// 新增成员按其本学期课表与不可用时间判定可用性；新增时间段不绑定地点、不要求技能。
+
+func (s *scheduleService) SimulateSchedule(ctx context.Context, req *dto.SimulateScheduleRequest) (*dto.ScheduleSimulationResponse, error) {
+	semester, err := s.repo.Semester.GetByID(ctx, req.SemesterID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSemesterNotFound
+		}
+		s.logger.Error("查询学期失败", zap.Error(err))
+		return nil, err
+	}
+	for code := range req.RuleWeights {
+		if _, ok := defaultPenalties[code]; !ok {
+			return nil, ErrSimulationRuleWeight
+		}
+	}
+	for _, ts := range req.AddTimeSlots {
+		if ts.EndTime <= ts.StartTime {
+			return nil, ErrSimulationTimeSlot
+		}
+	}
+
+	assignments, err := s.repo.UserSemesterAssignment.ListDutyRequiredSubmitted(ctx, semester.SemesterID)
+	if err != nil {
+		s.logger.Error("查询候选人失败", zap.Error(err))
+		return nil, err
+	}
+	timeSlots, err := s.repo.TimeSlot.List(ctx, semester.SemesterID, nil)
+	if err != nil {
+		s.logger.Error("查询时间段失败", zap.Error(err))
+		return nil, err
+	}
+	var candidates []scheduleCandidate
+	for _, a := range assignments {
+		if a.User != nil {
+			candidates = append(candidates, scheduleCandidate{userID: a.UserID, departmentID: a.User.DepartmentID, name: a.User.Name})
+		}
+	}
+
+	solverName := req.Solver
+	if solverName == "" {
+		solverName = model.SolverGreedy
+	}
+	opts := solverOptions{solver: solverName, seed: req.Seed}
+
+	// baseline：当前数据、全局规则
+	baseInput, err := s.loadSolverConstraints(ctx, semester, timeSlots, nil)
+	if err != nil {
+		return nil, err
+	}
+	baseInput.candidates = candidates
+	baseline, _, err := simulateSolve(ctx, baseInput, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// 叠加覆盖项
+	simSlots, err := simulatedTimeSlots(timeSlots, semester.SemesterID, req)
+	if err != nil {
+		return nil, err
+	}
+	simCandidates, warnings, err := s.simulatedCandidates(ctx, semester.SemesterID, candidates, req)
+	if err != nil {
+		return nil, err
+	}
+	simInput, err := s.loadSolverConstraints(ctx, semester, simSlots, req.RuleOverrides)
+	if err != nil {
+		return nil, err
+	}
+	simInput.candidates = simCandidates
+	simInput.weights = req.RuleWeights
+	quality, solveWarnings, err := simulateSolve(ctx, simInput, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ScheduleSimulationResponse{
+		SemesterID: semester.SemesterID,
+		Solver:     solverName,
+		Baseline:   baseline,
+		Quality:    quality,
+		Warnings:   append(warnings, solveWarnings...),
+	}, nil
+}
+
+// simulateSolve 求解并生成质量报告（无候选人或无时间段时直接按空方案评估）
+func simulateSolve(ctx context.Context, in *solverInput, opts solverOptions) (*dto.ScheduleQualityReport, []string, error) {
+	if len(in.candidates) == 0 || len(in.slots) == 0 {
+		return in.qualityReport(nil), nil, nil
+	}
+	result, err := newScheduleSolver(in, opts, nil, nil).solve(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return in.qualityReport(result.assignments), result.warnings, nil
+}
+
+// simulatedTimeSlots 移除指定时间段并追加模拟时间段（ID 以 sim- 开头）
+func simulatedTimeSlots(timeSlots []model.TimeSlot, semesterID string, req *dto.SimulateScheduleRequest) ([]model.TimeSlot, error) {
+	removed := make(map[string]bool, len(req.RemoveTimeSlots))
+	for _, id := range req.RemoveTimeSlots {
+		removed[id] = true
+	}
+	result := make([]model.TimeSlot, 0, len(timeSlots)+len(req.AddTimeSlots))
+	for _, ts := range timeSlots {
+		if removed[ts.TimeSlotID] {
+			delete(removed, ts.TimeSlotID)
+			continue
+		}
+		result = append(result, ts)
+	}
+	if len(removed) > 0 {
+		return nil, ErrTimeSlotNotFound
+	}
+	for i, add := range req.AddTimeSlots {
+		ts := model.TimeSlot{
+			TimeSlotID:     fmt.Sprintf("sim-%d", i+1),
+			Name:           add.Name,
+			SemesterID:     &semesterID,
+			StartTime:      add.StartTime,
+			EndTime:        add.EndTime,
+			DayOfWeek:      add.DayOfWeek,
+			IsActive:       true,
+			CostMultiplier: 1,
+		}
+		if add.CostMultiplier != nil {
+			ts.CostMultiplier = *add.CostMultiplier
+		}
+		result = append(result, ts)
+	}
+	return result, nil
+}
+
+// simulatedCandidates 移除 / 追加值班成员，返回候选人与提示
+func (s *scheduleService) simulatedCandidates(ctx context.Context, semesterID string, candidates []scheduleCandidate, req *dto.SimulateScheduleRequest) ([]scheduleCandidate, []string, error) {
+	var warnings []string
+	removed := make(map[string]bool, len(req.RemoveMembers))
+	for _, id := range req.RemoveMembers {
+		removed[id] = true
+	}
+	result := make([]scheduleCandidate, 0, len(candidates)+len(req.AddMembers))
+	present := make(map[string]bool, len(candidates))
+	for _, c := range candidates {
+		if removed[c.userID] {
+			delete(removed, c.userID)
+			continue
+		}
+		result = append(result, c)
+		present[c.userID] = true
+	}
+	for _, id := range req.RemoveMembers {
+		if removed[id] {
+			warnings = append(warnings, fmt.Sprintf("成员 %s 不在值班名单中，已忽略移除", id))
+		}
+	}
+
+	for _, id := range req.AddMembers {
+		if present[id] {
+			continue
+		}
+		user, err := s.repo.User.GetByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, nil, ErrUserNotFound
+			}
+			s.logger.Error("查询用户失败", zap.Error(err))
+			return nil, nil, err
+		}
+		assignment, err := s.repo.UserSemesterAssignment.GetByUserAndSemester(ctx, id, semesterID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			s.logger.Error("查询学期分配失败", zap.Error(err))
+			return nil, nil, err
+		}
+		if assignment == nil || assignment.TimetableStatus != model.TimetableStatusSubmitted {
+			warnings = append(warnings, fmt.Sprintf("成员「%s」未提交本学期课表，按无课计算", user.Name))
+		}
+		result = append(result, scheduleCandidate{userID: user.UserID, departmentID: user.DepartmentID, name: user.Name})
+		present[id] = true
+	}
+	return result, warnings, nil
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/model"
+)
+
+func TestScheduleService_SimulateSchedule_RemoveMember(t *testing.T) {
+	svc, repos := setupTestScheduleService()
+	seedBasicData(repos)
+
+	result, err := svc.SimulateSchedule(context.Background(), &dto.SimulateScheduleRequest{
+		SemesterID:    "sem-1",
+		RemoveMembers: []string{"user-2"},
+	})
+	if err != nil {
+		t.Fatalf("SimulateSchedule 应成功: %v", err)
+	}
+	if result.Baseline.FilledSlots != 4 {
+		t.Errorf("baseline 应填满 4 个槽位，实际 %d", result.Baseline.FilledSlots)
+	}
+	// 仅剩 user-1，R6 同日不重复 → 每周只能排 1 个班次
+	if result.Quality.FilledSlots != 2 || result.Quality.MemberCount != 1 {
+		t.Errorf("移除 user-2 后应填 2 个槽位、1 名成员，实际 %d / %d", result.Quality.FilledSlots, result.Quality.MemberCount)
+	}
+	if len(repos.schedule.schedules) != 0 || len(repos.scheduleItem.items) != 0 {
+		t.Error("模拟排班不应写入排班表或排班项")
+	}
+}
+
+func TestScheduleService_SimulateSchedule_AddMemberAndTimeSlot(t *testing.T) {
+	svc, repos := setupTestScheduleService()
+	seedBasicData(repos)
+	repos.user.users["user-3"] = &model.User{UserID: "user-3", Name: "王五", DepartmentID: "dept-1"}
+	repos.scheduleRule.rules["r3"].IsConfigurable = true
+
+	result, err := svc.SimulateSchedule(context.Background(), &dto.SimulateScheduleRequest{
+		SemesterID:    "sem-1",
+		AddMembers:    []string{"user-3"},
+		AddTimeSlots:  []dto.SimulatedTimeSlot{{Name: "周二上午", DayOfWeek: 2, StartTime: "08:10", EndTime: "10:05"}},
+		RuleOverrides: map[string]bool{"R3": false},
+	})
+	if err != nil {
+		t.Fatalf("SimulateSchedule 应成功: %v", err)
+	}
+	if result.Quality.TotalSlots != 6 || result.Quality.FilledSlots != 6 {
+		t.Errorf("新增时段与成员后应 6/6，实际 %d/%d", result.Quality.FilledSlots, result.Quality.TotalSlots)
+	}
+	found := false
+	for _, w := range result.Warnings {
+		if strings.Contains(w, "王五") && strings.Contains(w, "未提交") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("未提交课表的新增成员应有提示，实际=%v", result.Warnings)
+	}
+	for _, v := range result.Quality.Violations {
+		if v.RuleCode == "R3" {
+			t.Error("R3 已在模拟中停用，不应出现在违反统计中")
+		}
+	}
+}
+
+func TestScheduleService_SimulateSchedule_RuleWeights(t *testing.T) {
+	svc, repos := setupTestScheduleService()
+	seedBasicData(repos)
+	ctx := context.Background()
+
+	if _, err := svc.SimulateSchedule(ctx, &dto.SimulateScheduleRequest{
+		SemesterID: "sem-1", RuleWeights: map[string]int{"R1": 10},
+	}); !errors.Is(err, ErrSimulationRuleWeight) {
+		t.Errorf("硬约束不可调整罚分，应返回 ErrSimulationRuleWeight，实际: %v", err)
+	}
+	if _, err := svc.SimulateSchedule(ctx, &dto.SimulateScheduleRequest{
+		SemesterID:   "sem-1",
+		AddTimeSlots: []dto.SimulatedTimeSlot{{Name: "周二上午", DayOfWeek: 2, StartTime: "10:00", EndTime: "09:00"}},
+	}); !errors.Is(err, ErrSimulationTimeSlot) {
+		t.Errorf("结束早于开始应返回 ErrSimulationTimeSlot，实际: %v", err)
+	}
+	if _, err := svc.SimulateSchedule(ctx, &dto.SimulateScheduleRequest{
+		SemesterID: "sem-1", RemoveTimeSlots: []string{"ts-404"},
+	}); !errors.Is(err, ErrTimeSlotNotFound) {
+		t.Errorf("移除不存在的时间段应返回 ErrTimeSlotNotFound，实际: %v", err)
+	}
+
+	// 同部门两人同日值班：R3 罚分按覆盖的权重计
+	repos.assignment.assignments[1].User.DepartmentID = "dept-1"
+	result, err := svc.SimulateSchedule(ctx, &dto.SimulateScheduleRequest{
+		SemesterID: "sem-1", RuleWeights: map[string]int{"R3": 7},
+	})
+	if err != nil {
+		t.Fatalf("SimulateSchedule 应成功: %v", err)
+	}
+	for _, v := range result.Quality.Violations {
+		if v.RuleCode == "R3" && v.Penalty != v.Count*7 {
+			t.Errorf("R3 罚分应按权重 7 计算，实际 count=%d penalty=%d", v.Count, v.Penalty)
+		}
+	}
+	for _, v := range result.Baseline.Violations {
+		if v.RuleCode == "R3" && v.Penalty != v.Count*penaltyR3 {
+			t.Errorf("baseline 应使用默认罚分，实际 count=%d penalty=%d", v.Count, v.Penalty)
+		}
+	}
+}
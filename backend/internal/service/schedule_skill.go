@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+
+	"echo-union/backend/internal/model"
+	"echo-union/backend/internal/repository"
+)
+
+// ── 技能要求 ──
+//
+// 时间段 / 活动班次可设置所需技能，值班成员须全部具备（硬约束）；未设置技能要求的时段不受影响。
+// 自动排班、候选人推荐、手工调整与活动班次安排共用同一判定。
+
+// skillHolders 成员 → 已具备的技能集合（只加载被要求的技能）
+type skillHolders map[string]map[string]bool
+
+// loadSkillHolders 加载具备任一所需技能的成员；无技能要求时不访问数据库
+func loadSkillHolders(ctx context.Context, repo *repository.Repository, required []model.Skill) (skillHolders, error) {
+	holders := make(skillHolders)
+	if len(required) == 0 {
+		return holders, nil
+	}
+	ids := make([]string, 0, len(required))
+	seen := make(map[string]bool, len(required))
+	for _, skill := range required {
+		if !seen[skill.SkillID] {
+			seen[skill.SkillID] = true
+			ids = append(ids, skill.SkillID)
+		}
+	}
+	list, err := repo.Skill.ListHolders(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, us := range list {
+		if holders[us.UserID] == nil {
+			holders[us.UserID] = make(map[string]bool)
+		}
+		holders[us.UserID][us.SkillID] = true
+	}
+	return holders, nil
+}
+
+// missing 返回成员缺少的所需技能名称，空表示满足要求
+func (h skillHolders) missing(userID string, required []model.Skill) []string {
+	var names []string
+	for _, skill := range required {
+		if !h[userID][skill.SkillID] {
+			names = append(names, skill.Name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/model"
+)
+
+// seedSkillRequirement 登记技能，要求时间段具备该技能，并授予指定成员
+func seedSkillRequirement(repos *testScheduleRepos, timeSlotID string, holders ...string) *model.Skill {
+	skill := repos.skill.seedSkill("skill-audio", "音响设备")
+	_ = repos.skill.ReplaceTimeSlotSkills(context.Background(), timeSlotID, []model.TimeSlotSkill{
+		{TimeSlotID: timeSlotID, SkillID: skill.SkillID},
+	})
+	for _, userID := range holders {
+		repos.skill.userSkills = append(repos.skill.userSkills, model.UserSkill{UserID: userID, SkillID: skill.SkillID})
+	}
+	return skill
+}
+
+func TestScheduleService_AutoSchedule_SkillRequired(t *testing.T) {
+	svc, repos := setupTestScheduleService()
+	seedBasicData(repos)
+	seedSkillRequirement(repos, "ts-1", "user-1")
+
+	result, err := svc.AutoSchedule(context.Background(), &dto.AutoScheduleRequest{SemesterID: "sem-1"}, "admin-1")
+	if err != nil {
+		t.Fatalf("AutoSchedule 应成功: %v", err)
+	}
+	for _, item := range result.Schedule.Items {
+		if item.TimeSlot != nil && item.TimeSlot.ID == "ts-1" && item.Member != nil && item.Member.ID != "user-1" {
+			t.Errorf("第%d周 ts-1 要求音响设备技能，不应安排 %s", item.WeekNumber, item.Member.ID)
+		}
+	}
+}
+
+func TestScheduleService_ValidateCandidate_MissingSkill(t *testing.T) {
+	svc, repos := setupTestScheduleService()
+	seedBasicData(repos)
+	seedDraftItems(repos)
+	seedSkillRequirement(repos, "ts-1", "user-1")
+
+	resp, err := svc.ValidateCandidate(context.Background(), "item-1", &dto.ValidateCandidateRequest{MemberID: "user-2"})
+	if err != nil {
+		t.Fatalf("ValidateCandidate 应成功: %v", err)
+	}
+	if resp.Valid {
+		t.Fatal("缺少所需技能的成员不应通过校验")
+	}
+	found := false
+	for _, c := range resp.Conflicts {
+		if strings.Contains(c, "缺少技能") && strings.Contains(c, "音响设备") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("冲突信息应说明缺少的技能，实际=%v", resp.Conflicts)
+	}
+
+	resp, err = svc.ValidateCandidate(context.Background(), "item-1", &dto.ValidateCandidateRequest{MemberID: "user-1"})
+	if err != nil {
+		t.Fatalf("ValidateCandidate 应成功: %v", err)
+	}
+	if !resp.Valid {
+		t.Errorf("具备技能的成员应通过校验，实际冲突=%v", resp.Conflicts)
+	}
+}
+
+func TestScheduleService_ExplainItem_Unqualified(t *testing.T) {
+	svc, repos := setupTestScheduleService()
+	seedBasicData(repos)
+	seedDraftItems(repos)
+	seedSkillRequirement(repos, "ts-1", "user-1")
+
+	resp, err := svc.ExplainItem(context.Background(), "item-1")
+	if err != nil {
+		t.Fatalf("ExplainItem 应成功: %v", err)
+	}
+	for _, m := range resp.Members {
+		if m.Member.ID == "user-2" && m.Status != "unqualified" {
+			t.Errorf("user-2 缺少技能应为 unqualified，实际=%s", m.Status)
+		}
+	}
+}
+
+// ════════════════════════════════════════════════════════════
+// 活动班次技能要求
+// ════════════════════════════════════════════════════════════
+
+func TestEventService_SignUpRequiresSkill(t *testing.T) {
+	repos, svc := setupEventTest(t)
+	skill := repos.skill.seedSkill("skill-audio", "音响设备")
+	repos.skill.userSkills = append(repos.skill.userSkills, model.UserSkill{UserID: "user-3", SkillID: skill.SkillID})
+	eventID, shiftIDs := createTestEvent(t, svc, true,
+		dto.CreateEventShiftRequest{ShiftDate: "2099-09-07", StartTime: "18:00", EndTime: "20:00", Headcount: 2, SkillIDs: []string{skill.SkillID}})
+	ctx := context.Background()
+	if _, err := svc.PublishEvent(ctx, eventID, "admin-1"); err != nil {
+		t.Fatalf("发布失败: %v", err)
+	}
+
+	if err := svc.SignUp(ctx, shiftIDs[0], "user-2"); !errors.Is(err, ErrEventMemberUnqualified) {
+		t.Errorf("缺少技能报名应返回 ErrEventMemberUnqualified，实际: %v", err)
+	}
+	if err := svc.SignUp(ctx, shiftIDs[0], "user-3"); err != nil {
+		t.Errorf("具备技能成员报名失败: %v", err)
+	}
+}
+
+func TestEventService_AutoAssignRequiresSkill(t *testing.T) {
+	repos, svc := setupEventTest(t)
+	skill := repos.skill.seedSkill("skill-audio", "音响设备")
+	repos.skill.userSkills = append(repos.skill.userSkills, model.UserSkill{UserID: "user-2", SkillID: skill.SkillID})
+	eventID, shiftIDs := createTestEvent(t, svc, false,
+		dto.CreateEventShiftRequest{ShiftDate: "2099-09-08", StartTime: "18:00", EndTime: "20:00", Headcount: 2, SkillIDs: []string{skill.SkillID}})
+
+	result, err := svc.AutoAssign(context.Background(), eventID, "admin-1")
+	if err != nil {
+		t.Fatalf("自动分配失败: %v", err)
+	}
+	if result.Assigned != 1 {
+		t.Errorf("只有 user-2 具备技能，应分配 1 人次，实际 %d", result.Assigned)
+	}
+	if len(result.Unfilled) != 1 || result.Unfilled[0].ShiftID != shiftIDs[0] || result.Unfilled[0].Missing != 1 {
+		t.Errorf("班次应缺 1 人，实际: %+v", result.Unfilled)
+	}
+}
+
+// ════════════════════════════════════════════════════════════
+// SkillService 测试
+// ════════════════════════════════════════════════════════════
+
+func TestSkillService_CreateUpdateDelete(t *testing.T) {
+	repos := newTestScheduleRepos()
+	svc := NewSkillService(repos.toRepository(), zap.NewNop())
+	ctx := context.Background()
+
+	audio, err := svc.Create(ctx, &dto.CreateSkillRequest{Name: "音响设备"}, "admin-1")
+	if err != nil {
+		t.Fatalf("Create 应成功: %v", err)
+	}
+	if _, err := svc.Create(ctx, &dto.CreateSkillRequest{Name: "音响设备"}, "admin-1"); !errors.Is(err, ErrSkillNameExists) {
+		t.Errorf("重名应返回 ErrSkillNameExists，实际: %v", err)
+	}
+	key, err := svc.Create(ctx, &dto.CreateSkillRequest{Name: "办公室钥匙"}, "admin-1")
+	if err != nil {
+		t.Fatalf("Create 应成功: %v", err)
+	}
+
+	name := "音响设备"
+	if _, err := svc.Update(ctx, key.ID, &dto.UpdateSkillRequest{Name: &name}, "admin-1"); !errors.Is(err, ErrSkillNameExists) {
+		t.Errorf("改为已有名称应返回 ErrSkillNameExists，实际: %v", err)
+	}
+	if _, err := svc.Update(ctx, audio.ID, &dto.UpdateSkillRequest{Name: &name}, "admin-1"); err != nil {
+		t.Errorf("名称不变的更新应成功: %v", err)
+	}
+
+	repos.skill.userSkills = append(repos.skill.userSkills, model.UserSkill{UserID: "user-1", SkillID: audio.ID})
+	if err := svc.Delete(ctx, audio.ID, "admin-1"); err != nil {
+		t.Fatalf("Delete 应成功: %v", err)
+	}
+	if len(repos.skill.userSkills) != 0 {
+		t.Error("删除技能应一并移除成员技能")
+	}
+	if err := svc.Delete(ctx, audio.ID, "admin-1"); !errors.Is(err, ErrSkillNotFound) {
+		t.Errorf("重复删除应返回 ErrSkillNotFound，实际: %v", err)
+	}
+}
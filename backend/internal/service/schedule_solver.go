@@ -0,0 +1,668 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/model"
+)
+
+// ── 排班求解器 ──
+//
+// 求解器只操作内存数据、不访问数据库：自动排班、候选方案生成与质量评估共用同一套约束判定。
+// 硬约束：R1 课表冲突、R2 不可用时间、R6 同人同日不重复、R7 搭配约束（不同时段 / 不相邻）、时段技能要求；
+// 软约束：R3 同日部门不重复、R4 相邻班次部门不重复、R5 单双周早八部门不重复、R7 搭配约束（需同时段）；
+// R8 每周班次上限、R9 班次最小间隔、R10 避免连续两天值班按系统配置作为硬约束或软约束。
+
+// 软约束罚分与求解参数
+const (
+	penaltyR3 = 50 // 同日部门重复
+	penaltyR4 = 30 // 相邻班次部门重复
+	penaltyR5 = 20 // 单双周早八部门重复
+	penaltyR7 = 60 // 需搭配的成员未同时段值班
+
+	// earlySlotStart 开始时间不晚于该时刻的班次视为"早八"
+	earlySlotStart = "08:30"
+	// greedyWorkloadWeight 贪心打分中每加权小时（时长 × 冷门系数）的权重，保证"累计成本低优先"压过软约束罚分
+	greedyWorkloadWeight = 100
+	// workloadWeight 局部搜索目标函数中工作量均衡项权重（按人均累计加权成本平方和计）
+	workloadWeight = 100
+	// localSearchIterations 局部搜索最大迭代次数
+	localSearchIterations = 5000
+	// localSearchDefaultSeed 未指定 seed 时局部搜索使用的固定种子，保证结果可复现
+	localSearchDefaultSeed = 1
+)
+
+// scheduleCandidate 排班候选人
+type scheduleCandidate struct {
+	userID       string
+	departmentID string
+	name         string
+}
+
+// scheduleSlot 排班槽位: week_number × time_slot
+type scheduleSlot struct {
+	weekNumber int
+	timeSlot   model.TimeSlot
+}
+
+func (sl scheduleSlot) key() string {
+	return slotKey(sl.weekNumber, sl.timeSlot.TimeSlotID)
+}
+
+// scheduleAssignment 求解结果中的单个排班
+type scheduleAssignment struct {
+	weekNumber int
+	timeSlotID string
+	memberID   string
+}
+
+// slotAvailability 成员在槽位上的可用性及冲突原因
+type slotAvailability struct {
+	available bool
+	conflicts []string
+	courses   []string // R1 冲突课程名
+	reasons   []string // R2 不可用时间原因（未填写原因时为空串）
+	skills    []string // 缺少的所需技能名称
+}
+
+// solverInput 求解所需的全部内存数据
+type solverInput struct {
+	semester         *model.Semester
+	candidates       []scheduleCandidate
+	slots            []scheduleSlot
+	userCourses      map[string][]model.CourseSchedule
+	userUnavailables map[string][]model.UnavailableTime
+	rules            map[string]bool
+	timeSlots        []model.TimeSlot // 槽位对应的时段模板（判定相邻时段）
+	pairs            pairIndex        // R7 成员搭配约束
+	rest             restPolicy       // R8 / R9 / R10 休息约束参数
+	skills           skillHolders     // 时段技能要求涉及的成员技能
+	weights          map[string]int   // 软约束罚分覆盖（模拟排班使用，为空时取默认罚分）
+}
+
+// solverOptions 求解参数
+type solverOptions struct {
+	solver string // greedy | local_search
+	seed   *int64 // nil 表示按姓名打破平局（结果确定）
+}
+
+// solverResult 求解结果
+type solverResult struct {
+	assignments []scheduleAssignment
+	warnings    []string
+	penalty     int // 软约束总罚分
+}
+
+func slotKey(weekNumber int, timeSlotID string) string {
+	return fmt.Sprintf("%d:%s", weekNumber, timeSlotID)
+}
+
+func availabilityKey(userID string, weekNumber int, timeSlotID string) string {
+	return fmt.Sprintf("%s:%d:%s", userID, weekNumber, timeSlotID)
+}
+
+func isEarlySlot(ts model.TimeSlot) bool {
+	return ts.StartTime <= earlySlotStart
+}
+
+// availabilityOf 计算成员在槽位上的硬约束（R1/R2/技能要求）可用性
+func (in *solverInput) availabilityOf(userID string, sl scheduleSlot) *slotAvailability {
+	avail := &slotAvailability{available: true}
+	weekType := weekNumberToType(sl.weekNumber, in.semester.FirstWeekType)
+
+	// R1: 课表冲突检测（硬约束）
+	if in.rules["R1"] {
+		for _, course := range in.userCourses[userID] {
+			if hasTimeConflict(course.DayOfWeek, course.StartTime, course.EndTime, course.WeekType,
+				sl.timeSlot.DayOfWeek, sl.timeSlot.StartTime, sl.timeSlot.EndTime, weekType) {
+				avail.available = false
+				avail.conflicts = append(avail.conflicts, fmt.Sprintf("课程冲突: %s", course.CourseName))
+				avail.courses = append(avail.courses, course.CourseName)
+			}
+		}
+	}
+
+	// R2: 不可用时间检测（硬约束）
+	if in.rules["R2"] {
+		for _, ut := range in.userUnavailables[userID] {
+			if hasUnavailableConflict(ut, sl.timeSlot.DayOfWeek, sl.timeSlot.StartTime, sl.timeSlot.EndTime, weekType) {
+				avail.available = false
+				reason := "不可用时间冲突"
+				if ut.Reason != "" {
+					reason = fmt.Sprintf("不可用时间: %s", ut.Reason)
+				}
+				avail.conflicts = append(avail.conflicts, reason)
+				avail.reasons = append(avail.reasons, ut.Reason)
+			}
+		}
+	}
+
+	// 技能要求（硬约束）
+	for _, name := range in.skills.missing(userID, timeSlotSkills(&sl.timeSlot)) {
+		avail.available = false
+		avail.conflicts = append(avail.conflicts, fmt.Sprintf("缺少技能: %s", name))
+		avail.skills = append(avail.skills, name)
+	}
+
+	return avail
+}
+
+// slotIndex 构建 "week:slotID" → 槽位 索引
+func (in *solverInput) slotIndex() map[string]scheduleSlot {
+	index := make(map[string]scheduleSlot, len(in.slots))
+	for _, sl := range in.slots {
+		index[sl.key()] = sl
+	}
+	return index
+}
+
+// departmentIndex 构建 userID → departmentID 索引
+func (in *solverInput) departmentIndex() map[string]string {
+	index := make(map[string]string, len(in.candidates))
+	for _, c := range in.candidates {
+		index[c.userID] = c.departmentID
+	}
+	return index
+}
+
+// ════════════════════════════════════════════════════════════
+// 软约束评估（整体方案）
+// ════════════════════════════════════════════════════════════
+
+// defaultPenalties 软约束规则的默认罚分
+var defaultPenalties = map[string]int{
+	"R3": penaltyR3, "R4": penaltyR4, "R5": penaltyR5, "R7": penaltyR7,
+	"R8": penaltyR8, "R9": penaltyR9, "R10": penaltyR10,
+}
+
+// penalty 软约束规则的罚分（优先取本次求解的权重覆盖）
+func (in *solverInput) penalty(ruleCode string) int {
+	if w, ok := in.weights[ruleCode]; ok {
+		return w
+	}
+	return defaultPenalties[ruleCode]
+}
+
+// softPenalty 方案软约束评估结果
+type softPenalty struct {
+	total      int
+	violations map[string]int // ruleCode → 违反次数
+	penalties  map[string]int // ruleCode → 罚分
+}
+
+func (p *softPenalty) add(ruleCode string, weight int) {
+	p.violations[ruleCode]++
+	p.penalties[ruleCode] += weight
+	p.total += weight
+}
+
+// evaluateSoftPenalty 对完整方案计算软约束罚分（仅统计已启用规则）
+func (in *solverInput) evaluateSoftPenalty(assignments []scheduleAssignment) softPenalty {
+	result := softPenalty{violations: map[string]int{}, penalties: map[string]int{}}
+	slots := in.slotIndex()
+	depts := in.departmentIndex()
+
+	assigned := make(map[string]string, len(assignments)) // "week:slotID" → deptID
+	for _, a := range assignments {
+		assigned[slotKey(a.weekNumber, a.timeSlotID)] = depts[a.memberID]
+	}
+
+	// R3: 同日部门不重复 —— 同周同日同部门每多一人计一次
+	if in.rules["R3"] {
+		dayDept := make(map[string]int)
+		for _, a := range assignments {
+			sl, ok := slots[slotKey(a.weekNumber, a.timeSlotID)]
+			if !ok {
+				continue
+			}
+			dept := depts[a.memberID]
+			if dept == "" {
+				continue
+			}
+			dayDept[fmt.Sprintf("%d:%d:%s", a.weekNumber, sl.timeSlot.DayOfWeek, dept)]++
+		}
+		for _, n := range dayDept {
+			for i := 1; i < n; i++ {
+				result.add("R3", in.penalty("R3"))
+			}
+		}
+	}
+
+	// 按 周:星期 分组并按开始时间排序，用于判定相邻班次
+	dayslots := make(map[string][]scheduleSlot)
+	for _, sl := range in.slots {
+		key := fmt.Sprintf("%d:%d", sl.weekNumber, sl.timeSlot.DayOfWeek)
+		dayslots[key] = append(dayslots[key], sl)
+	}
+	for _, list := range dayslots {
+		sort.Slice(list, func(i, j int) bool { return list[i].timeSlot.StartTime < list[j].timeSlot.StartTime })
+	}
+
+	// R4: 相邻班次部门不重复
+	if in.rules["R4"] {
+		for _, list := range dayslots {
+			for i := 1; i < len(list); i++ {
+				prev, okPrev := assigned[list[i-1].key()]
+				cur, okCur := assigned[list[i].key()]
+				if okPrev && okCur && prev != "" && prev == cur {
+					result.add("R4", in.penalty("R4"))
+				}
+			}
+		}
+	}
+
+	// R5: 单双周早八部门不重复 —— 同一星期两周早八班次部门相同计一次
+	if in.rules["R5"] {
+		for _, sl := range in.slots {
+			if sl.weekNumber != 1 || !isEarlySlot(sl.timeSlot) {
+				continue
+			}
+			dept, ok := assigned[sl.key()]
+			if !ok || dept == "" {
+				continue
+			}
+			for _, other := range dayslots[fmt.Sprintf("%d:%d", 2, sl.timeSlot.DayOfWeek)] {
+				if isEarlySlot(other.timeSlot) && assigned[other.key()] == dept {
+					result.add("R5", in.penalty("R5"))
+				}
+			}
+		}
+	}
+
+	// R7: 需搭配的成员未同时段值班 —— 每个落单的班次计一次
+	if in.rules["R7"] && len(in.pairs) > 0 {
+		placed := in.placedSlots(assignments)
+		for _, a := range assignments {
+			sl, ok := slots[slotKey(a.weekNumber, a.timeSlotID)]
+			if !ok {
+				continue
+			}
+			for _, v := range in.pairViolations(a.memberID, sl, placed) {
+				if !v.hard() {
+					result.add("R7", in.penalty("R7"))
+				}
+			}
+		}
+	}
+
+	// R8 / R9 / R10: 休息与负载分散（仅统计软约束模式）
+	if in.rules["R8"] || in.rules["R9"] || in.rules["R10"] {
+		in.evaluateRestPenalty(in.placedSlots(assignments), &result)
+	}
+
+	return result
+}
+
+// ════════════════════════════════════════════════════════════
+// scheduleSolver — 求解流程
+// ════════════════════════════════════════════════════════════
+
+type scheduleSolver struct {
+	in       *solverInput
+	opts     solverOptions
+	rng      *rand.Rand // nil 表示确定性求解
+	matrix   map[string]*slotAvailability
+	progress *dto.AutoScheduleProgress
+	notify   func()
+}
+
+func newScheduleSolver(in *solverInput, opts solverOptions, progress *dto.AutoScheduleProgress, notify func()) *scheduleSolver {
+	if opts.solver == "" {
+		opts.solver = model.SolverGreedy
+	}
+	if progress == nil {
+		progress = &dto.AutoScheduleProgress{}
+	}
+	if notify == nil {
+		notify = func() {}
+	}
+	solver := &scheduleSolver{in: in, opts: opts, progress: progress, notify: notify}
+	if opts.seed != nil {
+		solver.rng = rand.New(rand.NewSource(*opts.seed))
+	}
+	return solver
+}
+
+// solve 构建可用性矩阵 → 贪心初解 →（可选）局部搜索
+func (s *scheduleSolver) solve(ctx context.Context) (*solverResult, error) {
+	s.progress.Phase = "matrix"
+	s.progress.TotalSlots = len(s.in.slots)
+	s.notify()
+	s.buildMatrix()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.progress.Phase = "assign"
+	s.notify()
+	result, err := s.greedy(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.opts.solver == model.SolverLocalSearch {
+		s.progress.Phase = "improve"
+		s.notify()
+		if err := s.improve(ctx, result); err != nil {
+			return nil, err
+		}
+	}
+
+	result.penalty = s.in.evaluateSoftPenalty(result.assignments).total
+	s.progress.BestScore = result.penalty
+	return result, nil
+}
+
+// buildMatrix 构建可用性矩阵: "userID:week:slotID" → availability
+func (s *scheduleSolver) buildMatrix() {
+	s.matrix = make(map[string]*slotAvailability, len(s.in.candidates)*len(s.in.slots))
+	for _, c := range s.in.candidates {
+		for _, sl := range s.in.slots {
+			s.matrix[availabilityKey(c.userID, sl.weekNumber, sl.timeSlot.TimeSlotID)] = s.in.availabilityOf(c.userID, sl)
+		}
+	}
+}
+
+func (s *scheduleSolver) available(userID string, weekNumber int, timeSlotID string) bool {
+	a, ok := s.matrix[availabilityKey(userID, weekNumber, timeSlotID)]
+	return ok && a.available
+}
+
+// greedy 最难排的槽位优先；同分候选人按姓名（指定 seed 时按随机顺序）打破平局
+func (s *scheduleSolver) greedy(ctx context.Context) (*solverResult, error) {
+	candidates := append([]scheduleCandidate(nil), s.in.candidates...)
+	slots := append([]scheduleSlot(nil), s.in.slots...)
+	if s.rng != nil {
+		s.rng.Shuffle(len(candidates), func(i, j int) { candidates[i], candidates[j] = candidates[j], candidates[i] })
+		s.rng.Shuffle(len(slots), func(i, j int) { slots[i], slots[j] = slots[j], slots[i] })
+	} else {
+		sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].name < candidates[j].name })
+	}
+	rank := make(map[string]int, len(candidates))
+	for i, c := range candidates {
+		rank[c.userID] = i
+	}
+
+	// 统计每个槽位的可用人数
+	type slotInfo struct {
+		slot           scheduleSlot
+		availableCount int
+	}
+	slotInfos := make([]slotInfo, 0, len(slots))
+	for _, sl := range slots {
+		count := 0
+		for _, c := range candidates {
+			if s.available(c.userID, sl.weekNumber, sl.timeSlot.TimeSlotID) {
+				count++
+			}
+		}
+		slotInfos = append(slotInfos, slotInfo{slot: sl, availableCount: count})
+	}
+
+	// 按可用人数升序排列（最难排的槽位优先）
+	sort.SliceStable(slotInfos, func(i, j int) bool {
+		return slotInfos[i].availableCount < slotInfos[j].availableCount
+	})
+
+	result := &solverResult{warnings: make([]string, 0)}
+
+	// 跟踪每人累计加权成本
+	memberCost := make(map[string]float64)
+	// 跟踪每人每天已排（R6: 同人同日不重复）
+	memberDayWeek := make(map[string]bool) // "userID:week:dayOfWeek"
+	// 跟踪每天每部门已排（R3: 同日部门不重复）
+	dayDeptWeek := make(map[string]bool) // "week:dayOfWeek:deptID"
+	// 跟踪相邻班次部门（R4）
+	slotDeptWeek := make(map[string]string) // "week:slotID" → deptID
+	// 跟踪每人已排槽位（R7）
+	placed := make(map[string][]scheduleSlot)
+
+	for _, si := range slotInfos {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		sl := si.slot
+		dayKey := fmt.Sprintf("%d:%d", sl.weekNumber, sl.timeSlot.DayOfWeek)
+
+		// 收集可用候选人
+		type scoredCandidate struct {
+			candidate scheduleCandidate
+			score     int // 越小越优先
+			penalty   int // 软约束罚分
+		}
+		var availCandidates []scoredCandidate
+
+		for _, c := range candidates {
+			if !s.available(c.userID, sl.weekNumber, sl.timeSlot.TimeSlotID) {
+				continue
+			}
+
+			// R6: 同人同日不重复（硬约束）
+			mdKey := fmt.Sprintf("%s:%s", c.userID, dayKey)
+			if memberDayWeek[mdKey] {
+				continue
+			}
+
+			// R7: 搭配约束 —— 不同时段 / 不相邻为硬约束，需同时段为软约束
+			pairs := s.in.pairViolations(c.userID, sl, placed)
+			if hasHardPairViolation(pairs) {
+				continue
+			}
+			penalty := len(pairs) * s.in.penalty("R7")
+
+			// R8 / R9 / R10: 休息约束（按配置为硬约束或软约束，跨周期首尾判定）
+			rest := s.in.restViolations(c.userID, sl, placed)
+			if hasHardRestViolation(rest) {
+				continue
+			}
+			penalty += s.in.softRestPenalty(rest)
+
+			// R3: 同日部门不重复（软约束）
+			if s.in.rules["R3"] {
+				ddKey := fmt.Sprintf("%s:%s", dayKey, c.departmentID)
+				if dayDeptWeek[ddKey] {
+					penalty += s.in.penalty("R3")
+				}
+			}
+
+			// R4: 相邻班次部门不重复（软约束）
+			if s.in.rules["R4"] {
+				if prevDept, exists := slotDeptWeek[sl.key()]; exists && prevDept == c.departmentID {
+					penalty += s.in.penalty("R4")
+				}
+			}
+
+			// R5: 单双周早八不重复（软约束）
+			if s.in.rules["R5"] {
+				if isEarlySlot(sl.timeSlot) {
+					otherWeek := 3 - sl.weekNumber // 1→2, 2→1
+					for _, otherSl := range s.in.slots {
+						if otherSl.weekNumber == otherWeek &&
+							otherSl.timeSlot.DayOfWeek == sl.timeSlot.DayOfWeek &&
+							isEarlySlot(otherSl.timeSlot) {
+							if assignedDept, exists := slotDeptWeek[otherSl.key()]; exists && assignedDept == c.departmentID {
+								penalty += s.in.penalty("R5")
+							}
+						}
+					}
+				}
+			}
+
+			// 累计加权成本低优先，其次软约束罚分低优先
+			score := workloadScore(memberCost[c.userID]) + penalty
+			availCandidates = append(availCandidates, scoredCandidate{candidate: c, score: score, penalty: penalty})
+		}
+
+		s.progress.ProcessedSlots++
+
+		if len(availCandidates) == 0 {
+			result.warnings = append(result.warnings, fmt.Sprintf("时段 %s (第%d周 周%d %s-%s) 无可用候选人",
+				sl.timeSlot.Name, sl.weekNumber, sl.timeSlot.DayOfWeek, sl.timeSlot.StartTime, sl.timeSlot.EndTime))
+			s.notify()
+			continue
+		}
+
+		sort.SliceStable(availCandidates, func(i, j int) bool {
+			if availCandidates[i].score != availCandidates[j].score {
+				return availCandidates[i].score < availCandidates[j].score
+			}
+			return rank[availCandidates[i].candidate.userID] < rank[availCandidates[j].candidate.userID]
+		})
+
+		chosen := availCandidates[0].candidate
+		result.assignments = append(result.assignments, scheduleAssignment{
+			weekNumber: sl.weekNumber,
+			timeSlotID: sl.timeSlot.TimeSlotID,
+			memberID:   chosen.userID,
+		})
+
+		// 更新跟踪状态
+		memberCost[chosen.userID] += slotCost(sl.timeSlot)
+		memberDayWeek[fmt.Sprintf("%s:%s", chosen.userID, dayKey)] = true
+		dayDeptWeek[fmt.Sprintf("%s:%s", dayKey, chosen.departmentID)] = true
+		slotDeptWeek[sl.key()] = chosen.departmentID
+		placed[chosen.userID] = append(placed[chosen.userID], sl)
+
+		s.progress.FilledSlots++
+		s.progress.BestScore += availCandidates[0].penalty
+		s.notify()
+	}
+
+	return result, nil
+}
+
+// objective 局部搜索目标函数：软约束罚分 + 工作量均衡项（越小越好）
+func (s *scheduleSolver) objective(assignments []scheduleAssignment) (total, penalty int) {
+	penalty = s.in.evaluateSoftPenalty(assignments).total
+	balance := 0.0
+	for _, load := range s.in.memberLoads(assignments) {
+		balance += load.cost * load.cost
+	}
+	return penalty + int(math.Round(balance*workloadWeight)), penalty
+}
+
+// improve 在贪心初解基础上做局部搜索：随机尝试"交换两项成员"或"替换单项成员"，
+// 仅接受满足全部硬约束且目标函数下降的变更
+func (s *scheduleSolver) improve(ctx context.Context, result *solverResult) error {
+	assignments := result.assignments
+	if len(assignments) == 0 || len(s.in.candidates) == 0 {
+		return nil
+	}
+
+	rng := s.rng
+	if rng == nil {
+		rng = rand.New(rand.NewSource(localSearchDefaultSeed))
+	}
+	slots := s.in.slotIndex()
+	dayOf := func(a scheduleAssignment) int {
+		return slots[slotKey(a.weekNumber, a.timeSlotID)].timeSlot.DayOfWeek
+	}
+	// busyOnDay R6: 成员在同周同日是否已有其他排班（忽略 skip 中的下标）
+	busyOnDay := func(memberID string, weekNumber, day int, skip ...int) bool {
+		for idx, a := range assignments {
+			ignored := false
+			for _, k := range skip {
+				if idx == k {
+					ignored = true
+					break
+				}
+			}
+			if !ignored && a.memberID == memberID && a.weekNumber == weekNumber && dayOf(a) == day {
+				return true
+			}
+		}
+		return false
+	}
+
+	best, _ := s.objective(assignments)
+	for iter := 0; iter < localSearchIterations; iter++ {
+		if iter%100 == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+
+		i, j := rng.Intn(len(assignments)), -1
+		a := assignments[i]
+		var undo func()
+
+		if rng.Intn(2) == 0 && len(assignments) > 1 {
+			// 交换两项成员
+			j = rng.Intn(len(assignments))
+			b := assignments[j]
+			if i == j || a.memberID == b.memberID {
+				continue
+			}
+			if !s.available(b.memberID, a.weekNumber, a.timeSlotID) || !s.available(a.memberID, b.weekNumber, b.timeSlotID) {
+				continue
+			}
+			if busyOnDay(b.memberID, a.weekNumber, dayOf(a), i, j) || busyOnDay(a.memberID, b.weekNumber, dayOf(b), i, j) {
+				continue
+			}
+			assignments[i].memberID, assignments[j].memberID = b.memberID, a.memberID
+			undo = func() { assignments[i].memberID, assignments[j].memberID = a.memberID, b.memberID }
+		} else {
+			// 替换单项成员
+			c := s.in.candidates[rng.Intn(len(s.in.candidates))]
+			if c.userID == a.memberID || !s.available(c.userID, a.weekNumber, a.timeSlotID) {
+				continue
+			}
+			if busyOnDay(c.userID, a.weekNumber, dayOf(a), i) {
+				continue
+			}
+			assignments[i].memberID = c.userID
+			undo = func() { assignments[i].memberID = a.memberID }
+		}
+
+		// R7 / R8 / R9 / R10: 变更后的成员不得违反硬搭配约束与硬休息约束
+		if s.hardViolated(assignments, slots, i, j) {
+			undo()
+			continue
+		}
+
+		total, penalty := s.objective(assignments)
+		if total < best {
+			best = total
+			s.progress.BestScore = penalty
+			s.notify()
+			continue
+		}
+		undo()
+	}
+
+	return nil
+}
+
+// hardViolated 局部搜索变更后，下标 i、j（-1 表示无）对应的排班是否违反硬搭配约束或硬休息约束
+func (s *scheduleSolver) hardViolated(assignments []scheduleAssignment, slots map[string]scheduleSlot, indexes ...int) bool {
+	checkPairs := s.in.rules["R7"] && len(s.in.pairs) > 0
+	checkRest := false
+	for _, code := range []string{"R8", "R9", "R10"} {
+		checkRest = checkRest || (s.in.rules[code] && s.in.rest.hard(code))
+	}
+	if !checkPairs && !checkRest {
+		return false
+	}
+	placed := s.in.placedSlots(assignments)
+	for _, k := range indexes {
+		if k < 0 {
+			continue
+		}
+		m := assignments[k]
+		sl := slots[slotKey(m.weekNumber, m.timeSlotID)]
+		if checkPairs && hasHardPairViolation(s.in.pairViolations(m.memberID, sl, placed)) {
+			return true
+		}
+		if checkRest && hasHardRestViolation(s.in.restViolations(m.memberID, sl, placed)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,430 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/model"
+)
+
+// ── 排班版本历史 ──
+//
+// 每次自动排班都会归档上一版排班表，归档版本连同其变更日志一直保留。
+// 这里提供版本列表、任意两版本的差异对比，以及把归档版本恢复为新草稿。
+// 恢复不会修改原归档版本，其变更日志仍可按原 schedule_id 查询。
+
+// ════════════════════════════════════════════════════════════
+// ListVersions — 版本列表
+// ════════════════════════════════════════════════════════════
+
+func (s *scheduleService) ListVersions(ctx context.Context, semesterID string) ([]dto.ScheduleVersionResponse, error) {
+	if _, err := s.repo.Semester.GetByID(ctx, semesterID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSemesterNotFound
+		}
+		s.logger.Error("查询学期失败", zap.Error(err))
+		return nil, err
+	}
+
+	schedules, err := s.repo.Schedule.ListBySemester(ctx, semesterID)
+	if err != nil {
+		s.logger.Error("查询排班版本失败", zap.Error(err))
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(schedules))
+	for _, schedule := range schedules {
+		ids = append(ids, schedule.ScheduleID)
+	}
+	itemCounts, err := s.repo.ScheduleItem.BatchCountBySchedules(ctx, ids)
+	if err != nil {
+		s.logger.Error("统计排班项失败", zap.Error(err))
+		return nil, err
+	}
+
+	result := make([]dto.ScheduleVersionResponse, 0, len(schedules))
+	for i := range schedules {
+		schedule := &schedules[i]
+
+		_, logCount, err := s.repo.ScheduleChangeLog.ListBySchedule(ctx, schedule.ScheduleID, 0, 1)
+		if err != nil {
+			s.logger.Error("统计变更日志失败", zap.Error(err))
+			return nil, err
+		}
+
+		version := dto.ScheduleVersionResponse{
+			ID:             schedule.ScheduleID,
+			SemesterID:     schedule.SemesterID,
+			Status:         schedule.Status,
+			Name:           schedule.Name,
+			Solver:         schedule.Solver,
+			ItemCount:      int(itemCounts[schedule.ScheduleID]),
+			ChangeLogCount: logCount,
+			RestoredFromID: schedule.RestoredFromID,
+			CreatedAt:      schedule.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		}
+		if schedule.PublishedAt != nil {
+			t := schedule.PublishedAt.Format("2006-01-02T15:04:05Z")
+			version.PublishedAt = &t
+		}
+		result = append(result, version)
+	}
+	return result, nil
+}
+
+// ════════════════════════════════════════════════════════════
+// DiffSchedules — 版本差异
+// ════════════════════════════════════════════════════════════
+
+func (s *scheduleService) DiffSchedules(ctx context.Context, req *dto.DiffSchedulesRequest) (*dto.ScheduleDiffResponse, error) {
+	base, err := s.repo.Schedule.GetByID(ctx, req.BaseID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrScheduleNotFound
+		}
+		s.logger.Error("查询排班表失败", zap.Error(err))
+		return nil, err
+	}
+	target, err := s.repo.Schedule.GetByID(ctx, req.TargetID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrScheduleNotFound
+		}
+		s.logger.Error("查询排班表失败", zap.Error(err))
+		return nil, err
+	}
+	if base.SemesterID != target.SemesterID {
+		return nil, ErrScheduleSemesterMismatch
+	}
+
+	baseItems, err := s.repo.ScheduleItem.ListBySchedule(ctx, base.ScheduleID)
+	if err != nil {
+		s.logger.Error("查询排班项失败", zap.Error(err))
+		return nil, err
+	}
+	targetItems, err := s.repo.ScheduleItem.ListBySchedule(ctx, target.ScheduleID)
+	if err != nil {
+		s.logger.Error("查询排班项失败", zap.Error(err))
+		return nil, err
+	}
+
+	diff := diffScheduleItems(baseItems, targetItems)
+	diff.BaseID = base.ScheduleID
+	diff.TargetID = target.ScheduleID
+	return diff, nil
+}
+
+// diffScheduleItems 按槽位（周次 + 时间段）对比两组排班项。
+// 同一槽位内两版本共有的成员视为未变；其余成员依次配对为"改派"，
+// 配对不上的分别计为"新增"或"移除"。
+func diffScheduleItems(baseItems, targetItems []model.ScheduleItem) *dto.ScheduleDiffResponse {
+	type slotItems struct {
+		weekNumber int
+		timeSlot   *model.TimeSlot
+		base       []*model.ScheduleItem
+		target     []*model.ScheduleItem
+	}
+
+	slots := make(map[string]*slotItems)
+	var order []string
+	slotOf := func(item *model.ScheduleItem) *slotItems {
+		key := slotKey(item.WeekNumber, item.TimeSlotID)
+		si, ok := slots[key]
+		if !ok {
+			si = &slotItems{weekNumber: item.WeekNumber}
+			slots[key] = si
+			order = append(order, key)
+		}
+		if si.timeSlot == nil {
+			si.timeSlot = item.TimeSlot
+		}
+		return si
+	}
+	for i := range baseItems {
+		si := slotOf(&baseItems[i])
+		si.base = append(si.base, &baseItems[i])
+	}
+	for i := range targetItems {
+		si := slotOf(&targetItems[i])
+		si.target = append(si.target, &targetItems[i])
+	}
+
+	// 成员维度统计
+	members := make(map[string]*dto.ScheduleMemberDiff)
+	memberOf := func(item *model.ScheduleItem) *dto.ScheduleMemberDiff {
+		md, ok := members[item.MemberID]
+		if !ok {
+			md = &dto.ScheduleMemberDiff{Member: dto.MemberBrief{ID: item.MemberID}}
+			members[item.MemberID] = md
+		}
+		if md.Member.Name == "" && item.Member != nil {
+			md.Member = *toMemberBrief(item.Member)
+		}
+		return md
+	}
+	for i := range baseItems {
+		memberOf(&baseItems[i]).BaseShifts++
+	}
+	for i := range targetItems {
+		memberOf(&targetItems[i]).TargetShifts++
+	}
+
+	resp := &dto.ScheduleDiffResponse{
+		Added:      make([]dto.ScheduleDiffItem, 0),
+		Removed:    make([]dto.ScheduleDiffItem, 0),
+		Reassigned: make([]dto.ScheduleDiffItem, 0),
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := slots[order[i]], slots[order[j]]
+		if a.weekNumber != b.weekNumber {
+			return a.weekNumber < b.weekNumber
+		}
+		if a.timeSlot != nil && b.timeSlot != nil {
+			if a.timeSlot.DayOfWeek != b.timeSlot.DayOfWeek {
+				return a.timeSlot.DayOfWeek < b.timeSlot.DayOfWeek
+			}
+			if a.timeSlot.StartTime != b.timeSlot.StartTime {
+				return a.timeSlot.StartTime < b.timeSlot.StartTime
+			}
+		}
+		return order[i] < order[j]
+	})
+
+	for _, key := range order {
+		si := slots[key]
+
+		targetMembers := make(map[string]bool, len(si.target))
+		for _, it := range si.target {
+			targetMembers[it.MemberID] = true
+		}
+		baseMembers := make(map[string]bool, len(si.base))
+		for _, it := range si.base {
+			baseMembers[it.MemberID] = true
+		}
+
+		var removed, added []*model.ScheduleItem
+		for _, it := range si.base {
+			if !targetMembers[it.MemberID] {
+				removed = append(removed, it)
+			}
+		}
+		for _, it := range si.target {
+			if !baseMembers[it.MemberID] {
+				added = append(added, it)
+			}
+		}
+
+		newDiffItem := func() dto.ScheduleDiffItem {
+			d := dto.ScheduleDiffItem{WeekNumber: si.weekNumber}
+			if si.timeSlot != nil {
+				d.TimeSlot = toTimeSlotBrief(si.timeSlot)
+			}
+			return d
+		}
+
+		for len(removed) > 0 && len(added) > 0 {
+			d := newDiffItem()
+			d.BaseItemID = removed[0].ScheduleItemID
+			d.BaseMember = toMemberBrief(removed[0].Member)
+			d.TargetItemID = added[0].ScheduleItemID
+			d.TargetMember = toMemberBrief(added[0].Member)
+			resp.Reassigned = append(resp.Reassigned, d)
+			memberOf(removed[0]).Lost++
+			memberOf(added[0]).Gained++
+			removed, added = removed[1:], added[1:]
+		}
+		for _, it := range removed {
+			d := newDiffItem()
+			d.BaseItemID = it.ScheduleItemID
+			d.BaseMember = toMemberBrief(it.Member)
+			resp.Removed = append(resp.Removed, d)
+			memberOf(it).Lost++
+		}
+		for _, it := range added {
+			d := newDiffItem()
+			d.TargetItemID = it.ScheduleItemID
+			d.TargetMember = toMemberBrief(it.Member)
+			resp.Added = append(resp.Added, d)
+			memberOf(it).Gained++
+		}
+	}
+
+	// 仅返回有变化的成员
+	resp.Members = make([]dto.ScheduleMemberDiff, 0)
+	for _, md := range members {
+		if md.Gained > 0 || md.Lost > 0 {
+			resp.Members = append(resp.Members, *md)
+		}
+	}
+	sort.Slice(resp.Members, func(i, j int) bool {
+		if resp.Members[i].Member.Name != resp.Members[j].Member.Name {
+			return resp.Members[i].Member.Name < resp.Members[j].Member.Name
+		}
+		return resp.Members[i].Member.ID < resp.Members[j].Member.ID
+	})
+
+	return resp
+}
+
+// ════════════════════════════════════════════════════════════
+// RestoreVersion — 归档版本恢复为草稿
+// ════════════════════════════════════════════════════════════
+
+func (s *scheduleService) RestoreVersion(ctx context.Context, scheduleID, callerID string) (*dto.ScheduleResponse, error) {
+	source, err := s.repo.Schedule.GetByID(ctx, scheduleID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrScheduleNotFound
+		}
+		s.logger.Error("查询排班表失败", zap.Error(err))
+		return nil, err
+	}
+	if source.Status != model.ScheduleStatusArchived {
+		return nil, ErrScheduleNotArchived
+	}
+
+	semester := source.Semester
+	if semester == nil {
+		semester, err = s.repo.Semester.GetByID(ctx, source.SemesterID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrSemesterNotFound
+			}
+			s.logger.Error("查询学期失败", zap.Error(err))
+			return nil, err
+		}
+	}
+	if semester.Phase != model.SemesterPhaseScheduling {
+		return nil, ErrPhaseNotScheduling
+	}
+
+	// 与自动排班共用学期锁，避免恢复与排班并发写入活跃排班表
+	unlock, ok, err := s.locker.tryLock(ctx, autoScheduleLockKey(source.SemesterID), autoScheduleLockTTL)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrAutoScheduleRunning
+	}
+	defer unlock()
+
+	existing, err := s.repo.Schedule.GetBySemester(ctx, source.SemesterID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		s.logger.Error("查询已有排班表失败", zap.Error(err))
+		return nil, err
+	}
+	if existing != nil && existing.Status == model.ScheduleStatusPublished {
+		return nil, ErrSchedulePublishedExists
+	}
+
+	sourceItems, err := s.repo.ScheduleItem.ListBySchedule(ctx, source.ScheduleID)
+	if err != nil {
+		s.logger.Error("查询排班项失败", zap.Error(err))
+		return nil, err
+	}
+	sourceSnapshots, err := s.repo.ScheduleMemberSnapshot.ListBySchedule(ctx, source.ScheduleID)
+	if err != nil {
+		s.logger.Error("查询成员快照失败", zap.Error(err))
+		return nil, err
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		s.logger.Error("开启事务失败", zap.Error(err))
+		return nil, err
+	}
+	rollbackTx := func() {
+		if tx != nil {
+			tx.Rollback()
+		}
+	}
+	txRepo := s.repo.WithTx(tx)
+
+	// 当前草稿 / need_regen 归档，成为新的历史版本
+	if existing != nil {
+		existing.Status = model.ScheduleStatusArchived
+		existing.UpdatedBy = &callerID
+		if err := txRepo.Schedule.Update(ctx, existing); err != nil {
+			rollbackTx()
+			s.logger.Error("归档旧排班表失败", zap.Error(err))
+			return nil, err
+		}
+	}
+
+	schedule := &model.Schedule{
+		SemesterID:     source.SemesterID,
+		Status:         model.ScheduleStatusDraft,
+		Name:           source.Name,
+		Solver:         source.Solver,
+		Seed:           source.Seed,
+		RuleOverrides:  source.RuleOverrides,
+		RestoredFromID: &source.ScheduleID,
+	}
+	schedule.CreatedBy = &callerID
+	schedule.UpdatedBy = &callerID
+	if err := txRepo.Schedule.Create(ctx, schedule); err != nil {
+		rollbackTx()
+		s.logger.Error("创建排班表失败", zap.Error(err))
+		return nil, err
+	}
+
+	items := make([]model.ScheduleItem, 0, len(sourceItems))
+	for _, src := range sourceItems {
+		item := model.ScheduleItem{
+			ScheduleID: schedule.ScheduleID,
+			WeekNumber: src.WeekNumber,
+			TimeSlotID: src.TimeSlotID,
+			MemberID:   src.MemberID,
+			LocationID: src.LocationID,
+		}
+		item.CreatedBy = &callerID
+		item.UpdatedBy = &callerID
+		items = append(items, item)
+	}
+	if err := txRepo.ScheduleItem.BatchCreate(ctx, items); err != nil {
+		rollbackTx()
+		s.logger.Error("批量创建排班项失败", zap.Error(err))
+		return nil, err
+	}
+
+	// 沿用原版本的成员快照，范围检测以恢复版本的成员范围为基准
+	now := time.Now()
+	snapshots := make([]model.ScheduleMemberSnapshot, 0, len(sourceSnapshots))
+	for _, src := range sourceSnapshots {
+		snapshots = append(snapshots, model.ScheduleMemberSnapshot{
+			ScheduleID:   schedule.ScheduleID,
+			UserID:       src.UserID,
+			DepartmentID: src.DepartmentID,
+			SnapshotAt:   src.SnapshotAt,
+			CreatedAt:    now,
+		})
+	}
+	if err := txRepo.ScheduleMemberSnapshot.BatchCreate(ctx, snapshots); err != nil {
+		rollbackTx()
+		s.logger.Error("保存成员快照失败", zap.Error(err))
+		return nil, err
+	}
+
+	if tx != nil {
+		if err := tx.Commit().Error; err != nil {
+			s.logger.Error("提交事务失败", zap.Error(err))
+			return nil, err
+		}
+	}
+
+	s.logger.Info("归档排班版本已恢复为草稿",
+		zap.String("source_id", source.ScheduleID),
+		zap.String("schedule_id", schedule.ScheduleID),
+		zap.String("caller_id", callerID),
+	)
+
+	schedule.Semester = semester
+	return s.buildScheduleResponse(ctx, schedule)
+}
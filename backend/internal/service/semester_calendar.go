@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"echo-union/backend/internal/model"
+	"echo-union/backend/internal/repository"
+)
+
+// ── 学期校历 ──
+//
+// 排班表按"两周一循环"的模板编排（week_number 1|2 × TimeSlot.DayOfWeek），
+// 落到具体日期时需经过校历换算：
+//   - 教学周次以学期开始日所在周的周一为第 1 周起点
+//   - 循环周 = (教学周 - 1) % 2 + 1，单双周类型由 Semester.FirstWeekType 决定
+//   - holiday 当天不值班；workday（调休上班）按指定星期 / 教学周执行
+
+// calendarDay 校历换算后的单日信息
+type calendarDay struct {
+	date         time.Time
+	dutyDay      bool   // 当天是否值班
+	dayOfWeek    int    // 实际执行的星期（调休日为替代星期）
+	teachingWeek int    // 实际执行的教学周次
+	cycleWeek    int    // 1 | 2，对应 ScheduleItem.WeekNumber
+	weekType     string // odd | even
+	kind         string // 空 | holiday | workday
+	name         string
+}
+
+// semesterCalendar 学期校历（学期基础信息 + 特殊日期）
+type semesterCalendar struct {
+	semester  *model.Semester
+	firstDay  time.Time // 第 1 教学周的周一
+	startDate time.Time
+	endDate   time.Time
+	special   map[string]model.SemesterCalendarDay // "2006-01-02" → 特殊日期
+}
+
+// dateOnly 截取日期部分（统一到 UTC 零点，避免时区导致跨日）
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func newSemesterCalendar(semester *model.Semester, days []model.SemesterCalendarDay) *semesterCalendar {
+	start := dateOnly(semester.StartDate)
+	cal := &semesterCalendar{
+		semester:  semester,
+		firstDay:  start.AddDate(0, 0, 1-goWeekdayToISO(start.Weekday())),
+		startDate: start,
+		endDate:   dateOnly(semester.EndDate),
+		special:   make(map[string]model.SemesterCalendarDay, len(days)),
+	}
+	for _, d := range days {
+		cal.special[dateOnly(d.Date).Format(model.TimeFormatDate)] = d
+	}
+	return cal
+}
+
+// loadSemesterCalendar 加载学期校历
+func loadSemesterCalendar(ctx context.Context, repo *repository.Repository, semester *model.Semester) (*semesterCalendar, error) {
+	days, err := repo.SemesterCalendar.ListBySemester(ctx, semester.SemesterID)
+	if err != nil {
+		return nil, err
+	}
+	return newSemesterCalendar(semester, days), nil
+}
+
+// contains 日期是否在学期范围内
+func (c *semesterCalendar) contains(date time.Time) bool {
+	d := dateOnly(date)
+	return !d.Before(c.startDate) && !d.After(c.endDate)
+}
+
+// teachingWeekOf 日期的自然教学周次（学期开始前返回 0）
+func (c *semesterCalendar) teachingWeekOf(date time.Time) int {
+	days := int(dateOnly(date).Sub(c.firstDay).Hours() / 24)
+	if days < 0 {
+		return 0
+	}
+	return days/7 + 1
+}
+
+// totalWeeks 学期教学周总数
+func (c *semesterCalendar) totalWeeks() int {
+	return c.teachingWeekOf(c.endDate)
+}
+
+// resolve 换算单日
+func (c *semesterCalendar) resolve(date time.Time) calendarDay {
+	d := dateOnly(date)
+	day := calendarDay{
+		date:         d,
+		dutyDay:      c.contains(d),
+		dayOfWeek:    goWeekdayToISO(d.Weekday()),
+		teachingWeek: c.teachingWeekOf(d),
+	}
+
+	if special, ok := c.special[d.Format(model.TimeFormatDate)]; ok {
+		day.kind = special.Kind
+		day.name = special.Name
+		switch special.Kind {
+		case model.CalendarDayHoliday:
+			day.dutyDay = false
+		case model.CalendarDayWorkday:
+			if special.RunsAsDayOfWeek != nil {
+				day.dayOfWeek = *special.RunsAsDayOfWeek
+			}
+			if special.RunsAsWeek != nil {
+				day.teachingWeek = *special.RunsAsWeek
+			}
+		}
+	}
+
+	if day.teachingWeek > 0 {
+		day.cycleWeek = (day.teachingWeek-1)%2 + 1
+		day.weekType = weekNumberToType(day.cycleWeek, c.semester.FirstWeekType)
+	}
+	return day
+}
+
+// days 换算 [from, to] 区间内（裁剪到学期范围）的每一天
+func (c *semesterCalendar) days(from, to time.Time) []calendarDay {
+	from, to = dateOnly(from), dateOnly(to)
+	if from.Before(c.startDate) {
+		from = c.startDate
+	}
+	if to.After(c.endDate) {
+		to = c.endDate
+	}
+	var result []calendarDay
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		result = append(result, c.resolve(d))
+	}
+	return result
+}
+
+// ════════════════════════════════════════════════════════════
+// 值班记录生成
+// ════════════════════════════════════════════════════════════
+
+// syncDutyRecords 按校历为已发布排班表同步 from（含）之后的值班记录。
+// 只增删日期或时段发生变化的记录："排班项 + 日期"在校历中仍是值班日的记录原样保留
+// （含转让 / 换班 / 请假改派后的值班人、网段豁免与紧急替班记录）；不再对应值班日的
+// 尚未开始（pending）记录删除，已签到/已结束的记录保持不变；缺少记录的值班日按排班项新建，
+// 与成员临时不可用冲突的新记录直接标记为需替班。
+// 返回新生成的记录（已预置 ScheduleItem.TimeSlot）。
+func syncDutyRecords(ctx context.Context, repo *repository.Repository, schedule *model.Schedule, cal *semesterCalendar, from time.Time, operatorID string) ([]model.DutyRecord, error) {
+	from = dateOnly(from)
+
+	items, err := repo.ScheduleItem.ListBySchedule(ctx, schedule.ScheduleID)
+	if err != nil {
+		return nil, err
+	}
+	timeSlots, err := repo.TimeSlot.List(ctx, schedule.SemesterID, nil)
+	if err != nil {
+		return nil, err
+	}
+	slotByID := make(map[string]*model.TimeSlot, len(timeSlots))
+	for i := range timeSlots {
+		slotByID[timeSlots[i].TimeSlotID] = &timeSlots[i]
+	}
+
+	// 成员 → 临时不可用
+	unavailables, err := repo.UnavailableTime.ListBySemester(ctx, schedule.SemesterID)
+	if err != nil {
+		return nil, err
+	}
+	onceOffs := make(map[string][]model.UnavailableTime)
+	for _, ut := range unavailables {
+		if isOnceOff(ut) {
+			onceOffs[ut.UserID] = append(onceOffs[ut.UserID], ut)
+		}
+	}
+
+	// "循环周:星期" → 排班项（TimeSlot 已回填）
+	byDay := make(map[[2]int][]model.ScheduleItem)
+	for _, item := range items {
+		if ts, ok := slotByID[item.TimeSlotID]; ok {
+			item.TimeSlot = ts
+		}
+		if item.TimeSlot == nil {
+			continue // 时间段已停用
+		}
+		key := [2]int{item.WeekNumber, item.TimeSlot.DayOfWeek}
+		byDay[key] = append(byDay[key], item)
+	}
+
+	// 按校历应有的值班："排班项:日期"
+	type plannedDuty struct {
+		day  calendarDay
+		item model.ScheduleItem
+	}
+	var planned []plannedDuty
+	wanted := make(map[string]bool)
+	for _, day := range cal.days(from, cal.endDate) {
+		if !day.dutyDay || day.cycleWeek == 0 {
+			continue
+		}
+		for _, item := range byDay[[2]int{day.cycleWeek, day.dayOfWeek}] {
+			planned = append(planned, plannedDuty{day: day, item: item})
+			wanted[item.ScheduleItemID+":"+day.date.Format(model.TimeFormatDate)] = true
+		}
+	}
+
+	// 现有记录：仍对应值班日的保留，不再对应的 pending 记录删除
+	existing, err := repo.DutyRecord.ListByScheduleFrom(ctx, schedule.ScheduleID, from)
+	if err != nil {
+		return nil, err
+	}
+	exists := make(map[string]bool, len(existing))
+	var stale []string
+	for _, r := range existing {
+		if r.ScheduleItemID == nil {
+			continue
+		}
+		key := *r.ScheduleItemID + ":" + dateOnly(r.DutyDate).Format(model.TimeFormatDate)
+		if !wanted[key] && r.Status == model.DutyRecordStatusPending {
+			stale = append(stale, r.DutyRecordID)
+			continue
+		}
+		exists[key] = true
+	}
+	if err := repo.DutyRecord.DeletePendingByIDs(ctx, stale, operatorID); err != nil {
+		return nil, err
+	}
+
+	var records []model.DutyRecord
+	for _, p := range planned {
+		item := p.item
+		if exists[item.ScheduleItemID+":"+p.day.date.Format(model.TimeFormatDate)] {
+			continue
+		}
+		itemID := item.ScheduleItemID
+		record := model.DutyRecord{
+			ScheduleItemID: &itemID,
+			MemberID:       item.MemberID,
+			DutyDate:       p.day.date,
+			Status:         model.DutyRecordStatusPending,
+		}
+		if ut := onceOffConflict(onceOffs[item.MemberID], p.day.date, item.TimeSlot); ut != nil {
+			record.NeedsSubstitute = true
+			record.SubstituteReason = substituteReason(ut)
+		}
+		record.CreatedBy = &operatorID
+		record.UpdatedBy = &operatorID
+		records = append(records, record)
+	}
+
+	if err := repo.DutyRecord.BatchCreate(ctx, records); err != nil {
+		return nil, err
+	}
+
+	// 回填关联，便于调用方生成通知（BatchCreate 之后，避免 GORM 级联写入关联）
+	itemByID := make(map[string]*model.ScheduleItem, len(items))
+	for _, list := range byDay {
+		for i := range list {
+			itemByID[list[i].ScheduleItemID] = &list[i]
+		}
+	}
+	for i := range records {
+		records[i].ScheduleItem = itemByID[*records[i].ScheduleItemID]
+	}
+	return records, nil
+}
+
+// needingSubstitute 筛选需替班的记录
+func needingSubstitute(records []model.DutyRecord) []model.DutyRecord {
+	var result []model.DutyRecord
+	for _, r := range records {
+		if r.NeedsSubstitute {
+			result = append(result, r)
+		}
+	}
+	return result
+}
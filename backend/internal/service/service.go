@@ -11,17 +11,26 @@ import (
 
 // Service 所有 Service 的聚合入口
 type Service struct {
-	Auth         AuthService
-	User         UserService
-	Department   DepartmentService
-	Semester     SemesterService
-	TimeSlot     TimeSlotService
-	Location     LocationService
-	SystemConfig SystemConfigService
-	ScheduleRule ScheduleRuleService
-	Schedule     ScheduleService
-	Timetable    TimetableService
-	Export       ExportService
+	Auth           AuthService
+	User           UserService
+	Department     DepartmentService
+	Semester       SemesterService
+	Calendar       CalendarService
+	TimeSlot       TimeSlotService
+	Location       LocationService
+	SystemConfig   SystemConfigService
+	ScheduleRule   ScheduleRuleService
+	Schedule       ScheduleService
+	Timetable      TimetableService
+	Export         ExportService
+	Notification   NotificationService
+	Event          EventService
+	PairConstraint PairConstraintService
+	Skill          SkillService
+	Swap           SwapService
+	Leave          LeaveService
+	Duty           DutyService
+	Statistics     StatisticsService
 }
 
 // NewService 创建 Service 聚合
@@ -33,16 +42,25 @@ func NewService(
 	logger *zap.Logger,
 ) *Service {
 	return &Service{
-		Auth:         NewAuthService(cfg, repo, jwtMgr, rdb, logger),
-		User:         NewUserService(repo, logger),
-		Department:   NewDepartmentService(repo, logger),
-		Semester:     NewSemesterService(repo, logger),
-		TimeSlot:     NewTimeSlotService(repo, logger),
-		Location:     NewLocationService(repo, logger),
-		SystemConfig: NewSystemConfigService(repo, logger),
-		ScheduleRule: NewScheduleRuleService(repo, logger),
-		Schedule:     NewScheduleService(repo, logger),
-		Timetable:    NewTimetableService(repo, logger),
-		Export:       NewExportService(repo, logger),
+		Auth:           NewAuthService(cfg, repo, jwtMgr, rdb, logger),
+		User:           NewUserService(repo, logger),
+		Department:     NewDepartmentService(repo, logger),
+		Semester:       NewSemesterService(repo, logger),
+		Calendar:       NewCalendarService(repo, logger),
+		TimeSlot:       NewTimeSlotService(repo, logger),
+		Location:       NewLocationService(repo, logger),
+		SystemConfig:   NewSystemConfigService(repo, logger),
+		ScheduleRule:   NewScheduleRuleService(repo, logger),
+		Schedule:       NewScheduleService(repo, rdb, logger),
+		Timetable:      NewTimetableService(repo, logger),
+		Export:         NewExportService(repo, logger),
+		Notification:   NewNotificationService(repo, logger),
+		Event:          NewEventService(repo, logger),
+		PairConstraint: NewPairConstraintService(repo, logger),
+		Skill:          NewSkillService(repo, logger),
+		Swap:           NewSwapService(repo, logger),
+		Leave:          NewLeaveService(repo, logger),
+		Duty:           NewDutyService(cfg, repo, rdb, logger),
+		Statistics:     NewStatisticsService(repo, logger),
 	}
 }
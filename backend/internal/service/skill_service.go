@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/model"
+	"echo-union/backend/internal/repository"
+)
+
+// ── 技能模块业务错误 ──
+
+var (
+	ErrSkillNotFound   = errors.New("技能不存在")
+	ErrSkillNameExists = errors.New("技能名称已存在")
+)
+
+// SkillService 技能目录业务接口
+type SkillService interface {
+	List(ctx context.Context) ([]dto.SkillResponse, error)
+	Create(ctx context.Context, req *dto.CreateSkillRequest, callerID string) (*dto.SkillResponse, error)
+	Update(ctx context.Context, id string, req *dto.UpdateSkillRequest, callerID string) (*dto.SkillResponse, error)
+	Delete(ctx context.Context, id string, callerID string) error
+}
+
+type skillService struct {
+	repo   *repository.Repository
+	logger *zap.Logger
+}
+
+// NewSkillService 创建 SkillService 实例
+func NewSkillService(repo *repository.Repository, logger *zap.Logger) SkillService {
+	return &skillService{repo: repo, logger: logger}
+}
+
+// ────────────────────── List ──────────────────────
+
+func (s *skillService) List(ctx context.Context) ([]dto.SkillResponse, error) {
+	skills, err := s.repo.Skill.List(ctx)
+	if err != nil {
+		s.logger.Error("列出技能失败", zap.Error(err))
+		return nil, err
+	}
+
+	result := make([]dto.SkillResponse, 0, len(skills))
+	for i := range skills {
+		result = append(result, *toSkillResponse(&skills[i]))
+	}
+	return result, nil
+}
+
+// ────────────────────── Create ──────────────────────
+
+func (s *skillService) Create(ctx context.Context, req *dto.CreateSkillRequest, callerID string) (*dto.SkillResponse, error) {
+	if err := s.checkNameUnique(ctx, req.Name, ""); err != nil {
+		return nil, err
+	}
+
+	skill := &model.Skill{
+		Name:        req.Name,
+		Description: req.Description,
+	}
+	skill.CreatedBy = &callerID
+	skill.UpdatedBy = &callerID
+
+	if err := s.repo.Skill.Create(ctx, skill); err != nil {
+		s.logger.Error("创建技能失败", zap.Error(err))
+		return nil, err
+	}
+	return toSkillResponse(skill), nil
+}
+
+// ────────────────────── Update ──────────────────────
+
+func (s *skillService) Update(ctx context.Context, id string, req *dto.UpdateSkillRequest, callerID string) (*dto.SkillResponse, error) {
+	skill, err := s.getSkill(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil && *req.Name != skill.Name {
+		if err := s.checkNameUnique(ctx, *req.Name, id); err != nil {
+			return nil, err
+		}
+		skill.Name = *req.Name
+	}
+	if req.Description != nil {
+		skill.Description = *req.Description
+	}
+	skill.UpdatedBy = &callerID
+
+	if err := s.repo.Skill.Update(ctx, skill); err != nil {
+		s.logger.Error("更新技能失败", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+	return toSkillResponse(skill), nil
+}
+
+// ────────────────────── Delete ──────────────────────
+
+// Delete 删除技能，成员技能与时间段 / 活动班次的技能要求一并移除
+func (s *skillService) Delete(ctx context.Context, id string, callerID string) error {
+	if _, err := s.getSkill(ctx, id); err != nil {
+		return err
+	}
+	if err := s.repo.Skill.Delete(ctx, id, callerID); err != nil {
+		s.logger.Error("删除技能失败", zap.String("id", id), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// ── 内部方法 ──
+
+func (s *skillService) getSkill(ctx context.Context, id string) (*model.Skill, error) {
+	skill, err := s.repo.Skill.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSkillNotFound
+		}
+		s.logger.Error("查询技能失败", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+	return skill, nil
+}
+
+// checkNameUnique 技能名称唯一（excludeID 为更新时的自身 ID）
+func (s *skillService) checkNameUnique(ctx context.Context, name, excludeID string) error {
+	existing, err := s.repo.Skill.GetByName(ctx, name)
+	if err == nil && existing.SkillID != excludeID {
+		return ErrSkillNameExists
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
+
+func toSkillResponse(skill *model.Skill) *dto.SkillResponse {
+	return &dto.SkillResponse{
+		ID:          skill.SkillID,
+		Name:        skill.Name,
+		Description: skill.Description,
+		CreatedAt:   skill.CreatedAt.Format(model.TimeFormatDateTime),
+		UpdatedAt:   skill.UpdatedAt.Format(model.TimeFormatDateTime),
+	}
+}
+
+// ── 技能关联（成员 / 时间段 / 活动班次共用） ──
+
+// resolveSkills 校验技能 ID 全部存在并去重，返回按名称排序的技能
+func resolveSkills(ctx context.Context, repo *repository.Repository, ids []string) ([]model.Skill, error) {
+	seen := make(map[string]bool, len(ids))
+	unique := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	if len(unique) == 0 {
+		return nil, nil
+	}
+	skills, err := repo.Skill.ListByIDs(ctx, unique)
+	if err != nil {
+		return nil, err
+	}
+	if len(skills) != len(unique) {
+		return nil, ErrSkillNotFound
+	}
+	return skills, nil
+}
+
+// userSkills 成员已具备的技能（需预加载 Skills.Skill）
+func userSkills(user *model.User) []model.Skill {
+	skills := make([]model.Skill, 0, len(user.Skills))
+	for _, us := range user.Skills {
+		if us.Skill != nil {
+			skills = append(skills, *us.Skill)
+		}
+	}
+	return skills
+}
+
+// timeSlotSkills 时间段所需技能（需预加载 RequiredSkills.Skill）
+func timeSlotSkills(ts *model.TimeSlot) []model.Skill {
+	skills := make([]model.Skill, 0, len(ts.RequiredSkills))
+	for _, req := range ts.RequiredSkills {
+		if req.Skill != nil {
+			skills = append(skills, *req.Skill)
+		}
+	}
+	return skills
+}
+
+// eventShiftSkills 活动班次所需技能（需预加载 RequiredSkills.Skill）
+func eventShiftSkills(shift *model.EventShift) []model.Skill {
+	skills := make([]model.Skill, 0, len(shift.RequiredSkills))
+	for _, req := range shift.RequiredSkills {
+		if req.Skill != nil {
+			skills = append(skills, *req.Skill)
+		}
+	}
+	return skills
+}
+
+func toSkillBriefs(skills []model.Skill) []dto.SkillBrief {
+	result := make([]dto.SkillBrief, 0, len(skills))
+	for _, skill := range skills {
+		result = append(result, dto.SkillBrief{ID: skill.SkillID, Name: skill.Name})
+	}
+	return result
+}
+
+// skillNames 技能名称列表，用于导出与冲突说明
+func skillNames(skills []model.Skill) []string {
+	names := make([]string, 0, len(skills))
+	for _, skill := range skills {
+		names = append(names, skill.Name)
+	}
+	return names
+}
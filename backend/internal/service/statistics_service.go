@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/model"
+	"echo-union/backend/internal/repository"
+)
+
+// ── 统计模块业务错误 ──
+
+var (
+	ErrStatsRangeInvalid = errors.New("统计区间无效：from 与 to 须同时提供且 from 不晚于 to")
+	ErrStatsNoSemester   = errors.New("当前无进行中的学期，请指定 semester_id 或统计区间")
+)
+
+// StatisticsService 统计业务接口
+//
+// 设计说明：
+//   - 出勤统计以值班记录为准，覆盖周常排班与活动班次
+//   - 统计区间的结束日期不晚于今天，尚未到来的值班不计入应值班次数
+//   - 紧急替班生成的记录计入接班成员，原记录仍按缺勤计入原成员
+type StatisticsService interface {
+	// Attendance 成员与部门出勤统计（leader 仅本部门）
+	Attendance(ctx context.Context, req *dto.AttendanceStatsRequest, callerRole, callerDeptID string) (*dto.AttendanceStatsResponse, error)
+}
+
+type statisticsService struct {
+	repo   *repository.Repository
+	logger *zap.Logger
+}
+
+// NewStatisticsService 创建 StatisticsService 实例
+func NewStatisticsService(repo *repository.Repository, logger *zap.Logger) StatisticsService {
+	return &statisticsService{repo: repo, logger: logger}
+}
+
+// ═══════════════════════════════════════════════════════════
+// Attendance — 出勤统计
+// ═══════════════════════════════════════════════════════════
+//
+// 计数口径（按值班记录状态）：
+//   - completed：正常签退；no_sign_out：签到未签退；absent：缺勤；absent_made_up：缺勤已补班
+//   - pending / on_duty：尚未签到或值班中（未被标记缺勤的过期记录也在此列）
+//   - late：签到迟到（与状态独立计数）；substitute：其中由紧急替班接手的记录
+//   - hours：completed 记录的计划时长合计
+//
+// 成员按部门、学号排序；部门汇总按部门名称排序
+
+func (s *statisticsService) Attendance(ctx context.Context, req *dto.AttendanceStatsRequest, callerRole, callerDeptID string) (*dto.AttendanceStatsResponse, error) {
+	from, to, err := s.attendanceRange(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	deptID := req.DepartmentID
+	if callerRole == model.RoleLeader {
+		deptID = callerDeptID
+	}
+
+	counts, err := s.repo.DutyRecord.CountAttendance(ctx, from, to, deptID)
+	if err != nil {
+		s.logger.Error("统计出勤失败", zap.Error(err))
+		return nil, err
+	}
+
+	stats := make(map[string]*dto.AttendanceCounts)
+	var memberIDs []string
+	for _, c := range counts {
+		st := stats[c.MemberID]
+		if st == nil {
+			st = &dto.AttendanceCounts{}
+			stats[c.MemberID] = st
+			memberIDs = append(memberIDs, c.MemberID)
+		}
+		addAttendanceCount(st, c)
+	}
+
+	result := &dto.AttendanceStatsResponse{
+		From:        from.Format(model.TimeFormatDate),
+		To:          to.Format(model.TimeFormatDate),
+		Departments: []dto.DepartmentAttendanceResponse{},
+		Members:     []dto.MemberAttendanceResponse{},
+	}
+	if len(memberIDs) == 0 {
+		return result, nil
+	}
+
+	users, err := s.repo.User.ListByIDs(ctx, memberIDs)
+	if err != nil {
+		s.logger.Error("查询成员失败", zap.Error(err))
+		return nil, err
+	}
+	briefs := make(map[string]*dto.MemberBrief, len(users))
+	for i := range users {
+		briefs[users[i].UserID] = toMemberBrief(&users[i])
+	}
+
+	departments := make(map[string]*dto.DepartmentAttendanceResponse)
+	for _, id := range memberIDs {
+		st := stats[id]
+		member := briefs[id]
+		if member == nil {
+			member = &dto.MemberBrief{ID: id}
+		}
+		result.Members = append(result.Members, dto.MemberAttendanceResponse{Member: member, AttendanceCounts: *st})
+		mergeAttendanceCounts(&result.Total, st)
+
+		if member.Department == nil {
+			continue
+		}
+		dept := departments[member.Department.ID]
+		if dept == nil {
+			dept = &dto.DepartmentAttendanceResponse{Department: member.Department}
+			departments[member.Department.ID] = dept
+		}
+		dept.MemberCount++
+		mergeAttendanceCounts(&dept.AttendanceCounts, st)
+	}
+
+	for _, dept := range departments {
+		dept.Hours = roundHours(dept.Hours)
+		result.Departments = append(result.Departments, *dept)
+	}
+	sort.Slice(result.Departments, func(i, j int) bool {
+		return result.Departments[i].Department.Name < result.Departments[j].Department.Name
+	})
+	for i := range result.Members {
+		result.Members[i].Hours = roundHours(result.Members[i].Hours)
+	}
+	sort.SliceStable(result.Members, func(i, j int) bool {
+		a, b := result.Members[i].Member, result.Members[j].Member
+		if an, bn := memberDepartmentName(a), memberDepartmentName(b); an != bn {
+			return an < bn
+		}
+		return a.StudentID < b.StudentID
+	})
+	result.Total.Hours = roundHours(result.Total.Hours)
+	return result, nil
+}
+
+// ── 内部辅助方法 ──
+
+// attendanceRange 解析统计区间：显式 from / to 优先，其次学期起止日期；结束日期不晚于今天
+func (s *statisticsService) attendanceRange(ctx context.Context, req *dto.AttendanceStatsRequest) (time.Time, time.Time, error) {
+	var from, to time.Time
+	switch {
+	case req.From != "" || req.To != "":
+		if req.From == "" || req.To == "" || req.From > req.To {
+			return from, to, ErrStatsRangeInvalid
+		}
+		from, _ = time.Parse(model.TimeFormatDate, req.From)
+		to, _ = time.Parse(model.TimeFormatDate, req.To)
+	default:
+		var semester *model.Semester
+		var err error
+		if req.SemesterID != "" {
+			semester, err = s.repo.Semester.GetByID(ctx, req.SemesterID)
+		} else {
+			semester, err = s.repo.Semester.GetCurrent(ctx)
+		}
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				if req.SemesterID != "" {
+					return from, to, ErrSemesterNotFound
+				}
+				return from, to, ErrStatsNoSemester
+			}
+			s.logger.Error("查询学期失败", zap.Error(err))
+			return from, to, err
+		}
+		from, to = dateOnly(semester.StartDate), dateOnly(semester.EndDate)
+	}
+
+	if today := dateOnly(time.Now()); to.After(today) {
+		to = today
+	}
+	return from, to, nil
+}
+
+func addAttendanceCount(st *dto.AttendanceCounts, c repository.AttendanceCount) {
+	st.Scheduled += c.Count
+	switch c.Status {
+	case model.DutyRecordStatusCompleted:
+		st.Completed += c.Count
+		st.Hours += c.Hours
+	case model.DutyRecordStatusAbsent:
+		st.Absent += c.Count
+	case model.DutyRecordStatusAbsentMadeUp:
+		st.MadeUp += c.Count
+	case model.DutyRecordStatusNoSignOut:
+		st.NoSignOut += c.Count
+	default:
+		st.Pending += c.Count
+	}
+	if c.IsLate {
+		st.Late += c.Count
+	}
+	if c.IsSubstitute {
+		st.Substitute += c.Count
+	}
+}
+
+func mergeAttendanceCounts(dst, src *dto.AttendanceCounts) {
+	dst.Scheduled += src.Scheduled
+	dst.Completed += src.Completed
+	dst.Late += src.Late
+	dst.Absent += src.Absent
+	dst.MadeUp += src.MadeUp
+	dst.NoSignOut += src.NoSignOut
+	dst.Pending += src.Pending
+	dst.Substitute += src.Substitute
+	dst.Hours += src.Hours
+}
+
+// roundHours 时长保留两位小数
+func roundHours(h float64) float64 {
+	return math.Round(h*100) / 100
+}
+
+func memberDepartmentName(m *dto.MemberBrief) string {
+	if m.Department == nil {
+		return ""
+	}
+	return m.Department.Name
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/model"
+)
+
+// setupAttendanceTest 在换班测试数据上补充各状态的值班记录：
+//   - user-1（技术部）：3 天前未签到、2 天前迟到完成、昨天缺勤
+//   - user-2（运营部）：2 天前完成、今天迟到未签退、3 天后待值班（不计入）
+//   - user-3（技术部）：2 天前缺勤已补班、昨天接手 user-1 缺勤的紧急替班并完成
+func setupAttendanceTest(t *testing.T) (*testScheduleRepos, StatisticsService) {
+	t.Helper()
+	repos, _, absentID := setupSwapTest(t, -1)
+	repos.dutyRecord.records[absentID].Status = model.DutyRecordStatusAbsent
+	repos.user.users["user-3"].Department = repos.user.users["user-1"].Department
+
+	item1, item2 := "item-1", "item-2"
+	day := func(offset int) time.Time { return dateOnly(time.Now().AddDate(0, 0, offset)) }
+	records := []model.DutyRecord{
+		{ScheduleItemID: &item1, MemberID: "user-1", DutyDate: day(-3), Status: model.DutyRecordStatusPending},
+		{ScheduleItemID: &item1, MemberID: "user-1", DutyDate: day(-2), Status: model.DutyRecordStatusCompleted, IsLate: true},
+		{ScheduleItemID: &item2, MemberID: "user-2", DutyDate: day(-2), Status: model.DutyRecordStatusCompleted},
+		{ScheduleItemID: &item2, MemberID: "user-2", DutyDate: day(0), Status: model.DutyRecordStatusNoSignOut, IsLate: true},
+		{ScheduleItemID: &item2, MemberID: "user-2", DutyDate: day(3), Status: model.DutyRecordStatusPending},
+		{ScheduleItemID: &item1, MemberID: "user-3", DutyDate: day(-2), Status: model.DutyRecordStatusAbsentMadeUp},
+		{ScheduleItemID: &item1, MemberID: "user-3", DutyDate: day(-1), Status: model.DutyRecordStatusCompleted, SubstituteForID: &absentID},
+	}
+	if err := repos.dutyRecord.BatchCreate(context.Background(), records); err != nil {
+		t.Fatalf("创建值班记录失败: %v", err)
+	}
+	return repos, NewStatisticsService(repos.toRepository(), zap.NewNop())
+}
+
+func TestStatisticsService_Attendance(t *testing.T) {
+	_, svc := setupAttendanceTest(t)
+	ctx := context.Background()
+
+	// 缺省按当前学期统计，截至今天
+	stats, err := svc.Attendance(ctx, &dto.AttendanceStatsRequest{}, model.RoleAdmin, "")
+	if err != nil {
+		t.Fatalf("Attendance 应成功: %v", err)
+	}
+	if stats.To != time.Now().Format(model.TimeFormatDate) {
+		t.Errorf("统计截止日期应为今天，实际 %s", stats.To)
+	}
+	if len(stats.Members) != 3 {
+		t.Fatalf("应统计 3 位成员，实际 %d", len(stats.Members))
+	}
+	if ids := []string{stats.Members[0].Member.ID, stats.Members[1].Member.ID, stats.Members[2].Member.ID}; ids[0] != "user-1" || ids[1] != "user-3" || ids[2] != "user-2" {
+		t.Errorf("成员应按部门、学号排序，实际 %v", ids)
+	}
+
+	u1 := stats.Members[0].AttendanceCounts
+	if u1.Scheduled != 3 || u1.Completed != 1 || u1.Late != 1 || u1.Absent != 1 || u1.Pending != 1 || u1.Hours != 1.92 {
+		t.Errorf("user-1 统计不符: %+v", u1)
+	}
+	u3 := stats.Members[1].AttendanceCounts
+	if u3.Scheduled != 2 || u3.MadeUp != 1 || u3.Completed != 1 || u3.Substitute != 1 {
+		t.Errorf("user-3 统计不符: %+v", u3)
+	}
+	u2 := stats.Members[2].AttendanceCounts
+	if u2.Scheduled != 2 || u2.Completed != 1 || u2.NoSignOut != 1 || u2.Late != 1 || u2.Hours != 2 {
+		t.Errorf("user-2 统计不符（3 天后的值班不应计入）: %+v", u2)
+	}
+
+	if len(stats.Departments) != 2 || stats.Departments[0].Department.Name != "技术部" {
+		t.Fatalf("应有技术部、运营部两个部门汇总，实际 %+v", stats.Departments)
+	}
+	tech := stats.Departments[0]
+	if tech.MemberCount != 2 || tech.Scheduled != 5 || tech.Completed != 2 || tech.Hours != 3.83 {
+		t.Errorf("技术部汇总不符: members=%d %+v", tech.MemberCount, tech.AttendanceCounts)
+	}
+	if stats.Total.Scheduled != 7 || stats.Total.Completed != 3 || stats.Total.Late != 2 {
+		t.Errorf("合计不符: %+v", stats.Total)
+	}
+}
+
+func TestStatisticsService_Attendance_ScopeAndRange(t *testing.T) {
+	_, svc := setupAttendanceTest(t)
+	ctx := context.Background()
+
+	// leader 固定为本部门，忽略 department_id
+	stats, err := svc.Attendance(ctx, &dto.AttendanceStatsRequest{DepartmentID: "dept-1"}, model.RoleLeader, "dept-2")
+	if err != nil {
+		t.Fatalf("Attendance 应成功: %v", err)
+	}
+	if len(stats.Members) != 1 || stats.Members[0].Member.ID != "user-2" || len(stats.Departments) != 1 {
+		t.Errorf("运营部负责人应只看到 user-2，实际 %+v", stats.Members)
+	}
+
+	req := &dto.AttendanceStatsRequest{
+		From:         time.Now().AddDate(0, 0, -2).Format(model.TimeFormatDate),
+		To:           time.Now().AddDate(0, 0, 10).Format(model.TimeFormatDate),
+		DepartmentID: "dept-1",
+	}
+	stats, err = svc.Attendance(ctx, req, model.RoleAdmin, "")
+	if err != nil {
+		t.Fatalf("Attendance 应成功: %v", err)
+	}
+	if stats.Total.Scheduled != 4 || stats.Total.Pending != 0 {
+		t.Errorf("技术部近两天应有 4 次值班且无未签到，实际 %+v", stats.Total)
+	}
+
+	req = &dto.AttendanceStatsRequest{From: req.To, To: req.From}
+	if _, err := svc.Attendance(ctx, req, model.RoleAdmin, ""); !errors.Is(err, ErrStatsRangeInvalid) {
+		t.Errorf("from 晚于 to 应返回 ErrStatsRangeInvalid，实际 %v", err)
+	}
+	if _, err := svc.Attendance(ctx, &dto.AttendanceStatsRequest{From: req.To}, model.RoleAdmin, ""); !errors.Is(err, ErrStatsRangeInvalid) {
+		t.Errorf("只提供 from 应返回 ErrStatsRangeInvalid，实际 %v", err)
+	}
+	if _, err := svc.Attendance(ctx, &dto.AttendanceStatsRequest{SemesterID: "sem-x"}, model.RoleAdmin, ""); !errors.Is(err, ErrSemesterNotFound) {
+		t.Errorf("学期不存在应返回 ErrSemesterNotFound，实际 %v", err)
+	}
+}
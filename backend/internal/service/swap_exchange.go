@@ -0,0 +1,539 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/model"
+	"echo-union/backend/internal/repository"
+)
+
+// exchangeSide 双向换班的一侧：成员及其排班项；互换单次值班时 record 为该次值班记录
+type exchangeSide struct {
+	memberID string
+	item     *model.ScheduleItem
+	record   *model.DutyRecord
+}
+
+// exchangeSides 从申请中取出申请人一侧与对方一侧（需预加载两侧排班项与值班记录）
+func exchangeSides(swap *model.SwapRequest) (applicant, target exchangeSide) {
+	applicant = exchangeSide{memberID: swap.ApplicantID, item: swap.ScheduleItem, record: swap.DutyRecord}
+	target = exchangeSide{item: swap.TargetScheduleItem, record: swap.TargetDutyRecord}
+	if swap.TargetMemberID != nil {
+		target.memberID = *swap.TargetMemberID
+	}
+	return applicant, target
+}
+
+// ════════════════════════════════════════════════════════════
+// CreateExchange — 发起双向换班
+// ════════════════════════════════════════════════════════════
+
+func (s *swapService) CreateExchange(ctx context.Context, req *dto.CreateExchangeRequest, callerID string) (*dto.SwapRequestResponse, error) {
+	itemMode := req.ScheduleItemID != "" && req.TargetScheduleItemID != "" && req.DutyRecordID == "" && req.TargetDutyRecordID == ""
+	recordMode := req.DutyRecordID != "" && req.TargetDutyRecordID != "" && req.ScheduleItemID == "" && req.TargetScheduleItemID == ""
+	if !itemMode && !recordMode {
+		return nil, ErrSwapExchangeInvalid
+	}
+
+	swap := &model.SwapRequest{
+		Kind:        model.SwapKindExchange,
+		ApplicantID: callerID,
+		Reason:      req.Reason,
+		Status:      model.SwapStatusPending,
+	}
+	var err error
+	if itemMode {
+		err = s.prepareItemExchange(ctx, swap, req.ScheduleItemID, req.TargetScheduleItemID)
+	} else {
+		err = s.prepareRecordExchange(ctx, swap, req.DutyRecordID, req.TargetDutyRecordID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 发起时即按互换后的状态校验，避免邀请对方接受一个无法生效的换班
+	conflicts, err := s.exchangeConflicts(ctx, swap)
+	if err != nil {
+		return nil, err
+	}
+	if len(conflicts) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrSwapMemberUnavailable, strings.Join(conflicts, "；"))
+	}
+
+	swap.CreatedBy = &callerID
+	swap.UpdatedBy = &callerID
+	if err := s.repo.SwapRequest.Create(ctx, swap); err != nil {
+		s.logger.Error("创建双向换班申请失败", zap.Error(err))
+		return nil, err
+	}
+
+	s.logger.Info("双向换班已发起",
+		zap.String("swap_request_id", swap.SwapRequestID),
+		zap.String("applicant_id", callerID),
+		zap.String("target_member_id", *swap.TargetMemberID),
+	)
+
+	s.sendSwapNotifications(ctx, swap.SwapRequestID, model.NotificationTypeSwapRequest, "换班请求",
+		fmt.Sprintf("%s 希望与你换班：%s，请确认", s.memberName(ctx, callerID), s.exchangeDescription(ctx, swap)),
+		[]string{*swap.TargetMemberID})
+	return s.getResponse(ctx, swap.SwapRequestID)
+}
+
+// prepareItemExchange 互换两个排班项（此后每周生效）：须为同一已发布排班表中本人与他人的排班项
+func (s *swapService) prepareItemExchange(ctx context.Context, swap *model.SwapRequest, itemID, targetItemID string) error {
+	if itemID == targetItemID {
+		return ErrSwapExchangeInvalid
+	}
+	item, err := s.getScheduleItem(ctx, itemID)
+	if err != nil {
+		return err
+	}
+	target, err := s.getScheduleItem(ctx, targetItemID)
+	if err != nil {
+		return err
+	}
+	if item.MemberID != swap.ApplicantID {
+		return ErrSwapNotOwner
+	}
+	if target.MemberID == swap.ApplicantID || target.ScheduleID != item.ScheduleID {
+		return ErrSwapExchangeInvalid
+	}
+
+	schedule, err := s.repo.Schedule.GetByID(ctx, item.ScheduleID)
+	if err != nil {
+		s.logger.Error("查询排班表失败", zap.Error(err))
+		return err
+	}
+	if schedule.Status != model.ScheduleStatusPublished {
+		return ErrSwapExchangeInvalid
+	}
+
+	for _, id := range []string{itemID, targetItemID} {
+		open, err := s.repo.SwapRequest.CountOpenItemExchanges(ctx, id)
+		if err != nil {
+			s.logger.Error("查询进行中的换班申请失败", zap.Error(err))
+			return err
+		}
+		if open > 0 {
+			return ErrSwapAlreadyOpen
+		}
+	}
+
+	targetMemberID := target.MemberID
+	swap.ScheduleItemID = itemID
+	swap.TargetScheduleItemID = &targetItemID
+	swap.TargetMemberID = &targetMemberID
+	swap.ScheduleItem, swap.TargetScheduleItem = item, target
+	return nil
+}
+
+// prepareRecordExchange 互换两次具体日期的值班：均为同一排班表中尚未开始、未超过截止时间的值班
+func (s *swapService) prepareRecordExchange(ctx context.Context, swap *model.SwapRequest, recordID, targetRecordID string) error {
+	if recordID == targetRecordID {
+		return ErrSwapExchangeInvalid
+	}
+	record, err := s.getDutyRecord(ctx, recordID)
+	if err != nil {
+		return err
+	}
+	target, err := s.getDutyRecord(ctx, targetRecordID)
+	if err != nil {
+		return err
+	}
+	if record.MemberID != swap.ApplicantID {
+		return ErrSwapNotOwner
+	}
+	if target.MemberID == swap.ApplicantID {
+		return ErrSwapExchangeInvalid
+	}
+	for _, r := range []*model.DutyRecord{record, target} {
+		if r.ScheduleItemID == nil || r.ScheduleItem == nil || r.Status != model.DutyRecordStatusPending {
+			return ErrSwapDutyNotTransferable
+		}
+	}
+	if record.ScheduleItem.ScheduleID != target.ScheduleItem.ScheduleID {
+		return ErrSwapExchangeInvalid
+	}
+
+	cfg, err := s.repo.SystemConfig.Get(ctx)
+	if err != nil {
+		s.logger.Error("查询系统配置失败", zap.Error(err))
+		return err
+	}
+	deadline := time.Duration(cfg.SwapDeadlineHours) * time.Hour
+	for _, r := range []*model.DutyRecord{record, target} {
+		if time.Until(dutyStartTime(r)) < deadline {
+			return ErrSwapDeadlinePassed
+		}
+		open, err := s.repo.SwapRequest.CountOpenByDutyRecord(ctx, r.DutyRecordID)
+		if err != nil {
+			s.logger.Error("查询进行中的换班申请失败", zap.Error(err))
+			return err
+		}
+		if open > 0 {
+			return ErrSwapAlreadyOpen
+		}
+		if err := s.checkNoPendingLeave(ctx, r.DutyRecordID); err != nil {
+			return err
+		}
+	}
+
+	targetMemberID := target.MemberID
+	swap.ScheduleItemID = *record.ScheduleItemID
+	swap.DutyRecordID = &recordID
+	swap.TargetScheduleItemID = target.ScheduleItemID
+	swap.TargetDutyRecordID = &targetRecordID
+	swap.TargetMemberID = &targetMemberID
+	swap.ScheduleItem, swap.TargetScheduleItem = record.ScheduleItem, target.ScheduleItem
+	swap.DutyRecord, swap.TargetDutyRecord = record, target
+	return nil
+}
+
+// ════════════════════════════════════════════════════════════
+// Respond — 被邀请成员接受 / 拒绝双向换班
+// ════════════════════════════════════════════════════════════
+
+func (s *swapService) Respond(ctx context.Context, id string, req *dto.RespondSwapRequest, callerID string) (*dto.SwapRequestResponse, error) {
+	swap, err := s.getSwap(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if swap.Kind != model.SwapKindExchange || swap.TargetMemberID == nil || *swap.TargetMemberID != callerID {
+		return nil, ErrSwapForbidden
+	}
+	if swap.Status != model.SwapStatusPending {
+		return nil, ErrSwapNotPending
+	}
+
+	if !req.Accept {
+		affected, err := s.repo.SwapRequest.Respond(ctx, id, callerID, model.SwapStatusRejected, req.Reason)
+		if err != nil {
+			s.logger.Error("拒绝换班申请失败", zap.Error(err))
+			return nil, err
+		}
+		if affected == 0 {
+			return nil, ErrSwapNotPending
+		}
+		swap.Status = model.SwapStatusRejected
+		swap.RejectReason = req.Reason
+		s.notifyExchangeResponded(ctx, swap)
+		return s.getResponse(ctx, id)
+	}
+
+	if err := s.validateExchange(ctx, swap); err != nil {
+		return nil, err
+	}
+
+	status, autoApproved, err := s.approvalStatus(ctx, swap, callerID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		s.logger.Error("开启事务失败", zap.Error(err))
+		return nil, err
+	}
+	rollbackTx := func() {
+		if tx != nil {
+			tx.Rollback()
+		}
+	}
+	txRepo := s.repo.WithTx(tx)
+
+	respondStatus := status
+	if autoApproved {
+		respondStatus = model.SwapStatusReviewing // 先进入审批，再由系统账号审批通过
+	}
+	affected, err := txRepo.SwapRequest.Respond(ctx, id, callerID, respondStatus, "")
+	if err != nil {
+		rollbackTx()
+		s.logger.Error("接受换班申请失败", zap.Error(err))
+		return nil, err
+	}
+	if affected == 0 {
+		rollbackTx()
+		return nil, ErrSwapNotPending // 申请人已撤回
+	}
+	if autoApproved {
+		if err := s.autoApprove(ctx, txRepo, swap); err != nil {
+			rollbackTx()
+			return nil, err
+		}
+	}
+	if status == model.SwapStatusCompleted {
+		if err := s.applyExchange(ctx, txRepo, swap, callerID); err != nil {
+			rollbackTx()
+			return nil, err
+		}
+	}
+
+	if tx != nil {
+		if err := tx.Commit().Error; err != nil {
+			s.logger.Error("提交事务失败", zap.Error(err))
+			return nil, err
+		}
+	}
+
+	s.logger.Info("双向换班已接受",
+		zap.String("swap_request_id", id),
+		zap.String("member_id", callerID),
+		zap.String("status", status),
+		zap.Bool("auto_approved", autoApproved),
+	)
+
+	swap.Status = status
+	s.notifyExchangeResponded(ctx, swap)
+	return s.getResponse(ctx, id)
+}
+
+// ── 内部辅助方法 ──
+
+func (s *swapService) getScheduleItem(ctx context.Context, id string) (*model.ScheduleItem, error) {
+	item, err := s.repo.ScheduleItem.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSwapScheduleItemNotFound
+		}
+		s.logger.Error("查询排班项失败", zap.Error(err))
+		return nil, err
+	}
+	return item, nil
+}
+
+func (s *swapService) getDutyRecord(ctx context.Context, id string) (*model.DutyRecord, error) {
+	record, err := s.repo.DutyRecord.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSwapDutyRecordNotFound
+		}
+		s.logger.Error("查询值班记录失败", zap.Error(err))
+		return nil, err
+	}
+	return record, nil
+}
+
+// validateExchange 生效前（接受 / 审批）重新校验：两侧仍归属原成员，且互换后双方均无冲突
+func (s *swapService) validateExchange(ctx context.Context, swap *model.SwapRequest) error {
+	applicant, target := exchangeSides(swap)
+	now := time.Now()
+	for _, side := range []exchangeSide{applicant, target} {
+		if side.item == nil {
+			return ErrSwapOutdated
+		}
+		if side.record == nil {
+			if side.item.MemberID != side.memberID {
+				return ErrSwapOutdated
+			}
+			continue
+		}
+		if side.record.MemberID != side.memberID || side.record.Status != model.DutyRecordStatusPending ||
+			!now.Before(dutyStartTime(side.record)) {
+			return ErrSwapOutdated
+		}
+	}
+
+	conflicts, err := s.exchangeConflicts(ctx, swap)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("%w: %s", ErrSwapMemberUnavailable, strings.Join(conflicts, "；"))
+	}
+	return nil
+}
+
+// exchangeConflicts 按互换后的最终状态校验双方，冲突原因以成员姓名为前缀
+func (s *swapService) exchangeConflicts(ctx context.Context, swap *model.SwapRequest) ([]string, error) {
+	applicant, target := exchangeSides(swap)
+	if applicant.item == nil || target.item == nil || applicant.item.TimeSlot == nil || target.item.TimeSlot == nil {
+		return []string{"排班项缺少时段信息"}, nil
+	}
+	sc, err := s.loadSwapContext(ctx, applicant.item.ScheduleID)
+	if err != nil {
+		return nil, err
+	}
+	return s.exchangeConflictsIn(ctx, sc, applicant, target)
+}
+
+// exchangeConflictsIn 在已加载的排班上下文中校验互换：模板层面的冲突在两侧成员互换后的排班项全集上判定，
+// 课程按接手一侧实际值班日的教学周判定（排班项取今日起的各次，单次值班取当天）；
+// 互换单次值班时另校验当天临时不可用与当天的其他值班（不含互换的两次）。
+func (s *swapService) exchangeConflictsIn(ctx context.Context, sc *swapContext, applicant, target exchangeSide) ([]string, error) {
+	allItems := make([]model.ScheduleItem, len(sc.allItems))
+	copy(allItems, sc.allItems)
+	for i := range allItems {
+		switch allItems[i].ScheduleItemID {
+		case applicant.item.ScheduleItemID:
+			allItems[i].MemberID = target.memberID
+		case target.item.ScheduleItemID:
+			allItems[i].MemberID = applicant.memberID
+		}
+	}
+	var exclude []string
+	if applicant.record != nil && target.record != nil {
+		exclude = []string{applicant.record.DutyRecordID, target.record.DutyRecordID}
+	}
+
+	var conflicts []string
+	for _, pair := range [][2]exchangeSide{{applicant, target}, {target, applicant}} {
+		memberID, takes := pair[0].memberID, pair[1]
+		var days []calendarDay
+		if takes.record != nil {
+			days = []calendarDay{sc.calendar.resolve(takes.record.DutyDate)}
+		} else {
+			days = sc.occurrenceDays(takes.item, time.Now())
+		}
+		found, err := s.memberConflicts(ctx, sc, memberID, takes.item, allItems, days, takes.record != nil, exclude...)
+		if err != nil {
+			return nil, err
+		}
+		if len(found) > 0 {
+			name := s.memberName(ctx, memberID)
+			for _, c := range found {
+				conflicts = append(conflicts, name+"："+c)
+			}
+		}
+	}
+	return conflicts, nil
+}
+
+// applyExchange 互换两侧成员并写入成对的变更日志（须在事务内调用）。
+// 互换排班项时同步改派今日起尚未开始的值班记录并关闭原成员的时间表冲突；互换单次值班只改派这两条值班记录。
+func (s *swapService) applyExchange(ctx context.Context, txRepo *repository.Repository, swap *model.SwapRequest, operatorID string) error {
+	applicant, target := exchangeSides(swap)
+	reason := "双向换班"
+	if swap.Reason != "" {
+		reason += "：" + swap.Reason
+	}
+
+	for _, pair := range [][2]exchangeSide{{applicant, target}, {target, applicant}} {
+		from, to := pair[0], pair[1]
+		changeLog := &model.ScheduleChangeLog{
+			ScheduleID:       from.item.ScheduleID,
+			ScheduleItemID:   from.item.ScheduleItemID,
+			OriginalMemberID: from.memberID,
+			NewMemberID:      to.memberID,
+			ChangeType:       "swap",
+			Reason:           reason,
+			OperatorID:       operatorID,
+			CreatedAt:        time.Now(),
+		}
+
+		if from.record != nil {
+			affected, err := txRepo.DutyRecord.ReassignPending(ctx, from.record.DutyRecordID, from.memberID, to.memberID, operatorID)
+			if err != nil {
+				s.logger.Error("改派值班记录失败", zap.Error(err))
+				return err
+			}
+			if affected == 0 {
+				return ErrSwapOutdated // 值班已开始或已被改派
+			}
+			dutyDate := from.record.DutyDate
+			changeLog.DutyDate = &dutyDate
+		} else {
+			item, err := txRepo.ScheduleItem.GetByID(ctx, from.item.ScheduleItemID)
+			if err != nil {
+				s.logger.Error("查询排班项失败", zap.Error(err))
+				return err
+			}
+			if item.MemberID != from.memberID {
+				return ErrSwapOutdated
+			}
+			updated := *item
+			updated.MemberID = to.memberID
+			updated.UpdatedBy = &operatorID
+			if err := txRepo.ScheduleItem.Update(ctx, &updated); err != nil {
+				s.logger.Error("更新排班项失败", zap.Error(err))
+				return err
+			}
+			if err := txRepo.DutyRecord.UpdatePendingMemberByItemFrom(ctx, item.ScheduleItemID, dateOnly(time.Now()), from.memberID, to.memberID, operatorID); err != nil {
+				s.logger.Error("改派值班记录失败", zap.Error(err))
+				return err
+			}
+			if err := txRepo.ScheduleConflict.CloseOpenByItem(ctx, item.ScheduleItemID, "排班项已互换", operatorID); err != nil {
+				s.logger.Error("关闭冲突任务失败", zap.Error(err))
+				return err
+			}
+		}
+
+		if err := txRepo.ScheduleChangeLog.Create(ctx, changeLog); err != nil {
+			s.logger.Error("创建变更日志失败", zap.Error(err))
+			return err
+		}
+	}
+	return nil
+}
+
+// exchangeSideDescription 一侧的描述：单次值班带日期，排班项注明此后每周
+func exchangeSideDescription(side exchangeSide) string {
+	if side.record != nil {
+		return dutyDescription(side.record)
+	}
+	if side.item == nil {
+		return ""
+	}
+	return describeItem(side.item) + "（此后每周）"
+}
+
+// exchangeDescription 双向换班描述，如 "张三 的 … ↔ 李四 的 …"
+func (s *swapService) exchangeDescription(ctx context.Context, swap *model.SwapRequest) string {
+	applicant, target := exchangeSides(swap)
+	return fmt.Sprintf("%s 的 %s ↔ %s 的 %s",
+		s.memberName(ctx, applicant.memberID), exchangeSideDescription(applicant),
+		s.memberName(ctx, target.memberID), exchangeSideDescription(target))
+}
+
+// notifyExchangeResponded 通知申请人对方的响应；需审批时同时通知管理员
+func (s *swapService) notifyExchangeResponded(ctx context.Context, swap *model.SwapRequest) {
+	target := s.memberName(ctx, *swap.TargetMemberID)
+	desc := s.exchangeDescription(ctx, swap)
+	switch swap.Status {
+	case model.SwapStatusRejected:
+		content := fmt.Sprintf("%s 拒绝了换班：%s", target, desc)
+		if swap.RejectReason != "" {
+			content += "（" + swap.RejectReason + "）"
+		}
+		s.sendSwapNotifications(ctx, swap.SwapRequestID, model.NotificationTypeSwapRejected, "换班被拒绝", content, []string{swap.ApplicantID})
+	case model.SwapStatusReviewing:
+		s.sendSwapNotifications(ctx, swap.SwapRequestID, model.NotificationTypeSwapAccepted, "换班已被接受",
+			fmt.Sprintf("%s 接受了换班：%s，待管理员审批", target, desc), []string{swap.ApplicantID})
+		admins, err := adminUserIDs(ctx, s.repo)
+		if err != nil {
+			s.logger.Warn("查询管理员失败，审批通知未发送", zap.Error(err))
+			return
+		}
+		s.sendSwapNotifications(ctx, swap.SwapRequestID, model.NotificationTypeSwapRequest, "双向换班待审批",
+			fmt.Sprintf("换班：%s，双方已确认，请审批", desc), admins)
+	default:
+		s.sendSwapNotifications(ctx, swap.SwapRequestID, model.NotificationTypeSwapAccepted, "换班已生效",
+			fmt.Sprintf("%s 接受了换班：%s，已完成互换%s", target, desc, autoApprovalNote(swap)), []string{swap.ApplicantID})
+	}
+}
+
+// toSwapItemBrief 转换换班涉及的排班项
+func toSwapItemBrief(item *model.ScheduleItem) *dto.SwapItemBrief {
+	if item == nil {
+		return nil
+	}
+	brief := &dto.SwapItemBrief{
+		ID:         item.ScheduleItemID,
+		WeekNumber: item.WeekNumber,
+		Member:     toMemberBrief(item.Member),
+	}
+	if item.TimeSlot != nil {
+		brief.TimeSlot = toTimeSlotBrief(item.TimeSlot)
+	}
+	if item.Location != nil {
+		brief.Location = &dto.LocationBrief{ID: item.Location.LocationID, Name: item.Location.Name}
+	}
+	return brief
+}
@@ -0,0 +1,315 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/model"
+	"echo-union/backend/internal/repository"
+)
+
+// ── 换班自动审批 ──
+//
+// 需审批的申请在认领 / 接受时按申请人所在部门的策略（无覆盖时为全局策略）判定，
+// 满足全部条件即由系统账号（model.SystemActorID）审批通过，否则照常进入管理员审批：
+//   - 双方同部门（策略可关闭）
+//   - 双方通过全部硬约束：认领 / 接受前已校验，不满足时直接拒绝而不会走到这里
+//   - 申请发起时间早于所涉最早一次值班开始至少 MinHoursBefore 小时
+//   - 申请人本学期已完成的换班少于 MaxSwapsPerSemester（0 不限）
+
+// swapPolicy 生效的自动审批策略
+type swapPolicy struct {
+	departmentID        string // 为空表示全局策略
+	autoApprove         bool
+	sameDepartment      bool
+	minHoursBefore      int
+	maxSwapsPerSemester int
+}
+
+func globalSwapPolicy(cfg *model.SystemConfig) *swapPolicy {
+	return &swapPolicy{
+		autoApprove:         cfg.SwapAutoApprove,
+		sameDepartment:      cfg.SwapAutoApproveSameDepartment,
+		minHoursBefore:      cfg.SwapAutoApproveMinHours,
+		maxSwapsPerSemester: cfg.SwapAutoApproveMaxPerSemester,
+	}
+}
+
+func departmentSwapPolicy(p *model.DepartmentSwapPolicy) *swapPolicy {
+	return &swapPolicy{
+		departmentID:        p.DepartmentID,
+		autoApprove:         p.AutoApprove,
+		sameDepartment:      p.SameDepartment,
+		minHoursBefore:      p.MinHoursBefore,
+		maxSwapsPerSemester: p.MaxSwapsPerSemester,
+	}
+}
+
+// effectivePolicy 部门策略优先，无覆盖时使用全局策略
+func (s *swapService) effectivePolicy(ctx context.Context, cfg *model.SystemConfig, departmentID string) (*swapPolicy, error) {
+	p, err := s.repo.DepartmentSwapPolicy.GetByDepartment(ctx, departmentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return globalSwapPolicy(cfg), nil
+		}
+		s.logger.Error("查询部门换班策略失败", zap.Error(err))
+		return nil, err
+	}
+	return departmentSwapPolicy(p), nil
+}
+
+// approvalStatus 认领 / 接受后的状态：无需审批或满足自动审批策略时为 completed，否则为 reviewing；
+// autoApproved 为 true 时须由系统账号审批通过
+func (s *swapService) approvalStatus(ctx context.Context, swap *model.SwapRequest, targetID string) (string, bool, error) {
+	cfg, err := s.repo.SystemConfig.Get(ctx)
+	if err != nil {
+		s.logger.Error("查询系统配置失败", zap.Error(err))
+		return "", false, err
+	}
+	if !cfg.SwapRequiresApproval {
+		return model.SwapStatusCompleted, false, nil
+	}
+	ok, err := s.autoApprovable(ctx, cfg, swap, targetID)
+	if err != nil {
+		return "", false, err
+	}
+	if ok {
+		return model.SwapStatusCompleted, true, nil
+	}
+	return model.SwapStatusReviewing, false, nil
+}
+
+// autoApprovable 申请是否满足申请人所在部门的自动审批策略
+func (s *swapService) autoApprovable(ctx context.Context, cfg *model.SystemConfig, swap *model.SwapRequest, targetID string) (bool, error) {
+	applicant, err := s.repo.User.GetByID(ctx, swap.ApplicantID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		s.logger.Error("查询申请人失败", zap.Error(err))
+		return false, err
+	}
+	policy, err := s.effectivePolicy(ctx, cfg, applicant.DepartmentID)
+	if err != nil {
+		return false, err
+	}
+	if !policy.autoApprove {
+		return false, nil
+	}
+
+	if policy.sameDepartment {
+		target, err := s.repo.User.GetByID(ctx, targetID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return false, nil
+			}
+			s.logger.Error("查询接班成员失败", zap.Error(err))
+			return false, err
+		}
+		if target.DepartmentID != applicant.DepartmentID {
+			return false, nil
+		}
+	}
+
+	start, ok, err := s.earliestDutyStart(ctx, swap)
+	if err != nil {
+		return false, err
+	}
+	if !ok || start.Sub(swap.CreatedAt) < time.Duration(policy.minHoursBefore)*time.Hour {
+		return false, nil
+	}
+
+	if policy.maxSwapsPerSemester > 0 {
+		if swap.ScheduleItem == nil {
+			return false, nil
+		}
+		schedule, err := s.repo.Schedule.GetByID(ctx, swap.ScheduleItem.ScheduleID)
+		if err != nil {
+			s.logger.Error("查询排班表失败", zap.Error(err))
+			return false, err
+		}
+		count, err := s.repo.SwapRequest.CountCompletedByApplicantInSemester(ctx, swap.ApplicantID, schedule.SemesterID)
+		if err != nil {
+			s.logger.Error("统计申请人换班次数失败", zap.Error(err))
+			return false, err
+		}
+		if count >= int64(policy.maxSwapsPerSemester) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// earliestDutyStart 申请所涉最早一次值班的开始时间：具体日期的值班取两侧记录，
+// 排班项互换取两侧排班项此后的第一次值班；ok 为 false 表示此后已无值班
+func (s *swapService) earliestDutyStart(ctx context.Context, swap *model.SwapRequest) (time.Time, bool, error) {
+	var starts []time.Time
+	if swap.DutyRecord != nil {
+		starts = append(starts, dutyStartTime(swap.DutyRecord))
+		if swap.TargetDutyRecord != nil {
+			starts = append(starts, dutyStartTime(swap.TargetDutyRecord))
+		}
+	} else if swap.ScheduleItem != nil {
+		sc, err := s.loadSwapContext(ctx, swap.ScheduleItem.ScheduleID)
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		for _, item := range []*model.ScheduleItem{swap.ScheduleItem, swap.TargetScheduleItem} {
+			if item == nil || item.TimeSlot == nil {
+				continue
+			}
+			if days := sc.occurrenceDays(item, dateOnly(time.Now())); len(days) > 0 {
+				itemID := item.ScheduleItemID
+				starts = append(starts, dutyStartTime(&model.DutyRecord{
+					ScheduleItemID: &itemID,
+					DutyDate:       days[0].date,
+					ScheduleItem:   item,
+				}))
+			}
+		}
+	}
+
+	if len(starts) == 0 {
+		return time.Time{}, false, nil
+	}
+	earliest := starts[0]
+	for _, t := range starts[1:] {
+		if t.Before(earliest) {
+			earliest = t
+		}
+	}
+	return earliest, true, nil
+}
+
+// autoApprove 在认领 / 接受的事务内由系统账号审批通过
+func (s *swapService) autoApprove(ctx context.Context, txRepo *repository.Repository, swap *model.SwapRequest) error {
+	affected, err := txRepo.SwapRequest.Approve(ctx, swap.SwapRequestID, model.SystemActorID)
+	if err != nil {
+		s.logger.Error("自动审批换班申请失败", zap.Error(err))
+		return err
+	}
+	if affected == 0 {
+		return ErrSwapNotReviewing
+	}
+	actor := model.SystemActorID
+	swap.ApprovedBy = &actor
+	return nil
+}
+
+// autoApprovalNote 自动审批通过时附加在通知中的说明
+func autoApprovalNote(swap *model.SwapRequest) string {
+	if swap.ApprovedBy != nil && *swap.ApprovedBy == model.SystemActorID {
+		return "（已按自动审批策略通过）"
+	}
+	return ""
+}
+
+// ════════════════════════════════════════════════════════════
+// 自动审批策略管理
+// ════════════════════════════════════════════════════════════
+
+// ListPolicies 全局策略与各部门覆盖
+func (s *swapService) ListPolicies(ctx context.Context) (*dto.SwapPolicyListResponse, error) {
+	cfg, err := s.repo.SystemConfig.Get(ctx)
+	if err != nil {
+		s.logger.Error("查询系统配置失败", zap.Error(err))
+		return nil, err
+	}
+	policies, err := s.repo.DepartmentSwapPolicy.List(ctx)
+	if err != nil {
+		s.logger.Error("查询部门换班策略失败", zap.Error(err))
+		return nil, err
+	}
+
+	result := &dto.SwapPolicyListResponse{
+		Global:      toSwapPolicyResponse(globalSwapPolicy(cfg), nil),
+		Departments: make([]dto.SwapPolicyResponse, 0, len(policies)),
+	}
+	for i := range policies {
+		p := &policies[i]
+		result.Departments = append(result.Departments, toSwapPolicyResponse(departmentSwapPolicy(p), p.Department))
+	}
+	return result, nil
+}
+
+// SetDepartmentPolicy 设置部门策略（整体覆盖全局策略）
+func (s *swapService) SetDepartmentPolicy(ctx context.Context, departmentID string, req *dto.SetSwapPolicyRequest, callerID string) (*dto.SwapPolicyResponse, error) {
+	dept, err := s.repo.Department.GetByID(ctx, departmentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSwapPolicyDepartmentNotFound
+		}
+		s.logger.Error("查询部门失败", zap.Error(err))
+		return nil, err
+	}
+
+	policy := &model.DepartmentSwapPolicy{
+		DepartmentID:        departmentID,
+		AutoApprove:         req.AutoApprove,
+		SameDepartment:      req.SameDepartment,
+		MinHoursBefore:      req.MinHoursBefore,
+		MaxSwapsPerSemester: req.MaxSwapsPerSemester,
+	}
+	if existing, err := s.repo.DepartmentSwapPolicy.GetByDepartment(ctx, departmentID); err == nil {
+		policy.BaseModel = existing.BaseModel
+	} else if errors.Is(err, gorm.ErrRecordNotFound) {
+		policy.CreatedBy = &callerID
+		policy.CreatedAt = time.Now()
+	} else {
+		s.logger.Error("查询部门换班策略失败", zap.Error(err))
+		return nil, err
+	}
+	policy.UpdatedBy = &callerID
+	policy.UpdatedAt = time.Now()
+
+	if err := s.repo.DepartmentSwapPolicy.Save(ctx, policy); err != nil {
+		s.logger.Error("保存部门换班策略失败", zap.Error(err))
+		return nil, err
+	}
+
+	s.logger.Info("部门换班策略已更新",
+		zap.String("department_id", departmentID),
+		zap.Bool("auto_approve", policy.AutoApprove),
+		zap.String("updated_by", callerID),
+	)
+
+	resp := toSwapPolicyResponse(departmentSwapPolicy(policy), dept)
+	return &resp, nil
+}
+
+// DeleteDepartmentPolicy 删除部门策略，恢复使用全局策略
+func (s *swapService) DeleteDepartmentPolicy(ctx context.Context, departmentID string) error {
+	if _, err := s.repo.DepartmentSwapPolicy.GetByDepartment(ctx, departmentID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSwapPolicyNotFound
+		}
+		s.logger.Error("查询部门换班策略失败", zap.Error(err))
+		return err
+	}
+	if err := s.repo.DepartmentSwapPolicy.Delete(ctx, departmentID); err != nil {
+		s.logger.Error("删除部门换班策略失败", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func toSwapPolicyResponse(p *swapPolicy, dept *model.Department) dto.SwapPolicyResponse {
+	resp := dto.SwapPolicyResponse{
+		AutoApprove:         p.autoApprove,
+		SameDepartment:      p.sameDepartment,
+		MinHoursBefore:      p.minHoursBefore,
+		MaxSwapsPerSemester: p.maxSwapsPerSemester,
+	}
+	if p.departmentID != "" {
+		resp.Department = &dto.DepartmentResponse{ID: p.departmentID}
+		if dept != nil {
+			resp.Department.Name = dept.Name
+		}
+	}
+	return resp
+}
@@ -0,0 +1,741 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/model"
+	"echo-union/backend/internal/repository"
+)
+
+// ── 换班模块业务错误 ──
+
+var (
+	ErrSwapNotFound                 = errors.New("换班申请不存在")
+	ErrSwapDutyRecordNotFound       = errors.New("值班记录不存在")
+	ErrSwapNotOwner                 = errors.New("只能转让本人的值班")
+	ErrSwapDutyNotTransferable      = errors.New("该次值班不可转让")
+	ErrSwapDeadlinePassed           = errors.New("已超过换班截止时间")
+	ErrSwapAlreadyOpen              = errors.New("该次值班已有进行中的换班申请")
+	ErrSwapNotClaimable             = errors.New("该转让已被认领或已关闭")
+	ErrSwapSelfClaim                = errors.New("不能认领自己发布的转让")
+	ErrSwapMemberUnavailable        = errors.New("成员在该次值班时段不可用")
+	ErrSwapNotReviewing             = errors.New("申请不在待审批状态")
+	ErrSwapNotCancellable           = errors.New("申请已结束，无法撤回")
+	ErrSwapForbidden                = errors.New("无权操作该换班申请")
+	ErrSwapExchangeInvalid          = errors.New("换班双方须为同一已发布排班中本人与他人的值班")
+	ErrSwapScheduleItemNotFound     = errors.New("排班项不存在")
+	ErrSwapNotPending               = errors.New("申请不在待响应状态")
+	ErrSwapOutdated                 = errors.New("值班安排已变更，申请已失效")
+	ErrSwapSuggestionTarget         = errors.New("须指定 item_id 或 duty_record_id 之一")
+	ErrSwapPolicyDepartmentNotFound = errors.New("部门不存在")
+	ErrSwapPolicyNotFound           = errors.New("该部门未设置独立的换班策略")
+	ErrSwapLeavePending             = errors.New("该次值班已有待审批的请假")
+)
+
+// SwapService 换班业务接口
+//
+// 设计说明：
+//   - 值班转让（give_away）：成员发布某一具体日期的值班，不指定接班人
+//   - 可认领成员的冲突校验与 GetCandidates 一致（不可用时间、技能、同日、搭配、休息约束），
+//     课程按该次值班所在教学周的精确周次判定，另校验当天临时不可用与当天已有的其他值班
+//   - 先到先得：认领为条件更新，并发认领只有一人成功；system_config.swap_requires_approval
+//     开启时认领后进入管理员审批，否则立即生效
+//   - 生效时只改派这一次值班记录（排班模板不变），并写入 change_type = swap 的单次变更日志
+//   - 双向换班（exchange）：与指定成员互换两个排班项（此后每周生效）或两次具体日期的值班；
+//     对方接受后按同一开关直接生效或进入审批，发起、接受、审批时均按互换后的最终状态校验双方
+//   - 互换在同一事务内完成，并为双方各写一条变更日志
+//   - 候选人推荐（Suggest）：按同一套校验列出可接手的成员，按当前周常负荷排序，并列出可回换的值班
+//   - 自动审批：需审批的申请在认领 / 接受时若满足申请人所在部门（或全局）的自动审批策略，
+//     由系统账号在同一事务内审批通过，approved_by 记为 model.SystemActorID
+type SwapService interface {
+	CreateGiveAway(ctx context.Context, req *dto.CreateGiveAwayRequest, callerID string) (*dto.SwapRequestResponse, error)
+	ListGiveAwayFeed(ctx context.Context, callerID string) ([]dto.SwapRequestResponse, error)
+	ClaimGiveAway(ctx context.Context, id, callerID string) (*dto.SwapRequestResponse, error)
+	CreateExchange(ctx context.Context, req *dto.CreateExchangeRequest, callerID string) (*dto.SwapRequestResponse, error)
+	Respond(ctx context.Context, id string, req *dto.RespondSwapRequest, callerID string) (*dto.SwapRequestResponse, error)
+	Suggest(ctx context.Context, req *dto.SwapSuggestionRequest, callerID string, isAdmin bool) ([]dto.SwapSuggestionResponse, error)
+	Review(ctx context.Context, id string, req *dto.ReviewSwapRequest, callerID string) (*dto.SwapRequestResponse, error)
+	Cancel(ctx context.Context, id, callerID string) error
+	GetByID(ctx context.Context, id, callerID string, isAdmin bool) (*dto.SwapRequestResponse, error)
+	ListMine(ctx context.Context, req *dto.SwapListRequest, callerID string) ([]dto.SwapRequestResponse, int64, error)
+	ListPending(ctx context.Context, req *dto.SwapListRequest) ([]dto.SwapRequestResponse, int64, error)
+
+	ListPolicies(ctx context.Context) (*dto.SwapPolicyListResponse, error)
+	SetDepartmentPolicy(ctx context.Context, departmentID string, req *dto.SetSwapPolicyRequest, callerID string) (*dto.SwapPolicyResponse, error)
+	DeleteDepartmentPolicy(ctx context.Context, departmentID string) error
+}
+
+type swapService struct {
+	repo     *repository.Repository
+	logger   *zap.Logger
+	schedule *scheduleService // 复用候选人冲突校验
+}
+
+// NewSwapService 创建 SwapService 实例
+func NewSwapService(repo *repository.Repository, logger *zap.Logger) SwapService {
+	return &swapService{
+		repo:     repo,
+		logger:   logger,
+		schedule: &scheduleService{repo: repo, logger: logger},
+	}
+}
+
+// ════════════════════════════════════════════════════════════
+// CreateGiveAway — 发布值班转让
+// ════════════════════════════════════════════════════════════
+
+func (s *swapService) CreateGiveAway(ctx context.Context, req *dto.CreateGiveAwayRequest, callerID string) (*dto.SwapRequestResponse, error) {
+	record, err := s.repo.DutyRecord.GetByID(ctx, req.DutyRecordID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSwapDutyRecordNotFound
+		}
+		s.logger.Error("查询值班记录失败", zap.Error(err))
+		return nil, err
+	}
+	if record.MemberID != callerID {
+		return nil, ErrSwapNotOwner
+	}
+	// 活动班次按报名 / 退出处理，不走转让
+	if record.ScheduleItemID == nil || record.ScheduleItem == nil || record.Status != model.DutyRecordStatusPending {
+		return nil, ErrSwapDutyNotTransferable
+	}
+
+	cfg, err := s.repo.SystemConfig.Get(ctx)
+	if err != nil {
+		s.logger.Error("查询系统配置失败", zap.Error(err))
+		return nil, err
+	}
+	if time.Until(dutyStartTime(record)) < time.Duration(cfg.SwapDeadlineHours)*time.Hour {
+		return nil, ErrSwapDeadlinePassed
+	}
+
+	open, err := s.repo.SwapRequest.CountOpenByDutyRecord(ctx, record.DutyRecordID)
+	if err != nil {
+		s.logger.Error("查询进行中的换班申请失败", zap.Error(err))
+		return nil, err
+	}
+	if open > 0 {
+		return nil, ErrSwapAlreadyOpen
+	}
+	if err := s.checkNoPendingLeave(ctx, record.DutyRecordID); err != nil {
+		return nil, err
+	}
+
+	recordID := record.DutyRecordID
+	swap := &model.SwapRequest{
+		ScheduleItemID: *record.ScheduleItemID,
+		DutyRecordID:   &recordID,
+		Kind:           model.SwapKindGiveAway,
+		ApplicantID:    callerID,
+		Reason:         req.Reason,
+		Status:         model.SwapStatusPending,
+	}
+	swap.CreatedBy = &callerID
+	swap.UpdatedBy = &callerID
+	if err := s.repo.SwapRequest.Create(ctx, swap); err != nil {
+		s.logger.Error("创建值班转让失败", zap.Error(err))
+		return nil, err
+	}
+
+	s.logger.Info("值班转让已发布",
+		zap.String("swap_request_id", swap.SwapRequestID),
+		zap.String("duty_record_id", recordID),
+		zap.String("applicant_id", callerID),
+	)
+
+	swap.DutyRecord = record
+	s.notifyGiveAway(ctx, swap)
+	return s.getResponse(ctx, swap.SwapRequestID)
+}
+
+// ════════════════════════════════════════════════════════════
+// ListGiveAwayFeed — 当前成员可认领的值班转让
+// ════════════════════════════════════════════════════════════
+
+func (s *swapService) ListGiveAwayFeed(ctx context.Context, callerID string) ([]dto.SwapRequestResponse, error) {
+	swaps, err := s.repo.SwapRequest.ListOpenGiveAways(ctx, dateOnly(time.Now()))
+	if err != nil {
+		s.logger.Error("查询值班转让失败", zap.Error(err))
+		return nil, err
+	}
+
+	now := time.Now()
+	result := make([]dto.SwapRequestResponse, 0, len(swaps))
+	for i := range swaps {
+		swap := &swaps[i]
+		if swap.ApplicantID == callerID || !giveAwayOpen(swap, now) {
+			continue
+		}
+		conflicts, err := s.claimConflicts(ctx, swap.DutyRecord, callerID)
+		if err != nil {
+			return nil, err
+		}
+		if len(conflicts) == 0 {
+			result = append(result, toSwapRequestResponse(swap))
+		}
+	}
+	return result, nil
+}
+
+// ════════════════════════════════════════════════════════════
+// ClaimGiveAway — 认领值班转让（先到先得）
+// ════════════════════════════════════════════════════════════
+
+func (s *swapService) ClaimGiveAway(ctx context.Context, id, callerID string) (*dto.SwapRequestResponse, error) {
+	swap, err := s.getSwap(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if swap.Kind != model.SwapKindGiveAway || !giveAwayOpen(swap, time.Now()) {
+		return nil, ErrSwapNotClaimable
+	}
+	if swap.ApplicantID == callerID {
+		return nil, ErrSwapSelfClaim
+	}
+
+	conflicts, err := s.claimConflicts(ctx, swap.DutyRecord, callerID)
+	if err != nil {
+		return nil, err
+	}
+	if len(conflicts) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrSwapMemberUnavailable, strings.Join(conflicts, "；"))
+	}
+
+	status, autoApproved, err := s.approvalStatus(ctx, swap, callerID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		s.logger.Error("开启事务失败", zap.Error(err))
+		return nil, err
+	}
+	rollbackTx := func() {
+		if tx != nil {
+			tx.Rollback()
+		}
+	}
+	txRepo := s.repo.WithTx(tx)
+
+	claimStatus := status
+	if autoApproved {
+		claimStatus = model.SwapStatusReviewing // 先进入审批，再由系统账号审批通过
+	}
+	affected, err := txRepo.SwapRequest.Claim(ctx, id, callerID, claimStatus)
+	if err != nil {
+		rollbackTx()
+		s.logger.Error("认领值班转让失败", zap.Error(err))
+		return nil, err
+	}
+	if affected == 0 {
+		rollbackTx()
+		return nil, ErrSwapNotClaimable // 并发认领
+	}
+	swap.TargetMemberID = &callerID
+	if autoApproved {
+		if err := s.autoApprove(ctx, txRepo, swap); err != nil {
+			rollbackTx()
+			return nil, err
+		}
+	}
+	if status == model.SwapStatusCompleted {
+		if err := s.applyGiveAway(ctx, txRepo, swap, callerID); err != nil {
+			rollbackTx()
+			return nil, err
+		}
+	}
+
+	if tx != nil {
+		if err := tx.Commit().Error; err != nil {
+			s.logger.Error("提交事务失败", zap.Error(err))
+			return nil, err
+		}
+	}
+
+	s.logger.Info("值班转让已认领",
+		zap.String("swap_request_id", id),
+		zap.String("member_id", callerID),
+		zap.String("status", status),
+		zap.Bool("auto_approved", autoApproved),
+	)
+
+	swap.Status = status
+	s.notifyClaimed(ctx, swap, callerID)
+	return s.getResponse(ctx, id)
+}
+
+// ════════════════════════════════════════════════════════════
+// Review — 管理员审批
+// ════════════════════════════════════════════════════════════
+
+func (s *swapService) Review(ctx context.Context, id string, req *dto.ReviewSwapRequest, callerID string) (*dto.SwapRequestResponse, error) {
+	swap, err := s.getSwap(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if swap.Status != model.SwapStatusReviewing || swap.TargetMemberID == nil {
+		return nil, ErrSwapNotReviewing
+	}
+
+	if !req.Approve {
+		affected, err := s.repo.SwapRequest.Reject(ctx, id, req.Reason, callerID)
+		if err != nil {
+			s.logger.Error("驳回换班申请失败", zap.Error(err))
+			return nil, err
+		}
+		if affected == 0 {
+			return nil, ErrSwapNotReviewing
+		}
+		swap.Status = model.SwapStatusRejected
+		swap.RejectReason = req.Reason
+		s.notifyReviewed(ctx, swap)
+		return s.getResponse(ctx, id)
+	}
+
+	// 认领 / 接受到审批期间成员情况可能变化，审批时重新校验
+	if err := s.validateForApproval(ctx, swap); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		s.logger.Error("开启事务失败", zap.Error(err))
+		return nil, err
+	}
+	rollbackTx := func() {
+		if tx != nil {
+			tx.Rollback()
+		}
+	}
+	txRepo := s.repo.WithTx(tx)
+
+	affected, err := txRepo.SwapRequest.Approve(ctx, id, callerID)
+	if err != nil {
+		rollbackTx()
+		s.logger.Error("审批换班申请失败", zap.Error(err))
+		return nil, err
+	}
+	if affected == 0 {
+		rollbackTx()
+		return nil, ErrSwapNotReviewing
+	}
+	if swap.Kind == model.SwapKindExchange {
+		err = s.applyExchange(ctx, txRepo, swap, callerID)
+	} else {
+		err = s.applyGiveAway(ctx, txRepo, swap, callerID)
+	}
+	if err != nil {
+		rollbackTx()
+		return nil, err
+	}
+
+	if tx != nil {
+		if err := tx.Commit().Error; err != nil {
+			s.logger.Error("提交事务失败", zap.Error(err))
+			return nil, err
+		}
+	}
+
+	s.logger.Info("换班申请已审批通过",
+		zap.String("swap_request_id", id),
+		zap.String("approved_by", callerID),
+	)
+
+	swap.Status = model.SwapStatusCompleted
+	s.notifyReviewed(ctx, swap)
+	return s.getResponse(ctx, id)
+}
+
+// ════════════════════════════════════════════════════════════
+// Cancel — 申请人撤回
+// ════════════════════════════════════════════════════════════
+
+func (s *swapService) Cancel(ctx context.Context, id, callerID string) error {
+	swap, err := s.getSwap(ctx, id)
+	if err != nil {
+		return err
+	}
+	if swap.ApplicantID != callerID {
+		return ErrSwapForbidden
+	}
+	affected, err := s.repo.SwapRequest.Cancel(ctx, id, callerID)
+	if err != nil {
+		s.logger.Error("撤回换班申请失败", zap.Error(err))
+		return err
+	}
+	if affected == 0 {
+		return ErrSwapNotCancellable
+	}
+	return nil
+}
+
+// ════════════════════════════════════════════════════════════
+// 查询
+// ════════════════════════════════════════════════════════════
+
+// GetByID 申请详情：管理员、申请人、接班人可见；待认领的转让对所有成员可见
+func (s *swapService) GetByID(ctx context.Context, id, callerID string, isAdmin bool) (*dto.SwapRequestResponse, error) {
+	swap, err := s.getSwap(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	involved := swap.ApplicantID == callerID || (swap.TargetMemberID != nil && *swap.TargetMemberID == callerID)
+	if !isAdmin && !involved && !giveAwayOpen(swap, time.Now()) {
+		return nil, ErrSwapForbidden
+	}
+	resp := toSwapRequestResponse(swap)
+	return &resp, nil
+}
+
+func (s *swapService) ListMine(ctx context.Context, req *dto.SwapListRequest, callerID string) ([]dto.SwapRequestResponse, int64, error) {
+	return s.list(ctx, &repository.SwapRequestFilters{Kind: req.Kind, Status: req.Status, MemberID: callerID}, &req.PaginationRequest)
+}
+
+// ListPending 待管理员审批的申请
+func (s *swapService) ListPending(ctx context.Context, req *dto.SwapListRequest) ([]dto.SwapRequestResponse, int64, error) {
+	return s.list(ctx, &repository.SwapRequestFilters{Kind: req.Kind, Status: model.SwapStatusReviewing}, &req.PaginationRequest)
+}
+
+func (s *swapService) list(ctx context.Context, filters *repository.SwapRequestFilters, page *dto.PaginationRequest) ([]dto.SwapRequestResponse, int64, error) {
+	swaps, total, err := s.repo.SwapRequest.ListWithFilters(ctx, filters, page.GetOffset(), page.GetPageSize())
+	if err != nil {
+		s.logger.Error("查询换班申请失败", zap.Error(err))
+		return nil, 0, err
+	}
+	result := make([]dto.SwapRequestResponse, 0, len(swaps))
+	for i := range swaps {
+		result = append(result, toSwapRequestResponse(&swaps[i]))
+	}
+	return result, total, nil
+}
+
+// ── 内部辅助方法 ──
+
+func (s *swapService) getSwap(ctx context.Context, id string) (*model.SwapRequest, error) {
+	swap, err := s.repo.SwapRequest.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSwapNotFound
+		}
+		s.logger.Error("查询换班申请失败", zap.Error(err))
+		return nil, err
+	}
+	return swap, nil
+}
+
+// checkNoPendingLeave 值班记录有待审批的请假时不可再发起换班
+func (s *swapService) checkNoPendingLeave(ctx context.Context, dutyRecordID string) error {
+	pending, err := s.repo.LeaveRequest.CountPendingByDutyRecord(ctx, dutyRecordID)
+	if err != nil {
+		s.logger.Error("查询待审批的请假失败", zap.Error(err))
+		return err
+	}
+	if pending > 0 {
+		return ErrSwapLeavePending
+	}
+	return nil
+}
+
+func (s *swapService) getResponse(ctx context.Context, id string) (*dto.SwapRequestResponse, error) {
+	swap, err := s.getSwap(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	resp := toSwapRequestResponse(swap)
+	return &resp, nil
+}
+
+// claimConflicts 返回成员接手该次值班的冲突原因，空表示可认领。
+// 成员须为本学期需值班且已提交课表的成员；冲突校验见 memberConflicts（按该次值班的具体日期）。
+func (s *swapService) claimConflicts(ctx context.Context, record *model.DutyRecord, memberID string) ([]string, error) {
+	if record == nil || record.ScheduleItem == nil || record.ScheduleItem.TimeSlot == nil {
+		return []string{"值班记录缺少排班时段"}, nil
+	}
+	sc, err := s.loadSwapContext(ctx, record.ScheduleItem.ScheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	assignment, err := s.repo.UserSemesterAssignment.GetByUserAndSemester(ctx, memberID, sc.schedule.SemesterID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		s.logger.Error("查询学期分配失败", zap.Error(err))
+		return nil, err
+	}
+	if assignment == nil || !assignment.DutyRequired || assignment.TimetableStatus != model.TimetableStatusSubmitted {
+		return []string{"非本学期值班成员或未提交课表"}, nil
+	}
+
+	days := []calendarDay{sc.calendar.resolve(record.DutyDate)}
+	return s.memberConflicts(ctx, sc, memberID, record.ScheduleItem, sc.allItems, days, true)
+}
+
+// validateForApproval 审批通过前重新校验：双向换班按互换后的状态校验双方，转让校验认领人
+func (s *swapService) validateForApproval(ctx context.Context, swap *model.SwapRequest) error {
+	if swap.Kind == model.SwapKindExchange {
+		return s.validateExchange(ctx, swap)
+	}
+	if swap.DutyRecord == nil || swap.DutyRecord.Status != model.DutyRecordStatusPending ||
+		swap.DutyRecord.MemberID != swap.ApplicantID || !time.Now().Before(dutyStartTime(swap.DutyRecord)) {
+		return ErrSwapDutyNotTransferable
+	}
+	conflicts, err := s.claimConflicts(ctx, swap.DutyRecord, *swap.TargetMemberID)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("%w: %s", ErrSwapMemberUnavailable, strings.Join(conflicts, "；"))
+	}
+	return nil
+}
+
+// applyGiveAway 改派该次值班记录并写入单次变更日志（须在事务内调用）
+func (s *swapService) applyGiveAway(ctx context.Context, txRepo *repository.Repository, swap *model.SwapRequest, operatorID string) error {
+	record := swap.DutyRecord
+	affected, err := txRepo.DutyRecord.ReassignPending(ctx, record.DutyRecordID, swap.ApplicantID, *swap.TargetMemberID, operatorID)
+	if err != nil {
+		s.logger.Error("改派值班记录失败", zap.Error(err))
+		return err
+	}
+	if affected == 0 {
+		return ErrSwapDutyNotTransferable // 值班已开始或已被改派
+	}
+
+	dutyDate := record.DutyDate
+	reason := "值班转让"
+	if swap.Reason != "" {
+		reason += "：" + swap.Reason
+	}
+	changeLog := &model.ScheduleChangeLog{
+		ScheduleID:       record.ScheduleItem.ScheduleID,
+		ScheduleItemID:   swap.ScheduleItemID,
+		OriginalMemberID: swap.ApplicantID,
+		NewMemberID:      *swap.TargetMemberID,
+		DutyDate:         &dutyDate,
+		ChangeType:       "swap",
+		Reason:           reason,
+		OperatorID:       operatorID,
+		CreatedAt:        time.Now(),
+	}
+	if err := txRepo.ScheduleChangeLog.Create(ctx, changeLog); err != nil {
+		s.logger.Error("创建变更日志失败", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// giveAwayOpen 转让是否仍可认领：待认领、值班记录仍属于申请人且尚未开始
+func giveAwayOpen(swap *model.SwapRequest, now time.Time) bool {
+	record := swap.DutyRecord
+	return swap.Kind == model.SwapKindGiveAway && swap.Status == model.SwapStatusPending && swap.TargetMemberID == nil &&
+		record != nil && record.Status == model.DutyRecordStatusPending && record.MemberID == swap.ApplicantID &&
+		now.Before(dutyStartTime(record))
+}
+
+// dutyStartTime 值班记录的开始时刻（本地时区，取排班时段或活动班次）；无时段信息时取当天零点
+func dutyStartTime(record *model.DutyRecord) time.Time {
+	d := record.DutyDate
+	hour, minute := 0, 0
+	start := ""
+	if record.ScheduleItem != nil && record.ScheduleItem.TimeSlot != nil {
+		start = record.ScheduleItem.TimeSlot.StartTime
+	} else if record.EventShift != nil {
+		start = record.EventShift.StartTime
+	}
+	if t, err := time.Parse("15:04", hhmm(start)); err == nil {
+		hour, minute = t.Hour(), t.Minute()
+	}
+	return time.Date(d.Year(), d.Month(), d.Day(), hour, minute, 0, 0, time.Local)
+}
+
+// hhmm 截取 HH:MM（数据库 time 类型可能带秒）
+func hhmm(t string) string {
+	if len(t) > 5 {
+		return t[:5]
+	}
+	return t
+}
+
+func containsString(list []string, target string) bool {
+	for _, s := range list {
+		if s == target {
+			return true
+		}
+	}
+	return false
+}
+
+// ════════════════════════════════════════════════════════════
+// 通知（尽力而为：失败只记录日志，不影响已完成的业务操作）
+// ════════════════════════════════════════════════════════════
+
+// dutyDescription 值班描述，如 "2026-10-12 周一上午（08:10-10:05）@学生会办公室"
+func dutyDescription(record *model.DutyRecord) string {
+	desc := record.DutyDate.Format(model.TimeFormatDate)
+	if record.ScheduleItem != nil && record.ScheduleItem.TimeSlot != nil {
+		ts := record.ScheduleItem.TimeSlot
+		desc += fmt.Sprintf(" %s（%s-%s）", ts.Name, ts.StartTime, ts.EndTime)
+		if loc := record.ScheduleItem.Location; loc != nil {
+			desc += "@" + loc.Name
+		}
+	}
+	return desc
+}
+
+func (s *swapService) memberName(ctx context.Context, userID string) string {
+	if user, err := s.repo.User.GetByID(ctx, userID); err == nil {
+		return user.Name
+	}
+	return userID
+}
+
+func (s *swapService) sendSwapNotifications(ctx context.Context, swapID, notifType, title, content string, userIDs []string) {
+	if len(userIDs) == 0 {
+		return
+	}
+	relatedType := model.NotificationRelatedSwapRequest
+	notifications := make([]model.Notification, 0, len(userIDs))
+	for _, userID := range userIDs {
+		relatedID := swapID
+		notifications = append(notifications, model.Notification{
+			UserID:      userID,
+			Type:        notifType,
+			Title:       title,
+			Content:     content,
+			RelatedType: &relatedType,
+			RelatedID:   &relatedID,
+		})
+	}
+	if err := s.repo.Notification.BatchCreate(ctx, notifications); err != nil {
+		s.logger.Warn("发送换班通知失败", zap.Error(err))
+	}
+}
+
+// notifyGiveAway 通知所有可认领成员
+func (s *swapService) notifyGiveAway(ctx context.Context, swap *model.SwapRequest) {
+	record := swap.DutyRecord
+	schedule, err := s.repo.Schedule.GetByID(ctx, record.ScheduleItem.ScheduleID)
+	if err != nil {
+		s.logger.Warn("查询排班表失败，转让通知未发送", zap.Error(err))
+		return
+	}
+	assignments, err := s.repo.UserSemesterAssignment.ListDutyRequiredSubmitted(ctx, schedule.SemesterID)
+	if err != nil {
+		s.logger.Warn("查询值班成员失败，转让通知未发送", zap.Error(err))
+		return
+	}
+
+	var eligible []string
+	for _, a := range assignments {
+		if a.UserID == swap.ApplicantID {
+			continue
+		}
+		conflicts, err := s.claimConflicts(ctx, record, a.UserID)
+		if err != nil {
+			s.logger.Warn("校验可认领成员失败，转让通知未发送", zap.Error(err))
+			return
+		}
+		if len(conflicts) == 0 {
+			eligible = append(eligible, a.UserID)
+		}
+	}
+	content := fmt.Sprintf("%s 转让 %s 的值班，先到先得", s.memberName(ctx, swap.ApplicantID), dutyDescription(record))
+	s.sendSwapNotifications(ctx, swap.SwapRequestID, model.NotificationTypeSwapRequest, "可认领的值班转让", content, eligible)
+}
+
+// notifyClaimed 通知申请人已被认领；需审批时同时通知管理员
+func (s *swapService) notifyClaimed(ctx context.Context, swap *model.SwapRequest, claimerID string) {
+	claimer := s.memberName(ctx, claimerID)
+	desc := dutyDescription(swap.DutyRecord)
+	if swap.Status == model.SwapStatusReviewing {
+		s.sendSwapNotifications(ctx, swap.SwapRequestID, model.NotificationTypeSwapAccepted, "值班转让已被认领",
+			fmt.Sprintf("%s 认领了你 %s 的值班，待管理员审批", claimer, desc), []string{swap.ApplicantID})
+		admins, err := adminUserIDs(ctx, s.repo)
+		if err != nil {
+			s.logger.Warn("查询管理员失败，审批通知未发送", zap.Error(err))
+			return
+		}
+		s.sendSwapNotifications(ctx, swap.SwapRequestID, model.NotificationTypeSwapRequest, "值班转让待审批",
+			fmt.Sprintf("%s 认领了 %s 转让的 %s 值班，请审批", claimer, s.memberName(ctx, swap.ApplicantID), desc), admins)
+		return
+	}
+	s.sendSwapNotifications(ctx, swap.SwapRequestID, model.NotificationTypeSwapAccepted, "值班转让已生效",
+		fmt.Sprintf("%s 认领了你 %s 的值班，该次值班已转给对方%s", claimer, desc, autoApprovalNote(swap)), []string{swap.ApplicantID})
+}
+
+// notifyReviewed 通知申请人与认领人 / 对方审批结果
+func (s *swapService) notifyReviewed(ctx context.Context, swap *model.SwapRequest) {
+	recipients := []string{swap.ApplicantID, *swap.TargetMemberID}
+	if swap.Kind == model.SwapKindExchange {
+		desc := s.exchangeDescription(ctx, swap)
+		if swap.Status == model.SwapStatusCompleted {
+			s.sendSwapNotifications(ctx, swap.SwapRequestID, model.NotificationTypeSwapApproved, "换班审批通过",
+				fmt.Sprintf("换班已审批通过并完成互换：%s", desc), recipients)
+			return
+		}
+		content := fmt.Sprintf("换班未通过审批，双方值班不变：%s", desc)
+		if swap.RejectReason != "" {
+			content += "（" + swap.RejectReason + "）"
+		}
+		s.sendSwapNotifications(ctx, swap.SwapRequestID, model.NotificationTypeSwapDenied, "换班审批驳回", content, recipients)
+		return
+	}
+
+	desc := dutyDescription(swap.DutyRecord)
+	if swap.Status == model.SwapStatusCompleted {
+		s.sendSwapNotifications(ctx, swap.SwapRequestID, model.NotificationTypeSwapApproved, "换班审批通过",
+			fmt.Sprintf("%s 的值班转让已审批通过，由 %s 值班", desc, s.memberName(ctx, *swap.TargetMemberID)), recipients)
+		return
+	}
+	content := fmt.Sprintf("%s 的值班转让未通过审批，仍由 %s 值班", desc, s.memberName(ctx, swap.ApplicantID))
+	if swap.RejectReason != "" {
+		content += "（" + swap.RejectReason + "）"
+	}
+	s.sendSwapNotifications(ctx, swap.SwapRequestID, model.NotificationTypeSwapDenied, "换班审批驳回", content, recipients)
+}
+
+// toSwapRequestResponse 转换换班申请响应（需预加载两侧排班项、值班记录及其时间段、地点与成员）
+func toSwapRequestResponse(r *model.SwapRequest) dto.SwapRequestResponse {
+	resp := dto.SwapRequestResponse{
+		ID:             r.SwapRequestID,
+		Kind:           r.Kind,
+		Status:         r.Status,
+		ScheduleItemID: r.ScheduleItemID,
+		Applicant:      toMemberBrief(r.Applicant),
+		TargetMember:   toMemberBrief(r.TargetMember),
+		Reason:         r.Reason,
+		RejectReason:   r.RejectReason,
+		CreatedAt:      r.CreatedAt.Format(model.TimeFormatDateTime),
+	}
+	if r.DutyRecord != nil {
+		record := toDutyRecordResponse(r.DutyRecord)
+		resp.DutyRecord = &record
+	}
+	if r.Kind == model.SwapKindExchange {
+		resp.ScheduleItem = toSwapItemBrief(r.ScheduleItem)
+		resp.TargetScheduleItem = toSwapItemBrief(r.TargetScheduleItem)
+		if r.TargetDutyRecord != nil {
+			record := toDutyRecordResponse(r.TargetDutyRecord)
+			resp.TargetDutyRecord = &record
+		}
+	}
+	if r.TargetRespondedAt != nil {
+		at := r.TargetRespondedAt.Format(model.TimeFormatDateTime)
+		resp.RespondedAt = &at
+	}
+	if r.ApprovedAt != nil {
+		at := r.ApprovedAt.Format(model.TimeFormatDateTime)
+		resp.ApprovedAt = &at
+	}
+	resp.AutoApproved = r.ApprovedBy != nil && *r.ApprovedBy == model.SystemActorID
+	return resp
+}
@@ -0,0 +1,623 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/model"
+)
+
+// setupSwapTest 已发布排班：item-1（第 1 周周一上午 user-1）、item-2（第 1 周周一下午 user-2），
+// 新增同部门成员 user-3 与管理员；为 item-1 生成 daysAhead 天后的一次值班记录
+func setupSwapTest(t *testing.T, daysAhead int) (*testScheduleRepos, SwapService, string) {
+	t.Helper()
+	repos := newTestScheduleRepos()
+	seedBasicData(repos)
+	seedDraftItems(repos)
+	repos.schedule.schedules["sched-1"].Status = model.ScheduleStatusPublished
+	// 学期覆盖前后数周，课程按值班日的教学周判定
+	repos.semester.semesters["sem-1"].StartDate = dateOnly(time.Now().AddDate(0, 0, -14))
+	repos.semester.semesters["sem-1"].EndDate = dateOnly(time.Now().AddDate(0, 0, 120))
+
+	user3 := &model.User{UserID: "user-3", Name: "王五", StudentID: "2021003", DepartmentID: "dept-1"}
+	repos.assignment.assignments = append(repos.assignment.assignments, model.UserSemesterAssignment{
+		AssignmentID: "a-3", UserID: "user-3", SemesterID: "sem-1", DutyRequired: true, TimetableStatus: "submitted", User: user3,
+	})
+	for _, a := range repos.assignment.assignments {
+		repos.user.users[a.UserID] = a.User
+	}
+	repos.user.users["admin-1"] = &model.User{UserID: "admin-1", Name: "管理员", Role: model.RoleAdmin}
+
+	itemID := "item-1"
+	records := []model.DutyRecord{{
+		ScheduleItemID: &itemID,
+		MemberID:       "user-1",
+		DutyDate:       dateOnly(time.Now().AddDate(0, 0, daysAhead)),
+		Status:         model.DutyRecordStatusPending,
+	}}
+	if err := repos.dutyRecord.BatchCreate(context.Background(), records); err != nil {
+		t.Fatalf("创建值班记录失败: %v", err)
+	}
+	return repos, NewSwapService(repos.toRepository(), zap.NewNop()), records[0].DutyRecordID
+}
+
+func notificationsOf(repos *testScheduleRepos, userID, notifType string) int {
+	count := 0
+	for _, n := range repos.notification.notifications {
+		if n.UserID == userID && n.Type == notifType {
+			count++
+		}
+	}
+	return count
+}
+
+func TestSwapService_GiveAway_ClaimAndApprove(t *testing.T) {
+	repos, svc, recordID := setupSwapTest(t, 7)
+	ctx := context.Background()
+
+	swap, err := svc.CreateGiveAway(ctx, &dto.CreateGiveAwayRequest{DutyRecordID: recordID, Reason: "临时有事"}, "user-1")
+	if err != nil {
+		t.Fatalf("CreateGiveAway 应成功: %v", err)
+	}
+	if swap.Kind != model.SwapKindGiveAway || swap.Status != model.SwapStatusPending || swap.TargetMember != nil {
+		t.Errorf("新发布的转让应为待认领，实际 kind=%s status=%s", swap.Kind, swap.Status)
+	}
+	// user-2 周一下午已有值班（R6），只有 user-3 可认领
+	if notificationsOf(repos, "user-3", model.NotificationTypeSwapRequest) != 1 || notificationsOf(repos, "user-2", model.NotificationTypeSwapRequest) != 0 {
+		t.Error("转让通知应只发给可认领成员")
+	}
+	if feed, _ := svc.ListGiveAwayFeed(ctx, "user-3"); len(feed) != 1 {
+		t.Errorf("user-3 的转让广场应有 1 条，实际 %d", len(feed))
+	}
+	if feed, _ := svc.ListGiveAwayFeed(ctx, "user-2"); len(feed) != 0 {
+		t.Errorf("user-2 不可认领，转让广场应为空，实际 %d", len(feed))
+	}
+
+	if _, err := svc.ClaimGiveAway(ctx, swap.ID, "user-2"); !errors.Is(err, ErrSwapMemberUnavailable) {
+		t.Errorf("同日已有值班认领应返回 ErrSwapMemberUnavailable，实际: %v", err)
+	}
+	claimed, err := svc.ClaimGiveAway(ctx, swap.ID, "user-3")
+	if err != nil {
+		t.Fatalf("ClaimGiveAway 应成功: %v", err)
+	}
+	if claimed.Status != model.SwapStatusReviewing || claimed.TargetMember == nil || claimed.TargetMember.ID != "user-3" {
+		t.Errorf("需审批时认领后应为 reviewing，实际 status=%s", claimed.Status)
+	}
+	if repos.dutyRecord.records[recordID].MemberID != "user-1" {
+		t.Error("审批前不应改派值班记录")
+	}
+	if notificationsOf(repos, "admin-1", model.NotificationTypeSwapRequest) != 1 {
+		t.Error("认领后应通知管理员审批")
+	}
+
+	pending, total, err := svc.ListPending(ctx, &dto.SwapListRequest{})
+	if err != nil || total != 1 || len(pending) != 1 {
+		t.Fatalf("待审批列表应有 1 条，实际 %d（err=%v）", total, err)
+	}
+
+	approved, err := svc.Review(ctx, swap.ID, &dto.ReviewSwapRequest{Approve: true}, "admin-1")
+	if err != nil {
+		t.Fatalf("Review 应成功: %v", err)
+	}
+	if approved.Status != model.SwapStatusCompleted {
+		t.Errorf("审批通过后应为 completed，实际 %s", approved.Status)
+	}
+	if repos.dutyRecord.records[recordID].MemberID != "user-3" {
+		t.Error("审批通过后该次值班应改派给 user-3")
+	}
+	if repos.scheduleItem.items["item-1"].MemberID != "user-1" {
+		t.Error("转让只影响单次值班，排班项不应改变")
+	}
+	if len(repos.changeLog.logs) != 1 {
+		t.Fatalf("应写入 1 条变更日志，实际 %d", len(repos.changeLog.logs))
+	}
+	log := repos.changeLog.logs[0]
+	if log.ChangeType != "swap" || log.DutyDate == nil || !log.DutyDate.Equal(repos.dutyRecord.records[recordID].DutyDate) ||
+		log.OriginalMemberID != "user-1" || log.NewMemberID != "user-3" {
+		t.Errorf("变更日志内容不正确: %+v", log)
+	}
+	if notificationsOf(repos, "user-1", model.NotificationTypeSwapApproved) != 1 || notificationsOf(repos, "user-3", model.NotificationTypeSwapApproved) != 1 {
+		t.Error("审批通过应通知申请人与认领人")
+	}
+
+	if _, err := svc.Review(ctx, swap.ID, &dto.ReviewSwapRequest{Approve: true}, "admin-1"); !errors.Is(err, ErrSwapNotReviewing) {
+		t.Errorf("重复审批应返回 ErrSwapNotReviewing，实际: %v", err)
+	}
+}
+
+func TestSwapService_GiveAway_NoApproval(t *testing.T) {
+	repos, svc, recordID := setupSwapTest(t, 7)
+	repos.systemConfig.cfg.SwapRequiresApproval = false
+	ctx := context.Background()
+
+	swap, err := svc.CreateGiveAway(ctx, &dto.CreateGiveAwayRequest{DutyRecordID: recordID}, "user-1")
+	if err != nil {
+		t.Fatalf("CreateGiveAway 应成功: %v", err)
+	}
+	if _, err := svc.CreateGiveAway(ctx, &dto.CreateGiveAwayRequest{DutyRecordID: recordID}, "user-1"); !errors.Is(err, ErrSwapAlreadyOpen) {
+		t.Errorf("重复发布应返回 ErrSwapAlreadyOpen，实际: %v", err)
+	}
+	if _, err := svc.ClaimGiveAway(ctx, swap.ID, "user-1"); !errors.Is(err, ErrSwapSelfClaim) {
+		t.Errorf("认领自己的转让应返回 ErrSwapSelfClaim，实际: %v", err)
+	}
+
+	claimed, err := svc.ClaimGiveAway(ctx, swap.ID, "user-3")
+	if err != nil {
+		t.Fatalf("ClaimGiveAway 应成功: %v", err)
+	}
+	if claimed.Status != model.SwapStatusCompleted || repos.dutyRecord.records[recordID].MemberID != "user-3" {
+		t.Errorf("无需审批时认领应立即生效，实际 status=%s member=%s", claimed.Status, repos.dutyRecord.records[recordID].MemberID)
+	}
+	if len(repos.changeLog.logs) != 1 || repos.changeLog.logs[0].OperatorID != "user-3" {
+		t.Error("认领生效应写入变更日志")
+	}
+	if _, err := svc.ClaimGiveAway(ctx, swap.ID, "user-2"); !errors.Is(err, ErrSwapNotClaimable) {
+		t.Errorf("已被认领应返回 ErrSwapNotClaimable，实际: %v", err)
+	}
+}
+
+func TestSwapService_GiveAway_SurvivesCalendarEdit(t *testing.T) {
+	repos, svc, _ := setupSwapTest(t, 7)
+	repos.systemConfig.cfg.SwapRequiresApproval = false
+	ctx := context.Background()
+
+	// 按校历重新生成记录，取 user-1 的下一次值班转让给 user-3
+	repo := repos.toRepository()
+	cal, err := loadSemesterCalendar(ctx, repo, repos.semester.semesters["sem-1"])
+	if err != nil {
+		t.Fatalf("加载校历失败: %v", err)
+	}
+	if _, err := syncDutyRecords(ctx, repo, repos.schedule.schedules["sched-1"], cal, time.Now(), "admin-1"); err != nil {
+		t.Fatalf("生成值班记录失败: %v", err)
+	}
+	var target *model.DutyRecord
+	for _, r := range repos.dutyRecord.records {
+		if r.MemberID == "user-1" && r.DutyDate.After(time.Now()) && (target == nil || r.DutyDate.Before(target.DutyDate)) {
+			target = r
+		}
+	}
+	if target == nil {
+		t.Fatal("应生成 user-1 的待值班记录")
+	}
+	swap, err := svc.CreateGiveAway(ctx, &dto.CreateGiveAwayRequest{DutyRecordID: target.DutyRecordID}, "user-1")
+	if err != nil {
+		t.Fatalf("CreateGiveAway 应成功: %v", err)
+	}
+	if _, err := svc.ClaimGiveAway(ctx, swap.ID, "user-3"); err != nil {
+		t.Fatalf("ClaimGiveAway 应成功: %v", err)
+	}
+
+	// 修改其他日期的校历，不应回退已转让的值班
+	calSvc := NewCalendarService(repo, zap.NewNop())
+	holiday := target.DutyDate.AddDate(0, 0, 1).Format(model.TimeFormatDate)
+	if _, err := calSvc.UpsertDay(ctx, "sem-1", &dto.UpsertCalendarDayRequest{Date: holiday, Kind: model.CalendarDayHoliday}, "admin-1"); err != nil {
+		t.Fatalf("UpsertDay 应成功: %v", err)
+	}
+	got, ok := repos.dutyRecord.records[target.DutyRecordID]
+	if !ok || got.MemberID != "user-3" {
+		t.Errorf("校历变更后转让的值班应仍属于 user-3，实际: %+v", got)
+	}
+}
+
+func TestSwapService_GiveAway_Validation(t *testing.T) {
+	repos, svc, recordID := setupSwapTest(t, 7)
+	ctx := context.Background()
+
+	if _, err := svc.CreateGiveAway(ctx, &dto.CreateGiveAwayRequest{DutyRecordID: recordID}, "user-2"); !errors.Is(err, ErrSwapNotOwner) {
+		t.Errorf("转让他人值班应返回 ErrSwapNotOwner，实际: %v", err)
+	}
+	if _, err := svc.CreateGiveAway(ctx, &dto.CreateGiveAwayRequest{DutyRecordID: "duty-404"}, "user-1"); !errors.Is(err, ErrSwapDutyRecordNotFound) {
+		t.Errorf("值班记录不存在应返回 ErrSwapDutyRecordNotFound，实际: %v", err)
+	}
+
+	// 当天的值班距开始不足 24 小时
+	_, todaySvc, todayID := setupSwapTest(t, 0)
+	if _, err := todaySvc.CreateGiveAway(ctx, &dto.CreateGiveAwayRequest{DutyRecordID: todayID}, "user-1"); !errors.Is(err, ErrSwapDeadlinePassed) {
+		t.Errorf("超过截止时间应返回 ErrSwapDeadlinePassed，实际: %v", err)
+	}
+
+	// 临时不可用的成员不可认领
+	date := repos.dutyRecord.records[recordID].DutyDate
+	repos.unavailable.times = append(repos.unavailable.times, model.UnavailableTime{
+		UnavailableTimeID: "ut-1", UserID: "user-3", SemesterID: "sem-1", RepeatType: model.RepeatTypeOnce,
+		SpecificDate: &date, StartTime: "08:00", EndTime: "12:00", Reason: "体检",
+	})
+	swap, err := svc.CreateGiveAway(ctx, &dto.CreateGiveAwayRequest{DutyRecordID: recordID}, "user-1")
+	if err != nil {
+		t.Fatalf("CreateGiveAway 应成功: %v", err)
+	}
+	if _, err := svc.ClaimGiveAway(ctx, swap.ID, "user-3"); !errors.Is(err, ErrSwapMemberUnavailable) {
+		t.Errorf("当天临时不可用应返回 ErrSwapMemberUnavailable，实际: %v", err)
+	}
+
+	if err := svc.Cancel(ctx, swap.ID, "user-2"); !errors.Is(err, ErrSwapForbidden) {
+		t.Errorf("非申请人撤回应返回 ErrSwapForbidden，实际: %v", err)
+	}
+	if err := svc.Cancel(ctx, swap.ID, "user-1"); err != nil {
+		t.Fatalf("Cancel 应成功: %v", err)
+	}
+	if err := svc.Cancel(ctx, swap.ID, "user-1"); !errors.Is(err, ErrSwapNotCancellable) {
+		t.Errorf("重复撤回应返回 ErrSwapNotCancellable，实际: %v", err)
+	}
+	if _, err := svc.GetByID(ctx, swap.ID, "user-3", false); !errors.Is(err, ErrSwapForbidden) {
+		t.Errorf("已撤回的转让对无关成员不可见，实际: %v", err)
+	}
+}
+
+func TestSwapService_Exchange_Items(t *testing.T) {
+	repos, svc, recordID := setupSwapTest(t, 7)
+	ctx := context.Background()
+
+	swap, err := svc.CreateExchange(ctx, &dto.CreateExchangeRequest{
+		ScheduleItemID: "item-1", TargetScheduleItemID: "item-2", Reason: "上午有实验",
+	}, "user-1")
+	if err != nil {
+		t.Fatalf("CreateExchange 应成功: %v", err)
+	}
+	if swap.Kind != model.SwapKindExchange || swap.Status != model.SwapStatusPending ||
+		swap.TargetMember == nil || swap.TargetMember.ID != "user-2" || swap.TargetScheduleItem == nil {
+		t.Errorf("新发起的换班应待 user-2 响应，实际 %+v", swap)
+	}
+	if notificationsOf(repos, "user-2", model.NotificationTypeSwapRequest) != 1 {
+		t.Error("发起换班应通知对方")
+	}
+	if _, err := svc.CreateExchange(ctx, &dto.CreateExchangeRequest{ScheduleItemID: "item-1", TargetScheduleItemID: "item-2"}, "user-1"); !errors.Is(err, ErrSwapAlreadyOpen) {
+		t.Errorf("重复发起应返回 ErrSwapAlreadyOpen，实际: %v", err)
+	}
+
+	if _, err := svc.Respond(ctx, swap.ID, &dto.RespondSwapRequest{Accept: true}, "user-3"); !errors.Is(err, ErrSwapForbidden) {
+		t.Errorf("非对方响应应返回 ErrSwapForbidden，实际: %v", err)
+	}
+	accepted, err := svc.Respond(ctx, swap.ID, &dto.RespondSwapRequest{Accept: true}, "user-2")
+	if err != nil {
+		t.Fatalf("Respond 应成功: %v", err)
+	}
+	if accepted.Status != model.SwapStatusReviewing || repos.scheduleItem.items["item-1"].MemberID != "user-1" {
+		t.Errorf("需审批时接受后应为 reviewing 且暂不互换，实际 status=%s", accepted.Status)
+	}
+	if notificationsOf(repos, "user-1", model.NotificationTypeSwapAccepted) != 1 || notificationsOf(repos, "admin-1", model.NotificationTypeSwapRequest) != 1 {
+		t.Error("接受后应通知申请人与管理员")
+	}
+
+	approved, err := svc.Review(ctx, swap.ID, &dto.ReviewSwapRequest{Approve: true}, "admin-1")
+	if err != nil {
+		t.Fatalf("Review 应成功: %v", err)
+	}
+	if approved.Status != model.SwapStatusCompleted {
+		t.Errorf("审批通过后应为 completed，实际 %s", approved.Status)
+	}
+	if repos.scheduleItem.items["item-1"].MemberID != "user-2" || repos.scheduleItem.items["item-2"].MemberID != "user-1" {
+		t.Error("审批通过后两个排班项的成员应互换")
+	}
+	if repos.dutyRecord.records[recordID].MemberID != "user-2" {
+		t.Error("互换排班项后尚未开始的值班记录应随之改派")
+	}
+	if len(repos.changeLog.logs) != 2 {
+		t.Fatalf("应写入成对的 2 条变更日志，实际 %d", len(repos.changeLog.logs))
+	}
+	for _, log := range repos.changeLog.logs {
+		if log.ChangeType != "swap" || log.DutyDate != nil || log.OperatorID != "admin-1" {
+			t.Errorf("变更日志内容不正确: %+v", log)
+		}
+	}
+	if a, b := repos.changeLog.logs[0], repos.changeLog.logs[1]; a.OriginalMemberID != b.NewMemberID || a.NewMemberID != b.OriginalMemberID {
+		t.Errorf("两条变更日志应互为对方: %+v / %+v", a, b)
+	}
+	if notificationsOf(repos, "user-2", model.NotificationTypeSwapApproved) != 1 {
+		t.Error("审批通过应通知双方")
+	}
+}
+
+func TestSwapService_Exchange_Occurrences(t *testing.T) {
+	repos, svc, recordID := setupSwapTest(t, 7)
+	repos.systemConfig.cfg.SwapRequiresApproval = false
+	ctx := context.Background()
+
+	itemID := "item-2"
+	records := []model.DutyRecord{{
+		ScheduleItemID: &itemID,
+		MemberID:       "user-2",
+		DutyDate:       repos.dutyRecord.records[recordID].DutyDate,
+		Status:         model.DutyRecordStatusPending,
+	}}
+	if err := repos.dutyRecord.BatchCreate(ctx, records); err != nil {
+		t.Fatalf("创建值班记录失败: %v", err)
+	}
+	targetID := records[0].DutyRecordID
+
+	swap, err := svc.CreateExchange(ctx, &dto.CreateExchangeRequest{DutyRecordID: recordID, TargetDutyRecordID: targetID}, "user-1")
+	if err != nil {
+		t.Fatalf("CreateExchange 应成功: %v", err)
+	}
+	if _, err := svc.CreateGiveAway(ctx, &dto.CreateGiveAwayRequest{DutyRecordID: targetID}, "user-2"); !errors.Is(err, ErrSwapAlreadyOpen) {
+		t.Errorf("对方的值班已在换班中，再发布转让应返回 ErrSwapAlreadyOpen，实际: %v", err)
+	}
+
+	completed, err := svc.Respond(ctx, swap.ID, &dto.RespondSwapRequest{Accept: true}, "user-2")
+	if err != nil {
+		t.Fatalf("Respond 应成功: %v", err)
+	}
+	if completed.Status != model.SwapStatusCompleted {
+		t.Errorf("无需审批时接受应立即生效，实际 %s", completed.Status)
+	}
+	if repos.dutyRecord.records[recordID].MemberID != "user-2" || repos.dutyRecord.records[targetID].MemberID != "user-1" {
+		t.Error("两次值班的成员应互换")
+	}
+	if repos.scheduleItem.items["item-1"].MemberID != "user-1" || repos.scheduleItem.items["item-2"].MemberID != "user-2" {
+		t.Error("互换单次值班不应改变排班项")
+	}
+	if len(repos.changeLog.logs) != 2 {
+		t.Fatalf("应写入成对的 2 条变更日志，实际 %d", len(repos.changeLog.logs))
+	}
+	for _, log := range repos.changeLog.logs {
+		if log.DutyDate == nil || log.OperatorID != "user-2" {
+			t.Errorf("单次互换的变更日志应带 duty_date: %+v", log)
+		}
+	}
+}
+
+func TestSwapService_Exchange_Validation(t *testing.T) {
+	repos, svc, recordID := setupSwapTest(t, 7)
+	ctx := context.Background()
+
+	if _, err := svc.CreateExchange(ctx, &dto.CreateExchangeRequest{ScheduleItemID: "item-1", TargetDutyRecordID: recordID}, "user-1"); !errors.Is(err, ErrSwapExchangeInvalid) {
+		t.Errorf("混用排班项与值班记录应返回 ErrSwapExchangeInvalid，实际: %v", err)
+	}
+	if _, err := svc.CreateExchange(ctx, &dto.CreateExchangeRequest{ScheduleItemID: "item-2", TargetScheduleItemID: "item-1"}, "user-1"); !errors.Is(err, ErrSwapNotOwner) {
+		t.Errorf("以他人排班项发起应返回 ErrSwapNotOwner，实际: %v", err)
+	}
+
+	// 互换后 user-2 周一上午有课
+	repos.courseSchedule.courses = []model.CourseSchedule{{
+		UserID: "user-2", SemesterID: "sem-1", CourseName: "高等数学",
+		DayOfWeek: 1, StartTime: "08:00", EndTime: "09:40", WeekType: model.WeekTypeAll,
+	}}
+	_, err := svc.CreateExchange(ctx, &dto.CreateExchangeRequest{ScheduleItemID: "item-1", TargetScheduleItemID: "item-2"}, "user-1")
+	if !errors.Is(err, ErrSwapMemberUnavailable) || !strings.Contains(err.Error(), "李四") {
+		t.Errorf("互换后对方课表冲突应返回 ErrSwapMemberUnavailable 并指明成员，实际: %v", err)
+	}
+	repos.courseSchedule.courses = nil
+
+	// 拒绝
+	swap, err := svc.CreateExchange(ctx, &dto.CreateExchangeRequest{ScheduleItemID: "item-1", TargetScheduleItemID: "item-2"}, "user-1")
+	if err != nil {
+		t.Fatalf("CreateExchange 应成功: %v", err)
+	}
+	rejected, err := svc.Respond(ctx, swap.ID, &dto.RespondSwapRequest{Reason: "下午有课"}, "user-2")
+	if err != nil || rejected.Status != model.SwapStatusRejected || rejected.RejectReason != "下午有课" {
+		t.Fatalf("拒绝应成功，实际 %+v（err=%v）", rejected, err)
+	}
+	if notificationsOf(repos, "user-1", model.NotificationTypeSwapRejected) != 1 {
+		t.Error("拒绝应通知申请人")
+	}
+	if _, err := svc.Respond(ctx, swap.ID, &dto.RespondSwapRequest{Accept: true}, "user-2"); !errors.Is(err, ErrSwapNotPending) {
+		t.Errorf("重复响应应返回 ErrSwapNotPending，实际: %v", err)
+	}
+
+	// 响应前对方排班项已被管理员改派
+	swap, err = svc.CreateExchange(ctx, &dto.CreateExchangeRequest{ScheduleItemID: "item-1", TargetScheduleItemID: "item-2"}, "user-1")
+	if err != nil {
+		t.Fatalf("CreateExchange 应成功: %v", err)
+	}
+	repos.scheduleItem.items["item-2"].MemberID = "user-3"
+	if _, err := svc.Respond(ctx, swap.ID, &dto.RespondSwapRequest{Accept: true}, "user-2"); !errors.Is(err, ErrSwapOutdated) {
+		t.Errorf("排班已变更应返回 ErrSwapOutdated，实际: %v", err)
+	}
+	if err := svc.Cancel(ctx, swap.ID, "user-1"); err != nil {
+		t.Fatalf("Cancel 应成功: %v", err)
+	}
+
+	// R6：互换后 user-3 同一天已有其他班次
+	repos.scheduleItem.items["item-2"].MemberID = "user-2"
+	repos.scheduleItem.items["item-3"] = &model.ScheduleItem{
+		ScheduleItemID: "item-3", ScheduleID: "sched-1", WeekNumber: 1,
+		TimeSlotID: "ts-1", MemberID: "user-3", TimeSlot: repos.timeSlot.slots["ts-1"],
+	}
+	repos.scheduleItem.items["item-4"] = &model.ScheduleItem{
+		ScheduleItemID: "item-4", ScheduleID: "sched-1", WeekNumber: 2,
+		TimeSlotID: "ts-2", MemberID: "user-3", TimeSlot: repos.timeSlot.slots["ts-2"],
+	}
+	_, err = svc.CreateExchange(ctx, &dto.CreateExchangeRequest{ScheduleItemID: "item-4", TargetScheduleItemID: "item-2"}, "user-3")
+	if !errors.Is(err, ErrSwapMemberUnavailable) || !strings.Contains(err.Error(), "同人同日重复排班") {
+		t.Errorf("互换后违反 R6 应返回 ErrSwapMemberUnavailable，实际: %v", err)
+	}
+}
+
+func TestSwapService_Suggest(t *testing.T) {
+	repos, svc, recordID := setupSwapTest(t, 7)
+	ctx := context.Background()
+
+	// user-3 周二上午有值班；user-4 无值班，周一上午的课只在第 1 教学周（已结束）
+	semID := "sem-1"
+	repos.timeSlot.slots["ts-3"] = &model.TimeSlot{
+		TimeSlotID: "ts-3", Name: "周二上午", SemesterID: &semID,
+		DayOfWeek: 2, StartTime: "08:10", EndTime: "10:05", IsActive: true,
+	}
+	repos.scheduleItem.items["item-3"] = &model.ScheduleItem{
+		ScheduleItemID: "item-3", ScheduleID: "sched-1", WeekNumber: 1,
+		TimeSlotID: "ts-3", MemberID: "user-3", TimeSlot: repos.timeSlot.slots["ts-3"],
+	}
+	user4 := &model.User{UserID: "user-4", Name: "赵六", StudentID: "2021004", DepartmentID: "dept-2"}
+	repos.user.users["user-4"] = user4
+	repos.assignment.assignments = append(repos.assignment.assignments, model.UserSemesterAssignment{
+		AssignmentID: "a-4", UserID: "user-4", SemesterID: "sem-1", DutyRequired: true, TimetableStatus: "submitted", User: user4,
+	})
+	repos.courseSchedule.courses = []model.CourseSchedule{{
+		UserID: "user-4", SemesterID: "sem-1", CourseName: "军事理论",
+		DayOfWeek: 1, StartTime: "08:00", EndTime: "09:40", WeekType: model.WeekTypeOdd, Weeks: model.IntArray{1},
+	}}
+	itemID := "item-3"
+	records := []model.DutyRecord{{
+		ScheduleItemID: &itemID,
+		MemberID:       "user-3",
+		DutyDate:       dateOnly(time.Now().AddDate(0, 0, 8)),
+		Status:         model.DutyRecordStatusPending,
+	}}
+	if err := repos.dutyRecord.BatchCreate(ctx, records); err != nil {
+		t.Fatalf("创建值班记录失败: %v", err)
+	}
+
+	if _, err := svc.Suggest(ctx, &dto.SwapSuggestionRequest{}, "user-1", false); !errors.Is(err, ErrSwapSuggestionTarget) {
+		t.Errorf("未指定查询对象应返回 ErrSwapSuggestionTarget，实际: %v", err)
+	}
+	if _, err := svc.Suggest(ctx, &dto.SwapSuggestionRequest{ItemID: "item-1"}, "user-2", false); !errors.Is(err, ErrSwapNotOwner) {
+		t.Errorf("非值班本人查询应返回 ErrSwapNotOwner，实际: %v", err)
+	}
+
+	// 排班项：user-2 同日已有值班被排除；user-4 负荷最低排在前面
+	suggestions, err := svc.Suggest(ctx, &dto.SwapSuggestionRequest{ItemID: "item-1"}, "user-1", false)
+	if err != nil {
+		t.Fatalf("Suggest 应成功: %v", err)
+	}
+	if len(suggestions) != 2 || suggestions[0].Member.ID != "user-4" || suggestions[1].Member.ID != "user-3" {
+		t.Fatalf("候选人应为 [user-4 user-3]，实际 %+v", suggestions)
+	}
+	if suggestions[0].Load != 0 || suggestions[1].ShiftCount != 1 || suggestions[1].Load <= 0 {
+		t.Errorf("负荷统计不正确: %+v", suggestions)
+	}
+	if len(suggestions[1].ReturnItems) != 1 || suggestions[1].ReturnItems[0].ID != "item-3" || len(suggestions[0].ReturnItems) != 0 {
+		t.Errorf("user-3 应可回换 item-3，实际 %+v", suggestions[1].ReturnItems)
+	}
+
+	// 具体日期的值班：管理员也可查询；回换列出 user-3 的单次值班
+	suggestions, err = svc.Suggest(ctx, &dto.SwapSuggestionRequest{DutyRecordID: recordID}, "admin-1", true)
+	if err != nil {
+		t.Fatalf("Suggest 应成功: %v", err)
+	}
+	if len(suggestions) != 2 || suggestions[1].Member.ID != "user-3" {
+		t.Fatalf("候选人应为 [user-4 user-3]，实际 %+v", suggestions)
+	}
+	if len(suggestions[1].ReturnDutyRecords) != 1 || suggestions[1].ReturnDutyRecords[0].ID != records[0].DutyRecordID {
+		t.Errorf("user-3 应可回换其单次值班，实际 %+v", suggestions[1].ReturnDutyRecords)
+	}
+}
+
+func TestSwapService_AutoApproval(t *testing.T) {
+	ctx := context.Background()
+
+	// enableAutoApproval 开启全局自动审批：同部门、提前 48 小时、每学期至多 1 次
+	enableAutoApproval := func(repos *testScheduleRepos) {
+		cfg := repos.systemConfig.cfg
+		cfg.SwapAutoApprove = true
+		cfg.SwapAutoApproveSameDepartment = true
+		cfg.SwapAutoApproveMinHours = 48
+		cfg.SwapAutoApproveMaxPerSemester = 1
+	}
+	// addRecord 为 item-1 追加 daysAhead 天后的一次值班记录
+	addRecord := func(t *testing.T, repos *testScheduleRepos, daysAhead int) string {
+		t.Helper()
+		itemID := "item-1"
+		records := []model.DutyRecord{{
+			ScheduleItemID: &itemID,
+			MemberID:       "user-1",
+			DutyDate:       dateOnly(time.Now().AddDate(0, 0, daysAhead)),
+			Status:         model.DutyRecordStatusPending,
+		}}
+		if err := repos.dutyRecord.BatchCreate(ctx, records); err != nil {
+			t.Fatalf("创建值班记录失败: %v", err)
+		}
+		return records[0].DutyRecordID
+	}
+	// giveAwayClaimed user-1 发布转让并由 claimer 认领
+	giveAwayClaimed := func(t *testing.T, svc SwapService, recordID, claimer string) *dto.SwapRequestResponse {
+		t.Helper()
+		swap, err := svc.CreateGiveAway(ctx, &dto.CreateGiveAwayRequest{DutyRecordID: recordID}, "user-1")
+		if err != nil {
+			t.Fatalf("CreateGiveAway 应成功: %v", err)
+		}
+		claimed, err := svc.ClaimGiveAway(ctx, swap.ID, claimer)
+		if err != nil {
+			t.Fatalf("ClaimGiveAway 应成功: %v", err)
+		}
+		return claimed
+	}
+
+	t.Run("满足策略由系统账号审批通过", func(t *testing.T) {
+		repos, svc, recordID := setupSwapTest(t, 7)
+		enableAutoApproval(repos)
+
+		claimed := giveAwayClaimed(t, svc, recordID, "user-3")
+		if claimed.Status != model.SwapStatusCompleted || !claimed.AutoApproved {
+			t.Fatalf("满足策略应自动通过，实际 status=%s auto=%v", claimed.Status, claimed.AutoApproved)
+		}
+		stored := repos.swap.swaps[claimed.ID]
+		if stored.ApprovedBy == nil || *stored.ApprovedBy != model.SystemActorID || stored.ApprovedAt == nil {
+			t.Error("自动审批应记录系统账号为审批人")
+		}
+		if repos.dutyRecord.records[recordID].MemberID != "user-3" || len(repos.changeLog.logs) != 1 {
+			t.Error("自动审批后应改派值班并写入变更日志")
+		}
+		if notificationsOf(repos, "admin-1", model.NotificationTypeSwapRequest) != 0 {
+			t.Error("自动审批不应再通知管理员审批")
+		}
+
+		// 本学期已完成 1 次，达到上限后转人工审批
+		second := giveAwayClaimed(t, svc, addRecord(t, repos, 14), "user-3")
+		if second.Status != model.SwapStatusReviewing || second.AutoApproved {
+			t.Errorf("超过学期换班次数应进入人工审批，实际 status=%s", second.Status)
+		}
+	})
+
+	t.Run("跨部门或临近值班进入人工审批", func(t *testing.T) {
+		repos, svc, recordID := setupSwapTest(t, 7)
+		enableAutoApproval(repos)
+		repos.systemConfig.cfg.SwapAutoApproveMaxPerSemester = 0
+		user4 := &model.User{UserID: "user-4", Name: "赵六", StudentID: "2021004", DepartmentID: "dept-2"}
+		repos.user.users["user-4"] = user4
+		repos.assignment.assignments = append(repos.assignment.assignments, model.UserSemesterAssignment{
+			AssignmentID: "a-4", UserID: "user-4", SemesterID: "sem-1", DutyRequired: true, TimetableStatus: "submitted", User: user4,
+		})
+
+		if claimed := giveAwayClaimed(t, svc, recordID, "user-4"); claimed.Status != model.SwapStatusReviewing {
+			t.Errorf("跨部门认领应进入人工审批，实际 %s", claimed.Status)
+		}
+
+		repos.systemConfig.cfg.SwapAutoApproveMinHours = 24 * 30
+		if claimed := giveAwayClaimed(t, svc, addRecord(t, repos, 14), "user-3"); claimed.Status != model.SwapStatusReviewing {
+			t.Errorf("未满足提前小时数应进入人工审批，实际 %s", claimed.Status)
+		}
+	})
+
+	t.Run("部门策略覆盖全局策略", func(t *testing.T) {
+		repos, svc, recordID := setupSwapTest(t, 7)
+		enableAutoApproval(repos)
+		repos.department.departments["dept-1"] = &model.Department{DepartmentID: "dept-1", Name: "技术部"}
+
+		if _, err := svc.SetDepartmentPolicy(ctx, "dept-x", &dto.SetSwapPolicyRequest{}, "admin-1"); !errors.Is(err, ErrSwapPolicyDepartmentNotFound) {
+			t.Errorf("不存在的部门应返回 ErrSwapPolicyDepartmentNotFound，实际: %v", err)
+		}
+		policy, err := svc.SetDepartmentPolicy(ctx, "dept-1", &dto.SetSwapPolicyRequest{AutoApprove: false}, "admin-1")
+		if err != nil || policy.Department == nil || policy.Department.Name != "技术部" {
+			t.Fatalf("SetDepartmentPolicy 应成功: %v", err)
+		}
+		if claimed := giveAwayClaimed(t, svc, recordID, "user-3"); claimed.Status != model.SwapStatusReviewing {
+			t.Errorf("部门关闭自动审批时应进入人工审批，实际 %s", claimed.Status)
+		}
+
+		// 全局关闭、部门开启（不要求同部门）
+		repos.systemConfig.cfg.SwapAutoApprove = false
+		if _, err := svc.SetDepartmentPolicy(ctx, "dept-1", &dto.SetSwapPolicyRequest{AutoApprove: true, MinHoursBefore: 24}, "admin-1"); err != nil {
+			t.Fatalf("SetDepartmentPolicy 应成功: %v", err)
+		}
+		if claimed := giveAwayClaimed(t, svc, addRecord(t, repos, 14), "user-3"); !claimed.AutoApproved {
+			t.Errorf("部门开启自动审批时应自动通过，实际 status=%s", claimed.Status)
+		}
+
+		policies, err := svc.ListPolicies(ctx)
+		if err != nil || policies.Global.AutoApprove || len(policies.Departments) != 1 || !policies.Departments[0].AutoApprove {
+			t.Errorf("策略列表应含全局策略与 1 条部门覆盖，实际 %+v（err=%v）", policies, err)
+		}
+		if err := svc.DeleteDepartmentPolicy(ctx, "dept-1"); err != nil {
+			t.Fatalf("DeleteDepartmentPolicy 应成功: %v", err)
+		}
+		if err := svc.DeleteDepartmentPolicy(ctx, "dept-1"); !errors.Is(err, ErrSwapPolicyNotFound) {
+			t.Errorf("重复删除应返回 ErrSwapPolicyNotFound，实际: %v", err)
+		}
+	})
+}
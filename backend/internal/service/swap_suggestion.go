@@ -0,0 +1,303 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+
+	"echo-union/backend/internal/dto"
+	"echo-union/backend/internal/model"
+)
+
+// maxReturnShifts 每位候选人最多列出的回换值班数
+const maxReturnShifts = 5
+
+// ── 换班可用性校验 ──
+//
+// 换班针对具体的值班日：课程按值班日所在教学周的精确周次判定（取代按单双周的 R1），
+// 其余模板层面的约束（不可用时间、技能、R6 同日、搭配、休息）与手工调整排班一致。
+
+// swapContext 换班校验所需的排班上下文（同一排班表的多次校验共用）
+type swapContext struct {
+	schedule   *model.Schedule
+	semester   *model.Semester
+	calendar   *semesterCalendar
+	rules      map[string]bool // R1 已关闭，由按精确周次的课程校验替代
+	courseRule bool            // R1 是否启用
+	allItems   []model.ScheduleItem
+}
+
+func (s *swapService) loadSwapContext(ctx context.Context, scheduleID string) (*swapContext, error) {
+	schedule, err := s.repo.Schedule.GetByID(ctx, scheduleID)
+	if err != nil {
+		s.logger.Error("查询排班表失败", zap.Error(err))
+		return nil, err
+	}
+	semester, err := s.repo.Semester.GetByID(ctx, schedule.SemesterID)
+	if err != nil {
+		s.logger.Error("查询学期失败", zap.Error(err))
+		return nil, err
+	}
+	cal, err := loadSemesterCalendar(ctx, s.repo, semester)
+	if err != nil {
+		s.logger.Error("查询校历失败", zap.Error(err))
+		return nil, err
+	}
+	allItems, err := s.repo.ScheduleItem.ListBySchedule(ctx, scheduleID)
+	if err != nil {
+		s.logger.Error("查询排班项失败", zap.Error(err))
+		return nil, err
+	}
+
+	rules := s.schedule.enabledRules(ctx)
+	courseRule := rules["R1"]
+	rules["R1"] = false
+	return &swapContext{
+		schedule:   schedule,
+		semester:   semester,
+		calendar:   cal,
+		rules:      rules,
+		courseRule: courseRule,
+		allItems:   allItems,
+	}, nil
+}
+
+// occurrenceDays 排班项自 from（含）起的各次值班日（按校历换算，含调休）
+func (sc *swapContext) occurrenceDays(item *model.ScheduleItem, from time.Time) []calendarDay {
+	if item.TimeSlot == nil {
+		return nil
+	}
+	var days []calendarDay
+	for _, day := range sc.calendar.days(from, sc.calendar.endDate) {
+		if day.dutyDay && day.cycleWeek == item.WeekNumber && day.dayOfWeek == item.TimeSlot.DayOfWeek {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+// memberLoads 各成员当前的周常值班负荷
+func (sc *swapContext) memberLoads() map[string]*memberLoad {
+	loads := make(map[string]*memberLoad)
+	for _, item := range sc.allItems {
+		if item.TimeSlot == nil {
+			continue
+		}
+		if loads[item.MemberID] == nil {
+			loads[item.MemberID] = &memberLoad{}
+		}
+		loads[item.MemberID].add(*item.TimeSlot)
+	}
+	return loads
+}
+
+// memberConflicts 成员接手 item 在 days 各日值班的冲突原因，allItems 为接手后的排班项全集。
+// dated 为 true 时（具体日期的值班）另校验当天临时不可用与当天的其他值班（excludeRecordIDs 除外）。
+func (s *swapService) memberConflicts(ctx context.Context, sc *swapContext, memberID string, item *model.ScheduleItem,
+	allItems []model.ScheduleItem, days []calendarDay, dated bool, excludeRecordIDs ...string) ([]string, error) {
+	conflicts := s.schedule.memberSlotConflicts(ctx, memberID, sc.semester, sc.rules, item, allItems)
+	ts := item.TimeSlot
+
+	if sc.courseRule && ts != nil {
+		courses, err := s.repo.CourseSchedule.ListByUserAndSemester(ctx, memberID, sc.semester.SemesterID)
+		if err != nil {
+			s.logger.Error("查询课表失败", zap.Error(err))
+			return nil, err
+		}
+		for _, c := range courses {
+			if !(c.StartTime < ts.EndTime && ts.StartTime < c.EndTime) {
+				continue
+			}
+			for _, day := range days {
+				if c.DayOfWeek == day.dayOfWeek && courseRunsInWeek(c, day) {
+					conflicts = append(conflicts, "课程冲突: "+c.CourseName)
+					break
+				}
+			}
+		}
+	}
+
+	if !dated {
+		return conflicts, nil
+	}
+	uts, err := s.repo.UnavailableTime.ListByUserAndSemester(ctx, memberID, sc.semester.SemesterID)
+	if err != nil {
+		s.logger.Error("查询不可用时间失败", zap.Error(err))
+		return nil, err
+	}
+	for _, day := range days {
+		if ut := onceOffConflict(uts, day.date, ts); ut != nil {
+			conflicts = append(conflicts, substituteReason(ut))
+		}
+
+		// 当天已有其他值班（含此前认领 / 互换来的值班）
+		others, err := s.repo.DutyRecord.ListPendingByMemberAndDate(ctx, sc.schedule.ScheduleID, memberID, day.date)
+		if err != nil {
+			s.logger.Error("查询成员当日值班失败", zap.Error(err))
+			return nil, err
+		}
+		for _, other := range others {
+			if containsString(excludeRecordIDs, other.DutyRecordID) {
+				continue
+			}
+			if !containsString(conflicts, "同人同日重复排班") {
+				conflicts = append(conflicts, "同人同日重复排班")
+			}
+			break
+		}
+	}
+	return conflicts, nil
+}
+
+// ════════════════════════════════════════════════════════════
+// Suggest — 换班候选人推荐
+// ════════════════════════════════════════════════════════════
+
+// Suggest 列出可接手排班项（此后每周）或某次值班的成员，按当前周常值班负荷从低到高排序；
+// 同时列出申请人可回换的该成员值班，供发起双向换班。仅值班本人与管理员可查询。
+func (s *swapService) Suggest(ctx context.Context, req *dto.SwapSuggestionRequest, callerID string, isAdmin bool) ([]dto.SwapSuggestionResponse, error) {
+	if (req.ItemID == "") == (req.DutyRecordID == "") {
+		return nil, ErrSwapSuggestionTarget
+	}
+
+	var side exchangeSide
+	if req.ItemID != "" {
+		item, err := s.getScheduleItem(ctx, req.ItemID)
+		if err != nil {
+			return nil, err
+		}
+		side = exchangeSide{memberID: item.MemberID, item: item}
+	} else {
+		record, err := s.getDutyRecord(ctx, req.DutyRecordID)
+		if err != nil {
+			return nil, err
+		}
+		if record.ScheduleItem == nil || record.Status != model.DutyRecordStatusPending {
+			return nil, ErrSwapDutyNotTransferable
+		}
+		side = exchangeSide{memberID: record.MemberID, item: record.ScheduleItem, record: record}
+	}
+	if !isAdmin && side.memberID != callerID {
+		return nil, ErrSwapNotOwner
+	}
+	if side.item.TimeSlot == nil {
+		return nil, ErrSwapDutyNotTransferable
+	}
+
+	sc, err := s.loadSwapContext(ctx, side.item.ScheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if sc.schedule.Status != model.ScheduleStatusPublished {
+		return nil, ErrSwapDutyNotTransferable
+	}
+	cfg, err := s.repo.SystemConfig.Get(ctx)
+	if err != nil {
+		s.logger.Error("查询系统配置失败", zap.Error(err))
+		return nil, err
+	}
+	deadline := time.Duration(cfg.SwapDeadlineHours) * time.Hour
+
+	var days []calendarDay
+	if side.record != nil {
+		days = []calendarDay{sc.calendar.resolve(side.record.DutyDate)}
+	} else {
+		days = sc.occurrenceDays(side.item, time.Now())
+	}
+
+	loads := sc.memberLoads()
+
+	assignments, err := s.repo.UserSemesterAssignment.ListDutyRequiredSubmitted(ctx, sc.schedule.SemesterID)
+	if err != nil {
+		s.logger.Error("查询值班成员失败", zap.Error(err))
+		return nil, err
+	}
+
+	result := make([]dto.SwapSuggestionResponse, 0)
+	for _, a := range assignments {
+		if a.UserID == side.memberID {
+			continue
+		}
+		conflicts, err := s.memberConflicts(ctx, sc, a.UserID, side.item, sc.allItems, days, side.record != nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(conflicts) > 0 {
+			continue
+		}
+
+		suggestion := dto.SwapSuggestionResponse{Member: toMemberBrief(a.User)}
+		if load := loads[a.UserID]; load != nil {
+			suggestion.ShiftCount = load.shifts
+			suggestion.Load = round2(load.cost)
+		}
+		if side.record != nil {
+			suggestion.ReturnDutyRecords, err = s.returnDutyRecords(ctx, sc, side, a.UserID, deadline)
+		} else {
+			suggestion.ReturnItems, err = s.returnItems(ctx, sc, side, a.UserID)
+		}
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, suggestion)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Load != result[j].Load {
+			return result[i].Load < result[j].Load
+		}
+		return result[i].ShiftCount < result[j].ShiftCount
+	})
+	return result, nil
+}
+
+// returnItems 候选人的排班项中，可与 side 互换（双方均无冲突）的部分
+func (s *swapService) returnItems(ctx context.Context, sc *swapContext, side exchangeSide, memberID string) ([]dto.SwapItemBrief, error) {
+	var result []dto.SwapItemBrief
+	for i := range sc.allItems {
+		item := &sc.allItems[i]
+		if item.MemberID != memberID || item.TimeSlot == nil {
+			continue
+		}
+		conflicts, err := s.exchangeConflictsIn(ctx, sc, side, exchangeSide{memberID: memberID, item: item})
+		if err != nil {
+			return nil, err
+		}
+		if len(conflicts) == 0 {
+			result = append(result, *toSwapItemBrief(item))
+			if len(result) == maxReturnShifts {
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+// returnDutyRecords 候选人尚未超过换班截止时间的值班中，可与 side 互换的最近几次
+func (s *swapService) returnDutyRecords(ctx context.Context, sc *swapContext, side exchangeSide, memberID string, deadline time.Duration) ([]dto.DutyRecordResponse, error) {
+	records, err := s.repo.DutyRecord.ListPendingByMemberFrom(ctx, sc.schedule.ScheduleID, memberID, dateOnly(time.Now()))
+	if err != nil {
+		s.logger.Error("查询成员值班记录失败", zap.Error(err))
+		return nil, err
+	}
+	var result []dto.DutyRecordResponse
+	for i := range records {
+		record := &records[i]
+		if record.ScheduleItem == nil || record.ScheduleItem.TimeSlot == nil || time.Until(dutyStartTime(record)) < deadline {
+			continue
+		}
+		conflicts, err := s.exchangeConflictsIn(ctx, sc, side, exchangeSide{memberID: memberID, item: record.ScheduleItem, record: record})
+		if err != nil {
+			return nil, err
+		}
+		if len(conflicts) == 0 {
+			result = append(result, toDutyRecordResponse(record))
+			if len(result) == maxReturnShifts {
+				break
+			}
+		}
+	}
+	return result, nil
+}
@@ -46,12 +46,24 @@ func (s *systemConfigService) Get(ctx context.Context) (*dto.SystemConfigRespons
 	}
 
 	return &dto.SystemConfigResponse{
-		SwapDeadlineHours:    cfg.SwapDeadlineHours,
-		DutyReminderTime:     cfg.DutyReminderTime,
-		DefaultLocation:      cfg.DefaultLocation,
-		SignInWindowMinutes:  cfg.SignInWindowMinutes,
-		SignOutWindowMinutes: cfg.SignOutWindowMinutes,
-		UpdatedAt:            cfg.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+		SwapDeadlineHours:             cfg.SwapDeadlineHours,
+		DutyReminderTime:              cfg.DutyReminderTime,
+		DefaultLocation:               cfg.DefaultLocation,
+		SignInWindowMinutes:           cfg.SignInWindowMinutes,
+		SignOutWindowMinutes:          cfg.SignOutWindowMinutes,
+		TimetableConflictPolicy:       cfg.TimetableConflictPolicy,
+		MaxShiftsPerWeek:              cfg.MaxShiftsPerWeek,
+		MaxShiftsPerWeekMode:          cfg.MaxShiftsPerWeekMode,
+		MinRestHours:                  cfg.MinRestHours,
+		MinRestMode:                   cfg.MinRestMode,
+		BackToBackDaysMode:            cfg.BackToBackDaysMode,
+		SwapRequiresApproval:          cfg.SwapRequiresApproval,
+		SwapAutoApprove:               cfg.SwapAutoApprove,
+		SwapAutoApproveSameDepartment: cfg.SwapAutoApproveSameDepartment,
+		SwapAutoApproveMinHours:       cfg.SwapAutoApproveMinHours,
+		SwapAutoApproveMaxPerSemester: cfg.SwapAutoApproveMaxPerSemester,
+		EmergencySubstituteEnabled:    cfg.EmergencySubstituteEnabled,
+		UpdatedAt:                     cfg.UpdatedAt.Format("2006-01-02T15:04:05Z"),
 	}, nil
 }
 
@@ -82,6 +94,42 @@ func (s *systemConfigService) Update(ctx context.Context, req *dto.UpdateSystemC
 	if req.SignOutWindowMinutes != nil {
 		cfg.SignOutWindowMinutes = *req.SignOutWindowMinutes
 	}
+	if req.TimetableConflictPolicy != nil {
+		cfg.TimetableConflictPolicy = *req.TimetableConflictPolicy
+	}
+	if req.MaxShiftsPerWeek != nil {
+		cfg.MaxShiftsPerWeek = *req.MaxShiftsPerWeek
+	}
+	if req.MaxShiftsPerWeekMode != nil {
+		cfg.MaxShiftsPerWeekMode = *req.MaxShiftsPerWeekMode
+	}
+	if req.MinRestHours != nil {
+		cfg.MinRestHours = *req.MinRestHours
+	}
+	if req.MinRestMode != nil {
+		cfg.MinRestMode = *req.MinRestMode
+	}
+	if req.BackToBackDaysMode != nil {
+		cfg.BackToBackDaysMode = *req.BackToBackDaysMode
+	}
+	if req.SwapRequiresApproval != nil {
+		cfg.SwapRequiresApproval = *req.SwapRequiresApproval
+	}
+	if req.SwapAutoApprove != nil {
+		cfg.SwapAutoApprove = *req.SwapAutoApprove
+	}
+	if req.SwapAutoApproveSameDepartment != nil {
+		cfg.SwapAutoApproveSameDepartment = *req.SwapAutoApproveSameDepartment
+	}
+	if req.SwapAutoApproveMinHours != nil {
+		cfg.SwapAutoApproveMinHours = *req.SwapAutoApproveMinHours
+	}
+	if req.SwapAutoApproveMaxPerSemester != nil {
+		cfg.SwapAutoApproveMaxPerSemester = *req.SwapAutoApproveMaxPerSemester
+	}
+	if req.EmergencySubstituteEnabled != nil {
+		cfg.EmergencySubstituteEnabled = *req.EmergencySubstituteEnabled
+	}
 
 	cfg.UpdatedBy = &callerID
 
@@ -91,11 +139,23 @@ func (s *systemConfigService) Update(ctx context.Context, req *dto.UpdateSystemC
 	}
 
 	return &dto.SystemConfigResponse{
-		SwapDeadlineHours:    cfg.SwapDeadlineHours,
-		DutyReminderTime:     cfg.DutyReminderTime,
-		DefaultLocation:      cfg.DefaultLocation,
-		SignInWindowMinutes:  cfg.SignInWindowMinutes,
-		SignOutWindowMinutes: cfg.SignOutWindowMinutes,
-		UpdatedAt:            cfg.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+		SwapDeadlineHours:             cfg.SwapDeadlineHours,
+		DutyReminderTime:              cfg.DutyReminderTime,
+		DefaultLocation:               cfg.DefaultLocation,
+		SignInWindowMinutes:           cfg.SignInWindowMinutes,
+		SignOutWindowMinutes:          cfg.SignOutWindowMinutes,
+		TimetableConflictPolicy:       cfg.TimetableConflictPolicy,
+		MaxShiftsPerWeek:              cfg.MaxShiftsPerWeek,
+		MaxShiftsPerWeekMode:          cfg.MaxShiftsPerWeekMode,
+		MinRestHours:                  cfg.MinRestHours,
+		MinRestMode:                   cfg.MinRestMode,
+		BackToBackDaysMode:            cfg.BackToBackDaysMode,
+		SwapRequiresApproval:          cfg.SwapRequiresApproval,
+		SwapAutoApprove:               cfg.SwapAutoApprove,
+		SwapAutoApproveSameDepartment: cfg.SwapAutoApproveSameDepartment,
+		SwapAutoApproveMinHours:       cfg.SwapAutoApproveMinHours,
+		SwapAutoApproveMaxPerSemester: cfg.SwapAutoApproveMaxPerSemester,
+		EmergencySubstituteEnabled:    cfg.EmergencySubstituteEnabled,
+		UpdatedAt:                     cfg.UpdatedAt.Format("2006-01-02T15:04:05Z"),
 	}, nil
 }
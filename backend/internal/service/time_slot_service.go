@@ -15,7 +15,8 @@ import (
 // ── 时间段模块业务错误 ──
 
 var (
-	ErrTimeSlotNotFound = errors.New("时间段不存在")
+	ErrTimeSlotNotFound       = errors.New("时间段不存在")
+	ErrTimeSlotLocationClosed = errors.New("绑定地点已停用或在该时段未开放")
 )
 
 // TimeSlotService 时间段业务接口
@@ -50,21 +51,58 @@ func (s *timeSlotService) Create(ctx context.Context, req *dto.CreateTimeSlotReq
 		}
 	}
 
+	skills, err := resolveSkills(ctx, s.repo, req.SkillIDs)
+	if err != nil {
+		return nil, err
+	}
+
 	slot := &model.TimeSlot{
-		Name:       req.Name,
-		SemesterID: req.SemesterID,
-		StartTime:  req.StartTime,
-		EndTime:    req.EndTime,
-		DayOfWeek:  req.DayOfWeek,
-		IsActive:   true,
+		Name:           req.Name,
+		SemesterID:     req.SemesterID,
+		StartTime:      req.StartTime,
+		EndTime:        req.EndTime,
+		DayOfWeek:      req.DayOfWeek,
+		IsActive:       true,
+		CostMultiplier: 1,
+	}
+	if req.CostMultiplier != nil {
+		slot.CostMultiplier = *req.CostMultiplier
+	}
+	slot.LocationID = req.LocationID
+	if err := s.checkBoundLocation(ctx, slot); err != nil {
+		return nil, err
 	}
 	slot.CreatedBy = &callerID
 	slot.UpdatedBy = &callerID
 
-	if err := s.repo.TimeSlot.Create(ctx, slot); err != nil {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rollbackTx := func() {
+		if tx != nil {
+			tx.Rollback()
+		}
+	}
+	txRepo := s.repo.WithTx(tx)
+
+	if err := txRepo.TimeSlot.Create(ctx, slot); err != nil {
+		rollbackTx()
 		s.logger.Error("创建时间段失败", zap.Error(err))
 		return nil, err
 	}
+	if len(skills) > 0 {
+		if err := txRepo.Skill.ReplaceTimeSlotSkills(ctx, slot.TimeSlotID, timeSlotSkillRows(slot.TimeSlotID, skills, callerID)); err != nil {
+			rollbackTx()
+			s.logger.Error("设置时间段技能要求失败", zap.Error(err))
+			return nil, err
+		}
+	}
+	if tx != nil {
+		if err := tx.Commit().Error; err != nil {
+			return nil, err
+		}
+	}
 
 	// 重新加载以获取关联
 	created, err := s.repo.TimeSlot.GetByID(ctx, slot.TimeSlotID)
@@ -134,15 +172,64 @@ func (s *timeSlotService) Update(ctx context.Context, id string, req *dto.Update
 	if req.IsActive != nil {
 		slot.IsActive = *req.IsActive
 	}
+	if req.CostMultiplier != nil {
+		slot.CostMultiplier = *req.CostMultiplier
+	}
+	if req.LocationID != nil {
+		slot.LocationID, slot.Location = nil, nil
+		if *req.LocationID != "" {
+			slot.LocationID = req.LocationID
+		}
+	}
+	// 绑定地点或时间变化时重新校验地点开放时段
+	if req.LocationID != nil || req.StartTime != nil || req.EndTime != nil || req.DayOfWeek != nil {
+		if err := s.checkBoundLocation(ctx, slot); err != nil {
+			return nil, err
+		}
+	}
+	var skills []model.Skill
+	if req.SkillIDs != nil {
+		if skills, err = resolveSkills(ctx, s.repo, *req.SkillIDs); err != nil {
+			return nil, err
+		}
+	}
 
 	slot.UpdatedBy = &callerID
 
-	if err := s.repo.TimeSlot.Update(ctx, slot); err != nil {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rollbackTx := func() {
+		if tx != nil {
+			tx.Rollback()
+		}
+	}
+	txRepo := s.repo.WithTx(tx)
+
+	if err := txRepo.TimeSlot.Update(ctx, slot); err != nil {
+		rollbackTx()
 		s.logger.Error("更新时间段失败", zap.String("id", id), zap.Error(err))
 		return nil, err
 	}
+	if req.SkillIDs != nil {
+		if err := txRepo.Skill.ReplaceTimeSlotSkills(ctx, id, timeSlotSkillRows(id, skills, callerID)); err != nil {
+			rollbackTx()
+			s.logger.Error("设置时间段技能要求失败", zap.String("id", id), zap.Error(err))
+			return nil, err
+		}
+	}
+	if tx != nil {
+		if err := tx.Commit().Error; err != nil {
+			return nil, err
+		}
+	}
 
-	return s.toTimeSlotResponse(slot), nil
+	updated, err := s.repo.TimeSlot.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.toTimeSlotResponse(updated), nil
 }
 
 // ────────────────────── Delete ──────────────────────
@@ -167,19 +254,47 @@ func (s *timeSlotService) Delete(ctx context.Context, id string, callerID string
 
 // ── 内部辅助方法 ──
 
+// checkBoundLocation 绑定的地点须存在、启用且开放时段覆盖该时间段
+func (s *timeSlotService) checkBoundLocation(ctx context.Context, slot *model.TimeSlot) error {
+	if slot.LocationID == nil {
+		return nil
+	}
+	loc, err := s.repo.Location.GetByID(ctx, *slot.LocationID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrLocationNotFound
+		}
+		return err
+	}
+	if !loc.IsActive || !locationOpen(loc, *slot) {
+		return ErrTimeSlotLocationClosed
+	}
+	return nil
+}
+
 func (s *timeSlotService) toTimeSlotResponse(slot *model.TimeSlot) *dto.TimeSlotResponse {
 	resp := &dto.TimeSlotResponse{
-		ID:         slot.TimeSlotID,
-		Name:       slot.Name,
-		SemesterID: slot.SemesterID,
-		StartTime:  slot.StartTime,
-		EndTime:    slot.EndTime,
-		DayOfWeek:  slot.DayOfWeek,
-		IsActive:   slot.IsActive,
-		CreatedAt:  slot.CreatedAt.Format("2006-01-02T15:04:05Z"),
-		UpdatedAt:  slot.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+		ID:             slot.TimeSlotID,
+		Name:           slot.Name,
+		SemesterID:     slot.SemesterID,
+		StartTime:      slot.StartTime,
+		EndTime:        slot.EndTime,
+		DayOfWeek:      slot.DayOfWeek,
+		IsActive:       slot.IsActive,
+		Hours:          slotHours(*slot),
+		CostMultiplier: costMultiplier(*slot),
+		Cost:           slotCost(*slot),
+		RequiredSkills: toSkillBriefs(timeSlotSkills(slot)),
+		CreatedAt:      slot.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		UpdatedAt:      slot.UpdatedAt.Format("2006-01-02T15:04:05Z"),
 	}
 
+	if slot.Location != nil {
+		resp.Location = &dto.LocationBrief{
+			ID:   slot.Location.LocationID,
+			Name: slot.Location.Name,
+		}
+	}
 	if slot.Semester != nil {
 		resp.Semester = &dto.SemesterBrief{
 			ID:   slot.Semester.SemesterID,
@@ -189,3 +304,15 @@ func (s *timeSlotService) toTimeSlotResponse(slot *model.TimeSlot) *dto.TimeSlot
 
 	return resp
 }
+
+// timeSlotSkillRows 构建时间段技能要求记录
+func timeSlotSkillRows(timeSlotID string, skills []model.Skill, callerID string) []model.TimeSlotSkill {
+	rows := make([]model.TimeSlotSkill, 0, len(skills))
+	for i := range skills {
+		row := model.TimeSlotSkill{TimeSlotID: timeSlotID, SkillID: skills[i].SkillID, Skill: &skills[i]}
+		row.CreatedBy = &callerID
+		row.UpdatedBy = &callerID
+		rows = append(rows, row)
+	}
+	return rows
+}
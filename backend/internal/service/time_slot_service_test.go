@@ -25,6 +25,7 @@ func setupTestTimeSlotService() (TimeSlotService, *mockTimeSlotRepo, *mockSemest
 		Location:     newMockLocationRepo(),
 		SystemConfig: newMockSystemConfigRepo(),
 		ScheduleRule: newMockScheduleRuleRepo(),
+		Skill:        newMockSkillRepo(nil, timeSlotRepo, nil),
 	}
 	logger := zap.NewNop()
 	svc := NewTimeSlotService(repo, logger)
@@ -100,6 +101,38 @@ func TestTimeSlotService_Create_SemesterNotFound(t *testing.T) {
 
 // ── GetByID 测试 ──
 
+func TestTimeSlotService_Create_BoundLocationClosed(t *testing.T) {
+	svc, _, _ := setupTestTimeSlotService()
+	locRepo := svc.(*timeSlotService).repo.Location.(*mockLocationRepo)
+	locRepo.locations["loc-hall"] = &model.Location{
+		LocationID: "loc-hall", Name: "活动室", IsActive: true, Capacity: 1,
+		Windows: []model.LocationWindow{{DayOfWeek: 1, StartTime: "13:00", EndTime: "18:00"}},
+	}
+
+	hall := "loc-hall"
+	req := &dto.CreateTimeSlotRequest{
+		Name:       "周一上午第1节",
+		StartTime:  "08:10",
+		EndTime:    "10:05",
+		DayOfWeek:  1,
+		LocationID: &hall,
+	}
+	if _, err := svc.Create(context.Background(), req, "admin-001"); !errors.Is(err, ErrTimeSlotLocationClosed) {
+		t.Errorf("期望 ErrTimeSlotLocationClosed，实际: %v", err)
+	}
+
+	req.StartTime, req.EndTime = "14:00", "16:00"
+	if _, err := svc.Create(context.Background(), req, "admin-001"); err != nil {
+		t.Errorf("开放时段内绑定应成功: %v", err)
+	}
+
+	missing := "loc-missing"
+	req.LocationID = &missing
+	if _, err := svc.Create(context.Background(), req, "admin-001"); !errors.Is(err, ErrLocationNotFound) {
+		t.Errorf("期望 ErrLocationNotFound，实际: %v", err)
+	}
+}
+
 func TestTimeSlotService_GetByID_Success(t *testing.T) {
 	svc, tsRepo, _ := setupTestTimeSlotService()
 	tsRepo.slots["ts-001"] = &model.TimeSlot{
@@ -27,6 +27,7 @@ var (
 	ErrTimetableUnavailableNotFound = errors.New("不可用时间记录不存在")
 	ErrTimetableUnavailableNotOwner = errors.New("无权操作此不可用时间记录")
 	ErrTimetableDepartmentNotFound  = errors.New("部门不存在")
+	ErrTimetableConflictsPublished  = errors.New("时间表变更与已发布排班冲突")
 )
 
 // ── TimetableService 接口 ──────────────────────────────────
@@ -35,6 +36,7 @@ var (
 //   - 课表导入（ImportICS）采用全量替换策略，在单个事务中执行
 //     "删除旧数据 → 批量插入新数据 → 回退提交状态"，保证原子性。
 //   - 不可用时间 CRUD 独立于课表，与课表共同构成"时间表"。
+//   - 排班发布后的时间表写入会重新评估与已发布排班的冲突（见 schedule_conflict.go）。
 //   - 提交（Submit）将 timetable_status 从 not_submitted 更新为 submitted。
 //   - 进度统计（Progress）按部门分组聚合。
 // ─────────────────────────────────────────────────────────────
@@ -118,6 +120,10 @@ func (s *timetableService) ImportICS(ctx context.Context, reader io.Reader, user
 		}
 	}
 	s.rollbackTimetableStatusTx(ctx, txRepo, userID, semester.SemesterID)
+	conflicts, err := s.reconcilePublishedConflicts(ctx, txRepo, semester.SemesterID, userID)
+	if err != nil {
+		return nil, err
+	}
 
 	if tx != nil {
 		if err := tx.Commit().Error; err != nil {
@@ -125,6 +131,7 @@ func (s *timetableService) ImportICS(ctx context.Context, reader io.Reader, user
 			return nil, fmt.Errorf("课表导入失败: %w", err)
 		}
 	}
+	notifyScheduleConflicts(ctx, s.repo, s.logger, conflicts)
 
 	// 4. 构建响应
 	events := make([]dto.ImportedCourseEvent, 0, len(courses))
@@ -231,13 +238,27 @@ func (s *timetableService) CreateUnavailableTime(ctx context.Context, req *dto.C
 		s.logger.Error("创建不可用时间失败", zap.Error(err))
 		return nil, err
 	}
-	s.rollbackTimetableStatusTx(ctx, txRepo, userID, semester.SemesterID)
+	// 临时不可用不影响排班模板，无需回退提交状态与检测排班冲突
+	var conflicts []model.ScheduleConflict
+	if !isOnceOff(ut) {
+		s.rollbackTimetableStatusTx(ctx, txRepo, userID, semester.SemesterID)
+		if conflicts, err = s.reconcilePublishedConflicts(ctx, txRepo, semester.SemesterID, userID); err != nil {
+			return nil, err
+		}
+	}
+	flagged, err := refreshSubstituteFlags(ctx, txRepo, semester.SemesterID, userID, onceOffDates(ut), userID)
+	if err != nil {
+		s.logger.Error("更新替班标记失败", zap.Error(err))
+		return nil, err
+	}
 
 	if tx != nil {
 		if err := tx.Commit().Error; err != nil {
 			return nil, err
 		}
 	}
+	notifySubstituteNeeded(ctx, s.repo, s.logger, flagged)
+	notifyScheduleConflicts(ctx, s.repo, s.logger, conflicts)
 
 	resp := toUnavailableResponse(ut)
 	return &resp, nil
@@ -254,6 +275,7 @@ func (s *timetableService) UpdateUnavailableTime(ctx context.Context, id string,
 	if ut.UserID != userID {
 		return nil, ErrTimetableUnavailableNotOwner
 	}
+	before := *ut
 
 	// 应用更新
 	if req.DayOfWeek != nil {
@@ -298,13 +320,26 @@ func (s *timetableService) UpdateUnavailableTime(ctx context.Context, id string,
 		s.logger.Error("更新不可用时间失败", zap.Error(err))
 		return nil, err
 	}
-	s.rollbackTimetableStatusTx(ctx, txRepo, userID, ut.SemesterID)
+	var conflicts []model.ScheduleConflict
+	if !isOnceOff(before) || !isOnceOff(*ut) {
+		s.rollbackTimetableStatusTx(ctx, txRepo, userID, ut.SemesterID)
+		if conflicts, err = s.reconcilePublishedConflicts(ctx, txRepo, ut.SemesterID, userID); err != nil {
+			return nil, err
+		}
+	}
+	flagged, err := refreshSubstituteFlags(ctx, txRepo, ut.SemesterID, userID, onceOffDates(before, *ut), userID)
+	if err != nil {
+		s.logger.Error("更新替班标记失败", zap.Error(err))
+		return nil, err
+	}
 
 	if tx != nil {
 		if err := tx.Commit().Error; err != nil {
 			return nil, err
 		}
 	}
+	notifySubstituteNeeded(ctx, s.repo, s.logger, flagged)
+	notifyScheduleConflicts(ctx, s.repo, s.logger, conflicts)
 
 	resp := toUnavailableResponse(*ut)
 	return &resp, nil
@@ -338,7 +373,18 @@ func (s *timetableService) DeleteUnavailableTime(ctx context.Context, id string,
 		s.logger.Error("删除不可用时间失败", zap.Error(err))
 		return err
 	}
-	s.rollbackTimetableStatusTx(ctx, txRepo, userID, ut.SemesterID)
+	if !isOnceOff(*ut) {
+		s.rollbackTimetableStatusTx(ctx, txRepo, userID, ut.SemesterID)
+		// 删除只会消除冲突，此处仅关闭已消失冲突对应的任务
+		if _, err := s.reconcilePublishedConflicts(ctx, txRepo, ut.SemesterID, userID); err != nil {
+			return err
+		}
+	}
+	// 撤销临时不可用后，当天的替班标记随之清除
+	if _, err := refreshSubstituteFlags(ctx, txRepo, ut.SemesterID, userID, onceOffDates(*ut), userID); err != nil {
+		s.logger.Error("更新替班标记失败", zap.Error(err))
+		return err
+	}
 
 	if tx != nil {
 		if err := tx.Commit().Error; err != nil {
@@ -565,6 +611,23 @@ func (s *timetableService) rollbackTimetableStatusTx(ctx context.Context, repo *
 	}
 }
 
+// onceOffDates 收集临时不可用涉及的日期（去重）
+func onceOffDates(uts ...model.UnavailableTime) []time.Time {
+	var dates []time.Time
+	seen := make(map[string]bool)
+	for _, ut := range uts {
+		if !isOnceOff(ut) {
+			continue
+		}
+		d := dateOnly(*ut.SpecificDate)
+		if key := d.Format(model.TimeFormatDate); !seen[key] {
+			seen[key] = true
+			dates = append(dates, d)
+		}
+	}
+	return dates
+}
+
 // ── 响应转换器 ──
 
 func toCourseResponses(courses []model.CourseSchedule) []dto.CourseResponse {
@@ -317,28 +317,41 @@ func TestIntArray_ScanAndValue(t *testing.T) {
 // 测试辅助：构建 TimetableService
 
 func setupTestTimetableService() (TimetableService, *testTimetableRepos) {
+	items := newMockScheduleItemRepo()
 	repos := &testTimetableRepos{
 		semester:       newMockSemesterRepo(),
 		courseSchedule: newMockCourseScheduleRepo(),
 		unavailable:    newMockUnavailableTimeRepo(),
 		assignment:     newMockUserSemesterAssignmentRepo(),
 		department:     newMockDeptRepo(),
+		schedule:       newMockScheduleRepo(),
+		scheduleItem:   items,
+		dutyRecord:     newMockDutyRecordRepo(items),
+		notification:   newMockNotificationRepo(),
+		timeSlot:       newMockTimeSlotRepo(),
+		scheduleRule:   newMockScheduleRuleRepo(),
+		systemConfig:   newMockSystemConfigRepo(),
+		conflict:       newMockScheduleConflictRepo(),
+		user:           newMockUserRepo(),
 	}
 	repoAgg := &repository.Repository{
-		User:                   newMockUserRepo(),
+		User:                   repos.user,
 		Department:             repos.department,
 		Semester:               repos.semester,
-		TimeSlot:               newMockTimeSlotRepo(),
+		TimeSlot:               repos.timeSlot,
 		Location:               newMockLocationRepo(),
-		SystemConfig:           newMockSystemConfigRepo(),
-		ScheduleRule:           newMockScheduleRuleRepo(),
+		SystemConfig:           repos.systemConfig,
+		ScheduleRule:           repos.scheduleRule,
 		CourseSchedule:         repos.courseSchedule,
 		UnavailableTime:        repos.unavailable,
 		UserSemesterAssignment: repos.assignment,
-		Schedule:               newMockScheduleRepo(),
-		ScheduleItem:           newMockScheduleItemRepo(),
+		Schedule:               repos.schedule,
+		ScheduleItem:           repos.scheduleItem,
 		ScheduleMemberSnapshot: newMockScheduleMemberSnapshotRepo(),
 		ScheduleChangeLog:      newMockScheduleChangeLogRepo(),
+		DutyRecord:             repos.dutyRecord,
+		Notification:           repos.notification,
+		ScheduleConflict:       repos.conflict,
 	}
 	logger := zap.NewNop()
 	svc := NewTimetableService(repoAgg, logger)
@@ -351,6 +364,15 @@ type testTimetableRepos struct {
 	unavailable    *mockUnavailableTimeRepo
 	assignment     *mockUserSemesterAssignmentRepo
 	department     *mockDeptRepo
+	schedule       *mockScheduleRepo
+	scheduleItem   *mockScheduleItemRepo
+	dutyRecord     *mockDutyRecordRepo
+	notification   *mockNotificationRepo
+	timeSlot       *mockTimeSlotRepo
+	scheduleRule   *mockScheduleRuleRepo
+	systemConfig   *mockSystemConfigRepo
+	conflict       *mockScheduleConflictRepo
+	user           *mockUserRepo
 }
 
 func seedTimetableBasicData(repos *testTimetableRepos) {
@@ -651,3 +673,28 @@ func TestTimetableService_GetDepartmentProgress(t *testing.T) {
 
 type CreateUnavailableTimeParams = dto.CreateUnavailableTimeRequest
 type UpdateUnavailableTimeParams = dto.UpdateUnavailableTimeRequest
+
+func TestTimetableService_CreateOnceOff_KeepsSubmittedStatus(t *testing.T) {
+	svc, repos := setupTestTimetableService()
+	seedTimetableBasicData(repos)
+	repos.assignment.assignments[0].TimetableStatus = "submitted"
+	ctx := context.Background()
+	date := "2025-03-05"
+
+	if _, err := svc.CreateUnavailableTime(ctx, &CreateUnavailableTimeParams{
+		DayOfWeek:    3,
+		StartTime:    "14:00",
+		EndTime:      "16:00",
+		Reason:       "看病",
+		RepeatType:   model.RepeatTypeOnce,
+		SpecificDate: &date,
+		WeekType:     "all",
+		SemesterID:   "sem-1",
+	}, "user-1"); err != nil {
+		t.Fatalf("Create 失败: %v", err)
+	}
+
+	if got := repos.assignment.assignments[0].TimetableStatus; got != "submitted" {
+		t.Errorf("临时不可用不应回退提交状态，实际 %s", got)
+	}
+}
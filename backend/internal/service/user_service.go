@@ -39,6 +39,8 @@ type UserService interface {
 	ResetPassword(ctx context.Context, id string, callerID string) (*dto.ResetPasswordResponse, error)
 	ParseImportFile(reader io.Reader) ([]ImportUserRow, error)
 	ImportUsers(ctx context.Context, rows []ImportUserRow) (*dto.ImportUserResponse, error)
+	// SetSkills 全量设置成员技能；部长只能设置本部门成员
+	SetSkills(ctx context.Context, id string, req *dto.SetUserSkillsRequest, callerID, callerRole, callerDeptID string) (*dto.UserResponse, error)
 }
 
 // ImportUserRow Excel 导入解析后的单行数据
@@ -48,6 +50,7 @@ type ImportUserRow struct {
 	StudentID      string
 	Email          string
 	DepartmentName string
+	SkillNames     []string // 可选列"技能"，多个技能以逗号或顿号分隔
 }
 
 type userService struct {
@@ -85,6 +88,11 @@ func (s *userService) CreateUser(ctx context.Context, req *dto.CreateUserRequest
 		return nil, err
 	}
 
+	skills, err := resolveSkills(ctx, s.repo, req.SkillIDs)
+	if err != nil {
+		return nil, err
+	}
+
 	// 默认密码 = "Ec" + 学号后6位（与批量导入逻辑一致）
 	defaultPwd := req.StudentID
 	if len(defaultPwd) > 6 {
@@ -109,10 +117,34 @@ func (s *userService) CreateUser(ctx context.Context, req *dto.CreateUserRequest
 		VersionedModel:     model.VersionedModel{SoftDeleteModel: model.SoftDeleteModel{BaseModel: model.BaseModel{CreatedBy: &callerID}}},
 	}
 
-	if err := s.repo.User.Create(ctx, user); err != nil {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rollbackTx := func() {
+		if tx != nil {
+			tx.Rollback()
+		}
+	}
+	txRepo := s.repo.WithTx(tx)
+
+	if err := txRepo.User.Create(ctx, user); err != nil {
+		rollbackTx()
 		s.logger.Error("创建用户失败", zap.Error(err))
 		return nil, err
 	}
+	if len(skills) > 0 {
+		if err := txRepo.Skill.ReplaceUserSkills(ctx, user.UserID, userSkillRows(user.UserID, skills, &callerID)); err != nil {
+			rollbackTx()
+			s.logger.Error("设置成员技能失败", zap.Error(err))
+			return nil, err
+		}
+	}
+	if tx != nil {
+		if err := tx.Commit().Error; err != nil {
+			return nil, err
+		}
+	}
 
 	// 重新加载以获取关联数据（部门等）
 	created, err := s.repo.User.GetByID(ctx, user.UserID)
@@ -320,6 +352,39 @@ func (s *userService) ResetPassword(ctx context.Context, id string, callerID str
 	return &dto.ResetPasswordResponse{TempPassword: tempPassword}, nil
 }
 
+// ────────────────────── SetSkills ──────────────────────
+
+func (s *userService) SetSkills(ctx context.Context, id string, req *dto.SetUserSkillsRequest, callerID, callerRole, callerDeptID string) (*dto.UserResponse, error) {
+	user, err := s.repo.User.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		s.logger.Error("查询用户失败", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+
+	// 部长只能设置本部门成员的技能
+	if callerRole == model.RoleLeader && user.DepartmentID != callerDeptID {
+		return nil, ErrNoPermission
+	}
+
+	skills, err := resolveSkills(ctx, s.repo, req.SkillIDs)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Skill.ReplaceUserSkills(ctx, id, userSkillRows(id, skills, &callerID)); err != nil {
+		s.logger.Error("设置成员技能失败", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+
+	updated, err := s.repo.User.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.toUserResponse(updated), nil
+}
+
 // ────────────────────── ParseImportFile ──────────────────────
 
 const maxImportRows = 1000
@@ -371,6 +436,9 @@ func (s *userService) ParseImportFile(reader io.Reader) ([]ImportUserRow, error)
 		if idx := colIndex["department"]; idx < len(row) {
 			item.DepartmentName = strings.TrimSpace(row[idx])
 		}
+		if idx := colIndex["skills"]; idx >= 0 && idx < len(row) {
+			item.SkillNames = splitSkillNames(row[idx])
+		}
 
 		// 跳过全空行
 		if item.Name == "" && item.StudentID == "" && item.Email == "" && item.DepartmentName == "" {
@@ -397,6 +465,7 @@ func parseHeaderIndex(header []string) map[string]int {
 		"student_id": -1,
 		"email":      -1,
 		"department": -1,
+		"skills":     -1,
 	}
 	for i, h := range header {
 		lower := strings.ToLower(strings.TrimSpace(h))
@@ -409,6 +478,8 @@ func parseHeaderIndex(header []string) map[string]int {
 			idx["email"] = i
 		case lower == "部门" || lower == "department":
 			idx["department"] = i
+		case lower == "技能" || lower == "skills":
+			idx["skills"] = i
 		}
 	}
 	return idx
@@ -426,11 +497,19 @@ func (s *userService) ImportUsers(ctx context.Context, rows []ImportUserRow) (*d
 		return nil, err
 	}
 
+	// 预加载技能目录，便于按名称查找
+	skillMap, err := s.buildSkillMap(ctx)
+	if err != nil {
+		s.logger.Error("加载技能目录失败", zap.Error(err))
+		return nil, err
+	}
+
 	// 第一阶段：数据预校验（不接触数据库写操作）
 	type validatedRow struct {
-		row  ImportUserRow
-		dept *model.Department
-		hash []byte
+		row    ImportUserRow
+		dept   *model.Department
+		skills []model.Skill
+		hash   []byte
 	}
 	var validRows []validatedRow
 
@@ -454,6 +533,24 @@ func (s *userService) ImportUsers(ctx context.Context, rows []ImportUserRow) (*d
 			continue
 		}
 
+		// 查找技能
+		var skills []model.Skill
+		var unknown []string
+		for _, name := range row.SkillNames {
+			if skill, ok := skillMap[name]; ok {
+				skills = append(skills, *skill)
+			} else {
+				unknown = append(unknown, name)
+			}
+		}
+		if len(unknown) > 0 {
+			resp.Failed++
+			resp.Errors = append(resp.Errors, dto.ImportUserError{
+				Row: row.Row, Reason: fmt.Sprintf("技能不存在: %s", strings.Join(unknown, "、")),
+			})
+			continue
+		}
+
 		// 检查学号唯一性
 		if _, err := s.repo.User.GetByStudentID(ctx, row.StudentID); err == nil {
 			resp.Failed++
@@ -488,7 +585,7 @@ func (s *userService) ImportUsers(ctx context.Context, rows []ImportUserRow) (*d
 			continue
 		}
 
-		validRows = append(validRows, validatedRow{row: row, dept: dept, hash: hash})
+		validRows = append(validRows, validatedRow{row: row, dept: dept, skills: skills, hash: hash})
 	}
 
 	// 第二阶段：在事务中批量创建所有通过校验的用户
@@ -529,6 +626,16 @@ func (s *userService) ImportUsers(ctx context.Context, rows []ImportUserRow) (*d
 					zap.Int("row", vr.row.Row), zap.Error(err))
 				return nil, fmt.Errorf("第 %d 行写入数据库失败，已回滚全部导入: %w", vr.row.Row, err)
 			}
+			if len(vr.skills) > 0 {
+				if err := txRepo.Skill.ReplaceUserSkills(ctx, user.UserID, userSkillRows(user.UserID, vr.skills, nil)); err != nil {
+					if tx != nil {
+						tx.Rollback()
+					}
+					s.logger.Error("导入成员技能写入失败，事务回滚",
+						zap.Int("row", vr.row.Row), zap.Error(err))
+					return nil, fmt.Errorf("第 %d 行写入数据库失败，已回滚全部导入: %w", vr.row.Row, err)
+				}
+			}
 			resp.Success++
 		}
 
@@ -561,6 +668,7 @@ func (s *userService) toUserResponse(user *model.User) *dto.UserResponse {
 		StudentID:  user.StudentID,
 		Role:       user.Role,
 		Department: dept,
+		Skills:     toSkillBriefs(userSkills(user)),
 	}
 }
 
@@ -577,6 +685,48 @@ func (s *userService) buildDepartmentMap(ctx context.Context) (map[string]*model
 	return m, nil
 }
 
+// buildSkillMap 构建技能名称 -> 技能实体映射
+func (s *userService) buildSkillMap(ctx context.Context) (map[string]*model.Skill, error) {
+	skills, err := s.repo.Skill.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]*model.Skill, len(skills))
+	for i := range skills {
+		m[skills[i].Name] = &skills[i]
+	}
+	return m, nil
+}
+
+// splitSkillNames 拆分导入单元格中的技能名称（逗号 / 顿号 / 分号分隔，去重去空）
+func splitSkillNames(cell string) []string {
+	parts := strings.FieldsFunc(cell, func(r rune) bool {
+		return r == ',' || r == '，' || r == '、' || r == ';' || r == '；'
+	})
+	seen := make(map[string]bool, len(parts))
+	var names []string
+	for _, p := range parts {
+		name := strings.TrimSpace(p)
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// userSkillRows 构建成员技能记录（批量导入时 operatorID 为空）
+func userSkillRows(userID string, skills []model.Skill, operatorID *string) []model.UserSkill {
+	rows := make([]model.UserSkill, 0, len(skills))
+	for i := range skills {
+		row := model.UserSkill{UserID: userID, SkillID: skills[i].SkillID, Skill: &skills[i]}
+		row.CreatedBy = operatorID
+		row.UpdatedBy = operatorID
+		rows = append(rows, row)
+	}
+	return rows
+}
+
 // generateTempPassword 生成指定长度的临时密码（保证包含字母和数字）
 func generateTempPassword(length int) (string, error) {
 	const letters = "abcdefghijkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ"
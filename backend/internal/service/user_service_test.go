@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 
 	"go.uber.org/zap"
@@ -26,6 +27,7 @@ func setupTestUserService() (UserService, *mockUserRepo, *mockDeptRepo) {
 		Location:     newMockLocationRepo(),
 		SystemConfig: newMockSystemConfigRepo(),
 		ScheduleRule: newMockScheduleRuleRepo(),
+		Skill:        newMockSkillRepo(userRepo, nil, nil),
 	}
 	logger := zap.NewNop()
 	svc := NewUserService(repo, logger)
@@ -471,6 +473,58 @@ func TestUserService_ImportUsers_Mixed(t *testing.T) {
 	}
 }
 
+func TestUserService_ImportUsers_UnknownSkill(t *testing.T) {
+	svc, _, _ := setupTestUserService()
+
+	rows := []ImportUserRow{
+		{Row: 2, Name: "新用户", StudentID: "2024401", Email: "skill@test.com", DepartmentName: "测试部门", SkillNames: []string{"不存在的技能"}},
+	}
+
+	result, err := svc.ImportUsers(context.Background(), rows)
+	if err != nil {
+		t.Fatalf("ImportUsers 应返回结果而非错误: %v", err)
+	}
+	if result.Failed != 1 || len(result.Errors) != 1 {
+		t.Fatalf("期望Failed=1，实际=%d", result.Failed)
+	}
+	if !strings.Contains(result.Errors[0].Reason, "不存在的技能") {
+		t.Errorf("错误信息应包含未知技能名，实际=%s", result.Errors[0].Reason)
+	}
+}
+
+// ── SetSkills 测试 ──
+
+func TestUserService_SetSkills_Success(t *testing.T) {
+	svc, userRepo, _ := setupTestUserService()
+	createTestUserForUserSvc(userRepo, "uid-001", "2024001", "张三", "member", "dept-1")
+	svc.(*userService).repo.Skill.(*mockSkillRepo).seedSkill("skill-1", "音响设备")
+
+	req := &dto.SetUserSkillsRequest{SkillIDs: []string{"skill-1", "skill-1"}}
+	resp, err := svc.SetSkills(context.Background(), "uid-001", req, "leader-1", "leader", "dept-1")
+	if err != nil {
+		t.Fatalf("SetSkills 应成功: %v", err)
+	}
+	if len(resp.Skills) != 1 || resp.Skills[0].Name != "音响设备" {
+		t.Errorf("期望技能=[音响设备]，实际=%+v", resp.Skills)
+	}
+
+	req = &dto.SetUserSkillsRequest{SkillIDs: []string{"skill-404"}}
+	if _, err := svc.SetSkills(context.Background(), "uid-001", req, "admin-1", "admin", ""); !errors.Is(err, ErrSkillNotFound) {
+		t.Errorf("期望 ErrSkillNotFound，实际: %v", err)
+	}
+}
+
+func TestUserService_SetSkills_LeaderOtherDept(t *testing.T) {
+	svc, userRepo, _ := setupTestUserService()
+	createTestUserForUserSvc(userRepo, "uid-001", "2024001", "张三", "member", "dept-1")
+
+	req := &dto.SetUserSkillsRequest{}
+	_, err := svc.SetSkills(context.Background(), "uid-001", req, "leader-2", "leader", "dept-2")
+	if !errors.Is(err, ErrNoPermission) {
+		t.Errorf("期望 ErrNoPermission，实际: %v", err)
+	}
+}
+
 // ── generateTempPassword 测试 ──
 
 func TestGenerateTempPassword(t *testing.T) {
@@ -0,0 +1,19 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_schedules_candidates;
+
+-- 候选方案无法在旧状态机中表示，回滚时归档
+UPDATE schedules SET status = 'archived' WHERE status = 'candidate';
+
+ALTER TABLE schedules DROP CONSTRAINT IF EXISTS ck_schedules_solver;
+ALTER TABLE schedules DROP CONSTRAINT ck_schedules_status;
+ALTER TABLE schedules ADD CONSTRAINT ck_schedules_status
+    CHECK (status IN ('draft', 'published', 'need_regen', 'archived'));
+
+ALTER TABLE schedules
+    DROP COLUMN IF EXISTS rule_overrides,
+    DROP COLUMN IF EXISTS seed,
+    DROP COLUMN IF EXISTS solver,
+    DROP COLUMN IF EXISTS name;
+
+COMMIT;
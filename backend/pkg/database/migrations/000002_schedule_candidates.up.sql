@@ -0,0 +1,25 @@
+-- ============================================================
+-- 排班候选方案：新增 candidate 状态与生成参数
+-- candidate 不在 uk_schedules_active_per_semester 覆盖范围内，
+-- 同一学期可并存多个候选方案。
+-- ============================================================
+
+BEGIN;
+
+ALTER TABLE schedules
+    ADD COLUMN name           VARCHAR(100),
+    ADD COLUMN solver         VARCHAR(20)  NOT NULL DEFAULT 'greedy',
+    ADD COLUMN seed           BIGINT,
+    ADD COLUMN rule_overrides JSONB;
+
+ALTER TABLE schedules DROP CONSTRAINT ck_schedules_status;
+ALTER TABLE schedules ADD CONSTRAINT ck_schedules_status
+    CHECK (status IN ('draft', 'published', 'need_regen', 'archived', 'candidate'));
+
+ALTER TABLE schedules ADD CONSTRAINT ck_schedules_solver
+    CHECK (solver IN ('greedy', 'local_search'));
+
+CREATE INDEX idx_schedules_candidates
+    ON schedules (semester_id, created_at) WHERE status = 'candidate' AND deleted_at IS NULL;
+
+COMMIT;
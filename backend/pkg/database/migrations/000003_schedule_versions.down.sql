@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_schedules_semester_created;
+
+ALTER TABLE schedules DROP COLUMN IF EXISTS restored_from_id;
+
+COMMIT;
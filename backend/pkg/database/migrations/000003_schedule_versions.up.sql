@@ -0,0 +1,13 @@
+-- ============================================================
+-- 排班版本历史：记录由归档版本恢复而来的排班表来源
+-- ============================================================
+
+BEGIN;
+
+ALTER TABLE schedules
+    ADD COLUMN restored_from_id UUID REFERENCES schedules (schedule_id);
+
+CREATE INDEX idx_schedules_semester_created
+    ON schedules (semester_id, created_at DESC) WHERE deleted_at IS NULL;
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE IF EXISTS semester_calendar_days;
+
+COMMIT;
@@ -0,0 +1,53 @@
+-- ============================================================
+-- 学期校历：节假日与调休上班日
+-- holiday 当天不生成值班记录；workday 按 runs_as_day_of_week / runs_as_week
+-- 执行对应星期与周次的排班（如周六补周一的班）。
+-- ============================================================
+
+BEGIN;
+
+CREATE TABLE semester_calendar_days (
+    calendar_day_id     UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    semester_id         UUID         NOT NULL,
+    date                DATE         NOT NULL,
+    kind                VARCHAR(20)  NOT NULL,
+    name                VARCHAR(100),
+    runs_as_day_of_week SMALLINT,
+    runs_as_week        SMALLINT,
+    source              VARCHAR(20)  NOT NULL DEFAULT 'manual',
+    created_at          TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by          UUID,
+    updated_at          TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_by          UUID,
+    deleted_at          TIMESTAMPTZ,
+    deleted_by          UUID,
+
+    CONSTRAINT ck_semester_calendar_days_kind
+        CHECK (kind IN ('holiday', 'workday')),
+    CONSTRAINT ck_semester_calendar_days_source
+        CHECK (source IN ('manual', 'ics')),
+    CONSTRAINT ck_semester_calendar_days_runs_as_day
+        CHECK (runs_as_day_of_week IS NULL OR runs_as_day_of_week BETWEEN 1 AND 7),
+    CONSTRAINT ck_semester_calendar_days_runs_as_week
+        CHECK (runs_as_week IS NULL OR runs_as_week >= 1),
+    CONSTRAINT ck_semester_calendar_days_holiday
+        CHECK (kind = 'workday' OR (runs_as_day_of_week IS NULL AND runs_as_week IS NULL)),
+    CONSTRAINT ck_semester_calendar_days_soft_delete
+        CHECK ((deleted_at IS NULL AND deleted_by IS NULL)
+            OR (deleted_at IS NOT NULL AND deleted_by IS NOT NULL)),
+
+    CONSTRAINT fk_semester_calendar_days_semester
+        FOREIGN KEY (semester_id) REFERENCES semesters(semester_id)
+        ON DELETE RESTRICT ON UPDATE CASCADE,
+    CONSTRAINT fk_semester_calendar_days_created_by
+        FOREIGN KEY (created_by) REFERENCES users(user_id),
+    CONSTRAINT fk_semester_calendar_days_updated_by
+        FOREIGN KEY (updated_by) REFERENCES users(user_id),
+    CONSTRAINT fk_semester_calendar_days_deleted_by
+        FOREIGN KEY (deleted_by) REFERENCES users(user_id)
+);
+
+CREATE UNIQUE INDEX uk_semester_calendar_days_date
+    ON semester_calendar_days (semester_id, date) WHERE deleted_at IS NULL;
+
+COMMIT;
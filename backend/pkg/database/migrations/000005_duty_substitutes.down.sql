@@ -0,0 +1,20 @@
+BEGIN;
+
+DELETE FROM notifications WHERE type = 'substitute_needed';
+
+ALTER TABLE notifications DROP CONSTRAINT ck_notifications_type;
+ALTER TABLE notifications ADD CONSTRAINT ck_notifications_type
+    CHECK (type IN (
+        'schedule_published', 'schedule_changed', 'duty_reminder',
+        'swap_request', 'swap_accepted', 'swap_rejected',
+        'swap_approved', 'swap_denied',
+        'absent_alert', 'make_up_alert', 'no_sign_out_alert'
+    ));
+
+DROP INDEX IF EXISTS idx_duty_records_needs_substitute;
+
+ALTER TABLE duty_records
+    DROP COLUMN IF EXISTS substitute_reason,
+    DROP COLUMN IF EXISTS needs_substitute;
+
+COMMIT;
@@ -0,0 +1,26 @@
+-- ============================================================
+-- 值班记录替班标记
+-- 成员的临时不可用（repeat_type = once）只影响对应日期的值班记录：
+-- 排班已发布时将该次值班标记为需替班并通知管理员，排班模板不变。
+-- ============================================================
+
+BEGIN;
+
+ALTER TABLE duty_records
+    ADD COLUMN needs_substitute  BOOLEAN      NOT NULL DEFAULT FALSE,
+    ADD COLUMN substitute_reason VARCHAR(200);
+
+CREATE INDEX idx_duty_records_needs_substitute
+    ON duty_records (duty_date) WHERE needs_substitute AND deleted_at IS NULL;
+
+ALTER TABLE notifications DROP CONSTRAINT ck_notifications_type;
+ALTER TABLE notifications ADD CONSTRAINT ck_notifications_type
+    CHECK (type IN (
+        'schedule_published', 'schedule_changed', 'duty_reminder',
+        'swap_request', 'swap_accepted', 'swap_rejected',
+        'swap_approved', 'swap_denied',
+        'absent_alert', 'make_up_alert', 'no_sign_out_alert',
+        'substitute_needed'
+    ));
+
+COMMIT;
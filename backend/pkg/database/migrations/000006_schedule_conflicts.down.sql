@@ -0,0 +1,21 @@
+BEGIN;
+
+DELETE FROM notifications WHERE type = 'schedule_conflict';
+
+ALTER TABLE notifications DROP CONSTRAINT ck_notifications_type;
+ALTER TABLE notifications ADD CONSTRAINT ck_notifications_type
+    CHECK (type IN (
+        'schedule_published', 'schedule_changed', 'duty_reminder',
+        'swap_request', 'swap_accepted', 'swap_rejected',
+        'swap_approved', 'swap_denied',
+        'absent_alert', 'make_up_alert', 'no_sign_out_alert',
+        'substitute_needed'
+    ));
+
+ALTER TABLE system_config
+    DROP CONSTRAINT IF EXISTS ck_system_config_timetable_conflict_policy,
+    DROP COLUMN IF EXISTS timetable_conflict_policy;
+
+DROP TABLE IF EXISTS schedule_conflicts;
+
+COMMIT;
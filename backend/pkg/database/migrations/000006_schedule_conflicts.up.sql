@@ -0,0 +1,71 @@
+-- ============================================================
+-- 排班发布后的时间表变更检测
+-- 成员在排班发布后重新导入课表或新增不可用时间，与其已发布排班项冲突时
+-- 生成冲突处理任务并通知管理员与成员；system_config.timetable_conflict_policy
+-- 为 block 时直接拒绝此类变更。
+-- ============================================================
+
+BEGIN;
+
+CREATE TABLE schedule_conflicts (
+    schedule_conflict_id UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    schedule_id          UUID         NOT NULL,
+    schedule_item_id     UUID         NOT NULL,
+    member_id            UUID         NOT NULL,
+    source               VARCHAR(20)  NOT NULL,
+    detail               VARCHAR(500) NOT NULL,
+    status               VARCHAR(20)  NOT NULL DEFAULT 'open',
+    resolution_note      VARCHAR(200),
+    resolved_at          TIMESTAMPTZ,
+    resolved_by          UUID,
+    created_at           TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by           UUID,
+    updated_at           TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_by           UUID,
+
+    CONSTRAINT ck_schedule_conflicts_source
+        CHECK (source IN ('course', 'unavailable')),
+    CONSTRAINT ck_schedule_conflicts_status
+        CHECK (status IN ('open', 'resolved', 'dismissed')),
+    CONSTRAINT ck_schedule_conflicts_resolved
+        CHECK ((status = 'open' AND resolved_at IS NULL)
+            OR (status <> 'open' AND resolved_at IS NOT NULL)),
+
+    CONSTRAINT fk_schedule_conflicts_schedule
+        FOREIGN KEY (schedule_id) REFERENCES schedules(schedule_id)
+        ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT fk_schedule_conflicts_item
+        FOREIGN KEY (schedule_item_id) REFERENCES schedule_items(schedule_item_id)
+        ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT fk_schedule_conflicts_member
+        FOREIGN KEY (member_id) REFERENCES users(user_id),
+    CONSTRAINT fk_schedule_conflicts_resolved_by
+        FOREIGN KEY (resolved_by) REFERENCES users(user_id),
+    CONSTRAINT fk_schedule_conflicts_created_by
+        FOREIGN KEY (created_by) REFERENCES users(user_id),
+    CONSTRAINT fk_schedule_conflicts_updated_by
+        FOREIGN KEY (updated_by) REFERENCES users(user_id)
+);
+
+-- 同一排班项同时至多一条待处理任务
+CREATE UNIQUE INDEX uk_schedule_conflicts_open_item
+    ON schedule_conflicts (schedule_item_id) WHERE status = 'open';
+CREATE INDEX idx_schedule_conflicts_schedule_status
+    ON schedule_conflicts (schedule_id, status);
+
+ALTER TABLE system_config
+    ADD COLUMN timetable_conflict_policy VARCHAR(20) NOT NULL DEFAULT 'notify',
+    ADD CONSTRAINT ck_system_config_timetable_conflict_policy
+        CHECK (timetable_conflict_policy IN ('notify', 'block'));
+
+ALTER TABLE notifications DROP CONSTRAINT ck_notifications_type;
+ALTER TABLE notifications ADD CONSTRAINT ck_notifications_type
+    CHECK (type IN (
+        'schedule_published', 'schedule_changed', 'duty_reminder',
+        'swap_request', 'swap_accepted', 'swap_rejected',
+        'swap_approved', 'swap_denied',
+        'absent_alert', 'make_up_alert', 'no_sign_out_alert',
+        'substitute_needed', 'schedule_conflict'
+    ));
+
+COMMIT;
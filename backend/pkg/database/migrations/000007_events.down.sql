@@ -0,0 +1,29 @@
+BEGIN;
+
+DELETE FROM notifications WHERE type IN ('event_assigned', 'event_cancelled');
+
+ALTER TABLE notifications DROP CONSTRAINT ck_notifications_type;
+ALTER TABLE notifications ADD CONSTRAINT ck_notifications_type
+    CHECK (type IN (
+        'schedule_published', 'schedule_changed', 'duty_reminder',
+        'swap_request', 'swap_accepted', 'swap_rejected',
+        'swap_approved', 'swap_denied',
+        'absent_alert', 'make_up_alert', 'no_sign_out_alert',
+        'substitute_needed', 'schedule_conflict'
+    ));
+
+DELETE FROM duty_records WHERE event_shift_id IS NOT NULL;
+
+DROP INDEX IF EXISTS uk_duty_records_event_shift_member;
+
+ALTER TABLE duty_records
+    DROP CONSTRAINT IF EXISTS ck_duty_records_source,
+    DROP CONSTRAINT IF EXISTS fk_duty_records_event_shift,
+    DROP COLUMN IF EXISTS event_shift_id,
+    ALTER COLUMN schedule_item_id SET NOT NULL;
+
+DROP TABLE IF EXISTS event_assignments;
+DROP TABLE IF EXISTS event_shifts;
+DROP TABLE IF EXISTS events;
+
+COMMIT;
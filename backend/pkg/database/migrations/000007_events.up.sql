@@ -0,0 +1,142 @@
+-- ============================================================
+-- 活动值班
+-- 迎新、换届选举、晚会等一次性活动按具体日期的班次安排人手，
+-- 不走每周排班模板。成员报名或按时间表自动分配，活动发布后
+-- 每个班次人员生成一条值班记录（duty_records.event_shift_id）。
+-- ============================================================
+
+BEGIN;
+
+CREATE TABLE events (
+    event_id    UUID          PRIMARY KEY DEFAULT gen_random_uuid(),
+    semester_id UUID          NOT NULL,
+    name        VARCHAR(100)  NOT NULL,
+    description VARCHAR(500),
+    status      VARCHAR(20)   NOT NULL DEFAULT 'draft',
+    signup_open BOOLEAN       NOT NULL DEFAULT FALSE,
+    created_at  TIMESTAMPTZ   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by  UUID,
+    updated_at  TIMESTAMPTZ   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_by  UUID,
+    deleted_at  TIMESTAMPTZ,
+    deleted_by  UUID,
+    version     INT           NOT NULL DEFAULT 1,
+
+    CONSTRAINT ck_events_status
+        CHECK (status IN ('draft', 'published', 'cancelled')),
+    CONSTRAINT ck_events_soft_delete
+        CHECK ((deleted_at IS NULL AND deleted_by IS NULL)
+            OR (deleted_at IS NOT NULL AND deleted_by IS NOT NULL)),
+
+    CONSTRAINT fk_events_semester
+        FOREIGN KEY (semester_id) REFERENCES semesters(semester_id)
+        ON DELETE RESTRICT ON UPDATE CASCADE,
+    CONSTRAINT fk_events_created_by
+        FOREIGN KEY (created_by) REFERENCES users(user_id),
+    CONSTRAINT fk_events_updated_by
+        FOREIGN KEY (updated_by) REFERENCES users(user_id),
+    CONSTRAINT fk_events_deleted_by
+        FOREIGN KEY (deleted_by) REFERENCES users(user_id)
+);
+
+CREATE INDEX idx_events_semester_status
+    ON events (semester_id, status) WHERE deleted_at IS NULL;
+
+CREATE TABLE event_shifts (
+    event_shift_id UUID          PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_id       UUID          NOT NULL,
+    shift_date     DATE          NOT NULL,
+    start_time     TIME          NOT NULL,
+    end_time       TIME          NOT NULL,
+    location_id    UUID,
+    headcount      SMALLINT      NOT NULL,
+    note           VARCHAR(200),
+    created_at     TIMESTAMPTZ   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by     UUID,
+    updated_at     TIMESTAMPTZ   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_by     UUID,
+    deleted_at     TIMESTAMPTZ,
+    deleted_by     UUID,
+    version        INT           NOT NULL DEFAULT 1,
+
+    CONSTRAINT ck_event_shifts_time
+        CHECK (end_time > start_time),
+    CONSTRAINT ck_event_shifts_headcount
+        CHECK (headcount BETWEEN 1 AND 100),
+    CONSTRAINT ck_event_shifts_soft_delete
+        CHECK ((deleted_at IS NULL AND deleted_by IS NULL)
+            OR (deleted_at IS NOT NULL AND deleted_by IS NOT NULL)),
+
+    CONSTRAINT fk_event_shifts_event
+        FOREIGN KEY (event_id) REFERENCES events(event_id)
+        ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT fk_event_shifts_location
+        FOREIGN KEY (location_id) REFERENCES locations(location_id)
+        ON DELETE SET NULL ON UPDATE CASCADE,
+    CONSTRAINT fk_event_shifts_created_by
+        FOREIGN KEY (created_by) REFERENCES users(user_id),
+    CONSTRAINT fk_event_shifts_updated_by
+        FOREIGN KEY (updated_by) REFERENCES users(user_id),
+    CONSTRAINT fk_event_shifts_deleted_by
+        FOREIGN KEY (deleted_by) REFERENCES users(user_id)
+);
+
+CREATE INDEX idx_event_shifts_event_date
+    ON event_shifts (event_id, shift_date) WHERE deleted_at IS NULL;
+
+CREATE TABLE event_assignments (
+    event_assignment_id UUID          PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_shift_id      UUID          NOT NULL,
+    member_id           UUID          NOT NULL,
+    source              VARCHAR(20)   NOT NULL,
+    created_at          TIMESTAMPTZ   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by          UUID,
+    updated_at          TIMESTAMPTZ   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_by          UUID,
+
+    CONSTRAINT ck_event_assignments_source
+        CHECK (source IN ('signup', 'auto', 'admin')),
+
+    CONSTRAINT fk_event_assignments_shift
+        FOREIGN KEY (event_shift_id) REFERENCES event_shifts(event_shift_id)
+        ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT fk_event_assignments_member
+        FOREIGN KEY (member_id) REFERENCES users(user_id)
+        ON DELETE RESTRICT ON UPDATE CASCADE,
+    CONSTRAINT fk_event_assignments_created_by
+        FOREIGN KEY (created_by) REFERENCES users(user_id),
+    CONSTRAINT fk_event_assignments_updated_by
+        FOREIGN KEY (updated_by) REFERENCES users(user_id)
+);
+
+CREATE UNIQUE INDEX uk_event_assignments_shift_member
+    ON event_assignments (event_shift_id, member_id);
+CREATE INDEX idx_event_assignments_member
+    ON event_assignments (member_id);
+
+-- 值班记录来源：周常排班项或活动班次，二者有且仅有其一
+ALTER TABLE duty_records
+    ALTER COLUMN schedule_item_id DROP NOT NULL,
+    ADD COLUMN event_shift_id UUID,
+    ADD CONSTRAINT fk_duty_records_event_shift
+        FOREIGN KEY (event_shift_id) REFERENCES event_shifts(event_shift_id)
+        ON DELETE RESTRICT ON UPDATE CASCADE,
+    ADD CONSTRAINT ck_duty_records_source
+        CHECK ((schedule_item_id IS NOT NULL) <> (event_shift_id IS NOT NULL));
+
+CREATE UNIQUE INDEX uk_duty_records_event_shift_member
+    ON duty_records (event_shift_id, member_id)
+    WHERE deleted_at IS NULL AND event_shift_id IS NOT NULL;
+
+ALTER TABLE notifications DROP CONSTRAINT ck_notifications_type;
+ALTER TABLE notifications ADD CONSTRAINT ck_notifications_type
+    CHECK (type IN (
+        'schedule_published', 'schedule_changed', 'duty_reminder',
+        'swap_request', 'swap_accepted', 'swap_rejected',
+        'swap_approved', 'swap_denied',
+        'absent_alert', 'make_up_alert', 'no_sign_out_alert',
+        'substitute_needed', 'schedule_conflict',
+        'event_assigned', 'event_cancelled'
+    ));
+
+COMMIT;
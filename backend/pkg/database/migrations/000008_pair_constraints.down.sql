@@ -0,0 +1,7 @@
+BEGIN;
+
+DELETE FROM schedule_rules WHERE rule_code = 'R7';
+
+DROP TABLE IF EXISTS pair_constraints;
+
+COMMIT;
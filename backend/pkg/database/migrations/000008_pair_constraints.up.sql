@@ -0,0 +1,58 @@
+-- ============================================================
+-- 成员搭配约束（规则 R7）
+-- 管理员按学期维护两名成员之间的搭配关系：
+--   must_pair     需同时段值班（新成员跟随老成员）
+--   must_not_pair 不能同时段值班
+--   not_adjacent  不能排在同日相邻时段
+-- 每个时段只排一人，多人值守通过同一时间的并行时段（如不同地点）配置，
+-- 因此"同时段"指同周同日时间重叠的并行时段；学期内没有并行时段时不能创建 must_pair。
+-- ============================================================
+
+BEGIN;
+
+CREATE TABLE pair_constraints (
+    pair_constraint_id UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    semester_id        UUID         NOT NULL,
+    member_a_id        UUID         NOT NULL,
+    member_b_id        UUID         NOT NULL,
+    kind               VARCHAR(20)  NOT NULL,
+    note               VARCHAR(200),
+    created_at         TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by         UUID,
+    updated_at         TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_by         UUID,
+    deleted_at         TIMESTAMPTZ,
+    deleted_by         UUID,
+
+    CONSTRAINT ck_pair_constraints_kind
+        CHECK (kind IN ('must_pair', 'must_not_pair', 'not_adjacent')),
+    CONSTRAINT ck_pair_constraints_members
+        CHECK (member_a_id <> member_b_id),
+    CONSTRAINT ck_pair_constraints_soft_delete
+        CHECK ((deleted_at IS NULL AND deleted_by IS NULL)
+            OR (deleted_at IS NOT NULL AND deleted_by IS NOT NULL)),
+
+    CONSTRAINT fk_pair_constraints_semester
+        FOREIGN KEY (semester_id) REFERENCES semesters(semester_id)
+        ON DELETE RESTRICT ON UPDATE CASCADE,
+    CONSTRAINT fk_pair_constraints_member_a
+        FOREIGN KEY (member_a_id) REFERENCES users(user_id),
+    CONSTRAINT fk_pair_constraints_member_b
+        FOREIGN KEY (member_b_id) REFERENCES users(user_id),
+    CONSTRAINT fk_pair_constraints_created_by
+        FOREIGN KEY (created_by) REFERENCES users(user_id),
+    CONSTRAINT fk_pair_constraints_updated_by
+        FOREIGN KEY (updated_by) REFERENCES users(user_id),
+    CONSTRAINT fk_pair_constraints_deleted_by
+        FOREIGN KEY (deleted_by) REFERENCES users(user_id)
+);
+
+-- 同一学期同一对成员至多一条约束（与 A / B 顺序无关）
+CREATE UNIQUE INDEX uk_pair_constraints_pair
+    ON pair_constraints (semester_id, LEAST(member_a_id, member_b_id), GREATEST(member_a_id, member_b_id))
+    WHERE deleted_at IS NULL;
+
+INSERT INTO schedule_rules (rule_code, rule_name, description, is_enabled, is_configurable) VALUES
+    ('R7', '成员搭配约束', '按学期搭配约束安排成员同时段 / 错开 / 不相邻值班', TRUE, TRUE);
+
+COMMIT;
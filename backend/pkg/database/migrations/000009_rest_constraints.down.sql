@@ -0,0 +1,15 @@
+BEGIN;
+
+DELETE FROM schedule_rules WHERE rule_code IN ('R8', 'R9', 'R10');
+
+ALTER TABLE system_config
+    DROP CONSTRAINT IF EXISTS ck_system_config_rest_modes,
+    DROP CONSTRAINT IF EXISTS ck_system_config_min_rest_hours,
+    DROP CONSTRAINT IF EXISTS ck_system_config_max_shifts_per_week,
+    DROP COLUMN IF EXISTS back_to_back_days_mode,
+    DROP COLUMN IF EXISTS min_rest_mode,
+    DROP COLUMN IF EXISTS min_rest_hours,
+    DROP COLUMN IF EXISTS max_shifts_per_week_mode,
+    DROP COLUMN IF EXISTS max_shifts_per_week;
+
+COMMIT;
@@ -0,0 +1,31 @@
+-- ============================================================
+-- 休息与负载分散约束
+-- R8 每周班次上限、R9 相邻班次最小间隔、R10 避免连续两天值班。
+-- 规则启停沿用 schedule_rules；阈值及硬 / 软约束模式存放于 system_config。
+-- 判定基于两周排班周期，第2周末与下一周期第1周初首尾相接。
+-- 三条规则均默认停用，升级后自动排班结果不变，由管理员按需启用。
+-- ============================================================
+
+BEGIN;
+
+ALTER TABLE system_config
+    ADD COLUMN max_shifts_per_week      INT         NOT NULL DEFAULT 2,
+    ADD COLUMN max_shifts_per_week_mode VARCHAR(10) NOT NULL DEFAULT 'soft',
+    ADD COLUMN min_rest_hours           INT         NOT NULL DEFAULT 12,
+    ADD COLUMN min_rest_mode            VARCHAR(10) NOT NULL DEFAULT 'soft',
+    ADD COLUMN back_to_back_days_mode   VARCHAR(10) NOT NULL DEFAULT 'soft',
+    ADD CONSTRAINT ck_system_config_max_shifts_per_week
+        CHECK (max_shifts_per_week >= 0),
+    ADD CONSTRAINT ck_system_config_min_rest_hours
+        CHECK (min_rest_hours >= 0),
+    ADD CONSTRAINT ck_system_config_rest_modes
+        CHECK (max_shifts_per_week_mode IN ('hard', 'soft')
+           AND min_rest_mode IN ('hard', 'soft')
+           AND back_to_back_days_mode IN ('hard', 'soft'));
+
+INSERT INTO schedule_rules (rule_code, rule_name, description, is_enabled, is_configurable) VALUES
+    ('R8',  '每周班次上限',     '同一成员每周的班次数不超过系统配置的上限',          FALSE, TRUE),
+    ('R9',  '班次最小间隔',     '同一成员相邻两个班次之间至少间隔系统配置的小时数',  FALSE, TRUE),
+    ('R10', '避免连续两天值班', '同一成员不在相邻两天连续值班',                      FALSE, TRUE);
+
+COMMIT;
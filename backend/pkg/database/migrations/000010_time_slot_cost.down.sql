@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE time_slots
+    DROP CONSTRAINT IF EXISTS ck_time_slots_cost_multiplier,
+    DROP COLUMN IF EXISTS cost_multiplier;
+
+COMMIT;
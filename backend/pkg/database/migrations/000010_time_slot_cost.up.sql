@@ -0,0 +1,14 @@
+-- ============================================================
+-- 时间段加权成本
+-- 班次成本 = 时长（小时）× 冷门系数；自动排班按成员累计成本均衡工作量，
+-- 质量报告与成员工作量统计同时给出时长与加权成本。
+-- ============================================================
+
+BEGIN;
+
+ALTER TABLE time_slots
+    ADD COLUMN cost_multiplier NUMERIC(4, 2) NOT NULL DEFAULT 1.00,
+    ADD CONSTRAINT ck_time_slots_cost_multiplier
+        CHECK (cost_multiplier > 0 AND cost_multiplier <= 10);
+
+COMMIT;
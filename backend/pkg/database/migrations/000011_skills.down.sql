@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP TABLE IF EXISTS event_shift_skills;
+DROP TABLE IF EXISTS time_slot_skills;
+DROP TABLE IF EXISTS user_skills;
+DROP TABLE IF EXISTS skills;
+
+COMMIT;
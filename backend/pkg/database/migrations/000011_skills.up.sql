@@ -0,0 +1,97 @@
+-- ============================================================
+-- 技能与资质要求
+-- 管理员维护技能目录（如音响设备操作、办公室钥匙），
+-- 管理员 / 部长为成员登记技能，时间段与活动班次可设置所需技能。
+-- 排班与候选人推荐只安排具备全部所需技能的成员（硬约束）。
+-- ============================================================
+
+BEGIN;
+
+CREATE TABLE skills (
+    skill_id    UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    name        VARCHAR(50)  NOT NULL,
+    description VARCHAR(200),
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by  UUID,
+    updated_at  TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_by  UUID,
+    deleted_at  TIMESTAMPTZ,
+    deleted_by  UUID,
+
+    CONSTRAINT ck_skills_soft_delete
+        CHECK ((deleted_at IS NULL AND deleted_by IS NULL)
+            OR (deleted_at IS NOT NULL AND deleted_by IS NOT NULL)),
+
+    CONSTRAINT fk_skills_created_by
+        FOREIGN KEY (created_by) REFERENCES users(user_id),
+    CONSTRAINT fk_skills_updated_by
+        FOREIGN KEY (updated_by) REFERENCES users(user_id),
+    CONSTRAINT fk_skills_deleted_by
+        FOREIGN KEY (deleted_by) REFERENCES users(user_id)
+);
+
+CREATE UNIQUE INDEX uk_skills_name ON skills (name) WHERE deleted_at IS NULL;
+
+-- 成员技能
+CREATE TABLE user_skills (
+    user_id    UUID        NOT NULL,
+    skill_id   UUID        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by UUID,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_by UUID,
+
+    PRIMARY KEY (user_id, skill_id),
+    CONSTRAINT fk_user_skills_user
+        FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE,
+    CONSTRAINT fk_user_skills_skill
+        FOREIGN KEY (skill_id) REFERENCES skills(skill_id) ON DELETE CASCADE,
+    CONSTRAINT fk_user_skills_created_by
+        FOREIGN KEY (created_by) REFERENCES users(user_id),
+    CONSTRAINT fk_user_skills_updated_by
+        FOREIGN KEY (updated_by) REFERENCES users(user_id)
+);
+
+CREATE INDEX idx_user_skills_skill ON user_skills (skill_id);
+
+-- 时间段所需技能
+CREATE TABLE time_slot_skills (
+    time_slot_id UUID        NOT NULL,
+    skill_id     UUID        NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by   UUID,
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_by   UUID,
+
+    PRIMARY KEY (time_slot_id, skill_id),
+    CONSTRAINT fk_time_slot_skills_time_slot
+        FOREIGN KEY (time_slot_id) REFERENCES time_slots(time_slot_id) ON DELETE CASCADE,
+    CONSTRAINT fk_time_slot_skills_skill
+        FOREIGN KEY (skill_id) REFERENCES skills(skill_id) ON DELETE CASCADE,
+    CONSTRAINT fk_time_slot_skills_created_by
+        FOREIGN KEY (created_by) REFERENCES users(user_id),
+    CONSTRAINT fk_time_slot_skills_updated_by
+        FOREIGN KEY (updated_by) REFERENCES users(user_id)
+);
+
+-- 活动班次所需技能
+CREATE TABLE event_shift_skills (
+    event_shift_id UUID        NOT NULL,
+    skill_id       UUID        NOT NULL,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by     UUID,
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_by     UUID,
+
+    PRIMARY KEY (event_shift_id, skill_id),
+    CONSTRAINT fk_event_shift_skills_shift
+        FOREIGN KEY (event_shift_id) REFERENCES event_shifts(event_shift_id) ON DELETE CASCADE,
+    CONSTRAINT fk_event_shift_skills_skill
+        FOREIGN KEY (skill_id) REFERENCES skills(skill_id) ON DELETE CASCADE,
+    CONSTRAINT fk_event_shift_skills_created_by
+        FOREIGN KEY (created_by) REFERENCES users(user_id),
+    CONSTRAINT fk_event_shift_skills_updated_by
+        FOREIGN KEY (updated_by) REFERENCES users(user_id)
+);
+
+COMMIT;
@@ -0,0 +1,13 @@
+BEGIN;
+
+ALTER TABLE time_slots
+    DROP CONSTRAINT IF EXISTS fk_time_slots_location,
+    DROP COLUMN IF EXISTS location_id;
+
+DROP TABLE IF EXISTS location_windows;
+
+ALTER TABLE locations
+    DROP CONSTRAINT IF EXISTS ck_locations_capacity,
+    DROP COLUMN IF EXISTS capacity;
+
+COMMIT;
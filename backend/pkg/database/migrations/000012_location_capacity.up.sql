@@ -0,0 +1,46 @@
+-- ============================================================
+-- 地点容量与开放时段
+-- 地点声明容量（同一时刻可容纳的值班人数）与开放时段（未配置表示全天开放），
+-- 时间段可绑定固定地点；自动排班为每个排班项分配开放且未满员的地点。
+-- ============================================================
+
+BEGIN;
+
+ALTER TABLE locations
+    ADD COLUMN capacity SMALLINT NOT NULL DEFAULT 1,
+    ADD CONSTRAINT ck_locations_capacity
+        CHECK (capacity >= 1 AND capacity <= 50);
+
+CREATE TABLE location_windows (
+    location_window_id UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    location_id        UUID        NOT NULL,
+    day_of_week        SMALLINT    NOT NULL,
+    start_time         TIME        NOT NULL,
+    end_time           TIME        NOT NULL,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by         UUID,
+    updated_at         TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_by         UUID,
+
+    CONSTRAINT ck_location_windows_day
+        CHECK (day_of_week BETWEEN 1 AND 7),
+    CONSTRAINT ck_location_windows_time
+        CHECK (end_time > start_time),
+
+    CONSTRAINT fk_location_windows_location
+        FOREIGN KEY (location_id) REFERENCES locations(location_id) ON DELETE CASCADE,
+    CONSTRAINT fk_location_windows_created_by
+        FOREIGN KEY (created_by) REFERENCES users(user_id),
+    CONSTRAINT fk_location_windows_updated_by
+        FOREIGN KEY (updated_by) REFERENCES users(user_id)
+);
+
+CREATE INDEX idx_location_windows_location ON location_windows (location_id, day_of_week);
+
+-- 时间段绑定地点（NULL 表示由自动排班选择）
+ALTER TABLE time_slots
+    ADD COLUMN location_id UUID,
+    ADD CONSTRAINT fk_time_slots_location
+        FOREIGN KEY (location_id) REFERENCES locations(location_id);
+
+COMMIT;
@@ -0,0 +1,23 @@
+BEGIN;
+
+ALTER TABLE system_config
+    DROP COLUMN IF EXISTS swap_requires_approval;
+
+ALTER TABLE schedule_change_logs
+    DROP COLUMN IF EXISTS duty_date;
+
+DELETE FROM swap_requests WHERE kind = 'give_away';
+
+DROP INDEX IF EXISTS idx_swap_requests_kind_status;
+DROP INDEX IF EXISTS uk_swap_requests_open_duty_record;
+
+ALTER TABLE swap_requests
+    DROP CONSTRAINT IF EXISTS fk_swap_requests_duty_record,
+    DROP CONSTRAINT IF EXISTS ck_swap_requests_target_member,
+    DROP CONSTRAINT IF EXISTS ck_swap_requests_give_away_record,
+    DROP CONSTRAINT IF EXISTS ck_swap_requests_kind,
+    DROP COLUMN IF EXISTS duty_record_id,
+    DROP COLUMN IF EXISTS kind,
+    ALTER COLUMN target_member_id SET NOT NULL;
+
+COMMIT;
@@ -0,0 +1,40 @@
+-- ============================================================
+-- 值班转让（give-away）
+-- 成员发布某一具体日期的值班（duty_record），不指定接班人；
+-- 符合条件的成员在转让广场认领，按 system_config.swap_requires_approval
+-- 直接生效或进入管理员审批。生效时改派该次值班记录并写入单次变更日志。
+-- ============================================================
+
+BEGIN;
+
+ALTER TABLE swap_requests
+    ADD COLUMN kind VARCHAR(20) NOT NULL DEFAULT 'transfer',
+    ADD COLUMN duty_record_id UUID,
+    ALTER COLUMN target_member_id DROP NOT NULL,
+    ADD CONSTRAINT ck_swap_requests_kind
+        CHECK (kind IN ('transfer', 'give_away')),
+    ADD CONSTRAINT ck_swap_requests_give_away_record
+        CHECK (kind != 'give_away' OR duty_record_id IS NOT NULL),
+    ADD CONSTRAINT ck_swap_requests_target_member
+        CHECK (kind = 'give_away' OR target_member_id IS NOT NULL),
+    ADD CONSTRAINT fk_swap_requests_duty_record
+        FOREIGN KEY (duty_record_id) REFERENCES duty_records(duty_record_id)
+        ON DELETE RESTRICT ON UPDATE CASCADE;
+
+-- 同一次值班同时至多一条进行中的申请
+CREATE UNIQUE INDEX uk_swap_requests_open_duty_record
+    ON swap_requests (duty_record_id)
+    WHERE status IN ('pending', 'reviewing') AND deleted_at IS NULL;
+
+CREATE INDEX idx_swap_requests_kind_status
+    ON swap_requests (kind, status)
+    WHERE deleted_at IS NULL;
+
+-- 单次值班变更（转让）记录对应日期；为空表示排班项整体变更
+ALTER TABLE schedule_change_logs
+    ADD COLUMN duty_date DATE;
+
+ALTER TABLE system_config
+    ADD COLUMN swap_requires_approval BOOLEAN NOT NULL DEFAULT TRUE;
+
+COMMIT;
@@ -0,0 +1,18 @@
+BEGIN;
+
+DELETE FROM swap_requests WHERE kind = 'exchange';
+
+DROP INDEX IF EXISTS idx_swap_requests_target_member;
+DROP INDEX IF EXISTS uk_swap_requests_open_target_duty_record;
+
+ALTER TABLE swap_requests
+    DROP CONSTRAINT IF EXISTS fk_swap_requests_target_duty_record,
+    DROP CONSTRAINT IF EXISTS fk_swap_requests_target_schedule_item,
+    DROP CONSTRAINT IF EXISTS ck_swap_requests_exchange,
+    DROP COLUMN IF EXISTS target_duty_record_id,
+    DROP COLUMN IF EXISTS target_schedule_item_id,
+    DROP CONSTRAINT ck_swap_requests_kind,
+    ADD CONSTRAINT ck_swap_requests_kind
+        CHECK (kind IN ('transfer', 'give_away'));
+
+COMMIT;
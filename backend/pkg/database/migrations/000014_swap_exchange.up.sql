@@ -0,0 +1,36 @@
+-- ============================================================
+-- 双向换班（exchange）
+-- 申请人与对方互换两个排班项（此后每周生效），或互换两次具体日期的值班；
+-- 对方接受后按 system_config.swap_requires_approval 直接生效或进入管理员审批，
+-- 生效时在同一事务内互换并写入成对的变更日志。
+-- ============================================================
+
+BEGIN;
+
+ALTER TABLE swap_requests
+    DROP CONSTRAINT ck_swap_requests_kind,
+    ADD CONSTRAINT ck_swap_requests_kind
+        CHECK (kind IN ('transfer', 'give_away', 'exchange')),
+    ADD COLUMN target_schedule_item_id UUID,
+    ADD COLUMN target_duty_record_id UUID,
+    -- 互换排班项：两侧均无值班记录；互换单次值班：两侧均有值班记录
+    ADD CONSTRAINT ck_swap_requests_exchange
+        CHECK (kind != 'exchange'
+            OR (target_schedule_item_id IS NOT NULL
+                AND (duty_record_id IS NULL) = (target_duty_record_id IS NULL))),
+    ADD CONSTRAINT fk_swap_requests_target_schedule_item
+        FOREIGN KEY (target_schedule_item_id) REFERENCES schedule_items(schedule_item_id)
+        ON DELETE RESTRICT ON UPDATE CASCADE,
+    ADD CONSTRAINT fk_swap_requests_target_duty_record
+        FOREIGN KEY (target_duty_record_id) REFERENCES duty_records(duty_record_id)
+        ON DELETE RESTRICT ON UPDATE CASCADE;
+
+CREATE UNIQUE INDEX uk_swap_requests_open_target_duty_record
+    ON swap_requests (target_duty_record_id)
+    WHERE status IN ('pending', 'reviewing') AND deleted_at IS NULL;
+
+CREATE INDEX idx_swap_requests_target_member
+    ON swap_requests (target_member_id, status)
+    WHERE deleted_at IS NULL;
+
+COMMIT;
@@ -0,0 +1,35 @@
+BEGIN;
+
+-- 系统账号审批的申请改为无审批人
+UPDATE swap_requests SET approved_by = NULL
+    WHERE approved_by = '00000000-0000-0000-0000-000000000001';
+UPDATE swap_requests SET updated_by = NULL
+    WHERE updated_by = '00000000-0000-0000-0000-000000000001';
+
+DROP TABLE IF EXISTS department_swap_policies;
+
+-- 迁移时为系统账号创建的部门（由系统账号标记删除）随账号一并删除：
+-- 先解除 deleted_by 对账号的引用，删除账号后再删除部门
+DO $$
+DECLARE
+    v_dept_id UUID;
+BEGIN
+    SELECT department_id INTO v_dept_id FROM departments
+        WHERE deleted_by = '00000000-0000-0000-0000-000000000001';
+    UPDATE departments SET deleted_at = NULL, deleted_by = NULL
+        WHERE department_id = v_dept_id;
+
+    DELETE FROM users WHERE user_id = '00000000-0000-0000-0000-000000000001';
+
+    DELETE FROM departments WHERE department_id = v_dept_id;
+END;
+$$;
+
+ALTER TABLE system_config
+    DROP CONSTRAINT IF EXISTS ck_system_config_swap_auto_approve,
+    DROP COLUMN IF EXISTS swap_auto_approve_max_per_semester,
+    DROP COLUMN IF EXISTS swap_auto_approve_min_hours,
+    DROP COLUMN IF EXISTS swap_auto_approve_same_department,
+    DROP COLUMN IF EXISTS swap_auto_approve;
+
+COMMIT;
@@ -0,0 +1,70 @@
+-- ============================================================
+-- 换班自动审批策略
+-- system_config 为全局策略，department_swap_policies 按申请人所在部门整体覆盖全局策略。
+-- 需审批的申请在认领 / 接受时若满足策略全部条件（同部门、提前时间、学期换班次数），
+-- 由系统账号直接审批通过（approved_by = 系统账号），与人工审批一样留痕。
+-- ============================================================
+
+BEGIN;
+
+ALTER TABLE system_config
+    ADD COLUMN swap_auto_approve                  BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN swap_auto_approve_same_department  BOOLEAN NOT NULL DEFAULT TRUE,
+    ADD COLUMN swap_auto_approve_min_hours        INT     NOT NULL DEFAULT 48,
+    ADD COLUMN swap_auto_approve_max_per_semester INT     NOT NULL DEFAULT 3,
+    ADD CONSTRAINT ck_system_config_swap_auto_approve
+        CHECK (swap_auto_approve_min_hours >= 0 AND swap_auto_approve_max_per_semester >= 0);
+
+CREATE TABLE department_swap_policies (
+    department_id          UUID        PRIMARY KEY,
+    auto_approve           BOOLEAN     NOT NULL DEFAULT FALSE,
+    same_department        BOOLEAN     NOT NULL DEFAULT TRUE,
+    min_hours_before       INT         NOT NULL DEFAULT 48,
+    max_swaps_per_semester INT         NOT NULL DEFAULT 3,   -- 0 不限
+    created_at             TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by             UUID,
+    updated_at             TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_by             UUID,
+
+    CONSTRAINT ck_department_swap_policies_values
+        CHECK (min_hours_before >= 0 AND max_swaps_per_semester >= 0),
+
+    CONSTRAINT fk_department_swap_policies_department
+        FOREIGN KEY (department_id) REFERENCES departments(department_id)
+        ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT fk_department_swap_policies_created_by
+        FOREIGN KEY (created_by) REFERENCES users(user_id),
+    CONSTRAINT fk_department_swap_policies_updated_by
+        FOREIGN KEY (updated_by) REFERENCES users(user_id)
+);
+
+-- 系统账号：自动审批的审批人。以已删除状态存在，不出现在成员列表中且无法登录，
+-- 仅供 approved_by / updated_by 等审计外键引用。挂靠最早创建的部门；尚无部门时
+-- 创建一个仅供挂靠的部门，并同样以已删除状态存在，不出现在部门列表与选择器中。
+DO $$
+DECLARE
+    v_dept_id UUID;
+    v_created BOOLEAN := FALSE;
+BEGIN
+    SELECT department_id INTO v_dept_id FROM departments ORDER BY created_at LIMIT 1;
+    IF v_dept_id IS NULL THEN
+        INSERT INTO departments (name, description, is_active)
+        VALUES ('系统', '系统账号所属部门', FALSE)
+        RETURNING department_id INTO v_dept_id;
+        v_created := TRUE;
+    END IF;
+
+    INSERT INTO users (user_id, name, student_id, email, password_hash, role, department_id,
+                       must_change_password, deleted_at, deleted_by)
+    VALUES ('00000000-0000-0000-0000-000000000001', '系统', 'system', 'system@echo-union.local', '!', 'member', v_dept_id,
+            FALSE, CURRENT_TIMESTAMP, '00000000-0000-0000-0000-000000000001');
+
+    IF v_created THEN
+        UPDATE departments
+        SET deleted_at = CURRENT_TIMESTAMP, deleted_by = '00000000-0000-0000-0000-000000000001'
+        WHERE department_id = v_dept_id;
+    END IF;
+END;
+$$;
+
+COMMIT;
@@ -0,0 +1,32 @@
+BEGIN;
+
+DELETE FROM notifications
+    WHERE type IN ('leave_request', 'leave_approved', 'leave_rejected', 'substitute_assigned')
+       OR related_type = 'leave_request';
+
+ALTER TABLE notifications DROP CONSTRAINT ck_notifications_related_type;
+ALTER TABLE notifications ADD CONSTRAINT ck_notifications_related_type
+    CHECK (related_type IS NULL
+        OR related_type IN ('schedule', 'schedule_item', 'swap_request', 'duty_record'));
+
+ALTER TABLE notifications DROP CONSTRAINT ck_notifications_type;
+ALTER TABLE notifications ADD CONSTRAINT ck_notifications_type
+    CHECK (type IN (
+        'schedule_published', 'schedule_changed', 'duty_reminder',
+        'swap_request', 'swap_accepted', 'swap_rejected',
+        'swap_approved', 'swap_denied',
+        'absent_alert', 'make_up_alert', 'no_sign_out_alert',
+        'substitute_needed', 'schedule_conflict',
+        'event_assigned', 'event_cancelled'
+    ));
+
+DELETE FROM schedule_change_logs WHERE change_type = 'leave';
+
+ALTER TABLE schedule_change_logs
+    DROP CONSTRAINT ck_scl_change_type,
+    ADD CONSTRAINT ck_scl_change_type
+        CHECK (change_type IN ('manual_adjust', 'swap', 'admin_modify'));
+
+DROP TABLE IF EXISTS leave_requests;
+
+COMMIT;
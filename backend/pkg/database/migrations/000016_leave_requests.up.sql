@@ -0,0 +1,94 @@
+-- ============================================================
+-- 请假（leave_requests）
+-- 成员为某一具体日期的值班请假，无需自行寻找接班人；
+-- 本部门负责人或管理员审批时从候选人中指定替班成员，通过后改派该次值班记录，
+-- 并写入 change_type = leave 的单次变更日志。请假按学期统计次数。
+-- ============================================================
+
+BEGIN;
+
+CREATE TABLE leave_requests (
+    leave_request_id UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    duty_record_id   UUID         NOT NULL,
+    member_id        UUID         NOT NULL,
+    semester_id      UUID         NOT NULL,
+    reason           VARCHAR(500) NOT NULL,
+    attachment_ref   VARCHAR(500),                 -- 证明材料引用（文件地址 / 编号）
+    status           VARCHAR(20)  NOT NULL DEFAULT 'pending',
+    substitute_id    UUID,
+    reviewed_by      UUID,
+    reviewed_at      TIMESTAMPTZ,
+    review_note      VARCHAR(500),
+    created_at       TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by       UUID,
+    updated_at       TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_by       UUID,
+    deleted_at       TIMESTAMPTZ,
+    deleted_by       UUID,
+    version          INT          NOT NULL DEFAULT 1,
+
+    CONSTRAINT ck_leave_requests_status
+        CHECK (status IN ('pending', 'approved', 'rejected', 'cancelled')),
+    CONSTRAINT ck_leave_requests_substitute
+        CHECK (status != 'approved' OR substitute_id IS NOT NULL),
+    CONSTRAINT ck_leave_requests_soft_delete
+        CHECK ((deleted_at IS NULL AND deleted_by IS NULL)
+            OR (deleted_at IS NOT NULL AND deleted_by IS NOT NULL)),
+
+    CONSTRAINT fk_leave_requests_duty_record
+        FOREIGN KEY (duty_record_id) REFERENCES duty_records(duty_record_id)
+        ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT fk_leave_requests_member
+        FOREIGN KEY (member_id) REFERENCES users(user_id)
+        ON DELETE RESTRICT ON UPDATE CASCADE,
+    CONSTRAINT fk_leave_requests_semester
+        FOREIGN KEY (semester_id) REFERENCES semesters(semester_id)
+        ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT fk_leave_requests_substitute
+        FOREIGN KEY (substitute_id) REFERENCES users(user_id),
+    CONSTRAINT fk_leave_requests_reviewed_by
+        FOREIGN KEY (reviewed_by) REFERENCES users(user_id),
+    CONSTRAINT fk_leave_requests_created_by
+        FOREIGN KEY (created_by) REFERENCES users(user_id),
+    CONSTRAINT fk_leave_requests_updated_by
+        FOREIGN KEY (updated_by) REFERENCES users(user_id),
+    CONSTRAINT fk_leave_requests_deleted_by
+        FOREIGN KEY (deleted_by) REFERENCES users(user_id)
+);
+
+-- 同一次值班同时只能有一条待审批的请假
+CREATE UNIQUE INDEX uk_leave_requests_open_duty_record
+    ON leave_requests (duty_record_id)
+    WHERE status = 'pending' AND deleted_at IS NULL;
+
+CREATE INDEX idx_leave_requests_member_semester
+    ON leave_requests (member_id, semester_id)
+    WHERE deleted_at IS NULL;
+
+CREATE INDEX idx_leave_requests_status
+    ON leave_requests (status)
+    WHERE deleted_at IS NULL;
+
+ALTER TABLE schedule_change_logs
+    DROP CONSTRAINT ck_scl_change_type,
+    ADD CONSTRAINT ck_scl_change_type
+        CHECK (change_type IN ('manual_adjust', 'swap', 'admin_modify', 'leave'));
+
+ALTER TABLE notifications DROP CONSTRAINT ck_notifications_type;
+ALTER TABLE notifications ADD CONSTRAINT ck_notifications_type
+    CHECK (type IN (
+        'schedule_published', 'schedule_changed', 'duty_reminder',
+        'swap_request', 'swap_accepted', 'swap_rejected',
+        'swap_approved', 'swap_denied',
+        'absent_alert', 'make_up_alert', 'no_sign_out_alert',
+        'substitute_needed', 'schedule_conflict',
+        'event_assigned', 'event_cancelled',
+        'leave_request', 'leave_approved', 'leave_rejected', 'substitute_assigned'
+    ));
+
+ALTER TABLE notifications DROP CONSTRAINT ck_notifications_related_type;
+ALTER TABLE notifications ADD CONSTRAINT ck_notifications_related_type
+    CHECK (related_type IS NULL
+        OR related_type IN ('schedule', 'schedule_item', 'swap_request', 'duty_record', 'leave_request'));
+
+COMMIT;
@@ -0,0 +1,40 @@
+BEGIN;
+
+DELETE FROM notifications
+    WHERE type IN ('emergency_substitute', 'emergency_filled')
+       OR related_type = 'emergency_substitution';
+
+ALTER TABLE notifications DROP CONSTRAINT ck_notifications_related_type;
+ALTER TABLE notifications ADD CONSTRAINT ck_notifications_related_type
+    CHECK (related_type IS NULL
+        OR related_type IN ('schedule', 'schedule_item', 'swap_request', 'duty_record', 'leave_request'));
+
+ALTER TABLE notifications DROP CONSTRAINT ck_notifications_type;
+ALTER TABLE notifications ADD CONSTRAINT ck_notifications_type
+    CHECK (type IN (
+        'schedule_published', 'schedule_changed', 'duty_reminder',
+        'swap_request', 'swap_accepted', 'swap_rejected',
+        'swap_approved', 'swap_denied',
+        'absent_alert', 'make_up_alert', 'no_sign_out_alert',
+        'substitute_needed', 'schedule_conflict',
+        'event_assigned', 'event_cancelled',
+        'leave_request', 'leave_approved', 'leave_rejected', 'substitute_assigned'
+    ));
+
+DROP TABLE IF EXISTS emergency_substitutions;
+
+DELETE FROM duty_records WHERE substitute_for_id IS NOT NULL;
+
+DROP INDEX IF EXISTS uk_duty_records_substitute_for;
+DROP INDEX uk_duty_records_schedule_item_date;
+CREATE UNIQUE INDEX uk_duty_records_schedule_item_date
+    ON duty_records (schedule_item_id, duty_date) WHERE deleted_at IS NULL;
+
+ALTER TABLE duty_records
+    DROP CONSTRAINT IF EXISTS fk_duty_records_substitute_for,
+    DROP COLUMN IF EXISTS substitute_for_id;
+
+ALTER TABLE system_config
+    DROP COLUMN IF EXISTS emergency_substitute_enabled;
+
+COMMIT;
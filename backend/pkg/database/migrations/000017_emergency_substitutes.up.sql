@@ -0,0 +1,92 @@
+-- ============================================================
+-- 紧急替班（emergency_substitutions）
+-- 值班记录被标记缺勤后（system_config.emergency_substitute_enabled 开启或标记时指定），
+-- 向此刻空闲的成员广播替班请求（站内通知、邮件、Webhook，附一键接班链接），
+-- 先到先得：第一位响应者获得一条新的值班记录（duty_records.substitute_for_id 指向缺勤记录），
+-- 缺勤记录保持 absent，供考勤统计区分缺勤与替班。
+-- ============================================================
+
+BEGIN;
+
+ALTER TABLE system_config
+    ADD COLUMN emergency_substitute_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- 替班生成的值班记录与原排班项、日期相同，唯一约束只约束原始记录
+ALTER TABLE duty_records
+    ADD COLUMN substitute_for_id UUID,
+    ADD CONSTRAINT fk_duty_records_substitute_for
+        FOREIGN KEY (substitute_for_id) REFERENCES duty_records(duty_record_id)
+        ON DELETE RESTRICT ON UPDATE CASCADE;
+
+DROP INDEX uk_duty_records_schedule_item_date;
+CREATE UNIQUE INDEX uk_duty_records_schedule_item_date
+    ON duty_records (schedule_item_id, duty_date)
+    WHERE deleted_at IS NULL AND substitute_for_id IS NULL;
+CREATE UNIQUE INDEX uk_duty_records_substitute_for
+    ON duty_records (substitute_for_id)
+    WHERE deleted_at IS NULL AND substitute_for_id IS NOT NULL;
+
+CREATE TABLE emergency_substitutions (
+    emergency_substitution_id UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    duty_record_id            UUID         NOT NULL,  -- 缺勤的值班记录
+    status                    VARCHAR(20)  NOT NULL DEFAULT 'open',
+    expires_at                TIMESTAMPTZ  NOT NULL,  -- 该次值班结束时间，之后不再接受响应
+    notified_count            INT          NOT NULL DEFAULT 0,
+    substitute_id             UUID,
+    substitute_record_id      UUID,
+    filled_at                 TIMESTAMPTZ,
+    created_at                TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by                UUID,
+    updated_at                TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_by                UUID,
+    deleted_at                TIMESTAMPTZ,
+    deleted_by                UUID,
+
+    CONSTRAINT ck_emergency_substitutions_status
+        CHECK (status IN ('open', 'filled', 'cancelled')),
+    CONSTRAINT ck_emergency_substitutions_filled
+        CHECK (status != 'filled' OR (substitute_id IS NOT NULL AND substitute_record_id IS NOT NULL)),
+    CONSTRAINT ck_emergency_substitutions_soft_delete
+        CHECK ((deleted_at IS NULL AND deleted_by IS NULL)
+            OR (deleted_at IS NOT NULL AND deleted_by IS NOT NULL)),
+
+    CONSTRAINT fk_emergency_substitutions_duty_record
+        FOREIGN KEY (duty_record_id) REFERENCES duty_records(duty_record_id)
+        ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT fk_emergency_substitutions_substitute
+        FOREIGN KEY (substitute_id) REFERENCES users(user_id),
+    CONSTRAINT fk_emergency_substitutions_substitute_record
+        FOREIGN KEY (substitute_record_id) REFERENCES duty_records(duty_record_id),
+    CONSTRAINT fk_emergency_substitutions_created_by
+        FOREIGN KEY (created_by) REFERENCES users(user_id),
+    CONSTRAINT fk_emergency_substitutions_updated_by
+        FOREIGN KEY (updated_by) REFERENCES users(user_id),
+    CONSTRAINT fk_emergency_substitutions_deleted_by
+        FOREIGN KEY (deleted_by) REFERENCES users(user_id)
+);
+
+-- 每条缺勤记录至多广播一次
+CREATE UNIQUE INDEX uk_emergency_substitutions_duty_record
+    ON emergency_substitutions (duty_record_id)
+    WHERE deleted_at IS NULL;
+
+ALTER TABLE notifications DROP CONSTRAINT ck_notifications_type;
+ALTER TABLE notifications ADD CONSTRAINT ck_notifications_type
+    CHECK (type IN (
+        'schedule_published', 'schedule_changed', 'duty_reminder',
+        'swap_request', 'swap_accepted', 'swap_rejected',
+        'swap_approved', 'swap_denied',
+        'absent_alert', 'make_up_alert', 'no_sign_out_alert',
+        'substitute_needed', 'schedule_conflict',
+        'event_assigned', 'event_cancelled',
+        'leave_request', 'leave_approved', 'leave_rejected', 'substitute_assigned',
+        'emergency_substitute', 'emergency_filled'
+    ));
+
+ALTER TABLE notifications DROP CONSTRAINT ck_notifications_related_type;
+ALTER TABLE notifications ADD CONSTRAINT ck_notifications_related_type
+    CHECK (related_type IS NULL
+        OR related_type IN ('schedule', 'schedule_item', 'swap_request', 'duty_record', 'leave_request',
+                            'emergency_substitution'));
+
+COMMIT;
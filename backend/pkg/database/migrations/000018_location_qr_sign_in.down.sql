@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE locations DROP COLUMN IF EXISTS qr_sign_in;
+
+COMMIT;
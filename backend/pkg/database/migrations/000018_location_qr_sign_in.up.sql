@@ -0,0 +1,12 @@
+-- ============================================================
+-- 地点二维码签到
+-- 每个地点的展示屏轮换显示签到二维码，令牌为 HMAC(地点 + 时间窗口)，短时有效且只能使用一次；
+-- 开启 qr_sign_in 的地点，成员签到时须提交本次值班所在地点的当前令牌。
+-- ============================================================
+
+BEGIN;
+
+ALTER TABLE locations
+    ADD COLUMN qr_sign_in BOOLEAN NOT NULL DEFAULT TRUE;
+
+COMMIT;
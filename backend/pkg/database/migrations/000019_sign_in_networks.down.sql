@@ -0,0 +1,11 @@
+BEGIN;
+
+DROP TABLE IF EXISTS sign_in_rejections;
+
+ALTER TABLE duty_records
+    DROP COLUMN IF EXISTS network_override_reason,
+    DROP COLUMN IF EXISTS network_override;
+
+DROP TABLE IF EXISTS location_networks;
+
+COMMIT;
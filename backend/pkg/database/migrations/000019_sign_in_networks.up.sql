@@ -0,0 +1,60 @@
+-- ============================================================
+-- 签到网络限制
+-- 地点可配置允许签到的客户端网段（如办公室 Wi-Fi），配置后签到 / 签退的客户端 IP 须落在其中之一；
+-- 管理员可为单条值班记录豁免（网络故障等），被拒绝的签到 / 签退写入审计表供负责人核查。
+-- ============================================================
+
+BEGIN;
+
+CREATE TABLE location_networks (
+    location_network_id UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    location_id         UUID         NOT NULL,
+    cidr                CIDR         NOT NULL,
+    note                VARCHAR(100),
+    created_at          TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by          UUID,
+    updated_at          TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_by          UUID,
+
+    CONSTRAINT fk_location_networks_location
+        FOREIGN KEY (location_id) REFERENCES locations(location_id) ON DELETE CASCADE,
+    CONSTRAINT fk_location_networks_created_by
+        FOREIGN KEY (created_by) REFERENCES users(user_id),
+    CONSTRAINT fk_location_networks_updated_by
+        FOREIGN KEY (updated_by) REFERENCES users(user_id)
+);
+
+CREATE INDEX idx_location_networks_location ON location_networks (location_id);
+
+ALTER TABLE duty_records
+    ADD COLUMN network_override        BOOLEAN      NOT NULL DEFAULT FALSE,
+    ADD COLUMN network_override_reason VARCHAR(200);
+
+CREATE TABLE sign_in_rejections (
+    sign_in_rejection_id UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    duty_record_id       UUID         NOT NULL,
+    member_id            UUID         NOT NULL,
+    location_id          UUID,
+    action               VARCHAR(10)  NOT NULL,
+    reason               VARCHAR(20)  NOT NULL,
+    client_ip            INET         NOT NULL,
+    detail               VARCHAR(200),
+    created_at           TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT ck_sign_in_rejections_action
+        CHECK (action IN ('sign_in', 'sign_out')),
+    CONSTRAINT ck_sign_in_rejections_reason
+        CHECK (reason IN ('network', 'qr_invalid', 'qr_wrong_location', 'qr_used')),
+
+    CONSTRAINT fk_sign_in_rejections_duty_record
+        FOREIGN KEY (duty_record_id) REFERENCES duty_records(duty_record_id) ON DELETE CASCADE,
+    CONSTRAINT fk_sign_in_rejections_member
+        FOREIGN KEY (member_id) REFERENCES users(user_id),
+    CONSTRAINT fk_sign_in_rejections_location
+        FOREIGN KEY (location_id) REFERENCES locations(location_id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_sign_in_rejections_member ON sign_in_rejections (member_id, created_at DESC);
+CREATE INDEX idx_sign_in_rejections_created ON sign_in_rejections (created_at DESC);
+
+COMMIT;
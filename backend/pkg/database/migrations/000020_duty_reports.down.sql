@@ -0,0 +1,20 @@
+BEGIN;
+
+DELETE FROM notifications WHERE type = 'duty_incident';
+
+ALTER TABLE notifications DROP CONSTRAINT ck_notifications_type;
+ALTER TABLE notifications ADD CONSTRAINT ck_notifications_type
+    CHECK (type IN (
+        'schedule_published', 'schedule_changed', 'duty_reminder',
+        'swap_request', 'swap_accepted', 'swap_rejected',
+        'swap_approved', 'swap_denied',
+        'absent_alert', 'make_up_alert', 'no_sign_out_alert',
+        'substitute_needed', 'schedule_conflict',
+        'event_assigned', 'event_cancelled',
+        'leave_request', 'leave_approved', 'leave_rejected', 'substitute_assigned',
+        'emergency_substitute', 'emergency_filled'
+    ));
+
+DROP TABLE IF EXISTS duty_reports;
+
+COMMIT;
@@ -0,0 +1,55 @@
+-- ============================================================
+-- 值班日志（交接班记录）
+-- 成员签到后为本次值班填写日志：正文、可选标签与异常标记。
+-- 冗余地点与值班开始时刻，便于下一班次查看交接内容与按学期检索；异常日志通知管理员。
+-- ============================================================
+
+BEGIN;
+
+CREATE TABLE duty_reports (
+    duty_report_id UUID          PRIMARY KEY DEFAULT gen_random_uuid(),
+    duty_record_id UUID          NOT NULL,
+    member_id      UUID          NOT NULL,
+    location_id    UUID,
+    duty_start     TIMESTAMPTZ   NOT NULL,
+    content        VARCHAR(2000) NOT NULL,
+    tags           JSONB         NOT NULL DEFAULT '[]',
+    incident       BOOLEAN       NOT NULL DEFAULT FALSE,
+    created_at     TIMESTAMPTZ   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by     UUID,
+    updated_at     TIMESTAMPTZ   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_by     UUID,
+
+    CONSTRAINT uk_duty_reports_duty_record UNIQUE (duty_record_id),
+
+    CONSTRAINT fk_duty_reports_duty_record
+        FOREIGN KEY (duty_record_id) REFERENCES duty_records(duty_record_id) ON DELETE CASCADE,
+    CONSTRAINT fk_duty_reports_member
+        FOREIGN KEY (member_id) REFERENCES users(user_id),
+    CONSTRAINT fk_duty_reports_location
+        FOREIGN KEY (location_id) REFERENCES locations(location_id) ON DELETE SET NULL,
+    CONSTRAINT fk_duty_reports_created_by
+        FOREIGN KEY (created_by) REFERENCES users(user_id),
+    CONSTRAINT fk_duty_reports_updated_by
+        FOREIGN KEY (updated_by) REFERENCES users(user_id)
+);
+
+CREATE INDEX idx_duty_reports_location_start ON duty_reports (location_id, duty_start DESC);
+CREATE INDEX idx_duty_reports_duty_start ON duty_reports (duty_start DESC);
+CREATE INDEX idx_duty_reports_tags ON duty_reports USING GIN (tags);
+
+ALTER TABLE notifications DROP CONSTRAINT ck_notifications_type;
+ALTER TABLE notifications ADD CONSTRAINT ck_notifications_type
+    CHECK (type IN (
+        'schedule_published', 'schedule_changed', 'duty_reminder',
+        'swap_request', 'swap_accepted', 'swap_rejected',
+        'swap_approved', 'swap_denied',
+        'absent_alert', 'make_up_alert', 'no_sign_out_alert',
+        'substitute_needed', 'schedule_conflict',
+        'event_assigned', 'event_cancelled',
+        'leave_request', 'leave_approved', 'leave_rejected', 'substitute_assigned',
+        'emergency_substitute', 'emergency_filled',
+        'duty_incident'
+    ));
+
+COMMIT;
@@ -0,0 +1,57 @@
+package mailer
+
+import (
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"echo-union/backend/config"
+)
+
+// Mailer SMTP 邮件发送（纯文本，UTF-8）
+type Mailer struct {
+	cfg *config.MailConfig
+}
+
+// New 创建 Mailer；未配置 SMTP 服务器时返回 nil，调用方据此跳过邮件渠道
+func New(cfg *config.MailConfig) *Mailer {
+	if cfg == nil || cfg.SMTPHost == "" || cfg.From == "" {
+		return nil
+	}
+	return &Mailer{cfg: cfg}
+}
+
+// Send 发送邮件给 to 中的全部收件人（同一封邮件，收件人互相可见时请逐个调用）
+func (m *Mailer) Send(to []string, subject, body string) error {
+	if len(to) == 0 {
+		return nil
+	}
+	addr := net.JoinHostPort(m.cfg.SMTPHost, strconv.Itoa(m.cfg.SMTPPort))
+	var auth smtp.Auth
+	if m.cfg.Username != "" {
+		auth = smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.SMTPHost)
+	}
+	if err := smtp.SendMail(addr, auth, m.cfg.From, to, buildMessage(m.cfg.From, to, subject, body)); err != nil {
+		return fmt.Errorf("发送邮件失败: %w", err)
+	}
+	return nil
+}
+
+// buildMessage 构造 RFC 5322 邮件，主题按 RFC 2047 以 base64 编码
+func buildMessage(from string, to []string, subject, body string) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + strings.Join(to, ", ") + "\r\n")
+	b.WriteString("Subject: " + mime.BEncoding.Encode("UTF-8", subject) + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	return []byte(b.String())
+}
@@ -2,6 +2,7 @@ package redis
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -12,7 +13,7 @@ import (
 )
 
 // Client Redis 客户端封装
-// 当前用于 Token 黑名单；后续可扩展缓存、分布式锁等场景
+// 用于 Token 黑名单、限流、分布式锁、一次性令牌防重放与后台任务状态共享；后续可扩展缓存等场景
 type Client struct {
 	rdb    *goredis.Client
 	logger *zap.Logger
@@ -83,6 +84,79 @@ func (c *Client) CheckRateLimit(ctx context.Context, key string, limit int, wind
 	return result <= int64(limit), nil
 }
 
+// ── 分布式锁 ──
+
+const lockPrefix = "lock:"
+
+// releaseLockScript 仅当锁持有者与 token 一致时删除，避免误删他人重新获取的锁
+var releaseLockScript = goredis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+    return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// AcquireLock 以 SET NX 方式获取分布式锁，token 用于释放时校验持有者
+// 返回 false 表示锁已被占用
+func (c *Client) AcquireLock(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+	return c.rdb.SetNX(ctx, lockPrefix+key, token, ttl).Result()
+}
+
+// ReleaseLock 释放分布式锁（仅持有者可释放）
+func (c *Client) ReleaseLock(ctx context.Context, key, token string) error {
+	return releaseLockScript.Run(ctx, c.rdb, []string{lockPrefix + key}, token).Err()
+}
+
+// ── 一次性令牌 ──
+
+const oncePrefix = "once:"
+
+// ClaimOnce 以 SET NX 登记一次性令牌的使用，TTL 应覆盖令牌有效期
+// 返回 false 表示令牌已被使用（重放）
+func (c *Client) ClaimOnce(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return c.rdb.SetNX(ctx, oncePrefix+key, "1", ttl).Result()
+}
+
+// ReleaseOnce 撤销一次性令牌的使用登记（使用未生效时调用）
+func (c *Client) ReleaseOnce(ctx context.Context, key string) error {
+	return c.rdb.Del(ctx, oncePrefix+key).Err()
+}
+
+// ── 后台任务状态 ──
+
+const (
+	jobStatePrefix  = "job:state:"
+	jobCancelPrefix = "job:cancel:"
+)
+
+// SetJobState 写入后台任务状态快照（供其他实例查询、订阅）
+func (c *Client) SetJobState(ctx context.Context, jobID string, state []byte, ttl time.Duration) error {
+	return c.rdb.Set(ctx, jobStatePrefix+jobID, state, ttl).Err()
+}
+
+// GetJobState 读取后台任务状态快照，任务不存在时返回 nil
+func (c *Client) GetJobState(ctx context.Context, jobID string) ([]byte, error) {
+	data, err := c.rdb.Get(ctx, jobStatePrefix+jobID).Bytes()
+	if errors.Is(err, goredis.Nil) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// RequestJobCancel 登记后台任务的取消请求，由执行任务的实例轮询处理
+func (c *Client) RequestJobCancel(ctx context.Context, jobID string, ttl time.Duration) error {
+	return c.rdb.Set(ctx, jobCancelPrefix+jobID, "1", ttl).Err()
+}
+
+// IsJobCancelRequested 检查后台任务是否已被请求取消
+func (c *Client) IsJobCancelRequested(ctx context.Context, jobID string) (bool, error) {
+	n, err := c.rdb.Exists(ctx, jobCancelPrefix+jobID).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
 // Ping 检查 Redis 连接是否正常
 func (c *Client) Ping(ctx context.Context) error {
 	return c.rdb.Ping(ctx).Err()
@@ -48,6 +48,15 @@ func Created(c *gin.Context, data interface{}) {
 	})
 }
 
+// Accepted 202 已受理（异步任务）
+func Accepted(c *gin.Context, data interface{}) {
+	c.JSON(http.StatusAccepted, Response{
+		Code:    0,
+		Message: "success",
+		Data:    data,
+	})
+}
+
 // OKPage 200 分页成功
 func OKPage(c *gin.Context, list interface{}, total int64, page, pageSize int) {
 	if pageSize <= 0 {
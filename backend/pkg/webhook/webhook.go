@@ -0,0 +1,70 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"echo-union/backend/config"
+)
+
+// Client 外部 Webhook 推送
+// 请求体为 {"event": ..., "occurred_at": ..., "data": ...}；配置了 secret 时
+// 请求头 X-Echo-Signature 为请求体的 HMAC-SHA256（hex），接收方据此校验来源。
+type Client struct {
+	url    string
+	secret string
+	http   *http.Client
+}
+
+// New 创建 Client；未配置 URL 时返回 nil，调用方据此跳过 Webhook 渠道
+func New(cfg *config.WebhookConfig) *Client {
+	if cfg == nil || cfg.URL == "" {
+		return nil
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	return &Client{url: cfg.URL, secret: cfg.Secret, http: &http.Client{Timeout: timeout}}
+}
+
+// Post 推送事件，非 2xx 响应视为失败
+func (c *Client) Post(ctx context.Context, event string, data interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"event":       event,
+		"occurred_at": time.Now().Format(time.RFC3339),
+		"data":        data,
+	})
+	if err != nil {
+		return fmt.Errorf("序列化 Webhook 事件失败: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("创建 Webhook 请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Echo-Event", event)
+	if c.secret != "" {
+		mac := hmac.New(sha256.New, []byte(c.secret))
+		mac.Write(body)
+		req.Header.Set("X-Echo-Signature", hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("推送 Webhook 失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("推送 Webhook 失败: HTTP %d", resp.StatusCode)
+	}
+	return nil
+}